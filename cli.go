@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/deeplink"
+)
+
+// command is a subcommand; run parses its own flags with newFlagSet and
+// parseFlags, so the global flags work after the command name too
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands are dispatched by name; the first is the default
+var commands = []command{
+	{"tui", "Monitor sessions in the terminal UI (the default)", runTUI},
+	{"serve", "Serve the web dashboard", runServe},
+	{"serve-ssh", "Serve the TUI over SSH", runServeSSH},
+	{"snapshot", "Write a sanitized state archive for bug reports", runSnapshot},
+	{"digest", "Summarize activity since the last digest", runDigest},
+	{"check", "Fail CI on session commands with findings", runCheck},
+	{"annotate", "Turn a session into a pull request review", runAnnotate},
+	{"export", "Write an encrypted archive of sessions", runExport},
+	{"open", "Open an exported archive", runOpen},
+	{"scrub", "Apply retention redaction rules to session files", runScrub},
+	{"doctor", "Diagnose the environment and suggest fixes", runDoctor},
+	{"bench", "Benchmark the session parser", runBench},
+	{"config", "Write, validate, and manage config files and profiles", runConfig},
+}
+
+// globalFlags are accepted before the command name and by the commands that
+// honor them (see commandGlobals)
+type globalFlags struct {
+	config       string
+	profile      string
+	projectsDirs []string
+	theme        string
+	logFile      string
+
+	openLog string // Log file already opened, so applying twice reuses it
+}
+
+var globals globalFlags
+
+// errFailed makes main exit 1 without printing an error, for commands whose
+// output already reports the failure (and may be a report on stdout)
+var errFailed = errors.New("failed")
+
+// globalNames are the flags register defines, to spot them in a command's flags
+var globalNames = map[string]bool{"config": true, "profile": true, "projects-dir": true, "theme": true, "log-file": true}
+
+// commandGlobals lists the global flags each command honors besides
+// --config, --profile, and --log-file, which every command does: --theme
+// only where the TUI is drawn, --projects-dir only where sessions are
+// discovered rather than read from files given as arguments
+var commandGlobals = map[string][]string{
+	"tui":       {"projects-dir", "theme"},
+	"serve":     {"projects-dir"},
+	"serve-ssh": {"projects-dir", "theme"},
+	"snapshot":  {"projects-dir"},
+	"digest":    {"projects-dir"},
+	"export":    {"projects-dir"},
+	"open":      {"theme"},
+	"doctor":    {"projects-dir"},
+}
+
+// honors reports whether the command named cmd honors the global flag name
+func honors(cmd, name string) bool {
+	switch name {
+	case "config", "profile", "log-file":
+		return true
+	}
+	return slices.Contains(commandGlobals[cmd], name)
+}
+
+// register defines on fs the global flags the command named cmd honors (all
+// of them when cmd is empty), defaulting to values already parsed
+func (g *globalFlags) register(fs *flag.FlagSet, cmd string) {
+	all := cmd == ""
+	fs.StringVar(&g.config, "config", g.config, "Read the config from this file instead of the standard locations")
+	fs.StringVar(&g.profile, "profile", g.profile, "Apply this named config profile (see: config profile list)")
+	if all || honors(cmd, "projects-dir") {
+		fs.Func("projects-dir", "Also watch this Claude projects directory, like projects_dirs (repeatable)", func(dir string) error {
+			g.projectsDirs = append(g.projectsDirs, dir)
+			return nil
+		})
+	}
+	if all || honors(cmd, "theme") {
+		fs.StringVar(&g.theme, "theme", g.theme, "Color theme: mocha, macchiato, frappe, or latte")
+	}
+	fs.StringVar(&g.logFile, "log-file", g.logFile, "Append diagnostic logs (watcher errors and the like) to this file")
+}
+
+// apply loads the config the global flags select and points the log at the
+// log file, discarding it otherwise so nothing is written over the TUI
+func (g *globalFlags) apply() error {
+	switch {
+	case g.logFile == "":
+		log.SetOutput(io.Discard)
+	case g.logFile != g.openLog:
+		f, err := os.OpenFile(g.logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return err
+		}
+		log.SetOutput(f)
+		g.openLog = g.logFile
+	}
+
+	if g.config != "" {
+		if err := config.SetPath(g.config); err != nil {
+			return fmt.Errorf("--config: %w", err)
+		}
+	}
+	if g.config == "" && g.profile == "" && g.theme == "" && len(g.projectsDirs) == 0 {
+		return nil // config.Global loads the standard config lazily
+	}
+	cfg, err := config.LoadProfile(g.profile)
+	if err != nil {
+		return err
+	}
+	if g.theme != "" {
+		if !config.IsTheme(g.theme) {
+			return fmt.Errorf("unknown theme %q (want mocha, macchiato, frappe, or latte)", g.theme)
+		}
+		cfg.Theme = g.theme
+	}
+	cfg.ProjectsDirs = append(cfg.ProjectsDirs, g.projectsDirs...)
+	config.SetGlobal(cfg)
+	return nil
+}
+
+// newFlagSet returns a command's flag set, with the global flags it honors
+// defined
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	globals.register(fs, name)
+	return fs
+}
+
+// parseFlags parses a command's flags, applying the global flags again if
+// any were given after the command name
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	changed := false
+	fs.Visit(func(f *flag.Flag) { changed = changed || globalNames[f.Name] })
+	if !changed {
+		return nil
+	}
+	return globals.apply()
+}
+
+// dispatch parses the global flags in args, then runs the command named next
+// with the rest. Without a command name (or with flags only the default
+// command knows, as in `cc_session_mon --demo`, or with a ccmon:// link) it
+// runs the default command.
+// It returns the name of the command it ran.
+func dispatch(args []string) (string, error) {
+	log.SetOutput(io.Discard) // Until --log-file says where
+	root := flag.NewFlagSet("cc_session_mon", flag.ContinueOnError)
+	root.SetOutput(io.Discard)
+	globals.register(root, "")
+	err := root.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		usage(os.Stdout)
+		return "", nil
+	}
+
+	cmd := commands[0]
+	switch {
+	case err != nil:
+		// An unknown flag: leave all the arguments to the default command,
+		// which parses the global flags among them again
+		globals = globalFlags{}
+	case root.NArg() == 0:
+		args = nil
+	case root.Arg(0) == "help":
+		usage(os.Stdout)
+		return "help", nil
+	case deeplink.IsLink(root.Arg(0)):
+		args = root.Args() // `cc_session_mon ccmon://...` opens the link in the TUI
+	default:
+		found := false
+		for _, c := range commands {
+			if c.name == root.Arg(0) {
+				cmd, found = c, true
+			}
+		}
+		if !found {
+			usage(os.Stderr)
+			return root.Arg(0), errors.New("unknown command")
+		}
+		args = root.Args()[1:]
+	}
+	if err == nil {
+		var unused string
+		root.Visit(func(f *flag.Flag) {
+			if unused == "" && !honors(cmd.name, f.Name) {
+				unused = f.Name
+			}
+		})
+		if unused != "" {
+			return cmd.name, fmt.Errorf("--%s doesn't apply to %s", unused, cmd.name)
+		}
+		if err := globals.apply(); err != nil {
+			return cmd.name, err
+		}
+	}
+	return cmd.name, cmd.run(args)
+}
+
+// usage lists the commands and global flags
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: cc_session_mon [global flags] [command] [flags]")
+	fmt.Fprintln(w, "       cc_session_mon [flags] ccmon://session/<id>[?cmd=<uuid>]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w, "\nGlobal flags (before or after the command; --theme only for tui, serve-ssh,")
+	fmt.Fprintln(w, "and open; --projects-dir only for commands that discover sessions):")
+	fs := flag.NewFlagSet("cc_session_mon", flag.ContinueOnError)
+	fs.SetOutput(w)
+	globals.register(fs, "")
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\nRun cc_session_mon <command> -h for a command's flags.")
+}
@@ -41,6 +41,8 @@
               ".go"
               ".mod"
               ".sum"
+              ".html"
+              ".yaml"
             ];
             ldflags = [
               "-s"
@@ -0,0 +1,439 @@
+// Package alert notifies configured sinks when commands arriving in watched
+// sessions trigger security findings or exceed alert thresholds.
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"cc_session_mon/internal/applog"
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+)
+
+// queueSize bounds the deliveries waiting for the worker; alerts arriving
+// while it is full are dropped
+const queueSize = 256
+
+// Event is one finding on one newly arrived command
+type Event struct {
+	Time        time.Time         `json:"time"`
+	Rule        string            `json:"rule"`
+	Severity    security.Severity `json:"severity"`
+	SessionID   string            `json:"session_id"`
+	ProjectPath string            `json:"project_path"`
+	Origin      string            `json:"origin,omitempty"`
+	Owner       string            `json:"owner,omitempty"` // Owner label from config owners
+	Pattern     string            `json:"pattern"`
+	Command     string            `json:"command"`
+
+	// Suppressed counts the alerts the sink's delivery policy held back since
+	// its previous alert
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+// Options configures an engine beyond what the config file says
+type Options struct {
+	// DashboardURL links chat alerts to the web dashboard when the config
+	// doesn't name one (e.g. the --web address)
+	DashboardURL string
+
+	// FlagSession marks a session flagged for actions with flag set
+	// (typically Watcher.FlagSession); flag actions do nothing without it
+	FlagSession func(filePath, reason string)
+
+	// SnapshotEvent copies each event Run handles, with its session's command
+	// history, under the watcher's lock (typically
+	// Watcher.SnapshotEventWithHistory), since the watcher keeps updating
+	// sessions while the engine reads them
+	SnapshotEvent func(session.WatchEvent) session.WatchEvent
+}
+
+// route sends alerts for matching rules at or above a severity to sinks
+type route struct {
+	cfg         config.AlertRoute
+	minSeverity security.Severity
+	sinks       []Notifier
+}
+
+// action is a configured guard-rail with its sinks resolved
+type action struct {
+	cfg      config.AlertAction
+	severity security.Severity
+	run      Notifier // exec sink for cfg.Run, or nil
+	notify   []Notifier
+}
+
+// Engine turns watcher events into alerts and delivers them to the sinks
+// chosen by the configured routes, and runs actions on matching commands
+type Engine struct {
+	sinks       []Notifier
+	names       map[Notifier]string // Sink names, for the log
+	routes      []route
+	actions     []action
+	flagSession func(filePath, reason string)
+	snapshot    func(session.WatchEvent) session.WatchEvent
+
+	thresholds []config.AlertThreshold
+	breached   map[string]time.Time // session path + threshold -> start of the last alerted breach
+	started    time.Time            // breaches ending earlier are history and never alert
+
+	// Sinks run on a worker so a slow one (an exec sink may take its whole
+	// timeout) never holds up the events behind it
+	mu     sync.Mutex
+	queue  chan func()
+	closed bool
+	done   chan struct{}
+
+	// Errors receives delivery failures; it is never closed and drops
+	// errors when full
+	Errors chan error
+}
+
+// New creates an engine from the alerts section of cfg, building each sink
+// with its registered Factory. Enabled reports whether any sink is configured.
+func New(cfg *config.Config, opts Options) (*Engine, error) {
+	e := &Engine{
+		Errors:      make(chan error, 10),
+		names:       make(map[Notifier]string),
+		flagSession: opts.FlagSession,
+		snapshot:    opts.SnapshotEvent,
+		thresholds:  cfg.Alerts.Thresholds,
+		breached:    make(map[string]time.Time),
+		started:     time.Now(),
+		queue:       make(chan func(), queueSize),
+		done:        make(chan struct{}),
+	}
+	minSeverity := security.SeverityHigh
+	if cfg.Alerts.MinSeverity != "" {
+		sev, err := security.ParseSeverity(cfg.Alerts.MinSeverity)
+		if err != nil {
+			return nil, err
+		}
+		minSeverity = sev
+	}
+
+	ctx := SinkContext{DashboardURL: cfg.Alerts.DashboardURL, OnError: e.reportError}
+	if ctx.DashboardURL == "" {
+		ctx.DashboardURL = opts.DashboardURL
+	}
+	byName := make(map[string]Notifier)
+	var names []string
+	for _, sc := range sinkConfigs(&cfg.Alerts) {
+		if _, dup := byName[sc.Name]; dup {
+			return nil, fmt.Errorf("duplicate sink name %q", sc.Name)
+		}
+		n, err := newNotifier(sc, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if n, err = limit(n, cfg.Alerts.Delivery.Merge(sc.DeliveryPolicy)); err != nil {
+			return nil, fmt.Errorf("sink %s: %w", sc.Name, err)
+		}
+		byName[sc.Name] = n
+		e.names[n] = sc.Name
+		names = append(names, sc.Name)
+		e.sinks = append(e.sinks, n)
+	}
+
+	routes := cfg.Alerts.Routes
+	if len(routes) == 0 && len(names) > 0 {
+		routes = []config.AlertRoute{{Sinks: names}}
+	}
+	for _, rc := range routes {
+		r := route{cfg: rc, minSeverity: minSeverity}
+		if rc.MinSeverity != "" {
+			sev, err := security.ParseSeverity(rc.MinSeverity)
+			if err != nil {
+				return nil, err
+			}
+			r.minSeverity = sev
+		}
+		for _, name := range rc.Sinks {
+			n, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("route names unknown sink %q", name)
+			}
+			r.sinks = append(r.sinks, n)
+		}
+		e.routes = append(e.routes, r)
+	}
+
+	for _, ac := range cfg.Alerts.Actions {
+		a, err := newAction(ac, byName)
+		if err != nil {
+			return nil, err
+		}
+		e.actions = append(e.actions, a)
+	}
+	// Started last, so an invalid config leaves no worker behind
+	go e.work()
+	return e, nil
+}
+
+// newAction resolves an action's severity, run command, and notify sinks
+func newAction(ac config.AlertAction, byName map[string]Notifier) (action, error) {
+	a := action{cfg: ac, severity: security.SeverityHigh}
+	if ac.Severity != "" {
+		sev, err := security.ParseSeverity(ac.Severity)
+		if err != nil {
+			return a, fmt.Errorf("action %s: %w", ac.Name, err)
+		}
+		a.severity = sev
+	}
+	if len(ac.Run) > 0 {
+		run, err := newExecSink(config.SinkConfig{Name: ac.Name, Command: ac.Run}, SinkContext{})
+		if err != nil {
+			return a, fmt.Errorf("action %s: %w", ac.Name, err)
+		}
+		a.run = run
+	}
+	for _, name := range ac.Notify {
+		n, ok := byName[name]
+		if !ok {
+			return a, fmt.Errorf("action %s notifies unknown sink %q", ac.Name, name)
+		}
+		a.notify = append(a.notify, n)
+	}
+	return a, nil
+}
+
+// sinkConfigs returns the named sinks plus the email, slack, and discord
+// shorthands (named after their type) when configured
+func sinkConfigs(a *config.AlertRules) []config.SinkConfig {
+	var sinks []config.SinkConfig
+	if a.Email.Enabled() {
+		sinks = append(sinks, config.SinkConfig{Name: "email", Type: "email", EmailSink: a.Email})
+	}
+	if a.Slack.URL() != "" {
+		sinks = append(sinks, config.SinkConfig{Name: "slack", Type: "slack", ChatSink: a.Slack})
+	}
+	if a.Discord.URL() != "" {
+		sinks = append(sinks, config.SinkConfig{Name: "discord", Type: "discord", ChatSink: a.Discord})
+	}
+	return append(sinks, a.Sinks...)
+}
+
+// Enabled reports whether the engine has anywhere to send alerts or actions to run
+func (e *Engine) Enabled() bool {
+	return len(e.sinks) > 0 || len(e.actions) > 0
+}
+
+// Run handles events, copied with Options.SnapshotEvent when set, until the
+// channel is closed
+func (e *Engine) Run(events <-chan session.WatchEvent) {
+	for ev := range events {
+		if e.snapshot != nil {
+			ev = e.snapshot(ev)
+		}
+		e.Handle(ev)
+	}
+}
+
+// Handle alerts on the findings of a "new_commands" event and on threshold
+// breaches it (or an "updated" event marking failures) completes. Other
+// events, including the history of newly discovered sessions, are ignored.
+func (e *Engine) Handle(ev session.WatchEvent) {
+	for _, alert := range e.events(ev) {
+		e.deliver(alert)
+	}
+	for _, alert := range e.breaches(ev) {
+		e.deliver(alert)
+	}
+	e.runActions(ev)
+}
+
+// deliver queues an alert for the sinks of the routes it matches, reaching
+// each sink at most once however many routes match it
+func (e *Engine) deliver(alert Event) {
+	var sinks []Notifier
+	for _, r := range e.routes {
+		if alert.Severity < r.minSeverity || !r.cfg.MatchesRule(alert.Rule) {
+			continue
+		}
+		for _, n := range r.sinks {
+			if !slices.Contains(sinks, n) {
+				sinks = append(sinks, n)
+			}
+		}
+	}
+	if len(sinks) == 0 {
+		return
+	}
+	e.dispatch(alert.Rule, func() {
+		for _, n := range sinks {
+			if err := n.Send(alert.Rule, alert.Severity, alert); err != nil {
+				e.reportError(fmt.Errorf("alert %q: %w", alert.Rule, err))
+			} else {
+				applog.Infof("alert: %q (%s) sent to %s", alert.Rule, alert.Severity, e.names[n])
+			}
+		}
+	})
+}
+
+// dispatch queues send for the worker without blocking. A full queue, or a
+// closed engine, drops it.
+func (e *Engine) dispatch(rule string, send func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.queue <- send:
+	default:
+		e.reportError(fmt.Errorf("alert %q dropped: %d deliveries already queued", rule, queueSize))
+	}
+}
+
+// work runs queued deliveries until Close closes the queue
+func (e *Engine) work() {
+	defer close(e.done)
+	for send := range e.queue {
+		send()
+	}
+}
+
+// breaches returns an alert for each threshold whose latest breach in the
+// event's session is new: it ends after the engine started and began after
+// the last breach alerted for that session and threshold
+func (e *Engine) breaches(ev session.WatchEvent) []Event {
+	if (ev.Type != "new_commands" && ev.Type != "updated") || ev.Session == nil {
+		return nil
+	}
+
+	sess := ev.Session
+	var alerts []Event
+	for i := range e.thresholds {
+		t := &e.thresholds[i]
+		found := security.ThresholdBreaches(t, sess.Commands)
+		if len(found) == 0 {
+			continue
+		}
+		b := found[len(found)-1]
+		key := sess.FilePath + "\x00" + t.Name
+		if b.End().Before(e.started) || !b.Start().After(e.breached[key]) {
+			continue
+		}
+		e.breached[key] = b.Start()
+
+		last := b.Commands[len(b.Commands)-1]
+		alert := newEvent(sess, &last, t.Name, b.Severity)
+		alert.Pattern = t.Pattern
+		alert.Command = b.Describe(t)
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// runActions triggers the actions matching each command of a "new_commands" event
+func (e *Engine) runActions(ev session.WatchEvent) {
+	if ev.Type != "new_commands" || ev.Session == nil {
+		return
+	}
+	sess := ev.Session
+	for i := range ev.Commands {
+		c := &ev.Commands[i]
+		for _, a := range e.actions {
+			if !a.cfg.Matches(sess.ProjectPath, c.Pattern, c.RawCommand) {
+				continue
+			}
+			alert := newEvent(sess, c, a.cfg.Name, a.severity)
+			e.dispatch(alert.Rule, func() {
+				if a.run != nil {
+					if err := a.run.Send(alert.Rule, alert.Severity, alert); err != nil {
+						e.reportError(fmt.Errorf("action %s: %w", a.cfg.Name, err))
+					} else {
+						applog.Infof("alert: action %s ran", a.cfg.Name)
+					}
+				}
+				for _, n := range a.notify {
+					if err := n.Send(alert.Rule, alert.Severity, alert); err != nil {
+						e.reportError(fmt.Errorf("action %s: %w", a.cfg.Name, err))
+					} else {
+						applog.Infof("alert: action %s sent to %s", a.cfg.Name, e.names[n])
+					}
+				}
+			})
+			if a.cfg.Flag && e.flagSession != nil {
+				e.flagSession(sess.FilePath, a.cfg.Name)
+			}
+		}
+	}
+}
+
+// events returns the findings on the commands of an event
+func (e *Engine) events(ev session.WatchEvent) []Event {
+	if ev.Type != "new_commands" || ev.Session == nil {
+		return nil
+	}
+
+	sess := ev.Session
+	cfg := config.ForProject(sess.ProjectPath)
+	var alerts []Event
+	for i := range ev.Commands {
+		c := &ev.Commands[i]
+		for _, f := range security.CommandFindings(c, sess.ProjectPath, cfg, sess.Commands) {
+			alerts = append(alerts, newEvent(sess, c, f.Rule, f.Severity))
+		}
+	}
+	return alerts
+}
+
+// newEvent builds the alert for a command under a rule or action name
+func newEvent(sess *session.Session, c *session.CommandEntry, rule string, sev security.Severity) Event {
+	return Event{
+		Time:        c.Timestamp,
+		Rule:        rule,
+		Severity:    sev,
+		SessionID:   sess.ID,
+		ProjectPath: sess.ProjectPath,
+		Origin:      sess.Origin,
+		Owner:       config.Global().OwnerOf(sess.Origin, sess.ProjectPath),
+		Pattern:     c.Pattern,
+		Command:     c.RawCommand,
+	}
+}
+
+// Pending returns the number of alerts queued for delivery, such as those
+// waiting for the worker or for an email batch window to close
+func (e *Engine) Pending() int {
+	pending := len(e.queue)
+	for _, n := range e.sinks {
+		if q, ok := n.(queuer); ok {
+			pending += q.Pending()
+		}
+	}
+	return pending
+}
+
+// Close delivers the queued alerts, flushes batched ones, and releases every
+// sink. Alerts handled afterwards are dropped.
+func (e *Engine) Close() error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+	<-e.done
+
+	var errs []error
+	for _, n := range e.sinks {
+		errs = append(errs, n.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// reportError logs a delivery failure and passes it to Errors without blocking
+func (e *Engine) reportError(err error) {
+	applog.Warnf("alert: %v", err)
+	select {
+	case e.Errors <- err:
+	default:
+	}
+}
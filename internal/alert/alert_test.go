@@ -0,0 +1,438 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+)
+
+func testSession() *session.Session {
+	return &session.Session{ID: "sess-1", ProjectPath: "/projects/alpha", FilePath: "/tmp/sess-1.jsonl"}
+}
+
+// recorder is a Notifier that keeps what it was sent
+type recorder struct {
+	sent []string
+}
+
+func (r *recorder) Send(event string, _ security.Severity, payload Event) error {
+	sent := event + ": " + payload.Command
+	if payload.Suppressed > 0 {
+		sent += fmt.Sprintf(" (+%d)", payload.Suppressed)
+	}
+	r.sent = append(r.sent, sent)
+	return nil
+}
+
+func (r *recorder) Close() error { return nil }
+
+func TestRegistryMatchesConfigSinkTypes(t *testing.T) {
+	want := slices.Clone(config.SinkTypes)
+	slices.Sort(want)
+	got := slices.DeleteFunc(registeredTypes(), func(typ string) bool { return typ == "recorder" })
+	if !slices.Equal(got, want) {
+		t.Errorf("registered sink types %v, config.SinkTypes %v", got, want)
+	}
+}
+
+func TestEngineRoutes(t *testing.T) {
+	cfg := config.DefaultConfig()
+	config.SetGlobal(cfg)
+	defer config.SetGlobal(nil)
+
+	recorders := make(map[string]*recorder)
+	Register("recorder", func(sc config.SinkConfig, _ SinkContext) (Notifier, error) {
+		recorders[sc.Name] = &recorder{}
+		return recorders[sc.Name], nil
+	})
+
+	cfg.Alerts.Sinks = []config.SinkConfig{{Name: "all", Type: "recorder"}, {Name: "pushes", Type: "recorder"}}
+	cfg.Alerts.Routes = []config.AlertRoute{
+		{MinSeverity: "medium", Sinks: []string{"all"}},
+		{Rules: []string{"Force push*"}, Sinks: []string{"pushes", "all"}},
+	}
+	e, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmds := []session.CommandEntry{
+		{ToolName: "Bash", Pattern: "Bash(rm:*)", RawCommand: "rm -rf build", Timestamp: time.Now()},
+		{ToolName: "Bash", Pattern: "Bash(curl:*)", RawCommand: "curl https://example.com", Timestamp: time.Now()},
+		{ToolName: "Bash", Pattern: "Bash(git:push:*)", RawCommand: "git push --force", Timestamp: time.Now()},
+	}
+	e.Handle(session.WatchEvent{Type: "discovered", Session: testSession(), Commands: cmds})
+	e.Handle(session.WatchEvent{Type: "new_commands", Session: testSession(), Commands: cmds})
+	// Close waits for the worker to deliver what was queued
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantAll := []string{"Recursive file deletion: rm -rf build", "Force push to remote: git push --force"}
+	if got := recorders["all"].sent; !slices.Equal(got, wantAll) {
+		t.Errorf("all sink got %v, want %v (each alert once, no low severity, no history)", got, wantAll)
+	}
+	if got := recorders["pushes"].sent; !slices.Equal(got, []string{"Force push to remote: git push --force"}) {
+		t.Errorf("pushes sink got %v", got)
+	}
+}
+
+func TestRunHandlesSnapshots(t *testing.T) {
+	cfg := config.DefaultConfig()
+	config.SetGlobal(cfg)
+	defer config.SetGlobal(nil)
+
+	var sink recorder
+	Register("recorder", func(config.SinkConfig, SinkContext) (Notifier, error) { return &sink, nil })
+	cfg.Alerts.Sinks = []config.SinkConfig{{Name: "all", Type: "recorder"}}
+
+	// The live event carries a harmless command; only the copy is evaluated
+	snapshot := func(ev session.WatchEvent) session.WatchEvent {
+		ev.Session = ev.Session.Header()
+		ev.Commands = []session.CommandEntry{
+			{ToolName: "Bash", Pattern: "Bash(git:push:*)", RawCommand: "git push --force", Timestamp: time.Now()},
+		}
+		return ev
+	}
+	e, err := New(cfg, Options{SnapshotEvent: snapshot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan session.WatchEvent, 1)
+	events <- session.WatchEvent{Type: "new_commands", Session: testSession(), Commands: []session.CommandEntry{
+		{ToolName: "Bash", Pattern: "Bash(ls:*)", RawCommand: "ls", Timestamp: time.Now()},
+	}}
+	close(events)
+	e.Run(events)
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"Force push to remote: git push --force"}; !slices.Equal(sink.sent, want) {
+		t.Errorf("sink got %v, want %v", sink.sent, want)
+	}
+}
+
+func TestNewRejectsUnknownSink(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Alerts.Routes = []config.AlertRoute{{Sinks: []string{"pager"}}}
+	if _, err := New(cfg, Options{}); err == nil {
+		t.Error("expected an error for a route to an undeclared sink")
+	}
+}
+
+func TestNewRejectsUnknownSeverity(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Alerts.MinSeverity = "critical"
+	if _, err := New(cfg, Options{}); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
+
+func TestEmailSinkBatchesPerRule(t *testing.T) {
+	sink, err := newEmailSink(config.EmailSink{
+		Host:        "smtp.example.com",
+		From:        "monitor@example.com",
+		To:          []string{"oncall@example.com"},
+		BatchWindow: time.Hour,
+		Templates: map[string]config.EmailTemplate{
+			"Force push to remote": {Subject: "PUSH {{.Count}}"},
+		},
+	}, func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []string
+	sink.send = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		if addr != "smtp.example.com:587" || from != "monitor@example.com" || len(to) != 1 {
+			t.Errorf("unexpected envelope %s %s %v", addr, from, to)
+		}
+		sent = append(sent, string(msg))
+		return nil
+	}
+
+	for _, ev := range []Event{
+		{Rule: "Recursive file deletion", Command: "rm -rf a"},
+		{Rule: "Force push to remote", Command: "git push -f"},
+		{Rule: "Recursive file deletion", Command: "rm -rf b"},
+	} {
+		if err := sink.Send(ev.Rule, ev.Severity, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sent) != 0 {
+		t.Fatalf("expected nothing sent before the batch window ends, got %d", len(sent))
+	}
+	if n := sink.Pending(); n != 3 {
+		t.Errorf("expected 3 alerts pending, got %d", n)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := sink.Pending(); n != 0 {
+		t.Errorf("expected nothing pending after Close, got %d", n)
+	}
+
+	if len(sent) != 2 {
+		t.Fatalf("expected one email per rule, got %d", len(sent))
+	}
+	if !strings.Contains(sent[0], "Subject: [cc_session_mon] low: Recursive file deletion (2)") ||
+		!strings.Contains(sent[0], "rm -rf a") || !strings.Contains(sent[0], "rm -rf b") {
+		t.Errorf("unexpected default-template email:\n%s", sent[0])
+	}
+	if !strings.Contains(sent[1], "Subject: PUSH 1") || !strings.Contains(sent[1], "git push -f") {
+		t.Errorf("expected the per-rule subject with the default body:\n%s", sent[1])
+	}
+}
+
+func TestChatSinkPostsAlert(t *testing.T) {
+	var got map[string][]map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Alerts.Discord.WebhookURL = srv.URL
+	e, err := New(cfg, Options{DashboardURL: "http://localhost:8080"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := Event{Rule: "Force push to remote", SessionID: "sess-1", Command: "git push -f"}
+	if err := e.sinks[0].Send(ev.Rule, ev.Severity, ev); err != nil {
+		t.Fatal(err)
+	}
+	embeds := got["embeds"]
+	if len(embeds) != 1 || embeds[0]["title"] != "Force push to remote" ||
+		embeds[0]["url"] != "http://localhost:8080/#session=sess-1" {
+		t.Errorf("unexpected Discord payload %+v", got)
+	}
+}
+
+func TestExecSinkPassesAlertOnStdin(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "alert.json")
+	n, err := newExecSink(config.SinkConfig{
+		Command: []string{"sh", "-c", `cat > "$0"; echo "$CCMON_RULE/$CCMON_SEVERITY" >> "$0"`, out},
+	}, SinkContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := Event{Rule: "Force push to remote", Severity: security.SeverityHigh, SessionID: "sess-1", Command: "git push -f"}
+	if err := n.Send(ev.Rule, ev.Severity, ev); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"rule":"Force push to remote","severity":"high"`) ||
+		!strings.HasSuffix(string(data), "Force push to remote/high\n") {
+		t.Errorf("unexpected command input:\n%s", data)
+	}
+
+	failing, _ := newExecSink(config.SinkConfig{Command: []string{"sh", "-c", "echo nope >&2; exit 3"}}, SinkContext{})
+	if err := failing.Send(ev.Rule, ev.Severity, ev); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("expected the exit error with stderr, got %v", err)
+	}
+}
+
+func TestEngineActions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	config.SetGlobal(cfg)
+	defer config.SetGlobal(nil)
+
+	var rec *recorder
+	Register("recorder", func(config.SinkConfig, SinkContext) (Notifier, error) {
+		rec = &recorder{}
+		return rec, nil
+	})
+	cfg.Alerts.Sinks = []config.SinkConfig{{Name: "team", Type: "recorder"}}
+	cfg.Alerts.Routes = []config.AlertRoute{{Rules: []string{"nothing"}, Sinks: []string{"team"}}}
+	cfg.Alerts.Actions = []config.AlertAction{{
+		Name:     "force-push-alpha",
+		Pattern:  "Bash(git:push:*)",
+		Contains: []string{"--force"},
+		Projects: []string{"alpha"},
+		Notify:   []string{"team"},
+		Flag:     true,
+	}}
+
+	var flagged []string
+	e, err := New(cfg, Options{FlagSession: func(path, reason string) { flagged = append(flagged, path+" "+reason) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.Handle(session.WatchEvent{Type: "new_commands", Session: testSession(), Commands: []session.CommandEntry{
+		{ToolName: "Bash", Pattern: "Bash(git:push:*)", RawCommand: "git push origin main"},
+		{ToolName: "Bash", Pattern: "Bash(git:push:*)", RawCommand: "git push --force origin main"},
+	}})
+	// Close waits for the worker to deliver what was queued
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(rec.sent, []string{"force-push-alpha: git push --force origin main"}) {
+		t.Errorf("expected one action alert, got %v", rec.sent)
+	}
+	if !slices.Equal(flagged, []string{"/tmp/sess-1.jsonl force-push-alpha"}) {
+		t.Errorf("expected the session flagged, got %v", flagged)
+	}
+}
+
+func TestEngineThresholds(t *testing.T) {
+	cfg := config.DefaultConfig()
+	config.SetGlobal(cfg)
+	defer config.SetGlobal(nil)
+
+	var rec *recorder
+	Register("recorder", func(config.SinkConfig, SinkContext) (Notifier, error) {
+		rec = &recorder{}
+		return rec, nil
+	})
+	cfg.Alerts.MinSeverity = "medium"
+	cfg.Alerts.Sinks = []config.SinkConfig{{Name: "team", Type: "recorder"}}
+	cfg.Alerts.Thresholds = []config.AlertThreshold{{Name: "failing-bash", Pattern: "Bash(*", Failed: true, Count: 2, InARow: true}}
+	e, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess := testSession()
+	failed := func() session.CommandEntry {
+		return session.CommandEntry{ToolName: "Bash", Pattern: "Bash(make:*)", RawCommand: "make", Timestamp: time.Now(), IsError: true}
+	}
+	// History from before the engine started never alerts
+	sess.Commands = []session.CommandEntry{failed(), failed(), failed()}
+	for i := range sess.Commands {
+		sess.Commands[i].Timestamp = e.started.Add(-time.Hour)
+	}
+	e.Handle(session.WatchEvent{Type: "updated", Session: sess})
+
+	for range 4 {
+		c := failed()
+		sess.Commands = append(sess.Commands, session.CommandEntry{ToolName: "Bash", Pattern: "Bash(make:*)", Timestamp: time.Now()}, c)
+		e.Handle(session.WatchEvent{Type: "new_commands", Session: sess, Commands: []session.CommandEntry{c}})
+	}
+	sess.Commands = append(sess.Commands, failed(), failed())
+	e.Handle(session.WatchEvent{Type: "new_commands", Session: sess})
+	sess.Commands = append(sess.Commands, failed())
+	e.Handle(session.WatchEvent{Type: "new_commands", Session: sess})
+	// Close waits for the worker to deliver what was queued
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(rec.sent, []string{"failing-bash: 3 failed Bash(* in a row"}) {
+		t.Errorf("expected one alert for the live streak, got %v", rec.sent)
+	}
+}
+
+// blockingSink is a Notifier whose Send waits until release is closed
+type blockingSink struct {
+	release chan struct{}
+}
+
+func (b *blockingSink) Send(string, security.Severity, Event) error {
+	<-b.release
+	return nil
+}
+
+func (b *blockingSink) Close() error { return nil }
+
+func TestEngineQueueDropsWhenFull(t *testing.T) {
+	cfg := config.DefaultConfig()
+	config.SetGlobal(cfg)
+	defer config.SetGlobal(nil)
+
+	sink := &blockingSink{release: make(chan struct{})}
+	Register("blocking", func(config.SinkConfig, SinkContext) (Notifier, error) { return sink, nil })
+	cfg.Alerts.Sinks = []config.SinkConfig{{Name: "slow", Type: "blocking"}}
+	e, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A stuck sink must not hold up the events behind it
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		cmds := []session.CommandEntry{{ToolName: "Bash", Pattern: "Bash(rm:*)", RawCommand: "rm -rf build", Timestamp: time.Now()}}
+		for range queueSize + 10 {
+			e.Handle(session.WatchEvent{Type: "new_commands", Session: testSession(), Commands: cmds})
+		}
+	}()
+	select {
+	case <-handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handle blocked on a slow sink")
+	}
+
+	select {
+	case err := <-e.Errors:
+		if !strings.Contains(err.Error(), "dropped") {
+			t.Errorf("expected a dropped alert reported, got %v", err)
+		}
+	default:
+		t.Error("expected alerts beyond the queue to be dropped and reported")
+	}
+	close(sink.release)
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimitedSink(t *testing.T) {
+	rec := &recorder{}
+	n, err := limit(rec, config.DeliveryPolicy{
+		RateLimit:   2,
+		DedupWindow: 10 * time.Minute,
+		QuietHours:  config.QuietHours{From: "22:00", To: "07:00", MinSeverity: "high"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.Local)
+	n.(*limitedSink).now = func() time.Time { return now }
+	send := func(at time.Duration, command string, sev security.Severity) {
+		now = time.Date(2025, 1, 2, 12, 0, 0, 0, time.Local).Add(at)
+		if err := n.Send("Recursive file deletion", sev, Event{Rule: "Recursive file deletion", Severity: sev, Command: command}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send(0, "rm -rf a", security.SeverityMedium)
+	send(time.Minute, "rm -rf a", security.SeverityMedium) // duplicate
+	send(2*time.Minute, "rm -rf b", security.SeverityMedium)
+	send(3*time.Minute, "rm -rf c", security.SeverityMedium) // over the rate limit
+	send(61*time.Minute, "rm -rf c", security.SeverityMedium)
+	send(11*time.Hour, "rm -rf d", security.SeverityMedium) // 23:00, quiet
+	send(11*time.Hour+time.Minute, "rm -rf e", security.SeverityHigh)
+
+	want := []string{
+		"Recursive file deletion: rm -rf a",
+		"Recursive file deletion: rm -rf b (+1)",
+		"Recursive file deletion: rm -rf c (+1)",
+		"Recursive file deletion: rm -rf e (+1)",
+	}
+	if !slices.Equal(rec.sent, want) {
+		t.Errorf("got %v, want %v", rec.sent, want)
+	}
+}
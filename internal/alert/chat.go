@@ -0,0 +1,66 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"cc_session_mon/internal/chat"
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// chatPostTimeout bounds each webhook delivery
+const chatPostTimeout = 10 * time.Second
+
+// chatSink posts each alert to a Slack or Discord incoming webhook
+type chatSink struct {
+	url       string
+	payload   func(...chat.Message) map[string]any
+	dashboard string // Web dashboard base URL for session links, optional
+}
+
+// chatFactory returns the factory for a chat sink rendered by payload
+func chatFactory(name string, payload func(...chat.Message) map[string]any) Factory {
+	return func(cfg config.SinkConfig, ctx SinkContext) (Notifier, error) {
+		url := cfg.URL()
+		if url == "" {
+			return nil, fmt.Errorf("no %s webhook_url", name)
+		}
+		return &chatSink{url: url, payload: payload, dashboard: ctx.DashboardURL}, nil
+	}
+}
+
+// Send posts the alert
+func (s *chatSink) Send(_ string, _ security.Severity, payload Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), chatPostTimeout)
+	defer cancel()
+	return chat.Post(ctx, s.url, s.payload(alertMessage(payload, s.dashboard)))
+}
+
+// Close is a no-op; chat deliveries are synchronous
+func (s *chatSink) Close() error { return nil }
+
+// alertMessage formats an alert for chat, linking to its session in the dashboard
+func alertMessage(ev Event, dashboard string) chat.Message {
+	msg := chat.Message{
+		Title:    ev.Rule,
+		URL:      chat.SessionURL(dashboard, ev.SessionID),
+		Text:     chat.CodeBlock(ev.Command),
+		Severity: ev.Severity,
+		Fields: []chat.Field{
+			{Name: "Project", Value: filepath.Base(ev.ProjectPath)},
+			{Name: "Severity", Value: ev.Severity.String()},
+			{Name: "Pattern", Value: ev.Pattern},
+		},
+		Timestamp: ev.Time,
+	}
+	if ev.Owner != "" {
+		msg.Fields = append(msg.Fields, chat.Field{Name: "Owner", Value: ev.Owner})
+	}
+	if ev.Suppressed > 0 {
+		msg.Fields = append(msg.Fields, chat.Field{Name: "Suppressed", Value: fmt.Sprintf("%d earlier alerts held back", ev.Suppressed)})
+	}
+	return msg
+}
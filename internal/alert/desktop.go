@@ -0,0 +1,72 @@
+package alert
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// desktopSink shows alerts as desktop notifications (notify-send, or
+// osascript on macOS)
+type desktopSink struct {
+	command string
+}
+
+// newDesktopSink finds the platform's notification command
+func newDesktopSink(config.SinkConfig, SinkContext) (Notifier, error) {
+	command := "notify-send"
+	if runtime.GOOS == "darwin" {
+		command = "osascript"
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return nil, fmt.Errorf("desktop notifications need %s: %w", command, err)
+	}
+	return &desktopSink{command: path}, nil
+}
+
+// Send shows a notification titled with the rule
+func (s *desktopSink) Send(event string, severity security.Severity, payload Event) error {
+	title := "cc_session_mon: " + event
+	body := filepath.Base(payload.ProjectPath) + ": " + firstLine(payload.Command)
+	if payload.Suppressed > 0 {
+		body += fmt.Sprintf(" (+%d held back)", payload.Suppressed)
+	}
+
+	var args []string
+	if runtime.GOOS == "darwin" {
+		args = []string{"-e", fmt.Sprintf("display notification %q with title %q", body, title)}
+	} else {
+		args = []string{"--urgency", urgency(severity), "--app-name", "cc_session_mon", title, body}
+	}
+	return exec.Command(s.command, args...).Run() //nolint:gosec // fixed notifier binary, alert text as arguments
+}
+
+// Close is a no-op; notifications are shown synchronously
+func (s *desktopSink) Close() error { return nil }
+
+// urgency maps a severity to a notify-send urgency level
+func urgency(sev security.Severity) string {
+	switch sev {
+	case security.SeverityHigh:
+		return "critical"
+	case security.SeverityMedium:
+		return "normal"
+	default:
+		return "low"
+	}
+}
+
+// firstLine returns the first line of a command, marking any continuation
+func firstLine(s string) string {
+	first, _, multi := strings.Cut(s, "\n")
+	if multi {
+		return first + " …"
+	}
+	return first
+}
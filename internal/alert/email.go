@@ -0,0 +1,215 @@
+package alert
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// Email sink defaults
+const (
+	defaultSMTPPort    = 587
+	defaultBatchWindow = time.Minute
+)
+
+// Built-in email templates, rendered with a batch
+const (
+	defaultEmailSubject = `[cc_session_mon] {{.Severity}}: {{.Rule}}{{if gt .Count 1}} ({{.Count}}){{end}}`
+	defaultEmailBody    = `{{.Count}} command(s) triggered "{{.Rule}}":
+{{range .Events}}
+{{.Time.Format "Jan 02 15:04:05"}}  {{.ProjectPath}}  (session {{.SessionID}})
+  {{.Command}}{{if .Suppressed}}
+  ({{.Suppressed}} earlier alerts held back){{end}}
+{{end}}`
+)
+
+// batch is the template data for one email: a rule's alerts within a batch window
+type batch struct {
+	Rule     string
+	Severity security.Severity
+	Count    int
+	Events   []Event
+}
+
+// emailTemplates is a parsed subject and body pair
+type emailTemplates struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// sendMailFunc matches smtp.SendMail, replaced in tests
+type sendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// emailSink batches alerts and sends one email per rule per batch window
+type emailSink struct {
+	cfg      config.EmailSink
+	addr     string
+	auth     smtp.Auth
+	defaults emailTemplates
+	perRule  map[string]emailTemplates
+	send     sendMailFunc
+	onError  func(error)
+
+	mu      sync.Mutex
+	pending map[string][]Event
+	order   []string // rules in the order they were first seen in the batch
+	timer   *time.Timer
+}
+
+// newEmailSink parses the sink's templates and resolves its SMTP settings
+func newEmailSink(cfg config.EmailSink, onError func(error)) (*emailSink, error) {
+	if cfg.Port == 0 {
+		cfg.Port = defaultSMTPPort
+	}
+	if cfg.BatchWindow <= 0 {
+		cfg.BatchWindow = defaultBatchWindow
+	}
+
+	s := &emailSink{
+		cfg:     cfg,
+		addr:    net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		perRule: make(map[string]emailTemplates),
+		send:    smtp.SendMail,
+		onError: onError,
+		pending: make(map[string][]Event),
+	}
+	if cfg.Username != "" {
+		s.auth = smtp.PlainAuth("", cfg.Username, os.Getenv(cfg.PasswordEnv), cfg.Host)
+	}
+
+	var err error
+	if s.defaults, err = parseEmailTemplates("default", cfg.Subject, cfg.Body, emailTemplates{}); err != nil {
+		return nil, err
+	}
+	for rule, t := range cfg.Templates {
+		if s.perRule[rule], err = parseEmailTemplates(rule, t.Subject, t.Body, s.defaults); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// parseEmailTemplates parses a subject and body, falling back to fallback
+// (or the built-in templates) for empty ones
+func parseEmailTemplates(name, subject, body string, fallback emailTemplates) (emailTemplates, error) {
+	var t emailTemplates
+	var err error
+	switch {
+	case subject != "":
+		t.subject, err = template.New(name + " subject").Parse(subject)
+	case fallback.subject != nil:
+		t.subject = fallback.subject
+	default:
+		t.subject, err = template.New(name + " subject").Parse(defaultEmailSubject)
+	}
+	if err != nil {
+		return t, err
+	}
+
+	switch {
+	case body != "":
+		t.body, err = template.New(name + " body").Parse(body)
+	case fallback.body != nil:
+		t.body = fallback.body
+	default:
+		t.body, err = template.New(name + " body").Parse(defaultEmailBody)
+	}
+	return t, err
+}
+
+// Send queues an alert, starting the batch window if it isn't running.
+// Delivery failures are reported to onError when the window closes.
+func (s *emailSink) Send(_ string, _ security.Severity, ev Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pending[ev.Rule]; !ok {
+		s.order = append(s.order, ev.Rule)
+	}
+	s.pending[ev.Rule] = append(s.pending[ev.Rule], ev)
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.cfg.BatchWindow, func() {
+			if err := s.flush(); err != nil {
+				s.onError(err)
+			}
+		})
+	}
+	return nil
+}
+
+// Pending returns the number of alerts waiting for the batch window to close
+func (s *emailSink) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := 0
+	for _, events := range s.pending {
+		pending += len(events)
+	}
+	return pending
+}
+
+// Close stops the batch window and sends whatever is pending
+func (s *emailSink) Close() error {
+	s.mu.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.mu.Unlock()
+	return s.flush()
+}
+
+// flush sends one email per rule queued since the last flush
+func (s *emailSink) flush() error {
+	s.mu.Lock()
+	pending, order := s.pending, s.order
+	s.pending, s.order, s.timer = make(map[string][]Event), nil, nil
+	s.mu.Unlock()
+
+	for _, rule := range order {
+		events := pending[rule]
+		msg, err := s.message(batch{Rule: rule, Severity: events[0].Severity, Count: len(events), Events: events})
+		if err != nil {
+			return err
+		}
+		if err := s.send(s.addr, s.auth, s.cfg.From, s.cfg.To, msg); err != nil {
+			return fmt.Errorf("sending alert email: %w", err)
+		}
+	}
+	return nil
+}
+
+// message renders a batch with its rule's templates as an RFC 5322 message
+func (s *emailSink) message(b batch) ([]byte, error) {
+	t, ok := s.perRule[b.Rule]
+	if !ok {
+		t = s.defaults
+	}
+
+	var subject, body bytes.Buffer
+	if err := t.subject.Execute(&subject, b); err != nil {
+		return nil, err
+	}
+	if err := t.body.Execute(&body, b); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body.String(), "\n", "\r\n"))
+	return msg.Bytes(), nil
+}
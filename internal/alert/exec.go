@@ -0,0 +1,73 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// defaultExecTimeout bounds an exec sink run when the config doesn't
+const defaultExecTimeout = 30 * time.Second
+
+// execSink runs a user-supplied command per alert with the alert JSON on
+// stdin, for automation the monitor doesn't know about (pausing an agent,
+// paging on-call)
+type execSink struct {
+	command []string
+	timeout time.Duration
+}
+
+// newExecSink builds an exec notifier from the sink's command
+func newExecSink(cfg config.SinkConfig, _ SinkContext) (Notifier, error) {
+	if len(cfg.Command) == 0 {
+		return nil, fmt.Errorf("exec needs a command")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	return &execSink{command: cfg.Command, timeout: timeout}, nil
+}
+
+// Send runs the command, passing the alert as JSON on stdin and its main
+// fields as CCMON_* environment variables. A non-zero exit is an error that
+// includes the command's stderr.
+func (s *execSink) Send(event string, severity security.Severity, payload Event) error {
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...) //nolint:gosec // command comes from the user's config
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(),
+		"CCMON_RULE="+event,
+		"CCMON_SEVERITY="+severity.String(),
+		"CCMON_SESSION_ID="+payload.SessionID,
+		"CCMON_PROJECT="+payload.ProjectPath,
+		"CCMON_OWNER="+payload.Owner,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", s.command[0], err, msg)
+		}
+		return fmt.Errorf("%s: %w", s.command[0], err)
+	}
+	return nil
+}
+
+// Close is a no-op; commands run synchronously
+func (s *execSink) Close() error { return nil }
@@ -0,0 +1,111 @@
+package alert
+
+import (
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// defaultRatePeriod is the rate limit period when a policy sets only a limit
+const defaultRatePeriod = time.Hour
+
+// limitedSink applies a delivery policy to a notifier: quiet hours, a dedup
+// window, and a rate limit. Alerts it holds back are counted in the next
+// alert that gets through (Event.Suppressed).
+type limitedSink struct {
+	next        Notifier
+	policy      config.DeliveryPolicy
+	quietMin    security.Severity
+	quietAll    bool // quiet hours hold back every severity
+	now         func() time.Time
+	sent        []time.Time          // send times within the rate period, oldest first
+	lastByAlert map[string]time.Time // rule + command -> last send, within the dedup window
+	suppressed  int
+}
+
+// limit wraps n in policy, or returns n unchanged when the policy limits nothing
+func limit(n Notifier, policy config.DeliveryPolicy) (Notifier, error) {
+	if policy.RateLimit == 0 && policy.DedupWindow == 0 && policy.QuietHours.From == "" {
+		return n, nil
+	}
+	if policy.RatePeriod <= 0 {
+		policy.RatePeriod = defaultRatePeriod
+	}
+	s := &limitedSink{
+		next:        n,
+		policy:      policy,
+		quietAll:    policy.QuietHours.MinSeverity == "",
+		now:         time.Now,
+		lastByAlert: make(map[string]time.Time),
+	}
+	if !s.quietAll {
+		sev, err := security.ParseSeverity(policy.QuietHours.MinSeverity)
+		if err != nil {
+			return nil, err
+		}
+		s.quietMin = sev
+	}
+	return s, nil
+}
+
+// Send delivers the alert unless the policy holds it back
+func (s *limitedSink) Send(event string, severity security.Severity, payload Event) error {
+	now := s.now()
+	if s.held(now, severity, payload) {
+		s.suppressed++
+		return nil
+	}
+
+	if s.policy.RateLimit > 0 {
+		s.sent = append(s.sent, now)
+	}
+	if s.policy.DedupWindow > 0 {
+		s.lastByAlert[payload.Rule+"\x00"+payload.Command] = now
+	}
+	payload.Suppressed, s.suppressed = s.suppressed, 0
+	return s.next.Send(event, severity, payload)
+}
+
+// held reports whether quiet hours, the dedup window, or the rate limit
+// hold back an alert at now, first forgetting sends that no longer count
+func (s *limitedSink) held(now time.Time, severity security.Severity, payload Event) bool {
+	if s.policy.QuietHours.Contains(now) && (s.quietAll || severity < s.quietMin) {
+		return true
+	}
+
+	if s.policy.DedupWindow > 0 {
+		for key, t := range s.lastByAlert {
+			if now.Sub(t) >= s.policy.DedupWindow {
+				delete(s.lastByAlert, key)
+			}
+		}
+		if _, ok := s.lastByAlert[payload.Rule+"\x00"+payload.Command]; ok {
+			return true
+		}
+	}
+
+	if s.policy.RateLimit > 0 {
+		expired := 0
+		for expired < len(s.sent) && now.Sub(s.sent[expired]) >= s.policy.RatePeriod {
+			expired++
+		}
+		s.sent = s.sent[expired:]
+		if len(s.sent) >= s.policy.RateLimit {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending returns the wrapped notifier's queued alerts. Alerts the policy
+// held back are not pending: they are only counted in the next alert.
+func (s *limitedSink) Pending() int {
+	if q, ok := s.next.(queuer); ok {
+		return q.Pending()
+	}
+	return 0
+}
+
+// Close closes the wrapped notifier
+func (s *limitedSink) Close() error { return s.next.Close() }
@@ -0,0 +1,103 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"cc_session_mon/internal/chat"
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// Notifier delivers alerts to one destination. event is the rule that
+// fired; payload carries the full alert.
+type Notifier interface {
+	Send(event string, severity security.Severity, payload Event) error
+	Close() error
+}
+
+// queuer is implemented by notifiers that hold alerts for later delivery
+type queuer interface {
+	// Pending returns the number of alerts accepted but not yet delivered
+	Pending() int
+}
+
+// SinkContext carries engine settings that notifiers may use
+type SinkContext struct {
+	DashboardURL string      // Web dashboard base URL for session links, optional
+	OnError      func(error) // Reports failures of deliveries made after Send returns
+}
+
+// Factory builds a notifier from its sink config
+type Factory func(cfg config.SinkConfig, ctx SinkContext) (Notifier, error)
+
+// registry maps sink types (config.SinkTypes) to their factories
+var registry = map[string]Factory{}
+
+// Register makes a notifier type available to alerts.sinks entries
+func Register(typ string, f Factory) {
+	registry[typ] = f
+}
+
+// registeredTypes returns the registered sink types, sorted
+func registeredTypes() []string {
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func init() {
+	Register("email", func(cfg config.SinkConfig, ctx SinkContext) (Notifier, error) {
+		if !cfg.EmailSink.Enabled() {
+			return nil, fmt.Errorf("email needs a host and recipients")
+		}
+		return newEmailSink(cfg.EmailSink, ctx.OnError)
+	})
+	Register("slack", chatFactory("slack", chat.SlackPayload))
+	Register("discord", chatFactory("discord", chat.DiscordPayload))
+	Register("webhook", newWebhookSink)
+	Register("desktop", newDesktopSink)
+	Register("syslog", newSyslogSink)
+	Register("exec", newExecSink)
+}
+
+// newNotifier builds the notifier for a sink config using the registry
+func newNotifier(cfg config.SinkConfig, ctx SinkContext) (Notifier, error) {
+	f, ok := registry[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("sink %s: unknown type %q", cfg.Name, cfg.Type)
+	}
+	n, err := f(cfg, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sink %s: %w", cfg.Name, err)
+	}
+	return n, nil
+}
+
+// webhookSink posts each alert's JSON to a URL
+type webhookSink struct {
+	url string
+}
+
+// newWebhookSink builds a generic JSON webhook notifier
+func newWebhookSink(cfg config.SinkConfig, _ SinkContext) (Notifier, error) {
+	url := cfg.URL()
+	if url == "" {
+		return nil, fmt.Errorf("no webhook_url")
+	}
+	return &webhookSink{url: url}, nil
+}
+
+// Send posts the alert as JSON
+func (s *webhookSink) Send(_ string, _ security.Severity, payload Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), chatPostTimeout)
+	defer cancel()
+	return chat.Post(ctx, s.url, payload)
+}
+
+// Close is a no-op; webhook deliveries are synchronous
+func (s *webhookSink) Close() error { return nil }
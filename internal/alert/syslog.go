@@ -0,0 +1,48 @@
+//go:build !windows && !plan9
+
+package alert
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// syslogSink writes alerts to the local syslog daemon
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// newSyslogSink connects to the local syslog daemon with the sink's tag
+func newSyslogSink(cfg config.SinkConfig, _ SinkContext) (Notifier, error) {
+	tag := cfg.Tag
+	if tag == "" {
+		tag = "cc_session_mon"
+	}
+	w, err := syslog.New(syslog.LOG_WARNING|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{w: w}, nil
+}
+
+// Send logs the alert at a priority matching its severity
+func (s *syslogSink) Send(event string, severity security.Severity, payload Event) error {
+	msg := fmt.Sprintf("%s [%s] session=%s project=%s command=%q",
+		event, severity, payload.SessionID, payload.ProjectPath, firstLine(payload.Command))
+	switch severity {
+	case security.SeverityHigh:
+		return s.w.Crit(msg)
+	case security.SeverityMedium:
+		return s.w.Warning(msg)
+	default:
+		return s.w.Notice(msg)
+	}
+}
+
+// Close disconnects from syslog
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
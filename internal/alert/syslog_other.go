@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package alert
+
+import (
+	"errors"
+
+	"cc_session_mon/internal/config"
+)
+
+// newSyslogSink reports that syslog isn't available on this platform
+func newSyslogSink(config.SinkConfig, SinkContext) (Notifier, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
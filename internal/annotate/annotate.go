@@ -0,0 +1,334 @@
+// Package annotate turns what an agent did in a session into a GitHub pull
+// request review: a comment on the lines each Edit and Write produced, and a
+// review body listing security findings and changes no line could be found for.
+package annotate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+)
+
+// timeFormat is how command times appear in comments
+const timeFormat = "15:04:05"
+
+// Options configures a review
+type Options struct {
+	Config      *config.Config    // Rules findings come from; nil uses the global config
+	MinSeverity security.Severity // Lowest finding severity listed
+	ProjectPath string            // Where the agent worked; "" uses the session's first working directory
+	Checkout    string            // The PR's checkout, searched for changed lines; "" uses the current directory
+	CommitID    string            // Commit the comments refer to; "" lets GitHub use the PR head
+	Diff        Diff              // Lines the PR changes; changes outside it go in the body. nil comments wherever a change is found
+}
+
+// Diff holds the lines a pull request changes, by file path relative to the
+// repository. GitHub rejects a review with any comment outside the diff.
+type Diff map[string][]hunk
+
+// hunk is the lines one diff hunk adds, ascending
+type hunk []int
+
+// Comment is a review comment on lines of a file, as the GitHub API takes it
+type Comment struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	StartLine int    `json:"start_line,omitempty"`
+	Side      string `json:"side"`
+	Body      string `json:"body"`
+}
+
+// Review is the body of a GitHub "create a review" request
+// (POST /repos/{owner}/{repo}/pulls/{pull}/reviews)
+type Review struct {
+	CommitID string    `json:"commit_id,omitempty"`
+	Event    string    `json:"event"`
+	Body     string    `json:"body"`
+	Comments []Comment `json:"comments"`
+}
+
+// change is a file change the agent made, located in the checkout or not
+type change struct {
+	cmd      *session.CommandEntry
+	path     string // Relative to the project
+	start    int    // First changed line, 0 when not found
+	end      int
+	findings []security.Finding
+}
+
+// Build reads a session file and its subagent transcripts and returns a
+// review annotating the files the agent changed inside the project
+func Build(path string, opts Options) (*Review, error) {
+	if opts.Config == nil {
+		opts.Config = config.Global()
+	}
+	if opts.Checkout == "" {
+		opts.Checkout = "."
+	}
+
+	commands, meta, err := session.ParseSessionFileUnfiltered(path)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	subagents, _ := filepath.Glob(filepath.Join(filepath.Dir(path), id, "subagents", "*.jsonl"))
+	for _, sub := range subagents {
+		subCommands, _, err := session.ParseSessionFileUnfiltered(sub)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, subCommands...)
+	}
+	sort.SliceStable(commands, func(i, j int) bool { return commands[i].Timestamp.Before(commands[j].Timestamp) })
+
+	projectPath := opts.ProjectPath
+	if projectPath == "" {
+		projectPath = meta.CWD
+	}
+
+	var changes []change
+	var findings []string
+	for i := range commands {
+		c := &commands[i]
+		var listed []security.Finding
+		for _, f := range security.CommandFindings(c, projectPath, opts.Config, commands) {
+			if f.Severity >= opts.MinSeverity {
+				listed = append(listed, f)
+			}
+		}
+
+		rel, inProject := relativePath(projectPath, c.RawCommand)
+		switch {
+		case isFileChange(c.ToolName) && inProject:
+			ch := change{cmd: c, path: rel, findings: listed}
+			ch.start, ch.end = locate(c, filepath.Join(opts.Checkout, rel), opts.Diff, rel)
+			changes = append(changes, ch)
+		case len(listed) > 0:
+			for _, f := range listed {
+				findings = append(findings, fmt.Sprintf("- %s %s: %s: `%s`",
+					c.Timestamp.Format(timeFormat), f.Severity, f.Rule, firstLine(c.RawCommand)))
+			}
+		}
+	}
+
+	review := &Review{CommitID: opts.CommitID, Event: "COMMENT", Comments: []Comment{}}
+	var unlocated []string
+	for _, ch := range changes {
+		if ch.start == 0 {
+			unlocated = append(unlocated, fmt.Sprintf("- `%s`: %s", ch.path, describe(ch)))
+			continue
+		}
+		review.addComment(ch)
+	}
+	review.Body = summary(filepath.Base(projectPath), len(commands), len(changes), findings, unlocated)
+	return review, nil
+}
+
+// addComment comments on a located change, or adds to the comment already
+// on the same lines
+func (r *Review) addComment(ch change) {
+	body := describe(ch)
+	for i := range r.Comments {
+		c := &r.Comments[i]
+		if c.Path == ch.path && c.Line == ch.end && (c.StartLine == ch.start || c.StartLine == 0 && ch.start == ch.end) {
+			c.Body += "\n" + body
+			return
+		}
+	}
+	c := Comment{Path: filepath.ToSlash(ch.path), Line: ch.end, Side: "RIGHT", Body: body}
+	if ch.start < ch.end {
+		c.StartLine = ch.start
+	}
+	r.Comments = append(r.Comments, c)
+}
+
+// describe says what a change was and which findings it triggered
+func describe(ch change) string {
+	verb := map[string]string{"Edit": "edited", "Write": "wrote", "NotebookEdit": "edited the notebook"}[ch.cmd.ToolName]
+	text := fmt.Sprintf("Agent %s this at %s", verb, ch.cmd.Timestamp.Format(timeFormat))
+	if ch.cmd.IsError {
+		text += " (the tool reported an error)"
+	}
+	for _, f := range ch.findings {
+		text += fmt.Sprintf(" ⚠ %s: %s", f.Severity, f.Rule)
+	}
+	return text
+}
+
+// summary writes the review body
+func summary(project string, commands, changes int, findings, unlocated []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Agent session in %s: %d tool calls, %d file changes in the project.\n", project, commands, changes)
+	if len(findings) > 0 {
+		b.WriteString("\nSecurity findings:\n")
+		b.WriteString(strings.Join(findings, "\n"))
+		b.WriteString("\n")
+	}
+	if len(unlocated) > 0 {
+		b.WriteString("\nChanges whose lines aren't among the PR's changes (later edited or deleted, or unchanged from the base):\n")
+		b.WriteString(strings.Join(unlocated, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// isFileChange reports whether a tool changes the file named by its RawCommand
+func isFileChange(toolName string) bool {
+	return toolName == "Edit" || toolName == "Write" || toolName == "NotebookEdit"
+}
+
+// relativePath returns file relative to projectPath, and whether it is inside it
+func relativePath(projectPath, file string) (string, bool) {
+	if projectPath == "" || !filepath.IsAbs(file) {
+		return "", false
+	}
+	rel, err := filepath.Rel(projectPath, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// locate finds the lines a change produced in the checked-out file: an
+// occurrence of an Edit's new text, or for a Write the first line of the
+// file. With a diff, only lines the PR adds count, so the first occurrence
+// the PR changes is used and trimmed to its added lines. It returns 0, 0
+// when there is none, or the file or tool input can't be read.
+func locate(c *session.CommandEntry, checkoutFile string, diff Diff, path string) (start, end int) {
+	data, err := os.ReadFile(filepath.Clean(checkoutFile))
+	if err != nil || c.ToolName == "NotebookEdit" {
+		return 0, 0
+	}
+
+	var ranges [][2]int
+	if c.ToolName == "Write" {
+		ranges = [][2]int{{1, strings.Count(strings.TrimSuffix(string(data), "\n"), "\n") + 1}}
+	} else {
+		input, err := session.FetchToolInput(c.FilePath, c.LineNumber, c.ToolName, c.UUID)
+		if err != nil {
+			return 0, 0
+		}
+		text, _ := input.Parsed["new_string"].(string)
+		ranges = occurrences(string(data), text)
+	}
+
+	for _, r := range ranges {
+		start, end = r[0], r[1]
+		if diff != nil {
+			start, end = clip(diff[filepath.ToSlash(path)], start, end)
+		}
+		if start == 0 {
+			continue
+		}
+		if c.ToolName == "Write" {
+			end = start
+		}
+		return start, end
+	}
+	return 0, 0
+}
+
+// occurrences returns the line ranges of each non-overlapping occurrence of
+// text in data
+func occurrences(data, text string) [][2]int {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Count(strings.TrimSuffix(text, "\n"), "\n")
+	var ranges [][2]int
+	for offset := 0; ; {
+		idx := strings.Index(data[offset:], text)
+		if idx < 0 {
+			return ranges
+		}
+		start := strings.Count(data[:offset+idx], "\n") + 1
+		ranges = append(ranges, [2]int{start, start + lines})
+		offset += idx + len(text)
+	}
+}
+
+// clip narrows start..end to the lines a file's hunks add within it, as far
+// as the first hunk adding any (a comment can't span hunks). It returns 0, 0
+// when none are added.
+func clip(hunks []hunk, start, end int) (int, int) {
+	for _, h := range hunks {
+		first, last := 0, 0
+		for _, line := range h {
+			if line >= start && line <= end {
+				if first == 0 {
+					first = line
+				}
+				last = line
+			}
+		}
+		if first > 0 {
+			return first, last
+		}
+	}
+	return 0, 0
+}
+
+// ParseFiles reads the JSON of GitHub's "list pull request files" endpoint
+// (GET /repos/{owner}/{repo}/pulls/{pull}/files), one array per page as
+// `gh api --paginate` writes them
+func ParseFiles(r io.Reader) (Diff, error) {
+	diff := Diff{}
+	dec := json.NewDecoder(r)
+	for {
+		var files []struct {
+			Filename string `json:"filename"`
+			Patch    string `json:"patch"` // Absent for binary and very large files
+		}
+		if err := dec.Decode(&files); errors.Is(err, io.EOF) {
+			return diff, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("reading pull request files: %w", err)
+		}
+		for _, f := range files {
+			diff[f.Filename] = parsePatch(f.Patch)
+		}
+	}
+}
+
+// parsePatch returns the lines each hunk of a unified diff adds
+func parsePatch(patch string) []hunk {
+	var hunks []hunk
+	line := 0
+	for _, l := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			// "@@ -12,7 +12,8 @@ func f()"; the counts are optional
+			fields := strings.Fields(l)
+			if len(fields) < 3 {
+				continue
+			}
+			newStart, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+			line, _ = strconv.Atoi(newStart)
+			hunks = append(hunks, hunk{})
+		case len(hunks) == 0 || l == "" || strings.HasPrefix(l, "-") || strings.HasPrefix(l, "\\"):
+			// Before the first hunk, removed lines, and "\ No newline at end of file"
+		default:
+			if strings.HasPrefix(l, "+") {
+				hunks[len(hunks)-1] = append(hunks[len(hunks)-1], line)
+			}
+			line++
+		}
+	}
+	return hunks
+}
+
+// firstLine returns a command's first line
+func firstLine(s string) string {
+	first, _, _ := strings.Cut(s, "\n")
+	return first
+}
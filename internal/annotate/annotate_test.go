@@ -0,0 +1,134 @@
+package annotate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// toolUse returns a session record calling tool with input at minute m in cwd
+func toolUse(m int, cwd, tool, input string) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":"2026-01-02T10:%02d:00Z","uuid":"u%d","sessionId":"s1","cwd":%q,`+
+		`"message":{"role":"assistant","content":[{"type":"tool_use","id":"t%d","name":%q,"input":%s}]}}`+"\n", m, m, cwd, m, tool, input)
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "repo")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "notes.md"), []byte("# Notes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "s1.jsonl")
+	data := toolUse(0, project, "Edit", fmt.Sprintf(`{"file_path":%q,"old_string":"x","new_string":"func main() {\n\tprintln(\"hi\")\n"}`, filepath.Join(project, "main.go"))) +
+		toolUse(1, project, "Write", fmt.Sprintf(`{"file_path":%q,"content":"# Notes\n"}`, filepath.Join(project, "notes.md"))) +
+		toolUse(2, project, "Bash", `{"command":"rm -rf build"}`) +
+		toolUse(3, project, "Edit", fmt.Sprintf(`{"file_path":%q,"old_string":"a","new_string":"b"}`, filepath.Join(project, "gone.go")))
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	config.SetGlobal(cfg)
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	review, err := Build(path, Options{Config: cfg, MinSeverity: security.SeverityMedium, Checkout: project})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(review.Comments) != 2 {
+		t.Fatalf("expected comments on main.go and notes.md, got %+v", review.Comments)
+	}
+	if c := review.Comments[0]; c.Path != "main.go" || c.StartLine != 3 || c.Line != 4 || c.Side != "RIGHT" {
+		t.Errorf("expected the edit on main.go lines 3-4, got %+v", c)
+	}
+	if c := review.Comments[1]; c.Path != "notes.md" || c.StartLine != 0 || c.Line != 1 || !strings.HasPrefix(c.Body, "Agent wrote this") {
+		t.Errorf("expected the write on notes.md line 1, got %+v", c)
+	}
+	if !strings.Contains(review.Body, "rm -rf build") {
+		t.Errorf("expected the rm -rf finding in the body, got %q", review.Body)
+	}
+	if !strings.Contains(review.Body, "- `gone.go`: Agent edited this at 10:03:00") {
+		t.Errorf("expected gone.go listed as unlocated, got %q", review.Body)
+	}
+
+	// Findings below the minimum severity aren't listed
+	review, err = Build(path, Options{Config: cfg, MinSeverity: security.SeverityHigh + 1, Checkout: project})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(review.Body, "Security findings") {
+		t.Errorf("expected no findings above high, got %q", review.Body)
+	}
+}
+
+func TestBuildWithDiff(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "repo")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// The edit's text appears twice; only the second copy is new in the PR
+	src := "package main\n\nfunc a() {}\n\nfunc a() {}\n"
+	if err := os.WriteFile(filepath.Join(project, "main.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "notes.md"), []byte("# Notes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "s1.jsonl")
+	data := toolUse(0, project, "Edit", fmt.Sprintf(`{"file_path":%q,"old_string":"x","new_string":"func a() {}\n"}`, filepath.Join(project, "main.go"))) +
+		toolUse(1, project, "Write", fmt.Sprintf(`{"file_path":%q,"content":"# Notes\n"}`, filepath.Join(project, "notes.md")))
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two pages, as gh api --paginate writes them; notes.md isn't in the PR
+	files := `[{"filename":"main.go","patch":"@@ -1,3 +1,5 @@\n package main\n \n func a() {}\n+\n+func a() {}"}]` +
+		`[{"filename":"logo.png"}]`
+	diff, err := ParseFiles(strings.NewReader(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	review, err := Build(path, Options{Config: cfg, MinSeverity: security.SeverityMedium, Checkout: project, Diff: diff})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(review.Comments) != 1 {
+		t.Fatalf("expected one comment, got %+v", review.Comments)
+	}
+	if c := review.Comments[0]; c.Path != "main.go" || c.Line != 5 || c.StartLine != 0 {
+		t.Errorf("expected the edit on the added main.go line 5, got %+v", c)
+	}
+	if !strings.Contains(review.Body, "- `notes.md`: Agent wrote this") {
+		t.Errorf("expected notes.md listed in the body, got %q", review.Body)
+	}
+}
+
+func TestParsePatch(t *testing.T) {
+	patch := "@@ -1,2 +1,3 @@\n a\n-b\n+c\n+d\n\\ No newline at end of file\n@@ -10 +11,2 @@ func f()\n x\n+y"
+	hunks := parsePatch(patch)
+	if len(hunks) != 2 || fmt.Sprint(hunks[0]) != "[2 3]" || fmt.Sprint(hunks[1]) != "[12]" {
+		t.Errorf("unexpected hunks: %v", hunks)
+	}
+	if start, end := clip(hunks, 1, 20); start != 2 || end != 3 {
+		t.Errorf("expected 1-20 clipped to the first hunk's 2-3, got %d-%d", start, end)
+	}
+	if start, _ := clip(hunks, 4, 11); start != 0 {
+		t.Errorf("expected no added lines in 4-11, got %d", start)
+	}
+}
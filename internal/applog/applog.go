@@ -0,0 +1,101 @@
+// Package applog keeps the monitor's own recent warnings and notable events
+// (watcher errors, dropped events, skipped lines, alert deliveries) in
+// memory for the TUI's log pane, and passes each to the standard logger,
+// which --log-file points at a file.
+package applog
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Level is an entry's severity
+type Level int
+
+const (
+	Info Level = iota
+	Warn
+)
+
+func (l Level) String() string {
+	if l == Warn {
+		return "warn"
+	}
+	return "info"
+}
+
+// Entry is one logged line
+type Entry struct {
+	Seq   uint64 // Increases by one per entry, from 1
+	Time  time.Time
+	Level Level
+	Text  string
+}
+
+// capacity is how many entries are kept; older ones are dropped
+const capacity = 200
+
+var (
+	mu      sync.Mutex
+	entries []Entry // Oldest first, at most capacity
+	seq     uint64
+)
+
+// Infof records a notable event
+func Infof(format string, args ...any) {
+	add(Info, fmt.Sprintf(format, args...))
+}
+
+// Warnf records a problem the monitor carried on from
+func Warnf(format string, args ...any) {
+	add(Warn, fmt.Sprintf(format, args...))
+}
+
+func add(level Level, text string) {
+	mu.Lock()
+	seq++
+	if len(entries) == capacity {
+		entries = append(entries[:0], entries[1:]...)
+	}
+	entries = append(entries, Entry{Seq: seq, Time: time.Now(), Level: level, Text: text})
+	mu.Unlock()
+	log.Printf("%s: %s", level, text)
+}
+
+// Recent returns the last n entries, oldest first
+func Recent(n int) []Entry {
+	mu.Lock()
+	defer mu.Unlock()
+	n = min(max(n, 0), len(entries))
+	return append([]Entry(nil), entries[len(entries)-n:]...)
+}
+
+// Last returns the Seq of the latest entry, 0 before any
+func Last() uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return seq
+}
+
+// WarningsSince counts the warnings still kept that were logged after the
+// entry with Seq after
+func WarningsSince(after uint64) int {
+	mu.Lock()
+	defer mu.Unlock()
+	n := 0
+	for _, e := range entries {
+		if e.Seq > after && e.Level == Warn {
+			n++
+		}
+	}
+	return n
+}
+
+// reset drops every entry, for tests
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	entries, seq = nil, 0
+}
@@ -0,0 +1,40 @@
+package applog
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	log.SetOutput(io.Discard)
+	reset()
+
+	if Last() != 0 || len(Recent(5)) != 0 {
+		t.Fatal("expected no entries")
+	}
+	Infof("started %d", 1)
+	Warnf("watcher: %v", "gone")
+	got := Recent(5)
+	if len(got) != 2 || got[0].Text != "started 1" || got[1].Level != Warn || got[1].Seq != 2 {
+		t.Errorf("Recent = %+v", got)
+	}
+	if WarningsSince(0) != 1 || WarningsSince(Last()) != 0 {
+		t.Errorf("WarningsSince: %d after 0, %d after %d", WarningsSince(0), WarningsSince(Last()), Last())
+	}
+
+	for i := range capacity {
+		Infof("line %d", i)
+	}
+	got = Recent(capacity + 10)
+	if len(got) != capacity || got[0].Text != "line 0" || got[capacity-1].Text != fmt.Sprintf("line %d", capacity-1) {
+		t.Errorf("expected the oldest entries dropped, got %d from %q", len(got), got[0].Text)
+	}
+	if WarningsSince(0) != 0 {
+		t.Error("expected the dropped warning not counted")
+	}
+	if got := Recent(1); len(got) != 1 || got[0].Seq != Last() {
+		t.Errorf("Recent(1) = %+v, want the latest", got)
+	}
+}
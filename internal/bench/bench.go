@@ -0,0 +1,174 @@
+// Package bench measures how fast the session parser gets through a corpus
+// of JSONL files, so performance regressions in the parser show up as
+// numbers: lines per second, allocations, and peak memory.
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+// Result is what parsing a corpus some number of times measured
+type Result struct {
+	Files      int
+	Iterations int
+	Lines      int64 // Per iteration
+	Bytes      int64 // Per iteration
+	Commands   int64 // Per iteration
+	Elapsed    time.Duration
+	Allocs     uint64 // Heap allocations over all iterations
+	AllocBytes uint64 // Bytes allocated over all iterations
+	PeakRSS    int64  // Peak resident set size of the process, in bytes (0 where unsupported)
+}
+
+// Corpus expands files and directories (searched recursively) into the
+// session files to parse
+func Corpus(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && filepath.Ext(p) == ".jsonl" {
+				files = append(files, p)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .jsonl files in %v", paths)
+	}
+	return files, nil
+}
+
+// Run parses every file iterations times with the full-file parser, timing
+// the parses and counting their allocations
+func Run(files []string, iterations int) (*Result, error) {
+	r := &Result{Files: len(files), Iterations: iterations}
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Clean(f))
+		if err != nil {
+			return nil, err
+		}
+		r.Bytes += int64(len(data))
+		r.Lines += int64(bytes.Count(data, []byte("\n")))
+	}
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := range iterations {
+		for _, f := range files {
+			commands, _, err := session.ParseSessionFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", f, err)
+			}
+			if i == 0 {
+				r.Commands += int64(len(commands))
+			}
+		}
+	}
+	r.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	r.Allocs = after.Mallocs - before.Mallocs
+	r.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	r.PeakRSS = peakRSS()
+	return r, nil
+}
+
+// LinesPerSec is the parse throughput
+func (r *Result) LinesPerSec() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Lines*int64(r.Iterations)) / r.Elapsed.Seconds()
+}
+
+// Write prints the result as a short report
+func (r *Result) Write(w io.Writer) {
+	parses := int64(r.Iterations) * r.Lines
+	fmt.Fprintf(w, "corpus:      %d files, %d lines, %s, %d commands\n", r.Files, r.Lines, formatBytes(uint64(r.Bytes)), r.Commands)
+	fmt.Fprintf(w, "iterations:  %d in %s\n", r.Iterations, r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "throughput:  %.0f lines/sec, %s/sec\n", r.LinesPerSec(),
+		formatBytes(uint64(float64(r.Bytes*int64(r.Iterations))/max(r.Elapsed.Seconds(), 1e-9))))
+	if parses > 0 {
+		fmt.Fprintf(w, "allocations: %d (%.1f/line), %s (%s/line)\n", r.Allocs, float64(r.Allocs)/float64(parses),
+			formatBytes(r.AllocBytes), formatBytes(r.AllocBytes/uint64(parses)))
+	}
+	if r.PeakRSS > 0 {
+		fmt.Fprintf(w, "peak RSS:    %s\n", formatBytes(uint64(r.PeakRSS)))
+	}
+}
+
+// formatBytes renders a size with a binary unit, e.g. "3.2 MiB"
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// StartProfiles starts a CPU profile written to cpuPath and arranges for an
+// allocation profile written to memPath; either may be empty. The returned
+// stop function finishes both.
+func StartProfiles(cpuPath, memPath string) (func() error, error) {
+	var cpu *os.File
+	if cpuPath != "" {
+		f, err := os.Create(filepath.Clean(cpuPath))
+		if err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		cpu = f
+	}
+
+	return func() error {
+		if cpu != nil {
+			pprof.StopCPUProfile()
+			if err := cpu.Close(); err != nil {
+				return err
+			}
+		}
+		if memPath == "" {
+			return nil
+		}
+		f, err := os.Create(filepath.Clean(memPath))
+		if err != nil {
+			return err
+		}
+		if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}, nil
+}
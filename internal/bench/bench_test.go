@@ -0,0 +1,105 @@
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestRunMeasuresGeneratedCorpus(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 2 {
+		if err := GenerateFixture(filepath.Join(dir, "project", fmt.Sprintf("session-%d.jsonl", i)), 100, uint64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := Corpus([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 fixture files, got %v", files)
+	}
+	r, err := Run(files, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Commands != 200 || r.Lines < 400 || r.Allocs == 0 {
+		t.Errorf("unexpected result: %+v", r)
+	}
+
+	var out strings.Builder
+	r.Write(&out)
+	if !strings.Contains(out.String(), "lines/sec") {
+		t.Errorf("report missing throughput:\n%s", out.String())
+	}
+}
+
+func TestGenerateFixtureIsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.jsonl"), filepath.Join(dir, "b.jsonl")
+	for _, path := range []string{a, b} {
+		if err := GenerateFixture(path, 600, 7); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commandsA, metaA, err := session.ParseSessionFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commandsB, _, _ := session.ParseSessionFile(b)
+	if len(commandsA) != 600 || len(commandsB) != 600 {
+		t.Fatalf("expected 600 commands each, got %d and %d", len(commandsA), len(commandsB))
+	}
+	if commandsA[599].RawCommand != commandsB[599].RawCommand {
+		t.Error("expected the same seed to generate the same commands")
+	}
+	if len(metaA.Markers) != 1 || metaA.Thinking.Blocks == 0 {
+		t.Errorf("expected a compaction and thinking blocks, got %d markers, %+v", len(metaA.Markers), metaA.Thinking)
+	}
+}
+
+// BenchmarkParseSessionFile parses a generated 5,000-call session
+func BenchmarkParseSessionFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "session.jsonl")
+	if err := GenerateFixture(path, 5000, 1); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if _, _, err := session.ParseSessionFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseSessionFileFrom parses the last tool call of a generated
+// session from its offset, as the watcher does on each write
+func BenchmarkParseSessionFileFrom(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "session.jsonl")
+	if err := GenerateFixture(path, 200, 1); err != nil {
+		b.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	// Start after all but the last tool_use and tool_result lines
+	lines := bytes.Count(data, []byte("\n"))
+	offset := bytes.LastIndexByte(data[:len(data)-1], '\n')
+	offset = bytes.LastIndexByte(data[:offset], '\n') + 1
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if _, _, _, _, err := session.ParseSessionFileFrom(path, int64(offset), lines-2); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package bench
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fixtureBash are the commands generated Bash calls run
+var fixtureBash = []string{
+	"go test ./...", "git status", "git diff --stat", "npm run build", "ls -la",
+	"rm -rf dist", "docker compose up -d", "kubectl get pods -n prod",
+	"cat <<'EOF' > notes.md\n# Notes\n\n- one\n- two\nEOF",
+}
+
+// fixtureFiles are the paths generated file tool calls touch
+var fixtureFiles = []string{
+	"/home/bench/app/main.go", "/home/bench/app/internal/server/handler.go",
+	"/home/bench/app/README.md", "/home/bench/app/web/src/App.tsx",
+}
+
+// GenerateFixture writes a synthetic session of calls tool calls to path:
+// a mix of Bash, Read, Edit, Write, and Grep calls with results, thinking
+// blocks, large outputs, failures, and an occasional compaction, so parsing
+// it exercises the same paths a long real session does. The same seed gives
+// the same file.
+func GenerateFixture(path string, calls int, seed uint64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	g := fixtureGen{
+		rng:       rand.New(rand.NewPCG(seed, 0)), //nolint:gosec // synthetic data
+		enc:       json.NewEncoder(w),
+		sessionID: fmt.Sprintf("bench-%d", seed),
+		ts:        time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC),
+	}
+	for i := 0; i < calls && g.err == nil; i++ {
+		g.toolCall(i)
+	}
+	if g.err != nil {
+		_ = f.Close()
+		return g.err
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// fixtureGen writes one fixture's records
+type fixtureGen struct {
+	rng       *rand.Rand
+	enc       *json.Encoder
+	sessionID string
+	ts        time.Time
+	lastUUID  string
+	err       error
+}
+
+// toolCall writes the i-th tool call and its result, with the records
+// that sometimes surround one
+func (g *fixtureGen) toolCall(i int) {
+	if i > 0 && i%500 == 0 {
+		g.write(map[string]any{
+			"type": "system", "subtype": "compact_boundary", "content": "Conversation compacted",
+			"compactMetadata": map[string]any{"trigger": "auto", "preTokens": 150000 + g.rng.IntN(10000)},
+		})
+	}
+
+	var content []map[string]any
+	if g.rng.IntN(3) == 0 {
+		content = append(content, map[string]any{"type": "thinking", "thinking": g.text(40 + g.rng.IntN(200))})
+	}
+	id := fmt.Sprintf("toolu_%08d", i)
+	name, input, result := g.randomCall()
+	content = append(content, map[string]any{"type": "tool_use", "id": id, "name": name, "input": input})
+	g.write(map[string]any{"type": "assistant", "message": map[string]any{"role": "assistant", "content": content}})
+
+	toolResult := map[string]any{"type": "tool_result", "tool_use_id": id, "content": result}
+	if g.rng.IntN(20) == 0 {
+		toolResult["is_error"] = true
+	}
+	g.write(map[string]any{"type": "user", "message": map[string]any{"role": "user", "content": []any{toolResult}}})
+}
+
+// randomCall picks a tool call with its input and result
+func (g *fixtureGen) randomCall() (name string, input map[string]any, result string) {
+	file := fixtureFiles[g.rng.IntN(len(fixtureFiles))]
+	switch g.rng.IntN(6) { //nolint:mnd // number of cases below
+	case 0:
+		return "Read", map[string]any{"file_path": file}, g.text(200 + g.rng.IntN(4000))
+	case 1:
+		return "Edit", map[string]any{
+			"file_path": file, "old_string": g.text(60), "new_string": g.text(80),
+		}, "The file " + file + " has been updated."
+	case 2:
+		return "Write", map[string]any{"file_path": file, "content": g.text(500 + g.rng.IntN(2000))},
+			"File created successfully at: " + file
+	case 3:
+		return "Grep", map[string]any{"pattern": "TODO", "path": filepath.Dir(file)}, g.text(100)
+	default:
+		cmd := fixtureBash[g.rng.IntN(len(fixtureBash))]
+		return "Bash", map[string]any{"command": cmd, "description": "Run " + strings.Fields(cmd)[0]},
+			g.text(50 + g.rng.IntN(3000))
+	}
+}
+
+// text returns about n bytes of filler lines
+func (g *fixtureGen) text(n int) string {
+	words := []string{"ok", "build", "package", "func", "return", "error", "test", "PASS", "main", "handler"}
+	var b strings.Builder
+	for b.Len() < n {
+		b.WriteString(words[g.rng.IntN(len(words))])
+		if g.rng.IntN(8) == 0 {
+			b.WriteByte('\n')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// write stamps a record with the fields every line has and encodes it
+func (g *fixtureGen) write(record map[string]any) {
+	if g.err != nil {
+		return
+	}
+	g.ts = g.ts.Add(time.Duration(1+g.rng.IntN(30)) * time.Second)
+	uuid := fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", g.rng.Uint32(), g.rng.Uint32()&0xffff,
+		g.rng.Uint32()&0xffff, g.rng.Uint32()&0xffff, g.rng.Uint64()&0xffffffffffff)
+	record["uuid"] = uuid
+	record["parentUuid"] = g.lastUUID
+	if g.lastUUID == "" {
+		record["parentUuid"] = nil
+	}
+	record["sessionId"] = g.sessionID
+	record["timestamp"] = g.ts.Format(time.RFC3339)
+	record["cwd"] = "/home/bench/app"
+	record["gitBranch"] = "main"
+	g.lastUUID = uuid
+	g.err = g.enc.Encode(record)
+}
@@ -0,0 +1,8 @@
+//go:build windows || plan9
+
+package bench
+
+// peakRSS reports nothing on platforms without getrusage
+func peakRSS() int64 {
+	return 0
+}
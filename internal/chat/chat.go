@@ -0,0 +1,182 @@
+// Package chat renders notifications as Slack (Block Kit) and Discord (embed)
+// webhook payloads, color-coded by severity.
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/security"
+)
+
+// Message is one notification: an alert, or a section of a digest
+type Message struct {
+	Title     string
+	URL       string // Link for the title (e.g. the web dashboard), optional
+	Text      string // Body in Slack/Discord-compatible markdown
+	Severity  security.Severity
+	Neutral   bool // Use the neutral color instead of the severity's (digest summaries)
+	Fields    []Field
+	Timestamp time.Time
+}
+
+// Field is a short name/value pair shown alongside the message
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Colors for message accents (catppuccin mocha)
+const (
+	colorHigh    = 0xf38ba8 // red
+	colorMedium  = 0xfab387 // peach
+	colorLow     = 0xf9e2af // yellow
+	colorNeutral = 0x89b4fa // blue
+)
+
+// color returns the accent color for a message
+func (m Message) color() int {
+	switch {
+	case m.Neutral:
+		return colorNeutral
+	case m.Severity == security.SeverityHigh:
+		return colorHigh
+	case m.Severity == security.SeverityMedium:
+		return colorMedium
+	default:
+		return colorLow
+	}
+}
+
+// Discord limits embeds per message and text per description
+const (
+	discordMaxEmbeds      = 10
+	discordMaxDescription = 4096
+	slackMaxText          = 3000
+)
+
+// SlackPayload renders messages as an incoming-webhook payload: one colored
+// attachment of blocks per message, with plain text for notifications
+func SlackPayload(msgs ...Message) map[string]any {
+	attachments := make([]map[string]any, 0, len(msgs))
+	var fallback []string
+	for _, m := range msgs {
+		fallback = append(fallback, m.Title)
+
+		title := "*" + m.Title + "*"
+		if m.URL != "" {
+			title = "*<" + m.URL + "|" + m.Title + ">*"
+		}
+		text := title
+		if m.Text != "" {
+			text += "\n" + m.Text
+		}
+		blocks := []map[string]any{{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": clip(text, slackMaxText)},
+		}}
+		if len(m.Fields) > 0 {
+			fields := make([]map[string]any, len(m.Fields))
+			for i, f := range m.Fields {
+				fields[i] = map[string]any{"type": "mrkdwn", "text": "*" + f.Name + "*\n" + f.Value}
+			}
+			blocks = append(blocks, map[string]any{"type": "section", "fields": fields})
+		}
+		if !m.Timestamp.IsZero() {
+			blocks = append(blocks, map[string]any{
+				"type":     "context",
+				"elements": []map[string]any{{"type": "mrkdwn", "text": m.Timestamp.Format("Jan 02 15:04:05")}},
+			})
+		}
+		attachments = append(attachments, map[string]any{
+			"color":  "#" + strconv.FormatInt(int64(m.color()), 16),
+			"blocks": blocks,
+		})
+	}
+	return map[string]any{"text": strings.Join(fallback, "; "), "attachments": attachments}
+}
+
+// DiscordPayload renders messages as a webhook payload of colored embeds.
+// Discord accepts at most ten embeds; later messages are dropped.
+func DiscordPayload(msgs ...Message) map[string]any {
+	if len(msgs) > discordMaxEmbeds {
+		msgs = msgs[:discordMaxEmbeds]
+	}
+	embeds := make([]map[string]any, 0, len(msgs))
+	for _, m := range msgs {
+		embed := map[string]any{
+			"title":       m.Title,
+			"description": clip(m.Text, discordMaxDescription),
+			"color":       m.color(),
+		}
+		if m.URL != "" {
+			embed["url"] = m.URL
+		}
+		if len(m.Fields) > 0 {
+			fields := make([]map[string]any, len(m.Fields))
+			for i, f := range m.Fields {
+				fields[i] = map[string]any{"name": f.Name, "value": f.Value, "inline": true}
+			}
+			embed["fields"] = fields
+		}
+		if !m.Timestamp.IsZero() {
+			embed["timestamp"] = m.Timestamp.Format(time.RFC3339)
+		}
+		embeds = append(embeds, embed)
+	}
+	return map[string]any{"embeds": embeds}
+}
+
+// clip truncates s to at most n bytes, marking the cut
+func clip(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
+
+// CodeBlock wraps a command for display, collapsing it to its first line
+func CodeBlock(command string) string {
+	first, _, multi := strings.Cut(command, "\n")
+	if multi {
+		first += " …"
+	}
+	return "`" + strings.ReplaceAll(first, "`", "'") + "`"
+}
+
+// Post sends a payload to a webhook URL as JSON
+func Post(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SessionURL links to a session in the web dashboard at base, or "" without a dashboard
+func SessionURL(base, sessionID string) string {
+	if base == "" {
+		return ""
+	}
+	return strings.TrimSuffix(base, "/") + "/#session=" + sessionID
+}
@@ -0,0 +1,89 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/security"
+)
+
+func TestSlackPayload(t *testing.T) {
+	payload := SlackPayload(Message{
+		Title:     "Recursive file deletion",
+		URL:       "http://localhost:8080/#session=abc",
+		Text:      "`rm -rf build`",
+		Severity:  security.SeverityHigh,
+		Fields:    []Field{{Name: "Project", Value: "alpha"}},
+		Timestamp: time.Now(),
+	})
+
+	if payload["text"] != "Recursive file deletion" {
+		t.Errorf("unexpected fallback text %v", payload["text"])
+	}
+	attachments := payload["attachments"].([]map[string]any)
+	if len(attachments) != 1 || attachments[0]["color"] != "#f38ba8" {
+		t.Fatalf("expected one red attachment, got %+v", attachments)
+	}
+	blocks := attachments[0]["blocks"].([]map[string]any)
+	if len(blocks) != 3 {
+		t.Fatalf("expected text, fields, and context blocks, got %+v", blocks)
+	}
+	text := blocks[0]["text"].(map[string]any)["text"]
+	if text != "*<http://localhost:8080/#session=abc|Recursive file deletion>*\n`rm -rf build`" {
+		t.Errorf("unexpected section text %q", text)
+	}
+}
+
+func TestDiscordPayload(t *testing.T) {
+	msgs := make([]Message, 12)
+	for i := range msgs {
+		msgs[i] = Message{Title: "digest", Neutral: true}
+	}
+	msgs[0] = Message{Title: "Force push", Severity: security.SeverityMedium, URL: "http://dash"}
+
+	embeds := DiscordPayload(msgs...)["embeds"].([]map[string]any)
+	if len(embeds) != discordMaxEmbeds {
+		t.Errorf("expected embeds capped at %d, got %d", discordMaxEmbeds, len(embeds))
+	}
+	if embeds[0]["color"] != colorMedium || embeds[0]["url"] != "http://dash" || embeds[1]["color"] != colorNeutral {
+		t.Errorf("unexpected embeds %+v", embeds[:2])
+	}
+}
+
+func TestPost(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	if err := Post(context.Background(), srv.URL, map[string]any{"text": "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if got["text"] != "hi" {
+		t.Errorf("unexpected payload %+v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	if err := Post(context.Background(), failing.URL, nil); err == nil {
+		t.Error("expected an error for a non-2xx response")
+	}
+}
+
+func TestSessionURL(t *testing.T) {
+	if got := SessionURL("http://localhost:8080/", "abc"); got != "http://localhost:8080/#session=abc" {
+		t.Errorf("SessionURL = %q", got)
+	}
+	if got := SessionURL("", "abc"); got != "" {
+		t.Errorf("expected no link without a dashboard, got %q", got)
+	}
+}
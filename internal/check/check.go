@@ -0,0 +1,130 @@
+// Package check gates CI on the commands an agent ran: it parses session
+// files and reports the commands that violate the config's check rules.
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+)
+
+// RuleDenied names violations from check.deny patterns
+const RuleDenied = "Denied pattern"
+
+// Options configures a check
+type Options struct {
+	Config      *config.Config    // Rules to check against; nil uses the global config
+	FailOn      security.Severity // Lowest finding severity that is a violation
+	ProjectPath string            // Where the session may write; "" uses its first working directory
+}
+
+// Violation is one rule a command breaks
+type Violation struct {
+	File      string            `json:"file"`
+	Line      int               `json:"line"`
+	Timestamp time.Time         `json:"timestamp"`
+	Tool      string            `json:"tool"`
+	Command   string            `json:"command"`
+	Rule      string            `json:"rule"`
+	Severity  security.Severity `json:"severity"`
+}
+
+// FailOn returns the severity cfg's check.fail_on names, high when unset
+func FailOn(cfg *config.Config) (security.Severity, error) {
+	if cfg.Check.FailOn == "" {
+		return security.SeverityHigh, nil
+	}
+	return security.ParseSeverity(cfg.Check.FailOn)
+}
+
+// File checks the commands in a session file and its subagent transcripts,
+// in the order they ran
+func File(path string, opts Options) ([]Violation, error) {
+	if opts.Config == nil {
+		opts.Config = config.Global()
+	}
+
+	commands, meta, err := session.ParseSessionFileUnfiltered(path)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	subagents, _ := filepath.Glob(filepath.Join(filepath.Dir(path), id, "subagents", "*.jsonl"))
+	for _, sub := range subagents {
+		subCommands, _, err := session.ParseSessionFileUnfiltered(sub)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, subCommands...)
+	}
+	sort.SliceStable(commands, func(i, j int) bool { return commands[i].Timestamp.Before(commands[j].Timestamp) })
+
+	projectPath := opts.ProjectPath
+	if projectPath == "" {
+		projectPath = meta.CWD
+	}
+
+	var violations []Violation
+	for i := range commands {
+		c := &commands[i]
+		add := func(rule string, sev security.Severity) {
+			violations = append(violations, Violation{
+				File:      c.FilePath,
+				Line:      c.LineNumber,
+				Timestamp: c.Timestamp,
+				Tool:      c.ToolName,
+				Command:   c.RawCommand,
+				Rule:      rule,
+				Severity:  sev,
+			})
+		}
+		if p := opts.Config.Check.Denied(c.Pattern); p != "" {
+			add(RuleDenied+" "+p, security.SeverityHigh)
+		}
+		for _, f := range security.CommandFindings(c, projectPath, opts.Config, commands) {
+			if f.Severity >= opts.FailOn {
+				add(f.Rule, f.Severity)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// Write renders violations to w as "text" (one line each, file:line first),
+// "json", "junit" (JUnit XML), "checkstyle" (Checkstyle XML), or "sarif"
+// (SARIF 2.1.0, for GitHub code scanning)
+func Write(w io.Writer, violations []Violation, format string) error {
+	switch format {
+	case "", "text":
+		for _, v := range violations {
+			first, _, _ := strings.Cut(v.Command, "\n")
+			if _, err := fmt.Fprintf(w, "%s:%d: %s: %s: %s %s\n", v.File, v.Line, v.Severity, v.Rule, v.Tool, first); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		if violations == nil {
+			violations = []Violation{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(violations)
+	case "junit":
+		return writeJUnit(w, violations)
+	case "checkstyle":
+		return writeCheckstyle(w, violations)
+	case "sarif":
+		return writeSARIF(w, violations)
+	default:
+		return fmt.Errorf("unknown format %q (want text, json, junit, checkstyle, or sarif)", format)
+	}
+}
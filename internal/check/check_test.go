@@ -0,0 +1,185 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// toolUse returns a session record calling tool with input at minute m
+func toolUse(m int, tool, input string) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":"2026-01-02T10:%02d:00Z","uuid":"u%d","sessionId":"s1","cwd":"/repo",`+
+		`"message":{"role":"assistant","content":[{"type":"tool_use","id":"t%d","name":%q,"input":%s}]}}`+"\n", m, m, m, tool, input)
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "s1.jsonl")
+	data := toolUse(0, "Bash", `{"command":"go test ./..."}`) +
+		toolUse(1, "Bash", `{"command":"git push origin main"}`) +
+		toolUse(3, "Write", `{"file_path":"/srv/shared/notes.txt","content":"x"}`)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Subagent commands count too, in the order they ran
+	subagents := filepath.Join(dir, "s1", "subagents")
+	if err := os.MkdirAll(subagents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(subagents, "agent-1.jsonl"), []byte(toolUse(2, "Bash", `{"command":"chmod 777 run.sh"}`)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Commands the config hides are still checked
+	cfg := config.DefaultConfig()
+	cfg.Check.Deny = []string{"Bash(git:push:*)"}
+	cfg.ToolGroups = append([]config.ToolGroup{{Name: "git", Patterns: []string{"Bash(git:*)"}, Exclude: true}}, cfg.ToolGroups...)
+	config.SetGlobal(cfg)
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	violations, err := File(path, Options{Config: cfg, FailOn: security.SeverityHigh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rules []string
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	if got := strings.Join(rules, "; "); got != "Denied pattern Bash(git:push:*); Writes outside the project" {
+		t.Errorf("expected the denied push and the write outside /repo, got %q", got)
+	}
+
+	violations, err = File(path, Options{Config: cfg, FailOn: security.SeverityMedium})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 3 || violations[1].Tool != "Bash" || violations[1].Command != "chmod 777 run.sh" {
+		t.Errorf("expected the subagent's chmod at medium severity, got %+v", violations)
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, violations[:1], "text"); err != nil {
+		t.Fatal(err)
+	}
+	if want := path + ":2: high: Denied pattern Bash(git:push:*): Bash git push origin main\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestHiddenCode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "s1.jsonl")
+	// Deletions hidden in a script the session wrote before running it, and
+	// in inline interpreter code
+	data := toolUse(0, "Write", `{"file_path":"/repo/clean.sh","content":"#!/bin/sh\nrm -rf ~/old\n"}`) +
+		toolUse(1, "Bash", `{"command":"bash clean.sh"}`) +
+		toolUse(2, "Bash", `{"command":"python3 -c 'import shutil; shutil.rmtree(\"/home/me/old\")'"}`) +
+		toolUse(3, "Bash", `{"command":"python3 -c 'print(1)'"}`)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	violations, err := File(path, Options{Config: config.DefaultConfig(), FailOn: security.SeverityHigh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, fmt.Sprintf("%d %s", v.Line, v.Rule))
+	}
+	if want := "2 Recursive file deletion; 3 Code deletes directory trees"; strings.Join(got, "; ") != want {
+		t.Errorf("expected the script's and the inline code's deletions, got %q", got)
+	}
+}
+
+func TestWriteReports(t *testing.T) {
+	violations := []Violation{
+		{File: "/ci/a.jsonl", Line: 2, Tool: "Bash", Command: "git push origin main", Rule: "Denied pattern Bash(git:push:*)", Severity: security.SeverityHigh},
+		{File: "/ci/a.jsonl", Line: 5, Tool: "Bash", Command: "chmod 777 run.sh\necho done", Rule: "Permission change", Severity: security.SeverityMedium},
+		{File: "/ci/b.jsonl", Line: 1, Tool: "Bash", Command: "curl example.com", Rule: "Network access", Severity: security.SeverityLow},
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, violations, "junit"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuites name="cc_session_mon check" tests="3" failures="3">`,
+		`<testsuite name="/ci/a.jsonl" tests="2" failures="2">`,
+		`<testcase name="line 5: Bash chmod 777 run.sh" classname="a">`,
+		`<failure message="Permission change" type="medium">`,
+		`<testsuite name="/ci/b.jsonl" tests="1" failures="1">`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %s in the JUnit report:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := Write(&out, violations, "checkstyle"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<file name="/ci/a.jsonl">`,
+		`<error line="2" severity="error" message="Denied pattern Bash(git:push:*): Bash git push origin main" source="cc_session_mon.check"></error>`,
+		`<error line="5" severity="warning"`,
+		`<error line="1" severity="info"`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %s in the Checkstyle report:\n%s", want, out.String())
+		}
+	}
+
+	// A clean run is still a well-formed report
+	out.Reset()
+	if err := Write(&out, nil, "junit"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `<testsuites name="cc_session_mon check" tests="0" failures="0"></testsuites>`) {
+		t.Errorf("expected an empty JUnit report, got %s", out.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	violations := []Violation{
+		{File: "sessions/a.jsonl", Line: 2, Tool: "Bash", Command: "git push origin main", Rule: "Denied pattern Bash(git:push:*)", Severity: security.SeverityHigh},
+		{File: "/ci/a.jsonl", Line: 5, Tool: "Bash", Command: "chmod 777 run.sh", Rule: "Permission change", Severity: security.SeverityMedium},
+		{File: "/ci/a.jsonl", Line: 6, Tool: "Bash", Command: "git push -f", Rule: "Denied pattern Bash(git:push:*)", Severity: security.SeverityHigh},
+	}
+	var out bytes.Buffer
+	if err := Write(&out, violations, "sarif"); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("expected one SARIF 2.1.0 run, got %s", out.String())
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "denied-pattern" || run.Tool.Driver.Rules[1].ID != "permission-change" {
+		t.Errorf("expected a rule per finding kind, got %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("expected a result per violation, got %+v", run.Results)
+	}
+	if r := run.Results[0]; r.Level != "error" || r.RuleIndex != 0 || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "sessions/a.jsonl" {
+		t.Errorf("expected a relative location at error level, got %+v", r)
+	}
+	if r := run.Results[1]; r.Level != "warning" || r.RuleIndex != 1 || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "file:///ci/a.jsonl" ||
+		r.Locations[0].PhysicalLocation.Region.StartLine != 5 {
+		t.Errorf("expected a file: URI at line 5, got %+v", r)
+	}
+	if r := run.Results[2]; r.RuleIndex != 0 || r.Message.Text != "Denied pattern Bash(git:push:*): Bash git push -f" {
+		t.Errorf("expected the second push under the shared rule, got %+v", r)
+	}
+}
@@ -0,0 +1,312 @@
+package check
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"cc_session_mon/internal/security"
+)
+
+// junitSuites is a JUnit XML report: a suite per session file and a failed
+// test case per violation
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// checkstyleReport is a Checkstyle XML report: an error per violation,
+// under the session file it was found in
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// byFile groups violations by session file, in the order files first appear
+func byFile(violations []Violation) (files []string, groups map[string][]Violation) {
+	groups = make(map[string][]Violation)
+	for _, v := range violations {
+		if _, ok := groups[v.File]; !ok {
+			files = append(files, v.File)
+		}
+		groups[v.File] = append(groups[v.File], v)
+	}
+	return files, groups
+}
+
+// writeJUnit renders violations as JUnit XML
+func writeJUnit(w io.Writer, violations []Violation) error {
+	report := junitSuites{Name: "cc_session_mon check", Tests: len(violations), Failures: len(violations)}
+	files, groups := byFile(violations)
+	for _, file := range files {
+		suite := junitSuite{Name: file, Tests: len(groups[file]), Failures: len(groups[file])}
+		for _, v := range groups[file] {
+			first, _, _ := strings.Cut(v.Command, "\n")
+			suite.Cases = append(suite.Cases, junitCase{
+				Name:      fmt.Sprintf("line %d: %s %s", v.Line, v.Tool, first),
+				ClassName: strings.TrimSuffix(filepath.Base(file), ".jsonl"),
+				Failure: junitFailure{
+					Message: v.Rule,
+					Type:    v.Severity.String(),
+					Text:    fmt.Sprintf("%s:%d: %s at %s\n%s", file, v.Line, v.Tool, v.Timestamp.Format("2006-01-02 15:04:05"), v.Command),
+				},
+			})
+		}
+		report.Suites = append(report.Suites, suite)
+	}
+	return writeXML(w, report)
+}
+
+// writeCheckstyle renders violations as Checkstyle XML
+func writeCheckstyle(w io.Writer, violations []Violation) error {
+	report := checkstyleReport{Version: "4.3"}
+	files, groups := byFile(violations)
+	for _, file := range files {
+		f := checkstyleFile{Name: file}
+		for _, v := range groups[file] {
+			first, _, _ := strings.Cut(v.Command, "\n")
+			f.Errors = append(f.Errors, checkstyleError{
+				Line:     v.Line,
+				Severity: checkstyleSeverity(v.Severity),
+				Message:  fmt.Sprintf("%s: %s %s", v.Rule, v.Tool, first),
+				Source:   "cc_session_mon.check",
+			})
+		}
+		report.Files = append(report.Files, f)
+	}
+	return writeXML(w, report)
+}
+
+// checkstyleSeverity maps a finding severity to Checkstyle's error, warning,
+// and info
+func checkstyleSeverity(s security.Severity) string {
+	switch s {
+	case security.SeverityHigh:
+		return "error"
+	case security.SeverityMedium:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// writeXML writes an indented XML document
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// sarifLog is a SARIF 2.1.0 log, the format GitHub code scanning uploads
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string          `json:"id"`
+	Name             string          `json:"name"`
+	ShortDescription sarifMessage    `json:"shortDescription"`
+	Properties       sarifProperties `json:"properties"`
+}
+
+type sarifProperties struct {
+	SecuritySeverity string   `json:"security-severity"`
+	Tags             []string `json:"tags"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// writeSARIF renders violations as a SARIF log with a rule per finding kind
+// and a result per violation, located at the session file line
+func writeSARIF(w io.Writer, violations []Violation) error {
+	driver := sarifDriver{Name: "cc_session_mon", InformationURI: "https://github.com/joshpearce/cc_session_mon", Rules: []sarifRule{}}
+	results := []sarifResult{}
+	index := make(map[string]int)
+	for _, v := range violations {
+		id, name := sarifRuleID(v.Rule), v.Rule
+		if strings.HasPrefix(v.Rule, RuleDenied+" ") {
+			name = RuleDenied
+		}
+		i, ok := index[id]
+		if !ok {
+			i = len(driver.Rules)
+			index[id] = i
+			driver.Rules = append(driver.Rules, sarifRule{
+				ID:               id,
+				Name:             name,
+				ShortDescription: sarifMessage{Text: name},
+				Properties:       sarifProperties{SecuritySeverity: securitySeverity(v.Severity), Tags: []string{"security"}},
+			})
+		}
+		first, _, _ := strings.Cut(v.Command, "\n")
+		results = append(results, sarifResult{
+			RuleID:    id,
+			RuleIndex: i,
+			Level:     sarifLevel(v.Severity),
+			Message:   sarifMessage{Text: fmt.Sprintf("%s: %s %s", v.Rule, v.Tool, first)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact{URI: sarifURI(v.File)},
+				Region:           sarifRegion{StartLine: max(v.Line, 1)},
+			}}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
+
+// sarifRuleID turns a rule name into a stable id, e.g. "Recursive file
+// deletion" into "recursive-file-deletion"; all check.deny patterns share
+// "denied-pattern"
+func sarifRuleID(rule string) string {
+	if strings.HasPrefix(rule, RuleDenied+" ") {
+		rule = RuleDenied
+	}
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(rule) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// sarifLevel maps a finding severity to SARIF's error, warning, and note
+func sarifLevel(s security.Severity) string {
+	switch s {
+	case security.SeverityHigh:
+		return "error"
+	case security.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity is the score GitHub code scanning ranks security alerts
+// by: high is 7.0 or more, medium 4.0 to 6.9, low below 4.0
+func securitySeverity(s security.Severity) string {
+	switch s {
+	case security.SeverityHigh:
+		return "8.0"
+	case security.SeverityMedium:
+		return "5.0"
+	default:
+		return "2.0"
+	}
+}
+
+// sarifURI is a session file's artifact URI: relative paths stay relative
+// (to the repository root on upload), absolute ones become file: URIs
+func sarifURI(path string) string {
+	if filepath.IsAbs(path) {
+		slashed := filepath.ToSlash(path)
+		if !strings.HasPrefix(slashed, "/") {
+			slashed = "/" + slashed // C:/x is file:///C:/x
+		}
+		return (&url.URL{Scheme: "file", Path: slashed}).String()
+	}
+	return filepath.ToSlash(path)
+}
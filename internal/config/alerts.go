@@ -0,0 +1,294 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AlertRules configures notifications for security findings on commands as
+// they arrive (history loaded at startup never alerts)
+type AlertRules struct {
+	// MinSeverity is the lowest finding severity that alerts: low, medium, or high (default)
+	MinSeverity string `yaml:"min_severity"`
+
+	// DashboardURL is the web dashboard's address for links in chat alerts
+	// (defaults to the --web address when serving the dashboard)
+	DashboardURL string `yaml:"dashboard_url"`
+
+	// Email sends alerts over SMTP
+	Email EmailSink `yaml:"email"`
+
+	// Slack and Discord post formatted alerts to incoming webhooks
+	Slack   ChatSink `yaml:"slack"`
+	Discord ChatSink `yaml:"discord"`
+
+	// Sinks are named notification destinations; email, slack, and discord
+	// above are shorthands for sinks of those names
+	Sinks []SinkConfig `yaml:"sinks"`
+
+	// Routes choose which sinks receive which rules. Without routes every
+	// sink receives every alert at or above MinSeverity.
+	Routes []AlertRoute `yaml:"routes"`
+
+	// Actions are guard-rails triggered by specific commands, independent of findings
+	Actions []AlertAction `yaml:"actions"`
+
+	// Thresholds alert on bursts and streaks of commands rather than single findings
+	Thresholds []AlertThreshold `yaml:"thresholds"`
+
+	// Delivery limits how often each sink is alerted; sinks may override its fields
+	Delivery DeliveryPolicy `yaml:"delivery"`
+}
+
+// DeliveryPolicy limits the alerts one sink receives. Alerts it holds back
+// are dropped and counted in the next alert the sink is sent.
+type DeliveryPolicy struct {
+	// RateLimit is the most alerts sent per RatePeriod (default 1h); 0 is unlimited
+	RateLimit  int           `yaml:"rate_limit"`
+	RatePeriod time.Duration `yaml:"rate_period"`
+
+	// DedupWindow drops an alert repeating the rule and command of one sent within this long
+	DedupWindow time.Duration `yaml:"dedup_window"`
+
+	// QuietHours holds back alerts during a daily period
+	QuietHours QuietHours `yaml:"quiet_hours"`
+}
+
+// Merge returns the policy with the fields set in override replacing its own
+func (p DeliveryPolicy) Merge(override DeliveryPolicy) DeliveryPolicy {
+	if override.RateLimit != 0 {
+		p.RateLimit = override.RateLimit
+	}
+	if override.RatePeriod != 0 {
+		p.RatePeriod = override.RatePeriod
+	}
+	if override.DedupWindow != 0 {
+		p.DedupWindow = override.DedupWindow
+	}
+	if override.QuietHours.From != "" {
+		p.QuietHours = override.QuietHours
+	}
+	return p
+}
+
+// QuietHours is a daily period, in local time, when only alerts at or above
+// MinSeverity are sent
+type QuietHours struct {
+	// From and To are "HH:MM"; To earlier than From spans midnight (e.g. 22:00 to 07:00)
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+
+	// MinSeverity still alerts during quiet hours; empty holds back everything
+	MinSeverity string `yaml:"min_severity"`
+}
+
+// Contains reports whether t falls within the quiet hours. Unset or
+// malformed hours (rejected by Validate) contain nothing.
+func (q *QuietHours) Contains(t time.Time) bool {
+	from, err := parseClock(q.From)
+	if err != nil {
+		return false
+	}
+	to, err := parseClock(q.To)
+	if err != nil {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	if from <= to {
+		return now >= from && now < to
+	}
+	return now >= from || now < to
+}
+
+// parseClock parses "HH:MM" as minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// AlertThreshold fires when more than Count matching commands occur within
+// Window, or in a row when InARow is set. Breaches alert like findings (the
+// threshold's name is the rule) and are listed in the Findings view.
+type AlertThreshold struct {
+	Name string `yaml:"name"`
+
+	// Pattern is the command pattern to count (supports a * wildcard), e.g.
+	// Write or Bash(go:test:*); empty counts every command
+	Pattern string `yaml:"pattern"`
+
+	// Failed counts only commands whose tool result was an error
+	Failed bool `yaml:"failed"`
+
+	// Count is the most matching commands allowed; one more breaches the threshold
+	Count int `yaml:"count"`
+
+	// Window is the period the commands must fall within, e.g. 5m
+	Window time.Duration `yaml:"window"`
+
+	// InARow counts consecutive commands instead of a time window. With
+	// Failed, a successful call matching Pattern ends the run; otherwise any
+	// command not matching Pattern does.
+	InARow bool `yaml:"in_a_row"`
+
+	// Severity of the alerts the threshold sends (default medium)
+	Severity string `yaml:"severity"`
+}
+
+// MatchesCommand reports whether a command counts towards the threshold
+// (ignoring Failed)
+func (t *AlertThreshold) MatchesCommand(pattern string) bool {
+	return t.Pattern == "" || matchPattern(t.Pattern, pattern)
+}
+
+// AlertAction runs a command, alerts sinks, and/or flags the session when a
+// new command matches its pattern, argument, and project filters
+type AlertAction struct {
+	Name string `yaml:"name"`
+
+	// Pattern is the command pattern to match (supports a * wildcard), e.g. Bash(git:push:*)
+	Pattern string `yaml:"pattern"`
+
+	// Contains requires the raw command to contain one of these strings (e.g. --force, -f)
+	Contains []string `yaml:"contains"`
+
+	// Projects limits the action to projects whose path (~ expanded) or
+	// directory name matches one of these patterns; empty matches every project
+	Projects []string `yaml:"projects"`
+
+	// Severity of the alerts the action sends (default high)
+	Severity string `yaml:"severity"`
+
+	// Run is a program and arguments to run with the alert JSON on stdin, as an exec sink
+	Run []string `yaml:"run"`
+
+	// Notify names the sinks to alert
+	Notify []string `yaml:"notify"`
+
+	// Flag marks the session as flagged in the session list
+	Flag bool `yaml:"flag"`
+}
+
+// Matches reports whether a command (pattern and raw text) in a project triggers the action
+func (a *AlertAction) Matches(projectPath, pattern, raw string) bool {
+	if a.Pattern != "" && !matchPattern(a.Pattern, pattern) {
+		return false
+	}
+	if len(a.Contains) > 0 && !slices.ContainsFunc(a.Contains, func(s string) bool { return strings.Contains(raw, s) }) {
+		return false
+	}
+	return len(a.Projects) == 0 || matchesProject(a.Projects, projectPath)
+}
+
+// SinkTypes are the notifier types the alert package registers
+var SinkTypes = []string{"email", "slack", "discord", "webhook", "desktop", "syslog", "exec"}
+
+// SinkConfig is one named notification destination. Type selects the
+// notifier; the remaining fields are read by the types that use them.
+type SinkConfig struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+
+	// webhook_url / webhook_url_env, for slack, discord, and webhook sinks
+	ChatSink `yaml:",inline"`
+
+	// SMTP settings and templates, for email sinks
+	EmailSink `yaml:",inline"`
+
+	// Tag is the syslog tag (default cc_session_mon)
+	Tag string `yaml:"tag"`
+
+	// Command is the program and arguments an exec sink runs per alert, with
+	// the alert JSON on stdin (no shell is involved)
+	Command []string `yaml:"command"`
+
+	// Timeout bounds each exec sink run (default 30s)
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Rate limit, dedup window, and quiet hours overriding alerts.delivery
+	DeliveryPolicy `yaml:",inline"`
+}
+
+// AlertRoute sends alerts for matching rules to a set of sinks
+type AlertRoute struct {
+	// Rules are rule names to match (supports a * wildcard); empty matches every rule
+	Rules []string `yaml:"rules"`
+
+	// MinSeverity overrides the alerts-wide minimum for this route
+	MinSeverity string `yaml:"min_severity"`
+
+	// Sinks are the names of the sinks that receive matching alerts
+	Sinks []string `yaml:"sinks"`
+}
+
+// MatchesRule reports whether the route applies to a finding's rule name
+func (r *AlertRoute) MatchesRule(rule string) bool {
+	if len(r.Rules) == 0 {
+		return true
+	}
+	for _, p := range r.Rules {
+		if matchPattern(p, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChatSink configures a Slack or Discord incoming webhook
+type ChatSink struct {
+	// WebhookURL, or the environment variable holding it (webhook URLs are secrets)
+	WebhookURL    string `yaml:"webhook_url"`
+	WebhookURLEnv string `yaml:"webhook_url_env"`
+}
+
+// URL returns the webhook URL, reading WebhookURLEnv when WebhookURL is unset
+func (c *ChatSink) URL() string {
+	if c.WebhookURL != "" {
+		return c.WebhookURL
+	}
+	if c.WebhookURLEnv != "" {
+		return os.Getenv(c.WebhookURLEnv)
+	}
+	return ""
+}
+
+// EmailSink configures the SMTP alert sink; it is disabled until Host is set
+type EmailSink struct {
+	// Host and Port address the SMTP server (STARTTLS is used when offered; port defaults to 587)
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+
+	// Username and the environment variable holding the password, for SMTP AUTH
+	Username    string `yaml:"username"`
+	PasswordEnv string `yaml:"password_env"`
+
+	// From and To are the sender and recipients
+	From string   `yaml:"from"`
+	To   []string `yaml:"to"`
+
+	// BatchWindow collects alerts for this long and sends one email per rule (default 1m)
+	BatchWindow time.Duration `yaml:"batch_window"`
+
+	// Subject and Body are text/template strings rendered with the batch
+	// (.Rule, .Severity, .Count, .Events); empty uses the built-in templates
+	Subject string `yaml:"subject"`
+	Body    string `yaml:"body"`
+
+	// Templates overrides Subject and Body per rule name (e.g. "Recursive file deletion")
+	Templates map[string]EmailTemplate `yaml:"templates"`
+}
+
+// EmailTemplate is a per-rule subject and body override
+type EmailTemplate struct {
+	Subject string `yaml:"subject"`
+	Body    string `yaml:"body"`
+}
+
+// Enabled reports whether the email sink is configured
+func (e *EmailSink) Enabled() bool {
+	return e.Host != "" && len(e.To) > 0
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -26,6 +27,19 @@ type ToolGroup struct {
 	Exclude bool `yaml:"exclude"`
 }
 
+// Category classifies commands by what they do (building, testing, version
+// control, ...) for stats, independent of how tool groups color them
+type Category struct {
+	// Name is the category shown in the Category column and stats
+	Name string `yaml:"name"`
+
+	// Patterns is a list of command patterns in this category (supports wildcards)
+	Patterns []string `yaml:"patterns"`
+}
+
+// Uncategorized is the category of commands no category's patterns match
+const Uncategorized = "other"
+
 // Config holds the application configuration
 type Config struct {
 	// Theme is the color theme to use (mocha, macchiato, frappe, latte)
@@ -33,12 +47,289 @@ type Config struct {
 
 	// ToolGroups defines styling groups for commands (checked in order, first match wins)
 	ToolGroups []ToolGroup `yaml:"tool_groups"`
+
+	// Categories classify commands for the Category column and stats
+	// (checked in order, first match wins)
+	Categories []Category `yaml:"categories"`
+
+	// Security adds rules on top of the built-in security warnings
+	Security SecurityRules `yaml:"security"`
+
+	// Commands extends the built-in command knowledge used to extract Bash patterns
+	Commands CommandKnowledge `yaml:"commands"`
+
+	// Alerts sends notifications when new commands trigger security findings
+	Alerts AlertRules `yaml:"alerts"`
+
+	// PersistSearchHistory keeps the TUI's recent search queries across runs
+	// in a search-history file in the state directory
+	PersistSearchHistory bool `yaml:"persist_search_history"`
+
+	// GitSnapshots records the git state of each session's project when
+	// the TUI first sees the session active, so C can show the cumulative
+	// diff against it
+	GitSnapshots bool `yaml:"git_snapshots"`
+
+	// Clock corrects timestamps from machines with skewed clocks
+	Clock ClockSettings `yaml:"clock"`
+
+	// RefreshInterval is how often activity status, "ago" times, and new
+	// subagent files are refreshed (the TUI tick, web and share pushes)
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+
+	// ActivityWindow is how recently a session file must have been written
+	// for the session to count as active
+	ActivityWindow time.Duration `yaml:"activity_window"`
+
+	// DevagentPollInterval is how often --devagent looks for new containers
+	DevagentPollInterval time.Duration `yaml:"devagent_poll_interval"`
+
+	// SubagentScanInterval is how often tracked sessions are checked for
+	// subagent transcripts the file watcher missed (kqueue on macOS can miss
+	// files created right after their directory)
+	SubagentScanInterval time.Duration `yaml:"subagent_scan_interval"`
+
+	// ArchiveDir is where sessions archived from the Sessions view are moved;
+	// empty uses session-archive next to the session's projects directory
+	ArchiveDir string `yaml:"archive_dir"`
+
+	// StateDir is where state kept between runs is stored (search history,
+	// the last digest time, the SSH host key); empty uses
+	// $XDG_STATE_HOME/cc_session_mon (see StateDir)
+	StateDir string `yaml:"state_dir"`
+
+	// CacheDir is where data that can be fetched again is stored (remote
+	// source mirrors); empty uses the user cache directory (see CacheDir)
+	CacheDir string `yaml:"cache_dir"`
+
+	// Homes are home directory globs (e.g. /home/*) whose users'
+	// .claude/projects trees are watched alongside the local one, for
+	// auditing a shared host from a privileged account
+	Homes []string `yaml:"homes"`
+
+	// ProjectsDirs are extra Claude projects directories to watch alongside
+	// the local one, e.g. a synced copy of another machine's
+	// ~/.claude/projects. Entries may start with ~ or $VARS and be globs,
+	// re-evaluated every refresh. The TUI's add-directory dialog (A)
+	// appends to it.
+	ProjectsDirs []string `yaml:"projects_dirs"`
+
+	// SharedDirs are shared locations other people's session files are
+	// synced to (a mounted share, or a directory kept in sync with a
+	// bucket), with a subfolder per user holding their projects directory.
+	// Entries may start with ~ or $VARS; new users are picked up every
+	// refresh.
+	SharedDirs []string `yaml:"shared_dirs"`
+
+	// RemoteSources are S3 or GCS prefixes archived sessions are fetched
+	// from, into a local cache the watcher follows
+	RemoteSources []RemoteSource `yaml:"remote_sources"`
+
+	// WatchStrategy is what gets a file watch: "directories" (the default,
+	// also when empty) watches every projects, project, session, and
+	// subagents directory; "projects" leaves subagent transcripts to polling;
+	// "poll" watches only projects directories and polls for the rest. The
+	// fewer watches, the further from Linux's inotify limit.
+	WatchStrategy string `yaml:"watch_strategy"`
+
+	// Owners label sessions by who runs them, for filtering and grouping
+	Owners []Owner `yaml:"owners"`
+
+	// Check sets what fails `cc_session_mon check`
+	Check CheckRules `yaml:"check"`
+
+	// Scrub sets what `cc_session_mon scrub` and `export --scrub` remove
+	// from session files
+	Scrub ScrubRules `yaml:"scrub"`
+
+	// TextIndicators replaces the TUI's activity dots, flag and warning
+	// symbols, and ellipses with text like [ACTIVE] and "...", for screen
+	// readers and fonts without the symbols
+	TextIndicators bool `yaml:"text_indicators"`
+
+	// Layout sets up the TUI's views
+	Layout LayoutSettings `yaml:"layout"`
+
+	// Profile is the name of the profile applied over the config file, if
+	// any (set by LoadProfile)
+	Profile string `yaml:"-"`
+
+	// Policy is the organization policy enforced over this config, if any
+	// (set by EnforcePolicy)
+	Policy *Policy `yaml:"-"`
+}
+
+// LayoutSettings sets up the TUI's views; profiles use it to suit a task
+type LayoutSettings struct {
+	// Preset starts from a named layout in LayoutPresets; the other layout
+	// keys set alongside it override the preset's
+	Preset string `yaml:"preset"`
+
+	// StartView is the view shown at startup: sessions (the default),
+	// commands, patterns, findings, or analytics
+	StartView string `yaml:"start_view"`
+
+	// Summaries shows the one-line summary under each session, as e does
+	Summaries bool `yaml:"summaries"`
+
+	// HidePreview hides the Sessions view preview pane in wide terminals
+	HidePreview bool `yaml:"hide_preview"`
+
+	// DetailPanel opens the detail panel whenever the Commands view is
+	// entered, so transcripts read with diffs and output alongside
+	DetailPanel bool `yaml:"detail_panel"`
+
+	// ExpandHeredocs shows heredoc bodies in full, as x does
+	ExpandHeredocs bool `yaml:"expand_heredocs"`
+
+	// ShowThinking shows the reasoning before each command, as t does
+	ShowThinking bool `yaml:"show_thinking"`
+}
+
+// CheckRules configures `cc_session_mon check`, which fails CI when a
+// session's commands violate them
+type CheckRules struct {
+	// FailOn is the lowest finding severity that fails: low, medium, or high (default)
+	FailOn string `yaml:"fail_on"`
+
+	// Deny are command patterns (e.g. Bash(git:push:*)) that fail whatever
+	// their findings
+	Deny []string `yaml:"deny"`
+}
+
+// Denied returns the deny pattern a command pattern matches, or ""
+func (c *CheckRules) Denied(pattern string) string {
+	for _, p := range c.Deny {
+		if matchPattern(p, pattern) {
+			return p
+		}
+	}
+	return ""
+}
+
+// ScrubRules configures `cc_session_mon scrub`, which rewrites session
+// files to satisfy retention policies. Tool calls (the command audit trail)
+// are always kept.
+type ScrubRules struct {
+	// StripToolResults replaces tool results' content with a placeholder,
+	// keeping their error flags
+	StripToolResults bool `yaml:"strip_tool_results"`
+
+	// MaskSecrets masks credentials (NAME=value assignments, bearer
+	// tokens, private keys, provider token formats) in all text
+	MaskSecrets bool `yaml:"mask_secrets"`
+
+	// DropThinking removes the model's thinking blocks
+	DropThinking bool `yaml:"drop_thinking"`
+
+	// Patterns are extra regular expressions masked along with secrets;
+	// with a capture group only the group is masked
+	Patterns []string `yaml:"patterns"`
+}
+
+// Default intervals, used when the config leaves them unset
+const (
+	DefaultRefreshInterval      = 30 * time.Second
+	DefaultActivityWindow       = 5 * time.Minute
+	DefaultDevagentPollInterval = 30 * time.Second
+	DefaultSubagentScanInterval = 10 * time.Second
+)
+
+// ClockSettings corrects session timestamps for clock skew between the
+// machines sessions come from and sets the zone they are shown in
+type ClockSettings struct {
+	// Timezone is the IANA zone timestamps are shown in (e.g. "UTC",
+	// "Europe/Berlin"); empty uses the local zone
+	Timezone string `yaml:"timezone"`
+
+	// Offsets are added to the timestamps of sessions by origin: "local",
+	// "devagent:<container>", or a pattern like "devagent:*". A container
+	// whose clock runs 90 seconds fast needs -90s.
+	Offsets map[string]time.Duration `yaml:"offsets"`
+}
+
+// Offset returns the correction for an origin's timestamps: its exact entry,
+// else the longest matching pattern, else zero
+func (c *ClockSettings) Offset(origin string) time.Duration {
+	if d, ok := c.Offsets[origin]; ok {
+		return d
+	}
+	var offset time.Duration
+	best := -1
+	for pattern, d := range c.Offsets {
+		if len(pattern) > best && matchPattern(pattern, origin) {
+			offset, best = d, len(pattern)
+		}
+	}
+	return offset
+}
+
+// Location returns the zone timestamps are shown in. An unknown zone
+// (rejected by Validate) falls back to the local zone.
+func (c *ClockSettings) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// CommandKnowledge configures how Bash commands are turned into patterns
+type CommandKnowledge struct {
+	// SubcommandDepth sets how many subcommand levels to capture per command,
+	// adding to or overriding the built-in table (e.g. gh: 2 gives Bash(gh:pr:view:*))
+	SubcommandDepth map[string]int `yaml:"subcommand_depth"`
+
+	// Wrappers are extra command prefixes stripped before pattern extraction
+	// (e.g. "doppler run", "timeout *"); * matches any single word
+	Wrappers []string `yaml:"wrappers"`
+
+	// FlagsWithArgs lists, per command, flags that consume the following word
+	// (e.g. git: ["-C"]) so it isn't mistaken for a subcommand; adds to the built-ins
+	FlagsWithArgs map[string][]string `yaml:"flags_with_args"`
+
+	// PatternDepth is the number of argument words captured after the
+	// subcommands for every command (0 keeps patterns at subcommand level)
+	PatternDepth int `yaml:"pattern_depth"`
+
+	// PatternDepthByCommand overrides PatternDepth per command family
+	// (e.g. git: 1 gives Bash(git:push:--force*))
+	PatternDepthByCommand map[string]int `yaml:"pattern_depth_by_command"`
+}
+
+// ArgumentDepth returns the number of argument words to capture for cmd
+func (k *CommandKnowledge) ArgumentDepth(cmd string) int {
+	if depth, ok := k.PatternDepthByCommand[cmd]; ok {
+		return depth
+	}
+	return k.PatternDepth
+}
+
+// SecurityRules defines user-configurable security warnings
+type SecurityRules struct {
+	// SensitivePaths are extra path substrings flagged as security-sensitive (e.g. "terraform.tfstate")
+	SensitivePaths []string `yaml:"sensitive_paths"`
+
+	// WarnPatterns are command patterns (supports wildcards) that produce a security warning
+	WarnPatterns []string `yaml:"warn_patterns"`
+
+	// AllowedWritePaths are directories outside a session's project that writes
+	// may target without an outside-project warning (/tmp and /dev are built in)
+	AllowedWritePaths []string `yaml:"allowed_write_paths"`
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
-		Theme: "mocha",
+		Theme:                "mocha",
+		RefreshInterval:      DefaultRefreshInterval,
+		ActivityWindow:       DefaultActivityWindow,
+		DevagentPollInterval: DefaultDevagentPollInterval,
+		SubagentScanInterval: DefaultSubagentScanInterval,
+		Scrub:                ScrubRules{StripToolResults: true, MaskSecrets: true, DropThinking: true},
 		ToolGroups: []ToolGroup{
 			{
 				Name:  "dangerous",
@@ -76,6 +367,11 @@ func DefaultConfig() *Config {
 				Color:    "lavender",
 				Patterns: []string{"Task", "TaskOutput"},
 			},
+			{
+				Name:     "skill",
+				Color:    "teal",
+				Patterns: []string{"Skill", "Skill(*)"},
+			},
 			{
 				Name:  "read-only",
 				Color: "green",
@@ -96,6 +392,108 @@ func DefaultConfig() *Config {
 				Patterns: []string{"*"},
 			},
 		},
+		Categories: []Category{
+			{
+				Name: "test",
+				Patterns: []string{
+					"Bash(go:test:*)",
+					"Bash(go:vet:*)",
+					"Bash(cargo:test:*)",
+					"Bash(npm:test:*)",
+					"Bash(yarn:test:*)",
+					"Bash(pnpm:test:*)",
+					"Bash(make:test:*)",
+					"Bash(make:check:*)",
+					"Bash(pytest:*)",
+					"Bash(jest:*)",
+					"Bash(vitest:*)",
+					"Bash(tox:*)",
+					"Bash(golangci-lint:*)",
+				},
+			},
+			{
+				Name: "build",
+				Patterns: []string{
+					"Bash(go:*)",
+					"Bash(cargo:*)",
+					"Bash(npm:*)",
+					"Bash(yarn:*)",
+					"Bash(pnpm:*)",
+					"Bash(pip:*)",
+					"Bash(uv:*)",
+					"Bash(make:*)",
+					"Bash(cmake:*)",
+					"Bash(tsc:*)",
+					"Bash(docker:build:*)",
+				},
+			},
+			{
+				Name:     "vcs",
+				Patterns: []string{"Bash(git:*)", "Bash(gh:*)", "Bash(jj:*)", "Bash(hg:*)"},
+			},
+			{
+				Name: "network",
+				Patterns: []string{
+					"WebFetch",
+					"WebSearch",
+					"Bash(curl:*)",
+					"Bash(wget:*)",
+					"Bash(ssh:*)",
+					"Bash(scp:*)",
+					"Bash(rsync:*)",
+					"Bash(nc:*)",
+					"Bash(ping:*)",
+					"Bash(dig:*)",
+				},
+			},
+			{
+				Name: "file-io",
+				Patterns: []string{
+					"Read",
+					"Write",
+					"Edit",
+					"MultiEdit",
+					"NotebookEdit",
+					"Glob",
+					"Grep",
+					"LS",
+					"Bash(cat:*)",
+					"Bash(ls:*)",
+					"Bash(find:*)",
+					"Bash(grep:*)",
+					"Bash(rg:*)",
+					"Bash(head:*)",
+					"Bash(tail:*)",
+					"Bash(sed:*)",
+					"Bash(cp:*)",
+					"Bash(mv:*)",
+					"Bash(rm:*)",
+					"Bash(mkdir:*)",
+					"Bash(touch:*)",
+					"Bash(tar:*)",
+				},
+			},
+			{
+				Name: "system",
+				Patterns: []string{
+					"Bash(sudo:*)",
+					"Bash(systemctl:*)",
+					"Bash(launchctl:*)",
+					"Bash(kill:*)",
+					"Bash(pkill:*)",
+					"Bash(killall:*)",
+					"Bash(ps:*)",
+					"Bash(chmod:*)",
+					"Bash(chown:*)",
+					"Bash(dd:*)",
+					"Bash(mount:*)",
+					"Bash(docker:*)",
+					"Bash(kubectl:*)",
+					"Bash(apt-get:*)",
+					"Bash(brew:*)",
+				},
+			},
+		},
 	}
 }
 
@@ -115,16 +513,72 @@ func Load(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
+	if err := cfg.applyLayoutPreset(data); err != nil {
+		return nil, err
+	}
+	cfg.defaultIntervals()
 
 	return cfg, nil
 }
 
-// LoadFromDefaultPath attempts to load config from standard locations
+// defaultIntervals restores the defaults for intervals set to zero or less
+// (rejected by Validate), which would otherwise tick continuously
+func (c *Config) defaultIntervals() {
+	if c.RefreshInterval <= 0 {
+		c.RefreshInterval = DefaultRefreshInterval
+	}
+	if c.ActivityWindow <= 0 {
+		c.ActivityWindow = DefaultActivityWindow
+	}
+	if c.DevagentPollInterval <= 0 {
+		c.DevagentPollInterval = DefaultDevagentPollInterval
+	}
+	if c.SubagentScanInterval <= 0 {
+		c.SubagentScanInterval = DefaultSubagentScanInterval
+	}
+}
+
+// LoadFromDefaultPath attempts to load config from standard locations, and
+// enforces the organization policy over it
 func LoadFromDefaultPath() (*Config, error) {
+	cfg := DefaultConfig()
+	if path := FindPath(); path != "" {
+		var err error
+		if cfg, err = Load(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.EnforcePolicy(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// pathOverride is the config file set with SetPath (--config), used instead
+// of the standard locations
+var pathOverride string
+
+// SetPath makes FindPath, and so LoadFromDefaultPath, profiles, and saves,
+// use the config file at path instead of the standard locations
+func SetPath(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	pathOverride = filepath.Clean(path)
+	return nil
+}
+
+// FindPath returns the config file set with SetPath, else the first existing
+// config file in the standard locations, or "" if there is none
+func FindPath() string {
+	if pathOverride != "" {
+		return pathOverride
+	}
 	// Check in order: current dir, ~/.config/cc_session_mon/, XDG_CONFIG_HOME
+	home, _ := os.UserHomeDir()
 	paths := []string{
 		"config.yaml",
-		filepath.Join(os.Getenv("HOME"), ".config", "cc_session_mon", "config.yaml"),
+		filepath.Join(home, ".config", "cc_session_mon", "config.yaml"),
 	}
 
 	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
@@ -134,17 +588,20 @@ func LoadFromDefaultPath() (*Config, error) {
 	for _, path := range paths {
 		cleanPath := filepath.Clean(path)
 		if _, err := os.Stat(cleanPath); err == nil { //nolint:gosec // config path from known locations
-			return Load(cleanPath)
+			return cleanPath
 		}
 	}
-
-	return DefaultConfig(), nil
+	return ""
 }
 
-// GetToolGroup returns the first matching tool group for a pattern, or nil
+// GetToolGroup returns the first matching tool group for a pattern, or nil.
+// Excluding groups are skipped for patterns the policy keeps visible.
 func (c *Config) GetToolGroup(pattern string) *ToolGroup {
 	for i := range c.ToolGroups {
 		group := &c.ToolGroups[i]
+		if group.Exclude && c.Policy.forbidsExclusion(pattern) {
+			continue
+		}
 		if group.Matches(pattern) {
 			return group
 		}
@@ -162,12 +619,41 @@ func (g *ToolGroup) Matches(pattern string) bool {
 	return false
 }
 
+// Classify returns the name of the first category matching a pattern, or
+// Uncategorized
+func (c *Config) Classify(pattern string) string {
+	for i := range c.Categories {
+		for _, p := range c.Categories[i].Patterns {
+			if matchPattern(p, pattern) {
+				return c.Categories[i].Name
+			}
+		}
+	}
+	return Uncategorized
+}
+
 // ShouldExclude returns true if the pattern should be excluded from display
 func (c *Config) ShouldExclude(pattern string) bool {
 	group := c.GetToolGroup(pattern)
 	return group != nil && group.Exclude
 }
 
+// SecurityWarnings returns a warning for each warn pattern that matches the command pattern
+func (c *Config) SecurityWarnings(pattern string) []string {
+	var warnings []string
+	for _, p := range c.Security.WarnPatterns {
+		if !matchPattern(p, pattern) {
+			continue
+		}
+		if c.Policy.requiresWarning(p) {
+			warnings = append(warnings, "Matches organization policy rule "+p)
+		} else {
+			warnings = append(warnings, "Matches security rule "+p)
+		}
+	}
+	return warnings
+}
+
 // matchPattern checks if a pattern matches (supports * wildcards)
 func matchPattern(pattern, value string) bool {
 	// Exact match
@@ -198,6 +684,7 @@ func Global() *Config {
 		cfg, err := LoadFromDefaultPath()
 		if err != nil {
 			cfg = DefaultConfig()
+			_ = cfg.EnforcePolicy() // main refuses to start with an invalid policy
 		}
 		globalConfig = cfg
 	}
@@ -207,4 +694,5 @@ func Global() *Config {
 // SetGlobal sets the global config instance (useful for testing)
 func SetGlobal(cfg *Config) {
 	globalConfig = cfg
+	clearProjectCache()
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -89,6 +90,35 @@ func TestGetToolGroup(t *testing.T) {
 	}
 }
 
+func TestClassify(t *testing.T) {
+	cfg := DefaultConfig()
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"Bash(go:test:*)", "test"},
+		{"Bash(go:build:*)", "build"},
+		{"Bash(git:push:*)", "vcs"},
+		{"WebFetch", "network"},
+		{"Bash(curl:*)", "network"},
+		{"Read", "file-io"},
+		{"Bash(rm:*)", "file-io"},
+		{"Bash(sudo:*)", "system"},
+		{"Task", Uncategorized},
+	}
+	for _, tt := range tests {
+		if got := cfg.Classify(tt.pattern); got != tt.want {
+			t.Errorf("Classify(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+
+	// Project categories are checked first
+	merged := cfg.WithOverride(&Config{Categories: []Category{{Name: "deploy", Patterns: []string{"Bash(make:deploy:*)"}}}})
+	if got := merged.Classify("Bash(make:deploy:*)"); got != "deploy" {
+		t.Errorf("project override Classify = %q, want deploy", got)
+	}
+}
+
 func TestShouldExclude(t *testing.T) {
 	cfg := &Config{
 		ToolGroups: []ToolGroup{
@@ -236,3 +266,162 @@ func TestSetGlobal(t *testing.T) {
 	// Reset to nil so other tests use defaults
 	SetGlobal(nil)
 }
+
+func TestAlertActionMatches(t *testing.T) {
+	t.Setenv("HOME", "/home/alice")
+	a := AlertAction{
+		Pattern:  "Bash(git:push:*)",
+		Contains: []string{"--force", "-f"},
+		Projects: []string{"~/code/infra", "prod-*"},
+	}
+
+	tests := []struct {
+		project, pattern, raw string
+		want                  bool
+	}{
+		{"/home/alice/code/infra", "Bash(git:push:*)", "git push --force origin main", true},
+		{"/srv/prod-api", "Bash(git:push:*)", "git push -f", true},
+		{"/home/alice/code/infra", "Bash(git:push:*)", "git push origin main", false},
+		{"/home/alice/code/web", "Bash(git:push:*)", "git push --force", false},
+		{"/home/alice/code/infra", "Bash(git:status:*)", "git status --force", false},
+	}
+	for _, tt := range tests {
+		if got := a.Matches(tt.project, tt.pattern, tt.raw); got != tt.want {
+			t.Errorf("Matches(%q, %q, %q) = %v, want %v", tt.project, tt.pattern, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2025, 1, 2, hour, minute, 0, 0, time.Local) }
+	overnight := QuietHours{From: "22:00", To: "07:00"}
+	afternoon := QuietHours{From: "13:00", To: "14:30"}
+
+	tests := []struct {
+		q    QuietHours
+		t    time.Time
+		want bool
+	}{
+		{overnight, at(23, 0), true},
+		{overnight, at(3, 0), true},
+		{overnight, at(7, 0), false},
+		{overnight, at(12, 0), false},
+		{afternoon, at(14, 29), true},
+		{afternoon, at(12, 59), false},
+		{QuietHours{}, at(3, 0), false},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Contains(tt.t); got != tt.want {
+			t.Errorf("%+v.Contains(%s) = %v, want %v", tt.q, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestClockOffset(t *testing.T) {
+	c := ClockSettings{Offsets: map[string]time.Duration{
+		"devagent:*":     -90 * time.Second,
+		"devagent:build": 2 * time.Minute,
+		"*":              time.Second,
+	}}
+	tests := []struct {
+		origin string
+		want   time.Duration
+	}{
+		{"devagent:build", 2 * time.Minute},
+		{"devagent:web", -90 * time.Second},
+		{"local", time.Second},
+	}
+	for _, tt := range tests {
+		if got := c.Offset(tt.origin); got != tt.want {
+			t.Errorf("Offset(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestLoadClockOffsets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("clock:\n  timezone: UTC\n  offsets:\n    local: -90s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Clock.Offset("local"); got != -90*time.Second {
+		t.Errorf("Offset(local) = %v, want -90s", got)
+	}
+	if cfg.Clock.Location() != time.UTC {
+		t.Errorf("Location() = %v, want UTC", cfg.Clock.Location())
+	}
+}
+
+func TestLoadIntervals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("refresh_interval: 5s\nactivity_window: 0s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RefreshInterval != 5*time.Second {
+		t.Errorf("RefreshInterval = %v, want 5s", cfg.RefreshInterval)
+	}
+	if cfg.ActivityWindow != DefaultActivityWindow {
+		t.Errorf("ActivityWindow = %v, want the default for a zero window", cfg.ActivityWindow)
+	}
+	if cfg.DevagentPollInterval != DefaultDevagentPollInterval {
+		t.Errorf("DevagentPollInterval = %v, want the default when unset", cfg.DevagentPollInterval)
+	}
+	if cfg.SubagentScanInterval != DefaultSubagentScanInterval {
+		t.Errorf("SubagentScanInterval = %v, want the default when unset", cfg.SubagentScanInterval)
+	}
+}
+
+func TestLoadLayoutPreset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("layout:\n  summaries: false\n  preset: reviewer\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LayoutPresets["reviewer"]
+	want.Preset = "reviewer"
+	want.Summaries = false // Set next to the preset, whatever the order
+	if cfg.Layout != want {
+		t.Errorf("Layout = %+v, want %+v", cfg.Layout, want)
+	}
+
+	if err := os.WriteFile(path, []byte("layout:\n  preset: auditor\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an unknown preset to fail")
+	}
+}
+
+func TestOwnerOf(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	cfg := &Config{Owners: []Owner{
+		{Name: "ci-bot", Origins: []string{"devagent:ci-*"}},
+		{Name: "alice", Origins: []string{"user:al"}, Projects: []string{"~/code/alice-*", "shared-infra"}},
+	}}
+	tests := []struct {
+		origin, project, want string
+	}{
+		{"devagent:ci-runner", "/workspace/app", "ci-bot"},
+		{"local", filepath.Join(home, "code", "alice-api"), "alice"},
+		{"local", "/srv/shared-infra", "alice"},
+		{"user:al", "/home/al/x", "alice"},
+		{"user:bob", "/home/bob/x", "bob"}, // Falls back to the home's user
+		{"shared:carol", "/work/x", "carol"},
+		{"local", "/tmp/scratch", ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.OwnerOf(tt.origin, tt.project); got != tt.want {
+			t.Errorf("OwnerOf(%q, %q) = %q, want %q", tt.origin, tt.project, got, tt.want)
+		}
+	}
+}
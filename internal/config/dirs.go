@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// appDir is the subdirectory of the XDG base directories holding our files
+const appDir = "cc_session_mon"
+
+// StateDir returns where state kept between runs lives (search history,
+// the last digest time, the SSH host key): the config's state_dir, else
+// $XDG_STATE_HOME/cc_session_mon, else ~/.local/state/cc_session_mon
+func StateDir() string {
+	if dir := Global().StateDir; dir != "" {
+		return expandDir(dir)
+	}
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// CacheDir returns where data that can be fetched again lives (remote
+// source mirrors): the config's cache_dir, else the user cache directory
+// ($XDG_CACHE_HOME or ~/.cache on Linux, ~/Library/Caches on macOS)
+func CacheDir() string {
+	if dir := Global().CacheDir; dir != "" {
+		return expandDir(dir)
+	}
+	if cache, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cache, appDir)
+	}
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// StatePath returns the path of a state file in StateDir. A file left next
+// to the user config by earlier versions is moved there first (with its
+// .pub, for keys), or used where it is if it can't be moved.
+func StatePath(name string) string {
+	path := filepath.Join(StateDir(), name)
+	legacy := filepath.Join(filepath.Dir(UserConfigPath()), name)
+	if legacy == path {
+		return path
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return path
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return path
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return legacy
+	}
+	if err := os.Rename(legacy, path); err != nil {
+		return legacy
+	}
+	_ = os.Rename(legacy+".pub", path+".pub")
+	return path
+}
+
+// xdgDir returns our directory under the XDG base directory in env, or
+// under fallback in the home directory when env is unset or relative (as
+// the spec requires)
+func xdgDir(env, fallback string) string {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, appDir)
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, fallback, appDir)
+}
+
+// ExpandHome replaces a leading ~ (alone or before a separator) with the home
+// directory. Other paths, ~user forms, and paths when the home directory is
+// unknown are returned unchanged.
+func ExpandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || rest != "" && rest[0] != '/' && rest[0] != filepath.Separator {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + rest
+}
+
+// expandDir expands a leading ~ and environment variables in a configured directory
+func expandDir(path string) string {
+	return filepath.Clean(os.ExpandEnv(ExpandHome(path)))
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	SetGlobal(DefaultConfig())
+	t.Cleanup(func() { SetGlobal(nil) })
+
+	if got, want := StateDir(), filepath.Join(home, ".local", "state", "cc_session_mon"); got != want {
+		t.Errorf("StateDir() = %s, want %s", got, want)
+	}
+	t.Setenv("XDG_STATE_HOME", "relative/state")
+	if got, want := StateDir(), filepath.Join(home, ".local", "state", "cc_session_mon"); got != want {
+		t.Errorf("expected a relative XDG_STATE_HOME to be ignored, got %s", got)
+	}
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "xdg"))
+	if got, want := StateDir(), filepath.Join(home, "xdg", "cc_session_mon"); got != want {
+		t.Errorf("StateDir() = %s, want %s", got, want)
+	}
+
+	cfg := DefaultConfig()
+	cfg.StateDir = "~/state"
+	cfg.CacheDir = "~/cache"
+	SetGlobal(cfg)
+	if got, want := StateDir(), filepath.Join(home, "state"); got != want {
+		t.Errorf("StateDir() with state_dir = %s, want %s", got, want)
+	}
+	if got, want := CacheDir(), filepath.Join(home, "cache"); got != want {
+		t.Errorf("CacheDir() with cache_dir = %s, want %s", got, want)
+	}
+}
+
+func TestStatePathMovesLegacyFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	SetGlobal(DefaultConfig())
+	t.Cleanup(func() { SetGlobal(nil) })
+
+	legacy := filepath.Join(home, ".config", "cc_session_mon", "search-history")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte("grep\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := StatePath("search-history")
+	if want := filepath.Join(home, ".local", "state", "cc_session_mon", "search-history"); path != want {
+		t.Fatalf("StatePath = %s, want %s", path, want)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "grep\n" {
+		t.Errorf("expected the legacy file moved, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("expected the legacy file gone, got %v", err)
+	}
+	if again := StatePath("search-history"); again != path {
+		t.Errorf("StatePath again = %s", again)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	t.Setenv("HOME", "/home/alice")
+	for path, want := range map[string]string{
+		"~":          "/home/alice",
+		"~/archive":  "/home/alice/archive",
+		"~bob/x":     "~bob/x",
+		"/srv/~/x":   "/srv/~/x",
+		"$HOME/x":    "$HOME/x",
+		"relative/~": "relative/~",
+	} {
+		if got := ExpandHome(path); got != want {
+			t.Errorf("ExpandHome(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
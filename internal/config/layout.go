@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LayoutPresets are the named layouts selected with layout.preset or
+// --layout, one per way of using the monitor
+var LayoutPresets = map[string]LayoutSettings{
+	// operator watches many sessions live: the Sessions list with the
+	// highlighted session's newest commands beside it, and the header's
+	// pending alerts
+	"operator": {StartView: "sessions"},
+
+	// reviewer reads one session closely: its transcript with the detail
+	// panel's diffs and output open, full heredocs and reasoning, and
+	// session summaries
+	"reviewer": {
+		StartView:      "commands",
+		Summaries:      true,
+		HidePreview:    true,
+		DetailPanel:    true,
+		ExpandHeredocs: true,
+		ShowThinking:   true,
+	},
+}
+
+// LayoutPresetNames returns the preset names, sorted, for messages
+func LayoutPresetNames() string {
+	names := make([]string, 0, len(LayoutPresets))
+	for name := range LayoutPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " or ")
+}
+
+// LayoutPreset returns the named preset
+func LayoutPreset(name string) (LayoutSettings, error) {
+	layout, ok := LayoutPresets[name]
+	if !ok {
+		return LayoutSettings{}, fmt.Errorf("unknown layout preset %q (want %s)", name, LayoutPresetNames())
+	}
+	layout.Preset = name
+	return layout, nil
+}
+
+// applyLayoutPreset replaces the layout with the preset data names, if it
+// names one, then reapplies the other layout keys data sets over it
+func (c *Config) applyLayoutPreset(data []byte) error {
+	var probe struct {
+		Layout struct {
+			Preset string `yaml:"preset"`
+		} `yaml:"layout"`
+	}
+	if err := yaml.Unmarshal(data, &probe); err != nil || probe.Layout.Preset == "" {
+		return err
+	}
+
+	layout, err := LayoutPreset(probe.Layout.Preset)
+	if err != nil {
+		return err
+	}
+	doc := struct {
+		Layout LayoutSettings `yaml:"layout"`
+	}{layout}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	c.Layout = doc.Layout
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Owner labels the sessions of some origins or projects with who runs them,
+// e.g. a person or an automation like "ci-bot"
+type Owner struct {
+	Name string `yaml:"name"`
+
+	// Origins are origin patterns (e.g. user:alice or devagent:ci-*)
+	Origins []string `yaml:"origins"`
+
+	// Projects are project path (~ expanded) or directory name patterns
+	Projects []string `yaml:"projects"`
+}
+
+// OwnerOf returns the owner label of a session from origin in projectPath:
+// the first owner with a matching origin or project, else the user of a
+// user:<name> or shared:<name> origin (see homes and shared_dirs), else ""
+func (c *Config) OwnerOf(origin, projectPath string) string {
+	for _, o := range c.Owners {
+		if slices.ContainsFunc(o.Origins, func(p string) bool { return matchPattern(p, origin) }) ||
+			matchesProject(o.Projects, projectPath) {
+			return o.Name
+		}
+	}
+	for _, prefix := range []string{"user:", "shared:"} {
+		if name, ok := strings.CutPrefix(origin, prefix); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// matchesProject reports whether projectPath or its directory name matches
+// one of patterns; a leading ~/ in a pattern is the home directory
+func matchesProject(patterns []string, projectPath string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	for _, p := range patterns {
+		p = ExpandHome(p)
+		if matchPattern(p, projectPath) || matchPattern(p, filepath.Base(projectPath)) {
+			return true
+		}
+	}
+	return false
+}
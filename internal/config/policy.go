@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// policyPath is where the organization policy is read from: a file only
+// administrators can write, so users can't point elsewhere to drop it
+var policyPath = defaultPolicyPath()
+
+// defaultPolicyPath returns /etc/cc_session_mon/policy.yaml, or its
+// ProgramData equivalent on Windows
+func defaultPolicyPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "cc_session_mon", "policy.yaml")
+	}
+	return "/etc/cc_session_mon/policy.yaml"
+}
+
+// PolicyPath returns where the organization policy is read from
+func PolicyPath() string {
+	return policyPath
+}
+
+// Policy holds monitoring rules a security team distributes to every user.
+// They are enforced over the user config, profiles, and project overrides.
+type Policy struct {
+	// Security rules that are always on, in addition to the user's
+	Security SecurityRules `yaml:"security"`
+
+	// NeverExclude are command patterns (supports wildcards) that tool
+	// groups can't hide: excluding groups are skipped for them
+	NeverExclude []string `yaml:"never_exclude"`
+}
+
+// LoadPolicy reads the organization policy; no policy file returns nil. A
+// policy that doesn't validate is an error rather than partly enforced.
+func LoadPolicy() (*Policy, error) {
+	data, err := os.ReadFile(policyPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if problems := ValidatePolicy(data); len(problems) > 0 {
+		return nil, fmt.Errorf("%s:%s", policyPath, problems[0])
+	}
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("%s: %w", policyPath, err)
+	}
+	return &p, nil
+}
+
+// ValidatePolicy returns line-numbered problems in a policy file. Policies
+// can require rules but not relax them, so allowed_write_paths is refused.
+func ValidatePolicy(data []byte) []Problem {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Problem{{Message: strings.TrimPrefix(err.Error(), "yaml: ")}}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return []Problem{{Line: root.Line, Message: "policy must be a mapping"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "security":
+			problems = append(problems, validateSecurity(value)...)
+			for j := 0; value.Kind == yaml.MappingNode && j+1 < len(value.Content); j += 2 {
+				if value.Content[j].Value == "allowed_write_paths" {
+					problems = append(problems, Problem{value.Content[j].Line,
+						"a policy can't allow writes; allowed_write_paths belongs in user config"})
+				}
+			}
+		case "never_exclude":
+			problems = append(problems, validateList("never_exclude", value)...)
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown policy key %q", key.Value)})
+		}
+	}
+	return problems
+}
+
+// EnforcePolicy applies the organization policy, if there is one, to c
+func (c *Config) EnforcePolicy() error {
+	p, err := LoadPolicy()
+	if err != nil || p == nil {
+		return err
+	}
+	c.applyPolicy(p)
+	return nil
+}
+
+// applyPolicy adds the policy's security rules missing from c and records
+// the policy for GetToolGroup
+func (c *Config) applyPolicy(p *Policy) {
+	c.Policy = p
+	c.Security.SensitivePaths = appendMissing(c.Security.SensitivePaths, p.Security.SensitivePaths)
+	c.Security.WarnPatterns = appendMissing(c.Security.WarnPatterns, p.Security.WarnPatterns)
+}
+
+// appendMissing returns a copy of list with the values it lacks appended
+func appendMissing(list, values []string) []string {
+	merged := slices.Clone(list)
+	for _, v := range values {
+		if !slices.Contains(merged, v) {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+// forbidsExclusion reports whether the policy keeps pattern from being hidden
+func (p *Policy) forbidsExclusion(pattern string) bool {
+	if p == nil {
+		return false
+	}
+	for _, never := range p.NeverExclude {
+		if matchPattern(never, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiresWarning reports whether a warn pattern comes from the policy
+func (p *Policy) requiresWarning(warnPattern string) bool {
+	return p != nil && slices.Contains(p.Security.WarnPatterns, warnPattern)
+}
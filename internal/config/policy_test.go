@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// usePolicy points the policy path at a temp file holding content
+func usePolicy(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	prev := policyPath
+	policyPath = path
+	t.Cleanup(func() { policyPath = prev })
+}
+
+func TestPolicyEnforcedOverConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	usePolicy(t, "security:\n  warn_patterns: [\"Bash(curl:*)\"]\n  sensitive_paths: [.aws/credentials]\nnever_exclude:\n  - \"Bash(git push:*)\"\n")
+
+	user := "tool_groups:\n  - name: hidden\n    exclude: true\n    patterns: [\"Bash(git*)\"]\n  - name: git\n    color: green\n    patterns: [\"Bash(git*)\"]\n"
+	if err := os.MkdirAll(filepath.Dir(UserConfigPath()), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(UserConfigPath(), []byte(user), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A profile replacing the security rules can't drop the policy's
+	if err := ImportProfile("lax", []byte("security:\n  warn_patterns: []\n"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"", "lax"} {
+		cfg, err := LoadProfile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Contains(cfg.Security.WarnPatterns, "Bash(curl:*)") || !slices.Contains(cfg.Security.SensitivePaths, ".aws/credentials") {
+			t.Errorf("profile %q: expected the policy's rules enforced, got %+v", name, cfg.Security)
+		}
+		if cfg.ShouldExclude("Bash(git push:origin)") {
+			t.Errorf("profile %q: expected git push kept visible by the policy", name)
+		}
+		if group := cfg.GetToolGroup("Bash(git push:origin)"); group == nil || group.Name != "git" {
+			t.Errorf("profile %q: expected the next matching group to style git push, got %+v", name, group)
+		}
+		if !cfg.ShouldExclude("Bash(git status)") {
+			t.Errorf("profile %q: expected other exclusions kept", name)
+		}
+		if w := cfg.SecurityWarnings("Bash(curl:example.com)"); len(w) != 1 || !strings.Contains(w[0], "organization policy") {
+			t.Errorf("profile %q: expected the warning attributed to the policy, got %v", name, w)
+		}
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	prev := policyPath
+	policyPath = filepath.Join(t.TempDir(), "missing.yaml")
+	t.Cleanup(func() { policyPath = prev })
+	if p, err := LoadPolicy(); p != nil || err != nil {
+		t.Errorf("expected no policy without a file, got %+v, %v", p, err)
+	}
+
+	usePolicy(t, "security:\n  allowed_write_paths: [/]\n")
+	if _, err := LoadPolicy(); err == nil || !strings.Contains(err.Error(), "can't allow writes") {
+		t.Errorf("expected a policy relaxing rules to be refused, got %v", err)
+	}
+	if problems := ValidatePolicy([]byte("never_exclude: Bash(rm:*)\nexclusions: []\n")); len(problems) != 2 {
+		t.Errorf("expected a non-list and an unknown key reported, got %v", problems)
+	}
+}
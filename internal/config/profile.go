@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profileNamePattern limits profile names to what is safe as a file name
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ProfilesDir returns where named profiles are kept: a profiles directory
+// next to the user config
+func ProfilesDir() string {
+	return filepath.Join(filepath.Dir(UserConfigPath()), "profiles")
+}
+
+// ProfilePath returns the file holding the named profile
+func ProfilePath(name string) (string, error) {
+	if !profileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q (use letters, digits, - and _)", name)
+	}
+	return filepath.Join(ProfilesDir(), name+".yaml"), nil
+}
+
+// Profiles returns the names of the saved profiles, sorted
+func Profiles() ([]string, error) {
+	entries, err := os.ReadDir(ProfilesDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".yaml")
+		if ok && !entry.IsDir() && profileNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadProfile returns the config from the standard locations with the named
+// profile applied over it: keys the profile sets replace the config's, so
+// its tool_groups or security lists replace rather than extend them, though
+// not the organization policy's rules. An empty name returns the config
+// unchanged.
+func LoadProfile(name string) (*Config, error) {
+	cfg, err := LoadFromDefaultPath()
+	if err != nil || name == "" {
+		return cfg, err
+	}
+
+	data, err := ExportProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+	if err := cfg.applyLayoutPreset(data); err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+	cfg.defaultIntervals()
+	cfg.Profile = name
+	// The profile may have replaced the policy's rules; put them back
+	if err := cfg.EnforcePolicy(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ExportProfile returns the named profile's file contents, to share it
+func ExportProfile(name string) ([]byte, error) {
+	path, err := ProfilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path built from a validated profile name
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no profile named %q in %s", name, ProfilesDir())
+	}
+	return data, err
+}
+
+// ImportProfile saves data as the named profile after validating it. An
+// existing profile is only replaced when force is set.
+func ImportProfile(name string, data []byte, force bool) error {
+	path, err := ProfilePath(name)
+	if err != nil {
+		return err
+	}
+	if problems := Validate(data); len(problems) > 0 {
+		return fmt.Errorf("profile %s is invalid: %s (%d problem(s) in all; see `config validate`)", name, problems[0], len(problems))
+	}
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("profile %s already exists (use --force to replace it)", name)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
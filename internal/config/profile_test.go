@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir()) // No config.yaml in the working directory
+	base := "theme: latte\nsecurity:\n  sensitive_paths: [.env]\n  warn_patterns: [\"Bash(curl:*)\"]\n"
+	if err := os.MkdirAll(filepath.Dir(UserConfigPath()), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(UserConfigPath(), []byte(base), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	review := []byte("security:\n  warn_patterns: [\"Bash(git push:*)\"]\nlayout:\n  start_view: findings\n")
+	if err := ImportProfile("security-review", review, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportProfile("security-review", review, false); err == nil {
+		t.Error("expected an existing profile to be kept without force")
+	}
+	if err := ImportProfile("casual", []byte("theme: dracula\n"), false); err == nil || !strings.Contains(err.Error(), "dracula") {
+		t.Errorf("expected an invalid profile to be refused, got %v", err)
+	}
+	if err := ImportProfile("../escape", review, false); err == nil {
+		t.Error("expected a name with a path separator to be refused")
+	}
+
+	names, err := Profiles()
+	if err != nil || !slices.Equal(names, []string{"security-review"}) {
+		t.Fatalf("Profiles() = %v, %v", names, err)
+	}
+	if data, err := ExportProfile("security-review"); err != nil || string(data) != string(review) {
+		t.Errorf("ExportProfile() = %q, %v", data, err)
+	}
+
+	cfg, err := LoadProfile("security-review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != "security-review" || cfg.Theme != "latte" || cfg.Layout.StartView != "findings" {
+		t.Errorf("expected the profile applied over the config, got %q, %q, %q", cfg.Profile, cfg.Theme, cfg.Layout.StartView)
+	}
+	if !slices.Equal(cfg.Security.WarnPatterns, []string{"Bash(git push:*)"}) || !slices.Equal(cfg.Security.SensitivePaths, []string{".env"}) {
+		t.Errorf("expected the profile's lists to replace only the keys it sets, got %+v", cfg.Security)
+	}
+	if cfg.RefreshInterval != DefaultRefreshInterval {
+		t.Errorf("expected default intervals kept, got %v", cfg.RefreshInterval)
+	}
+
+	if _, err := LoadProfile("missing"); err == nil {
+		t.Error("expected an unknown profile to fail")
+	}
+	if cfg, err := LoadProfile(""); err != nil || cfg.Profile != "" || cfg.Theme != "latte" {
+		t.Errorf("expected no profile to load the config alone, got %+v, %v", cfg, err)
+	}
+}
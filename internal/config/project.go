@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the per-project override file looked up in each session's project directory
+const ProjectFileName = ".cc_session_mon.yaml"
+
+// projectCache holds merged configs by project path. Override files are read
+// once per process; restart to pick up edits.
+var (
+	projectCacheMu sync.Mutex
+	projectCache   = make(map[string]*Config)
+)
+
+// ForProject returns the global config merged with the project's
+// .cc_session_mon.yaml, if present. Project tool groups and categories are
+// checked before the global ones, and project security rules are added to the
+// global rules, except allowed_write_paths, which only the global config sets.
+// Returns Global() for an empty path or a project without an override file.
+func ForProject(projectPath string) *Config {
+	global := Global()
+	if projectPath == "" {
+		return global
+	}
+
+	projectCacheMu.Lock()
+	defer projectCacheMu.Unlock()
+
+	if cfg, ok := projectCache[projectPath]; ok {
+		return cfg
+	}
+
+	cfg := global
+	if override, err := loadOverride(filepath.Join(projectPath, ProjectFileName)); err == nil && override != nil {
+		cfg = global.WithOverride(override)
+	}
+	projectCache[projectPath] = cfg
+	return cfg
+}
+
+// loadOverride reads a project override file. Unlike Load, missing fields are
+// left empty rather than filled with defaults; a missing file returns nil.
+func loadOverride(path string) (*Config, error) {
+	data, err := os.ReadFile(filepath.Clean(path)) //nolint:gosec // path derived from session project path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// WithOverride returns a copy of c with the override's tool groups and
+// categories placed first (so they win) and its sensitive paths and warn
+// patterns appended. Its allowed write paths are ignored: a project file
+// may only make the rules stricter, and the agent being watched can write it.
+// The theme stays global.
+func (c *Config) WithOverride(override *Config) *Config {
+	merged := *c
+	merged.ToolGroups = append(append([]ToolGroup{}, override.ToolGroups...), c.ToolGroups...)
+	merged.Categories = append(append([]Category{}, override.Categories...), c.Categories...)
+	merged.Security = SecurityRules{
+		SensitivePaths:    append(append([]string{}, c.Security.SensitivePaths...), override.Security.SensitivePaths...),
+		WarnPatterns:      append(append([]string{}, c.Security.WarnPatterns...), override.Security.WarnPatterns...),
+		AllowedWritePaths: append([]string{}, c.Security.AllowedWritePaths...),
+	}
+	return &merged
+}
+
+// clearProjectCache drops merged project configs (called when the global config changes)
+func clearProjectCache() {
+	projectCacheMu.Lock()
+	defer projectCacheMu.Unlock()
+	projectCache = make(map[string]*Config)
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestForProject(t *testing.T) {
+	SetGlobal(&Config{
+		Theme: "mocha",
+		ToolGroups: []ToolGroup{
+			{Name: "bash", Color: "mauve", Patterns: []string{"Bash(*)"}},
+		},
+		Security: SecurityRules{SensitivePaths: []string{".env"}, AllowedWritePaths: []string{"~/scratch"}},
+	})
+	t.Cleanup(func() { SetGlobal(nil) })
+
+	projectDir := t.TempDir()
+	override := `theme: latte
+tool_groups:
+  - name: infra-danger
+    color: red
+    bold: true
+    patterns:
+      - "Bash(terraform:*)"
+  - name: hidden
+    exclude: true
+    patterns:
+      - "Bash(ls:*)"
+security:
+  sensitive_paths: ["terraform.tfstate"]
+  warn_patterns: ["Bash(terraform:*)"]
+  allowed_write_paths: ["/", "~/.ssh"]
+`
+	if err := os.WriteFile(filepath.Join(projectDir, ProjectFileName), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := ForProject(projectDir)
+
+	if cfg.Theme != "mocha" {
+		t.Errorf("Theme = %q, project overrides must not change the global theme", cfg.Theme)
+	}
+	if g := cfg.GetToolGroup("Bash(terraform:*)"); g == nil || g.Name != "infra-danger" {
+		t.Errorf("GetToolGroup(terraform) = %v, want project group infra-danger", g)
+	}
+	if g := cfg.GetToolGroup("Bash(git:*)"); g == nil || g.Name != "bash" {
+		t.Errorf("GetToolGroup(git) = %v, want global group bash", g)
+	}
+	if !cfg.ShouldExclude("Bash(ls:*)") {
+		t.Error("project exclusion should apply")
+	}
+	if got := cfg.Security.SensitivePaths; len(got) != 2 || got[0] != ".env" || got[1] != "terraform.tfstate" {
+		t.Errorf("SensitivePaths = %v, want global then project entries", got)
+	}
+	if got := cfg.Security.AllowedWritePaths; len(got) != 1 || got[0] != "~/scratch" {
+		t.Errorf("AllowedWritePaths = %v, want only the global entry (a project can't widen it)", got)
+	}
+	if w := cfg.SecurityWarnings("Bash(terraform:*)"); len(w) != 1 {
+		t.Errorf("SecurityWarnings() = %v, want one warning", w)
+	}
+
+	// Projects without an override file, and the empty path, use the global config
+	if ForProject(t.TempDir()) != Global() {
+		t.Error("project without override should use the global config")
+	}
+	if ForProject("") != Global() {
+		t.Error("empty project path should use the global config")
+	}
+	if Global().ShouldExclude("Bash(ls:*)") {
+		t.Error("project exclusion leaked into the global config")
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SavePath returns the config file settings changed at runtime are saved
+// to: the one in use, or where `config init` would write one
+func SavePath() string {
+	if path := FindPath(); path != "" {
+		return path
+	}
+	return UserConfigPath()
+}
+
+// AddProjectsDir appends dir to projects_dirs in the config file at path,
+// starting from the commented defaults when there is no file. Only the
+// projects_dirs lines are changed, so the rest of the file keeps its
+// comments and layout.
+func AddProjectsDir(path, dir string) error {
+	cleanPath := filepath.Clean(path)
+	data, err := os.ReadFile(cleanPath) //nolint:gosec // config path from known locations
+	if errors.Is(err, os.ErrNotExist) {
+		data = defaultYAML
+	} else if err != nil {
+		return err
+	}
+
+	updated, err := appendProjectsDir(data, dir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cleanPath, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cleanPath), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(cleanPath, updated, 0o600)
+}
+
+// appendProjectsDir returns data with dir added to its projects_dirs list
+func appendProjectsDir(data []byte, dir string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	item, err := yaml.Marshal(dir)
+	if err != nil {
+		return nil, err
+	}
+	entry := "- " + strings.TrimSpace(string(item))
+
+	var key, value *yaml.Node
+	if len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+		root := doc.Content[0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == "projects_dirs" {
+				key, value = root.Content[i], root.Content[i+1]
+			}
+		}
+	}
+
+	text := string(data)
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	lines := strings.SplitAfter(text, "\n")
+	switch {
+	case key == nil:
+		// No list yet: add one at the end
+		return fmt.Appendf(nil, "%s\n\nprojects_dirs:\n  %s\n", strings.TrimRight(text, "\n"), entry), nil
+	case value.Kind == yaml.SequenceNode && value.Style&yaml.FlowStyle == 0 && len(value.Content) > 0:
+		// A block list: add an item after the last one, indented the same
+		last := value.Content[len(value.Content)-1]
+		indent := strings.Repeat(" ", max(0, last.Column-3))
+		lines = append(lines[:last.Line], append([]string{indent + entry + "\n"}, lines[last.Line:]...)...)
+		return []byte(strings.Join(lines, "")), nil
+	case value.Line == key.Line && (value.Kind == yaml.SequenceNode || value.Tag == "!!null"):
+		// projects_dirs: [...] or an empty value on the key's line: rewrite
+		// it as a block list
+		block := "projects_dirs:\n"
+		for _, existing := range value.Content {
+			item, err := yaml.Marshal(existing.Value)
+			if err != nil {
+				return nil, err
+			}
+			block += "  - " + strings.TrimSpace(string(item)) + "\n"
+		}
+		block += "  " + entry + "\n"
+		lines[key.Line-1] = block
+		return []byte(strings.Join(lines, "")), nil
+	}
+	return nil, errors.New("projects_dirs isn't a list that can be extended; add the directory by hand")
+}
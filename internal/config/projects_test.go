@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAppendProjectsDir(t *testing.T) {
+	tests := []struct {
+		name, config string
+		want         []string
+	}{
+		{"no list", "# comment\ntheme: latte\n", []string{"/mnt/b"}},
+		{"block list", "projects_dirs:\n    - /mnt/a # synced\ntheme: latte\n", []string{"/mnt/a", "/mnt/b"}},
+		{"flow list", "projects_dirs: [/mnt/a]\ntheme: latte\n", []string{"/mnt/a", "/mnt/b"}},
+		{"empty value", "projects_dirs:\ntheme: latte", []string{"/mnt/b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := appendProjectsDir([]byte(tt.config), "/mnt/b")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if problems := Validate(out); len(problems) > 0 {
+				t.Fatalf("invalid result %v:\n%s", problems, out)
+			}
+			var cfg Config
+			if err := yaml.Unmarshal(out, &cfg); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(cfg.ProjectsDirs, tt.want) || cfg.Theme != "latte" {
+				t.Errorf("got projects_dirs %v, theme %q, want %v:\n%s", cfg.ProjectsDirs, cfg.Theme, tt.want, out)
+			}
+		})
+	}
+
+	// Comments elsewhere survive
+	out, _ := appendProjectsDir([]byte("projects_dirs:\n  - /mnt/a # synced\n"), "/mnt/b")
+	if !strings.Contains(string(out), "# synced") {
+		t.Errorf("expected comments to be kept:\n%s", out)
+	}
+}
+
+func TestAddProjectsDirWithoutConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cc_session_mon", "config.yaml")
+	if err := AddProjectsDir(path, "/mnt/laptop/.claude/projects"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.ProjectsDirs, []string{"/mnt/laptop/.claude/projects"}) {
+		t.Errorf("ProjectsDirs = %v", cfg.ProjectsDirs)
+	}
+	data, _ := os.ReadFile(path) //nolint:gosec // test file
+	if !strings.Contains(string(data), "# Extra Claude projects directories") {
+		t.Error("expected the new config to start from the commented defaults")
+	}
+}
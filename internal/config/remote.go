@@ -0,0 +1,42 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultRemotePollInterval is how often a remote source is listed when its
+// poll_interval is unset
+const DefaultRemotePollInterval = time.Minute
+
+// RemoteSource is a cloud storage prefix session files are archived under,
+// laid out like a projects directory (<prefix><project>/<session>.jsonl)
+type RemoteSource struct {
+	// URL is s3://bucket/prefix or gs://bucket/prefix
+	URL string `yaml:"url"`
+
+	// Name labels the source's sessions with origin remote:<name>; empty
+	// uses the bucket name
+	Name string `yaml:"name"`
+
+	// PollInterval is how often new and grown objects are fetched
+	PollInterval time.Duration `yaml:"poll_interval"`
+}
+
+// Label returns the source's name, or its bucket if it has none
+func (r RemoteSource) Label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	_, rest, _ := strings.Cut(r.URL, "://")
+	bucket, _, _ := strings.Cut(rest, "/")
+	return bucket
+}
+
+// Interval returns the source's poll interval, or the default if unset
+func (r RemoteSource) Interval() time.Duration {
+	if r.PollInterval <= 0 {
+		return DefaultRemotePollInterval
+	}
+	return r.PollInterval
+}
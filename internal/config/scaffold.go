@@ -0,0 +1,48 @@
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultYAML is a fully commented equivalent of DefaultConfig
+//
+//go:embed default.yaml
+var defaultYAML []byte
+
+// DefaultYAML returns the commented default config file contents
+func DefaultYAML() []byte {
+	return defaultYAML
+}
+
+// UserConfigPath returns where `config init` writes the config:
+// $XDG_CONFIG_HOME/cc_session_mon/config.yaml, or ~/.config/cc_session_mon/config.yaml
+func UserConfigPath() string {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "cc_session_mon", "config.yaml")
+}
+
+// WriteDefault writes the commented default config to path, creating parent
+// directories. An existing file is only replaced when force is set.
+func WriteDefault(path string, force bool) error {
+	cleanPath := filepath.Clean(path)
+	if !force {
+		if _, err := os.Stat(cleanPath); err == nil {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", cleanPath)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cleanPath), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(cleanPath, defaultYAML, 0o600)
+}
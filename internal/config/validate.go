@@ -0,0 +1,656 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a config validation finding tied to a line in the YAML source
+type Problem struct {
+	Line    int
+	Message string
+}
+
+// String formats the problem as "N: message", suitable for a "path:" prefix
+func (p Problem) String() string {
+	return fmt.Sprintf("%d: %s", p.Line, p.Message)
+}
+
+// themes are the accepted values for Config.Theme
+var themes = map[string]bool{"mocha": true, "macchiato": true, "frappe": true, "latte": true}
+
+// IsTheme reports whether name is an accepted theme
+func IsTheme(name string) bool {
+	return themes[name]
+}
+
+// colorNames are the catppuccin color names accepted for ToolGroup.Color
+var colorNames = map[string]bool{
+	"rosewater": true, "flamingo": true, "pink": true, "mauve": true, "red": true,
+	"maroon": true, "peach": true, "yellow": true, "green": true, "teal": true,
+	"sky": true, "sapphire": true, "blue": true, "lavender": true, "text": true,
+	"subtext1": true, "subtext0": true, "overlay2": true, "overlay1": true,
+	"overlay0": true, "surface2": true, "surface1": true, "surface0": true,
+	"base": true, "mantle": true, "crust": true,
+}
+
+// groupKeys and securityKeys are the fields ToolGroup and SecurityRules decode
+var (
+	groupKeys    = map[string]bool{"name": true, "color": true, "bold": true, "patterns": true, "exclude": true}
+	securityKeys = map[string]bool{"sensitive_paths": true, "warn_patterns": true, "allowed_write_paths": true}
+)
+
+// yamlErrorLine extracts the line number from yaml.v3 error messages
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// seenPattern records where an earlier group's or category's pattern was declared
+type seenPattern struct {
+	pattern string
+	group   string // "group <name>" or "category <name>"
+	line    int
+}
+
+// Validate checks a config file's contents for syntax errors, unknown keys,
+// unknown themes and colors, malformed patterns, and patterns that can never
+// match because an earlier group or category already claims them. Problems
+// are sorted by line.
+func Validate(data []byte) []Problem {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		line := 0
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		return []Problem{{Line: line, Message: strings.TrimPrefix(err.Error(), "yaml: ")}}
+	}
+	if len(doc.Content) == 0 {
+		return nil // Empty file: defaults apply
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return []Problem{{Line: root.Line, Message: "config must be a mapping"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "theme":
+			if !themes[value.Value] {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("unknown theme %q (want mocha, macchiato, frappe, or latte)", value.Value)})
+			}
+		case "tool_groups":
+			problems = append(problems, validateToolGroups(value)...)
+		case "categories":
+			problems = append(problems, validateCategories(value)...)
+		case "security":
+			problems = append(problems, validateSecurity(value)...)
+		case "commands":
+			problems = append(problems, validateCommands(value)...)
+		case "alerts":
+			problems = append(problems, validateAlerts(value)...)
+		case "clock":
+			problems = append(problems, validateClock(value)...)
+		case "layout":
+			problems = append(problems, validateLayout(value)...)
+		case "refresh_interval", "activity_window", "devagent_poll_interval", "subagent_scan_interval":
+			if d, err := time.ParseDuration(value.Value); err != nil || d <= 0 {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("%s must be a positive duration like 30s or 5m, got %q", key.Value, value.Value)})
+			}
+		case "archive_dir", "state_dir", "cache_dir":
+			if value.Kind != yaml.ScalarNode || value.Tag == "!!null" {
+				problems = append(problems, Problem{value.Line, key.Value + " must be a path"})
+			}
+		case "homes":
+			problems = append(problems, validateHomes(value)...)
+		case "projects_dirs":
+			problems = append(problems, validateProjectsDirs(value)...)
+		case "shared_dirs":
+			problems = append(problems, validateSharedDirs(value)...)
+		case "remote_sources":
+			problems = append(problems, validateRemoteSources(value)...)
+		case "watch_strategy":
+			if !watchStrategies[value.Value] {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("unknown watch_strategy %q (want directories, projects, or poll)", value.Value)})
+			}
+		case "owners":
+			problems = append(problems, validateOwners(value)...)
+		case "check":
+			problems = append(problems, validateCheck(value)...)
+		case "scrub":
+			problems = append(problems, validateScrub(value)...)
+		case "persist_search_history", "git_snapshots", "text_indicators":
+			if value.Tag != "!!bool" {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("%s must be true or false, got %q", key.Value, value.Value)})
+			}
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown key %q", key.Value)})
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+	return problems
+}
+
+// validateClock checks the timezone and the per-origin offsets
+func validateClock(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, "clock must be a mapping"}}
+	}
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "timezone":
+			if _, err := time.LoadLocation(value.Value); err != nil {
+				problems = append(problems, Problem{value.Line, fmt.Sprintf("unknown timezone %q", value.Value)})
+			}
+		case "offsets":
+			if value.Kind != yaml.MappingNode {
+				problems = append(problems, Problem{value.Line, "clock.offsets must map origins to durations"})
+				continue
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				origin, offset := value.Content[j], value.Content[j+1]
+				if _, err := time.ParseDuration(offset.Value); err != nil {
+					problems = append(problems, Problem{offset.Line,
+						fmt.Sprintf("clock offset for %q must be a duration like -90s or 2m, got %q", origin.Value, offset.Value)})
+				}
+			}
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown clock key %q", key.Value)})
+		}
+	}
+	return problems
+}
+
+// watchStrategies are the values watch_strategy may take
+var watchStrategies = map[string]bool{"directories": true, "projects": true, "poll": true}
+
+// startViews are the views layout.start_view may name
+var startViews = map[string]bool{"sessions": true, "commands": true, "patterns": true, "findings": true, "analytics": true}
+
+// validateLayout checks the start view and the layout switches
+func validateLayout(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, "layout must be a mapping"}}
+	}
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "start_view":
+			if !startViews[value.Value] {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("unknown start_view %q (want sessions, commands, patterns, findings, or analytics)", value.Value)})
+			}
+		case "preset":
+			if _, ok := LayoutPresets[value.Value]; !ok {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("unknown layout preset %q (want %s)", value.Value, LayoutPresetNames())})
+			}
+		case "summaries", "hide_preview", "detail_panel", "expand_heredocs", "show_thinking":
+			if value.Tag != "!!bool" {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("layout.%s must be true or false, got %q", key.Value, value.Value)})
+			}
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown layout key %q", key.Value)})
+		}
+	}
+	return problems
+}
+
+// validateToolGroups checks each group in the tool_groups sequence
+func validateToolGroups(node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "tool_groups must be a list"}}
+	}
+
+	var problems []Problem
+	var earlier []seenPattern
+	for _, group := range node.Content {
+		if group.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{group.Line, "tool group must be a mapping"})
+			continue
+		}
+		groupProblems, patterns := validateToolGroup(group, earlier)
+		problems = append(problems, groupProblems...)
+		earlier = append(earlier, patterns...)
+	}
+	return problems
+}
+
+// validateToolGroup checks one group's fields and returns its patterns so
+// later groups can be checked for shadowing
+func validateToolGroup(group *yaml.Node, earlier []seenPattern) ([]Problem, []seenPattern) {
+	var problems []Problem
+	var name, color string
+	var colorLine int
+	var exclude bool
+	var patternsNode *yaml.Node
+
+	for i := 0; i+1 < len(group.Content); i += 2 {
+		key, value := group.Content[i], group.Content[i+1]
+		switch key.Value {
+		case "name":
+			name = value.Value
+		case "color":
+			color, colorLine = value.Value, value.Line
+		case "exclude":
+			exclude = value.Value == "true"
+		case "patterns":
+			patternsNode = value
+		default:
+			if !groupKeys[key.Value] {
+				problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown tool group key %q", key.Value)})
+			}
+		}
+	}
+
+	label := name
+	if label == "" {
+		label = "(unnamed)"
+		problems = append(problems, Problem{group.Line, "tool group has no name"})
+	}
+	problems = append(problems, validateGroupColor(label, color, colorLine, group.Line, exclude)...)
+
+	if patternsNode == nil || patternsNode.Kind != yaml.SequenceNode || len(patternsNode.Content) == 0 {
+		problems = append(problems, Problem{group.Line, fmt.Sprintf("group %s: no patterns", label)})
+		return problems, nil
+	}
+
+	var seen []seenPattern
+	for _, p := range patternsNode.Content {
+		problems = append(problems, validatePattern("group "+label, p, earlier)...)
+		seen = append(seen, seenPattern{pattern: p.Value, group: "group " + label, line: p.Line})
+	}
+	return problems, seen
+}
+
+// validateCategories checks each category's name and patterns, including
+// patterns an earlier category already claims
+func validateCategories(node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "categories must be a list"}}
+	}
+
+	var problems []Problem
+	var earlier []seenPattern
+	for _, category := range node.Content {
+		if category.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{category.Line, "category must be a mapping"})
+			continue
+		}
+		var name string
+		var patternsNode *yaml.Node
+		for i := 0; i+1 < len(category.Content); i += 2 {
+			key, value := category.Content[i], category.Content[i+1]
+			switch key.Value {
+			case "name":
+				name = value.Value
+			case "patterns":
+				patternsNode = value
+			default:
+				problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown category key %q", key.Value)})
+			}
+		}
+
+		label := "category " + name
+		if name == "" {
+			label = "category (unnamed)"
+			problems = append(problems, Problem{category.Line, "category has no name"})
+		}
+		if patternsNode == nil || patternsNode.Kind != yaml.SequenceNode || len(patternsNode.Content) == 0 {
+			problems = append(problems, Problem{category.Line, label + ": no patterns"})
+			continue
+		}
+		for _, p := range patternsNode.Content {
+			problems = append(problems, validatePattern(label, p, earlier)...)
+			earlier = append(earlier, seenPattern{pattern: p.Value, group: label, line: p.Line})
+		}
+	}
+	return problems
+}
+
+// validateGroupColor checks that a group's color is a catppuccin name, and
+// that non-excluded groups set one
+func validateGroupColor(label, color string, colorLine, groupLine int, exclude bool) []Problem {
+	switch {
+	case color != "" && !colorNames[color]:
+		return []Problem{{colorLine, fmt.Sprintf("group %s: unknown color %q", label, color)}}
+	case color == "" && !exclude:
+		return []Problem{{groupLine, fmt.Sprintf("group %s: no color set", label)}}
+	}
+	return nil
+}
+
+// validatePattern checks a single pattern's syntax and whether an earlier
+// group's (or category's) pattern already matches everything it would
+func validatePattern(group string, node *yaml.Node, earlier []seenPattern) []Problem {
+	p := node.Value
+	switch {
+	case strings.TrimSpace(p) == "":
+		return []Problem{{node.Line, fmt.Sprintf("%s: empty pattern", group)}}
+	case strings.Count(p, "*") > 1:
+		return []Problem{{node.Line,
+			fmt.Sprintf("%s: pattern %q has more than one *, only the first is a wildcard", group, p)}}
+	}
+
+	for _, e := range earlier {
+		if matchPattern(e.pattern, p) {
+			return []Problem{{node.Line, fmt.Sprintf("%s: pattern %q is unreachable, %s claims it first (%q, line %d)",
+				group, p, e.group, e.pattern, e.line)}}
+		}
+	}
+	return nil
+}
+
+// validateSecurity checks the security section's keys and warn pattern syntax
+func validateSecurity(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, "security must be a mapping"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !securityKeys[key.Value] {
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown security key %q", key.Value)})
+			continue
+		}
+		if value.Kind != yaml.SequenceNode {
+			problems = append(problems, Problem{value.Line, fmt.Sprintf("security.%s must be a list", key.Value)})
+			continue
+		}
+		if key.Value == "warn_patterns" {
+			for _, p := range value.Content {
+				if strings.Count(p.Value, "*") > 1 {
+					problems = append(problems, Problem{p.Line,
+						fmt.Sprintf("warn pattern %q has more than one *, only the first is a wildcard", p.Value)})
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// validateHomes checks that homes is a list of absolute path globs
+func validateHomes(node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "homes must be a list"}}
+	}
+
+	var problems []Problem
+	for _, p := range node.Content {
+		if _, err := filepath.Match(p.Value, ""); err != nil {
+			problems = append(problems, Problem{p.Line, fmt.Sprintf("home glob %q is malformed", p.Value)})
+		} else if !filepath.IsAbs(p.Value) {
+			problems = append(problems, Problem{p.Line, fmt.Sprintf("home glob %q must be an absolute path", p.Value)})
+		}
+	}
+	return problems
+}
+
+// validateProjectsDirs checks that projects_dirs is a list of absolute
+// paths or globs, or ones starting with ~ or an environment variable
+func validateProjectsDirs(node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "projects_dirs must be a list"}}
+	}
+
+	var problems []Problem
+	for _, p := range node.Content {
+		if _, err := filepath.Match(p.Value, ""); err != nil {
+			problems = append(problems, Problem{p.Line, fmt.Sprintf("projects dir glob %q is malformed", p.Value)})
+		} else if !filepath.IsAbs(p.Value) && !strings.HasPrefix(p.Value, "~") && !strings.HasPrefix(p.Value, "$") {
+			problems = append(problems, Problem{p.Line, fmt.Sprintf("projects dir %q must be an absolute path, or start with ~ or $VAR", p.Value)})
+		}
+	}
+	return problems
+}
+
+// validateSharedDirs checks that shared_dirs is a list of absolute paths,
+// or ones starting with ~ or an environment variable
+func validateSharedDirs(node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "shared_dirs must be a list"}}
+	}
+
+	var problems []Problem
+	for _, p := range node.Content {
+		if !filepath.IsAbs(p.Value) && !strings.HasPrefix(p.Value, "~") && !strings.HasPrefix(p.Value, "$") {
+			problems = append(problems, Problem{p.Line, fmt.Sprintf("shared dir %q must be an absolute path, or start with ~ or $VAR", p.Value)})
+		}
+	}
+	return problems
+}
+
+// validateRemoteSources checks that each remote source has an s3:// or gs://
+// URL naming a bucket, and a positive poll interval if it sets one
+func validateRemoteSources(node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "remote_sources must be a list"}}
+	}
+
+	var problems []Problem
+	for _, source := range node.Content {
+		if source.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{source.Line, "remote source must be a mapping"})
+			continue
+		}
+		url := ""
+		for i := 0; i+1 < len(source.Content); i += 2 {
+			key, value := source.Content[i], source.Content[i+1]
+			switch key.Value {
+			case "url":
+				url = value.Value
+				scheme, rest, _ := strings.Cut(url, "://")
+				if bucket, _, _ := strings.Cut(rest, "/"); (scheme != "s3" && scheme != "gs") || bucket == "" {
+					problems = append(problems, Problem{value.Line, fmt.Sprintf("remote source url %q must be s3://bucket/prefix or gs://bucket/prefix", url)})
+				}
+			case "name":
+			case "poll_interval":
+				if d, err := time.ParseDuration(value.Value); err != nil || d <= 0 {
+					problems = append(problems, Problem{value.Line,
+						fmt.Sprintf("poll_interval must be a positive duration like 30s or 5m, got %q", value.Value)})
+				}
+			default:
+				problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown remote source key %q", key.Value)})
+			}
+		}
+		if url == "" {
+			problems = append(problems, Problem{source.Line, "remote source has no url"})
+		}
+	}
+	return problems
+}
+
+// validateOwners checks that each owner has a name and origin or project
+// patterns to match
+func validateOwners(node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "owners must be a list"}}
+	}
+
+	var problems []Problem
+	for _, owner := range node.Content {
+		if owner.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{owner.Line, "owner must be a mapping"})
+			continue
+		}
+		name, patterns := "", 0
+		for i := 0; i+1 < len(owner.Content); i += 2 {
+			key, value := owner.Content[i], owner.Content[i+1]
+			switch key.Value {
+			case "name":
+				name = value.Value
+			case "origins", "projects":
+				if value.Kind != yaml.SequenceNode {
+					problems = append(problems, Problem{value.Line, fmt.Sprintf("owner %s must be a list", key.Value)})
+					continue
+				}
+				patterns += len(value.Content)
+			default:
+				problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown owner key %q", key.Value)})
+			}
+		}
+		switch {
+		case name == "":
+			problems = append(problems, Problem{owner.Line, "owner has no name"})
+		case patterns == 0:
+			problems = append(problems, Problem{owner.Line, fmt.Sprintf("owner %q has no origins or projects", name)})
+		}
+	}
+	return problems
+}
+
+// validateCheck checks the check section: a severity and deny patterns
+func validateCheck(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, "check must be a mapping"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "fail_on":
+			problems = append(problems, validateSeverity(value)...)
+		case "deny":
+			if value.Kind != yaml.SequenceNode {
+				problems = append(problems, Problem{value.Line, "check.deny must be a list"})
+				continue
+			}
+			for _, p := range value.Content {
+				if strings.Count(p.Value, "*") > 1 {
+					problems = append(problems, Problem{p.Line,
+						fmt.Sprintf("deny pattern %q has more than one *, only the first is a wildcard", p.Value)})
+				}
+			}
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown check key %q", key.Value)})
+		}
+	}
+	return problems
+}
+
+// validateScrub checks the scrub switches and that patterns compile
+func validateScrub(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, "scrub must be a mapping"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "strip_tool_results", "mask_secrets", "drop_thinking":
+			if value.Tag != "!!bool" {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("scrub.%s must be true or false, got %q", key.Value, value.Value)})
+			}
+		case "patterns":
+			if value.Kind != yaml.SequenceNode {
+				problems = append(problems, Problem{value.Line, "scrub.patterns must be a list"})
+				continue
+			}
+			for _, p := range value.Content {
+				if _, err := regexp.Compile(p.Value); err != nil {
+					problems = append(problems, Problem{p.Line, fmt.Sprintf("scrub pattern %q: %v", p.Value, err)})
+				}
+			}
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown scrub key %q", key.Value)})
+		}
+	}
+	return problems
+}
+
+// validateCommands checks the command knowledge base: depths must be
+// non-negative integers and wrappers non-empty
+func validateCommands(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, "commands must be a mapping"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "subcommand_depth", "pattern_depth_by_command":
+			problems = append(problems, validateDepthMap(key.Value, value)...)
+		case "pattern_depth":
+			if n, err := strconv.Atoi(value.Value); err != nil || n < 0 {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("commands.pattern_depth must be a non-negative integer, got %q", value.Value)})
+			}
+		case "flags_with_args":
+			if value.Kind != yaml.MappingNode {
+				problems = append(problems, Problem{value.Line, "commands.flags_with_args must be a mapping of command: [flags]"})
+				continue
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				problems = append(problems, validateFlagList(value.Content[j].Value, value.Content[j+1])...)
+			}
+		case "wrappers":
+			if value.Kind != yaml.SequenceNode {
+				problems = append(problems, Problem{value.Line, "commands.wrappers must be a list"})
+				continue
+			}
+			for _, w := range value.Content {
+				if strings.TrimSpace(w.Value) == "" {
+					problems = append(problems, Problem{w.Line, "empty wrapper"})
+				}
+			}
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown commands key %q", key.Value)})
+		}
+	}
+	return problems
+}
+
+// validateDepthMap checks a command: depth mapping
+func validateDepthMap(name string, node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, fmt.Sprintf("commands.%s must be a mapping of command: depth", name)}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		cmd, depth := node.Content[i], node.Content[i+1]
+		if n, err := strconv.Atoi(depth.Value); err != nil || n < 0 {
+			problems = append(problems, Problem{depth.Line,
+				fmt.Sprintf("%s for %q must be a non-negative integer, got %q", strings.ReplaceAll(name, "_", " "), cmd.Value, depth.Value)})
+		}
+	}
+	return problems
+}
+
+// validateFlagList checks one command's flags_with_args entry
+func validateFlagList(cmd string, node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, fmt.Sprintf("flags_with_args for %q must be a list", cmd)}}
+	}
+
+	var problems []Problem
+	for _, f := range node.Content {
+		if !strings.HasPrefix(f.Value, "-") {
+			problems = append(problems, Problem{f.Line, fmt.Sprintf("flags_with_args for %q: %q is not a flag", cmd, f.Value)})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,520 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// severities are the accepted values for AlertRules.MinSeverity
+var severities = map[string]bool{"low": true, "medium": true, "high": true}
+
+// validateAlerts checks the alerts section: severity names, sink settings,
+// and that routes name declared sinks
+func validateAlerts(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, "alerts must be a mapping"}}
+	}
+
+	var problems []Problem
+	declared := make(map[string]bool)
+	var routes, actions, thresholds *yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "min_severity":
+			problems = append(problems, validateSeverity(value)...)
+		case "dashboard_url":
+		case "email":
+			declared["email"] = true
+			problems = append(problems, validateEmailSink(value)...)
+		case "slack", "discord":
+			declared[key.Value] = true
+			problems = append(problems, validateChatSink(key.Value, value)...)
+		case "sinks":
+			problems = append(problems, validateSinks(value, declared)...)
+		case "delivery":
+			problems = append(problems, validateDelivery(value)...)
+		case "routes":
+			routes = value
+		case "actions":
+			actions = value
+		case "thresholds":
+			thresholds = value
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown alerts key %q", key.Value)})
+		}
+	}
+	if routes != nil {
+		problems = append(problems, validateRoutes(routes, declared)...)
+	}
+	if actions != nil {
+		problems = append(problems, validateActions(actions, declared)...)
+	}
+	if thresholds != nil {
+		problems = append(problems, validateThresholds(thresholds)...)
+	}
+	return problems
+}
+
+// validateSeverity checks a severity name
+func validateSeverity(node *yaml.Node) []Problem {
+	if !severities[node.Value] {
+		return []Problem{{node.Line, fmt.Sprintf("unknown severity %q (want low, medium, or high)", node.Value)}}
+	}
+	return nil
+}
+
+// validateEmailSink checks the alerts.email shorthand
+func validateEmailSink(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, "alerts.email must be a mapping"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		keyProblems, known := validateEmailKey("alerts.email", key.Value, value)
+		if !known {
+			keyProblems = []Problem{{key.Line, fmt.Sprintf("unknown alerts.email key %q", key.Value)}}
+		}
+		problems = append(problems, keyProblems...)
+	}
+	return problems
+}
+
+// validateEmailKey checks one SMTP setting (port, batch window, recipients,
+// templates), reporting whether key is an email setting at all
+func validateEmailKey(prefix, key string, value *yaml.Node) ([]Problem, bool) {
+	switch key {
+	case "host", "username", "password_env", "from":
+		return nil, true
+	case "port":
+		if n, err := strconv.Atoi(value.Value); err != nil || n < 1 || n > 65535 {
+			return []Problem{{value.Line, fmt.Sprintf("%s.port must be a port number, got %q", prefix, value.Value)}}, true
+		}
+		return nil, true
+	case "to":
+		if value.Kind != yaml.SequenceNode {
+			return []Problem{{value.Line, prefix + ".to must be a list"}}, true
+		}
+		return nil, true
+	case "batch_window":
+		return validateDuration(prefix+".batch_window", value), true
+	case "subject", "body":
+		return validateTemplate(prefix+"."+key, value), true
+	case "templates":
+		return validateEmailTemplates(prefix, value), true
+	}
+	return nil, false
+}
+
+// validateChatSink checks a Slack or Discord webhook shorthand's keys
+func validateChatSink(name string, node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, fmt.Sprintf("alerts.%s must be a mapping", name)}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if key.Value != "webhook_url" && key.Value != "webhook_url_env" {
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown alerts.%s key %q", name, key.Value)})
+		}
+	}
+	return problems
+}
+
+// validateSinks checks each named sink and records its name in declared
+func validateSinks(node *yaml.Node, declared map[string]bool) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "alerts.sinks must be a list"}}
+	}
+
+	var problems []Problem
+	for _, sink := range node.Content {
+		if sink.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{sink.Line, "sink must be a mapping"})
+			continue
+		}
+		name, sinkProblems := validateSink(sink)
+		problems = append(problems, sinkProblems...)
+		switch {
+		case name == "":
+		case declared[name]:
+			problems = append(problems, Problem{sink.Line, fmt.Sprintf("duplicate sink name %q", name)})
+		default:
+			declared[name] = true
+		}
+	}
+	return problems
+}
+
+// validateSink checks one sink's name, type, and the keys its type reads
+func validateSink(sink *yaml.Node) (string, []Problem) {
+	var name, typ string
+	for i := 0; i+1 < len(sink.Content); i += 2 {
+		switch key, value := sink.Content[i], sink.Content[i+1]; key.Value {
+		case "name":
+			name = value.Value
+		case "type":
+			typ = value.Value
+		}
+	}
+
+	label := name
+	if label == "" {
+		label = "(unnamed)"
+	}
+	var problems []Problem
+	switch {
+	case name == "":
+		problems = append(problems, Problem{sink.Line, "sink has no name"})
+	case typ == "":
+		problems = append(problems, Problem{sink.Line, fmt.Sprintf("sink %s has no type", label)})
+		return name, problems
+	case !slices.Contains(SinkTypes, typ):
+		problems = append(problems, Problem{sink.Line, fmt.Sprintf("sink %s: unknown type %q (want one of %v)", label, typ, SinkTypes)})
+		return name, problems
+	}
+
+	for i := 0; i+1 < len(sink.Content); i += 2 {
+		key, value := sink.Content[i], sink.Content[i+1]
+		known := key.Value == "name" || key.Value == "type"
+		var keyProblems []Problem
+		switch typ {
+		case "email":
+			keyProblems, known = validateEmailKey("sink "+label, key.Value, value)
+			known = known || key.Value == "name" || key.Value == "type"
+		case "slack", "discord", "webhook":
+			known = known || key.Value == "webhook_url" || key.Value == "webhook_url_env"
+		case "syslog":
+			known = known || key.Value == "tag"
+		case "exec":
+			keyProblems, known = validateExecKey(label, key.Value, value)
+			known = known || key.Value == "name" || key.Value == "type"
+		}
+		if !known {
+			keyProblems, known = validateDeliveryKey("sink "+label, key.Value, value)
+		}
+		if !known {
+			keyProblems = []Problem{{key.Line, fmt.Sprintf("sink %s: unknown key %q for a %s sink", label, key.Value, typ)}}
+		}
+		problems = append(problems, keyProblems...)
+	}
+	return name, problems
+}
+
+// validateDelivery checks the alerts.delivery defaults
+func validateDelivery(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, "alerts.delivery must be a mapping"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		keyProblems, known := validateDeliveryKey("alerts.delivery", key.Value, value)
+		if !known {
+			keyProblems = []Problem{{key.Line, fmt.Sprintf("unknown alerts.delivery key %q", key.Value)}}
+		}
+		problems = append(problems, keyProblems...)
+	}
+	return problems
+}
+
+// validateDeliveryKey checks a rate limit, dedup window, or quiet hours
+// setting, reporting whether key is one at all
+func validateDeliveryKey(prefix, key string, value *yaml.Node) ([]Problem, bool) {
+	switch key {
+	case "rate_limit":
+		if n, err := strconv.Atoi(value.Value); err != nil || n < 0 {
+			return []Problem{{value.Line, fmt.Sprintf("%s: rate_limit must be a number of alerts, got %q", prefix, value.Value)}}, true
+		}
+		return nil, true
+	case "rate_period", "dedup_window":
+		return validateDuration(prefix+": "+key, value), true
+	case "quiet_hours":
+		return validateQuietHours(prefix, value), true
+	}
+	return nil, false
+}
+
+// validateQuietHours checks a quiet_hours mapping of from, to, and min_severity
+func validateQuietHours(prefix string, node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, prefix + ": quiet_hours must be a mapping of from, to, and min_severity"}}
+	}
+
+	var problems []Problem
+	var from, to bool
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "from", "to":
+			if key.Value == "from" {
+				from = true
+			} else {
+				to = true
+			}
+			if _, err := parseClock(value.Value); err != nil {
+				problems = append(problems, Problem{value.Line, fmt.Sprintf("%s: quiet_hours %s must be a time like 22:00, got %q", prefix, key.Value, value.Value)})
+			}
+		case "min_severity":
+			problems = append(problems, validateSeverity(value)...)
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("%s: unknown quiet_hours key %q", prefix, key.Value)})
+		}
+	}
+	if !from || !to {
+		problems = append(problems, Problem{node.Line, prefix + ": quiet_hours needs both from and to"})
+	}
+	return problems
+}
+
+// validateExecKey checks an exec sink's command and timeout, reporting
+// whether key is an exec setting at all
+func validateExecKey(label, key string, value *yaml.Node) ([]Problem, bool) {
+	switch key {
+	case "command":
+		if value.Kind != yaml.SequenceNode || len(value.Content) == 0 {
+			return []Problem{{value.Line, fmt.Sprintf("sink %s: command must be a non-empty list of program and arguments", label)}}, true
+		}
+		return nil, true
+	case "timeout":
+		return validateDuration(fmt.Sprintf("sink %s: timeout", label), value), true
+	}
+	return nil, false
+}
+
+// validateRoutes checks that each route names declared sinks and valid severities
+func validateRoutes(node *yaml.Node, declared map[string]bool) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "alerts.routes must be a list"}}
+	}
+
+	var problems []Problem
+	for _, route := range node.Content {
+		if route.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{route.Line, "route must be a mapping"})
+			continue
+		}
+		hasSinks := false
+		for i := 0; i+1 < len(route.Content); i += 2 {
+			key, value := route.Content[i], route.Content[i+1]
+			switch key.Value {
+			case "rules":
+				if value.Kind != yaml.SequenceNode {
+					problems = append(problems, Problem{value.Line, "route rules must be a list"})
+				}
+			case "min_severity":
+				problems = append(problems, validateSeverity(value)...)
+			case "sinks":
+				hasSinks = true
+				problems = append(problems, validateRouteSinks(value, declared)...)
+			default:
+				problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown route key %q", key.Value)})
+			}
+		}
+		if !hasSinks {
+			problems = append(problems, Problem{route.Line, "route has no sinks"})
+		}
+	}
+	return problems
+}
+
+// validateRouteSinks checks that a route's sinks list names declared sinks
+func validateRouteSinks(node *yaml.Node, declared map[string]bool) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "route sinks must be a list"}}
+	}
+	var problems []Problem
+	for _, s := range node.Content {
+		if !declared[s.Value] {
+			problems = append(problems, Problem{s.Line, fmt.Sprintf("route names unknown sink %q", s.Value)})
+		}
+	}
+	return problems
+}
+
+// validateActions checks each action's matchers and that it does something
+func validateActions(node *yaml.Node, declared map[string]bool) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "alerts.actions must be a list"}}
+	}
+
+	var problems []Problem
+	for _, action := range node.Content {
+		if action.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{action.Line, "action must be a mapping"})
+			continue
+		}
+		problems = append(problems, validateAction(action, declared)...)
+	}
+	return problems
+}
+
+// validateAction checks one action's keys
+func validateAction(action *yaml.Node, declared map[string]bool) []Problem {
+	var problems []Problem
+	var name string
+	var matches, acts bool
+	for i := 0; i+1 < len(action.Content); i += 2 {
+		key, value := action.Content[i], action.Content[i+1]
+		switch key.Value {
+		case "name":
+			name = value.Value
+		case "pattern":
+			matches = true
+			if strings.Count(value.Value, "*") > 1 {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("action pattern %q has more than one *, only the first is a wildcard", value.Value)})
+			}
+		case "contains":
+			matches = true
+			problems = append(problems, validateList("action contains", value)...)
+		case "projects":
+			problems = append(problems, validateList("action projects", value)...)
+		case "severity":
+			problems = append(problems, validateSeverity(value)...)
+		case "run":
+			acts = true
+			if value.Kind != yaml.SequenceNode || len(value.Content) == 0 {
+				problems = append(problems, Problem{value.Line, "action run must be a non-empty list of program and arguments"})
+			}
+		case "notify":
+			acts = true
+			problems = append(problems, validateRouteSinks(value, declared)...)
+		case "flag":
+			acts = acts || value.Value == "true"
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown action key %q", key.Value)})
+		}
+	}
+
+	switch {
+	case name == "":
+		problems = append(problems, Problem{action.Line, "action has no name"})
+	case !matches:
+		problems = append(problems, Problem{action.Line, fmt.Sprintf("action %s has no pattern or contains to match", name)})
+	case !acts:
+		problems = append(problems, Problem{action.Line, fmt.Sprintf("action %s does nothing (set run, notify, or flag)", name)})
+	}
+	return problems
+}
+
+// validateThresholds checks the alerts.thresholds list
+func validateThresholds(node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, "alerts.thresholds must be a list"}}
+	}
+
+	var problems []Problem
+	for _, threshold := range node.Content {
+		if threshold.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{threshold.Line, "threshold must be a mapping"})
+			continue
+		}
+		problems = append(problems, validateThreshold(threshold)...)
+	}
+	return problems
+}
+
+// validateThreshold checks one threshold's keys and that it has a count and
+// exactly one of window or in_a_row
+func validateThreshold(threshold *yaml.Node) []Problem {
+	var problems []Problem
+	var name string
+	var count, window, inARow bool
+	for i := 0; i+1 < len(threshold.Content); i += 2 {
+		key, value := threshold.Content[i], threshold.Content[i+1]
+		switch key.Value {
+		case "name":
+			name = value.Value
+		case "pattern":
+			if strings.Count(value.Value, "*") > 1 {
+				problems = append(problems, Problem{value.Line,
+					fmt.Sprintf("threshold pattern %q has more than one *, only the first is a wildcard", value.Value)})
+			}
+		case "failed":
+		case "count":
+			count = true
+			if n, err := strconv.Atoi(value.Value); err != nil || n < 1 {
+				problems = append(problems, Problem{value.Line, fmt.Sprintf("threshold count must be a positive number, got %q", value.Value)})
+			}
+		case "window":
+			window = true
+			problems = append(problems, validateDuration("threshold window", value)...)
+		case "in_a_row":
+			inARow = value.Value == "true"
+		case "severity":
+			problems = append(problems, validateSeverity(value)...)
+		default:
+			problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown threshold key %q", key.Value)})
+		}
+	}
+
+	switch {
+	case name == "":
+		problems = append(problems, Problem{threshold.Line, "threshold has no name"})
+	case !count:
+		problems = append(problems, Problem{threshold.Line, fmt.Sprintf("threshold %s has no count", name)})
+	case window == inARow:
+		problems = append(problems, Problem{threshold.Line, fmt.Sprintf("threshold %s needs either a window or in_a_row: true", name)})
+	}
+	return problems
+}
+
+// validateList checks that a value is a list
+func validateList(name string, node *yaml.Node) []Problem {
+	if node.Kind != yaml.SequenceNode {
+		return []Problem{{node.Line, name + " must be a list"}}
+	}
+	return nil
+}
+
+// validateEmailTemplates checks the per-rule subject/body overrides
+func validateEmailTemplates(prefix string, node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{node.Line, prefix + ".templates must be a mapping of rule: {subject, body}"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		rule, tmpl := node.Content[i], node.Content[i+1]
+		if tmpl.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{tmpl.Line, fmt.Sprintf("template for %q must be a mapping", rule.Value)})
+			continue
+		}
+		for j := 0; j+1 < len(tmpl.Content); j += 2 {
+			key, value := tmpl.Content[j], tmpl.Content[j+1]
+			if key.Value != "subject" && key.Value != "body" {
+				problems = append(problems, Problem{key.Line, fmt.Sprintf("unknown template key %q (want subject or body)", key.Value)})
+				continue
+			}
+			problems = append(problems, validateTemplate(fmt.Sprintf("%s template for %q", key.Value, rule.Value), value)...)
+		}
+	}
+	return problems
+}
+
+// validateDuration checks that a value parses as a Go duration ("30s", "5m")
+func validateDuration(name string, node *yaml.Node) []Problem {
+	if d, err := time.ParseDuration(node.Value); err != nil || d < 0 {
+		return []Problem{{node.Line, fmt.Sprintf("%s must be a duration like 30s or 5m, got %q", name, node.Value)}}
+	}
+	return nil
+}
+
+// validateTemplate checks that a value parses as a text/template
+func validateTemplate(name string, node *yaml.Node) []Problem {
+	if _, err := template.New(name).Parse(node.Value); err != nil {
+		return []Problem{{node.Line, fmt.Sprintf("%s: %v", name, err)}}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDefaultYAMLMatchesDefaultConfig(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal(DefaultYAML(), &cfg); err != nil {
+		t.Fatalf("default.yaml does not parse: %v", err)
+	}
+	if !reflect.DeepEqual(&cfg, DefaultConfig()) {
+		t.Error("default.yaml has drifted from DefaultConfig()")
+	}
+	if problems := Validate(DefaultYAML()); len(problems) != 0 {
+		t.Errorf("default.yaml has problems: %v", problems)
+	}
+}
+
+func TestValidateShippedConfig(t *testing.T) {
+	data, err := os.ReadFile("../../config.yaml")
+	if err != nil {
+		t.Skipf("shipped config not available: %v", err)
+	}
+	if problems := Validate(data); len(problems) != 0 {
+		t.Errorf("config.yaml has problems: %v", problems)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantLine int
+		wantMsg  string
+	}{
+		{"syntax error", "theme: [mocha\n", 1, "did not find expected"},
+		{"unknown theme", "theme: dracula\n", 1, `unknown theme "dracula"`},
+		{"unknown key", "theme: mocha\nthem: latte\n", 2, `unknown key "them"`},
+		{"search history not bool", "persist_search_history: yes please\n", 1, "must be true or false"},
+		{"unknown timezone", "clock:\n  timezone: Mars/Olympus\n", 2, `unknown timezone "Mars/Olympus"`},
+		{"bad refresh interval", "refresh_interval: 30\n", 1, "refresh_interval must be a positive duration"},
+		{"zero activity window", "activity_window: 0s\n", 1, "activity_window must be a positive duration"},
+		{"relative home glob", "homes:\n  - home/*\n", 2, `home glob "home/*" must be an absolute path`},
+		{"homes not a list", "homes: /home/*\n", 1, "homes must be a list"},
+		{"relative projects dir", "projects_dirs:\n  - mnt/projects\n", 2, `projects dir "mnt/projects" must be an absolute path, or start with ~ or $VAR`},
+		{"malformed projects dir glob", "projects_dirs:\n  - ~/machines/[/.claude/projects\n", 2, `projects dir glob "~/machines/[/.claude/projects" is malformed`},
+		{"relative shared dir", "shared_dirs:\n  - team\n", 2, `shared dir "team" must be an absolute path, or start with ~ or $VAR`},
+		{"remote source scheme", "remote_sources:\n  - url: https://bucket/prefix\n", 2, `remote source url "https://bucket/prefix" must be s3://bucket/prefix or gs://bucket/prefix`},
+		{"remote source without url", "remote_sources:\n  - name: archive\n", 2, "remote source has no url"},
+		{"remote source interval", "remote_sources:\n  - url: s3://b/p/\n    poll_interval: soon\n", 3, `poll_interval must be a positive duration like 30s or 5m, got "soon"`},
+		{"state dir not a path", "state_dir: [a, b]\n", 1, "state_dir must be a path"},
+		{"unknown watch strategy", "watch_strategy: recursive\n", 1, `unknown watch_strategy "recursive" (want directories, projects, or poll)`},
+		{"owner without patterns", "owners:\n  - name: alice\n", 2, `owner "alice" has no origins or projects`},
+		{"unknown owner key", "owners:\n  - name: ci-bot\n    origin: [\"devagent:ci-*\"]\n", 3, `unknown owner key "origin"`},
+		{"unknown check severity", "check:\n  fail_on: critical\n", 2, `unknown severity "critical"`},
+		{"scrub switch not bool", "scrub:\n  drop_thinking: sometimes\n", 2, "scrub.drop_thinking must be true or false"},
+		{"bad scrub pattern", "scrub:\n  patterns: ['customer-(\\d+']\n", 2, `scrub pattern "customer-(\\d+"`},
+		{"unknown start view", "layout:\n  start_view: timeline\n", 2, `unknown start_view "timeline"`},
+		{"unknown layout preset", "layout:\n  preset: auditor\n", 2, `unknown layout preset "auditor" (want operator or reviewer)`},
+		{"layout switch not bool", "layout:\n  summaries: on please\n", 2, "layout.summaries must be true or false"},
+		{"bad clock offset", "clock:\n  offsets:\n    local: 5 minutes\n", 3, `clock offset for "local" must be a duration`},
+		{"unknown color", "tool_groups:\n  - name: a\n    color: purple\n    patterns: [Edit]\n", 3, `unknown color "purple"`},
+		{"missing color", "tool_groups:\n  - name: a\n    patterns: [Edit]\n", 2, "no color set"},
+		{"unknown group key", "tool_groups:\n  - name: a\n    color: red\n    pattern: [Edit]\n", 4, `unknown tool group key "pattern"`},
+		{"no patterns", "tool_groups:\n  - name: a\n    color: red\n", 2, "no patterns"},
+		{"no name", "tool_groups:\n  - color: red\n    patterns: [Edit]\n", 2, "no name"},
+		{"two wildcards", "tool_groups:\n  - name: a\n    color: red\n    patterns:\n      - \"Bash(*:*)\"\n", 5, "more than one *"},
+		{"empty pattern", "tool_groups:\n  - name: a\n    color: red\n    patterns:\n      - \"\"\n", 5, "empty pattern"},
+		{"bad depth", "commands:\n  subcommand_depth:\n    gh: two\n", 3, `subcommand depth for "gh"`},
+		{"bad pattern depth", "commands:\n  pattern_depth: -1\n", 2, "pattern_depth must be a non-negative integer"},
+		{"flag without dash", "commands:\n  flags_with_args:\n    aws: [profile]\n", 3, `"profile" is not a flag`},
+		{"empty wrapper", "commands:\n  wrappers:\n    - \"\"\n", 3, "empty wrapper"},
+		{"unknown commands key", "commands:\n  depth: {}\n", 2, `unknown commands key "depth"`},
+		{"unknown severity", "alerts:\n  min_severity: critical\n", 2, `unknown severity "critical"`},
+		{"bad batch window", "alerts:\n  email:\n    batch_window: soon\n", 3, "batch_window must be a duration"},
+		{"unknown sink type", "alerts:\n  sinks:\n    - name: pager\n      type: pagerduty\n", 3, `unknown type "pagerduty"`},
+		{"wrong key for sink type", "alerts:\n  sinks:\n    - name: desk\n      type: desktop\n      webhook_url: x\n", 5, `unknown key "webhook_url" for a desktop sink`},
+		{"duplicate sink", "alerts:\n  slack:\n    webhook_url: x\n  sinks:\n    - name: slack\n      type: webhook\n", 5, `duplicate sink name "slack"`},
+		{"exec command string", "alerts:\n  sinks:\n    - name: pause\n      type: exec\n      command: pause-agent.sh\n", 5, "command must be a non-empty list"},
+		{"action does nothing", "alerts:\n  actions:\n    - name: push\n      pattern: \"Bash(git:push:*)\"\n", 3, "action push does nothing"},
+		{"action notifies unknown sink", "alerts:\n  actions:\n    - name: push\n      pattern: \"Bash(git:push:*)\"\n      notify: [pager]\n", 5, `unknown sink "pager"`},
+		{"threshold without window", "alerts:\n  thresholds:\n    - name: burst\n      pattern: Write\n      count: 20\n", 3, "threshold burst needs either a window or in_a_row"},
+		{"threshold bad count", "alerts:\n  thresholds:\n    - name: burst\n      count: many\n      window: 5m\n", 4, "threshold count must be a positive number"},
+		{"quiet hours without end", "alerts:\n  delivery:\n    quiet_hours:\n      from: \"22:00\"\n", 4, "quiet_hours needs both from and to"},
+		{"sink bad rate limit", "alerts:\n  sinks:\n    - name: desk\n      type: desktop\n      rate_limit: lots\n", 5, "sink desk: rate_limit must be a number"},
+		{"route to unknown sink", "alerts:\n  sinks:\n    - name: desk\n      type: desktop\n  routes:\n    - sinks: [desk, pager]\n", 6, `unknown sink "pager"`},
+		{"bad email template", "alerts:\n  email:\n    templates:\n      Force push to remote:\n        subject: \"{{.Rule\"\n", 5, `subject template for "Force push to remote"`},
+		{"category without patterns", "categories:\n  - name: test\n", 2, "category test: no patterns"},
+		{"unknown category key", "categories:\n  - name: test\n    color: red\n    patterns: [Edit]\n", 3, `unknown category key "color"`},
+		{
+			"unreachable category pattern",
+			"categories:\n  - name: build\n    patterns:\n      - \"Bash(go:*)\"\n" +
+				"  - name: test\n    patterns:\n      - \"Bash(go:test:*)\"\n",
+			7, `category build claims it first ("Bash(go:*)", line 4)`,
+		},
+		{
+			"unreachable pattern",
+			"tool_groups:\n  - name: git\n    color: teal\n    patterns:\n      - \"Bash(git:*)\"\n" +
+				"  - name: push\n    color: red\n    patterns:\n      - \"Bash(git:push:*)\"\n",
+			9, `group git claims it first ("Bash(git:*)", line 5)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := Validate([]byte(tt.content))
+			for _, p := range problems {
+				if p.Line == tt.wantLine && strings.Contains(p.Message, tt.wantMsg) {
+					return
+				}
+			}
+			t.Errorf("Validate() = %v, want a problem on line %d containing %q", problems, tt.wantLine, tt.wantMsg)
+		})
+	}
+}
+
+func TestValidateAllowsExcludeWithoutColor(t *testing.T) {
+	content := "tool_groups:\n  - name: hidden\n    exclude: true\n    patterns: [TodoRead]\n"
+	if problems := Validate([]byte(content)); len(problems) != 0 {
+		t.Errorf("Validate() = %v, want no problems", problems)
+	}
+}
+
+func TestWriteDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cc_session_mon", "config.yaml")
+
+	if err := WriteDefault(path, false); err != nil {
+		t.Fatalf("WriteDefault() error = %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "mocha" {
+		t.Errorf("Expected theme 'mocha', got %q", cfg.Theme)
+	}
+
+	if err := WriteDefault(path, false); err == nil {
+		t.Error("WriteDefault() should refuse to overwrite without force")
+	}
+	if err := WriteDefault(path, true); err != nil {
+		t.Errorf("WriteDefault(force) error = %v", err)
+	}
+}
+
+func TestValidateAlertSinksAndRoutes(t *testing.T) {
+	content := `alerts:
+  min_severity: medium
+  slack:
+    webhook_url_env: SLACK_WEBHOOK_URL
+  sinks:
+    - name: oncall
+      type: email
+      host: smtp.example.com
+      to: [oncall@example.com]
+    - name: desk
+      type: desktop
+      dedup_window: 5m
+    - name: log
+      type: syslog
+      tag: ccmon
+    - name: pause
+      type: exec
+      command: [/usr/local/bin/pause-agent, --reason, alert]
+      timeout: 10s
+  routes:
+    - rules: ["Force push*", "Recursive file deletion"]
+      sinks: [oncall, slack]
+    - min_severity: low
+      sinks: [desk, log]
+  actions:
+    - name: force-push-prod
+      pattern: "Bash(git:push:*)"
+      contains: ["--force", "-f"]
+      projects: ["~/code/infra", "prod-*"]
+      run: [/usr/local/bin/pause-agent]
+      notify: [oncall]
+      flag: true
+  delivery:
+    rate_limit: 20
+    rate_period: 1h
+    dedup_window: 10m
+    quiet_hours:
+      from: "22:00"
+      to: "07:00"
+      min_severity: high
+  thresholds:
+    - name: write-burst
+      pattern: Write
+      count: 20
+      window: 5m
+    - name: failing-bash
+      pattern: "Bash(*"
+      failed: true
+      count: 3
+      in_a_row: true
+      severity: high
+`
+	if problems := Validate([]byte(content)); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+}
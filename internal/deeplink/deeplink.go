@@ -0,0 +1,117 @@
+// Package deeplink formats and parses ccmon:// links to a session, or to
+// one of its commands, that open the monitor focused on it:
+//
+//	ccmon://session/<session id>
+//	ccmon://session/<session id>?cmd=<message uuid>[&tool=<tool_use id>]
+//
+// tool is only added when the message made several tool calls.
+package deeplink
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"cc_session_mon/internal/session"
+)
+
+// Scheme is the links' URL scheme
+const Scheme = "ccmon"
+
+// Link names a session and optionally a command in it
+type Link struct {
+	SessionID string // Session ID, or a unique prefix of one
+	Command   string // UUID of the command's message (or its tool_use ID); "" for the session
+	ToolUseID string // Which of the message's tool calls, when it made several
+}
+
+// ForSession returns the link to a session
+func ForSession(sess *session.Session) Link {
+	return Link{SessionID: sess.ID}
+}
+
+// ForCommand returns the link to a command in a session
+func ForCommand(sess *session.Session, c *session.CommandEntry) Link {
+	l := Link{SessionID: sess.ID, Command: c.UUID}
+	if c.UUID == "" {
+		l.Command = c.ToolUseID
+		return l
+	}
+	for i := range sess.Commands {
+		other := &sess.Commands[i]
+		if other.UUID == c.UUID && other.ToolUseID != c.ToolUseID {
+			l.ToolUseID = c.ToolUseID
+			break
+		}
+	}
+	return l
+}
+
+// String formats the link
+func (l Link) String() string {
+	u := url.URL{Scheme: Scheme, Host: "session", Path: "/" + l.SessionID}
+	q := url.Values{}
+	if l.Command != "" {
+		q.Set("cmd", l.Command)
+	}
+	if l.ToolUseID != "" {
+		q.Set("tool", l.ToolUseID)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// IsLink reports whether s looks like a link, to tell one from a command
+// name or a path
+func IsLink(s string) bool {
+	return strings.HasPrefix(s, Scheme+"://")
+}
+
+// Parse parses a link
+func Parse(s string) (Link, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return Link{}, fmt.Errorf("link %q: %w", s, err)
+	}
+	id := strings.Trim(u.Path, "/")
+	if u.Scheme != Scheme || u.Host != "session" || id == "" || strings.Contains(id, "/") {
+		return Link{}, fmt.Errorf("link %q: want %s://session/<id>[?cmd=<uuid>]", s, Scheme)
+	}
+	q := u.Query()
+	l := Link{SessionID: id, Command: q.Get("cmd"), ToolUseID: q.Get("tool")}
+	if l.ToolUseID != "" && l.Command == "" {
+		return Link{}, fmt.Errorf("link %q: tool needs cmd", s)
+	}
+	return l, nil
+}
+
+// Find returns the session the link names: the one with its ID, else the
+// only one whose ID starts with it
+func (l Link) Find(sessions []*session.Session) *session.Session {
+	var found *session.Session
+	matches := 0
+	for _, sess := range sessions {
+		if sess.ID == l.SessionID {
+			return sess
+		}
+		if strings.HasPrefix(sess.ID, l.SessionID) {
+			found = sess
+			matches++
+		}
+	}
+	if matches != 1 {
+		return nil
+	}
+	return found
+}
+
+// Matches reports whether c is the command the link names
+func (l Link) Matches(c *session.CommandEntry) bool {
+	if l.Command == "" {
+		return false
+	}
+	if c.UUID != l.Command && c.ToolUseID != l.Command {
+		return false
+	}
+	return l.ToolUseID == "" || c.ToolUseID == l.ToolUseID
+}
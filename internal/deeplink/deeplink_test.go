@@ -0,0 +1,68 @@
+package deeplink
+
+import (
+	"testing"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestRoundTrip(t *testing.T) {
+	sess := &session.Session{ID: "abc-123", Commands: []session.CommandEntry{
+		{UUID: "m1", ToolUseID: "t1"},
+		{UUID: "m2", ToolUseID: "t2"},
+		{UUID: "m2", ToolUseID: "t3"},
+	}}
+
+	tests := []struct {
+		link Link
+		want string
+	}{
+		{ForSession(sess), "ccmon://session/abc-123"},
+		{ForCommand(sess, &sess.Commands[0]), "ccmon://session/abc-123?cmd=m1"},
+		{ForCommand(sess, &sess.Commands[2]), "ccmon://session/abc-123?cmd=m2&tool=t3"},
+	}
+	for _, tt := range tests {
+		if got := tt.link.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+		parsed, err := Parse(tt.want)
+		if err != nil || parsed != tt.link {
+			t.Errorf("Parse(%s) = %+v, %v; want %+v", tt.want, parsed, err, tt.link)
+		}
+	}
+
+	l, _ := Parse("ccmon://session/abc-123?cmd=m2&tool=t3")
+	for i, want := range []bool{false, false, true} {
+		if got := l.Matches(&sess.Commands[i]); got != want {
+			t.Errorf("Matches(command %d) = %v, want %v", i, got, want)
+		}
+	}
+	if l, _ := Parse("ccmon://session/abc-123?cmd=t1"); !l.Matches(&sess.Commands[0]) {
+		t.Error("expected a tool_use ID to name its command")
+	}
+
+	for _, bad := range []string{"ccmon://session/", "ccmon://other/abc", "http://session/abc", "ccmon://session/a/b", "ccmon://session/abc?tool=t1"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("expected Parse(%s) to fail", bad)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	sessions := []*session.Session{{ID: "abc-1"}, {ID: "abc-2"}, {ID: "abc"}, {ID: "def-1"}}
+	tests := []struct {
+		id, want string
+	}{
+		{"abc-2", "abc-2"},
+		{"abc", "abc"},   // Exact, though also a prefix of others
+		{"def", "def-1"}, // Unique prefix
+		{"abc-", ""},     // Ambiguous
+		{"xyz", ""},
+	}
+	for _, tt := range tests {
+		got := Link{SessionID: tt.id}.Find(sessions)
+		if (got == nil) != (tt.want == "") || (got != nil && got.ID != tt.want) {
+			t.Errorf("Find(%s) = %v, want %q", tt.id, got, tt.want)
+		}
+	}
+}
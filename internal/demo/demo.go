@@ -0,0 +1,210 @@
+// Package demo generates a synthetic projects directory and keeps appending
+// realistic session records to it, for screenshots, demos, and theme testing
+// without exposing real session data.
+package demo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// appendInterval is the average delay between synthetic tool calls
+const appendInterval = 2 * time.Second
+
+// historyLength is the number of tool calls seeded into each session at startup
+const historyLength = 25
+
+// project describes a fake project and the tool calls its agent makes
+type project struct {
+	path  string
+	files []string
+	bash  []string
+}
+
+// projects are the fake projects sessions are generated for
+var projects = []project{
+	{
+		path:  "/home/demo/code/webapp",
+		files: []string{"src/App.tsx", "src/api/client.ts", "src/components/Header.tsx", "package.json"},
+		bash:  []string{"npm install", "npm run build", "npm test", "git status", "git diff", "git add -A", "rm -rf dist"},
+	},
+	{
+		path:  "/home/demo/code/api-server",
+		files: []string{"cmd/server/main.go", "internal/handlers/users.go", "internal/db/queries.go", "go.mod"},
+		bash:  []string{"go build ./...", "go test ./...", "go mod tidy", "git log --oneline -5", "git commit -m 'Fix handler'", "docker compose up -d"},
+	},
+	{
+		path:  "/home/demo/code/infra",
+		files: []string{"main.tf", "modules/vpc/main.tf", "variables.tf"},
+		bash:  []string{"terraform plan", "terraform fmt", "kubectl get pods -n prod", "sudo systemctl restart nginx", "git push origin main"},
+	},
+}
+
+// sessionFile is a generated session being appended to
+type sessionFile struct {
+	path      string
+	sessionID string
+	project   project
+}
+
+// Generator writes synthetic sessions into a temporary projects directory
+type Generator struct {
+	Dir      string // Projects directory to point the watcher at
+	sessions []sessionFile
+	rng      *rand.Rand
+	done     chan struct{}
+}
+
+// NewGenerator creates a temporary projects directory seeded with one session
+// per fake project, each with some command history.
+func NewGenerator() (*Generator, error) {
+	dir, err := os.MkdirTemp("", "cc_session_mon-demo-")
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Generator{
+		Dir:  dir,
+		rng:  rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0)), //nolint:gosec // synthetic data
+		done: make(chan struct{}),
+	}
+
+	for _, p := range projects {
+		encoded := strings.ReplaceAll(p.path, "/", "-")
+		projectDir := filepath.Join(dir, encoded)
+		if err := os.MkdirAll(projectDir, 0o750); err != nil {
+			return nil, err
+		}
+
+		sf := sessionFile{project: p, sessionID: g.uuid()}
+		sf.path = filepath.Join(projectDir, sf.sessionID+".jsonl")
+
+		// Seed history spread over the last hour
+		start := time.Now().Add(-time.Hour)
+		for i := range historyLength {
+			ts := start.Add(time.Duration(i) * time.Hour / historyLength)
+			if err := g.appendToolCall(sf, ts); err != nil {
+				return nil, err
+			}
+		}
+		g.sessions = append(g.sessions, sf)
+	}
+
+	return g, nil
+}
+
+// Start begins appending synthetic tool calls in the background
+func (g *Generator) Start() {
+	go func() {
+		for {
+			// Jitter between 0.5x and 1.5x the base interval
+			delay := appendInterval/2 + time.Duration(g.rng.Int64N(int64(appendInterval)))
+			select {
+			case <-g.done:
+				return
+			case <-time.After(delay):
+				sf := g.sessions[g.rng.IntN(len(g.sessions))]
+				_ = g.appendToolCall(sf, time.Now())
+			}
+		}
+	}()
+}
+
+// Stop halts generation and removes the temporary directory
+func (g *Generator) Stop() error {
+	close(g.done)
+	return os.RemoveAll(g.Dir)
+}
+
+// appendToolCall writes a tool_use record and its tool_result to the session file
+func (g *Generator) appendToolCall(sf sessionFile, ts time.Time) error {
+	toolName, input, output := g.randomToolCall(sf.project)
+	toolUseID := "toolu_" + strings.ReplaceAll(g.uuid(), "-", "")[:24]
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	resultJSON, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+
+	assistant := map[string]any{
+		"type":      "assistant",
+		"timestamp": ts.UTC().Format(time.RFC3339),
+		"uuid":      g.uuid(),
+		"sessionId": sf.sessionID,
+		"cwd":       sf.project.path,
+		"gitBranch": "main",
+		"message": map[string]any{
+			"role": "assistant",
+			"content": []map[string]any{
+				{"type": "tool_use", "id": toolUseID, "name": toolName, "input": json.RawMessage(inputJSON)},
+			},
+		},
+	}
+	user := map[string]any{
+		"type":      "user",
+		"timestamp": ts.Add(time.Second).UTC().Format(time.RFC3339),
+		"uuid":      g.uuid(),
+		"sessionId": sf.sessionID,
+		"cwd":       sf.project.path,
+		"message": map[string]any{
+			"role": "user",
+			"content": []map[string]any{
+				{"type": "tool_result", "tool_use_id": toolUseID, "content": json.RawMessage(resultJSON)},
+			},
+		},
+	}
+
+	f, err := os.OpenFile(sf.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	if err := enc.Encode(assistant); err != nil {
+		return err
+	}
+	return enc.Encode(user)
+}
+
+// randomToolCall picks a plausible tool call for the project
+func (g *Generator) randomToolCall(p project) (toolName string, input map[string]any, output string) {
+	file := filepath.Join(p.path, p.files[g.rng.IntN(len(p.files))])
+
+	switch g.rng.IntN(6) { //nolint:mnd // number of cases below
+	case 0:
+		return "Read", map[string]any{"file_path": file}, "     1\tpackage main\n     2\t\n     3\t// ..."
+	case 1:
+		return "Edit", map[string]any{
+			"file_path":  file,
+			"old_string": "const timeout = 30",
+			"new_string": "const timeout = 60",
+		}, "The file " + file + " has been updated."
+	case 2:
+		return "Write", map[string]any{
+			"file_path": file,
+			"content":   "// Generated by demo mode\n",
+		}, "File created successfully at: " + file
+	case 3:
+		return "Grep", map[string]any{"pattern": "TODO", "path": p.path}, fmt.Sprintf("Found %d files", g.rng.IntN(10)+1)
+	default:
+		cmd := p.bash[g.rng.IntN(len(p.bash))]
+		return "Bash", map[string]any{"command": cmd, "description": "Run " + strings.Fields(cmd)[0]}, "ok"
+	}
+}
+
+// uuid returns a random RFC 4122-formatted identifier
+func (g *Generator) uuid() string {
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
+		g.rng.Uint32(), g.rng.Uint32()&0xffff, g.rng.Uint32()&0xffff,
+		g.rng.Uint32()&0xffff, g.rng.Uint64()&0xffffffffffff)
+}
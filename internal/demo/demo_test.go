@@ -0,0 +1,60 @@
+package demo
+
+import (
+	"path/filepath"
+	"testing"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestGeneratorSeedsParseableSessions(t *testing.T) {
+	g, err := NewGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = g.Stop() })
+
+	files, err := filepath.Glob(filepath.Join(g.Dir, "*", "*.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(projects) {
+		t.Fatalf("expected %d session files, got %d", len(projects), len(files))
+	}
+
+	for _, f := range files {
+		commands, meta, err := session.ParseSessionFile(f)
+		if err != nil {
+			t.Fatalf("parse %s: %v", f, err)
+		}
+		if len(commands) != historyLength {
+			t.Errorf("%s: expected %d commands, got %d", f, historyLength, len(commands))
+		}
+		if meta.CWD == "" {
+			t.Errorf("%s: expected cwd metadata", f)
+		}
+	}
+}
+
+func TestGeneratedResultsAreFetchable(t *testing.T) {
+	g, err := NewGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = g.Stop() })
+
+	sf := g.sessions[0]
+	commands, _, err := session.ParseSessionFile(sf.path)
+	if err != nil || len(commands) == 0 {
+		t.Fatalf("parse: %v (%d commands)", err, len(commands))
+	}
+
+	cmd := commands[0]
+	input, err := session.FetchToolInput(cmd.FilePath, cmd.LineNumber, cmd.ToolName, cmd.UUID)
+	if err != nil {
+		t.Fatalf("FetchToolInput: %v", err)
+	}
+	if input.Result == "" {
+		t.Error("expected generated tool call to have a result")
+	}
+}
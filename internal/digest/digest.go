@@ -0,0 +1,439 @@
+// Package digest summarizes session activity over a period (new sessions,
+// dangerous commands, top patterns, failed tool calls) for scheduled reports,
+// and sums up single sessions in one line.
+package digest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/chat"
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+)
+
+// Default limits for the lists in a report
+const (
+	DefaultTopPatterns = 10
+	DefaultMaxItems    = 10
+)
+
+// Options configures a digest
+type Options struct {
+	Since       time.Time      // Start of the period (inclusive)
+	Until       time.Time      // End of the period (exclusive)
+	Config      *config.Config // Rules used to rate commands
+	TopPatterns int            // Number of patterns listed
+	MaxItems    int            // Number of dangerous commands and errors listed
+	Owner       string         // Only include sessions with this owner label (config owners); "" includes all
+}
+
+// Report is the digest of one period
+type Report struct {
+	Since          time.Time        `json:"since"`
+	Until          time.Time        `json:"until"`
+	Owner          string           `json:"owner,omitempty"`
+	ActiveSessions int              `json:"active_sessions"`
+	Commands       int              `json:"commands"`
+	NewSessions    []SessionSummary `json:"new_sessions"`
+	DangerousCount int              `json:"dangerous_count"`
+	Dangerous      []CommandSummary `json:"dangerous"`
+	TopPatterns    []PatternSummary `json:"top_patterns"`
+	ErrorCount     int              `json:"error_count"`
+	Errors         []CommandSummary `json:"errors"`
+	ByOwner        []OwnerSummary   `json:"by_owner,omitempty"`
+	Heatmap        *session.Heatmap `json:"heatmap"` // Commands per hour of each day in the period
+}
+
+// SessionSummary is a session that started during the period
+type SessionSummary struct {
+	ID          string    `json:"id"`
+	ProjectPath string    `json:"project_path"`
+	GitBranch   string    `json:"git_branch,omitempty"`
+	Origin      string    `json:"origin,omitempty"`
+	Owner       string    `json:"owner,omitempty"`
+	Started     time.Time `json:"started"`
+	Commands    int       `json:"commands"`
+}
+
+// CommandSummary is a command worth reviewing
+type CommandSummary struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ProjectPath string    `json:"project_path"`
+	Owner       string    `json:"owner,omitempty"`
+	Pattern     string    `json:"pattern"`
+	Command     string    `json:"command"`
+	Rules       []string  `json:"rules,omitempty"` // High-severity findings, for dangerous commands
+}
+
+// OwnerSummary is one owner's activity over the period; Owner is "" for
+// sessions no owner label matches
+type OwnerSummary struct {
+	Owner     string `json:"owner"`
+	Sessions  int    `json:"sessions"`
+	Commands  int    `json:"commands"`
+	Dangerous int    `json:"dangerous"`
+	Errors    int    `json:"errors"`
+}
+
+// PatternSummary is a pattern's count over the period
+type PatternSummary struct {
+	Pattern string `json:"pattern"`
+	Count   int    `json:"count"`
+}
+
+// Build summarizes the commands in sessions issued between opts.Since and opts.Until
+func Build(sessions []*session.Session, opts Options) *Report {
+	if opts.TopPatterns <= 0 {
+		opts.TopPatterns = DefaultTopPatterns
+	}
+	if opts.MaxItems <= 0 {
+		opts.MaxItems = DefaultMaxItems
+	}
+	if opts.Config == nil {
+		opts.Config = config.Global()
+	}
+
+	r := &Report{Since: opts.Since, Until: opts.Until, Owner: opts.Owner}
+	r.Heatmap = session.NewHeatmap(opts.Since.In(opts.Config.Clock.Location()), opts.Until)
+	var inPeriod []session.CommandEntry
+	byOwner := map[string]*OwnerSummary{}
+	for _, sess := range sessions {
+		owner := opts.Config.OwnerOf(sess.Origin, sess.ProjectPath)
+		if opts.Owner != "" && owner != opts.Owner {
+			continue
+		}
+		dangerous, errs := r.DangerousCount, r.ErrorCount
+		var count int
+		var started time.Time
+		for i := range sess.Commands {
+			c := &sess.Commands[i]
+			if started.IsZero() || c.Timestamp.Before(started) {
+				started = c.Timestamp
+			}
+			if !r.contains(c.Timestamp) {
+				continue
+			}
+			count++
+			inPeriod = append(inPeriod, *c)
+			r.Heatmap.Add(c.Timestamp)
+			r.addCommand(sess, owner, c, opts.Config)
+		}
+		if count == 0 {
+			continue
+		}
+		r.ActiveSessions++
+		o := byOwner[owner]
+		if o == nil {
+			o = &OwnerSummary{Owner: owner}
+			byOwner[owner] = o
+		}
+		o.Sessions++
+		o.Commands += count
+		o.Dangerous += r.DangerousCount - dangerous
+		o.Errors += r.ErrorCount - errs
+		if r.contains(started) {
+			r.NewSessions = append(r.NewSessions, SessionSummary{
+				ID:          sess.ID,
+				ProjectPath: sess.ProjectPath,
+				GitBranch:   sess.GitBranch,
+				Origin:      sess.Origin,
+				Owner:       owner,
+				Started:     started,
+				Commands:    count,
+			})
+		}
+	}
+	r.Commands = len(inPeriod)
+	if opts.Owner == "" {
+		r.ByOwner = ownerSummaries(byOwner)
+	}
+
+	sort.Slice(r.NewSessions, func(i, j int) bool {
+		return r.NewSessions[i].Started.Before(r.NewSessions[j].Started)
+	})
+	r.Dangerous = newestFirst(r.Dangerous, opts.MaxItems)
+	r.Errors = newestFirst(r.Errors, opts.MaxItems)
+
+	for _, p := range session.AggregatePatterns(inPeriod) {
+		if len(r.TopPatterns) == opts.TopPatterns {
+			break
+		}
+		r.TopPatterns = append(r.TopPatterns, PatternSummary{Pattern: p.Pattern, Count: p.Count})
+	}
+	return r
+}
+
+// ownerSummaries lists owners by commands, most first, with unowned
+// sessions last; nil when no session has an owner
+func ownerSummaries(byOwner map[string]*OwnerSummary) []OwnerSummary {
+	if _, unowned := byOwner[""]; len(byOwner) == 0 || (unowned && len(byOwner) == 1) {
+		return nil
+	}
+	summaries := make([]OwnerSummary, 0, len(byOwner))
+	for _, o := range byOwner {
+		summaries = append(summaries, *o)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if (a.Owner == "") != (b.Owner == "") {
+			return b.Owner == ""
+		}
+		if a.Commands != b.Commands {
+			return a.Commands > b.Commands
+		}
+		return a.Owner < b.Owner
+	})
+	return summaries
+}
+
+// contains reports whether t falls within the report's period
+func (r *Report) contains(t time.Time) bool {
+	return !t.Before(r.Since) && t.Before(r.Until)
+}
+
+// addCommand records a command in the period as dangerous and/or failed
+func (r *Report) addCommand(sess *session.Session, owner string, c *session.CommandEntry, cfg *config.Config) {
+	summary := CommandSummary{
+		Timestamp:   c.Timestamp,
+		ProjectPath: sess.ProjectPath,
+		Owner:       owner,
+		Pattern:     c.Pattern,
+		Command:     c.RawCommand,
+	}
+	if c.IsError {
+		r.ErrorCount++
+		r.Errors = append(r.Errors, summary)
+	}
+
+	for _, f := range security.CommandFindings(c, sess.ProjectPath, cfg, sess.Commands) {
+		if f.Severity == security.SeverityHigh {
+			summary.Rules = append(summary.Rules, f.Rule)
+		}
+	}
+	if len(summary.Rules) > 0 {
+		r.DangerousCount++
+		r.Dangerous = append(r.Dangerous, summary)
+	}
+}
+
+// newestFirst sorts commands newest first and keeps at most limit
+func newestFirst(cmds []CommandSummary, limit int) []CommandSummary {
+	sort.SliceStable(cmds, func(i, j int) bool {
+		return cmds[i].Timestamp.After(cmds[j].Timestamp)
+	})
+	if len(cmds) > limit {
+		cmds = cmds[:limit]
+	}
+	return cmds
+}
+
+// Text renders the report as plain text suitable for email or a terminal
+func (r *Report) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "cc_session_mon digest: %s - %s%s\n",
+		r.Since.Format("Jan 02 15:04"), r.Until.Format("Jan 02 15:04"), r.ownerSuffix())
+	fmt.Fprintf(&b, "%d active sessions, %d commands, %d new sessions\n",
+		r.ActiveSessions, r.Commands, len(r.NewSessions))
+
+	if len(r.ByOwner) > 0 {
+		b.WriteString("\nBy owner\n")
+		for _, o := range r.ByOwner {
+			fmt.Fprintf(&b, "  %-16s %s\n", ownerName(o.Owner), o.counts())
+		}
+	}
+
+	if len(r.NewSessions) > 0 {
+		b.WriteString("\nNew sessions\n")
+		for _, s := range r.NewSessions {
+			project := filepath.Base(s.ProjectPath)
+			if s.GitBranch != "" {
+				project += " (" + s.GitBranch + ")"
+			}
+			fmt.Fprintf(&b, "  %s  %s  %d commands\n", s.Started.Format("Jan 02 15:04"), project, s.Commands)
+		}
+	}
+
+	if r.DangerousCount > 0 {
+		fmt.Fprintf(&b, "\nDangerous commands (%d)\n", r.DangerousCount)
+		for _, c := range r.Dangerous {
+			fmt.Fprintf(&b, "  %s  %s  %s\n    %s\n", c.Timestamp.Format("Jan 02 15:04"),
+				filepath.Base(c.ProjectPath), strings.Join(c.Rules, ", "), singleLine(c.Command))
+		}
+	}
+
+	if len(r.TopPatterns) > 0 {
+		b.WriteString("\nTop patterns\n")
+		for _, p := range r.TopPatterns {
+			fmt.Fprintf(&b, "  %6d  %s\n", p.Count, p.Pattern)
+		}
+	}
+
+	if r.ErrorCount > 0 {
+		fmt.Fprintf(&b, "\nErrors (%d)\n", r.ErrorCount)
+		for _, c := range r.Errors {
+			fmt.Fprintf(&b, "  %s  %s  %s\n", c.Timestamp.Format("Jan 02 15:04"),
+				filepath.Base(c.ProjectPath), singleLine(c.Command))
+		}
+	}
+	return b.String()
+}
+
+// ownerSuffix names the owner a report is limited to, for its title
+func (r *Report) ownerSuffix() string {
+	if r.Owner == "" {
+		return ""
+	}
+	return " (" + r.Owner + ")"
+}
+
+// ownerName returns an owner label for display, naming unowned sessions
+func ownerName(owner string) string {
+	if owner == "" {
+		return "(no owner)"
+	}
+	return owner
+}
+
+// counts sums up an owner's activity, e.g. "2 sessions, 40 commands, 1 dangerous, 3 errors"
+func (o OwnerSummary) counts() string {
+	return fmt.Sprintf("%d sessions, %d commands, %d dangerous, %d errors", o.Sessions, o.Commands, o.Dangerous, o.Errors)
+}
+
+// singleLine collapses a command to its first line, truncated for display
+func singleLine(s string) string {
+	first, _, multi := strings.Cut(s, "\n")
+	if len(first) > 120 {
+		return first[:119] + "…"
+	}
+	if multi {
+		return first + " …"
+	}
+	return first
+}
+
+// Write renders the report to w as "text", "json", or "html"
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "", "text":
+		_, err := io.WriteString(w, r.Text())
+		return err
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case "html":
+		return r.HTML(w)
+	default:
+		return fmt.Errorf("unknown format %q (want text, json, or html)", format)
+	}
+}
+
+// webhookPayload is the JSON posted to a generic webhook: the rendered text
+// under "text" (understood by Slack-style incoming webhooks) plus the full report
+type webhookPayload struct {
+	Text   string  `json:"text"`
+	Report *Report `json:"report"`
+}
+
+// WebhookPayload returns the report as posted to a generic webhook
+func (r *Report) WebhookPayload() any {
+	return webhookPayload{Text: r.Text(), Report: r}
+}
+
+// ChatMessages renders the report for Slack or Discord: a summary, then
+// dangerous commands and errors when there are any. Titles link to the
+// dashboard at dashboard when set.
+func (r *Report) ChatMessages(dashboard string) []chat.Message {
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "%d active sessions, %d commands, %d new sessions",
+		r.ActiveSessions, r.Commands, len(r.NewSessions))
+	for _, s := range r.NewSessions {
+		fmt.Fprintf(&summary, "\n• %s %s (%d commands)", s.Started.Format("Jan 02 15:04"), filepath.Base(s.ProjectPath), s.Commands)
+	}
+	var patterns []string
+	for _, p := range r.TopPatterns {
+		patterns = append(patterns, fmt.Sprintf("%d  %s", p.Count, p.Pattern))
+	}
+
+	msgs := []chat.Message{{
+		Title:     fmt.Sprintf("Digest: %s - %s%s", r.Since.Format("Jan 02 15:04"), r.Until.Format("Jan 02 15:04"), r.ownerSuffix()),
+		URL:       dashboard,
+		Text:      summary.String(),
+		Neutral:   true,
+		Timestamp: r.Until,
+	}}
+	if len(r.ByOwner) > 0 {
+		var owners []string
+		for _, o := range r.ByOwner {
+			owners = append(owners, ownerName(o.Owner)+": "+o.counts())
+		}
+		msgs[0].Fields = append(msgs[0].Fields, chat.Field{Name: "By owner", Value: strings.Join(owners, "\n")})
+	}
+	if len(patterns) > 0 {
+		msgs[0].Fields = append(msgs[0].Fields, chat.Field{Name: "Top patterns", Value: strings.Join(patterns, "\n")})
+	}
+
+	if r.DangerousCount > 0 {
+		var b strings.Builder
+		for _, c := range r.Dangerous {
+			fmt.Fprintf(&b, "%s %s: %s\n%s\n", c.Timestamp.Format("Jan 02 15:04"), filepath.Base(c.ProjectPath),
+				strings.Join(c.Rules, ", "), chat.CodeBlock(c.Command))
+		}
+		msgs = append(msgs, chat.Message{
+			Title:    fmt.Sprintf("Dangerous commands (%d)", r.DangerousCount),
+			URL:      dashboard,
+			Text:     b.String(),
+			Severity: security.SeverityHigh,
+		})
+	}
+
+	if r.ErrorCount > 0 {
+		var b strings.Builder
+		for _, c := range r.Errors {
+			fmt.Fprintf(&b, "%s %s %s\n", c.Timestamp.Format("Jan 02 15:04"), filepath.Base(c.ProjectPath), chat.CodeBlock(c.Command))
+		}
+		msgs = append(msgs, chat.Message{
+			Title:    fmt.Sprintf("Errors (%d)", r.ErrorCount),
+			URL:      dashboard,
+			Text:     b.String(),
+			Severity: security.SeverityMedium,
+		})
+	}
+	return msgs
+}
+
+// DefaultStatePath is where the time of the last digest is recorded, in
+// the state directory
+func DefaultStatePath() string {
+	return config.StatePath("digest-last-run")
+}
+
+// LoadLastRun returns the time recorded by SaveLastRun, or the zero time
+// when no digest has run yet
+func LoadLastRun(path string) (time.Time, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+}
+
+// SaveLastRun records t as the end of the last digest period
+func SaveLastRun(path string, t time.Time) error {
+	cleanPath := filepath.Clean(path)
+	if err := os.MkdirAll(filepath.Dir(cleanPath), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(cleanPath, []byte(t.Format(time.RFC3339)+"\n"), 0o600)
+}
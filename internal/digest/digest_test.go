@@ -0,0 +1,183 @@
+package digest
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+)
+
+func TestBuild(t *testing.T) {
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+	sessions := []*session.Session{
+		{
+			ID:          "old",
+			ProjectPath: "/projects/alpha",
+			Commands: []session.CommandEntry{
+				{ToolName: "Bash", Pattern: "Bash(git:status:*)", RawCommand: "git status", Timestamp: since.Add(-time.Hour)},
+				{ToolName: "Bash", Pattern: "Bash(rm:*)", RawCommand: "rm -rf build", Timestamp: since.Add(time.Hour)},
+			},
+		},
+		{
+			ID:          "new",
+			ProjectPath: "/projects/beta",
+			GitBranch:   "main",
+			Commands: []session.CommandEntry{
+				{ToolName: "Bash", Pattern: "Bash(go:test:*)", RawCommand: "go test ./...", Timestamp: since.Add(2 * time.Hour), IsError: true},
+				{ToolName: "Bash", Pattern: "Bash(go:test:*)", RawCommand: "go test ./...", Timestamp: since.Add(3 * time.Hour)},
+			},
+		},
+		{
+			ID:          "later",
+			ProjectPath: "/projects/gamma",
+			Commands: []session.CommandEntry{
+				{ToolName: "Bash", Pattern: "Bash(ls:*)", RawCommand: "ls", Timestamp: until.Add(time.Hour)},
+			},
+		},
+	}
+
+	r := Build(sessions, Options{Since: since, Until: until, Config: config.DefaultConfig()})
+
+	if r.ActiveSessions != 2 || r.Commands != 3 {
+		t.Errorf("expected 2 active sessions and 3 commands, got %d and %d", r.ActiveSessions, r.Commands)
+	}
+	if len(r.NewSessions) != 1 || r.NewSessions[0].ID != "new" || r.NewSessions[0].Commands != 2 {
+		t.Errorf("expected only session new as new with 2 commands, got %+v", r.NewSessions)
+	}
+	if r.DangerousCount != 1 || r.Dangerous[0].Command != "rm -rf build" {
+		t.Errorf("expected rm -rf as the dangerous command, got %+v", r.Dangerous)
+	}
+	if r.ErrorCount != 1 || r.Errors[0].ProjectPath != "/projects/beta" {
+		t.Errorf("expected one error in beta, got %+v", r.Errors)
+	}
+	if len(r.TopPatterns) == 0 || r.TopPatterns[0] != (PatternSummary{"Bash(go:test:*)", 2}) {
+		t.Errorf("expected go test as the top pattern, got %+v", r.TopPatterns)
+	}
+
+	text := r.Text()
+	for _, want := range []string{"New sessions", "beta (main)", "Dangerous commands (1)", "rm -rf build", "Top patterns", "Errors (1)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected text to contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHTMLHeatmap(t *testing.T) {
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	cfg := config.DefaultConfig()
+	cfg.Clock.Timezone = "UTC"
+	sessions := []*session.Session{{
+		ID:          "s",
+		ProjectPath: "/projects/alpha",
+		Commands: []session.CommandEntry{
+			{ToolName: "Bash", Pattern: "Bash(ls:*)", RawCommand: "ls <dir>", Timestamp: since.Add(9 * time.Hour)},
+			{ToolName: "Bash", Pattern: "Bash(ls:*)", RawCommand: "ls", Timestamp: since.Add(33 * time.Hour)},
+		},
+	}}
+
+	r := Build(sessions, Options{Since: since, Until: since.Add(48 * time.Hour), Config: cfg})
+	if len(r.Heatmap.Days) != 3 || r.Heatmap.Counts[0][9] != 1 || r.Heatmap.Counts[1][9] != 1 {
+		t.Fatalf("expected one command at 09:00 on each of the first two of 3 days, got %+v", r.Heatmap)
+	}
+
+	var b strings.Builder
+	if err := r.Write(&b, "html"); err != nil {
+		t.Fatal(err)
+	}
+	page := b.String()
+	for _, want := range []string{"<h2>Activity</h2>", "Sun Mar 01", `class="cell l4" title="09:00 - 1 commands"`, "<code>Bash(ls:*)</code>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected the HTML report to contain %q", want)
+		}
+	}
+}
+
+func TestBuildByOwner(t *testing.T) {
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	cfg := config.DefaultConfig()
+	cfg.Owners = []config.Owner{{Name: "ci-bot", Origins: []string{"devagent:ci-*"}}}
+	sessions := []*session.Session{
+		{ID: "ci", ProjectPath: "/projects/alpha", Origin: "devagent:ci-1", Commands: []session.CommandEntry{
+			{ToolName: "Bash", Pattern: "Bash(rm:*)", RawCommand: "rm -rf build", Timestamp: since.Add(time.Hour)},
+			{ToolName: "Bash", Pattern: "Bash(ls:*)", RawCommand: "ls", Timestamp: since.Add(2 * time.Hour)},
+		}},
+		{ID: "alice", ProjectPath: "/home/alice/beta", Origin: "user:alice", Commands: []session.CommandEntry{
+			{ToolName: "Bash", Pattern: "Bash(go:test:*)", RawCommand: "go test ./...", Timestamp: since.Add(time.Hour), IsError: true},
+		}},
+		{ID: "mine", ProjectPath: "/projects/gamma", Origin: "local", Commands: []session.CommandEntry{
+			{ToolName: "Bash", Pattern: "Bash(ls:*)", RawCommand: "ls", Timestamp: since.Add(time.Hour)},
+		}},
+	}
+
+	r := Build(sessions, Options{Since: since, Until: since.Add(24 * time.Hour), Config: cfg})
+	want := []OwnerSummary{
+		{Owner: "ci-bot", Sessions: 1, Commands: 2, Dangerous: 1},
+		{Owner: "alice", Sessions: 1, Commands: 1, Errors: 1},
+		{Owner: "", Sessions: 1, Commands: 1},
+	}
+	if !slices.Equal(r.ByOwner, want) {
+		t.Errorf("expected owners by commands with unowned last, got %+v", r.ByOwner)
+	}
+	if text := r.Text(); !strings.Contains(text, "ci-bot           1 sessions, 2 commands, 1 dangerous, 0 errors") ||
+		!strings.Contains(text, "(no owner)") {
+		t.Errorf("expected the by-owner section:\n%s", text)
+	}
+
+	r = Build(sessions, Options{Since: since, Until: since.Add(24 * time.Hour), Config: cfg, Owner: "alice"})
+	if r.ActiveSessions != 1 || r.ErrorCount != 1 || r.DangerousCount != 0 || r.NewSessions[0].Owner != "alice" || r.ByOwner != nil {
+		t.Errorf("expected only alice's session, got %+v", r)
+	}
+	if !strings.HasPrefix(r.Text(), "cc_session_mon digest: Mar 01 00:00 - Mar 02 00:00 (alice)\n") {
+		t.Errorf("expected the owner in the title, got:\n%s", r.Text())
+	}
+}
+
+func TestLastRunRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "digest-last-run")
+
+	got, err := LoadLastRun(path)
+	if err != nil || !got.IsZero() {
+		t.Fatalf("expected zero time for missing state, got %v, %v", got, err)
+	}
+
+	when := time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)
+	if err := SaveLastRun(path, when); err != nil {
+		t.Fatal(err)
+	}
+	got, err = LoadLastRun(path)
+	if err != nil || !got.Equal(when) {
+		t.Errorf("expected %v, got %v, %v", when, got, err)
+	}
+}
+
+func TestChatMessages(t *testing.T) {
+	r := &Report{
+		Commands:       4,
+		DangerousCount: 1,
+		Dangerous:      []CommandSummary{{ProjectPath: "/projects/alpha", Command: "rm -rf build", Rules: []string{"Recursive file deletion"}}},
+		TopPatterns:    []PatternSummary{{"Bash(go:test:*)", 3}},
+	}
+
+	msgs := r.ChatMessages("http://localhost:8080")
+	if len(msgs) != 2 {
+		t.Fatalf("expected a summary and a dangerous section, got %+v", msgs)
+	}
+	if !msgs[0].Neutral || !strings.Contains(msgs[0].Text, "4 commands") || len(msgs[0].Fields) != 1 {
+		t.Errorf("unexpected summary: %+v", msgs[0])
+	}
+	if msgs[1].Severity != security.SeverityHigh || !strings.Contains(msgs[1].Text, "`rm -rf build`") ||
+		msgs[1].URL != "http://localhost:8080" {
+		t.Errorf("unexpected dangerous section: %+v", msgs[1])
+	}
+
+	payload, ok := r.WebhookPayload().(webhookPayload)
+	if !ok || payload.Report != r || !strings.Contains(payload.Text, "4 commands") {
+		t.Errorf("unexpected webhook payload: %+v", payload)
+	}
+}
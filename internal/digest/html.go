@@ -0,0 +1,112 @@
+package digest
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// heatmapLevels is the number of shades of the heatmap, including empty hours
+const heatmapLevels = 5
+
+// htmlTemplate renders a report as a standalone page for sharing or archiving
+var htmlTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"time":    func(t time.Time) string { return t.Format("Jan 02 15:04") },
+	"base":    filepath.Base,
+	"line":    singleLine,
+	"join":    strings.Join,
+	"owner":   ownerName,
+	"hours":   func() []int { return hourColumns[:] },
+	"hourTag": hourLabel,
+	"level":   func(r *Report, count int) int { return r.Heatmap.Level(count, heatmapLevels) },
+	"daySum":  func(counts [24]int) int { return sum(counts[:]) },
+	"cellTip": func(count, hour int) string { return fmt.Sprintf("%02d:00 - %d commands", hour, count) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>cc_session_mon digest: {{time .Since}} - {{time .Until}}{{if .Owner}} ({{.Owner}}){{end}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #1e1e2e; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: 2px 8px; }
+code { font-family: ui-monospace, monospace; }
+.heatmap td.cell { width: 14px; height: 14px; padding: 0; border: 2px solid #fff; }
+.heatmap th { font-weight: normal; font-size: 80%; color: #6c7086; }
+.l0 { background: #ebedf0; } .l1 { background: #9be9a8; } .l2 { background: #40c463; }
+.l3 { background: #30a14e; } .l4 { background: #216e39; }
+</style>
+</head>
+<body>
+<h1>cc_session_mon digest{{if .Owner}} ({{.Owner}}){{end}}</h1>
+<p>{{time .Since}} - {{time .Until}}: {{.ActiveSessions}} active sessions, {{.Commands}} commands, {{len .NewSessions}} new sessions</p>
+{{if .Heatmap}}{{if .Heatmap.Days}}
+<h2>Activity</h2>
+<table class="heatmap">
+<tr><th></th>{{range hours}}<th>{{hourTag .}}</th>{{end}}<th>Total</th></tr>
+{{$r := .}}{{range $i, $day := .Heatmap.Days}}{{$counts := index $r.Heatmap.Counts $i}}
+<tr><th>{{$day.Format "Mon Jan 02"}}</th>{{range $hour, $count := $counts}}<td class="cell l{{level $r $count}}" title="{{cellTip $count $hour}}"></td>{{end}}<td>{{daySum $counts}}</td></tr>{{end}}
+</table>
+{{end}}{{end}}
+{{if .ByOwner}}
+<h2>By owner</h2>
+<table>{{range .ByOwner}}
+<tr><td>{{owner .Owner}}</td><td>{{.Sessions}} sessions</td><td>{{.Commands}} commands</td><td>{{.Dangerous}} dangerous</td><td>{{.Errors}} errors</td></tr>{{end}}
+</table>
+{{end}}
+{{if .NewSessions}}
+<h2>New sessions</h2>
+<table>{{range .NewSessions}}
+<tr><td>{{time .Started}}</td><td>{{base .ProjectPath}}{{if .GitBranch}} ({{.GitBranch}}){{end}}</td><td>{{.Commands}} commands</td></tr>{{end}}
+</table>
+{{end}}
+{{if .DangerousCount}}
+<h2>Dangerous commands ({{.DangerousCount}})</h2>
+<table>{{range .Dangerous}}
+<tr><td>{{time .Timestamp}}</td><td>{{base .ProjectPath}}</td><td>{{join .Rules ", "}}</td><td><code>{{line .Command}}</code></td></tr>{{end}}
+</table>
+{{end}}
+{{if .TopPatterns}}
+<h2>Top patterns</h2>
+<table>{{range .TopPatterns}}
+<tr><td>{{.Count}}</td><td><code>{{.Pattern}}</code></td></tr>{{end}}
+</table>
+{{end}}
+{{if .ErrorCount}}
+<h2>Errors ({{.ErrorCount}})</h2>
+<table>{{range .Errors}}
+<tr><td>{{time .Timestamp}}</td><td>{{base .ProjectPath}}</td><td><code>{{line .Command}}</code></td></tr>{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+// hourColumns are the hours of the heatmap's columns
+var hourColumns = [24]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23}
+
+// hourLabel labels every third hour column, e.g. "09"
+func hourLabel(hour int) string {
+	if hour%3 != 0 {
+		return ""
+	}
+	return fmt.Sprintf("%02d", hour)
+}
+
+// HTML renders the report as a standalone HTML page, with a contributions
+// calendar-style heatmap of the commands per hour of each day
+func (r *Report) HTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
+
+// sum adds up counts
+func sum(counts []int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,255 @@
+package digest
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+)
+
+// Summary is the one-line account of a session, e.g. "142 cmds: mostly go
+// test/git; edited 12 files in internal/; 2 dangerous: rm -rf build"
+type Summary struct {
+	Commands    int
+	Mostly      []string // Command families making up at least half the commands, largest first
+	EditedFiles int      // Distinct files edited or written
+	EditedDir   string   // Deepest project directory holding every edited file, if below the root
+	Dangerous   int      // Commands with a high-severity finding
+	Example     string   // The most recent dangerous command, first line only
+	Failed      int      // Commands whose tool result was an error
+
+	Skills   []session.SkillCount // Skills invoked, most used first
+	Thinking session.ThinkingStats
+}
+
+// maxMostly caps the command families listed as "mostly"
+const maxMostly = 3
+
+// maxSkills caps the skills listed by name; the rest are counted
+const maxSkills = 3
+
+// Summarize builds the summary of a session, rating commands with cfg
+// (the session's project config when nil)
+func Summarize(sess *session.Session, cfg *config.Config) Summary {
+	if cfg == nil {
+		cfg = config.ForProject(sess.ProjectPath)
+	}
+
+	s := Summary{
+		Commands: len(sess.Commands),
+		Mostly:   commandFamilies(sess.Commands),
+		Skills:   session.SkillUsage(sess.Commands),
+		Thinking: sess.Thinking,
+	}
+	edited := make(map[string]bool)
+	var dirs []string
+	for i := range sess.Commands {
+		c := &sess.Commands[i]
+		if c.IsError {
+			s.Failed++
+		}
+		switch c.ToolName {
+		case "Edit", "Write", "NotebookEdit":
+			if !edited[c.RawCommand] {
+				edited[c.RawCommand] = true
+				dirs = append(dirs, filepath.Dir(c.RawCommand))
+			}
+		}
+		for _, f := range security.CommandFindings(c, sess.ProjectPath, cfg, sess.Commands) {
+			if f.Severity == security.SeverityHigh {
+				s.Dangerous++
+				s.Example = firstLine(c.RawCommand)
+				break
+			}
+		}
+	}
+	s.EditedFiles = len(edited)
+	s.EditedDir = projectDir(sess.ProjectPath, dirs)
+	return s
+}
+
+// String renders the summary on one line
+func (s Summary) String() string {
+	if s.Commands == 0 {
+		return "no commands"
+	}
+
+	var parts []string
+	if len(s.Mostly) > 0 {
+		parts = append(parts, "mostly "+strings.Join(s.Mostly, "/"))
+	}
+	if s.EditedFiles > 0 {
+		edited := fmt.Sprintf("edited %d %s", s.EditedFiles, plural(s.EditedFiles, "file"))
+		if s.EditedDir != "" {
+			edited += " in " + s.EditedDir + "/"
+		}
+		parts = append(parts, edited)
+	}
+	if len(s.Skills) > 0 {
+		parts = append(parts, "skills "+formatSkills(s.Skills))
+	}
+	if s.Thinking.Blocks > 0 {
+		parts = append(parts, "thinking "+s.Thinking.String())
+	}
+	if s.Dangerous > 0 {
+		dangerous := fmt.Sprintf("%d dangerous", s.Dangerous)
+		if s.Example != "" {
+			dangerous += ": " + s.Example
+		}
+		parts = append(parts, dangerous)
+	}
+	if s.Failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", s.Failed))
+	}
+
+	line := fmt.Sprintf("%d cmds", s.Commands)
+	if len(parts) > 0 {
+		line += ": " + strings.Join(parts, "; ")
+	}
+	return line
+}
+
+// Redacted returns the summary without the dangerous command example or
+// the edited directory
+func (s Summary) Redacted() Summary {
+	s.Example = ""
+	s.EditedDir = ""
+	return s
+}
+
+// formatSkills lists skills by name with their use counts, "pdf ×2, review"
+func formatSkills(skills []session.SkillCount) string {
+	var names []string
+	for i, sk := range skills {
+		if i == maxSkills {
+			names = append(names, fmt.Sprintf("+%d more", len(skills)-maxSkills))
+			break
+		}
+		name := sk.Name
+		if sk.Count > 1 {
+			name += fmt.Sprintf(" ×%d", sk.Count)
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// commandFamilies returns the largest command families covering at least
+// half the commands. A family is a program's single subcommand when that is
+// all it ran ("go test"), else the program ("git"); other tools are
+// families of their own ("Read").
+func commandFamilies(commands []session.CommandEntry) []string {
+	type program struct {
+		name  string
+		count int
+		subs  map[string]bool
+	}
+	byName := make(map[string]*program)
+	for i := range commands {
+		words := patternWords(&commands[i])
+		p, ok := byName[words[0]]
+		if !ok {
+			p = &program{name: words[0], subs: make(map[string]bool)}
+			byName[words[0]] = p
+		}
+		p.count++
+		p.subs[strings.Join(words, " ")] = true
+	}
+
+	programs := make([]*program, 0, len(byName))
+	for _, p := range byName {
+		programs = append(programs, p)
+	}
+	sort.Slice(programs, func(i, j int) bool {
+		if programs[i].count != programs[j].count {
+			return programs[i].count > programs[j].count
+		}
+		return programs[i].name < programs[j].name
+	})
+
+	var families []string
+	covered := 0
+	for _, p := range programs {
+		if covered*2 >= len(commands) || len(families) == maxMostly {
+			break
+		}
+		name := p.name
+		if len(p.subs) == 1 {
+			for sub := range p.subs {
+				name = sub
+			}
+		}
+		families = append(families, name)
+		covered += p.count
+	}
+	return families
+}
+
+// patternWords splits a command's pattern into its words: "Bash(go:test:*)"
+// is go test, any other tool is its name
+func patternWords(c *session.CommandEntry) []string {
+	inner, ok := strings.CutPrefix(c.Pattern, "Bash(")
+	if !ok {
+		return []string{c.ToolName}
+	}
+	var words []string
+	for _, w := range strings.Split(strings.TrimSuffix(inner, ")"), ":") {
+		if w != "*" && w != "" {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		return []string{c.ToolName}
+	}
+	return words
+}
+
+// projectDir returns the deepest directory below the project root that
+// holds every dir, relative to the root, or "" when there is none
+func projectDir(projectPath string, dirs []string) string {
+	if projectPath == "" || len(dirs) == 0 {
+		return ""
+	}
+	var common []string
+	for i, dir := range dirs {
+		rel, err := filepath.Rel(projectPath, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return ""
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if i == 0 {
+			common = parts
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+		if n == 0 {
+			return ""
+		}
+	}
+	return strings.Join(common, "/")
+}
+
+// firstLine returns a command's first line, truncated to fit a summary
+func firstLine(s string) string {
+	first, _, _ := strings.Cut(s, "\n")
+	if len(first) > 40 {
+		return first[:39] + "…"
+	}
+	return first
+}
+
+// plural returns word with an s unless n is 1
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
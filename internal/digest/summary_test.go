@@ -0,0 +1,64 @@
+package digest
+
+import (
+	"testing"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+func TestSummarize(t *testing.T) {
+	bash := func(pattern, raw string) session.CommandEntry {
+		return session.CommandEntry{ToolName: "Bash", Pattern: pattern, RawCommand: raw}
+	}
+	edit := func(path string) session.CommandEntry {
+		return session.CommandEntry{ToolName: "Edit", Pattern: "Edit", RawCommand: path}
+	}
+	sess := &session.Session{
+		ProjectPath: "/projects/alpha",
+		Commands: []session.CommandEntry{
+			bash("Bash(go:test:*)", "go test ./..."),
+			bash("Bash(go:test:*)", "go test ./internal/..."),
+			bash("Bash(go:test:*)", "go test -run X ./..."),
+			bash("Bash(git:status:*)", "git status"),
+			bash("Bash(git:diff:*)", "git diff"),
+			edit("/projects/alpha/internal/tui/view.go"),
+			edit("/projects/alpha/internal/tui/view.go"),
+			edit("/projects/alpha/internal/digest/summary.go"),
+			bash("Bash(rm:*)", "rm -rf build"),
+		},
+	}
+	sess.Commands[0].IsError = true
+
+	s := Summarize(sess, config.DefaultConfig())
+	want := "9 cmds: mostly Edit/go test; edited 2 files in internal/; 1 dangerous: rm -rf build; 1 failed"
+	if got := s.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := s.Redacted().String(); got != "9 cmds: mostly Edit/go test; edited 2 files; 1 dangerous; 1 failed" {
+		t.Errorf("Redacted() = %q", got)
+	}
+
+	sess.Commands = sess.Commands[:5]
+	if got := Summarize(sess, config.DefaultConfig()).String(); got != "5 cmds: mostly go test; 1 failed" {
+		t.Errorf("String() = %q", got)
+	}
+	sess.Commands = sess.Commands[3:]
+	if got := Summarize(sess, config.DefaultConfig()).String(); got != "2 cmds: mostly git" {
+		t.Errorf("String() = %q", got)
+	}
+	skill := func(name string) session.CommandEntry {
+		return session.CommandEntry{ToolName: "Skill", Pattern: "Skill(" + name + ")", RawCommand: name}
+	}
+	sess.Commands = append(sess.Commands, skill("review"), skill("pdf"), skill("pdf"), skill("xlsx"), skill("docx"))
+	if got := Summarize(sess, config.DefaultConfig()).String(); got != "7 cmds: mostly Skill; skills pdf ×2, docx, review, +1 more" {
+		t.Errorf("String() = %q", got)
+	}
+	sess.Thinking = session.ThinkingStats{Blocks: 4, Chars: 5300}
+	if got := Summarize(sess, config.DefaultConfig()).String(); got != "7 cmds: mostly Skill; skills pdf ×2, docx, review, +1 more; thinking 4 blocks, 5.3k chars" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := Summarize(&session.Session{}, config.DefaultConfig()).String(); got != "no commands" {
+		t.Errorf("String() = %q", got)
+	}
+}
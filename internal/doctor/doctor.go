@@ -0,0 +1,390 @@
+// Package doctor diagnoses the environment cc_session_mon runs in: the
+// config, the projects directories, file watching and its limits, the
+// external tools some features need, and the terminal. Each check says
+// what to do about a problem.
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/muesli/termenv"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/devagent"
+	"cc_session_mon/internal/remote"
+	"cc_session_mon/internal/session"
+)
+
+// Status is a check's outcome
+type Status int
+
+const (
+	OK   Status = iota
+	Warn        // Works, but something is likely to go wrong
+	Fail        // Something doesn't work
+)
+
+func (s Status) String() string {
+	switch s {
+	case Warn:
+		return "warn"
+	case Fail:
+		return "FAIL"
+	default:
+		return "ok"
+	}
+}
+
+// Result is what a check found, and how to fix it if it isn't OK
+type Result struct {
+	Check  string
+	Status Status
+	Detail string
+	Fix    string
+}
+
+// Options sets what Run checks
+type Options struct {
+	Config         *config.Config
+	ConfigPath     string // The config file in use, "" if none
+	FollowDevagent bool   // Check devagent as --follow-devagent needs it
+}
+
+// Run runs every check
+func Run(opts Options) []Result {
+	results := []Result{checkConfig(opts.ConfigPath)}
+
+	dirs := projectsDirs(opts.Config)
+	sessions := 0
+	for _, dir := range dirs {
+		r, n := checkProjectsDir(dir)
+		results = append(results, r)
+		sessions += n
+	}
+	if sessions == 0 {
+		results = append(results, Result{Check: "Sessions", Status: Warn,
+			Detail: "no session files in any projects directory",
+			Fix:    "start a Claude Code session as this user, or point projects_dirs, homes, or shared_dirs at where sessions are"})
+	}
+
+	results = append(results, checkFsnotify(), checkWatchLimit(dirs, opts.Config.WatchStrategy))
+	dr, devagentDirs := checkDevagent(opts.FollowDevagent)
+	results = append(results, dr)
+	for _, dir := range devagentDirs {
+		r, _ := checkProjectsDir(dir)
+		results = append(results, r)
+	}
+	for _, src := range opts.Config.RemoteSources {
+		results = append(results, checkRemoteSource(src))
+	}
+	return append(results, checkTerminal(os.Stdout, opts.Config.TextIndicators))
+}
+
+// Failed reports whether any check failed
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Write prints results, one line each with any fix indented below
+func Write(w io.Writer, results []Result) {
+	for _, r := range results {
+		fmt.Fprintf(w, "[%-4s] %s: %s\n", r.Status, r.Check, r.Detail)
+		if r.Fix != "" && r.Status != OK {
+			fmt.Fprintf(w, "       fix: %s\n", r.Fix)
+		}
+	}
+}
+
+// projectsDirs returns the projects directories the TUI watches without
+// --follow-devagent: the local one, projects_dirs, homes, and shared_dirs
+func projectsDirs(cfg *config.Config) []string {
+	home, _ := os.UserHomeDir()
+	dirs := []string{filepath.Join(home, ".claude", "projects")}
+	dirs = append(dirs, session.ExpandProjectsDirs(cfg.ProjectsDirs)...)
+	for _, h := range session.FindHomeProjects(cfg.Homes) {
+		dirs = append(dirs, h.Dir)
+	}
+	for _, s := range session.FindSharedProjects(cfg.SharedDirs) {
+		dirs = append(dirs, s.Dir)
+	}
+	return dirs
+}
+
+// checkConfig validates the config file in use
+func checkConfig(path string) Result {
+	r := Result{Check: "Config"}
+	if path == "" {
+		r.Detail = "no config file, using the defaults"
+		return r
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("%s can't be read: %v", path, err)
+		r.Fix = "fix its permissions, or pass --config with another file"
+		return r
+	}
+	problems := config.Validate(data)
+	if len(problems) == 0 {
+		r.Detail = path + " is valid"
+		return r
+	}
+	r.Status = Fail
+	r.Detail = fmt.Sprintf("%s has %d problem(s), the first at line %s", path, len(problems), problems[0])
+	r.Fix = "run `cc_session_mon config validate` to list them"
+	return r
+}
+
+// checkProjectsDir checks a projects directory can be read, returning how
+// many session files it holds
+func checkProjectsDir(dir string) (Result, int) {
+	r := Result{Check: "Projects directory"}
+	info, err := os.Stat(dir)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		r.Status, r.Detail = Warn, dir+" does not exist"
+		r.Fix = "Claude Code creates it on its first session; if CLAUDE_CONFIG_DIR is set, add $CLAUDE_CONFIG_DIR/projects to projects_dirs"
+		return r, 0
+	case err != nil:
+		r.Status, r.Detail = Fail, fmt.Sprintf("%s can't be read: %v", dir, err)
+		r.Fix = "run as a user that can read it"
+		return r, 0
+	case !info.IsDir():
+		r.Status, r.Detail = Fail, dir+" is not a directory"
+		return r, 0
+	}
+	projects, err := os.ReadDir(dir)
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("%s can't be read: %v", dir, err)
+		r.Fix = "run as a user that can read it"
+		return r, 0
+	}
+	sessions, unreadable := 0, 0
+	for _, p := range projects {
+		if !p.IsDir() {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join(dir, p.Name(), "*.jsonl"))
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			if fh, err := os.Open(f); err != nil { //nolint:gosec // a session file
+				unreadable++
+			} else {
+				_ = fh.Close()
+				sessions++
+			}
+		}
+	}
+	r.Detail = fmt.Sprintf("%s: %d project(s), %d session(s)", dir, len(projects), sessions)
+	if unreadable > 0 {
+		r.Status = Fail
+		r.Detail += fmt.Sprintf(", %d session file(s) can't be read", unreadable)
+		r.Fix = "run as a user that can read them"
+	}
+	return r, sessions
+}
+
+// checkFsnotify watches a temporary directory and waits for the event of a
+// file created in it
+func checkFsnotify() Result {
+	r := Result{Check: "File watching"}
+	fail := func(err error) Result {
+		r.Status, r.Detail = Fail, fmt.Sprintf("fsnotify doesn't work here: %v", err)
+		r.Fix = "set watch_strategy: poll to find changes by polling"
+		return r
+	}
+	dir, err := os.MkdirTemp("", "cc_session_mon-doctor-")
+	if err != nil {
+		return fail(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fail(err)
+	}
+	defer func() { _ = w.Close() }()
+	if err := w.Add(dir); err != nil {
+		return fail(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "probe.jsonl"), []byte("{}\n"), 0o600); err != nil {
+		return fail(err)
+	}
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-w.Events:
+			if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) {
+				r.Detail = "events arrive for new files"
+				return r
+			}
+		case err := <-w.Errors:
+			return fail(err)
+		case <-timeout:
+			return fail(errors.New("no event within 2s of creating a file"))
+		}
+	}
+}
+
+// checkWatchLimit compares the watches the projects directories need with
+// the OS limit on them
+func checkWatchLimit(dirs []string, strategy string) Result {
+	r := Result{Check: "Watch limit"}
+	limit, what, fix, ok := watchLimit()
+	if !ok {
+		r.Detail = "no watch limit to check on this OS"
+		return r
+	}
+	needed := 0
+	for _, dir := range dirs {
+		needed += watchesNeeded(dir, strategy)
+	}
+	r.Detail = fmt.Sprintf("about %d watch(es) needed with watch_strategy %s; %s is %d", needed, strategyName(strategy), what, limit)
+	// Editors, IDEs, and file sync share the limit, so leave them room
+	if needed > limit/2 {
+		r.Status = Warn
+		r.Fix = fix
+	}
+	return r
+}
+
+// watchesNeeded counts the directories a watch strategy watches under a
+// projects directory: the projects directory itself, project directories,
+// and session and subagents directories
+func watchesNeeded(root, strategy string) int {
+	depth := 3
+	switch strategy {
+	case session.WatchPoll:
+		depth = 0
+	case session.WatchProjects:
+		depth = 1
+	}
+	n := 0
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		level := 0
+		if rel != "." {
+			level = strings.Count(rel, string(filepath.Separator)) + 1
+		}
+		if level > depth {
+			return filepath.SkipDir
+		}
+		n++
+		return nil
+	})
+	return n
+}
+
+func strategyName(strategy string) string {
+	if strategy == "" {
+		return session.WatchDirectories
+	}
+	return strategy
+}
+
+// checkDevagent checks the devagent CLI and lists its environments,
+// returning their projects directories
+func checkDevagent(follow bool) (Result, []string) {
+	r := Result{Check: "devagent"}
+	path, err := exec.LookPath("devagent")
+	if err != nil {
+		r.Detail = "not installed (only needed for --follow-devagent)"
+		if follow {
+			r.Status, r.Detail = Fail, "not found in PATH, so --follow-devagent finds no containers"
+			r.Fix = "install devagent or add it to PATH"
+		}
+		return r, nil
+	}
+	envs, err := devagent.Discover()
+	if err != nil {
+		r.Status, r.Detail = Warn, fmt.Sprintf("%s: %v", path, err)
+		r.Fix = "check that `devagent list` works (is Docker running?)"
+		if follow {
+			r.Status = Fail
+		}
+		return r, nil
+	}
+	r.Detail = fmt.Sprintf("%s: %d environment(s)", path, len(envs))
+	if !follow {
+		return r, nil
+	}
+	var dirs []string
+	for _, env := range envs {
+		dirs = append(dirs, env.ProjectsDir)
+	}
+	return r, dirs
+}
+
+// checkRemoteSource checks the CLI a remote source is read with is installed
+func checkRemoteSource(src config.RemoteSource) Result {
+	r := Result{Check: "Remote source " + src.URL}
+	scheme, _, _, err := remote.ParseURL(src.URL)
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		return r
+	}
+	tool := "aws"
+	if scheme == "gs" {
+		tool = "gcloud"
+	}
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		r.Status, r.Detail = Fail, tool+" CLI not found in PATH"
+		r.Fix = "install the " + tool + " CLI and sign in with it"
+		return r
+	}
+	r.Detail = "read with " + path
+	return r
+}
+
+// checkTerminal checks out is a terminal with colors and a UTF-8 locale
+func checkTerminal(out *os.File, textIndicators bool) Result {
+	r := Result{Check: "Terminal"}
+	info, err := out.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		r.Detail = "output isn't a terminal, so terminal checks are skipped (use --plain for pipes)"
+		return r
+	}
+	term := os.Getenv("TERM")
+	if term == "" || term == "dumb" {
+		r.Status, r.Detail = Warn, fmt.Sprintf("TERM is %q, so the TUI can't draw", term)
+		r.Fix = "set TERM (e.g. xterm-256color), or use --plain"
+		return r
+	}
+	profile := map[termenv.Profile]string{
+		termenv.TrueColor: "true color", termenv.ANSI256: "256 colors", termenv.ANSI: "16 colors", termenv.Ascii: "no colors",
+	}[termenv.NewOutput(out).Profile]
+	r.Detail = fmt.Sprintf("TERM=%s, %s", term, profile)
+
+	locale := os.Getenv("LC_ALL")
+	if locale == "" {
+		locale = os.Getenv("LC_CTYPE")
+	}
+	if locale == "" {
+		locale = os.Getenv("LANG")
+	}
+	upper := strings.ToUpper(locale)
+	if !strings.Contains(upper, "UTF-8") && !strings.Contains(upper, "UTF8") && !textIndicators {
+		r.Status = Warn
+		r.Detail += fmt.Sprintf(", locale %q isn't UTF-8", locale)
+		r.Fix = "set LANG to a UTF-8 locale, or text_indicators: true for ASCII indicators"
+	}
+	return r
+}
@@ -0,0 +1,90 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestCheckConfig(t *testing.T) {
+	dir := t.TempDir()
+	if r := checkConfig(""); r.Status != OK {
+		t.Errorf("no config file: %+v", r)
+	}
+	good := filepath.Join(dir, "good.yaml")
+	_ = os.WriteFile(good, []byte("theme: latte\n"), 0o600)
+	if r := checkConfig(good); r.Status != OK {
+		t.Errorf("valid config: %+v", r)
+	}
+	bad := filepath.Join(dir, "bad.yaml")
+	_ = os.WriteFile(bad, []byte("theme: latte\nthme: mocha\n"), 0o600)
+	if r := checkConfig(bad); r.Status != Fail || !strings.Contains(r.Detail, "line 2") || r.Fix == "" {
+		t.Errorf("invalid config: %+v", r)
+	}
+}
+
+func TestCheckProjectsDir(t *testing.T) {
+	dir := t.TempDir()
+	if r, n := checkProjectsDir(filepath.Join(dir, "missing")); r.Status != Warn || n != 0 || r.Fix == "" {
+		t.Errorf("missing dir: %+v, %d", r, n)
+	}
+
+	_ = os.MkdirAll(filepath.Join(dir, "-proj", "sess", "subagents"), 0o750)
+	_ = os.WriteFile(filepath.Join(dir, "-proj", "sess.jsonl"), []byte("{}\n"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "-proj", "other.jsonl"), []byte("{}\n"), 0o600)
+	r, n := checkProjectsDir(dir)
+	if r.Status != OK || n != 2 || !strings.Contains(r.Detail, "1 project(s), 2 session(s)") {
+		t.Errorf("readable dir: %+v, %d", r, n)
+	}
+
+	file := filepath.Join(dir, "-proj", "sess.jsonl")
+	if r, _ := checkProjectsDir(file); r.Status != Fail {
+		t.Errorf("file as projects dir: %+v", r)
+	}
+}
+
+func TestWatchesNeeded(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "-a", "sess", "subagents"), 0o750)
+	_ = os.MkdirAll(filepath.Join(dir, "-b"), 0o750)
+
+	tests := []struct {
+		strategy string
+		want     int
+	}{
+		{"", 5},
+		{session.WatchDirectories, 5},
+		{session.WatchProjects, 3},
+		{session.WatchPoll, 1},
+	}
+	for _, tt := range tests {
+		if got := watchesNeeded(dir, tt.strategy); got != tt.want {
+			t.Errorf("watchesNeeded(%q) = %d, want %d", tt.strategy, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFsnotify(t *testing.T) {
+	if r := checkFsnotify(); r.Status != OK {
+		t.Errorf("fsnotify: %+v", r)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	results := []Result{
+		{Check: "Config", Detail: "valid", Fix: "unused"},
+		{Check: "devagent", Status: Fail, Detail: "not found", Fix: "install it"},
+	}
+	var b strings.Builder
+	Write(&b, results)
+	want := "[ok  ] Config: valid\n[FAIL] devagent: not found\n       fix: install it\n"
+	if b.String() != want {
+		t.Errorf("Write:\n%s\nwant:\n%s", b.String(), want)
+	}
+	if !Failed(results) || Failed(results[:1]) {
+		t.Error("Failed should report only a failing check")
+	}
+}
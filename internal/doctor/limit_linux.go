@@ -0,0 +1,21 @@
+package doctor
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// watchLimit returns inotify's per-user watch limit
+func watchLimit() (limit int, what, fix string, ok bool) {
+	data, err := os.ReadFile("/proc/sys/fs/inotify/max_user_watches")
+	if err != nil {
+		return 0, "", "", false
+	}
+	limit, err = strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, "", "", false
+	}
+	return limit, "fs.inotify.max_user_watches",
+		"raise it with `sudo sysctl fs.inotify.max_user_watches=524288` (add it to /etc/sysctl.d/ to keep it), or set watch_strategy: projects", true
+}
@@ -0,0 +1,8 @@
+//go:build windows || plan9
+
+package doctor
+
+// watchLimit reports no limit on platforms whose watches don't have one
+func watchLimit() (limit int, what, fix string, ok bool) {
+	return 0, "", "", false
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !windows && !plan9
+
+package doctor
+
+import "syscall"
+
+// watchLimit returns the open file limit, which kqueue's watches (one open
+// file each) count against
+func watchLimit() (limit int, what, fix string, ok bool) {
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return 0, "", "", false
+	}
+	return int(min(rl.Cur, 1<<30)), "the open file limit (ulimit -n)",
+		"raise the open file limit (ulimit -n), or set watch_strategy: projects", true
+}
@@ -0,0 +1,315 @@
+// Package export bundles sessions' JSONL files and their metadata into an
+// age-encrypted tar.gz archive, for long-term retention of audit records,
+// and opens such archives again for review. Archives decrypt with the age
+// tool too: age -d -i key.txt archive.tar.gz.age | tar xz
+package export
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/scrub"
+	"cc_session_mon/internal/session"
+
+	"filippo.io/age"
+)
+
+// manifestName is the archive entry describing its sessions
+const manifestName = "manifest.json"
+
+// Options configures an export
+type Options struct {
+	Version    string          // Application version recorded in the manifest
+	Recipients []age.Recipient // Who can open the archive: public keys, or a passphrase
+	Scrub      *scrub.Scrubber // Scrubs the archived files, if set
+}
+
+// Manifest describes an archive's sessions and files
+type Manifest struct {
+	Version    string          `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Host       string          `json:"host"`
+	Scrubbed   bool            `json:"scrubbed,omitempty"` // Files had the scrub rules applied
+	Sessions   []SessionRecord `json:"sessions"`
+}
+
+// SessionRecord is a session's metadata and files in the manifest
+type SessionRecord struct {
+	ID           string       `json:"id"`
+	ProjectPath  string       `json:"project_path"`
+	Origin       string       `json:"origin"`
+	Owner        string       `json:"owner,omitempty"`
+	GitBranch    string       `json:"git_branch,omitempty"`
+	StartedAt    time.Time    `json:"started_at"`
+	LastActivity time.Time    `json:"last_activity"`
+	CommandCount int          `json:"command_count"`
+	Dir          string       `json:"dir"`   // Projects directory in the archive holding the session
+	Files        []FileRecord `json:"files"` // The session file, then its subagent transcripts
+}
+
+// FileRecord is an archived file and its checksum
+type FileRecord struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Filter selects the sessions to export; zero fields match every session
+type Filter struct {
+	IDs     []string      // Session IDs or ID prefixes
+	Project string        // Substring of the project path
+	Owner   string        // Owner label (config owners)
+	Since   time.Duration // Active within this long
+}
+
+// Select returns the sessions matching the filter
+func (f Filter) Select(sessions []*session.Session) []*session.Session {
+	var selected []*session.Session
+	for _, sess := range sessions {
+		switch {
+		case len(f.IDs) > 0 && !slices.ContainsFunc(f.IDs, func(id string) bool { return strings.HasPrefix(sess.ID, id) }),
+			f.Project != "" && !strings.Contains(sess.ProjectPath, f.Project),
+			f.Owner != "" && config.Global().OwnerOf(sess.Origin, sess.ProjectPath) != f.Owner,
+			f.Since > 0 && time.Since(sess.LastActivity) > f.Since:
+			continue
+		}
+		selected = append(selected, sess)
+	}
+	return selected
+}
+
+// Write archives sessions' files and a manifest of them to archive, encrypted
+// to opts.Recipients. Sessions are laid out by origin as projects
+// directories (projects/<origin>/<project>/<session>.jsonl) so an opened
+// archive is watched like live sessions. Files still being written are
+// archived up to their last complete line.
+func Write(archive string, sessions []*session.Session, opts Options) (err error) {
+	f, err := os.OpenFile(filepath.Clean(archive), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(archive)
+		}
+	}()
+
+	enc, err := age.Encrypt(f, opts.Recipients...)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(enc)
+	tw := tar.NewWriter(gz)
+
+	host, _ := os.Hostname()
+	manifest := Manifest{Version: opts.Version, ExportedAt: time.Now(), Host: host, Scrubbed: opts.Scrub != nil}
+	slugs := map[string]string{}
+	for _, sess := range sessions {
+		record := SessionRecord{
+			ID:           sess.ID,
+			ProjectPath:  sess.ProjectPath,
+			Origin:       sess.Origin,
+			Owner:        config.Global().OwnerOf(sess.Origin, sess.ProjectPath),
+			GitBranch:    sess.GitBranch,
+			StartedAt:    sess.StartedAt,
+			LastActivity: sess.LastActivity,
+			CommandCount: len(sess.Commands),
+			Dir:          path.Join("projects", originSlug(slugs, sess.Origin)),
+		}
+		for _, file := range sessionFiles(sess.FilePath) {
+			rel, _ := filepath.Rel(filepath.Dir(filepath.Dir(sess.FilePath)), file)
+			fr, err := addFile(tw, path.Join(record.Dir, filepath.ToSlash(rel)), file, opts.Scrub)
+			if err != nil {
+				return fmt.Errorf("archiving %s: %w", file, err)
+			}
+			record.Files = append(record.Files, fr)
+		}
+		manifest.Sessions = append(manifest.Sessions, record)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: manifestName, Mode: 0o600, Size: int64(len(data)), ModTime: manifest.ExportedAt}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	return errors.Join(tw.Close(), gz.Close(), enc.Close())
+}
+
+// sessionFiles returns a session file and its subagent transcripts
+func sessionFiles(sessionPath string) []string {
+	subagents, _ := filepath.Glob(filepath.Join(strings.TrimSuffix(sessionPath, ".jsonl"), "subagents", "*.jsonl"))
+	return append([]string{sessionPath}, subagents...)
+}
+
+// addFile writes a file's complete lines to the archive as name, scrubbed
+// if scrubber is set
+func addFile(tw *tar.Writer, name, file string, scrubber *scrub.Scrubber) (FileRecord, error) {
+	data, err := os.ReadFile(file) //nolint:gosec // a watched session file
+	if err != nil {
+		return FileRecord{}, err
+	}
+	data = data[:bytes.LastIndexByte(data, '\n')+1]
+	if scrubber != nil {
+		var buf bytes.Buffer
+		if _, err := scrubber.Copy(&buf, bytes.NewReader(data)); err != nil {
+			return FileRecord{}, err
+		}
+		data = buf.Bytes()
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return FileRecord{}, err
+	}
+	hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return FileRecord{}, err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return FileRecord{}, err
+	}
+	sum := sha256.Sum256(data)
+	return FileRecord{Path: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+var unsafeSlug = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// originSlug returns the directory name for an origin's sessions, unique
+// among the origins seen so far
+func originSlug(slugs map[string]string, origin string) string {
+	if slug, ok := slugs[origin]; ok {
+		return slug
+	}
+	base := strings.Trim(unsafeSlug.ReplaceAllString(origin, "-"), "-.")
+	if base == "" {
+		base = "local"
+	}
+	slug := base
+	for n := 2; slices.Contains(slices.Collect(maps.Values(slugs)), slug); n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	slugs[origin] = slug
+	return slug
+}
+
+// Open decrypts archive with the first matching identity and
+// extracts its sessions into dir, checking every file against the
+// manifest's checksums. A file missing, altered, or not in the manifest
+// fails the open.
+func Open(archive, dir string, identities ...age.Identity) (*Manifest, error) {
+	f, err := os.Open(filepath.Clean(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec, err := age.Decrypt(f, identities...)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(dec)
+	if err != nil {
+		return nil, fmt.Errorf("not a session archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var manifest *Manifest
+	extracted := map[string]FileRecord{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == manifestName {
+			manifest = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("reading manifest: %w", err)
+			}
+			continue
+		}
+		rel := filepath.FromSlash(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || !strings.HasPrefix(hdr.Name, "projects/") || !filepath.IsLocal(rel) {
+			return nil, fmt.Errorf("unexpected archive entry %q", hdr.Name)
+		}
+		fr, err := extractFile(tr, filepath.Join(dir, rel))
+		if err != nil {
+			return nil, err
+		}
+		fr.Path = hdr.Name
+		extracted[hdr.Name] = fr
+	}
+	if manifest == nil {
+		return nil, errors.New("not a session archive: no manifest")
+	}
+
+	for _, sess := range manifest.Sessions {
+		for _, want := range sess.Files {
+			got, ok := extracted[want.Path]
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("archive is missing %s", want.Path)
+			case got != want:
+				return nil, fmt.Errorf("%s doesn't match its checksum in the manifest", want.Path)
+			}
+			delete(extracted, want.Path)
+		}
+	}
+	for name := range extracted {
+		return nil, fmt.Errorf("%s isn't in the archive's manifest", name)
+	}
+	return manifest, nil
+}
+
+// extractFile writes an archive entry to file, returning its size and checksum
+func extractFile(r io.Reader, file string) (FileRecord, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return FileRecord{}, err
+	}
+	out, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // inside the extraction directory
+	if err != nil {
+		return FileRecord{}, err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), r)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return FileRecord{Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}, err
+}
+
+// ProjectsDirs returns the projects directories an archive extracted into
+// dir holds, mapped to the origin of their sessions
+func (m *Manifest) ProjectsDirs(dir string) map[string]string {
+	dirs := map[string]string{}
+	for _, sess := range m.Sessions {
+		dirs[filepath.Join(dir, filepath.FromSlash(sess.Dir))] = sess.Origin
+	}
+	return dirs
+}
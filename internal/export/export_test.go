@@ -0,0 +1,164 @@
+package export
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/scrub"
+	"cc_session_mon/internal/session"
+
+	"filippo.io/age"
+)
+
+// writeSession creates a session file, and a subagent transcript, under a
+// projects directory
+func writeSession(t *testing.T, projects, project, id, content string) *session.Session {
+	t.Helper()
+	file := filepath.Join(projects, project, id+".jsonl")
+	subagent := filepath.Join(projects, project, id, "subagents", "agent-1.jsonl")
+	if err := os.MkdirAll(filepath.Dir(subagent), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(subagent, []byte(`{"type":"user"}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return &session.Session{ID: id, FilePath: file, ProjectPath: "/home/alice/" + project, LastActivity: time.Now()}
+}
+
+func TestWriteOpen(t *testing.T) {
+	projects := t.TempDir()
+	local := writeSession(t, projects, "-home-alice-alpha", "sess-1", `{"type":"user"}`+"\n"+`{"type":"assistant"}`+"\n"+`{"partial`)
+	local.Origin = "local"
+	remote := writeSession(t, projects, "-home-alice-beta", "sess-2", `{"type":"user"}`+"\n")
+	remote.Origin = "devagent:box-1"
+
+	id, _ := age.GenerateX25519Identity()
+	archive := filepath.Join(t.TempDir(), "export.tar.gz.age")
+	if err := Write(archive, []*session.Session{local, remote}, Options{Version: "test", Recipients: []age.Recipient{id.Recipient()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	var noMatch *age.NoIdentityMatchError
+	if _, err := Open(archive, t.TempDir(), other); !errors.As(err, &noMatch) {
+		t.Errorf("expected another identity to be refused, got %v", err)
+	}
+
+	dir := t.TempDir()
+	manifest, err := Open(archive, dir, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Sessions) != 2 || manifest.Version != "test" {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	dirs := manifest.ProjectsDirs(dir)
+	if dirs[filepath.Join(dir, "projects", "local")] != "local" || dirs[filepath.Join(dir, "projects", "devagent-box-1")] != "devagent:box-1" {
+		t.Errorf("unexpected projects directories %v", dirs)
+	}
+
+	// The trailing partial line is left out
+	got, err := os.ReadFile(filepath.Join(dir, "projects", "local", "-home-alice-alpha", "sess-1.jsonl"))
+	if err != nil || string(got) != `{"type":"user"}`+"\n"+`{"type":"assistant"}`+"\n" {
+		t.Errorf("extracted session = %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "projects", "devagent-box-1", "-home-alice-beta", "sess-2", "subagents", "agent-1.jsonl")); err != nil {
+		t.Errorf("expected the subagent transcript to be extracted: %v", err)
+	}
+}
+
+func TestOpenRefusesUnlistedEntries(t *testing.T) {
+	id, _ := age.GenerateX25519Identity()
+	write := func(entries map[string]string) string {
+		archive := filepath.Join(t.TempDir(), "crafted.tar.gz.age")
+		f, _ := os.Create(archive)
+		enc, _ := age.Encrypt(f, id.Recipient())
+		gz := gzip.NewWriter(enc)
+		tw := tar.NewWriter(gz)
+		for name, data := range entries {
+			_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data))})
+			_, _ = tw.Write([]byte(data))
+		}
+		_ = tw.Close()
+		_ = gz.Close()
+		_ = enc.Close()
+		_ = f.Close()
+		return archive
+	}
+
+	tests := []struct {
+		name    string
+		entries map[string]string
+		want    string
+	}{
+		{"escaping path", map[string]string{"projects/../../evil": "x"}, "unexpected archive entry"},
+		{"no manifest", map[string]string{"projects/local/p/s.jsonl": "x"}, "no manifest"},
+		{"unlisted file", map[string]string{manifestName: `{"sessions":[]}`, "projects/local/p/s.jsonl": "x"}, "isn't in the archive's manifest"},
+		{"altered file", map[string]string{
+			manifestName:               `{"sessions":[{"files":[{"path":"projects/local/p/s.jsonl","size":1,"sha256":"00"}]}]}`,
+			"projects/local/p/s.jsonl": "x",
+		}, "doesn't match its checksum"},
+	}
+	for _, tt := range tests {
+		_, err := Open(write(tt.entries), t.TempDir(), id)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestFilterSelect(t *testing.T) {
+	now := time.Now()
+	sessions := []*session.Session{
+		{ID: "abc123", ProjectPath: "/home/alice/alpha", LastActivity: now},
+		{ID: "def456", ProjectPath: "/home/alice/beta", LastActivity: now.Add(-48 * time.Hour)},
+	}
+	tests := []struct {
+		filter Filter
+		want   int
+	}{
+		{Filter{}, 2},
+		{Filter{IDs: []string{"abc"}}, 1},
+		{Filter{Project: "beta"}, 1},
+		{Filter{Since: 24 * time.Hour}, 1},
+		{Filter{IDs: []string{"def"}, Since: 24 * time.Hour}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Select(sessions); len(got) != tt.want {
+			t.Errorf("%+v selected %d sessions, want %d", tt.filter, len(got), tt.want)
+		}
+	}
+}
+
+func TestWriteScrubbed(t *testing.T) {
+	sess := writeSession(t, t.TempDir(), "-home-alice-alpha", "sess-1",
+		`{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"plan"}]}}`+"\n")
+	scrubber, err := scrub.New(config.ScrubRules{DropThinking: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := age.GenerateX25519Identity()
+	archive := filepath.Join(t.TempDir(), "export.tar.gz.age")
+	if err := Write(archive, []*session.Session{sess}, Options{Recipients: []age.Recipient{id.Recipient()}, Scrub: scrubber}); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	manifest, err := Open(archive, dir, id)
+	if err != nil || !manifest.Scrubbed {
+		t.Fatalf("Open = %+v, %v", manifest, err)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "projects", "local", "-home-alice-alpha", "sess-1.jsonl"))
+	if strings.Contains(string(got), "plan") {
+		t.Errorf("expected the thinking block dropped, got %q", got)
+	}
+}
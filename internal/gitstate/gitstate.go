@@ -0,0 +1,118 @@
+// Package gitstate records a project's git state and diffs the work tree
+// against it later, tying a session's commands to the net change they made.
+package gitstate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot is a project's git state at one moment: HEAD plus uncommitted
+// changes to tracked files, and which files were untracked
+type Snapshot struct {
+	Dir       string    // Top level of the work tree
+	Head      string    // HEAD commit when taken
+	Commit    string    // Commit holding the state: a `git stash create` commit, or Head when clean
+	Taken     time.Time // When the snapshot was taken
+	Untracked []string  // Untracked, not ignored files, relative to Dir
+}
+
+// Diff is the change in a work tree since a snapshot
+type Diff struct {
+	Patch    string   // `git diff` of tracked files against the snapshot
+	NewFiles []string // Untracked files that were not there at the snapshot
+}
+
+// Empty reports whether nothing changed since the snapshot
+func (d *Diff) Empty() bool {
+	return strings.TrimSpace(d.Patch) == "" && len(d.NewFiles) == 0
+}
+
+// Take snapshots the git state of the work tree containing dir. It creates
+// a dangling stash commit for uncommitted changes, so the work tree, index,
+// and refs are left alone.
+func Take(ctx context.Context, dir string) (*Snapshot, error) {
+	top, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{Dir: strings.TrimSpace(top), Taken: time.Now()}
+	head, err := git(ctx, snap.Dir, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("%s has no commits yet", snap.Dir)
+	}
+	snap.Head = strings.TrimSpace(head)
+
+	stash, err := git(ctx, snap.Dir, "stash", "create")
+	if err != nil {
+		return nil, err
+	}
+	snap.Commit = strings.TrimSpace(stash)
+	if snap.Commit == "" {
+		snap.Commit = snap.Head // Nothing uncommitted
+	}
+	if snap.Untracked, err = untracked(ctx, snap.Dir); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Diff compares the work tree with the snapshot: tracked files as a patch
+// (including commits made since), and the untracked files that are new
+func (s *Snapshot) Diff(ctx context.Context) (*Diff, error) {
+	patch, err := git(ctx, s.Dir, "diff", "--no-color", "--no-ext-diff", s.Commit, "--")
+	if err != nil {
+		return nil, err
+	}
+	now, err := untracked(ctx, s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	before := make(map[string]bool, len(s.Untracked))
+	for _, f := range s.Untracked {
+		before[f] = true
+	}
+	d := &Diff{Patch: patch}
+	for _, f := range now {
+		if !before[f] {
+			d.NewFiles = append(d.NewFiles, f)
+		}
+	}
+	return d, nil
+}
+
+// untracked lists the untracked, not ignored files of the work tree at dir
+func untracked(ctx context.Context, dir string) ([]string, error) {
+	out, err := git(ctx, dir, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// git runs a git subcommand in dir and returns its output, or an error with
+// git's message
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...) //nolint:gosec // fixed git binary, our own arguments
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
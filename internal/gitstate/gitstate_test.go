@@ -0,0 +1,86 @@
+package gitstate
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testRepo creates a git repository with one committed file
+func testRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	write(t, dir, "main.go", "package main\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	return dir
+}
+
+func write(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	dir := testRepo(t)
+	ctx := context.Background()
+
+	// Changes from before the session are not part of its diff
+	write(t, dir, "main.go", "package main\n\n// before\n")
+	write(t, dir, "notes.txt", "old\n")
+	snap, err := Take(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Commit == snap.Head {
+		t.Error("snapshot of a dirty tree should be a stash commit")
+	}
+	if status, _ := git(ctx, dir, "stash", "list"); status != "" {
+		t.Errorf("snapshot should not touch the stash, got %q", status)
+	}
+
+	d, err := snap.Diff(ctx)
+	if err != nil || !d.Empty() {
+		t.Fatalf("diff right after the snapshot = %+v, %v; want empty", d, err)
+	}
+
+	write(t, dir, "main.go", "package main\n\n// before\n// during\n")
+	write(t, dir, "new.go", "package main\n")
+	d, err = snap.Diff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(d.Patch, "+// during") || strings.Contains(d.Patch, "+// before") {
+		t.Errorf("patch = %q, want only the change since the snapshot", d.Patch)
+	}
+	if !reflect.DeepEqual(d.NewFiles, []string{"new.go"}) {
+		t.Errorf("new files = %v, want [new.go]", d.NewFiles)
+	}
+}
+
+func TestTakeOutsideRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if _, err := Take(context.Background(), t.TempDir()); err == nil {
+		t.Error("Take outside a repository should fail")
+	}
+}
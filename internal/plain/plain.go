@@ -0,0 +1,123 @@
+// Package plain writes the session event stream as linear text: one labeled
+// line per new session, activity change, and command, appended as they
+// happen. It replaces the TUI for screen readers and for piping to tee.
+package plain
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+)
+
+// timeFormat is how event times are written
+const timeFormat = "15:04:05"
+
+// Writer turns watcher events into lines of text
+type Writer struct {
+	w      io.Writer
+	active map[string]bool // Last written activity by session file path
+}
+
+// New creates a Writer appending to w
+func New(w io.Writer) *Writer {
+	return &Writer{w: w, active: make(map[string]bool)}
+}
+
+// Initial writes a summary of the sessions found at startup
+func (p *Writer) Initial(sessions []*session.Session) {
+	active := 0
+	for _, sess := range sessions {
+		if sess.IsActive {
+			active++
+		}
+	}
+	p.printf("Found %s, %d active.", count(len(sessions), "session"), active)
+	for _, sess := range sessions {
+		p.active[sess.FilePath] = sess.IsActive
+		p.printf("Session %s: %s, %s, last activity %s, path %s%s.",
+			name(sess), status(sess), count(len(sess.Commands), "command"), sess.LastActivity.Format(timeFormat), sess.ProjectPath, owner(sess))
+	}
+}
+
+// Event writes the lines for one event: new sessions, sessions becoming
+// active or idle, and each new command with its security warnings. The event
+// must not be updated while it is written: pass a
+// Watcher.SnapshotEventWithHistory copy, not one straight from the watcher.
+func (p *Writer) Event(event session.WatchEvent) {
+	sess := event.Session
+	if sess == nil {
+		return
+	}
+
+	switch event.Type {
+	case "discovered":
+		p.active[sess.FilePath] = sess.IsActive
+		p.printf("New session %s, path %s%s.", name(sess), sess.ProjectPath, owner(sess))
+	case "updated":
+		if was, seen := p.active[sess.FilePath]; seen && was != sess.IsActive {
+			p.printf("Session %s is now %s.", name(sess), status(sess))
+		}
+		p.active[sess.FilePath] = sess.IsActive
+	case "removed":
+		delete(p.active, sess.FilePath)
+		p.printf("Session %s is gone, its directory was removed.", name(sess))
+	case "new_commands":
+		cfg := config.ForProject(sess.ProjectPath)
+		for i := range event.Commands {
+			p.command(sess, &event.Commands[i], cfg)
+		}
+	}
+}
+
+// command writes a command's line, with its medium and high severity
+// findings as warnings after it
+func (p *Writer) command(sess *session.Session, cmd *session.CommandEntry, cfg *config.Config) {
+	script, _ := session.SplitHeredocs(cmd.RawCommand)
+	text := strings.Join(strings.Fields(strings.ReplaceAll(script, "\n", "; ")), " ")
+	p.printf("%s, session %s, %s: %s", cmd.Timestamp.Format(timeFormat), name(sess), cmd.ToolName, text)
+
+	for _, f := range security.CommandFindings(cmd, sess.ProjectPath, cfg, sess.Commands) {
+		if f.Severity >= security.SeverityMedium {
+			p.printf("  Warning, %s severity: %s.", f.Severity, f.Rule)
+		}
+	}
+}
+
+// printf writes one line; write errors (a closed pipe) are ignored
+func (p *Writer) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(p.w, format+"\n", args...)
+}
+
+// name returns the project name a session is known by
+func name(sess *session.Session) string {
+	return filepath.Base(sess.ProjectPath)
+}
+
+// owner names a session's owner (see config owners) after its path, if it has one
+func owner(sess *session.Session) string {
+	if o := config.Global().OwnerOf(sess.Origin, sess.ProjectPath); o != "" {
+		return ", owner " + o
+	}
+	return ""
+}
+
+// status describes a session's activity
+func status(sess *session.Session) string {
+	if sess.IsActive {
+		return "active"
+	}
+	return "idle"
+}
+
+// count writes n with word, pluralized unless n is 1
+func count(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
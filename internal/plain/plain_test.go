@@ -0,0 +1,50 @@
+package plain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestWriterEvents(t *testing.T) {
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.Local)
+	sess := &session.Session{
+		FilePath:     "/home/u/.claude/projects/-code-api/s1.jsonl",
+		ProjectPath:  "/code/api",
+		IsActive:     true,
+		LastActivity: at,
+		Commands:     []session.CommandEntry{{ToolName: "Bash", RawCommand: "ls"}},
+	}
+
+	var out bytes.Buffer
+	w := New(&out)
+	w.Initial([]*session.Session{sess})
+
+	// Metadata updates only write a line when the activity changes
+	w.Event(session.WatchEvent{Type: "updated", Session: sess})
+	idle := *sess
+	idle.IsActive = false
+	w.Event(session.WatchEvent{Type: "updated", Session: &idle})
+
+	w.Event(session.WatchEvent{Type: "new_commands", Session: sess, Commands: []session.CommandEntry{
+		{ToolName: "Bash", RawCommand: "cat <<EOF > notes.txt\nhello\nEOF\ngo test ./...", Timestamp: at},
+		{ToolName: "Bash", RawCommand: "rm -rf build", Timestamp: at},
+	}})
+	w.Event(session.WatchEvent{Type: "discovered", Session: &session.Session{ProjectPath: "/home/bob/web", Origin: "user:bob"}})
+
+	want := []string{
+		"Found 1 session, 1 active.",
+		"Session api: active, 1 command, last activity 15:04:05, path /code/api.",
+		"Session api is now idle.",
+		"15:04:05, session api, Bash: cat <<EOF > notes.txt; go test ./...",
+		"15:04:05, session api, Bash: rm -rf build",
+		"  Warning, high severity: Recursive file deletion.",
+		"New session web, path /home/bob/web, owner bob.",
+	}
+	if got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
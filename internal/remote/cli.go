@@ -0,0 +1,114 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// s3Store reads an S3 bucket with the aws CLI, using its configured
+// credentials and region
+type s3Store struct {
+	bucket string
+}
+
+func (s s3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	out, err := run(ctx, "aws", "s3api", "list-objects-v2", "--bucket", s.bucket, "--prefix", prefix,
+		"--query", "Contents[].{Key: Key, Size: Size, ETag: ETag}", "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseS3List(out)
+}
+
+func (s s3Store) Read(ctx context.Context, key string, offset int64) ([]byte, error) {
+	// get-object writes the body to a file and its metadata to stdout
+	tmp, err := os.CreateTemp("", "cc_session_mon-s3-*")
+	if err != nil {
+		return nil, err
+	}
+	_ = tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	args := []string{"s3api", "get-object", "--bucket", s.bucket, "--key", key}
+	if offset > 0 {
+		args = append(args, "--range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if _, err := run(ctx, "aws", append(args, tmp.Name())...); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(tmp.Name())
+}
+
+// parseS3List parses list-objects-v2 output, null for an empty prefix
+func parseS3List(out []byte) ([]Object, error) {
+	var objects []Object
+	if err := json.Unmarshal(out, &objects); err != nil {
+		return nil, fmt.Errorf("unexpected aws s3api output: %w", err)
+	}
+	return objects, nil
+}
+
+// gcsStore reads a Cloud Storage bucket with the gcloud CLI, using its
+// configured account
+type gcsStore struct {
+	bucket string
+}
+
+func (s gcsStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	out, err := run(ctx, "gcloud", "storage", "objects", "list", "gs://"+s.bucket+"/"+prefix+"**",
+		"--format", "value(name,size,etag)")
+	if err != nil {
+		return nil, err
+	}
+	return parseGCSList(out)
+}
+
+func (s gcsStore) Read(ctx context.Context, key string, offset int64) ([]byte, error) {
+	args := []string{"storage", "cat", "gs://" + s.bucket + "/" + key}
+	if offset > 0 {
+		args = append(args, "--range", fmt.Sprintf("%d-", offset))
+	}
+	return run(ctx, "gcloud", args...)
+}
+
+// parseGCSList parses `value(name,size,etag)` output, one tab-separated
+// object per line
+func parseGCSList(out []byte) ([]Object, error) {
+	var objects []Object
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		size, etag, _ := strings.Cut(rest, "\t")
+		n, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected gcloud storage output %q", scanner.Text())
+		}
+		objects = append(objects, Object{Key: name, Size: n, ETag: strings.TrimSpace(etag)})
+	}
+	return objects, scanner.Err()
+}
+
+// run runs a cloud CLI and returns its stdout, or an error carrying its stderr
+func run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...) //nolint:gosec // fixed CLI binary, our own arguments
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,213 @@
+// Package remote mirrors session files archived in cloud storage (S3 or
+// GCS) into a local cache directory the watcher follows like any other
+// projects directory, so archived sessions are reviewed with the same
+// parser and views. Objects are listed by prefix on every poll and fetched
+// incrementally: only the bytes an object grew by are fetched and appended,
+// so the watcher parses just the new lines. An object rewritten in place
+// (a new ETag at the same size) or shrunk is fetched again whole.
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+// Object is a listed object, its size in bytes, and its ETag, which changes
+// whenever the object is rewritten (empty if the store doesn't report one)
+type Object struct {
+	Key  string
+	Size int64
+	ETag string
+}
+
+// Store lists and reads the objects of a bucket
+type Store interface {
+	// List returns the objects whose keys start with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+
+	// Read returns an object's bytes from offset to its end
+	Read(ctx context.Context, key string, offset int64) ([]byte, error)
+}
+
+// Mirror copies the JSONL objects under a bucket prefix into a local directory
+type Mirror struct {
+	store    Store
+	prefix   string
+	dir      string
+	origin   string
+	interval time.Duration
+}
+
+// New creates the mirror of a configured remote source, cached under the
+// cache directory, reading the bucket with the aws or gcloud CLI
+func New(src config.RemoteSource) (*Mirror, error) {
+	scheme, bucket, prefix, err := ParseURL(src.URL)
+	if err != nil {
+		return nil, err
+	}
+	var store Store = s3Store{bucket: bucket}
+	if scheme == "gs" {
+		store = gcsStore{bucket: bucket}
+	}
+	dir := filepath.Join(config.CacheDir(), "remote", scheme, bucket, filepath.FromSlash(prefix))
+	return NewMirror(store, prefix, dir, src), nil
+}
+
+// NewMirror creates a mirror of the objects under prefix in store, kept in dir
+func NewMirror(store Store, prefix, dir string, src config.RemoteSource) *Mirror {
+	return &Mirror{
+		store:    store,
+		prefix:   prefix,
+		dir:      dir,
+		origin:   "remote:" + src.Label(),
+		interval: src.Interval(),
+	}
+}
+
+// ParseURL splits an s3://bucket/prefix or gs://bucket/prefix URL. A
+// non-empty prefix always ends in a slash, so it names a directory.
+func ParseURL(url string) (scheme, bucket, prefix string, err error) {
+	scheme, rest, _ := strings.Cut(url, "://")
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if (scheme != "s3" && scheme != "gs") || bucket == "" {
+		return "", "", "", fmt.Errorf("remote source %q must be s3://bucket/prefix or gs://bucket/prefix", url)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return scheme, bucket, prefix, nil
+}
+
+// Dir returns the local directory the objects are mirrored into
+func (m *Mirror) Dir() string {
+	return m.dir
+}
+
+// Origin returns the origin label of the mirrored sessions, e.g. "remote:archive"
+func (m *Mirror) Origin() string {
+	return m.origin
+}
+
+// Sync fetches the JSONL objects that are new or changed since the last
+// sync. An object that grew has only its new bytes fetched and appended; one
+// that shrank, or kept its size under a new ETag (replaced by a different
+// upload), is fetched whole and renamed over the cached copy. The ETag of
+// each cached copy is kept beside it in a .etag file. Keys that would land outside the mirror directory
+// are skipped. Objects that fail are retried on the next sync, and their
+// errors returned together.
+func (m *Mirror) Sync(ctx context.Context) error {
+	objects, err := m.store.List(ctx, m.prefix)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, obj := range objects {
+		rel := filepath.FromSlash(strings.TrimPrefix(obj.Key, m.prefix))
+		if !strings.HasSuffix(rel, ".jsonl") || !filepath.IsLocal(rel) {
+			continue
+		}
+		path := filepath.Join(m.dir, rel)
+		var have int64
+		if info, err := os.Stat(path); err == nil {
+			have = info.Size()
+		}
+		etag := readETag(path)
+		if obj.Size == have && obj.ETag == etag {
+			continue
+		}
+		if err := m.fetch(ctx, obj, path, have, obj.Size <= have); err != nil {
+			errs = append(errs, fmt.Errorf("fetching %s: %w", obj.Key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fetch brings the cached copy at path, have bytes long, up to date with
+// obj: whole when it was replaced, otherwise by appending the bytes past have
+func (m *Mirror) fetch(ctx context.Context, obj Object, path string, have int64, replaced bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if replaced {
+		data, err := m.store.Read(ctx, obj.Key, 0)
+		if err != nil {
+			return err
+		}
+		// Not a .jsonl name, so the watcher ignores it until renamed
+		tmp := path + ".part"
+		if err := os.WriteFile(tmp, data, 0o600); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return err
+		}
+		return writeETag(path, obj.ETag)
+	}
+
+	data, err := m.store.Read(ctx, obj.Key, have)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600) //nolint:gosec // inside our own cache directory
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err := errors.Join(err, f.Close()); err != nil {
+		return err
+	}
+	return writeETag(path, obj.ETag)
+}
+
+// readETag returns the ETag recorded for the cached copy at path, or "" if
+// none was
+func readETag(path string) string {
+	data, err := os.ReadFile(path + ".etag") //nolint:gosec // inside our own cache directory
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// writeETag records the ETag of the cached copy at path. Like .part, the
+// .etag name isn't one the watcher reads.
+func writeETag(path, etag string) error {
+	if etag == "" {
+		return nil
+	}
+	return os.WriteFile(path+".etag", []byte(etag), 0o600)
+}
+
+// Follow syncs the mirror now and then every poll interval until w is
+// stopped. Failed syncs are reported on w.Errors and retried on the next
+// poll.
+func (m *Mirror) Follow(w *session.Watcher) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+		err := m.Sync(ctx)
+		cancel()
+		if err != nil {
+			select {
+			case w.Errors <- fmt.Errorf("remote source %s: %w", m.origin, err):
+			default:
+			}
+		}
+
+		select {
+		case <-w.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
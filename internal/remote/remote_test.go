@@ -0,0 +1,148 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cc_session_mon/internal/config"
+)
+
+// fakeStore is an in-memory bucket that records the reads made of it
+type fakeStore struct {
+	objects map[string]string
+	reads   []string
+}
+
+func (f *fakeStore) List(_ context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	for key, body := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			etag := fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(body)))
+			objects = append(objects, Object{Key: key, Size: int64(len(body)), ETag: etag})
+		}
+	}
+	return objects, nil
+}
+
+func (f *fakeStore) Read(_ context.Context, key string, offset int64) ([]byte, error) {
+	f.reads = append(f.reads, fmt.Sprintf("%s@%d", key, offset))
+	return []byte(f.objects[key][offset:]), nil
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url, scheme, bucket, prefix string
+		wantErr                     bool
+	}{
+		{url: "s3://archive/claude/projects", scheme: "s3", bucket: "archive", prefix: "claude/projects/"},
+		{url: "gs://ci-agents/sessions/", scheme: "gs", bucket: "ci-agents", prefix: "sessions/"},
+		{url: "s3://archive", scheme: "s3", bucket: "archive"},
+		{url: "https://archive/sessions", wantErr: true},
+		{url: "gs:///sessions", wantErr: true},
+	}
+	for _, tt := range tests {
+		scheme, bucket, prefix, err := ParseURL(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseURL(%q) error = %v", tt.url, err)
+			continue
+		}
+		if scheme != tt.scheme || bucket != tt.bucket || prefix != tt.prefix {
+			t.Errorf("ParseURL(%q) = %q, %q, %q", tt.url, scheme, bucket, prefix)
+		}
+	}
+}
+
+func TestMirrorSync(t *testing.T) {
+	store := &fakeStore{objects: map[string]string{
+		"sessions/-work-app/s1.jsonl":                   "line 1\n",
+		"sessions/-work-app/s1/subagents/agent-1.jsonl": "agent\n",
+		"sessions/-work-app/notes.txt":                  "not a session\n",
+		"sessions/../escape.jsonl":                      "outside\n",
+		"other/-work-app/s2.jsonl":                      "other prefix\n",
+	}}
+	dir := t.TempDir()
+	m := NewMirror(store, "sessions/", dir, config.RemoteSource{URL: "s3://archive/sessions/"})
+	if m.Origin() != "remote:archive" {
+		t.Errorf("Origin() = %q", m.Origin())
+	}
+	read := func(rel string) string {
+		t.Helper()
+		data, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		return string(data)
+	}
+
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("-work-app/s1.jsonl"); got != "line 1\n" {
+		t.Errorf("s1.jsonl = %q", got)
+	}
+	if got := read("-work-app/s1/subagents/agent-1.jsonl"); got != "agent\n" {
+		t.Errorf("agent-1.jsonl = %q", got)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "-work-app"))
+	if len(entries) != 3 {
+		t.Errorf("expected only the session, its ETag, and its directory mirrored, got %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.jsonl")); err == nil {
+		t.Error("expected a key outside the prefix's directory to be skipped")
+	}
+
+	// Unchanged objects aren't fetched again; grown ones only their new bytes
+	store.reads = nil
+	store.objects["sessions/-work-app/s1.jsonl"] += "line 2\n"
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.reads) != 1 || store.reads[0] != "sessions/-work-app/s1.jsonl@7" {
+		t.Errorf("expected one read from the cached size, got %v", store.reads)
+	}
+	if got := read("-work-app/s1.jsonl"); got != "line 1\nline 2\n" {
+		t.Errorf("s1.jsonl = %q", got)
+	}
+
+	// An object replaced by a shorter one is fetched whole
+	store.objects["sessions/-work-app/s1.jsonl"] = "new\n"
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("-work-app/s1.jsonl"); got != "new\n" {
+		t.Errorf("s1.jsonl = %q", got)
+	}
+
+	// So is one rewritten in place at the same size
+	store.reads = nil
+	store.objects["sessions/-work-app/s1.jsonl"] = "old\n"
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.reads) != 1 || store.reads[0] != "sessions/-work-app/s1.jsonl@0" {
+		t.Errorf("expected one whole read, got %v", store.reads)
+	}
+	if got := read("-work-app/s1.jsonl"); got != "old\n" {
+		t.Errorf("s1.jsonl = %q", got)
+	}
+}
+
+func TestParseLists(t *testing.T) {
+	s3, err := parseS3List([]byte(`[{"Key": "p/a.jsonl", "Size": 12, "ETag": "\"9b2cf535f27731c974343645a3985328\""}]`))
+	if err != nil || len(s3) != 1 || s3[0] != (Object{Key: "p/a.jsonl", Size: 12, ETag: `"9b2cf535f27731c974343645a3985328"`}) {
+		t.Errorf("parseS3List = %v, %v", s3, err)
+	}
+	if empty, err := parseS3List([]byte("null\n")); err != nil || len(empty) != 0 {
+		t.Errorf("parseS3List(null) = %v, %v", empty, err)
+	}
+
+	gcs, err := parseGCSList([]byte("p/a.jsonl\t12\tCJC/5ZqPjYEDEAE=\np/b.jsonl\t0\n"))
+	if err != nil || len(gcs) != 2 || gcs[0].ETag != "CJC/5ZqPjYEDEAE=" || gcs[1] != (Object{Key: "p/b.jsonl", Size: 0}) {
+		t.Errorf("parseGCSList = %v, %v", gcs, err)
+	}
+	if _, err := parseGCSList([]byte("p/a.jsonl\tlots\n")); err == nil {
+		t.Error("expected an error for a malformed size")
+	}
+}
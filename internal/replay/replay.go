@@ -0,0 +1,141 @@
+// Package replay records a watcher's event stream to a JSONL file and plays
+// it back into a replica watcher, so UI bugs tied to specific event sequences
+// can be reproduced and turned into regression tests.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+// maxFrameSize bounds a single recorded frame; "discovered" frames carry full command history
+const maxFrameSize = 64 * 1024 * 1024
+
+// Frame is one recorded event. Offset is the time since recording started.
+type Frame struct {
+	OffsetMS int64                  `json:"offset_ms"`
+	Type     string                 `json:"type"` // "discovered", "updated", "new_commands"
+	Session  *session.Session       `json:"session"`
+	Commands []session.CommandEntry `json:"commands,omitempty"`
+}
+
+// Offset returns the frame's offset as a duration
+func (f Frame) Offset() time.Duration {
+	return time.Duration(f.OffsetMS) * time.Millisecond
+}
+
+// Recorder appends a watcher's events to a file
+type Recorder struct {
+	file  *os.File
+	enc   *json.Encoder
+	start time.Time
+	mu    sync.Mutex
+}
+
+// NewRecorder creates (or truncates) path and records the given initial
+// sessions as "discovered" frames at offset zero.
+func NewRecorder(path string, initial []*session.Session) (*Recorder, error) {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{file: f, enc: json.NewEncoder(f), start: time.Now()}
+	for _, sess := range initial {
+		if err := r.write(Frame{Type: "discovered", Session: sess}); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Record writes events from w (typically from w.Subscribe) until the channel
+// closes. Each is encoded from a w.SnapshotEvent copy, since w keeps updating
+// the session while it is written; only "discovered" frames carry history.
+func (r *Recorder) Record(w *session.Watcher, events <-chan session.WatchEvent) {
+	for event := range events {
+		event = w.SnapshotEvent(event)
+		_ = r.write(Frame{
+			OffsetMS: time.Since(r.start).Milliseconds(),
+			Type:     event.Type,
+			Session:  event.Session,
+			Commands: event.Commands,
+		})
+	}
+}
+
+// write encodes a single frame
+func (r *Recorder) write(f Frame) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(f)
+}
+
+// Close flushes and closes the recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// Load reads all frames from a recording
+func Load(path string) ([]Frame, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFrameSize)
+
+	var frames []Frame
+	for scanner.Scan() {
+		var frame Frame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, scanner.Err()
+}
+
+// Play injects frames into w in recorded order. Frames at offset zero are
+// applied synchronously so they form the initial state; the rest are applied
+// in the background with their recorded spacing divided by speed (speed <= 0
+// replays without delays). The returned channel is closed when playback ends.
+func Play(frames []Frame, w *session.Watcher, speed float64) <-chan struct{} {
+	i := 0
+	for ; i < len(frames) && frames[i].OffsetMS == 0; i++ {
+		inject(w, frames[i])
+	}
+
+	done := make(chan struct{})
+	rest := frames[i:]
+	go func() {
+		defer close(done)
+		start := time.Now()
+		for _, f := range rest {
+			if speed > 0 {
+				due := time.Duration(float64(f.Offset()) / speed)
+				if wait := due - time.Since(start); wait > 0 {
+					time.Sleep(wait)
+				}
+			}
+			inject(w, f)
+		}
+	}()
+	return done
+}
+
+// inject applies a frame to the watcher
+func inject(w *session.Watcher, f Frame) {
+	w.Inject(session.WatchEvent{Type: f.Type, Session: f.Session, Commands: f.Commands})
+}
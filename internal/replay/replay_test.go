@@ -0,0 +1,81 @@
+package replay
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestRecordLoadPlay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	ts := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	sess := &session.Session{
+		ID:          "sess-1",
+		ProjectPath: "/projects/alpha",
+		FilePath:    "/projects/-projects-alpha/sess-1.jsonl",
+		Commands: []session.CommandEntry{
+			{ToolName: "Bash", RawCommand: "git status", Pattern: "Bash(git:status:*)", Timestamp: ts},
+		},
+	}
+
+	rec, err := NewRecorder(path, []*session.Session{sess})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := session.NewReplicaWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan session.WatchEvent, 1)
+	events <- session.WatchEvent{
+		Type:    "new_commands",
+		Session: sess,
+		Commands: []session.CommandEntry{
+			{ToolName: "Bash", RawCommand: "go test ./...", Pattern: "Bash(go:test:*)", Timestamp: ts.Add(time.Second)},
+		},
+	}
+	close(events)
+	rec.Record(recorded, events)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	frames, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("got %d frames, want 2", len(frames))
+	}
+	if frames[0].Type != "discovered" || frames[0].OffsetMS != 0 || len(frames[0].Session.Commands) != 1 {
+		t.Errorf("first frame = %+v, want discovered snapshot at offset 0", frames[0])
+	}
+	if frames[1].Type != "new_commands" || len(frames[1].Session.Commands) != 0 {
+		t.Errorf("second frame = %+v, want header-only new_commands", frames[1])
+	}
+
+	w, err := session.NewReplicaWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Force a non-zero offset so the second frame is played asynchronously
+	frames[1].OffsetMS = 1
+	done := Play(frames, w, 0)
+
+	// Playback is still running: read a copy taken under the watcher's lock
+	sessions := w.Snapshot()
+	if len(sessions) != 1 || sessions[0].ID != "sess-1" {
+		t.Fatalf("initial state = %+v, want sess-1", sessions)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("playback did not finish")
+	}
+	if got := len(w.GetSessions()[0].Commands); got != 2 {
+		t.Errorf("after playback got %d commands, want 2", got)
+	}
+}
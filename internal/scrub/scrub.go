@@ -0,0 +1,279 @@
+// Package scrub rewrites session JSONL for retention policies: tool results
+// stripped, secrets masked, and thinking blocks dropped, while the tool
+// calls that make up the command audit trail are kept.
+package scrub
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"cc_session_mon/internal/config"
+)
+
+// Placeholder replaces a stripped tool result's content
+const Placeholder = "[scrubbed]"
+
+// secretPatterns match credentials in any text: NAME=value assignments
+// whose name suggests one, bearer tokens, private key blocks, and the
+// token formats of common providers
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b[A-Z0-9_]*(?:TOKEN|SECRET|PASSWORD|PASSWD|API_?KEY|ACCESS_?KEY)[A-Z0-9_]*=(\S+)`),
+	regexp.MustCompile(`(?i)\bBearer\s+([A-Za-z0-9._~+/=-]{16,})`),
+	regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}\b|\bgithub_pat_[A-Za-z0-9_]{22,}`),
+	regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+}
+
+// Stats counts what a scrub changed
+type Stats struct {
+	Lines       int // Lines read
+	Changed     int // Lines rewritten
+	ToolResults int // Tool results stripped
+	Thinking    int // Thinking blocks dropped
+	Secrets     int // Secrets masked
+}
+
+// Add accumulates another file's stats
+func (s *Stats) Add(o Stats) {
+	s.Lines += o.Lines
+	s.Changed += o.Changed
+	s.ToolResults += o.ToolResults
+	s.Thinking += o.Thinking
+	s.Secrets += o.Secrets
+}
+
+// Scrubber applies a config's scrub rules
+type Scrubber struct {
+	rules    config.ScrubRules
+	patterns []*regexp.Regexp // Secret patterns, with the config's own
+}
+
+// New compiles rules' extra secret patterns
+func New(rules config.ScrubRules) (*Scrubber, error) {
+	s := &Scrubber{rules: rules, patterns: secretPatterns}
+	for _, p := range rules.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("scrub pattern %q: %w", p, err)
+		}
+		s.patterns = append(s.patterns[:len(s.patterns):len(s.patterns)], re)
+	}
+	return s, nil
+}
+
+// Line scrubs one JSONL record, returning it unchanged (the same slice) if
+// no rule applies. Lines that aren't JSON objects only have secrets masked.
+func (s *Scrubber) Line(line []byte, stats *Stats) []byte {
+	stats.Lines++
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var record map[string]any
+	if err := dec.Decode(&record); err != nil {
+		if !s.rules.MaskSecrets {
+			return line
+		}
+		masked, n := s.mask(string(line))
+		if n == 0 {
+			return line
+		}
+		stats.Secrets += n
+		stats.Changed++
+		return []byte(masked)
+	}
+
+	var changed Stats
+	if msg, ok := record["message"].(map[string]any); ok {
+		if content, ok := msg["content"].([]any); ok {
+			msg["content"] = s.content(content, &changed)
+		}
+	}
+	// The structured copy of a result (stdout, file contents) goes with it
+	_, structured := record["toolUseResult"]
+	structured = structured && s.rules.StripToolResults
+	if structured {
+		delete(record, "toolUseResult")
+	}
+	if s.rules.MaskSecrets {
+		s.maskValue(record, &changed)
+	}
+	if changed == (Stats{}) && !structured {
+		return line
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(record); err != nil {
+		return line
+	}
+	stats.Changed++
+	stats.ToolResults += changed.ToolResults
+	stats.Thinking += changed.Thinking
+	stats.Secrets += changed.Secrets
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// content drops thinking blocks from a message's content and strips its
+// tool results, keeping their IDs and error flags
+func (s *Scrubber) content(blocks []any, stats *Stats) []any {
+	kept := blocks[:0]
+	for _, b := range blocks {
+		block, ok := b.(map[string]any)
+		if !ok {
+			kept = append(kept, b)
+			continue
+		}
+		switch block["type"] {
+		case "thinking", "redacted_thinking":
+			if s.rules.DropThinking {
+				stats.Thinking++
+				continue
+			}
+		case "tool_result":
+			if s.rules.StripToolResults && block["content"] != Placeholder {
+				block["content"] = Placeholder
+				stats.ToolResults++
+			}
+		}
+		kept = append(kept, block)
+	}
+	return kept
+}
+
+// maskValue masks secrets in every string in a decoded JSON value
+func (s *Scrubber) maskValue(v any, stats *Stats) any {
+	switch v := v.(type) {
+	case string:
+		masked, n := s.mask(v)
+		stats.Secrets += n
+		return masked
+	case map[string]any:
+		for k, item := range v {
+			v[k] = s.maskValue(item, stats)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = s.maskValue(item, stats)
+		}
+	}
+	return v
+}
+
+// mask replaces secrets in text with ***, keeping an assignment's name or a
+// bearer token's scheme, and returns how many it masked
+func (s *Scrubber) mask(text string) (string, int) {
+	n := 0
+	for _, re := range s.patterns {
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			sub := re.FindStringSubmatchIndex(match)
+			if len(sub) >= 4 && sub[2] >= 0 {
+				if match[sub[2]:sub[3]] == "***" {
+					return match
+				}
+				n++
+				return match[:sub[2]] + "***" + match[sub[3]:]
+			}
+			n++
+			return "***"
+		})
+	}
+	return text, n
+}
+
+// Copy scrubs JSONL from r to w line by line
+func (s *Scrubber) Copy(w io.Writer, r io.Reader) (Stats, error) {
+	var stats Stats
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			body := bytes.TrimSuffix(line, []byte("\n"))
+			if _, werr := bw.Write(s.Line(body, &stats)); werr != nil {
+				return stats, werr
+			}
+			if len(body) < len(line) {
+				_ = bw.WriteByte('\n')
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return stats, bw.Flush()
+		}
+		if err != nil {
+			return stats, err
+		}
+	}
+}
+
+// File scrubs the session file src into dst, which may be src itself: the
+// result is written next to dst and renamed over it, so a failure leaves
+// dst as it was. With dryRun nothing is written.
+func (s *Scrubber) File(src, dst string, dryRun bool) (Stats, error) {
+	in, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return Stats{}, err
+	}
+	defer in.Close()
+	if dryRun {
+		return s.Copy(io.Discard, in)
+	}
+
+	info, err := in.Stat()
+	if err != nil {
+		return Stats{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return Stats{}, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".scrub-*")
+	if err != nil {
+		return Stats{}, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	stats, err := s.Copy(tmp, in)
+	if err == nil {
+		err = tmp.Chmod(info.Mode().Perm())
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return stats, err
+	}
+	return stats, os.Rename(tmp.Name(), dst)
+}
+
+// Files returns the session files to scrub for a path: every .jsonl file
+// under a directory, or a session file and its subagent transcripts. base
+// is the directory their paths are relative to when copied elsewhere.
+func Files(path string) (base string, files []string, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if !info.IsDir() {
+		subagents, _ := filepath.Glob(filepath.Join(strings.TrimSuffix(path, ".jsonl"), "subagents", "*.jsonl"))
+		return filepath.Dir(path), append([]string{path}, subagents...), nil
+	}
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(p, ".jsonl") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return path, files, err
+}
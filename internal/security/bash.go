@@ -0,0 +1,100 @@
+// Package security holds the heuristics behind the detail panel's security
+// warnings: shell command checks, interpreter code checks, and sensitive paths.
+package security
+
+import "strings"
+
+// securityCheck defines a check function and its warning message
+type securityCheck struct {
+	check   func(cmd string) bool
+	warning string
+}
+
+// securityChecks contains all bash security checks
+var securityChecks = []securityCheck{
+	{checkRecursiveRm, "Recursive file deletion"},
+	{checkSimpleRm, "File deletion"},
+	{checkSudo, "Runs with elevated privileges"},
+	{checkChmod, "Changes file permissions"},
+	{checkChown, "Changes file ownership"},
+	{checkCurlPipeShell, "Downloads and pipes to shell"},
+	{checkDd, "Direct disk/device operation"},
+	{checkMkfs, "Filesystem creation"},
+	{checkKill, "Process termination"},
+	{checkGitForcePush, "Force push to remote"},
+	{checkGitHardReset, "Hard reset (discards changes)"},
+}
+
+// AnalyzeBash returns security warnings for a bash command
+func AnalyzeBash(command string) []string {
+	var warnings []string
+	cmd := strings.ToLower(command)
+
+	for _, sc := range securityChecks {
+		if sc.check(cmd) {
+			warnings = append(warnings, sc.warning)
+		}
+	}
+	return warnings
+}
+
+// hasCommand checks if cmd contains "name " or starts with "name\t"
+func hasCommand(cmd, name string) bool {
+	return strings.Contains(cmd, name+" ") || strings.HasPrefix(cmd, name+"\t")
+}
+
+func checkRecursiveRm(cmd string) bool {
+	if !hasCommand(cmd, "rm") && !strings.HasPrefix(cmd, "rm\n") {
+		return false
+	}
+	return strings.Contains(cmd, "-rf") || strings.Contains(cmd, "-r ") || strings.Contains(cmd, " -fr")
+}
+
+func checkSimpleRm(cmd string) bool {
+	if !hasCommand(cmd, "rm") && !strings.HasPrefix(cmd, "rm\n") {
+		return false
+	}
+	// Only flag if not already caught by recursive check
+	return !checkRecursiveRm(cmd)
+}
+
+func checkSudo(cmd string) bool {
+	return strings.Contains(cmd, "sudo ") || strings.HasPrefix(cmd, "sudo\t")
+}
+
+func checkChmod(cmd string) bool {
+	return strings.Contains(cmd, "chmod ")
+}
+
+func checkChown(cmd string) bool {
+	return strings.Contains(cmd, "chown ")
+}
+
+func checkCurlPipeShell(cmd string) bool {
+	if !strings.Contains(cmd, "|") {
+		return false
+	}
+	hasCurl := strings.Contains(cmd, "curl") || strings.Contains(cmd, "wget")
+	hasShell := strings.Contains(cmd, "bash") || strings.Contains(cmd, "sh")
+	return hasCurl && hasShell
+}
+
+func checkDd(cmd string) bool {
+	return hasCommand(cmd, "dd")
+}
+
+func checkMkfs(cmd string) bool {
+	return strings.Contains(cmd, "mkfs")
+}
+
+func checkKill(cmd string) bool {
+	return strings.Contains(cmd, "kill ") || strings.Contains(cmd, "pkill ") || strings.Contains(cmd, "killall ")
+}
+
+func checkGitForcePush(cmd string) bool {
+	return strings.Contains(cmd, "git push") && strings.Contains(cmd, "--force")
+}
+
+func checkGitHardReset(cmd string) bool {
+	return strings.Contains(cmd, "git reset --hard")
+}
@@ -0,0 +1,192 @@
+package security
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+// MaxBlastRadius is the highest blast radius score
+const MaxBlastRadius = 100
+
+// Blast radius factor names
+const (
+	FactorOutsideWrites = "writes outside the project"
+	FactorDangerous     = "dangerous commands"
+	FactorNetwork       = "network destinations"
+	FactorInstalls      = "package installs"
+)
+
+// blastLimits are the most points each factor can add, so that no single
+// kind of activity decides the score on its own
+var blastLimits = map[string]int{
+	FactorOutsideWrites: 40,
+	FactorDangerous:     40,
+	FactorNetwork:       20,
+	FactorInstalls:      25,
+}
+
+// Points per write outside the project, network destination, and install
+const (
+	outsideWritePoints = 8
+	networkHostPoints  = 3
+	installPoints      = 5
+)
+
+// dangerPoints are the points of a dangerous command by its worst severity
+var dangerPoints = map[Severity]int{SeverityHigh: 10, SeverityMedium: 4, SeverityLow: 1}
+
+// packageInstalls are the command prefixes that install packages, whose
+// code runs on the machine (install scripts, or later imports)
+var packageInstalls = [][]string{
+	{"pip", "install"}, {"pip3", "install"}, {"python", "-m", "pip", "install"},
+	{"python3", "-m", "pip", "install"}, {"uv", "pip", "install"}, {"uv", "add"},
+	{"npm", "install"}, {"npm", "i"}, {"npm", "ci"}, {"yarn", "add"}, {"yarn", "install"},
+	{"pnpm", "add"}, {"pnpm", "install"}, {"cargo", "install"}, {"go", "install"},
+	{"gem", "install"}, {"brew", "install"}, {"apt", "install"}, {"apt-get", "install"},
+	{"dnf", "install"}, {"yum", "install"}, {"pacman", "-S"}, {"nix-env", "-i"},
+}
+
+// BlastFactor is one kind of activity adding to a session's blast radius
+type BlastFactor struct {
+	Name     string
+	Count    int      // Occurrences: writes, commands, hosts, or installs
+	Points   int      // Points added to the score, after the factor's limit
+	Examples []string // A few of the paths, rules, hosts, or packages, for the explanation
+}
+
+// String describes the factor, e.g. "writes outside the project: 3 (~/.bashrc, ...) +24"
+func (f BlastFactor) String() string {
+	s := fmt.Sprintf("%s: %d", f.Name, f.Count)
+	if len(f.Examples) > 0 {
+		s += " (" + strings.Join(f.Examples, ", ") + ")"
+	}
+	return s + fmt.Sprintf(" +%d", f.Points)
+}
+
+// BlastRadius estimates how much a session could have affected beyond its
+// project, to decide which sessions need review first
+type BlastRadius struct {
+	Score   int           // 0 to MaxBlastRadius
+	Factors []BlastFactor // The factors that added points, most first
+}
+
+// blastExamples is how many examples a factor keeps
+const blastExamples = 3
+
+// SessionBlastRadius scores a session by its writes outside the project,
+// dangerous commands (weighted by severity), distinct network destinations
+// other than package registries, and package installs, each capped.
+func SessionBlastRadius(sess *session.Session) BlastRadius {
+	cfg := config.ForProject(sess.ProjectPath)
+	counts := make(map[string]int)
+	points := make(map[string]int)
+	examples := make(map[string][]string)
+	seen := make(map[string]bool)
+	note := func(factor, example string) {
+		if key := factor + "\x00" + example; !seen[key] {
+			seen[key] = true
+			if len(examples[factor]) < blastExamples {
+				examples[factor] = append(examples[factor], example)
+			}
+		}
+	}
+
+	hosts := make(map[string]bool)
+	for i := range sess.Commands {
+		c := &sess.Commands[i]
+		for _, path := range OutsideProjectWrites(c.ToolName, c.RawCommand, c.CWD, sess.ProjectPath, cfg.Security.AllowedWritePaths) {
+			counts[FactorOutsideWrites]++
+			points[FactorOutsideWrites] += outsideWritePoints
+			note(FactorOutsideWrites, path)
+		}
+		if c.ToolName != "Bash" {
+			continue
+		}
+
+		if rule, sev, ok := worstDanger(c, cfg); ok {
+			counts[FactorDangerous]++
+			points[FactorDangerous] += dangerPoints[sev]
+			note(FactorDangerous, rule)
+		}
+		installs := 0
+		script, _ := session.SplitHeredocs(c.RawCommand)
+		for _, words := range session.SimpleCommands(script) {
+			if prefix := installPrefix(words); prefix != "" {
+				installs++
+				note(FactorInstalls, prefix)
+			}
+		}
+		counts[FactorInstalls] += installs
+		points[FactorInstalls] += installs * installPoints
+		if installs > 0 {
+			continue // Registry traffic is counted as the install
+		}
+		for _, access := range AnalyzeNetwork(c.RawCommand) {
+			host := access.Host
+			if host == "" {
+				host = access.Command + " (unknown host)"
+			}
+			if !hosts[host] {
+				hosts[host] = true
+				counts[FactorNetwork]++
+				points[FactorNetwork] += networkHostPoints
+				note(FactorNetwork, host)
+			}
+		}
+	}
+
+	var radius BlastRadius
+	for name, limit := range blastLimits {
+		if counts[name] == 0 {
+			continue
+		}
+		factor := BlastFactor{Name: name, Count: counts[name], Points: min(points[name], limit), Examples: examples[name]}
+		radius.Factors = append(radius.Factors, factor)
+		radius.Score += factor.Points
+	}
+	radius.Score = min(radius.Score, MaxBlastRadius)
+	sort.Slice(radius.Factors, func(i, j int) bool {
+		a, b := radius.Factors[i], radius.Factors[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		return a.Name < b.Name
+	})
+	return radius
+}
+
+// worstDanger returns the most severe built-in shell warning or configured
+// warn pattern a Bash command triggers
+func worstDanger(c *session.CommandEntry, cfg *config.Config) (rule string, sev Severity, ok bool) {
+	consider := func(r string, s Severity) {
+		if !ok || s > sev {
+			rule, sev, ok = r, s, true
+		}
+	}
+	for _, w := range AnalyzeBash(c.RawCommand) {
+		s, known := bashSeverities[w]
+		if !known {
+			s = SeverityMedium
+		}
+		consider(w, s)
+	}
+	for _, w := range cfg.SecurityWarnings(c.Pattern) {
+		consider(w, SeverityMedium)
+	}
+	return rule, sev, ok
+}
+
+// installPrefix returns the install command a simple command starts with
+// (e.g. "npm install"), or "" when it installs nothing
+func installPrefix(words []string) string {
+	for _, prefix := range packageInstalls {
+		if session.HasWordPrefix(words, prefix) {
+			return strings.Join(prefix, " ")
+		}
+	}
+	return ""
+}
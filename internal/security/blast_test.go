@@ -0,0 +1,66 @@
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestSessionBlastRadius(t *testing.T) {
+	sess := &session.Session{
+		ProjectPath: "/work/app",
+		Commands: []session.CommandEntry{
+			{ToolName: "Edit", RawCommand: "/work/app/main.go"},
+			{ToolName: "Write", RawCommand: "/home/dev/.bashrc"},
+			{ToolName: "Bash", RawCommand: "echo export X=1 >> ~/.profile", CWD: "/work/app"},
+			{ToolName: "Bash", RawCommand: "rm -rf build"},
+			{ToolName: "Bash", RawCommand: "kill 1234"},
+			{ToolName: "Bash", RawCommand: "npm install left-pad && sudo apt-get install jq"},
+			{ToolName: "Bash", RawCommand: "curl https://example.com/a"},
+			{ToolName: "Bash", RawCommand: "curl https://example.com/b"},
+			{ToolName: "Bash", RawCommand: "go test ./..."},
+		},
+	}
+
+	radius := SessionBlastRadius(sess)
+	want := map[string]struct{ count, points int }{
+		FactorOutsideWrites: {2, 16},
+		FactorDangerous:     {3, 21}, // rm -rf and sudo high, kill low
+		FactorInstalls:      {2, 10},
+		FactorNetwork:       {1, 3}, // Both curls reach example.com
+	}
+	if radius.Score != 50 {
+		t.Errorf("score = %d, want 50", radius.Score)
+	}
+	if len(radius.Factors) != len(want) {
+		t.Fatalf("factors = %+v, want %d", radius.Factors, len(want))
+	}
+	for _, f := range radius.Factors {
+		if w := want[f.Name]; f.Count != w.count || f.Points != w.points {
+			t.Errorf("%s: count %d, points %d; want %d, %d", f.Name, f.Count, f.Points, w.count, w.points)
+		}
+	}
+	if radius.Factors[0].Name != FactorDangerous {
+		t.Errorf("first factor = %s, want the one adding the most points", radius.Factors[0].Name)
+	}
+	if s := radius.Factors[1].String(); !strings.HasPrefix(s, "writes outside the project: 2 (/home/dev/.bashrc, ") || !strings.HasSuffix(s, " +16") {
+		t.Errorf("explanation = %q", s)
+	}
+}
+
+func TestSessionBlastRadiusLimits(t *testing.T) {
+	sess := &session.Session{ProjectPath: "/work/app"}
+	for range 20 {
+		sess.Commands = append(sess.Commands,
+			session.CommandEntry{ToolName: "Write", RawCommand: "/etc/hosts"},
+			session.CommandEntry{ToolName: "Bash", RawCommand: "sudo rm -rf /var/lib/thing"})
+	}
+	radius := SessionBlastRadius(sess)
+	if radius.Score != 80 {
+		t.Errorf("score = %d, want 80 (two factors at their limit of 40)", radius.Score)
+	}
+	if quiet := SessionBlastRadius(&session.Session{ProjectPath: "/work/app"}); quiet.Score != 0 || len(quiet.Factors) != 0 {
+		t.Errorf("empty session radius = %+v, want zero", quiet)
+	}
+}
@@ -0,0 +1,54 @@
+package security
+
+import (
+	"strings"
+
+	"cc_session_mon/internal/session"
+)
+
+// codeChecks maps code fragments (lowercase) to warnings for interpreter code
+var codeChecks = []struct {
+	fragments []string
+	warning   string
+}{
+	{[]string{"os.system", "subprocess", "child_process", "popen", "execsync", "spawnsync"}, "Code runs shell commands"},
+	{[]string{"shutil.rmtree", "rmsync", "fs.rm(", "fs.promises.rm", "rm_rf", "rm_r(", "rimraf", "remove_tree", "rmtree"}, "Code deletes directory trees"},
+	{[]string{"os.remove", "os.unlink", "fs.unlink", "file.delete", "fileutils.rm", "unlink("}, "Code deletes files"},
+	{[]string{"eval(", "exec("}, "Code evaluates dynamic code"},
+	{[]string{"b64decode", "base64.decode", "atob(", "buffer.from("}, "Code decodes embedded data"},
+	{[]string{"urllib", "requests.", "http.client", "socket.", "fetch(", "net/http", "net::http", "open-uri"}, "Code makes network connections"},
+	{[]string{"os.environ", "process.env", "env["}, "Code reads environment variables"},
+	{[]string{"chmod", "chown", "setuid"}, "Code changes permissions"},
+}
+
+// AnalyzeCode returns security warnings for interpreter code (inline or a script).
+// It does not include AnalyzeBash checks for shell commands embedded in the code.
+func AnalyzeCode(code string) []string {
+	lower := strings.ToLower(code)
+	var warnings []string
+	for _, c := range codeChecks {
+		for _, f := range c.fragments {
+			if strings.Contains(lower, f) {
+				warnings = append(warnings, c.warning)
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// InlineCode returns the code a Bash command gives an interpreter: its -c/-e
+// argument, or the heredoc it reads as a script from stdin ("python3 - <<EOF")
+func InlineCode(command string) string {
+	interp, ok := session.ParseInterpreter(command)
+	if !ok {
+		return ""
+	}
+	if interp.Code != "" {
+		return interp.Code
+	}
+	if _, heredocs := session.SplitHeredocs(command); interp.Script == "-" && len(heredocs) > 0 {
+		return heredocs[0].Body
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package security
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{"harmless", "print(1 + 1)", nil},
+		{"shell out", "import subprocess; subprocess.run(['ls'])", []string{"Code runs shell commands"}},
+		{"delete tree", "import shutil; shutil.rmtree('/tmp/x')", []string{"Code deletes directory trees"}},
+		{"delete file", "import os; os.remove('/tmp/x')", []string{"Code deletes files"}},
+		{
+			"decode and eval",
+			"import base64; exec(base64.b64decode('cHJpbnQoMSk='))",
+			[]string{"Code evaluates dynamic code", "Code decodes embedded data"},
+		},
+		{"node network", "fetch('https://example.com').then(r => r.text())", []string{"Code makes network connections"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AnalyzeCode(tt.code); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AnalyzeCode(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInlineCode(t *testing.T) {
+	tests := []struct {
+		command, want string
+	}{
+		{"python3 - <<EOF\nimport os\nEOF", "import os"},
+		{`python3 -c "print(1)"`, "print(1)"},
+		{"python3 build.py", ""},
+		{"cat <<EOF\nimport os\nEOF", ""},
+	}
+	for _, tt := range tests {
+		if got := InlineCode(tt.command); got != tt.want {
+			t.Errorf("InlineCode(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// CWDDrift describes how far cwd has moved from the project root, or returns
+// "" while the agent is still working inside the project. The filesystem
+// root and the home directory are called out, since commands run there can
+// touch far more than the project.
+func CWDDrift(projectPath, cwd string) string {
+	if projectPath == "" || cwd == "" {
+		return ""
+	}
+	cwd = filepath.Clean(cwd)
+	if within(cwd, projectPath) {
+		return ""
+	}
+
+	home, _ := os.UserHomeDir()
+	switch {
+	case filepath.Dir(cwd) == cwd && filepath.IsAbs(cwd): // "/" or a drive root like C:\
+		return "Working in the filesystem root"
+	case home != "" && cwd == filepath.Clean(home):
+		return "Working in the home directory"
+	case within(projectPath, cwd):
+		return "Working in a parent of the project: " + cwd
+	default:
+		return "Working outside the project: " + cwd
+	}
+}
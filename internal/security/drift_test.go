@@ -0,0 +1,26 @@
+package security
+
+import "testing"
+
+func TestCWDDrift(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+
+	tests := []struct {
+		cwd  string
+		want string
+	}{
+		{"/home/dev/app", ""},
+		{"/home/dev/app/internal/", ""},
+		{"", ""},
+		{"/", "Working in the filesystem root"},
+		{"/home/dev", "Working in the home directory"},
+		{"/home", "Working in a parent of the project: /home"},
+		{"/home/dev/app2", "Working outside the project: /home/dev/app2"},
+	}
+
+	for _, tt := range tests {
+		if got := CWDDrift("/home/dev/app", tt.cwd); got != tt.want {
+			t.Errorf("CWDDrift(%q) = %q, want %q", tt.cwd, got, tt.want)
+		}
+	}
+}
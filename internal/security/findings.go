@@ -0,0 +1,237 @@
+package security
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+// Severity ranks how urgently a finding deserves review
+type Severity int
+
+const (
+	SeverityLow    Severity = iota // Worth knowing (network access)
+	SeverityMedium                 // Review when auditing (permission changes, configured rules)
+	SeverityHigh                   // Review now (destructive, privileged, secrets, sandbox escapes)
+)
+
+// String returns the severity's display name
+func (s Severity) String() string {
+	switch s {
+	case SeverityHigh:
+		return "high"
+	case SeverityMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// MarshalText encodes the severity by name (in JSON alert payloads)
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity parses a severity name as returned by String
+func ParseSeverity(name string) (Severity, error) {
+	switch name {
+	case "high":
+		return SeverityHigh, nil
+	case "medium":
+		return SeverityMedium, nil
+	case "low":
+		return SeverityLow, nil
+	}
+	return SeverityLow, fmt.Errorf("unknown severity %q (want low, medium, or high)", name)
+}
+
+// bashSeverities assigns a severity to each AnalyzeBash warning; unlisted ones are medium
+var bashSeverities = map[string]Severity{
+	"Recursive file deletion":       SeverityHigh,
+	"Runs with elevated privileges": SeverityHigh,
+	"Downloads and pipes to shell":  SeverityHigh,
+	"Direct disk/device operation":  SeverityHigh,
+	"Filesystem creation":           SeverityHigh,
+	"Force push to remote":          SeverityHigh,
+	"File deletion":                 SeverityLow,
+	"Process termination":           SeverityLow,
+}
+
+// codeSeverities assigns a severity to each AnalyzeCode warning; unlisted ones are medium
+var codeSeverities = map[string]Severity{
+	"Code deletes directory trees":     SeverityHigh,
+	"Code makes network connections":   SeverityLow,
+	"Code reads environment variables": SeverityLow,
+}
+
+// warningSeverity returns the severity of an AnalyzeBash or AnalyzeCode warning
+func warningSeverity(warning string) Severity {
+	if sev, ok := bashSeverities[warning]; ok {
+		return sev
+	}
+	if sev, ok := codeSeverities[warning]; ok {
+		return sev
+	}
+	return SeverityMedium
+}
+
+// Rule names for findings that don't come from AnalyzeBash or configured rules
+const (
+	RuleOutsideWrite   = "Writes outside the project"
+	RuleSecret         = "Exposes secrets"
+	RuleSensitiveRead  = "Reads sensitive path"
+	RuleSensitiveWrite = "Writes sensitive path"
+	RuleNetwork        = "Network access"
+	RuleCWDDrift       = "Works outside the project"
+	RuleConfigTamper   = "Configuration tampering"
+)
+
+// Finding is one security rule a command triggers
+type Finding struct {
+	Rule     string
+	Severity Severity
+}
+
+// CommandFindings returns the findings for a command, using only what the
+// command list knows (the raw command or path, not the loaded tool input):
+// built-in shell checks and interpreter code checks on inline code, cfg's warn patterns and sensitive paths, writes
+// outside projectPath, secret exposure, network access, cwd drift, and
+// changes to the agent's own configuration. history is the command's
+// session, for the scripts a Bash command runs that the session wrote
+// earlier; their content's findings are the command's own.
+func CommandFindings(c *session.CommandEntry, projectPath string, cfg *config.Config, history []session.CommandEntry) []Finding {
+	var findings []Finding
+	add := func(rule string, sev Severity) {
+		for _, f := range findings {
+			if f.Rule == rule {
+				return
+			}
+		}
+		findings = append(findings, Finding{rule, sev})
+	}
+
+	if c.ToolName == "Bash" {
+		for _, w := range AnalyzeBash(c.RawCommand) {
+			add(w, warningSeverity(w))
+		}
+		if len(AnalyzeNetwork(c.RawCommand)) > 0 {
+			add(RuleNetwork, SeverityLow)
+		}
+		for _, w := range AnalyzeCode(InlineCode(c.RawCommand)) {
+			add(w, warningSeverity(w))
+		}
+		for _, run := range WrittenScriptRuns(c.RawCommand, c.CWD, c.Timestamp, history) {
+			for _, w := range run.Warnings {
+				add(w, warningSeverity(w))
+			}
+		}
+	}
+	for _, w := range cfg.SecurityWarnings(c.Pattern) {
+		add(w, SeverityMedium)
+	}
+
+	switch c.ToolName {
+	case "Read":
+		if IsSensitivePath(c.RawCommand, cfg.Security.SensitivePaths) {
+			add(RuleSensitiveRead, SeverityMedium)
+		}
+	case "Write", "Edit", "NotebookEdit":
+		if IsSensitivePath(c.RawCommand, cfg.Security.SensitivePaths) {
+			add(RuleSensitiveWrite, SeverityHigh)
+		}
+	}
+	if len(OutsideProjectWrites(c.ToolName, c.RawCommand, c.CWD, projectPath, cfg.Security.AllowedWritePaths)) > 0 {
+		add(RuleOutsideWrite, SeverityHigh)
+	}
+	if len(SecretExposures(c.ToolName, c.RawCommand)) > 0 {
+		add(RuleSecret, SeverityHigh)
+	}
+	if len(ConfigTampering(c.ToolName, c.RawCommand)) > 0 {
+		add(RuleConfigTamper, SeverityHigh)
+	}
+	if CWDDrift(projectPath, c.CWD) != "" {
+		add(RuleCWDDrift, SeverityMedium)
+	}
+	return findings
+}
+
+// FindingRef points at a command that triggered a finding
+type FindingRef struct {
+	Session *session.Session
+	Command session.CommandEntry
+}
+
+// FindingSummary aggregates one rule's findings across sessions
+type FindingSummary struct {
+	Rule     string
+	Severity Severity
+	Count    int          // Commands triggering the rule
+	Sessions int          // Distinct sessions affected
+	LastSeen time.Time    // Most recent occurrence
+	Commands []FindingRef // Offending commands, newest first
+}
+
+// AggregateFindings groups the findings of every command in sessions by
+// rule, using each session's project config. Breaches of alert thresholds
+// are included under the threshold's name, with the commands they counted.
+// Results are sorted by severity, then most recent occurrence.
+func AggregateFindings(sessions []*session.Session) []*FindingSummary {
+	byRule := make(map[string]*FindingSummary)
+	sessionsByRule := make(map[string]map[string]bool)
+	add := func(sess *session.Session, c *session.CommandEntry, f Finding) {
+		s, ok := byRule[f.Rule]
+		if !ok {
+			s = &FindingSummary{Rule: f.Rule, Severity: f.Severity}
+			byRule[f.Rule] = s
+			sessionsByRule[f.Rule] = make(map[string]bool)
+		}
+		s.Count++
+		if c.Timestamp.After(s.LastSeen) {
+			s.LastSeen = c.Timestamp
+		}
+		if !sessionsByRule[f.Rule][sess.FilePath] {
+			sessionsByRule[f.Rule][sess.FilePath] = true
+			s.Sessions++
+		}
+		s.Commands = append(s.Commands, FindingRef{Session: sess, Command: *c})
+	}
+
+	for _, sess := range sessions {
+		cfg := config.ForProject(sess.ProjectPath)
+		for i := range sess.Commands {
+			c := &sess.Commands[i]
+			for _, f := range CommandFindings(c, sess.ProjectPath, cfg, sess.Commands) {
+				add(sess, c, f)
+			}
+		}
+		for i := range cfg.Alerts.Thresholds {
+			for _, b := range ThresholdBreaches(&cfg.Alerts.Thresholds[i], sess.Commands) {
+				for j := range b.Commands {
+					add(sess, &b.Commands[j], Finding{b.Threshold, b.Severity})
+				}
+			}
+		}
+	}
+
+	summaries := make([]*FindingSummary, 0, len(byRule))
+	for _, s := range byRule {
+		sort.Slice(s.Commands, func(i, j int) bool {
+			return s.Commands[i].Command.Timestamp.After(s.Commands[j].Command.Timestamp)
+		})
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if !a.LastSeen.Equal(b.LastSeen) {
+			return a.LastSeen.After(b.LastSeen)
+		}
+		return a.Rule < b.Rule
+	})
+	return summaries
+}
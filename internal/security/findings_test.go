@@ -0,0 +1,50 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestAggregateFindings(t *testing.T) {
+	now := time.Now()
+	sessions := []*session.Session{
+		{
+			FilePath:    "/s/1.jsonl",
+			ProjectPath: "/work/alpha",
+			Commands: []session.CommandEntry{
+				{ToolName: "Bash", RawCommand: "rm -rf build", Timestamp: now.Add(-3 * time.Minute)},
+				{ToolName: "Bash", RawCommand: "curl https://example.com", Timestamp: now.Add(-2 * time.Minute)},
+				{ToolName: "Bash", RawCommand: "go test ./...", Timestamp: now},
+			},
+		},
+		{
+			FilePath:    "/s/2.jsonl",
+			ProjectPath: "/work/beta",
+			Commands: []session.CommandEntry{
+				{ToolName: "Bash", RawCommand: "rm -rf dist", Timestamp: now.Add(-time.Minute)},
+			},
+		},
+	}
+
+	got := AggregateFindings(sessions)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rules, got %d: %+v", len(got), got)
+	}
+
+	rm := got[0]
+	if rm.Rule != "Recursive file deletion" || rm.Severity != SeverityHigh {
+		t.Errorf("expected high-severity recursive deletion first, got %s (%s)", rm.Rule, rm.Severity)
+	}
+	if rm.Count != 2 || rm.Sessions != 2 || !rm.LastSeen.Equal(now.Add(-time.Minute)) {
+		t.Errorf("recursive deletion: count=%d sessions=%d last=%v", rm.Count, rm.Sessions, rm.LastSeen)
+	}
+	if rm.Commands[0].Command.RawCommand != "rm -rf dist" {
+		t.Errorf("expected newest offending command first, got %q", rm.Commands[0].Command.RawCommand)
+	}
+
+	if got[1].Rule != RuleNetwork || got[1].Severity != SeverityLow {
+		t.Errorf("expected low-severity network access second, got %s (%s)", got[1].Rule, got[1].Severity)
+	}
+}
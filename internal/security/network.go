@@ -0,0 +1,178 @@
+package security
+
+import (
+	"net/url"
+	"strings"
+
+	"cc_session_mon/internal/session"
+)
+
+// NetworkAccess is a command in a Bash command line that reaches the network
+type NetworkAccess struct {
+	Command string // What connects: "curl", "ssh", "pip install", "git clone", ...
+	Host    string // Destination host, empty when it can't be determined
+}
+
+// Default package registries for installs that don't name one
+const (
+	pypiHost = "pypi.org"
+	npmHost  = "registry.npmjs.org"
+)
+
+// networkAliases maps command variants to the name whose flags they share in
+// session.TakesArg
+var networkAliases = map[string]string{
+	"ncat": "nc", "netcat": "nc", "sftp": "scp",
+}
+
+// AnalyzeNetwork returns the commands in a Bash command line that reach the
+// network (curl, wget, nc, ssh, scp, package installs, git clone), with the
+// destination host when it can be extracted. Heredoc bodies are ignored.
+func AnalyzeNetwork(command string) []NetworkAccess {
+	script, _ := session.SplitHeredocs(command)
+	var accesses []NetworkAccess
+	for _, words := range session.SimpleCommands(script) {
+		if access, ok := networkAccess(words); ok {
+			accesses = append(accesses, access)
+		}
+	}
+	return accesses
+}
+
+// pypiFlags and npmFlags name the package index for pip and npm-style installs
+var (
+	pypiFlags = []string{"-i", "--index-url"}
+	npmFlags  = []string{"--registry"}
+)
+
+// networkSubcommands lists commands that reach the network only with certain
+// subcommands, with the registry used when none is named (empty for git,
+// whose destination is the URL argument)
+var networkSubcommands = []struct {
+	prefix   []string
+	registry string
+	flags    []string
+}{
+	{[]string{"pip", "install"}, pypiHost, pypiFlags},
+	{[]string{"pip", "download"}, pypiHost, pypiFlags},
+	{[]string{"pip3", "install"}, pypiHost, pypiFlags},
+	{[]string{"python", "-m", "pip", "install"}, pypiHost, pypiFlags},
+	{[]string{"python3", "-m", "pip", "install"}, pypiHost, pypiFlags},
+	{[]string{"uv", "pip", "install"}, pypiHost, pypiFlags},
+	{[]string{"npm", "install"}, npmHost, npmFlags},
+	{[]string{"npm", "i"}, npmHost, npmFlags},
+	{[]string{"npm", "ci"}, npmHost, npmFlags},
+	{[]string{"yarn", "add"}, npmHost, npmFlags},
+	{[]string{"yarn", "install"}, npmHost, npmFlags},
+	{[]string{"pnpm", "add"}, npmHost, npmFlags},
+	{[]string{"pnpm", "install"}, npmHost, npmFlags},
+	{[]string{"git", "clone"}, "", nil},
+	{[]string{"git", "remote", "add"}, "", nil},
+}
+
+// networkAccess reports whether a simple command reaches the network
+func networkAccess(words []string) (NetworkAccess, bool) {
+	cmd, args := words[0], words[1:]
+	if alias, ok := networkAliases[cmd]; ok {
+		return NetworkAccess{Command: cmd, Host: destination(alias, args)}, true
+	}
+
+	switch cmd {
+	case "curl", "wget", "nc", "ssh":
+		return NetworkAccess{Command: cmd, Host: destination(cmd, args)}, true
+	case "scp", "rsync":
+		return NetworkAccess{Command: cmd, Host: firstURLHost(args)}, true
+	}
+
+	for _, sub := range networkSubcommands {
+		if !session.HasWordPrefix(words, sub.prefix) {
+			continue
+		}
+		rest := words[len(sub.prefix):]
+		access := NetworkAccess{Command: strings.Join(sub.prefix, " "), Host: firstURLHost(rest)}
+		if sub.registry != "" {
+			access.Host = registryHost(rest, sub.registry, sub.flags...)
+		}
+		return access, true
+	}
+	return NetworkAccess{}, false
+}
+
+// destination returns the host a curl/wget/nc/ssh invocation connects to:
+// the first URL among the arguments that aren't flags or flag values,
+// otherwise the first such argument
+func destination(cmd string, args []string) string {
+	var operands []string
+	for i := 0; i < len(args); i++ {
+		if strings.HasPrefix(args[i], "-") {
+			if session.TakesArg(cmd, args[i]) {
+				i++
+			}
+			continue
+		}
+		operands = append(operands, args[i])
+	}
+	if host := firstURLHost(operands); host != "" {
+		return host
+	}
+	if len(operands) == 0 {
+		return ""
+	}
+	host, _, _ := strings.Cut(operands[0], "/")
+	if _, h, ok := strings.Cut(host, "@"); ok {
+		return h
+	}
+	return host
+}
+
+// registryHost returns the host of the package index named by one of flags
+// (--registry URL or --registry=URL), a URL given as a package, or def
+func registryHost(args []string, def string, flags ...string) string {
+	for i, a := range args {
+		for _, f := range flags {
+			if a == f && i+1 < len(args) {
+				return urlHost(args[i+1])
+			}
+			if value, ok := strings.CutPrefix(a, f+"="); ok {
+				return urlHost(value)
+			}
+		}
+	}
+	if host := firstURLHost(args); host != "" {
+		return host
+	}
+	return def
+}
+
+// firstURLHost returns the host of the first argument that is a URL or an
+// scp-like remote (git@github.com:owner/repo, user@host:path), or ""
+func firstURLHost(args []string) string {
+	for _, a := range args {
+		if strings.HasPrefix(a, "-") {
+			continue
+		}
+		if host := urlHost(a); host != "" {
+			return host
+		}
+	}
+	return ""
+}
+
+// urlHost extracts the host from a URL ("https://host/...", "git+ssh://...")
+// or an scp-like remote ("user@host:path", "host:path"). Local paths give "".
+func urlHost(s string) string {
+	if strings.Contains(s, "://") {
+		if u, err := url.Parse(s); err == nil {
+			return u.Hostname()
+		}
+		return ""
+	}
+	remote, _, ok := strings.Cut(s, ":")
+	if !ok || remote == "" || strings.Contains(remote, "/") {
+		return ""
+	}
+	if _, host, ok := strings.Cut(remote, "@"); ok {
+		return host
+	}
+	return remote
+}
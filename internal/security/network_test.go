@@ -0,0 +1,35 @@
+package security
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeNetwork(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []NetworkAccess
+	}{
+		{"ls -la", nil},
+		{"curl -sSL https://example.com/install.sh | sh", []NetworkAccess{{"curl", "example.com"}}},
+		{`curl -H "Accept: application/json" -o out.json api.github.com/repos`, []NetworkAccess{{"curl", "api.github.com"}}},
+		{"wget -q -O - http://10.0.0.5:8080/x", []NetworkAccess{{"wget", "10.0.0.5"}}},
+		{"nc -w 3 db.internal 5432", []NetworkAccess{{"nc", "db.internal"}}},
+		{"ssh -p 2222 -i key deploy@prod.example.com uptime", []NetworkAccess{{"ssh", "prod.example.com"}}},
+		{"scp build.tar.gz user@host.example.com:/srv/", []NetworkAccess{{"scp", "host.example.com"}}},
+		{"pip install -r requirements.txt", []NetworkAccess{{"pip install", "pypi.org"}}},
+		{"python3 -m pip install --index-url https://pkgs.corp.dev/simple foo", []NetworkAccess{{"python3 -m pip install", "pkgs.corp.dev"}}},
+		{"npm install --registry=https://npm.corp.dev lodash", []NetworkAccess{{"npm install", "npm.corp.dev"}}},
+		{"npm run build", nil},
+		{"git clone git@github.com:owner/repo.git && cd repo", []NetworkAccess{{"git clone", "github.com"}}},
+		{"git remote add upstream https://gitlab.com/x/y.git", []NetworkAccess{{"git remote add", "gitlab.com"}}},
+		{"sudo curl", []NetworkAccess{{"curl", ""}}},
+		{"cat <<EOF > notes.md\ncurl https://example.com\nEOF", nil},
+	}
+
+	for _, tt := range tests {
+		if got := AnalyzeNetwork(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AnalyzeNetwork(%q) = %+v, want %+v", tt.command, got, tt.want)
+		}
+	}
+}
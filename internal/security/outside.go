@@ -0,0 +1,97 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+// allowedWriteDirs are locations outside the project that writes may target
+// without a warning (temp directories and devices like /dev/null)
+var allowedWriteDirs = []string{"/tmp", "/private/tmp", "/var/folders", "/dev"}
+
+// OutsideProjectWrites returns the paths a tool call writes that fall
+// outside projectPath and the allowed locations (built-ins plus extra).
+// For Write, Edit, and NotebookEdit raw is the file path; for Bash it is the
+// command, whose redirect and tee targets are checked. Relative paths are
+// resolved against cwd, or projectPath when cwd is empty. Targets using shell
+// variables other than $HOME can't be resolved and are skipped.
+func OutsideProjectWrites(toolName, raw, cwd, projectPath string, extra []string) []string {
+	if projectPath == "" {
+		return nil
+	}
+
+	var targets []string
+	switch toolName {
+	case "Write", "Edit", "NotebookEdit":
+		targets = []string{raw}
+	case "Bash":
+		targets = session.WriteTargets(raw)
+	default:
+		return nil
+	}
+
+	if cwd == "" {
+		cwd = projectPath
+	}
+	var outside []string
+	for _, target := range targets {
+		path, ok := resolvePath(target, cwd)
+		if !ok || within(path, projectPath) || isAllowedWrite(path, extra) {
+			continue
+		}
+		outside = append(outside, path)
+	}
+	return outside
+}
+
+// resolvePath makes target absolute, expanding ~ and $HOME. It reports false
+// for empty targets and ones that still contain shell variables.
+func resolvePath(target, cwd string) (string, bool) {
+	target = expandHome(target)
+	if target == "" || strings.Contains(target, "$") {
+		return "", false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(cwd, target)
+	}
+	return filepath.Clean(target), true
+}
+
+// expandHome replaces a leading ~, $HOME, or ${HOME} with the home directory
+func expandHome(path string) string {
+	for _, prefix := range []string{"$HOME", "${HOME}"} {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			return config.ExpandHome("~" + rest)
+		}
+	}
+	return config.ExpandHome(path)
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	dir = filepath.Clean(dir)
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// isAllowedWrite reports whether path is in one of the allowed write locations
+func isAllowedWrite(path string, extra []string) bool {
+	for _, dir := range allowedWriteDirs {
+		if within(path, dir) {
+			return true
+		}
+	}
+	// The platform's temp directory (%TEMP% on Windows, $TMPDIR on macOS)
+	if within(path, os.TempDir()) {
+		return true
+	}
+	for _, dir := range extra {
+		if within(path, expandHome(dir)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package security
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOutsideProjectWrites(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+
+	tests := []struct {
+		name  string
+		tool  string
+		raw   string
+		cwd   string
+		extra []string
+		want  []string
+	}{
+		{"write inside", "Write", "/home/dev/app/main.go", "", nil, nil},
+		{"write outside", "Write", "/home/dev/other/main.go", "", nil, []string{"/home/dev/other/main.go"}},
+		{"sibling prefix is outside", "Edit", "/home/dev/app2/x.go", "", nil, []string{"/home/dev/app2/x.go"}},
+		{"tmp allowed", "Write", "/tmp/scratch.txt", "", nil, nil},
+		{"configured allow-list", "Write", "/home/dev/.cache/x", "", []string{"~/.cache"}, nil},
+		{"redirect to home", "Bash", "echo x >> ~/.bashrc", "", nil, []string{"/home/dev/.bashrc"}},
+		{"relative escapes project", "Bash", "echo x > ../notes.txt", "", nil, []string{"/home/dev/notes.txt"}},
+		{"relative against cwd", "Bash", "echo x > out.txt", "/srv", nil, []string{"/srv/out.txt"}},
+		{"dev null allowed", "Bash", "make 2>/dev/null", "", nil, nil},
+		{"unresolvable variable", "Bash", "echo x > $OUT", "", nil, nil},
+		{"read ignored", "Read", "/etc/passwd", "", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OutsideProjectWrites(tt.tool, tt.raw, tt.cwd, "/home/dev/app", tt.extra)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OutsideProjectWrites() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
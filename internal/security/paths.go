@@ -0,0 +1,29 @@
+package security
+
+import "strings"
+
+// sensitivePatterns contains path patterns that indicate security-sensitive files.
+// Defined at package level to avoid allocation on each IsSensitivePath call.
+var sensitivePatterns = []string{
+	"/etc/", "/usr/", "/bin/", "/sbin/",
+	".ssh/", ".gnupg/", ".aws/",
+	".env", "credentials", "secrets",
+	"/root/", "sudoers", "passwd", "shadow",
+}
+
+// IsSensitivePath checks if a path is security-sensitive, using the built-in
+// patterns plus any configured extras
+func IsSensitivePath(path string, extra []string) bool {
+	pathLower := strings.ToLower(path)
+	for _, s := range sensitivePatterns {
+		if strings.Contains(pathLower, s) {
+			return true
+		}
+	}
+	for _, s := range extra {
+		if strings.Contains(pathLower, strings.ToLower(s)) {
+			return true
+		}
+	}
+	return false
+}
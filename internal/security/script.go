@@ -0,0 +1,124 @@
+package security
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+// shellScriptExts are extensions of scripts analyzed with the shell checks only
+var shellScriptExts = map[string]bool{".sh": true, ".bash": true, ".zsh": true}
+
+// scriptExts are extensions of files treated as scripts
+var scriptExts = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".py": true, ".js": true,
+	".mjs": true, ".ts": true, ".rb": true, ".pl": true, ".php": true,
+}
+
+// IsScript reports whether a file looks like a script, by extension or shebang
+func IsScript(path, content string) bool {
+	return scriptExts[strings.ToLower(filepath.Ext(path))] || strings.HasPrefix(content, "#!")
+}
+
+// isShellScript reports whether a script is a shell script
+func isShellScript(path, content string) bool {
+	if shellScriptExts[strings.ToLower(filepath.Ext(path))] {
+		return true
+	}
+	if first, _, _ := strings.Cut(content, "\n"); strings.HasPrefix(first, "#!") {
+		return strings.HasSuffix(first, "sh") || strings.Contains(first, "sh ")
+	}
+	return false
+}
+
+// AnalyzeScript returns security warnings for a script's content: shell
+// checks for every script, plus code checks for non-shell scripts
+func AnalyzeScript(path, content string) []string {
+	warnings := AnalyzeBash(content)
+	if !isShellScript(path, content) {
+		warnings = append(warnings, AnalyzeCode(content)...)
+	}
+	return warnings
+}
+
+// ScriptRun is a script executed by a Bash command after the same session wrote it
+type ScriptRun struct {
+	Path      string    // Absolute script path
+	WrittenAt time.Time // When the Write happened
+	Warnings  []string  // Findings in the written content
+}
+
+// WrittenScriptRuns finds the scripts a Bash command executes that an
+// earlier Write in commands created, and analyzes the written content.
+// Relative paths are resolved against cwd. Writing a script and then running
+// it sidesteps command-level checks, so this looks at what the script does.
+func WrittenScriptRuns(command, cwd string, at time.Time, commands []session.CommandEntry) []ScriptRun {
+	var runs []ScriptRun
+	for _, script := range session.ExecutedScripts(command) {
+		path := script
+		if !filepath.IsAbs(path) {
+			if cwd == "" {
+				continue
+			}
+			path = filepath.Join(cwd, path)
+		}
+		path = filepath.Clean(path)
+
+		write := lastWriteBefore(commands, path, at)
+		if write == nil {
+			continue
+		}
+		runs = append(runs, ScriptRun{Path: path, WrittenAt: write.Timestamp, Warnings: writtenScriptWarnings(path, write)})
+	}
+	return runs
+}
+
+// scriptWarnings caches AnalyzeScript of written scripts by their Write's
+// record, which doesn't change once written, so findings can be computed on
+// every update without reading the session file again
+var scriptWarnings = struct {
+	sync.Mutex
+	byWrite map[string][]string
+}{byWrite: make(map[string][]string)}
+
+// writtenScriptWarnings returns the findings in the content write wrote to path
+func writtenScriptWarnings(path string, write *session.CommandEntry) []string {
+	key := fmt.Sprintf("%s:%d:%s:%s", write.FilePath, write.LineNumber, write.UUID, path)
+	scriptWarnings.Lock()
+	warnings, ok := scriptWarnings.byWrite[key]
+	scriptWarnings.Unlock()
+	if ok {
+		return warnings
+	}
+
+	input, err := session.FetchToolInput(write.FilePath, write.LineNumber, write.ToolName, write.UUID)
+	if err != nil {
+		return nil // Not cached: the file may be readable later
+	}
+	if content, ok := input.Parsed["content"].(string); ok {
+		warnings = AnalyzeScript(path, content)
+	}
+	scriptWarnings.Lock()
+	scriptWarnings.byWrite[key] = warnings
+	scriptWarnings.Unlock()
+	return warnings
+}
+
+// lastWriteBefore returns the most recent Write of path at or before t
+func lastWriteBefore(commands []session.CommandEntry, path string, t time.Time) *session.CommandEntry {
+	var found *session.CommandEntry
+	for i := range commands {
+		c := &commands[i]
+		if c.ToolName != "Write" || filepath.Clean(c.RawCommand) != path || c.Timestamp.After(t) {
+			continue
+		}
+		if found == nil || c.Timestamp.After(found.Timestamp) {
+			found = c
+		}
+	}
+	return found
+}
@@ -0,0 +1,79 @@
+package security
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestAnalyzeScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []string
+	}{
+		{"shell script", "clean.sh", "#!/bin/bash\nrm -rf build/\n", []string{"Recursive file deletion"}},
+		{"shebang without extension", "clean", "#!/usr/bin/env bash\nsudo reboot\n", []string{"Runs with elevated privileges"}},
+		{
+			"python script gets code checks too",
+			"fix.py",
+			"import os\nos.system('rm -rf /tmp/x')\n",
+			[]string{"Recursive file deletion", "Code runs shell commands"},
+		},
+		{"harmless", "hello.sh", "echo hello\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AnalyzeScript(tt.path, tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AnalyzeScript() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrittenScriptRuns(t *testing.T) {
+	dir := t.TempDir()
+	sessionFile := filepath.Join(dir, "sess.jsonl")
+	script := "/work/proj/cleanup.sh"
+
+	input, err := json.Marshal(map[string]string{"file_path": script, "content": "#!/bin/sh\nrm -rf ~/old\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := `{"type":"assistant","timestamp":"2026-01-02T10:00:00Z","uuid":"w1","sessionId":"s","cwd":"/work/proj",` +
+		`"message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Write","input":` + string(input) + `}]}}` + "\n"
+	if err := os.WriteFile(sessionFile, []byte(record), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	written := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	commands := []session.CommandEntry{
+		{ToolName: "Write", RawCommand: script, UUID: "w1", LineNumber: 1, FilePath: sessionFile, Timestamp: written},
+	}
+
+	runs := WrittenScriptRuns("chmod +x cleanup.sh && ./cleanup.sh", "/work/proj", written.Add(time.Minute), commands)
+	if len(runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(runs))
+	}
+	if runs[0].Path != script || !runs[0].WrittenAt.Equal(written) {
+		t.Errorf("run = %+v, want path %s written at %v", runs[0], script, written)
+	}
+	if !reflect.DeepEqual(runs[0].Warnings, []string{"Recursive file deletion"}) {
+		t.Errorf("warnings = %v, want recursive deletion", runs[0].Warnings)
+	}
+
+	// A script run before it was written (or never written) is not correlated
+	if runs := WrittenScriptRuns("./cleanup.sh", "/work/proj", written.Add(-time.Minute), commands); len(runs) != 0 {
+		t.Errorf("run before write correlated: %+v", runs)
+	}
+	if runs := WrittenScriptRuns("./other.sh", "/work/proj", written.Add(time.Minute), commands); len(runs) != 0 {
+		t.Errorf("unrelated script correlated: %+v", runs)
+	}
+}
@@ -0,0 +1,157 @@
+package security
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+// allEnvVars names the whole environment, dumped by env, printenv, or export
+const allEnvVars = "all environment variables"
+
+// secretNameFragments mark environment variable names (uppercased) as sensitive
+var secretNameFragments = []string{
+	"SECRET", "TOKEN", "PASSWORD", "PASSWD", "API_KEY", "APIKEY",
+	"PRIVATE_KEY", "CREDENTIAL", "ACCESS_KEY", "AUTH",
+}
+
+// envRefPattern matches $NAME and ${NAME} references
+var envRefPattern = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)`)
+
+// SecretExposure is a sensitive environment variable (or .env file) a tool call touches
+type SecretExposure struct {
+	Secret string // Variable name, ".env" file name, or "all environment variables"
+	Action string // "printed", "exported", or "written"
+}
+
+// IsSecretName reports whether an environment variable name looks sensitive
+func IsSecretName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, f := range secretNameFragments {
+		if strings.Contains(upper, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnvFile reports whether path is a dotenv file (.env, .env.local, ...)
+func isEnvFile(path string) bool {
+	base := filepath.Base(path)
+	return base == ".env" || strings.HasPrefix(base, ".env.")
+}
+
+// SecretExposures returns the secrets a tool call prints, exports, or
+// writes. For Write and Edit raw is the file path; for Bash it is the
+// command: env/printenv/export dumps, echo/printf of sensitive variables,
+// exports of sensitive variables, and redirects into .env files.
+func SecretExposures(toolName, raw string) []SecretExposure {
+	if toolName == "Write" || toolName == "Edit" {
+		if isEnvFile(raw) {
+			return []SecretExposure{{filepath.Base(raw), "written"}}
+		}
+		return nil
+	}
+	if toolName != "Bash" {
+		return nil
+	}
+
+	var exposures []SecretExposure
+	for _, words := range session.SimpleCommands(raw) {
+		exposures = append(exposures, commandExposures(words)...)
+	}
+	for _, target := range session.WriteTargets(raw) {
+		if isEnvFile(target) {
+			exposures = append(exposures, SecretExposure{filepath.Base(target), "written"})
+		}
+	}
+	return exposures
+}
+
+// commandExposures returns the secrets a simple command prints or exports
+func commandExposures(words []string) []SecretExposure {
+	cmd, args := words[0], words[1:]
+	switch cmd {
+	case "env", "printenv", "export", "set", "declare":
+		if len(args) == 0 || (cmd == "export" && args[0] == "-p") || (cmd == "declare" && args[0] == "-x") {
+			return []SecretExposure{{allEnvVars, "printed"}}
+		}
+	}
+
+	var exposures []SecretExposure
+	switch cmd {
+	case "printenv":
+		for _, a := range args {
+			if IsSecretName(a) {
+				exposures = append(exposures, SecretExposure{a, "printed"})
+			}
+		}
+	case "echo", "printf":
+		for _, a := range args {
+			for _, m := range envRefPattern.FindAllStringSubmatch(a, -1) {
+				if IsSecretName(m[1]) {
+					exposures = append(exposures, SecretExposure{m[1], "printed"})
+				}
+			}
+		}
+	case "export":
+		for _, a := range args {
+			if name, _, ok := strings.Cut(a, "="); ok && IsSecretName(name) {
+				exposures = append(exposures, SecretExposure{name, "exported"})
+			}
+		}
+	}
+	return exposures
+}
+
+// SecretTouch aggregates one secret's exposures across a session
+type SecretTouch struct {
+	Secret   string    // Variable or file name
+	Actions  []string  // Distinct actions, in first-seen order
+	Count    int       // Number of tool calls touching it
+	LastSeen time.Time // Most recent tool call touching it
+}
+
+// SecretsTouched aggregates the secrets exposed by a session's commands,
+// most recently touched first
+func SecretsTouched(commands []session.CommandEntry) []SecretTouch {
+	bySecret := make(map[string]*SecretTouch)
+	for i := range commands {
+		c := &commands[i]
+		seen := make(map[string]bool)
+		for _, e := range SecretExposures(c.ToolName, c.RawCommand) {
+			t, ok := bySecret[e.Secret]
+			if !ok {
+				t = &SecretTouch{Secret: e.Secret}
+				bySecret[e.Secret] = t
+			}
+			if !slices.Contains(t.Actions, e.Action) {
+				t.Actions = append(t.Actions, e.Action)
+			}
+			if !seen[e.Secret] {
+				seen[e.Secret] = true
+				t.Count++
+				if c.Timestamp.After(t.LastSeen) {
+					t.LastSeen = c.Timestamp
+				}
+			}
+		}
+	}
+
+	touched := make([]SecretTouch, 0, len(bySecret))
+	for _, t := range bySecret {
+		touched = append(touched, *t)
+	}
+	sort.Slice(touched, func(i, j int) bool {
+		if !touched[i].LastSeen.Equal(touched[j].LastSeen) {
+			return touched[i].LastSeen.After(touched[j].LastSeen)
+		}
+		return touched[i].Secret < touched[j].Secret
+	})
+	return touched
+}
@@ -0,0 +1,53 @@
+package security
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+func TestSecretExposures(t *testing.T) {
+	tests := []struct {
+		tool string
+		raw  string
+		want []SecretExposure
+	}{
+		{"Bash", "ls -la", nil},
+		{"Bash", "env | sort", []SecretExposure{{"all environment variables", "printed"}}},
+		{"Bash", "env FOO=1 make", nil},
+		{"Bash", "printenv GITHUB_TOKEN HOME", []SecretExposure{{"GITHUB_TOKEN", "printed"}}},
+		{"Bash", `echo "key=${AWS_SECRET_ACCESS_KEY}" $PATH`, []SecretExposure{{"AWS_SECRET_ACCESS_KEY", "printed"}}},
+		{"Bash", "export API_KEY=abc123 DEBUG=1", []SecretExposure{{"API_KEY", "exported"}}},
+		{"Bash", "echo DB_PASSWORD=x >> .env.local", []SecretExposure{{".env.local", "written"}}},
+		{"Write", "/app/.env", []SecretExposure{{".env", "written"}}},
+		{"Write", "/app/env.go", nil},
+		{"Read", "/app/.env", nil},
+	}
+
+	for _, tt := range tests {
+		if got := SecretExposures(tt.tool, tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SecretExposures(%s, %q) = %+v, want %+v", tt.tool, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSecretsTouched(t *testing.T) {
+	now := time.Now()
+	commands := []session.CommandEntry{
+		{ToolName: "Bash", RawCommand: "echo $GITHUB_TOKEN", Timestamp: now.Add(-2 * time.Minute)},
+		{ToolName: "Bash", RawCommand: "export GITHUB_TOKEN=x && echo $GITHUB_TOKEN", Timestamp: now.Add(-time.Minute)},
+		{ToolName: "Write", RawCommand: "/app/.env", Timestamp: now},
+		{ToolName: "Bash", RawCommand: "go test ./...", Timestamp: now},
+	}
+
+	got := SecretsTouched(commands)
+	want := []SecretTouch{
+		{Secret: ".env", Actions: []string{"written"}, Count: 1, LastSeen: now},
+		{Secret: "GITHUB_TOKEN", Actions: []string{"printed", "exported"}, Count: 2, LastSeen: now.Add(-time.Minute)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SecretsTouched() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package security
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+// Kinds of agent configuration a command can change
+const (
+	ConfigInstructions = "instructions"         // CLAUDE.md memory files, loaded into every prompt
+	ConfigSettings     = "settings"             // Permissions, hooks, and environment
+	ConfigHooks        = "hooks"                // Hook scripts run around tool calls
+	ConfigMCP          = "MCP servers"          // Tools the agent can call
+	ConfigMonitor      = "monitoring overrides" // .cc_session_mon.yaml, which can hide commands from this monitor
+)
+
+// ConfigWrite is a write to the agent's own configuration
+type ConfigWrite struct {
+	Path string
+	Kind string
+}
+
+// inPlaceEditors edit the files they are given when passed -i
+var inPlaceEditors = map[string]bool{"sed": true, "perl": true, "ruby": true}
+
+// fileModifiers change or remove every file they are given
+var fileModifiers = map[string]bool{"rm": true, "truncate": true, "chmod": true, "chown": true, "unlink": true}
+
+// fileCopiers write their last argument, or into it when it's a directory
+var fileCopiers = map[string]bool{"cp": true, "mv": true, "install": true, "ln": true, "rsync": true}
+
+// AgentConfigKind returns the kind of agent configuration path is (CLAUDE.md,
+// Claude settings, hook scripts, MCP server lists, or this monitor's project
+// override file), or "" for other files
+func AgentConfigKind(p string) string {
+	p = filepath.ToSlash(filepath.Clean(p))
+	base, dir := path.Base(p), path.Base(path.Dir(p))
+	switch {
+	case strings.EqualFold(base, "CLAUDE.md"), strings.EqualFold(base, "CLAUDE.local.md"):
+		return ConfigInstructions
+	case base == ".mcp.json":
+		return ConfigMCP
+	case base == config.ProjectFileName:
+		return ConfigMonitor
+	case strings.Contains("/"+p+"/", "/.claude/hooks/"):
+		return ConfigHooks
+	case dir == ".claude" && (base == "settings.json" || base == "settings.local.json"),
+		base == "managed-settings.json", base == ".claude.json":
+		return ConfigSettings
+	}
+	return ""
+}
+
+// ConfigTampering returns the agent configuration a tool call changes. For
+// Write, Edit, and NotebookEdit raw is the file path; for Bash it is the
+// command, whose redirect and tee targets, in-place edits (sed -i), copy and
+// move destinations, and removed or re-permissioned files are checked.
+func ConfigTampering(toolName, raw string) []ConfigWrite {
+	var targets []string
+	switch toolName {
+	case "Write", "Edit", "NotebookEdit":
+		targets = []string{raw}
+	case "Bash":
+		targets = session.WriteTargets(raw)
+		script, _ := session.SplitHeredocs(raw)
+		for _, words := range session.SimpleCommands(script) {
+			targets = append(targets, modifiedFiles(words)...)
+		}
+	default:
+		return nil
+	}
+
+	var writes []ConfigWrite
+	seen := make(map[string]bool)
+	for _, t := range targets {
+		if kind := AgentConfigKind(t); kind != "" && !seen[t] {
+			seen[t] = true
+			writes = append(writes, ConfigWrite{t, kind})
+		}
+	}
+	return writes
+}
+
+// modifiedFiles returns the files a simple command changes other than by
+// redirects: the operands of in-place editors and file modifiers, the
+// destination of copies (the sources' names inside it when it is a
+// directory like .claude/hooks/), and the sources of moves
+func modifiedFiles(words []string) []string {
+	name := filepath.Base(words[0])
+	var operands []string
+	inPlace := false
+	for _, w := range words[1:] {
+		switch {
+		case w == "--in-place" || strings.HasPrefix(w, "--in-place="):
+			inPlace = true
+		case strings.HasPrefix(w, "--"):
+		case strings.HasPrefix(w, "-"):
+			inPlace = inPlace || strings.Contains(w, "i") // -i, -i.bak, perl -pi
+		default:
+			operands = append(operands, w)
+		}
+	}
+
+	switch {
+	case inPlaceEditors[name] && inPlace, fileModifiers[name]:
+		return operands
+	case fileCopiers[name] && len(operands) >= 2:
+		dest, sources := operands[len(operands)-1], operands[:len(operands)-1]
+		var files []string
+		if name == "mv" {
+			files = append(files, sources...) // Moved away
+		}
+		dir := path.Clean(filepath.ToSlash(dest))
+		if !strings.HasSuffix(dest, "/") && len(sources) == 1 && path.Base(dir) != ".claude" && path.Base(dir) != "hooks" {
+			return append(files, dest)
+		}
+		for _, src := range sources {
+			files = append(files, path.Join(dir, path.Base(filepath.ToSlash(src))))
+		}
+		return files
+	}
+	return nil
+}
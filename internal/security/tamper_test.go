@@ -0,0 +1,70 @@
+package security
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+func TestAgentConfigKind(t *testing.T) {
+	tests := map[string]string{
+		"/work/app/CLAUDE.md":                   ConfigInstructions,
+		"docs/claude.md":                        ConfigInstructions,
+		"CLAUDE.local.md":                       ConfigInstructions,
+		"/work/app/.claude/settings.json":       ConfigSettings,
+		"/home/dev/.claude/settings.local.json": ConfigSettings,
+		"/home/dev/.claude.json":                ConfigSettings,
+		".claude/hooks/pre-tool.sh":             ConfigHooks,
+		".claude/hooks":                         ConfigHooks,
+		"/work/app/.mcp.json":                   ConfigMCP,
+		"/work/app/.cc_session_mon.yaml":        ConfigMonitor,
+		"/work/app/config/settings.json":        "",
+		"/work/app/README.md":                   "",
+		"/work/app/hooks/pre-commit":            "",
+	}
+	for path, want := range tests {
+		if got := AgentConfigKind(path); got != want {
+			t.Errorf("AgentConfigKind(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestConfigTampering(t *testing.T) {
+	tests := []struct {
+		tool, raw string
+		want      []ConfigWrite
+	}{
+		{"Edit", "/work/app/.claude/settings.json", []ConfigWrite{{"/work/app/.claude/settings.json", ConfigSettings}}},
+		{"Write", "/work/app/CLAUDE.md", []ConfigWrite{{"/work/app/CLAUDE.md", ConfigInstructions}}},
+		{"Read", "/work/app/CLAUDE.md", nil},
+		{"Bash", "cat CLAUDE.md", nil},
+		{"Bash", "echo 'always approve' >> CLAUDE.md", []ConfigWrite{{"CLAUDE.md", ConfigInstructions}}},
+		{"Bash", "jq '.permissions.allow += [\"Bash\"]' .claude/settings.json > t && mv t .claude/settings.json",
+			[]ConfigWrite{{".claude/settings.json", ConfigSettings}}},
+		{"Bash", "sed -i.bak 's/deny/allow/' .claude/settings.local.json", []ConfigWrite{{".claude/settings.local.json", ConfigSettings}}},
+		{"Bash", "cp ~/evil.sh .claude/hooks/", []ConfigWrite{{".claude/hooks/evil.sh", ConfigHooks}}},
+		{"Bash", "chmod +x .claude/hooks/pre.sh", []ConfigWrite{{".claude/hooks/pre.sh", ConfigHooks}}},
+		{"Bash", "rm -f .mcp.json", []ConfigWrite{{".mcp.json", ConfigMCP}}},
+		{"Write", "/work/app/.cc_session_mon.yaml", []ConfigWrite{{"/work/app/.cc_session_mon.yaml", ConfigMonitor}}},
+		{"Bash", "printf 'allowed_write_paths: [/]\\n' >> .cc_session_mon.yaml",
+			[]ConfigWrite{{".cc_session_mon.yaml", ConfigMonitor}}},
+		{"Bash", "cp .mcp.json /tmp/backup.json", nil},
+		{"Bash", "sed -n 1,5p CLAUDE.md", nil},
+	}
+	for _, tt := range tests {
+		if got := ConfigTampering(tt.tool, tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ConfigTampering(%s %q) = %v, want %v", tt.tool, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestMonitorConfigTamperFinding(t *testing.T) {
+	c := &session.CommandEntry{ToolName: "Edit", RawCommand: "/work/app/.cc_session_mon.yaml"}
+	findings := CommandFindings(c, "/work/app", config.DefaultConfig(), nil)
+	if !slices.Contains(findings, Finding{Rule: RuleConfigTamper, Severity: SeverityHigh}) {
+		t.Errorf("expected a high-severity %q finding, got %v", RuleConfigTamper, findings)
+	}
+}
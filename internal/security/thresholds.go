@@ -0,0 +1,122 @@
+package security
+
+import (
+	"fmt"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+// Breach is a burst or streak of commands that exceeded an alert threshold
+type Breach struct {
+	Threshold string
+	Severity  Severity
+	Commands  []session.CommandEntry // The counted commands, oldest first
+}
+
+// Start returns the time of the breach's first command
+func (b *Breach) Start() time.Time { return b.Commands[0].Timestamp }
+
+// End returns the time of the breach's last command
+func (b *Breach) End() time.Time { return b.Commands[len(b.Commands)-1].Timestamp }
+
+// Describe summarizes the breach, e.g. "21 Write in 5m0s" or "4 failed Bash(go:*) in a row"
+func (b *Breach) Describe(t *config.AlertThreshold) string {
+	what := t.Pattern
+	if what == "" {
+		what = "commands"
+	}
+	if t.Failed {
+		what = "failed " + what
+	}
+	if t.InARow {
+		return fmt.Sprintf("%d %s in a row", len(b.Commands), what)
+	}
+	return fmt.Sprintf("%d %s in %s", len(b.Commands), what, t.Window)
+}
+
+// ThresholdBreaches returns the breaches of t in a session's commands,
+// oldest first. A breach lasts while the threshold stays exceeded, so a
+// sustained burst is one breach, not one per command.
+func ThresholdBreaches(t *config.AlertThreshold, commands []session.CommandEntry) []Breach {
+	if t.Count < 1 || (!t.InARow && t.Window <= 0) {
+		return nil
+	}
+	sev := SeverityMedium
+	if t.Severity != "" {
+		if s, err := ParseSeverity(t.Severity); err == nil {
+			sev = s
+		}
+	}
+
+	var breaches []Breach
+	if t.InARow {
+		breaches = streakBreaches(t, commands)
+	} else {
+		breaches = windowBreaches(t, commands)
+	}
+	for i := range breaches {
+		breaches[i].Threshold = t.Name
+		breaches[i].Severity = sev
+	}
+	return breaches
+}
+
+// windowBreaches finds periods where more than t.Count matching commands
+// fall within t.Window of each other
+func windowBreaches(t *config.AlertThreshold, commands []session.CommandEntry) []Breach {
+	var matched []session.CommandEntry
+	for _, c := range commands {
+		if t.MatchesCommand(c.Pattern) && (!t.Failed || c.IsError) {
+			matched = append(matched, c)
+		}
+	}
+
+	var breaches []Breach
+	var current *Breach
+	first := 0
+	for i, c := range matched {
+		for c.Timestamp.Sub(matched[first].Timestamp) >= t.Window {
+			first++
+		}
+		if i-first+1 <= t.Count {
+			current = nil
+			continue
+		}
+		if current == nil {
+			breaches = append(breaches, Breach{Commands: append([]session.CommandEntry(nil), matched[first:i+1]...)})
+			current = &breaches[len(breaches)-1]
+			continue
+		}
+		current.Commands = append(current.Commands, c)
+	}
+	return breaches
+}
+
+// streakBreaches finds runs of more than t.Count consecutive matching
+// commands. With t.Failed the run is over the commands matching t.Pattern and
+// ends at a successful one; otherwise any non-matching command ends it.
+func streakBreaches(t *config.AlertThreshold, commands []session.CommandEntry) []Breach {
+	var breaches []Breach
+	var run []session.CommandEntry
+	endRun := func() {
+		if len(run) > t.Count {
+			breaches = append(breaches, Breach{Commands: run})
+		}
+		run = nil
+	}
+	for _, c := range commands {
+		matches := t.MatchesCommand(c.Pattern)
+		switch {
+		case t.Failed && !matches:
+			continue
+		case matches && (!t.Failed || c.IsError):
+			run = append(run, c)
+		default:
+			endRun()
+		}
+	}
+	endRun()
+	return breaches
+}
@@ -0,0 +1,68 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+func TestThresholdBreachesWindow(t *testing.T) {
+	start := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	var cmds []session.CommandEntry
+	// Four writes a minute apart, a long pause, then one more
+	for _, minute := range []int{0, 1, 2, 3, 30} {
+		cmds = append(cmds,
+			session.CommandEntry{ToolName: "Write", Pattern: "Write", Timestamp: start.Add(time.Duration(minute) * time.Minute)},
+			session.CommandEntry{ToolName: "Read", Pattern: "Read", Timestamp: start.Add(time.Duration(minute) * time.Minute)},
+		)
+	}
+
+	th := &config.AlertThreshold{Name: "write-burst", Pattern: "Write", Count: 2, Window: 5 * time.Minute}
+	got := ThresholdBreaches(th, cmds)
+	if len(got) != 1 {
+		t.Fatalf("expected one sustained breach, got %d: %+v", len(got), got)
+	}
+	b := got[0]
+	if b.Threshold != "write-burst" || b.Severity != SeverityMedium || len(b.Commands) != 4 {
+		t.Errorf("unexpected breach %s (%s) with %d commands", b.Threshold, b.Severity, len(b.Commands))
+	}
+	if !b.Start().Equal(start) || !b.End().Equal(start.Add(3*time.Minute)) {
+		t.Errorf("breach spans %s - %s", b.Start(), b.End())
+	}
+	if d := b.Describe(th); d != "4 Write in 5m0s" {
+		t.Errorf("Describe() = %q", d)
+	}
+
+	th.Count = 4
+	if got := ThresholdBreaches(th, cmds); len(got) != 0 {
+		t.Errorf("expected no breach at exactly the count, got %+v", got)
+	}
+}
+
+func TestThresholdBreachesInARow(t *testing.T) {
+	bash := func(failed bool) session.CommandEntry {
+		return session.CommandEntry{ToolName: "Bash", Pattern: "Bash(go:test:*)", IsError: failed}
+	}
+	read := session.CommandEntry{ToolName: "Read", Pattern: "Read"}
+	cmds := []session.CommandEntry{
+		bash(true), bash(true), read, bash(true), bash(false), // a Read doesn't end the run, a pass does
+		bash(true), bash(true), bash(true), bash(true),
+	}
+
+	th := &config.AlertThreshold{Name: "failing-tests", Pattern: "Bash(*", Failed: true, Count: 2, InARow: true, Severity: "high"}
+	got := ThresholdBreaches(th, cmds)
+	if len(got) != 2 || len(got[0].Commands) != 3 || len(got[1].Commands) != 4 {
+		t.Fatalf("expected runs of 3 and 4 failures, got %+v", got)
+	}
+	if got[1].Severity != SeverityHigh || got[1].Describe(th) != "4 failed Bash(* in a row" {
+		t.Errorf("unexpected breach %s: %q", got[1].Severity, got[1].Describe(th))
+	}
+
+	// Without failed, any other command ends the run
+	th = &config.AlertThreshold{Name: "tests", Pattern: "Bash(go:test:*)", Count: 3, InARow: true}
+	if got := ThresholdBreaches(th, cmds); len(got) != 1 || len(got[0].Commands) != 6 {
+		t.Errorf("expected the final run of 6 tests, got %+v", got)
+	}
+}
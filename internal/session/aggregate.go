@@ -0,0 +1,150 @@
+package session
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxPatternExamples is the number of distinct raw commands kept per pattern
+const maxPatternExamples = 5
+
+// AggregatePatterns groups commands by pattern, counting occurrences and
+// collecting up to five distinct examples. Results are sorted by count (descending).
+func AggregatePatterns(commands []CommandEntry) []*CommandPattern {
+	patternMap := make(map[string]*CommandPattern)
+
+	// Use a map per pattern to track unique examples (O(1) lookup instead of O(n))
+	exampleSets := make(map[string]map[string]struct{})
+
+	for i := range commands {
+		cmd := &commands[i] // Use pointer to avoid copying 128-byte struct
+
+		if p, exists := patternMap[cmd.Pattern]; exists {
+			p.Count++
+			if cmd.Timestamp.After(p.LastSeen) {
+				p.LastSeen = cmd.Timestamp
+			}
+			// Use set for O(1) duplicate check
+			if len(p.Examples) < maxPatternExamples {
+				exSet := exampleSets[cmd.Pattern]
+				if _, seen := exSet[cmd.RawCommand]; !seen {
+					exSet[cmd.RawCommand] = struct{}{}
+					p.Examples = append(p.Examples, cmd.RawCommand)
+				}
+			}
+		} else {
+			patternMap[cmd.Pattern] = &CommandPattern{
+				Pattern:  cmd.Pattern,
+				ToolName: cmd.ToolName,
+				Count:    1,
+				LastSeen: cmd.Timestamp,
+				Examples: []string{cmd.RawCommand},
+			}
+			// Initialize example set for this pattern
+			exampleSets[cmd.Pattern] = map[string]struct{}{cmd.RawCommand: {}}
+		}
+	}
+
+	// Convert to slice and sort by count
+	patterns := make([]*CommandPattern, 0, len(patternMap))
+	for _, p := range patternMap {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		return patterns[i].Count > patterns[j].Count
+	})
+
+	return patterns
+}
+
+// SkillCount is the number of times a session invoked one skill
+type SkillCount struct {
+	Name  string
+	Count int
+}
+
+// SkillUsage counts Skill calls by skill name, most used first (then by name)
+func SkillUsage(commands []CommandEntry) []SkillCount {
+	counts := make(map[string]int)
+	for i := range commands {
+		if commands[i].ToolName == "Skill" {
+			counts[commands[i].RawCommand]++
+		}
+	}
+
+	skills := make([]SkillCount, 0, len(counts))
+	for name, count := range counts {
+		skills = append(skills, SkillCount{Name: name, Count: count})
+	}
+	sort.Slice(skills, func(i, j int) bool {
+		if skills[i].Count != skills[j].Count {
+			return skills[i].Count > skills[j].Count
+		}
+		return skills[i].Name < skills[j].Name
+	})
+	return skills
+}
+
+// CommandRank is how often one raw command (or pattern) ran across sessions
+type CommandRank struct {
+	Key      string    // The raw command's first line, or the pattern
+	Pattern  string    // Pattern of the command (the key itself when ranking patterns)
+	ToolName string    // Tool name without pattern
+	Count    int       // Number of runs
+	Sessions int       // Sessions that ran it
+	LastUsed time.Time // Most recent run
+}
+
+// RankCommands counts the commands of all sessions by raw command, or by
+// pattern when byPattern is set, most frequent first (then most recent)
+func RankCommands(sessions []*Session, byPattern bool) []*CommandRank {
+	ranks := make(map[string]*CommandRank)
+	for _, sess := range sessions {
+		seen := make(map[string]bool)
+		for i := range sess.Commands {
+			cmd := &sess.Commands[i]
+			key, id := cmd.Pattern, cmd.Pattern
+			if !byPattern {
+				// The same path read and edited is two different commands
+				key, _, _ = strings.Cut(strings.TrimSpace(cmd.RawCommand), "\n")
+				id = cmd.ToolName + "\x00" + key
+			}
+			if key == "" {
+				continue
+			}
+
+			r, ok := ranks[id]
+			if !ok {
+				r = &CommandRank{Key: key, Pattern: cmd.Pattern, ToolName: cmd.ToolName}
+				ranks[id] = r
+			}
+			r.Count++
+			if cmd.Timestamp.After(r.LastUsed) {
+				r.LastUsed = cmd.Timestamp
+			}
+			if !seen[id] {
+				seen[id] = true
+				r.Sessions++
+			}
+		}
+	}
+
+	ranked := make([]*CommandRank, 0, len(ranks))
+	for _, r := range ranks {
+		ranked = append(ranked, r)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		if !ranked[i].LastUsed.Equal(ranked[j].LastUsed) {
+			return ranked[i].LastUsed.After(ranked[j].LastUsed)
+		}
+		if ranked[i].Key != ranked[j].Key {
+			return ranked[i].Key < ranked[j].Key
+		}
+		return ranked[i].ToolName < ranked[j].ToolName
+	})
+	return ranked
+}
@@ -0,0 +1,40 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRankCommands(t *testing.T) {
+	at := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	first := &Session{Commands: []CommandEntry{
+		{ToolName: "Bash", Pattern: "Bash(go:test:*)", RawCommand: "go test ./...", Timestamp: at},
+		{ToolName: "Bash", Pattern: "Bash(go:test:*)", RawCommand: "go test ./...", Timestamp: at.Add(time.Minute)},
+		{ToolName: "Bash", Pattern: "Bash(go:test:*)", RawCommand: "go test -run X ./internal/tui", Timestamp: at},
+		{ToolName: "Read", Pattern: "Read", RawCommand: "main.go", Timestamp: at},
+	}}
+	second := &Session{Commands: []CommandEntry{
+		{ToolName: "Bash", Pattern: "Bash(go:test:*)", RawCommand: "go test ./...\n# again", Timestamp: at.Add(time.Hour)},
+		{ToolName: "Edit", Pattern: "Edit", RawCommand: "main.go", Timestamp: at},
+	}}
+
+	ranked := RankCommands([]*Session{first, second}, false)
+	if len(ranked) != 4 {
+		t.Fatalf("got %d raw commands, want 4 (a path read and edited is two)", len(ranked))
+	}
+	top := ranked[0]
+	if top.Key != "go test ./..." || top.Count != 3 || top.Sessions != 2 || !top.LastUsed.Equal(at.Add(time.Hour)) {
+		t.Errorf("top command = %+v, want go test ./... run 3 times in 2 sessions, last an hour in", top)
+	}
+
+	ranked = RankCommands([]*Session{first, second}, true)
+	if len(ranked) != 3 {
+		t.Fatalf("got %d patterns, want 3", len(ranked))
+	}
+	if ranked[0].Key != "Bash(go:test:*)" || ranked[0].Count != 4 || ranked[0].Sessions != 2 {
+		t.Errorf("top pattern = %+v, want Bash(go:test:*) run 4 times in 2 sessions", ranked[0])
+	}
+	if ranked[1].Key != "Edit" || ranked[2].Key != "Read" {
+		t.Errorf("ties ordered %s, %s; want Edit, Read", ranked[1].Key, ranked[2].Key)
+	}
+}
@@ -0,0 +1,78 @@
+package session
+
+import (
+	"encoding/base64"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Attachment is non-text content in a tool result (an image block, a base64
+// payload, or binary output), kept out of ToolInput.Result so it isn't
+// rendered as text
+type Attachment struct {
+	MediaType string // e.g. "image/png", "application/octet-stream"
+	Data      []byte // Decoded content
+}
+
+// minBase64Payload is the shortest bare base64 text treated as a payload
+const minBase64Payload = 1024
+
+// dataURI matches the prefix of a base64 data: URI, capturing its media type
+var dataURI = regexp.MustCompile(`^data:([\w.+-]+/[\w.+-]+);base64,`)
+
+// textAttachment returns result text as an attachment when it is a base64
+// payload (bare or a data: URI) or binary; ok is false for ordinary text
+func textAttachment(text string) (att Attachment, ok bool) {
+	trimmed := strings.TrimSpace(text)
+	if m := dataURI.FindStringSubmatch(trimmed); m != nil {
+		if data, ok := decodeBase64(trimmed[len(m[0]):]); ok {
+			return Attachment{MediaType: m[1], Data: data}, true
+		}
+	}
+	if len(trimmed) >= minBase64Payload {
+		if data, ok := decodeBase64(trimmed); ok {
+			return Attachment{MediaType: mediaType(data), Data: data}, true
+		}
+	}
+	if isBinary(text) {
+		return Attachment{MediaType: mediaType([]byte(text)), Data: []byte(text)}, true
+	}
+	return Attachment{}, false
+}
+
+// decodeBase64 decodes s, which may be wrapped across lines and unpadded
+func decodeBase64(s string) ([]byte, bool) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+	return data, err == nil
+}
+
+// isBinary reports whether text is not readable: invalid UTF-8, NUL bytes,
+// or more than one in ten runes control characters other than whitespace
+func isBinary(text string) bool {
+	if !utf8.ValidString(text) || strings.ContainsRune(text, 0) {
+		return true
+	}
+	var control, total int
+	for _, r := range text {
+		total++
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			control++
+		}
+	}
+	return control*10 > total
+}
+
+// mediaType sniffs data's media type, without parameters such as charset
+func mediaType(data []byte) string {
+	mt, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	return mt
+}
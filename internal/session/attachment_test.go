@@ -0,0 +1,62 @@
+package session
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExtractResultAttachments(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 2000)...)
+	encoded := base64.StdEncoding.EncodeToString(png)
+	raw := func(v interface{}) json.RawMessage {
+		data, _ := json.Marshal(v)
+		return data
+	}
+
+	tests := []struct {
+		name      string
+		content   json.RawMessage
+		wantText  string
+		wantTypes []string
+	}{
+		{"plain text", raw("ok\nall good"), "ok\nall good", nil},
+		{"image block", raw([]map[string]interface{}{
+			{"type": "text", "text": "screenshot:"},
+			{"type": "image", "source": map[string]string{"type": "base64", "media_type": "image/png", "data": encoded}},
+		}), "screenshot:", []string{"image/png"}},
+		{"data URI", raw("data:image/jpeg;base64," + encoded), "", []string{"image/jpeg"}},
+		{"bare base64 wrapped across lines", raw(strings.Join(chunk(encoded, 76), "\n")), "", []string{"image/png"}},
+		{"binary output", raw("ELF\x00\x01\x02\x03 garbage"), "", []string{"application/octet-stream"}},
+		{"short base64-looking word", raw("deadbeef"), "deadbeef", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, atts := extractResult(tt.content)
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+			var types []string
+			for _, a := range atts {
+				types = append(types, a.MediaType)
+				if a.MediaType == "image/png" && len(a.Data) != len(png) {
+					t.Errorf("decoded %d bytes, want %d", len(a.Data), len(png))
+				}
+			}
+			if strings.Join(types, ",") != strings.Join(tt.wantTypes, ",") {
+				t.Errorf("attachments = %v, want %v", types, tt.wantTypes)
+			}
+		})
+	}
+}
+
+// chunk splits s into lines of n characters
+func chunk(s string, n int) []string {
+	var lines []string
+	for len(s) > n {
+		lines = append(lines, s[:n])
+		s = s[n:]
+	}
+	return append(lines, s)
+}
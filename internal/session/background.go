@@ -0,0 +1,241 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Background job statuses
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobKilled    = "killed"
+)
+
+// backgroundStarted matches the result of a run_in_background Bash call,
+// capturing the shell ID that BashOutput and KillShell refer to
+var backgroundStarted = regexp.MustCompile(`running in background with ID: ([\w-]+)`)
+
+// JobStart links a background Bash command, by tool_use ID, to its shell
+type JobStart struct {
+	ToolUseID string
+	ShellID   string
+}
+
+// JobCall is a BashOutput poll or KillShell call on a background shell
+type JobCall struct {
+	ShellID string
+	Kill    bool
+}
+
+// BackgroundJob is what a background Bash command's shell reported through
+// the BashOutput calls on it
+type BackgroundJob struct {
+	ShellID  string
+	Status   string // JobRunning, JobCompleted, JobFailed, or JobKilled
+	ExitCode int    // Set once the shell exits
+	Output   string // stdout and stderr from every poll, in order
+	Polls    int
+}
+
+// Settled reports whether a tool call's output can no longer change: its
+// result is written and, for a background command, its shell has ended
+func (t *ToolInput) Settled() bool {
+	return t.HasResult && (t.Job == nil || t.Job.Status != JobRunning)
+}
+
+// recordJobStarts links background commands to the shell IDs their results
+// name. Results for commands parsed in an earlier pass are left in
+// meta.JobStarts for the caller to apply.
+func (ps *parseState) recordJobStarts(msg *Message) {
+	for _, content := range msg.Content {
+		if content.Type != "tool_result" || !bytes.Contains(content.Content, []byte("in background with ID")) {
+			continue
+		}
+		text, _ := extractResult(content.Content)
+		m := backgroundStarted.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		if idx, ok := ps.toolUses[content.ToolUseID]; ok {
+			if ps.commands[idx].Background {
+				ps.commands[idx].BackgroundID = m[1]
+				ps.jobs[m[1]] = idx
+			}
+		} else if content.ToolUseID != "" {
+			ps.meta.JobStarts = append(ps.meta.JobStarts, JobStart{ToolUseID: content.ToolUseID, ShellID: m[1]})
+		}
+	}
+}
+
+// foldJobCall counts a BashOutput or KillShell call against the background
+// command whose shell it names, reporting whether it was folded. Calls on a
+// shell started in an earlier pass are left in meta.JobCalls; in a full
+// parse, calls on an unknown shell stay entries of their own.
+func (ps *parseState) foldJobCall(toolName string, input *GenericInput) bool {
+	var call JobCall
+	switch toolName {
+	case "BashOutput":
+		call = JobCall{ShellID: input.BashID}
+	case "KillShell":
+		call = JobCall{ShellID: firstNonEmpty(input.ShellID, input.BashID), Kill: true}
+	default:
+		return false
+	}
+	if call.ShellID == "" {
+		return false
+	}
+	if idx, ok := ps.jobs[call.ShellID]; ok {
+		call.apply(&ps.commands[idx])
+		return true
+	}
+	if ps.incremental {
+		ps.meta.JobCalls = append(ps.meta.JobCalls, call)
+		return true
+	}
+	return false
+}
+
+// apply counts the call against a background command
+func (c JobCall) apply(cmd *CommandEntry) {
+	if c.Kill {
+		cmd.Killed = true
+	} else {
+		cmd.Polls++
+	}
+}
+
+// ApplyJobEvents applies background shell events from an incremental parse
+// to the commands parsed before it, searching from the newest, and reports
+// whether any command changed
+func ApplyJobEvents(commands []CommandEntry, meta SessionMetadata) bool {
+	var changed bool
+	for _, start := range meta.JobStarts {
+		for i := len(commands) - 1; i >= 0; i-- {
+			if commands[i].ToolUseID == start.ToolUseID {
+				if commands[i].Background {
+					commands[i].BackgroundID = start.ShellID
+					changed = true
+				}
+				break
+			}
+		}
+	}
+	for _, call := range meta.JobCalls {
+		for i := len(commands) - 1; i >= 0; i-- {
+			if commands[i].BackgroundID == call.ShellID {
+				call.apply(&commands[i])
+				changed = true
+				break
+			}
+		}
+	}
+	return changed
+}
+
+// backgroundShellID returns the shell ID of a background Bash call whose
+// result has been written, or ""
+func backgroundShellID(input *ToolInput) string {
+	if input.ToolName != "Bash" || !input.HasResult {
+		return ""
+	}
+	if bg, _ := input.Parsed["run_in_background"].(bool); !bg {
+		return ""
+	}
+	if m := backgroundStarted.FindStringSubmatch(input.Result); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// readBackgroundJob collects what the BashOutput and KillShell calls on
+// shellID reported, scanning filePath after the line that started it
+func readBackgroundJob(filePath string, startLine int, shellID string) (*BackgroundJob, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	job := &BackgroundJob{ShellID: shellID, Status: JobRunning}
+	calls := make(map[string]bool) // tool_use ID -> KillShell
+	var output strings.Builder
+
+	scanner, release := newLineScanner(file)
+	defer release()
+	for line := 1; scanner.Scan(); line++ {
+		if line <= startLine || !bytes.Contains(scanner.Bytes(), []byte(`"tool_`)) {
+			continue
+		}
+		var record JSONLRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.Message == nil {
+			continue
+		}
+		for _, content := range record.Message.Content {
+			switch content.Type {
+			case "tool_use":
+				var input GenericInput
+				if err := json.Unmarshal(content.Input, &input); err != nil {
+					continue
+				}
+				switch {
+				case content.Name == "BashOutput" && input.BashID == shellID:
+					calls[content.ID] = false
+					job.Polls++
+				case content.Name == "KillShell" && firstNonEmpty(input.ShellID, input.BashID) == shellID:
+					calls[content.ID] = true
+				}
+			case "tool_result":
+				kill, ok := calls[content.ToolUseID]
+				if !ok {
+					continue
+				}
+				text, _ := extractResult(content.Content)
+				if kill {
+					if !content.IsError {
+						job.Status = JobKilled
+					}
+					continue
+				}
+				job.applyPoll(text, &output)
+			}
+		}
+	}
+	job.Output = output.String()
+	return job, scanner.Err()
+}
+
+// applyPoll folds a BashOutput result into the job: its new output and the
+// status and exit code it reports
+func (j *BackgroundJob) applyPoll(result string, output *strings.Builder) {
+	for _, tag := range []string{"stdout", "stderr"} {
+		if s := tagContent(result, tag); s != "" {
+			output.WriteString(s + "\n")
+		}
+	}
+	if code, err := strconv.Atoi(tagContent(result, "exit_code")); err == nil {
+		j.ExitCode = code
+	}
+	switch status := tagContent(result, "status"); status {
+	case JobCompleted, JobFailed, JobKilled:
+		j.Status = status
+		if status == JobCompleted && j.ExitCode != 0 {
+			j.Status = JobFailed
+		}
+	}
+}
+
+// tagContent returns the trimmed text inside the first <tag>...</tag> in s
+func tagContent(s, tag string) string {
+	_, rest, ok := strings.Cut(s, "<"+tag+">")
+	if !ok {
+		return ""
+	}
+	inner, _, _ := strings.Cut(rest, "</"+tag+">")
+	return strings.Trim(inner, "\n")
+}
@@ -0,0 +1,107 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// backgroundSession is a run_in_background command polled twice, then killed
+var backgroundSession = []string{
+	`{"type":"assistant","uuid":"u1","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"npm run dev","run_in_background":true}}]}}`,
+	`{"type":"user","uuid":"u2","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"Command running in background with ID: bash_1"}]}}`,
+	`{"type":"assistant","uuid":"u3","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"BashOutput","input":{"bash_id":"bash_1"}}]}}`,
+	`{"type":"user","uuid":"u4","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"<status>running</status>\n\n<stdout>\nstarting\n</stdout>"}]}}`,
+	`{"type":"assistant","uuid":"u5","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"BashOutput","input":{"bash_id":"bash_1"}}]}}`,
+	`{"type":"user","uuid":"u6","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","content":"<status>running</status>\n\n<stdout>\nlistening on :3000\n</stdout>"}]}}`,
+	`{"type":"assistant","uuid":"u7","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"KillShell","input":{"shell_id":"bash_1"}}]}}`,
+	`{"type":"user","uuid":"u8","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t4","content":"Successfully killed shell: bash_1"}]}}`,
+}
+
+func writeBackgroundSession(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(backgroundSession, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBackgroundJobFolding(t *testing.T) {
+	path := writeBackgroundSession(t)
+
+	commands, _, err := ParseSessionFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 1 {
+		t.Fatalf("expected the polls folded into one entry, got %d entries", len(commands))
+	}
+	job := commands[0]
+	if !job.Background || job.BackgroundID != "bash_1" || job.Polls != 2 || !job.Killed {
+		t.Errorf("unexpected job entry: %+v", job)
+	}
+}
+
+func TestBackgroundJobFoldingAcrossPasses(t *testing.T) {
+	path := writeBackgroundSession(t)
+	data, _ := os.ReadFile(path)
+	firstLine := int64(len(backgroundSession[0]) + 1)
+
+	// The watcher reads the tool_use first and everything else later
+	if err := os.WriteFile(path, data[:firstLine], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	commands, _, offset, line, err := ParseSessionFileFrom(path, 0, 0)
+	if err != nil || len(commands) != 1 {
+		t.Fatalf("expected the command from the first pass, got %d, %v", len(commands), err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	more, meta, _, _, err := ParseSessionFileFrom(path, offset, line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(more) != 0 {
+		t.Fatalf("expected the polls to be folded, got %+v", more)
+	}
+	if !ApplyJobEvents(commands, meta) {
+		t.Fatal("expected the job events to change the command")
+	}
+	if c := commands[0]; c.BackgroundID != "bash_1" || c.Polls != 2 || !c.Killed {
+		t.Errorf("unexpected job entry: %+v", c)
+	}
+}
+
+func TestFetchToolInputCollectsBackgroundJob(t *testing.T) {
+	path := writeBackgroundSession(t)
+
+	input, err := FetchToolInput(path, 1, "Bash", "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := input.Job
+	if job == nil {
+		t.Fatal("expected the background job's output")
+	}
+	if job.Status != JobKilled || job.Polls != 2 {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if job.Output != "starting\nlistening on :3000\n" {
+		t.Errorf("Output = %q", job.Output)
+	}
+	if !input.Settled() {
+		t.Error("expected a killed job to be settled")
+	}
+}
+
+func TestBackgroundJobApplyPoll(t *testing.T) {
+	job := &BackgroundJob{Status: JobRunning}
+	var output strings.Builder
+	job.applyPoll("<status>completed</status>\n\n<exit_code>1</exit_code>\n\n<stderr>\nboom\n</stderr>", &output)
+	if job.Status != JobFailed || job.ExitCode != 1 || output.String() != "boom\n" {
+		t.Errorf("unexpected job after a failing exit: %+v, %q", job, output.String())
+	}
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"sort"
+
+	"cc_session_mon/internal/config"
+)
+
+// categoryTopPatterns is how many of its most used patterns a CategoryStat keeps
+const categoryTopPatterns = 3
+
+// CategoryStat is how many commands of all sessions fall in one category
+// of the config's classification
+type CategoryStat struct {
+	Name        string
+	Count       int
+	Sessions    int      // Sessions with commands in the category
+	TopPatterns []string // Most used patterns, most first
+}
+
+// CategoryStats counts the commands of all sessions per category, classified
+// by each session's project config, most commands first (then by name)
+func CategoryStats(sessions []*Session) []*CategoryStat {
+	stats := make(map[string]*CategoryStat)
+	patterns := make(map[string]map[string]int)
+	for _, sess := range sessions {
+		cfg := config.ForProject(sess.ProjectPath)
+		seen := make(map[string]bool)
+		for i := range sess.Commands {
+			pattern := sess.Commands[i].Pattern
+			name := cfg.Classify(pattern)
+			st, ok := stats[name]
+			if !ok {
+				st = &CategoryStat{Name: name}
+				stats[name] = st
+				patterns[name] = make(map[string]int)
+			}
+			st.Count++
+			patterns[name][pattern]++
+			if !seen[name] {
+				seen[name] = true
+				st.Sessions++
+			}
+		}
+	}
+
+	sorted := make([]*CategoryStat, 0, len(stats))
+	for name, st := range stats {
+		st.TopPatterns = topPatterns(patterns[name], categoryTopPatterns)
+		sorted = append(sorted, st)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// topPatterns returns the n patterns with the highest counts, ties by pattern
+func topPatterns(counts map[string]int, n int) []string {
+	top := make([]string, 0, len(counts))
+	for p := range counts {
+		top = append(top, p)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if counts[top[i]] != counts[top[j]] {
+			return counts[top[i]] > counts[top[j]]
+		}
+		return top[i] < top[j]
+	})
+	return top[:min(n, len(top))]
+}
@@ -0,0 +1,42 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	"cc_session_mon/internal/config"
+)
+
+func TestCategoryStats(t *testing.T) {
+	config.SetGlobal(config.DefaultConfig())
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	commands := func(patterns ...string) []CommandEntry {
+		entries := make([]CommandEntry, len(patterns))
+		for i, p := range patterns {
+			entries[i] = CommandEntry{Pattern: p}
+		}
+		return entries
+	}
+	sessions := []*Session{
+		{ProjectPath: "/code/a", Commands: commands("Bash(go:test:*)", "Bash(go:test:*)", "Read", "Edit", "Task")},
+		{ProjectPath: "/code/b", Commands: commands("Read", "Read", "Bash(cargo:test:*)", "Bash(git:status:*)")},
+	}
+
+	stats := CategoryStats(sessions)
+	var names []string
+	for _, st := range stats {
+		names = append(names, st.Name)
+	}
+	if want := []string{"file-io", "test", config.Uncategorized, "vcs"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("categories = %v, want %v", names, want)
+	}
+
+	fileIO, test := stats[0], stats[1]
+	if fileIO.Count != 4 || fileIO.Sessions != 2 || !reflect.DeepEqual(fileIO.TopPatterns, []string{"Read", "Edit"}) {
+		t.Errorf("file-io = %+v, want 4 commands in 2 sessions, mostly Read", fileIO)
+	}
+	if test.Count != 3 || test.Sessions != 2 || test.TopPatterns[0] != "Bash(go:test:*)" {
+		t.Errorf("test = %+v, want 3 commands in 2 sessions, mostly go test", test)
+	}
+}
@@ -0,0 +1,45 @@
+package session
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+)
+
+// normalizeTimes corrects the times parsed from a session file for its
+// origin's configured clock skew and moves them into the display zone, so
+// sessions from every machine sort and show "ago" times against one clock
+func normalizeTimes(origin string, commands []CommandEntry, meta *SessionMetadata) {
+	clock := &config.Global().Clock
+	offset, loc := clock.Offset(origin), clock.Location()
+	normalize := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		return t.Add(offset).In(loc)
+	}
+
+	for i := range commands {
+		commands[i].Timestamp = normalize(commands[i].Timestamp)
+	}
+	if meta == nil {
+		return
+	}
+	meta.StartedAt = normalize(meta.StartedAt)
+	for i := range meta.Markers {
+		meta.Markers[i].Timestamp = normalize(meta.Markers[i].Timestamp)
+	}
+}
+
+// originOf returns the origin label of the projects directory holding path.
+// Must be called with w.mu held.
+func (w *Watcher) originOf(path string) string {
+	for _, projectsDir := range w.projectsDirs {
+		if strings.HasPrefix(path, projectsDir+string(filepath.Separator)) || path == projectsDir {
+			return w.originMap[projectsDir]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/config"
+)
+
+func TestSkewedOriginNormalized(t *testing.T) {
+	config.SetGlobal(&config.Config{Clock: config.ClockSettings{
+		Timezone: "UTC",
+		Offsets:  map[string]time.Duration{"devagent:*": -time.Hour},
+	}})
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	// The container's clock runs an hour fast
+	root, _ := writeSessionCopy(t, []string{
+		`{"type":"assistant","timestamp":"2026-01-02T11:00:00+01:00","uuid":"u1","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]}}`,
+	})
+	w, err := NewWatcher([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	w.SetOrigin(root, "devagent:box")
+
+	sessions, err := w.DiscoverSessions()
+	if err != nil || len(sessions) != 1 || len(sessions[0].Commands) != 1 {
+		t.Fatalf("expected one session with one command, got %v, %v", sessions, err)
+	}
+	got := sessions[0].Commands[0].Timestamp
+	if want := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("Timestamp = %v, want %v", got, want)
+	}
+}
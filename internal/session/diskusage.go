@@ -0,0 +1,25 @@
+package session
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// DiskUsage returns the bytes a session occupies on disk: its JSONL file
+// plus everything under its subagent directory (SessionFiles). Files that
+// vanish or can't be read while walking are skipped.
+func DiskUsage(filePath string) int64 {
+	var total int64
+	for _, root := range SessionFiles(filePath) {
+		_ = filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+			return nil
+		})
+	}
+	return total
+}
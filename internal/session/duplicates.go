@@ -0,0 +1,79 @@
+package session
+
+import "sort"
+
+// dedupeSessions drops the twins of sessions found under more than one
+// watched root (a synced backup, or a host and container view of the same
+// mount): for each session ID only the preferred copy is kept, with the
+// other copies' files listed in its Duplicates. Order is preserved.
+func dedupeSessions(sessions []*Session) []*Session {
+	byID := make(map[string][]*Session, len(sessions))
+	for _, s := range sessions {
+		byID[s.ID] = append(byID[s.ID], s)
+	}
+
+	kept := make([]*Session, 0, len(sessions))
+	for _, s := range sessions {
+		twins := byID[s.ID]
+		if len(twins) == 1 {
+			s.Duplicates = nil
+			kept = append(kept, s)
+			continue
+		}
+		if preferredSession(twins) != s {
+			continue
+		}
+		s.Duplicates = s.Duplicates[:0]
+		for _, twin := range twins {
+			if twin != s {
+				s.Duplicates = append(s.Duplicates, twin.FilePath)
+			}
+		}
+		sort.Strings(s.Duplicates)
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+// preferredSession picks the copy of a session to show: the one with the
+// most commands (the others lag behind it), then the most recently active,
+// then a local one over a container's, then by file path
+func preferredSession(twins []*Session) *Session {
+	best := twins[0]
+	for _, s := range twins[1:] {
+		switch {
+		case len(s.Commands) != len(best.Commands):
+			if len(s.Commands) > len(best.Commands) {
+				best = s
+			}
+		case !s.LastActivity.Equal(best.LastActivity):
+			if s.LastActivity.After(best.LastActivity) {
+				best = s
+			}
+		case isLocal(s) != isLocal(best):
+			if isLocal(s) {
+				best = s
+			}
+		case s.FilePath < best.FilePath:
+			best = s
+		}
+	}
+	return best
+}
+
+// isLocal reports whether a session was found in a local projects directory
+func isLocal(s *Session) bool {
+	return s.Origin == "" || s.Origin == "local"
+}
+
+// isDuplicate reports whether s is hidden in favor of another copy of the
+// same session. Must be called with w.mu held.
+func (w *Watcher) isDuplicate(s *Session) bool {
+	var twins []*Session
+	for _, other := range w.sessions {
+		if other.ID == s.ID {
+			twins = append(twins, other)
+		}
+	}
+	return len(twins) > 1 && preferredSession(twins) != s
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSessionCopy writes lines as session sess-1 under a new projects root
+func writeSessionCopy(t *testing.T, lines []string) (root, path string) {
+	t.Helper()
+	root = t.TempDir()
+	projectDir := filepath.Join(root, "-projects-alpha")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path = filepath.Join(projectDir, "sess-1.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return root, path
+}
+
+func TestDuplicateSessionsMerged(t *testing.T) {
+	// The synced copy lags one command behind the live one
+	liveRoot, livePath := writeSessionCopy(t, backgroundSession)
+	syncedRoot, syncedPath := writeSessionCopy(t, backgroundSession[:1])
+
+	w, err := NewWatcher([]string{syncedRoot, liveRoot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+
+	discovered, err := w.DiscoverSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sessions := range [][]*Session{discovered, w.GetSessions()} {
+		if len(sessions) != 1 {
+			t.Fatalf("expected the twins merged into one session, got %d", len(sessions))
+		}
+		s := sessions[0]
+		if s.FilePath != livePath {
+			t.Errorf("expected the most complete copy to be kept, got %s", s.FilePath)
+		}
+		if len(s.Duplicates) != 1 || s.Duplicates[0] != syncedPath {
+			t.Errorf("Duplicates = %v", s.Duplicates)
+		}
+	}
+}
+
+func TestPreferredSessionTieBreaks(t *testing.T) {
+	local := &Session{ID: "a", FilePath: "/b/a.jsonl", Origin: "local"}
+	container := &Session{ID: "a", FilePath: "/a/a.jsonl", Origin: "devagent:box"}
+	if got := preferredSession([]*Session{container, local}); got != local {
+		t.Errorf("expected the local copy on a tie, got %s", got.FilePath)
+	}
+	other := &Session{ID: "a", FilePath: "/c/a.jsonl", Origin: "local"}
+	if got := preferredSession([]*Session{other, local}); got != local {
+		t.Errorf("expected the first path on a full tie, got %s", got.FilePath)
+	}
+}
@@ -0,0 +1,70 @@
+package session
+
+import (
+	"sort"
+	"time"
+)
+
+// Heatmap counts commands per hour of each day in a period
+type Heatmap struct {
+	Days   []time.Time `json:"days"`   // Midnight of each day, oldest first
+	Counts [][24]int   `json:"counts"` // Commands per hour of each day
+	Max    int         `json:"max"`    // Largest hourly count
+}
+
+// NewHeatmap returns an empty heatmap of the days from since's through
+// until's, in since's time zone
+func NewHeatmap(since, until time.Time) *Heatmap {
+	h := &Heatmap{}
+	until = until.In(since.Location())
+	last := midnight(until)
+	for day := midnight(since); !day.After(last); day = day.AddDate(0, 0, 1) {
+		h.Days = append(h.Days, day)
+	}
+	h.Counts = make([][24]int, len(h.Days))
+	return h
+}
+
+// ActivityHeatmap counts the commands of all sessions issued on the days
+// from since's through until's
+func ActivityHeatmap(sessions []*Session, since, until time.Time) *Heatmap {
+	h := NewHeatmap(since, until)
+	for _, sess := range sessions {
+		for i := range sess.Commands {
+			h.Add(sess.Commands[i].Timestamp)
+		}
+	}
+	return h
+}
+
+// Add counts a command issued at t, unless it falls outside the heatmap's days
+func (h *Heatmap) Add(t time.Time) {
+	if len(h.Days) == 0 {
+		return
+	}
+	t = t.In(h.Days[0].Location())
+	day := midnight(t)
+	// Days differ by 23 or 25 hours across DST changes, so search the
+	// calendar days rather than dividing
+	i := sort.Search(len(h.Days), func(i int) bool { return !h.Days[i].Before(day) })
+	if i == len(h.Days) || !h.Days[i].Equal(day) {
+		return
+	}
+	h.Counts[i][t.Hour()]++
+	h.Max = max(h.Max, h.Counts[i][t.Hour()])
+}
+
+// Level buckets a count into 0 (none) through levels-1 (the busiest hour),
+// like the shades of a contributions calendar
+func (h *Heatmap) Level(count, levels int) int {
+	if count <= 0 || h.Max == 0 || levels < 2 {
+		return 0
+	}
+	// Round up, so every hour with commands shows and only the busiest is darkest
+	return (count*(levels-1) + h.Max - 1) / h.Max
+}
+
+// midnight returns the start of t's day in t's time zone
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
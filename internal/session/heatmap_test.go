@@ -0,0 +1,37 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActivityHeatmap(t *testing.T) {
+	loc := time.FixedZone("test", 2*3600)
+	since := time.Date(2026, 3, 1, 18, 0, 0, 0, loc)
+	until := time.Date(2026, 3, 3, 9, 0, 0, 0, loc)
+	at := func(day, hour int) CommandEntry {
+		return CommandEntry{Timestamp: time.Date(2026, 3, day, hour, 30, 0, 0, loc)}
+	}
+	sessions := []*Session{
+		{Commands: []CommandEntry{at(1, 9), at(1, 9), at(1, 9), at(3, 14)}},
+		{Commands: []CommandEntry{
+			at(2, 23),
+			{Timestamp: time.Date(2026, 3, 2, 22, 10, 0, 0, time.UTC)}, // 00:10 on the 3rd in loc
+			at(4, 1), // after the last day
+		}},
+	}
+
+	h := ActivityHeatmap(sessions, since, until)
+	if len(h.Days) != 3 || !h.Days[0].Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, loc)) {
+		t.Fatalf("days = %v, want March 1-3 from midnight", h.Days)
+	}
+	if h.Counts[0][9] != 3 || h.Counts[1][23] != 1 || h.Counts[2][0] != 1 || h.Counts[2][14] != 1 {
+		t.Errorf("counts = %v", h.Counts)
+	}
+	if h.Max != 3 {
+		t.Errorf("max = %d, want 3", h.Max)
+	}
+	if h.Level(0, 5) != 0 || h.Level(1, 5) != 2 || h.Level(2, 5) != 3 || h.Level(3, 5) != 4 {
+		t.Errorf("levels of 0-3 = %d, %d, %d, %d; want 0, 2, 3, 4", h.Level(0, 5), h.Level(1, 5), h.Level(2, 5), h.Level(3, 5))
+	}
+}
@@ -0,0 +1,93 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Heredoc is a here-document body found in a Bash command
+type Heredoc struct {
+	Delimiter string // Terminator word (e.g. EOF)
+	Body      string // Lines between the opener and the terminator
+	Lines     int    // Number of body lines
+}
+
+// heredocOpener matches "<<EOF", "<<-EOF", "<< 'EOF'" and "<<\"EOF\"" but not here-strings (<<<)
+var heredocOpener = regexp.MustCompile(`(?:^|[^<])<<(-?)[ \t]*['"]?([A-Za-z_][A-Za-z0-9_]*)['"]?`)
+
+// pendingHeredoc is an opener whose body starts on the next line
+type pendingHeredoc struct {
+	delimiter string
+	stripTabs bool // <<- allows tab-indented terminators
+}
+
+// SplitHeredocs separates heredoc bodies from a Bash command. It returns the
+// command with each body and its terminator line removed, and the bodies in
+// order. An unterminated heredoc runs to the end of the command.
+func SplitHeredocs(command string) (string, []Heredoc) {
+	if !strings.Contains(command, "<<") {
+		return command, nil
+	}
+
+	lines := strings.Split(command, "\n")
+	var kept []string
+	var docs []Heredoc
+
+	for i := 0; i < len(lines); i++ {
+		kept = append(kept, lines[i])
+		for _, p := range findHeredocOpeners(lines[i]) {
+			var body []string
+			for i++; i < len(lines); i++ {
+				line := lines[i]
+				if p.stripTabs {
+					line = strings.TrimLeft(line, "\t")
+				}
+				if line == p.delimiter {
+					break
+				}
+				body = append(body, lines[i])
+			}
+			docs = append(docs, Heredoc{Delimiter: p.delimiter, Body: strings.Join(body, "\n"), Lines: len(body)})
+		}
+	}
+
+	return strings.Join(kept, "\n"), docs
+}
+
+// findHeredocOpeners returns the heredocs opened on a line, in order
+func findHeredocOpeners(line string) []pendingHeredoc {
+	matches := heredocOpener.FindAllStringSubmatch(line, -1)
+	if matches == nil {
+		return nil
+	}
+	openers := make([]pendingHeredoc, 0, len(matches))
+	for _, m := range matches {
+		openers = append(openers, pendingHeredoc{delimiter: m[2], stripTabs: m[1] == "-"})
+	}
+	return openers
+}
+
+// firstLogicalLine returns the first command line of a multi-line script,
+// skipping blank and comment lines and joining backslash continuations.
+// Heredoc bodies and later lines are ignored.
+func firstLogicalLine(command string) string {
+	if !strings.Contains(command, "\n") {
+		return command
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(command, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if b.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "#")) {
+			continue
+		}
+		if cont, ok := strings.CutSuffix(trimmed, "\\"); ok {
+			b.WriteString(cont)
+			b.WriteString(" ")
+			continue
+		}
+		b.WriteString(trimmed)
+		break
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package session
+
+import "testing"
+
+func TestSplitHeredocs(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		wantScript string
+		wantDocs   []Heredoc
+	}{
+		{"no heredoc", "ls -la", "ls -la", nil},
+		{"here-string is not a heredoc", "cat <<< hello", "cat <<< hello", nil},
+		{
+			"simple heredoc",
+			"cat <<EOF > notes.txt\nline one\nline two\nEOF",
+			"cat <<EOF > notes.txt",
+			[]Heredoc{{Delimiter: "EOF", Body: "line one\nline two", Lines: 2}},
+		},
+		{
+			"quoted delimiter inside command substitution",
+			"git commit -m \"$(cat <<'EOF'\nFix bug\n\nDetails\nEOF\n)\"",
+			"git commit -m \"$(cat <<'EOF'\n)\"",
+			[]Heredoc{{Delimiter: "EOF", Body: "Fix bug\n\nDetails", Lines: 3}},
+		},
+		{
+			"tab-stripped terminator",
+			"cat <<-END\n\tindented\n\tEND\necho done",
+			"cat <<-END\necho done",
+			[]Heredoc{{Delimiter: "END", Body: "\tindented", Lines: 1}},
+		},
+		{
+			"unterminated heredoc",
+			"python3 - <<PY\nprint(1)",
+			"python3 - <<PY",
+			[]Heredoc{{Delimiter: "PY", Body: "print(1)", Lines: 1}},
+		},
+		{
+			"two heredocs on one line",
+			"cmd <<A <<B\na\nA\nb\nB",
+			"cmd <<A <<B",
+			[]Heredoc{{Delimiter: "A", Body: "a", Lines: 1}, {Delimiter: "B", Body: "b", Lines: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, docs := SplitHeredocs(tt.command)
+			if script != tt.wantScript {
+				t.Errorf("script = %q, want %q", script, tt.wantScript)
+			}
+			if len(docs) != len(tt.wantDocs) {
+				t.Fatalf("got %d heredocs, want %d: %+v", len(docs), len(tt.wantDocs), docs)
+			}
+			for i := range docs {
+				if docs[i] != tt.wantDocs[i] {
+					t.Errorf("heredoc %d = %+v, want %+v", i, docs[i], tt.wantDocs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractPatternMultiLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"heredoc body ignored", "cat <<EOF > /etc/hosts\nrm -rf /\nEOF", "Bash(cat:*)"},
+		{"commit message heredoc", "git commit -m \"$(cat <<'EOF'\nmsg\nEOF\n)\"", "Bash(git:commit:*)"},
+		{"leading comment and blank lines", "\n# build it\nmake build\nmake test", "Bash(make:build:*)"},
+		{"backslash continuation", "FOO=1 \\\n  go test \\\n  ./...", "Bash(go:test:*)"},
+		{"continued sudo", "sudo \\\n  systemctl restart nginx", "Bash(sudo:systemctl:restart:*)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractPattern("Bash", tt.input)
+			if result != tt.expected {
+				t.Errorf("ExtractPattern(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// HomeProjects is a user's Claude projects directory found under a home
+// directory glob
+type HomeProjects struct {
+	Dir  string // <home>/.claude/projects
+	User string // Owner of the home directory
+}
+
+// Origin returns the origin label for the user's sessions, e.g. "user:alice"
+func (h HomeProjects) Origin() string {
+	return "user:" + h.User
+}
+
+// FindHomeProjects expands home directory globs (e.g. /home/*) and returns
+// the .claude/projects directories under them, sorted and without
+// duplicates. Homes without a projects directory, or that can't be read, are
+// skipped; reading other users' homes usually needs a privileged account.
+func FindHomeProjects(globs []string) []HomeProjects {
+	var found []HomeProjects
+	for _, pattern := range globs {
+		homes, _ := filepath.Glob(pattern)
+		for _, home := range homes {
+			dir := filepath.Join(home, ".claude", "projects")
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+			if slices.ContainsFunc(found, func(h HomeProjects) bool { return h.Dir == dir }) {
+				continue
+			}
+			found = append(found, HomeProjects{Dir: dir, User: homeOwner(home)})
+		}
+	}
+	slices.SortFunc(found, func(a, b HomeProjects) int { return cmp.Compare(a.Dir, b.Dir) })
+	return found
+}
+
+// homeOwner returns the name of the user owning a home directory, falling
+// back to the directory's name when the owner can't be looked up
+func homeOwner(home string) string {
+	if name := fileOwner(home); name != "" {
+		return name
+	}
+	return filepath.Base(home)
+}
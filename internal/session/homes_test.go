@@ -0,0 +1,40 @@
+package session
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFindHomeProjects(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"alice/.claude/projects", "bob/.claude", "carol/.claude/projects"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// bob has no projects directory, and overlapping globs list alice once
+	found := FindHomeProjects([]string{filepath.Join(root, "*"), filepath.Join(root, "alice")})
+	if len(found) != 2 {
+		t.Fatalf("expected alice and carol, got %+v", found)
+	}
+	if found[0].Dir != filepath.Join(root, "alice", ".claude", "projects") ||
+		found[1].Dir != filepath.Join(root, "carol", ".claude", "projects") {
+		t.Errorf("unexpected dirs %+v", found)
+	}
+
+	// The homes belong to whoever runs the test, not to alice and carol
+	want := "alice"
+	if runtime.GOOS != "windows" {
+		if u, err := user.Current(); err == nil {
+			want = u.Username
+		}
+	}
+	if found[0].User != want || !strings.HasPrefix(found[0].Origin(), "user:") {
+		t.Errorf("expected the home owned by %s, got %+v (origin %s)", want, found[0], found[0].Origin())
+	}
+}
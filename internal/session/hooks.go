@@ -0,0 +1,264 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Hook outcomes
+const (
+	HookRan       = "ran"       // Finished without affecting the tool call
+	HookBlocked   = "blocked"   // Stopped the tool call (exit code 2 or a deny decision)
+	HookFailed    = "failed"    // Errored without blocking
+	HookModified  = "modified"  // Changed the tool input, decided its permission, or added context
+	HookCancelled = "cancelled" // Interrupted or timed out
+)
+
+// HookRun is a hook that fired around a tool call
+type HookRun struct {
+	Event      string // Hook event, e.g. PreToolUse or PostToolUse
+	Name       string // Hook name as recorded, e.g. PreToolUse:Bash
+	Command    string // The hook's command, when recorded
+	Outcome    string // HookRan, HookBlocked, ...
+	Detail     string // First line of the hook's reason, output, or added context
+	ToolUseID  string // tool_use ID of the command it fired for
+	LineNumber int
+}
+
+// Label describes the run, e.g. "PreToolUse [~/.claude/hooks/guard.sh] blocked: no force pushes"
+func (h HookRun) Label() string {
+	label := h.Event
+	if h.Command != "" {
+		label += " [" + h.Command + "]"
+	}
+	label += " " + h.Outcome
+	if h.Detail != "" {
+		label += ": " + h.Detail
+	}
+	return label
+}
+
+// hookRecord holds the fields of the JSONL records that report hooks: hook
+// attachments, and system messages written by older versions
+type hookRecord struct {
+	Type       string          `json:"type"`
+	Content    json.RawMessage `json:"content"`
+	ToolUseID  string          `json:"toolUseID"`
+	Attachment *struct {
+		Type          string          `json:"type"`
+		HookName      string          `json:"hookName"`
+		HookEvent     string          `json:"hookEvent"`
+		ToolUseID     string          `json:"toolUseID"`
+		Command       string          `json:"command"`
+		Content       json.RawMessage `json:"content"`
+		Stderr        string          `json:"stderr"`
+		Decision      string          `json:"decision"`
+		Reason        string          `json:"reason"`
+		BlockingError json.RawMessage `json:"blockingError"`
+		UpdatedInput  json.RawMessage `json:"updatedInput"`
+	} `json:"attachment"`
+}
+
+// hookHints are substrings of every line that can report a hook, checked
+// before decoding
+var hookHints = [][]byte{[]byte(`"hook_`), []byte(`"hookName"`), []byte(`ToolUse:`)}
+
+// attachmentOutcomes maps hook attachment types to outcomes
+var attachmentOutcomes = map[string]string{
+	"hook_success":                HookRan,
+	"hook_blocking_error":         HookBlocked,
+	"hook_non_blocking_error":     HookFailed,
+	"hook_error_during_execution": HookFailed,
+	"hook_cancelled":              HookCancelled,
+	"hook_additional_context":     HookModified,
+	"hook_permission_decision":    HookModified,
+	"hook_stopped_continuation":   HookBlocked,
+}
+
+// hookSystemMessage matches the system messages older versions write for
+// hooks, e.g. "PostToolUse:Edit [prettier --write] completed successfully"
+var hookSystemMessage = regexp.MustCompile(`^((\w+):\S+) \[(.*?)\] (.+)$`)
+
+// hookResultError matches the error a blocking hook leaves as the tool
+// result, e.g. "PreToolUse:Bash hook error: [~/guard.sh]: No force pushes"
+var hookResultError = regexp.MustCompile(`^((\w+):\S+) hook (?:blocking )?error: \[(.*?)\]: ?(.*)`)
+
+// captureHook records a hook reported on line against the command it fired for
+func (ps *parseState) captureHook(line []byte) {
+	hinted := false
+	for _, hint := range hookHints {
+		if bytes.Contains(line, hint) {
+			hinted = true
+			break
+		}
+	}
+	if !hinted {
+		return
+	}
+
+	var rec hookRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return
+	}
+	run := HookRun{LineNumber: ps.lineNumber}
+	switch a := rec.Attachment; {
+	case rec.Type == "attachment" && a != nil && strings.HasPrefix(a.Type, "hook_"):
+		run.Name, run.Event, run.Command, run.ToolUseID = a.HookName, a.HookEvent, a.Command, a.ToolUseID
+		run.Outcome = attachmentOutcomes[a.Type]
+		if run.Outcome == "" {
+			run.Outcome = HookRan
+		}
+		switch {
+		case a.Type == "hook_permission_decision" && a.Decision == "deny":
+			run.Outcome = HookBlocked
+		case len(a.UpdatedInput) > 0 && string(a.UpdatedInput) != "null":
+			run.Outcome = HookModified
+		}
+		var blocking struct {
+			BlockingError string `json:"blockingError"`
+			Command       string `json:"command"`
+		}
+		if json.Unmarshal(a.BlockingError, &blocking) == nil {
+			run.Detail = blocking.BlockingError
+			if run.Command == "" {
+				run.Command = blocking.Command
+			}
+		} else {
+			_ = json.Unmarshal(a.BlockingError, &run.Detail)
+		}
+		for _, detail := range []string{a.Reason, a.Stderr, jsonText(a.Content)} {
+			if run.Detail == "" {
+				run.Detail = detail
+			}
+		}
+	case rec.Type == "system" && rec.ToolUseID != "":
+		m := hookSystemMessage.FindStringSubmatch(jsonText(rec.Content))
+		if m == nil {
+			return
+		}
+		run.Name, run.Event, run.Command, run.ToolUseID = m[1], m[2], m[3], rec.ToolUseID
+		status := strings.ToLower(m[4])
+		switch {
+		case strings.Contains(status, "blocking"):
+			run.Outcome = HookBlocked
+		case strings.Contains(status, "fail"), strings.Contains(status, "error"):
+			run.Outcome = HookFailed
+		case strings.Contains(status, "cancel"):
+			run.Outcome = HookCancelled
+		default:
+			run.Outcome = HookRan
+		}
+		if _, detail, ok := strings.Cut(m[4], ": "); ok {
+			run.Detail = detail
+		}
+	default:
+		return
+	}
+	if run.Event == "" {
+		run.Event, _, _ = strings.Cut(run.Name, ":")
+	}
+	run.Detail = firstLine(run.Detail)
+	ps.addHook(run)
+}
+
+// captureBlockedResults records the hooks named by failed tool results: a
+// blocking hook's error replaces the result of the call it blocked
+func (ps *parseState) captureBlockedResults(msg *Message) {
+	for _, content := range msg.Content {
+		if content.Type != "tool_result" || !content.IsError || !bytes.Contains(content.Content, []byte("hook")) {
+			continue
+		}
+		text, _ := extractResult(content.Content)
+		m := hookResultError.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		ps.addHook(HookRun{Name: m[1], Event: m[2], Command: m[3], Outcome: HookBlocked,
+			Detail: firstLine(m[4]), ToolUseID: content.ToolUseID, LineNumber: ps.lineNumber})
+	}
+}
+
+// addHook attaches a hook run to its command, or leaves it in meta.Hooks
+// when the command was parsed in an earlier pass
+func (ps *parseState) addHook(run HookRun) {
+	if run.ToolUseID == "" {
+		return // Hooks outside tool calls (prompts, session start, stop)
+	}
+	if idx, ok := ps.toolUses[run.ToolUseID]; ok {
+		ps.commands[idx].addHook(run)
+	} else {
+		ps.meta.Hooks = append(ps.meta.Hooks, run)
+	}
+}
+
+// ApplyHooks attaches hook runs left in SessionMetadata.Hooks to the
+// commands they fired for, reporting whether any command changed
+func ApplyHooks(commands []CommandEntry, runs []HookRun) bool {
+	var found bool
+	for _, run := range runs {
+		for i := len(commands) - 1; i >= 0; i-- {
+			if commands[i].ToolUseID == run.ToolUseID {
+				commands[i].addHook(run)
+				found = true
+				break
+			}
+		}
+	}
+	return found
+}
+
+// addHook records a hook run. A hook reported twice (a blocking hook as an
+// attachment and again in the result it replaced) is kept once.
+func (c *CommandEntry) addHook(run HookRun) {
+	for i := range c.Hooks {
+		h := &c.Hooks[i]
+		if h.Name != run.Name || h.Outcome != run.Outcome || (h.Command != "" && run.Command != "" && h.Command != run.Command) {
+			continue
+		}
+		if h.Detail == "" {
+			h.Detail = run.Detail
+		}
+		if h.Command == "" {
+			h.Command = run.Command
+		}
+		return
+	}
+	c.Hooks = append(c.Hooks, run)
+}
+
+// HookOutcome returns the most consequential outcome of the hooks that fired
+// for c (blocked, then modified, failed, cancelled), or "" if none affected it
+func (c *CommandEntry) HookOutcome() string {
+	for _, outcome := range []string{HookBlocked, HookModified, HookFailed, HookCancelled} {
+		for _, h := range c.Hooks {
+			if h.Outcome == outcome {
+				return outcome
+			}
+		}
+	}
+	return ""
+}
+
+// jsonText returns a JSON string's value, or the lines of a list of
+// strings, or "" for other JSON
+func jsonText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var lines []string
+	_ = json.Unmarshal(raw, &lines)
+	return strings.Join(lines, "\n")
+}
+
+// firstLine returns the first non-empty line of s, trimmed
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
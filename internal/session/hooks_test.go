@@ -0,0 +1,70 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseHooks(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","uuid":"a1","timestamp":"2025-01-02T10:00:00Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"git push --force"}}]}}`,
+		`{"type":"attachment","uuid":"h1","timestamp":"2025-01-02T10:00:01Z","attachment":{"type":"hook_blocking_error","hookName":"PreToolUse:Bash","hookEvent":"PreToolUse","toolUseID":"t1","blockingError":{"blockingError":"No force pushes\nuse --force-with-lease","command":"~/.claude/hooks/guard.sh"}}}`,
+		`{"type":"user","uuid":"u1","timestamp":"2025-01-02T10:00:01Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","is_error":true,"content":"PreToolUse:Bash hook error: [~/.claude/hooks/guard.sh]: No force pushes"}]}}`,
+		`{"type":"assistant","uuid":"a2","timestamp":"2025-01-02T10:00:05Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"/code/app/main.go"}}]}}`,
+		`{"type":"system","uuid":"s1","timestamp":"2025-01-02T10:00:06Z","toolUseID":"t2","level":"info","content":"PostToolUse:Edit [gofmt -w] completed successfully"}`,
+		`{"type":"attachment","uuid":"h2","timestamp":"2025-01-02T10:00:06Z","attachment":{"type":"hook_additional_context","hookName":"PostToolUse:Edit","toolUseID":"t2","content":["lint: 2 warnings"]}}`,
+		`{"type":"attachment","uuid":"h3","timestamp":"2025-01-02T10:00:07Z","attachment":{"type":"hook_success","hookName":"Stop","hookEvent":"Stop"}}`,
+	}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, _, err := ParseSessionFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 2 {
+		t.Fatalf("expected 2 commands, got %d", len(commands))
+	}
+
+	push := commands[0]
+	if len(push.Hooks) != 1 || push.HookOutcome() != HookBlocked {
+		t.Fatalf("expected one blocking hook on the push (the result repeats it), got %+v", push.Hooks)
+	}
+	if got, want := push.Hooks[0].Label(), "PreToolUse [~/.claude/hooks/guard.sh] blocked: No force pushes"; got != want {
+		t.Errorf("label = %q, want %q", got, want)
+	}
+
+	edit := commands[1]
+	if len(edit.Hooks) != 2 || edit.Hooks[0].Outcome != HookRan || edit.Hooks[0].Command != "gofmt -w" {
+		t.Fatalf("expected the gofmt run and the added context on the edit, got %+v", edit.Hooks)
+	}
+	if edit.HookOutcome() != HookModified || edit.Hooks[1].Detail != "lint: 2 warnings" {
+		t.Errorf("edit outcome = %q (%+v), want modified with the context", edit.HookOutcome(), edit.Hooks[1])
+	}
+}
+
+func TestParseHooksIncremental(t *testing.T) {
+	first := `{"type":"assistant","uuid":"a1","timestamp":"2025-01-02T10:00:00Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"make"}}]}}` + "\n"
+	second := `{"type":"attachment","uuid":"h1","timestamp":"2025-01-02T10:00:01Z","attachment":{"type":"hook_non_blocking_error","hookName":"PreToolUse:Bash","toolUseID":"t1","stderr":"jq: not found"}}` + "\n"
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(first+second), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, _, _, _, err := ParseSessionFileFrom(path, 0, 0)
+	if err != nil || len(commands) != 1 {
+		t.Fatalf("expected one command, got %d (%v)", len(commands), err)
+	}
+	commands[0].Hooks = nil
+	_, meta, _, _, err := ParseSessionFileFrom(path, int64(len(first)), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ApplyHooks(commands, meta.Hooks) || commands[0].HookOutcome() != HookFailed || commands[0].Hooks[0].Detail != "jq: not found" {
+		t.Errorf("expected the failed hook applied to the earlier command, got %+v", commands[0].Hooks)
+	}
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileHotspot is how often the sessions read, edited, and wrote one file
+type FileHotspot struct {
+	Path        string    // Absolute path of the file
+	ProjectPath string    // Project of the session that touched it last
+	Reads       int       // Read calls
+	Edits       int       // Edit and NotebookEdit calls
+	Writes      int       // Write calls (creating or replacing the file)
+	Sessions    int       // Sessions that touched it
+	LastTouched time.Time // Most recent call
+}
+
+// Changes returns the number of edits and writes
+func (h *FileHotspot) Changes() int {
+	return h.Edits + h.Writes
+}
+
+// hotspotTools are the tools whose input is the path of the file they touch
+var hotspotTools = map[string]bool{"Read": true, "Edit": true, "Write": true, "NotebookEdit": true}
+
+// FileHotspots counts the Read, Edit, and Write calls of all sessions per
+// file, most changed first (then most touched, then by path). Relative paths
+// are resolved against the call's working directory.
+func FileHotspots(sessions []*Session) []*FileHotspot {
+	spots := make(map[string]*FileHotspot)
+	for _, sess := range sessions {
+		seen := make(map[string]bool)
+		for i := range sess.Commands {
+			cmd := &sess.Commands[i]
+			path := cmd.RawCommand
+			if !hotspotTools[cmd.ToolName] || path == "" {
+				continue
+			}
+			if !filepath.IsAbs(path) && cmd.CWD != "" {
+				path = filepath.Join(cmd.CWD, path)
+			}
+			path = filepath.Clean(path)
+
+			h, ok := spots[path]
+			if !ok {
+				h = &FileHotspot{Path: path}
+				spots[path] = h
+			}
+			switch cmd.ToolName {
+			case "Read":
+				h.Reads++
+			case "Write":
+				h.Writes++
+			default:
+				h.Edits++
+			}
+			if !cmd.Timestamp.Before(h.LastTouched) {
+				h.LastTouched = cmd.Timestamp
+				h.ProjectPath = sess.ProjectPath
+			}
+			if !seen[path] {
+				seen[path] = true
+				h.Sessions++
+			}
+		}
+	}
+
+	sorted := make([]*FileHotspot, 0, len(spots))
+	for _, h := range spots {
+		sorted = append(sorted, h)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Changes() != b.Changes() {
+			return a.Changes() > b.Changes()
+		}
+		if a.Reads != b.Reads {
+			return a.Reads > b.Reads
+		}
+		return a.Path < b.Path
+	})
+	return sorted
+}
@@ -0,0 +1,37 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileHotspots(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	first := &Session{ProjectPath: "/code/app", Commands: []CommandEntry{
+		{ToolName: "Read", RawCommand: "/code/app/model.go", Timestamp: at},
+		{ToolName: "Edit", RawCommand: "/code/app/model.go", Timestamp: at.Add(time.Minute)},
+		{ToolName: "Edit", RawCommand: "model.go", CWD: "/code/app", Timestamp: at.Add(2 * time.Minute)},
+		{ToolName: "Read", RawCommand: "/code/app/README.md", Timestamp: at},
+		{ToolName: "Read", RawCommand: "/code/app/README.md", Timestamp: at},
+		{ToolName: "Bash", RawCommand: "cat /code/app/model.go", Timestamp: at},
+	}}
+	second := &Session{ProjectPath: "/code/app", Commands: []CommandEntry{
+		{ToolName: "Write", RawCommand: "/code/app/model.go", Timestamp: at.Add(time.Hour)},
+		{ToolName: "Write", RawCommand: "/code/app/new.go", Timestamp: at},
+	}}
+
+	spots := FileHotspots([]*Session{first, second})
+	if len(spots) != 3 {
+		t.Fatalf("got %d files, want 3", len(spots))
+	}
+	model := spots[0]
+	if model.Path != "/code/app/model.go" || model.Reads != 1 || model.Edits != 2 || model.Writes != 1 || model.Sessions != 2 {
+		t.Errorf("top hotspot = %+v, want model.go read once, edited twice, written once, in 2 sessions", model)
+	}
+	if !model.LastTouched.Equal(at.Add(time.Hour)) {
+		t.Errorf("model.go last touched %v, want an hour in", model.LastTouched)
+	}
+	if spots[1].Path != "/code/app/new.go" || spots[2].Path != "/code/app/README.md" {
+		t.Errorf("order = %s, %s; want new.go (changed) before README.md (only read)", spots[1].Path, spots[2].Path)
+	}
+}
@@ -0,0 +1,244 @@
+package session
+
+import "strings"
+
+// Pattern markers for interpreters run with inline code or a script read from stdin
+const (
+	inlineCodeMarker = "inline-code"
+	stdinMarker      = "stdin"
+)
+
+// interpreterInlineFlags lists, per interpreter, the flags whose argument is code to run
+var interpreterInlineFlags = map[string][]string{
+	"python":  {"-c"},
+	"python2": {"-c"},
+	"python3": {"-c"},
+	"node":    {"-e", "--eval", "-p", "--print"},
+	"bun":     {"-e", "--eval", "-p", "--print"},
+	"ruby":    {"-e"},
+	"perl":    {"-e", "-E"},
+	"php":     {"-r"},
+}
+
+// Interpreter describes an interpreter invocation in a Bash command
+type Interpreter struct {
+	Name   string // Interpreter command (python3, node, ...)
+	Script string // Script path, module (python -m), or "-" for stdin; empty for inline code
+	Code   string // Inline code passed with -c/-e, empty otherwise
+}
+
+// isInterpreter returns true if cmd is a known script interpreter
+func isInterpreter(cmd string) bool {
+	_, ok := interpreterInlineFlags[cmd]
+	return ok
+}
+
+// ParseInterpreter reports whether command runs an interpreter and, if so,
+// which script or inline code. Quotes are honored so multi-line inline code
+// is returned intact.
+func ParseInterpreter(command string) (Interpreter, bool) {
+	words := skipEnvVars(shellWords(command))
+	if len(words) > 0 && words[0] == "sudo" {
+		words = skipFlags("sudo", words[1:])
+	}
+	words = unwrapCommand(words)
+	if len(words) == 0 || !isInterpreter(words[0]) {
+		return Interpreter{}, false
+	}
+
+	interp := Interpreter{Name: words[0]}
+	interp.Script, interp.Code = interpreterTarget(words[0], words[1:])
+	return interp, true
+}
+
+// interpreterTarget finds the script (or module) an interpreter runs, or the
+// inline code given with an inline flag. Other flags are skipped.
+func interpreterTarget(cmd string, args []string) (script, code string) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "-":
+			return "-", ""
+		case a == "-m" && strings.HasPrefix(cmd, "python") && i+1 < len(args):
+			return args[i+1], ""
+		case isInlineFlag(cmd, a):
+			if i+1 < len(args) {
+				return "", args[i+1]
+			}
+			return "", ""
+		case strings.HasPrefix(a, "-"):
+			if TakesArg(cmd, a) {
+				i++
+			}
+		default:
+			return a, ""
+		}
+	}
+	return "", ""
+}
+
+// isInlineFlag returns true if flag introduces inline code for cmd
+func isInlineFlag(cmd, flag string) bool {
+	for _, f := range interpreterInlineFlags[cmd] {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// interpreterParts returns the pattern parts after the interpreter name:
+// the script or module, "inline-code", "stdin", or nothing for a bare REPL
+func interpreterParts(cmd string, args []string) []string {
+	script, code := interpreterTarget(cmd, args)
+	switch {
+	case code != "":
+		return []string{inlineCodeMarker}
+	case script == "":
+		return nil
+	case script == "-":
+		return []string{stdinMarker}
+	default:
+		return []string{script}
+	}
+}
+
+// shellWords splits a command into words like a POSIX shell would, honoring
+// single quotes, double quotes, and backslash escapes. Newlines separate
+// words. Expansions and operators are left as literal text.
+func shellWords(s string) []string {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	var quote rune
+
+	for i := 0; i < len(s); i++ {
+		c := rune(s[i])
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				cur.WriteByte(s[i])
+			}
+		case quote == '"':
+			switch {
+			case c == '"':
+				quote = 0
+			case c == '\\' && i+1 < len(s) && strings.ContainsRune("\"\\$`", rune(s[i+1])):
+				i++
+				cur.WriteByte(s[i])
+			default:
+				cur.WriteByte(s[i])
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == '\\' && i+1 < len(s):
+			i++
+			if s[i] != '\n' { // Backslash-newline is a line continuation
+				cur.WriteByte(s[i])
+				inWord = true
+			}
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteByte(s[i])
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words
+}
+
+// shellOperators separate commands in a command line
+var shellOperators = map[string]bool{"&&": true, "||": true, ";": true, "|": true, "&": true}
+
+// ExecutedScripts returns the script files a command line runs: "bash x.sh",
+// "source x.sh", "./x.sh", "/tmp/x.sh", or an interpreter script like
+// "python3 x.py". Paths are returned as written (possibly relative).
+func ExecutedScripts(command string) []string {
+	var scripts []string
+	for _, words := range SimpleCommands(command) {
+		if script := segmentScript(words); script != "" {
+			scripts = append(scripts, script)
+		}
+	}
+	return scripts
+}
+
+// SimpleCommands splits a command line into its simple commands at shell
+// operators, returning each as words with leading environment assignments,
+// sudo, and command wrappers (env, time, configured wrappers) stripped.
+// Empty commands are dropped; a wrapper with no command is kept as is.
+func SimpleCommands(command string) [][]string {
+	var commands [][]string
+	for _, segment := range splitSegments(shellWords(command)) {
+		words := skipEnvVars(segment)
+		if len(words) > 0 && words[0] == "sudo" {
+			words = skipFlags("sudo", words[1:])
+		}
+		// A wrapper with nothing to run (a bare "env") is the command itself
+		if unwrapped := unwrapCommand(words); len(unwrapped) > 0 {
+			words = unwrapped
+		}
+		if len(words) > 0 {
+			commands = append(commands, words)
+		}
+	}
+	return commands
+}
+
+// splitSegments splits words into separate commands at shell operators
+func splitSegments(words []string) [][]string {
+	var segments [][]string
+	var cur []string
+	for _, w := range words {
+		if shellOperators[w] {
+			segments = append(segments, cur)
+			cur = nil
+			continue
+		}
+		if trimmed, ok := strings.CutSuffix(w, ";"); ok && trimmed != "" {
+			cur = append(cur, trimmed)
+			segments = append(segments, cur)
+			cur = nil
+			continue
+		}
+		cur = append(cur, w)
+	}
+	return append(segments, cur)
+}
+
+// segmentScript returns the script a simple command runs, or ""
+func segmentScript(words []string) string {
+	cmd := words[0]
+	switch {
+	case cmd == "source" || cmd == ".":
+		if len(words) > 1 {
+			return words[1]
+		}
+	case isShell(cmd):
+		for _, a := range words[1:] {
+			if a == "-c" {
+				return ""
+			}
+			if !strings.HasPrefix(a, "-") {
+				return a
+			}
+		}
+	case isInterpreter(cmd):
+		if script, _ := interpreterTarget(cmd, words[1:]); script != "-" && strings.Contains(script, ".") {
+			return script
+		}
+	case strings.Contains(cmd, "/"):
+		return cmd
+	}
+	return ""
+}
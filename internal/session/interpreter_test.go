@@ -0,0 +1,111 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractPatternInterpreters(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"python script", "python build.py --release", "Bash(python:build.py:*)"},
+		{"python3 script with path", "python3 scripts/gen.py", "Bash(python3:scripts/gen.py:*)"},
+		{"python inline code", `python3 -c "import os; print(os.getcwd())"`, "Bash(python3:inline-code:*)"},
+		{"python module", "python3 -m pytest -x tests/", "Bash(python3:pytest:*)"},
+		{"python flag with arg", "python3 -W ignore run.py", "Bash(python3:run.py:*)"},
+		{"python stdin heredoc", "python3 - <<EOF\nprint(1)\nEOF", "Bash(python3:stdin:*)"},
+		{"python repl", "python3", "Bash(python3:*)"},
+		{"node eval", `node -e "console.log(1)"`, "Bash(node:inline-code:*)"},
+		{"node script", "node server.js", "Bash(node:server.js:*)"},
+		{"ruby inline", `ruby -e 'puts 1'`, "Bash(ruby:inline-code:*)"},
+		{"perl inline", `perl -E 'say 1'`, "Bash(perl:inline-code:*)"},
+		{"sudo python", "sudo python3 fix.py", "Bash(sudo:python3:fix.py:*)"},
+		{"multi-line inline code", "python3 -c \"\nimport os\nos.remove('x')\n\"", "Bash(python3:inline-code:*)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractPattern("Bash", tt.input)
+			if result != tt.expected {
+				t.Errorf("ExtractPattern(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseInterpreter(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    Interpreter
+		wantOK  bool
+	}{
+		{"not an interpreter", "ls -la", Interpreter{}, false},
+		{"script", "python3 gen.py a b", Interpreter{Name: "python3", Script: "gen.py"}, true},
+		{
+			"double-quoted multi-line code",
+			"python3 -c \"\nimport os\nprint(\\\"hi\\\")\n\"",
+			Interpreter{Name: "python3", Code: "\nimport os\nprint(\"hi\")\n"},
+			true,
+		},
+		{"single-quoted code", `node -e 'console.log("x")'`, Interpreter{Name: "node", Code: `console.log("x")`}, true},
+		{"env and wrapper", "FOO=1 time ruby -e 'puts 1'", Interpreter{Name: "ruby", Code: "puts 1"}, true},
+		{"stdin", "python3 - <<EOF", Interpreter{Name: "python3", Script: "-"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseInterpreter(tt.command)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseInterpreter(%q) = %+v, %v; want %+v, %v", tt.command, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestShellWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"a b  c", []string{"a", "b", "c"}},
+		{`echo "a b" 'c d'`, []string{"echo", "a b", "c d"}},
+		{`echo "say \"hi\"" it\'s`, []string{"echo", `say "hi"`, "it's"}},
+		{"a \\\nb", []string{"a", "b"}},
+		{`x=""`, []string{"x="}},
+		{`""`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		if got := shellWords(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("shellWords(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExecutedScripts(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"ls -la", nil},
+		{"bash deploy.sh --prod", []string{"deploy.sh"}},
+		{"sh -x ./run.sh", []string{"./run.sh"}},
+		{"bash -c 'echo hi'", nil},
+		{"chmod +x fix.sh && ./fix.sh", []string{"./fix.sh"}},
+		{"source env.sh; /tmp/cleanup.sh", []string{"env.sh", "/tmp/cleanup.sh"}},
+		{". ./env.sh", []string{"./env.sh"}},
+		{"sudo python3 /opt/tool.py", []string{"/opt/tool.py"}},
+		{"python3 -m pytest", nil},
+		{"cat x | scripts/filter.sh", []string{"scripts/filter.sh"}},
+	}
+
+	for _, tt := range tests {
+		if got := ExecutedScripts(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExecutedScripts(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package session
+
+import "slices"
+
+// Lineage maps each resumed session to the session it resumes
+type Lineage map[*Session]*Session
+
+// NewLineage links the sessions that resume one another. A session resumes
+// another in the same project when one of its summary records names the
+// other's last message, or when it starts with the same message (resuming
+// copies the earlier conversation); of several such sessions, the one that
+// started most recently before it is its predecessor.
+func NewLineage(sessions []*Session) Lineage {
+	l := make(Lineage)
+	for _, s := range sessions {
+		if p := predecessor(sessions, s); p != nil {
+			l[s] = p
+		}
+	}
+	return l
+}
+
+// predecessor returns the session s resumes, or nil
+func predecessor(sessions []*Session, s *Session) *Session {
+	var best *Session
+	for _, other := range sessions {
+		if other == s || other.ProjectPath != s.ProjectPath {
+			continue
+		}
+		if other.LastUUID != "" && slices.Contains(s.ResumedFrom, other.LastUUID) {
+			return other
+		}
+		if s.RootUUID == "" || other.RootUUID != s.RootUUID || !startedBefore(other, s) {
+			continue
+		}
+		if best == nil || startedBefore(best, other) {
+			best = other
+		}
+	}
+	return best
+}
+
+// startedBefore orders sessions by start time, then ID, so sessions sharing
+// a start time still form a chain rather than a cycle
+func startedBefore(a, b *Session) bool {
+	if !a.StartedAt.Equal(b.StartedAt) {
+		return a.StartedAt.Before(b.StartedAt)
+	}
+	return a.ID < b.ID
+}
+
+// Chain returns the sessions linked to s by resumption, oldest first: its
+// predecessors, s, and the sessions that resumed it in turn (the most
+// recent, where a session was resumed more than once)
+func (l Lineage) Chain(s *Session) []*Session {
+	chain := []*Session{s}
+	seen := map[*Session]bool{s: true}
+	for p := l[s]; p != nil && !seen[p]; p = l[p] {
+		chain = append([]*Session{p}, chain...)
+		seen[p] = true
+	}
+	for cur := s; ; {
+		var next *Session
+		for succ, pred := range l {
+			if pred == cur && !seen[succ] && (next == nil || startedBefore(next, succ)) {
+				next = succ
+			}
+		}
+		if next == nil {
+			return chain
+		}
+		chain = append(chain, next)
+		seen[next] = true
+		cur = next
+	}
+}
+
+// ChainCommands returns the commands of a chain's sessions, dropping the
+// copies a resumed session holds of its predecessor's commands
+func ChainCommands(chain []*Session) []CommandEntry {
+	var commands []CommandEntry
+	seen := make(map[string]bool)
+	for _, s := range chain {
+		for i := range s.Commands {
+			key := s.Commands[i].UUID + s.Commands[i].ToolName
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			commands = append(commands, s.Commands[i])
+		}
+	}
+	return commands
+}
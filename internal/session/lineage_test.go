@@ -0,0 +1,43 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLineageChain(t *testing.T) {
+	start := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	original := &Session{ID: "a", ProjectPath: "/p", RootUUID: "r1", LastUUID: "a9", StartedAt: start,
+		Commands: []CommandEntry{{UUID: "c1", ToolName: "Bash"}}}
+	// Resuming copies the conversation, so it starts with the same message
+	resumed := &Session{ID: "b", ProjectPath: "/p", RootUUID: "r1", LastUUID: "b9", StartedAt: start.Add(time.Hour),
+		Commands: []CommandEntry{{UUID: "c1", ToolName: "Bash"}, {UUID: "c2", ToolName: "Edit"}}}
+	// A summary record names the last message of the conversation it continues
+	continued := &Session{ID: "c", ProjectPath: "/p", RootUUID: "r2", ResumedFrom: []string{"b9"}, StartedAt: start.Add(2 * time.Hour),
+		Commands: []CommandEntry{{UUID: "c3", ToolName: "Bash"}}}
+	elsewhere := &Session{ID: "d", ProjectPath: "/q", RootUUID: "r1", StartedAt: start.Add(3 * time.Hour)}
+
+	lineage := NewLineage([]*Session{continued, elsewhere, resumed, original})
+	for _, s := range []*Session{original, resumed, continued} {
+		chain := lineage.Chain(s)
+		if len(chain) != 3 || chain[0] != original || chain[1] != resumed || chain[2] != continued {
+			t.Errorf("Chain(%s) = %v", s.ID, chainIDs(chain))
+		}
+	}
+	if chain := lineage.Chain(elsewhere); len(chain) != 1 {
+		t.Errorf("expected a session in another project to stand alone, got %v", chainIDs(chain))
+	}
+
+	commands := ChainCommands(lineage.Chain(original))
+	if len(commands) != 3 {
+		t.Errorf("expected the copied command to be dropped, got %d commands", len(commands))
+	}
+}
+
+func chainIDs(chain []*Session) []string {
+	ids := make([]string, len(chain))
+	for i, s := range chain {
+		ids[i] = s.ID
+	}
+	return ids
+}
@@ -0,0 +1,109 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Marker kinds
+const (
+	MarkerCompaction = "compaction" // The context was summarized to free space
+	MarkerRestart    = "restart"    // A new conversation began in the same session file
+)
+
+// Marker is a point in a session where the agent's context changed; the
+// commands after it may behave differently from those before
+type Marker struct {
+	Kind       string // MarkerCompaction or MarkerRestart
+	Detail     string // e.g. "auto, 156k tokens"
+	Timestamp  time.Time
+	LineNumber int
+}
+
+// Label describes the marker for a divider row, e.g. "context compacted (auto, 156k tokens)"
+func (m Marker) Label() string {
+	label := "context compacted"
+	if m.Kind == MarkerRestart {
+		label = "conversation restarted"
+	}
+	if m.Detail != "" {
+		label += " (" + m.Detail + ")"
+	}
+	return label
+}
+
+// markerRecord holds the fields of a JSONL record that mark context changes.
+// It is decoded separately, only for lines that can hold a marker.
+type markerRecord struct {
+	Type             string          `json:"type"`
+	Subtype          string          `json:"subtype"`
+	Timestamp        string          `json:"timestamp"`
+	ParentUUID       json.RawMessage `json:"parentUuid"`
+	IsSidechain      bool            `json:"isSidechain"`
+	IsCompactSummary bool            `json:"isCompactSummary"`
+	CompactMetadata  *struct {
+		Trigger   string `json:"trigger"`
+		PreTokens int    `json:"preTokens"`
+	} `json:"compactMetadata"`
+}
+
+// markerHints are substrings of every line that can hold a marker, checked
+// before decoding
+var markerHints = [][]byte{[]byte(`"parentUuid":null`), []byte(`compact_boundary`), []byte(`"isCompactSummary":true`)}
+
+// captureMarker records a compaction boundary or conversation restart on line
+func (ps *parseState) captureMarker(line []byte) {
+	hinted := false
+	for _, hint := range markerHints {
+		if bytes.Contains(line, hint) {
+			hinted = true
+			break
+		}
+	}
+	if !hinted {
+		return
+	}
+
+	var rec markerRecord
+	if err := json.Unmarshal(line, &rec); err != nil || rec.IsSidechain {
+		return
+	}
+	marker := Marker{LineNumber: ps.lineNumber}
+	if t, err := time.Parse(time.RFC3339, rec.Timestamp); err == nil {
+		marker.Timestamp = t
+	}
+
+	switch {
+	case rec.Type == "system" && rec.Subtype == "compact_boundary":
+		marker.Kind = MarkerCompaction
+		if md := rec.CompactMetadata; md != nil {
+			var details []string
+			if md.Trigger != "" {
+				details = append(details, md.Trigger)
+			}
+			if md.PreTokens > 0 {
+				details = append(details, fmt.Sprintf("%dk tokens", (md.PreTokens+500)/1000))
+			}
+			marker.Detail = strings.Join(details, ", ")
+		}
+		ps.compacted = true
+	case rec.IsCompactSummary:
+		// Older sessions mark compaction only with the summary message
+		if !ps.compacted {
+			marker.Kind = MarkerCompaction
+		}
+		ps.compacted = false
+	case string(rec.ParentUUID) == "null" && (rec.Type == "user" || rec.Type == "assistant"):
+		if ps.conversing && !ps.compacted {
+			marker.Kind = MarkerRestart
+		}
+		ps.compacted = false
+		ps.conversing = true
+	}
+	if marker.Kind != "" {
+		ps.meta.Markers = append(ps.meta.Markers, marker)
+	}
+}
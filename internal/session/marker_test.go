@@ -0,0 +1,42 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMarkers(t *testing.T) {
+	lines := []string{
+		`{"type":"user","parentUuid":null,"uuid":"u1","timestamp":"2025-01-02T10:00:00Z","message":{"role":"user","content":"fix the build"}}`,
+		`{"type":"assistant","parentUuid":"u1","uuid":"u2","timestamp":"2025-01-02T10:00:05Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"make"}}]}}`,
+		`{"type":"user","parentUuid":null,"isSidechain":true,"uuid":"s1","timestamp":"2025-01-02T10:01:00Z","message":{"role":"user","content":"subagent task"}}`,
+		`{"type":"system","subtype":"compact_boundary","parentUuid":null,"uuid":"c1","timestamp":"2025-01-02T11:00:00Z","content":"Conversation compacted","compactMetadata":{"trigger":"auto","preTokens":155800}}`,
+		`{"type":"user","parentUuid":"c1","isCompactSummary":true,"uuid":"c2","timestamp":"2025-01-02T11:00:01Z","message":{"role":"user","content":"This session is being continued..."}}`,
+		`{"type":"user","parentUuid":null,"uuid":"r1","timestamp":"2025-01-02T12:00:00Z","message":{"role":"user","content":"start over"}}`,
+	}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, meta, err := ParseSessionFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meta.Markers) != 2 {
+		t.Fatalf("expected a compaction and a restart, got %+v", meta.Markers)
+	}
+	if m := meta.Markers[0]; m.Kind != MarkerCompaction || m.LineNumber != 4 || m.Label() != "context compacted (auto, 156k tokens)" {
+		t.Errorf("unexpected compaction marker: %+v", m)
+	}
+	if m := meta.Markers[1]; m.Kind != MarkerRestart || m.LineNumber != 6 {
+		t.Errorf("unexpected restart marker: %+v", m)
+	}
+
+	// Typed prompts (string content) count as messages for lineage too
+	if meta.RootUUID != "u1" || meta.LastUUID != "r1" || meta.StartedAt.IsZero() {
+		t.Errorf("unexpected lineage: root %q, last %q, started %v", meta.RootUUID, meta.LastUUID, meta.StartedAt)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows || plan9
+
+package session
+
+// fileOwner reports no owner on platforms without Unix file ownership, so
+// homes are named after their directory
+func fileOwner(string) string {
+	return ""
+}
@@ -2,11 +2,15 @@ package session
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
+
+	"cc_session_mon/internal/applog"
 )
 
 // JSONLRecord represents a single line in the session file
@@ -18,12 +22,38 @@ type JSONLRecord struct {
 	GitBranch string   `json:"gitBranch"`
 	CWD       string   `json:"cwd"`
 	Message   *Message `json:"message,omitempty"`
+
+	// Lineage: summary records name the last message of the conversation a
+	// resumed session continues; sidechain records belong to a subagent
+	LeafUUID    string `json:"leafUuid"`
+	IsSidechain bool   `json:"isSidechain"`
 }
 
 // Message represents the message field in a JSONL record
 type Message struct {
-	Role    string        `json:"role"`
-	Content []ContentItem `json:"content"`
+	Role    string   `json:"role"`
+	Content Contents `json:"content"`
+}
+
+// Contents is a message's content items
+type Contents []ContentItem
+
+// UnmarshalJSON accepts content as an array of items or, as in prompts the
+// user typed, a plain string (which becomes a single text item)
+func (c *Contents) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*c = Contents{{Type: "text", Text: text}}
+		return nil
+	}
+	if string(data) == "null" {
+		*c = nil
+		return nil
+	}
+	return json.Unmarshal(data, (*[]ContentItem)(c))
 }
 
 // ContentItem represents an item in the content array
@@ -31,9 +61,12 @@ type ContentItem struct {
 	Type      string          `json:"type"`
 	Name      string          `json:"name,omitempty"`
 	Input     json.RawMessage `json:"input,omitempty"`
-	ID        string          `json:"id,omitempty"`         // tool_use ID
+	ID        string          `json:"id,omitempty"`          // tool_use ID
 	ToolUseID string          `json:"tool_use_id,omitempty"` // References tool_use ID in tool_result
 	Content   json.RawMessage `json:"content,omitempty"`     // tool_result content
+	IsError   bool            `json:"is_error,omitempty"`    // Set on failed tool_result
+	Thinking  string          `json:"thinking,omitempty"`    // thinking block text
+	Text      string          `json:"text,omitempty"`        // text block
 }
 
 // GenericInput is used to extract common fields from any tool's input
@@ -53,6 +86,17 @@ type GenericInput struct {
 
 	// Task-specific
 	Skill string `json:"skill"`
+
+	// Background shells
+	RunInBackground bool   `json:"run_in_background"`
+	BashID          string `json:"bash_id"`  // BashOutput
+	ShellID         string `json:"shell_id"` // KillShell
+
+	// Interactive decision points
+	Questions []struct {
+		Question string `json:"question"`
+	} `json:"questions"`
+	Plan string `json:"plan"`
 }
 
 // ExtractDisplayString returns the most relevant string to display for this input
@@ -72,12 +116,28 @@ func (g *GenericInput) ExtractDisplayString(toolName string) string {
 	case "Task":
 		return g.Description
 	case "Skill":
-		return g.Skill
+		return g.skillName()
+	case "AskUserQuestion":
+		if len(g.Questions) > 0 {
+			return g.Questions[0].Question
+		}
+	case "ExitPlanMode":
+		for _, line := range strings.Split(g.Plan, "\n") {
+			if line = strings.TrimSpace(strings.TrimLeft(line, "# ")); line != "" {
+				return truncate(line, 100)
+			}
+		}
 	}
 
 	return g.fallbackDisplay()
 }
 
+// skillName returns the skill a Skill call invokes; older sessions pass it
+// as "command" rather than "skill"
+func (g *GenericInput) skillName() string {
+	return strings.TrimPrefix(firstNonEmpty(g.Skill, g.Command), "/")
+}
+
 // formatGlob returns a display string for Glob tool
 func (g *GenericInput) formatGlob() string {
 	if g.Pattern != "" && g.Path != "" {
@@ -126,51 +186,103 @@ func truncate(s string, maxLen int) string {
 // SessionMetadata contains metadata extracted from a session file
 type SessionMetadata struct {
 	GitBranch string
-	CWD       string
+	CWD       string // First working directory seen (the project path)
+	LastCWD   string // Most recent working directory seen
+
+	// FailedToolUses are tool_use IDs with failed results whose tool_use was
+	// parsed in an earlier pass (incremental parsing only)
+	FailedToolUses []string
+
+	// JobStarts and JobCalls are background shell events for commands parsed
+	// in an earlier pass (incremental parsing only); see ApplyJobEvents
+	JobStarts []JobStart
+	JobCalls  []JobCall
+
+	// Thinking counts the thinking blocks parsed; TrailingThinking is the
+	// part after the last tool call, which precedes the next one
+	Thinking         ThinkingStats
+	TrailingThinking ThinkingStats
+
+	Markers []Marker // Compactions and restarts, in file order
+
+	// Hooks fired for tool calls parsed in an earlier pass (incremental
+	// parsing only); see ApplyHooks
+	Hooks []HookRun
+
+	// Lineage (see Predecessor): the first and last message UUIDs and when
+	// the conversation started, and the leafUuids of summary records
+	RootUUID    string
+	LastUUID    string
+	StartedAt   time.Time
+	ResumedFrom []string
 }
 
 // parseState holds state for incremental JSONL parsing
 type parseState struct {
 	commands   []CommandEntry
 	meta       SessionMetadata
-	seen       map[string]bool
+	seen       map[seenKey]bool
+	toolUses   map[string]int // tool_use ID -> index in commands, for marking errors
+	jobs       map[string]int // Background shell ID -> index in commands
 	lineNumber int
-	offset     int64
 	filePath   string
+
+	incremental bool          // Parsing from an offset, after commands parsed earlier
+	thinking    ThinkingStats // Reasoning since the last tool call
+	conversing  bool          // A message has been read, so a new root message is a restart
+	compacted   bool          // The last marker was a compaction boundary
+	unfiltered  bool          // Keep commands the config excludes from display
 }
 
-// newParseState creates a new parse state
+// seenKey identifies a tool call for deduplication
+type seenKey struct {
+	uuid, toolName string
+}
+
+// newParseState takes a parse state from the pool (see release)
 func newParseState(filePath string, startLine int, startOffset int64) *parseState {
-	return &parseState{
-		seen:       make(map[string]bool),
-		lineNumber: startLine,
-		offset:     startOffset,
-		filePath:   filePath,
-	}
+	ps := parseStates.Get().(*parseState)
+	ps.lineNumber = startLine
+	ps.filePath = filePath
+	ps.incremental = startOffset > 0
+	ps.conversing = startOffset > 0
+	return ps
 }
 
-// processLine parses a single JSONL line and extracts commands.
-// Returns the number of bytes consumed (for offset tracking).
-func (ps *parseState) processLine(line []byte) int {
-	lineLen := len(line) + 1 // +1 for newline
+// processLine parses a single JSONL line and extracts commands
+func (ps *parseState) processLine(line []byte) {
 	ps.lineNumber++
 
+	ps.captureMarker(line)
+	ps.captureHook(line)
+
 	var record JSONLRecord
-	if err := json.Unmarshal(line, &record); err != nil {
-		return lineLen
+	if err := decodeRecord(line, &record); err != nil {
+		if len(bytes.TrimSpace(line)) > 0 {
+			applog.Warnf("parse: skipped line %d of %s: %v", ps.lineNumber, ps.filePath, err)
+		}
+		return
 	}
 
 	ps.captureMetadata(&record)
+	if record.Type == "user" || record.Type == "assistant" {
+		ps.conversing = true
+	}
 
+	if record.Type == "user" && record.Message != nil {
+		ps.markErrors(record.Message)
+		ps.recordJobStarts(record.Message)
+		ps.captureBlockedResults(record.Message)
+		return
+	}
 	if record.Type != "assistant" || record.Message == nil {
-		return lineLen
+		return
 	}
 
 	for _, content := range record.Message.Content {
+		ps.countThinking(&content)
 		ps.processToolUse(&record, &content)
 	}
-
-	return lineLen
 }
 
 // captureMetadata extracts session metadata from a record
@@ -178,9 +290,23 @@ func (ps *parseState) captureMetadata(record *JSONLRecord) {
 	if record.CWD != "" && ps.meta.CWD == "" {
 		ps.meta.CWD = record.CWD
 	}
+	if record.CWD != "" {
+		ps.meta.LastCWD = record.CWD
+	}
 	if record.GitBranch != "" && ps.meta.GitBranch == "" {
 		ps.meta.GitBranch = record.GitBranch
 	}
+
+	if record.Type == "summary" && record.LeafUUID != "" {
+		ps.meta.ResumedFrom = append(ps.meta.ResumedFrom, record.LeafUUID)
+	}
+	if (record.Type == "user" || record.Type == "assistant") && !record.IsSidechain && record.UUID != "" {
+		if ps.meta.RootUUID == "" {
+			ps.meta.RootUUID = record.UUID
+			ps.meta.StartedAt, _ = time.Parse(time.RFC3339, record.Timestamp)
+		}
+		ps.meta.LastUUID = record.UUID
+	}
 }
 
 // processToolUse processes a single tool_use content item
@@ -195,38 +321,52 @@ func (ps *parseState) processToolUse(record *JSONLRecord, content *ContentItem)
 		UUID:       record.UUID,
 		LineNumber: ps.lineNumber,
 		FilePath:   ps.filePath,
+		CWD:        record.CWD,
+		ToolUseID:  content.ID,
+		Thinking:   ps.thinking,
 	}
+	ps.thinking = ThinkingStats{}
 
 	// Parse input and extract display string
 	var input GenericInput
 	if err := json.Unmarshal(content.Input, &input); err == nil {
 		entry.RawCommand = input.ExtractDisplayString(content.Name)
 	}
+	entry.Background = content.Name == "Bash" && input.RunInBackground
 
 	// Fall back to tool name if no display string extracted
 	if entry.RawCommand == "" {
 		entry.RawCommand = content.Name
 	}
 
-	// Extract pattern (Bash gets special treatment for command grouping)
-	if content.Name == "Bash" {
+	// Extract pattern (Bash gets special treatment for command grouping,
+	// skills a pattern per skill name)
+	switch content.Name {
+	case "Bash":
 		entry.Pattern = ExtractPattern("Bash", input.Command)
-	} else {
+	case "Skill":
+		entry.Pattern = ExtractPattern("Skill", input.skillName())
+	default:
 		entry.Pattern = content.Name
 	}
 
-	// Skip if pattern should be excluded
-	if !ShouldInclude(entry.Pattern) {
+	// Skip if pattern should be excluded (globally or by the project's override file)
+	if !ps.unfiltered && !ShouldIncludeInProject(ps.meta.CWD, entry.Pattern) {
 		return
 	}
 
 	// Create unique key for deduplication
-	entryKey := record.UUID + content.Name
+	entryKey := seenKey{record.UUID, content.Name}
 	if ps.seen[entryKey] {
 		return
 	}
 	ps.seen[entryKey] = true
 
+	// Polling or killing a background shell folds into the command that started it
+	if ps.foldJobCall(content.Name, &input) {
+		return
+	}
+
 	// Parse timestamp
 	if t, err := time.Parse(time.RFC3339, record.Timestamp); err == nil {
 		entry.Timestamp = t
@@ -236,12 +376,44 @@ func (ps *parseState) processToolUse(record *JSONLRecord, content *ContentItem)
 
 	// Only add if we got a valid command/path
 	if entry.RawCommand != "" {
+		if content.ID != "" {
+			ps.toolUses[content.ID] = len(ps.commands)
+		}
 		ps.commands = append(ps.commands, entry)
 	}
 }
 
+// markErrors flags commands whose tool_result reports an error. Results for
+// tool_uses parsed in an earlier pass are left in meta.FailedToolUses for
+// the caller to apply.
+func (ps *parseState) markErrors(msg *Message) {
+	for _, content := range msg.Content {
+		if content.Type != "tool_result" || !content.IsError {
+			continue
+		}
+		if idx, ok := ps.toolUses[content.ToolUseID]; ok {
+			ps.commands[idx].IsError = true
+		} else if content.ToolUseID != "" {
+			ps.meta.FailedToolUses = append(ps.meta.FailedToolUses, content.ToolUseID)
+		}
+	}
+}
+
 // ParseSessionFile reads a JSONL file and extracts command entries
 func ParseSessionFile(path string) ([]CommandEntry, SessionMetadata, error) {
+	return parseWholeFile(path, false)
+}
+
+// ParseSessionFileUnfiltered is like ParseSessionFile but keeps the commands
+// that tool groups with exclude: true hide, for checks that must see every
+// command whatever the config or a project's override file hides
+func ParseSessionFileUnfiltered(path string) ([]CommandEntry, SessionMetadata, error) {
+	return parseWholeFile(path, true)
+}
+
+// parseWholeFile reads a JSONL file from the start, keeping excluded
+// commands when unfiltered is set
+func parseWholeFile(path string, unfiltered bool) ([]CommandEntry, SessionMetadata, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, SessionMetadata{}, err
@@ -249,20 +421,25 @@ func ParseSessionFile(path string) ([]CommandEntry, SessionMetadata, error) {
 	defer file.Close()
 
 	ps := newParseState(path, 0, 0)
+	ps.unfiltered = unfiltered
+	defer ps.release()
 
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 2*1024*1024) // 2MB max line size
+	scanner, release := newLineScanner(file)
+	defer release()
 
 	for scanner.Scan() {
 		ps.processLine(scanner.Bytes())
 	}
 
+	ps.meta.TrailingThinking = ps.thinking
 	return ps.commands, ps.meta, scanner.Err()
 }
 
 // ParseSessionFileFrom reads a JSONL file starting from a byte offset
-// Returns commands found, metadata, new offset, new line number, and any error
+// Returns commands found, metadata, new offset, new line number, and any error.
+// The offset only moves past complete records: a final line without its
+// newline that isn't a whole record yet (the writer was caught mid-write) is
+// left for the next call to read.
 func ParseSessionFileFrom(path string, offset int64, startLine int) (commands []CommandEntry, meta SessionMetadata, newOffset int64, newLine int, err error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -270,24 +447,39 @@ func ParseSessionFileFrom(path string, offset int64, startLine int) (commands []
 	}
 	defer file.Close()
 
-	// Seek to offset
+	// A whole record read without its line ending last time leaves the
+	// ending at offset; it ends that line rather than starting an empty one
+	start := offset
 	if offset > 0 {
-		if _, err := file.Seek(offset, 0); err != nil {
+		var around [3]byte
+		n, _ := file.ReadAt(around[:], offset-1)
+		if n >= 2 && around[0] != '\n' {
+			switch {
+			case around[1] == '\n':
+				start++
+			case n == 3 && around[1] == '\r' && around[2] == '\n':
+				start += 2
+			}
+		}
+		if _, err := file.Seek(start, io.SeekStart); err != nil {
 			return nil, SessionMetadata{}, offset, startLine, err
 		}
 	}
 
 	ps := newParseState(path, startLine, offset)
+	defer ps.release()
 
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 2*1024*1024)
+	scanner, release := newLineScanner(file)
+	defer release()
+	var consumed int64
+	scanner.Split(scanRecords(&consumed))
 
 	for scanner.Scan() {
-		ps.offset += int64(ps.processLine(scanner.Bytes()))
+		ps.processLine(scanner.Bytes())
 	}
 
-	return ps.commands, ps.meta, ps.offset, ps.lineNumber, scanner.Err()
+	ps.meta.TrailingThinking = ps.thinking
+	return ps.commands, ps.meta, start + consumed, ps.lineNumber, scanner.Err()
 }
 
 // ToolInput holds the full parsed input for a tool call, loaded on demand
@@ -300,21 +492,41 @@ type ToolInput struct {
 	ToolUseID string                 // The tool_use ID for linking to result
 	Result    string                 // The tool result/output (if found)
 	IsError   bool                   // Whether the result was an error
+	HasResult bool                   // Whether the tool_result has been written yet
+
+	Attachments []Attachment   // Images and binary content from the result, left out of Result
+	Job         *BackgroundJob // What the shell reported, for a run_in_background Bash call
+	Thinking    []string       // Thinking blocks since the previous tool call, oldest first
 }
 
 // FetchToolInput reads a tool call record and its result from a JSONL file.
 // It first tries the line number (fast path), then falls back to UUID-based search.
 // After finding the tool_use, it scans ahead to find the matching tool_result.
+// Results are cached by file and tool call (see toolInputs).
 func FetchToolInput(filePath string, lineNumber int, toolName, uuid string) (*ToolInput, error) {
+	key := toolInputKey{file: filePath, uuid: uuid, toolName: toolName}
+	input, generation, ok := cachedToolInput(key)
+	if ok {
+		return input, nil
+	}
+	input, err := fetchToolInput(filePath, lineNumber, toolName, uuid)
+	if err != nil {
+		return nil, err
+	}
+	cacheToolInput(key, generation, input)
+	return input, nil
+}
+
+// fetchToolInput scans a JSONL file for a tool call record and its result
+func fetchToolInput(filePath string, lineNumber int, toolName, uuid string) (*ToolInput, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 2*1024*1024)
+	scanner, release := newLineScanner(file)
+	defer release()
 
 	result := scanForToolInput(scanner, lineNumber, toolName, uuid)
 
@@ -322,17 +534,25 @@ func FetchToolInput(filePath string, lineNumber int, toolName, uuid string) (*To
 		return nil, err
 	}
 
-	if result.input != nil {
-		findToolResult(result.input, result.lines)
-		return result.input, nil
+	input := result.input
+	if input != nil {
+		findToolResult(input, result.lines)
+		input.Thinking = append(precedingThinking(result.allLines), input.Thinking...)
+	} else {
+		// Fast path failed - search through collected lines by UUID
+		input = searchFallbackLines(result.allLines, toolName, uuid)
 	}
-
-	// Fast path failed - search through collected lines by UUID
-	if input := searchFallbackLines(result.allLines, toolName, uuid); input != nil {
-		return input, nil
+	if input == nil {
+		return nil, fmt.Errorf("tool %s with UUID %s not found", toolName, uuid)
 	}
 
-	return nil, fmt.Errorf("tool %s with UUID %s not found", toolName, uuid)
+	// A background command's output arrives later, through BashOutput calls
+	if shellID := backgroundShellID(input); shellID != "" {
+		if input.Job, err = readBackgroundJob(filePath, lineNumber, shellID); err != nil {
+			return nil, err
+		}
+	}
+	return input, nil
 }
 
 // scanResult holds the result of scanning a file for tool input
@@ -384,6 +604,7 @@ func searchFallbackLines(allLines [][]byte, toolName, uuid string) *ToolInput {
 	for i, line := range allLines {
 		input := tryParseToolInput(line, toolName, uuid)
 		if input != nil {
+			input.Thinking = append(precedingThinking(allLines[:i]), input.Thinking...)
 			// Collect following lines for result search
 			lines := [][]byte{line}
 			for j := i + 1; j < len(allLines) && len(lines) <= 10; j++ {
@@ -412,8 +633,15 @@ func tryParseToolInput(line []byte, toolName, uuid string) *ToolInput {
 		return nil
 	}
 
-	// Find the matching tool_use content
+	// Find the matching tool_use content, keeping the reasoning before it
+	var thinking []string
 	for _, content := range record.Message.Content {
+		if text, ok := thinkingText(&content); ok {
+			thinking = append(thinking, text)
+		}
+		if content.Type == "tool_use" && content.Name != toolName {
+			thinking = nil
+		}
 		if content.Type == "tool_use" && content.Name == toolName {
 			var parsed map[string]interface{}
 			if err := json.Unmarshal(content.Input, &parsed); err != nil {
@@ -427,6 +655,7 @@ func tryParseToolInput(line []byte, toolName, uuid string) *ToolInput {
 				ToolUseID: content.ID,
 				CWD:       record.CWD,
 				GitBranch: record.GitBranch,
+				Thinking:  thinking,
 			}
 		}
 	}
@@ -453,7 +682,8 @@ func findToolResult(input *ToolInput, lines [][]byte) {
 		// Look for tool_result with matching tool_use_id
 		for _, content := range record.Message.Content {
 			if content.Type == "tool_result" && content.ToolUseID == input.ToolUseID {
-				input.Result = extractResultText(content.Content)
+				input.Result, input.Attachments = extractResult(content.Content)
+				input.HasResult = true
 				// Check if this is an error result (heuristic: look for error indicators)
 				input.IsError = isErrorResult(input.Result)
 				return
@@ -462,42 +692,61 @@ func findToolResult(input *ToolInput, lines [][]byte) {
 	}
 }
 
-// extractResultText extracts readable text from tool_result content
-func extractResultText(content json.RawMessage) string {
+// extractResult extracts readable text from tool_result content, setting
+// aside image blocks, base64 payloads, and binary output as attachments
+func extractResult(content json.RawMessage) (string, []Attachment) {
 	if len(content) == 0 {
-		return ""
+		return "", nil
 	}
 
 	// Try parsing as string first (simple case)
 	var simpleStr string
 	if err := json.Unmarshal(content, &simpleStr); err == nil {
-		return simpleStr
+		if att, ok := textAttachment(simpleStr); ok {
+			return "", []Attachment{att}
+		}
+		return simpleStr, nil
 	}
 
 	// Try parsing as array of content items (common format)
 	var items []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
+		Type   string `json:"type"`
+		Text   string `json:"text"`
+		Source struct {
+			Type      string `json:"type"`
+			MediaType string `json:"media_type"`
+			Data      string `json:"data"`
+		} `json:"source"`
 	}
 	if err := json.Unmarshal(content, &items); err == nil {
 		var result string
+		var attachments []Attachment
 		for _, item := range items {
-			if item.Type == "text" && item.Text != "" {
+			switch {
+			case item.Type == "image" && item.Source.Type == "base64":
+				if data, ok := decodeBase64(item.Source.Data); ok {
+					attachments = append(attachments, Attachment{MediaType: item.Source.MediaType, Data: data})
+				}
+			case item.Type == "text" && item.Text != "":
+				if att, ok := textAttachment(item.Text); ok {
+					attachments = append(attachments, att)
+					continue
+				}
 				if result != "" {
 					result += "\n"
 				}
 				result += item.Text
 			}
 		}
-		return result
+		return result, attachments
 	}
 
 	// Fall back to raw string (truncated)
 	s := string(content)
 	if len(s) > 2000 {
-		return s[:2000] + "..."
+		return s[:2000] + "...", nil
 	}
-	return s
+	return s, nil
 }
 
 // isErrorResult checks if the result text indicates an error
@@ -7,6 +7,7 @@ import (
 
 // subcommandDepth defines how many subcommand levels to capture for each command.
 // Commands not in this map get depth 0 (command only, no subcommands).
+// Entries in the config's commands.subcommand_depth take precedence.
 var subcommandDepth = map[string]int{
 	// Version control
 	"git": 1,
@@ -55,16 +56,86 @@ var subcommandDepth = map[string]int{
 	"alembic": 1,
 }
 
+// flagsWithArgs lists, per command, the flags that consume the next word, so
+// that word isn't mistaken for a subcommand (git -C /path status) or, for
+// network commands, for the destination (curl -o out URL). Flags given as
+// --flag=value need no entry. The config's commands.flags_with_args adds to
+// this table.
+var flagsWithArgs = map[string][]string{
+	"sudo":      {"-u", "-g", "-C", "-D", "-h", "-p"},
+	"git":       {"-C", "-c", "--git-dir", "--work-tree", "--namespace", "--exec-path"},
+	"kubectl":   {"-n", "--namespace", "--context", "--kubeconfig", "--cluster", "--user", "-s", "--server"},
+	"helm":      {"-n", "--namespace", "--kube-context", "--kubeconfig"},
+	"docker":    {"-c", "--context", "-H", "--host", "--config", "-l", "--log-level"},
+	"podman":    {"-c", "--connection", "--url", "--root", "--runroot"},
+	"terraform": {"-chdir"},
+	"systemctl": {"-H", "--host", "-M", "--machine"},
+	"make":      {"-C", "-f", "--file", "--directory", "-j"},
+	"go":        {"-C"},
+	"npm":       {"--prefix", "-w", "--workspace"},
+	"yarn":      {"--cwd"},
+	"pnpm":      {"-C", "--dir", "--filter", "-F"},
+	"cargo":     {"--manifest-path", "-Z"},
+	"gh":        {"-R", "--repo"},
+	"tmux":      {"-L", "-S", "-f"},
+	"nix":       {"--extra-experimental-features", "--experimental-features"},
+	"uv":        {"--directory", "--project"},
+	"python":    {"-W", "-X"},
+	"python3":   {"-W", "-X"},
+	"node":      {"-r", "--require", "--import"},
+	"ruby":      {"-I", "-r"},
+	"perl":      {"-I", "-M"},
+	"curl": {
+		"-o", "--output", "-H", "--header", "-d", "--data", "--data-raw", "--data-binary",
+		"-X", "--request", "-u", "--user", "-A", "--user-agent", "-e", "--referer",
+		"-b", "--cookie", "-c", "--cookie-jar", "-F", "--form", "-T", "--upload-file",
+		"-x", "--proxy", "-w", "--write-out", "-m", "--max-time", "--connect-timeout",
+		"-K", "--config", "--cacert", "--cert", "--key", "-r", "--range",
+	},
+	"wget": {"-O", "-o", "-P", "-U", "--header", "-e", "-t", "-T", "-i"},
+	"nc":   {"-p", "-s", "-w", "-i", "-x", "-X", "-q", "-I", "-O"},
+	"ssh": {
+		"-p", "-i", "-l", "-o", "-F", "-L", "-R", "-D", "-J", "-b", "-c", "-E",
+		"-e", "-m", "-O", "-Q", "-S", "-W", "-w", "-B",
+	},
+	"scp": {"-P", "-i", "-o", "-F", "-J", "-c", "-l", "-S"},
+}
+
+// TakesArg reports whether flag consumes the next word for cmd
+func TakesArg(cmd, flag string) bool {
+	for _, f := range flagsWithArgs[cmd] {
+		if f == flag {
+			return true
+		}
+	}
+	for _, f := range config.Global().Commands.FlagsWithArgs[cmd] {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
 // ShouldInclude returns true if the pattern should be included in the display
 func ShouldInclude(pattern string) bool {
 	return !config.Global().ShouldExclude(pattern)
 }
 
+// ShouldIncludeInProject is like ShouldInclude but honors the project's override file
+func ShouldIncludeInProject(projectPath, pattern string) bool {
+	return !config.ForProject(projectPath).ShouldExclude(pattern)
+}
+
 // ExtractPattern converts a tool call into Claude permission pattern format
 func ExtractPattern(toolName, input string) string {
 	switch toolName {
 	case "Bash":
 		return extractBashPattern(input)
+	case "Skill":
+		if input = strings.TrimSpace(input); input != "" {
+			return "Skill(" + input + ")"
+		}
+		return toolName
 	case "Edit", "Write", "NotebookEdit":
 		return toolName
 	default:
@@ -75,7 +146,8 @@ func ExtractPattern(toolName, input string) string {
 // extractBashPattern extracts the command pattern from a bash command
 // Pattern format: Bash([sudo:]<command>[:<subcommand>]:*)
 func extractBashPattern(command string) string {
-	command = strings.TrimSpace(command)
+	// Only the first logical line determines the pattern (ignores heredoc bodies and later lines)
+	command = strings.TrimSpace(firstLogicalLine(command))
 	if command == "" {
 		return "Bash"
 	}
@@ -94,7 +166,7 @@ func extractBashPattern(command string) string {
 	// Check for sudo prefix and preserve it
 	hasSudo := words[0] == "sudo"
 	if hasSudo {
-		words = skipSudoFlags(words[1:])
+		words = skipFlags("sudo", words[1:])
 	}
 
 	// Handle command wrappers (env, time, nice, etc.)
@@ -125,42 +197,67 @@ func buildPatternParts(hasSudo bool, words []string) []string {
 	cmd := words[0]
 	parts = append(parts, cmd)
 
+	// Interpreters are identified by the script they run (or inline code)
+	if isInterpreter(cmd) {
+		return append(parts, interpreterParts(cmd, words[1:])...)
+	}
+
 	// Extract subcommands based on depth config
-	subcommands := extractSubcommands(cmd, words[1:])
+	subcommands, args := extractSubcommands(cmd, words[1:])
 	parts = append(parts, subcommands...)
 
+	// Capture argument words verbatim for finer-grained patterns, if configured
+	if n := config.Global().Commands.ArgumentDepth(cmd); n > 0 {
+		parts = append(parts, args[:min(n, len(args))]...)
+	}
+
 	return parts
 }
 
-// extractSubcommands extracts subcommands from args based on the command's depth
-func extractSubcommands(cmd string, args []string) []string {
-	depth := subcommandDepth[cmd]
+// commandDepth returns the number of subcommand levels to capture for cmd
+func commandDepth(cmd string) int {
+	if depth, ok := config.Global().Commands.SubcommandDepth[cmd]; ok {
+		return depth
+	}
+	return subcommandDepth[cmd]
+}
+
+// extractSubcommands extracts subcommands from args based on the command's
+// depth, returning them and the arguments that follow
+func extractSubcommands(cmd string, args []string) (subcommands, rest []string) {
+	depth := commandDepth(cmd)
 	if depth == 0 || len(args) == 0 {
-		return nil
+		return nil, args
 	}
 
-	var subcommands []string
 	for i := 0; i < depth && len(args) > 0; i++ {
-		// Skip flags to find the subcommand
-		args = skipFlags(args)
+		// Skip flags (and their arguments) to find the subcommand
+		args = skipFlags(cmd, args)
 		if len(args) == 0 {
 			break
 		}
 		subcommands = append(subcommands, args[0])
 		args = args[1:]
 	}
-	return subcommands
+	return subcommands, args
 }
 
-// skipFlags skips leading flag arguments
-func skipFlags(args []string) []string {
+// skipFlags skips leading flag arguments, including the value of flags that
+// cmd is known to take as a separate word
+func skipFlags(cmd string, args []string) []string {
 	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
-		args = args[1:]
+		if TakesArg(cmd, args[0]) && len(args) > 1 {
+			args = args[2:]
+		} else {
+			args = args[1:]
+		}
 	}
 	return args
 }
 
-// bashPattern formats a Bash pattern from parts
+// bashPattern formats a Bash pattern from parts. A trailing flag is written
+// as a prefix match (git:push:--force*) so it also covers --force-with-lease
+// and --flag=value forms.
 func bashPattern(hasSudo bool, parts []string) string {
 	if len(parts) == 0 {
 		if hasSudo {
@@ -168,6 +265,9 @@ func bashPattern(hasSudo bool, parts []string) string {
 		}
 		return "Bash"
 	}
+	if strings.HasPrefix(parts[len(parts)-1], "-") {
+		return "Bash(" + strings.Join(parts, ":") + "*)"
+	}
 	return "Bash(" + strings.Join(parts, ":") + ":*)"
 }
 
@@ -179,27 +279,6 @@ func skipEnvVars(words []string) []string {
 	return words
 }
 
-// skipSudoFlags advances past sudo flags and returns remaining words
-func skipSudoFlags(words []string) []string {
-	for len(words) > 0 {
-		w := words[0]
-		if !strings.HasPrefix(w, "-") {
-			return words
-		}
-		// Flags that take an argument
-		if w == "-u" || w == "-g" || w == "-C" || w == "-D" || w == "-h" || w == "-p" {
-			if len(words) > 1 {
-				words = words[2:]
-			} else {
-				words = words[1:]
-			}
-		} else {
-			words = words[1:]
-		}
-	}
-	return words
-}
-
 // unwrapCommand handles command wrappers like env, time, nice, etc.
 func unwrapCommand(words []string) []string {
 	if len(words) == 0 {
@@ -216,8 +295,45 @@ func unwrapCommand(words []string) []string {
 	case "xargs":
 		return unwrapXargs(words)
 	default:
-		return words
+		return unwrapConfigured(words)
+	}
+}
+
+// unwrapConfigured strips the first matching wrapper from the config's
+// commands.wrappers. If the wrapper's words are followed by flags, the
+// command starts after a lone "--" when present (doppler run --project x -- cmd),
+// otherwise after the flags.
+func unwrapConfigured(words []string) []string {
+	for _, wrapper := range config.Global().Commands.Wrappers {
+		prefix := strings.Fields(wrapper)
+		if len(prefix) == 0 || !HasWordPrefix(words, prefix) {
+			continue
+		}
+		rest := words[len(prefix):]
+		if len(rest) > 0 && strings.HasPrefix(rest[0], "-") {
+			for i, w := range rest {
+				if w == "--" {
+					return rest[i+1:]
+				}
+			}
+		}
+		return skipFlags(prefix[0], rest)
+	}
+	return words
+}
+
+// HasWordPrefix reports whether words starts with prefix, where a "*" in
+// prefix matches any single word
+func HasWordPrefix(words, prefix []string) bool {
+	if len(words) < len(prefix) {
+		return false
+	}
+	for i, p := range prefix {
+		if p != "*" && p != words[i] {
+			return false
+		}
 	}
+	return true
 }
 
 // unwrapEnv handles: env VAR=val command or env -i command
@@ -24,6 +24,7 @@ func TestExtractPattern(t *testing.T) {
 		{"sudo with -E flag", "Bash", "sudo -E npm install", "Bash(sudo:npm:install:*)"},
 		{"sudo only", "Bash", "sudo", "Bash(sudo:*)"},
 		{"sudo with flags only", "Bash", "sudo -u root", "Bash(sudo:*)"},
+		{"sudo -u then git -C", "Bash", "sudo -u deploy git -C /srv/app pull", "Bash(sudo:git:pull:*)"},
 
 		// === Git subcommands ===
 		{"git status", "Bash", "git status", "Bash(git:status:*)"},
@@ -35,7 +36,9 @@ func TestExtractPattern(t *testing.T) {
 		{"git diff", "Bash", "git diff HEAD~1", "Bash(git:diff:*)"},
 		{"git add", "Bash", "git add .", "Bash(git:add:*)"},
 		{"git clone", "Bash", "git clone https://github.com/user/repo", "Bash(git:clone:*)"},
-		{"git with -C flag", "Bash", "git -C /path status", "Bash(git:/path:*)"}, // -C takes an arg, so /path is captured
+		{"git with -C flag", "Bash", "git -C /path status", "Bash(git:status:*)"}, // -C takes an arg, so /path is skipped
+		{"git with -c flag", "Bash", "git -c user.name=x commit -m msg", "Bash(git:commit:*)"},
+		{"git with --git-dir=", "Bash", "git --git-dir=/repo/.git log", "Bash(git:log:*)"},
 
 		// === ZFS/ZPool ===
 		{"zfs destroy", "Bash", "zfs destroy tank/data", "Bash(zfs:destroy:*)"},
@@ -54,6 +57,11 @@ func TestExtractPattern(t *testing.T) {
 		{"podman rm", "Bash", "podman rm -f container", "Bash(podman:rm:*)"},
 		{"kubectl get", "Bash", "kubectl get pods -n kube-system", "Bash(kubectl:get:*)"},
 		{"kubectl delete", "Bash", "kubectl delete pod nginx", "Bash(kubectl:delete:*)"},
+		{"kubectl with -n", "Bash", "kubectl -n kube-system get pods", "Bash(kubectl:get:*)"},
+		{"kubectl with --context", "Bash", "kubectl --context prod delete pod x", "Bash(kubectl:delete:*)"},
+		{"docker with --context", "Bash", "docker --context remote ps", "Bash(docker:ps:*)"},
+		{"helm with -n", "Bash", "helm -n apps upgrade web ./chart", "Bash(helm:upgrade:*)"},
+		{"make with -C", "Bash", "make -C subdir test", "Bash(make:test:*)"},
 		{"helm install", "Bash", "helm install myapp ./chart", "Bash(helm:install:*)"},
 
 		// === System services ===
@@ -117,6 +125,10 @@ func TestExtractPattern(t *testing.T) {
 		{"Write tool", "Write", "/path/to/file.go", "Write"},
 		{"NotebookEdit tool", "NotebookEdit", "/path/to/notebook.ipynb", "NotebookEdit"},
 
+		// === Skills get a pattern per skill ===
+		{"Skill tool", "Skill", "pdf", "Skill(pdf)"},
+		{"Skill without name", "Skill", "", "Skill"},
+
 		// === Unknown tool ===
 		{"unknown tool", "Unknown", "something", "Unknown"},
 	}
@@ -179,3 +191,93 @@ func TestShouldInclude(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractPatternWithCommandKnowledge(t *testing.T) {
+	config.SetGlobal(&config.Config{
+		Commands: config.CommandKnowledge{
+			SubcommandDepth: map[string]int{
+				"gh":        2,
+				"aws":       2,
+				"terraform": 2,
+				"go":        0, // Override a built-in entry
+			},
+			Wrappers: []string{"doppler run", "timeout *", "direnv exec *"},
+			FlagsWithArgs: map[string][]string{
+				"terraform": {"-var-file"},
+				"aws":       {"--profile", "--region"},
+			},
+		},
+	})
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		// === Configured depths ===
+		{"gh depth 2", "gh pr view 123", "Bash(gh:pr:view:*)"},
+		{"aws depth 2", "aws s3 cp a.txt s3://bucket/", "Bash(aws:s3:cp:*)"},
+		{"terraform depth 2", "terraform workspace select prod", "Bash(terraform:workspace:select:*)"},
+		{"depth 2 with fewer args", "terraform plan", "Bash(terraform:plan:*)"},
+		{"configured flag with arg", "aws --profile prod s3 ls", "Bash(aws:s3:ls:*)"},
+		{"built-in flags kept alongside config", "terraform -chdir infra plan", "Bash(terraform:plan:*)"},
+		{"built-in overridden to 0", "go test ./...", "Bash(go:*)"},
+		{"unconfigured built-in unchanged", "git push origin main", "Bash(git:push:*)"},
+
+		// === Configured wrappers ===
+		{"plain wrapper", "doppler run npm test", "Bash(npm:test:*)"},
+		{"wrapper with flags and --", "doppler run --project api -- npm test", "Bash(npm:test:*)"},
+		{"wildcard wrapper arg", "timeout 30 make build", "Bash(make:build:*)"},
+		{"wildcard mid-prefix", "direnv exec . cargo build", "Bash(cargo:build:*)"},
+		{"non-matching prefix", "doppler secrets", "Bash(doppler:*)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractPattern("Bash", tt.input)
+			if result != tt.expected {
+				t.Errorf("ExtractPattern(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractPatternWithPatternDepth(t *testing.T) {
+	config.SetGlobal(&config.Config{
+		Commands: config.CommandKnowledge{
+			PatternDepth:          0,
+			PatternDepthByCommand: map[string]int{"git": 1, "npm": 1, "ls": 2},
+		},
+	})
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"git push --force", "git push --force origin main", "Bash(git:push:--force*)"},
+		{"git push to remote", "git push origin main", "Bash(git:push:origin:*)"},
+		{"git status no args", "git status", "Bash(git:status:*)"},
+		{"npm run script", "npm run build", "Bash(npm:run:build:*)"},
+		{"command without subcommands", "ls -la /tmp", "Bash(ls:-la:/tmp:*)"},
+		{"sudo preserved", "sudo git push --force", "Bash(sudo:git:push:--force*)"},
+		{"unconfigured family unchanged", "go test -v ./...", "Bash(go:test:*)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractPattern("Bash", tt.input)
+			if result != tt.expected {
+				t.Errorf("ExtractPattern(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	// A global depth applies to every command without its own entry
+	config.SetGlobal(&config.Config{Commands: config.CommandKnowledge{PatternDepth: 1}})
+	if got := ExtractPattern("Bash", "go test -v ./..."); got != "Bash(go:test:-v*)" {
+		t.Errorf("ExtractPattern with global depth = %q, want %q", got, "Bash(go:test:-v*)")
+	}
+}
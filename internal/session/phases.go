@@ -0,0 +1,177 @@
+package session
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+// IdleGap is the shortest pause between two commands that counts as idle
+// time rather than work
+const IdleGap = 5 * time.Minute
+
+// phaseRadius is how many commands on each side of a command vote on its
+// phase, smoothing single stray Reads or test runs into their surroundings
+const phaseRadius = 2
+
+// Phase is a rough stage of a session's work
+type Phase string
+
+const (
+	PhaseExploration    Phase = "exploration"    // Mostly Read, Glob, and Grep
+	PhaseImplementation Phase = "implementation" // Mostly Edit and Write
+	PhaseVerification   Phase = "verification"   // Mostly test, build, and lint runs
+)
+
+// Phases lists the phases in the order a session usually goes through them
+var Phases = []Phase{PhaseExploration, PhaseImplementation, PhaseVerification}
+
+// verifyCommand matches shell commands that test, build, or lint the project
+var verifyCommand = regexp.MustCompile(`(^|[\s;&|(])(go (test|vet|build)|cargo (test|check|clippy)|(npm|pnpm|yarn|bun)( run)? (test|lint|build)|npx (jest|vitest|tsc)|pytest|python3? -m (pytest|unittest)|jest|vitest|tox|rspec|make (test|check)|mvn (test|verify)|gradle test|\./gradlew test|ctest|golangci-lint|ruff|mypy|eslint|tsc)\b`)
+
+// CommandPhase returns the phase a command points to, or "" for commands
+// that fit any phase, e.g. git or ls
+func CommandPhase(cmd *CommandEntry) Phase {
+	switch cmd.ToolName {
+	case "Read", "Glob", "Grep", "LS", "NotebookRead", "WebFetch", "WebSearch":
+		return PhaseExploration
+	case "Edit", "MultiEdit", "Write", "NotebookEdit":
+		return PhaseImplementation
+	case "Bash":
+		if verifyCommand.MatchString(cmd.RawCommand) {
+			return PhaseVerification
+		}
+	}
+	return ""
+}
+
+// PhaseSpan is a stretch of a session spent in one phase. It runs from its
+// first command up to the next span's first command.
+type PhaseSpan struct {
+	Phase    Phase
+	Start    time.Time
+	End      time.Time
+	Commands int
+}
+
+// IdleSpan is a pause of at least IdleGap between two commands
+type IdleSpan struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Timeline is how a session spent its time, from its first command to its last
+type Timeline struct {
+	Start  time.Time
+	End    time.Time
+	Active time.Duration // Duration less the idle gaps
+	Idle   []IdleSpan
+	Phases []PhaseSpan // Empty when no command points to a phase
+}
+
+// Duration returns the time from the first command to the last
+func (t *Timeline) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+// PhaseAt returns the phase at time at, or "" outside the phases
+func (t *Timeline) PhaseAt(at time.Time) Phase {
+	i := sort.Search(len(t.Phases), func(i int) bool { return t.Phases[i].Start.After(at) })
+	if i == 0 {
+		return ""
+	}
+	return t.Phases[i-1].Phase
+}
+
+// IdleAt reports whether time at falls in an idle gap
+func (t *Timeline) IdleAt(at time.Time) bool {
+	for _, gap := range t.Idle {
+		if at.After(gap.Start) && at.Before(gap.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionTimeline computes a session's duration, idle gaps, and phases from
+// its commands. Each command takes the phase most of its neighbors point
+// to, and runs of the same phase form a span.
+func SessionTimeline(sess *Session) *Timeline {
+	t := &Timeline{}
+	if len(sess.Commands) == 0 {
+		return t
+	}
+
+	// Subagent commands are appended out of order
+	cmds := make([]*CommandEntry, len(sess.Commands))
+	for i := range sess.Commands {
+		cmds[i] = &sess.Commands[i]
+	}
+	sort.SliceStable(cmds, func(i, j int) bool { return cmds[i].Timestamp.Before(cmds[j].Timestamp) })
+
+	t.Start, t.End = cmds[0].Timestamp, cmds[len(cmds)-1].Timestamp
+	t.Active = t.Duration()
+	for i := 1; i < len(cmds); i++ {
+		if gap := cmds[i].Timestamp.Sub(cmds[i-1].Timestamp); gap >= IdleGap {
+			t.Idle = append(t.Idle, IdleSpan{Start: cmds[i-1].Timestamp, End: cmds[i].Timestamp})
+			t.Active -= gap
+		}
+	}
+
+	own := make([]Phase, len(cmds))
+	for i, cmd := range cmds {
+		own[i] = CommandPhase(cmd)
+	}
+	var prev Phase
+	for i, cmd := range cmds {
+		phase := votePhase(own, i)
+		if phase == "" {
+			phase = prev
+		}
+		if phase == "" {
+			continue // Leading commands before any phase join the first span
+		}
+		prev = phase
+		if n := len(t.Phases); n > 0 && t.Phases[n-1].Phase == phase {
+			t.Phases[n-1].Commands++
+			continue
+		}
+		if n := len(t.Phases); n > 0 {
+			t.Phases[n-1].End = cmd.Timestamp
+		}
+		t.Phases = append(t.Phases, PhaseSpan{Phase: phase, Start: cmd.Timestamp, Commands: 1})
+	}
+	if len(t.Phases) > 0 {
+		t.Phases[0].Start = t.Start
+		t.Phases[0].Commands += len(cmds) - phaseCommands(t.Phases)
+		t.Phases[len(t.Phases)-1].End = t.End
+	}
+	return t
+}
+
+// votePhase returns the phase most commands within phaseRadius of command i
+// point to; ties go to command i's own phase, then to the earlier phase
+func votePhase(own []Phase, i int) Phase {
+	votes := make(map[Phase]int)
+	for j := max(0, i-phaseRadius); j <= min(len(own)-1, i+phaseRadius); j++ {
+		if own[j] != "" {
+			votes[own[j]]++
+		}
+	}
+	best := own[i]
+	for _, phase := range Phases {
+		if votes[phase] > votes[best] {
+			best = phase
+		}
+	}
+	return best
+}
+
+// phaseCommands returns the commands counted in spans
+func phaseCommands(spans []PhaseSpan) int {
+	n := 0
+	for _, s := range spans {
+		n += s.Commands
+	}
+	return n
+}
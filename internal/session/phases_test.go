@@ -0,0 +1,92 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommandPhase(t *testing.T) {
+	tests := []struct {
+		tool, command string
+		want          Phase
+	}{
+		{"Grep", "TODO", PhaseExploration},
+		{"Read", "/code/app/main.go", PhaseExploration},
+		{"Edit", "/code/app/main.go", PhaseImplementation},
+		{"Write", "/code/app/new.go", PhaseImplementation},
+		{"Bash", "go test ./...", PhaseVerification},
+		{"Bash", "cd web && npm run test", PhaseVerification},
+		{"Bash", "python -m pytest -x", PhaseVerification},
+		{"Bash", "git status", ""},
+		{"Bash", "echo gotest", ""},
+		{"Task", "explore the code", ""},
+	}
+	for _, tt := range tests {
+		if got := CommandPhase(&CommandEntry{ToolName: tt.tool, RawCommand: tt.command}); got != tt.want {
+			t.Errorf("CommandPhase(%s %q) = %q, want %q", tt.tool, tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestSessionTimeline(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	step := func(n int) time.Time { return at.Add(time.Duration(n) * time.Minute) }
+	sess := &Session{Commands: []CommandEntry{
+		{ToolName: "Bash", RawCommand: "git log", Timestamp: step(0)},
+		{ToolName: "Read", Timestamp: step(1)},
+		{ToolName: "Grep", Timestamp: step(2)},
+		{ToolName: "Read", Timestamp: step(3)},
+		{ToolName: "Edit", Timestamp: step(4)},
+		{ToolName: "Edit", Timestamp: step(5)},
+		{ToolName: "Read", Timestamp: step(6)}, // A stray read among edits
+		{ToolName: "Edit", Timestamp: step(7)},
+		{ToolName: "Write", Timestamp: step(8)},
+		// Twenty minutes away, then tests
+		{ToolName: "Bash", RawCommand: "go test ./...", Timestamp: step(28)},
+		{ToolName: "Bash", RawCommand: "go vet ./...", Timestamp: step(29)},
+		{ToolName: "Bash", RawCommand: "go test ./...", Timestamp: step(30)},
+	}}
+	// Subagent commands arrive out of order
+	sess.Commands[3], sess.Commands[10] = sess.Commands[10], sess.Commands[3]
+
+	tl := SessionTimeline(sess)
+	if tl.Duration() != 30*time.Minute || tl.Active != 10*time.Minute {
+		t.Errorf("duration %v, active %v; want 30m0s, 10m0s", tl.Duration(), tl.Active)
+	}
+	if len(tl.Idle) != 1 || !tl.Idle[0].Start.Equal(step(8)) || !tl.Idle[0].End.Equal(step(28)) {
+		t.Errorf("idle = %+v, want one gap from minute 8 to 28", tl.Idle)
+	}
+
+	want := []PhaseSpan{
+		// The first edit still has mostly reads around it
+		{PhaseExploration, step(0), step(5), 5},
+		{PhaseImplementation, step(5), step(28), 4},
+		{PhaseVerification, step(28), step(30), 3},
+	}
+	if len(tl.Phases) != len(want) {
+		t.Fatalf("phases = %+v, want %d spans", tl.Phases, len(want))
+	}
+	for i, span := range tl.Phases {
+		w := want[i]
+		if span.Phase != w.Phase || !span.Start.Equal(w.Start) || !span.End.Equal(w.End) || span.Commands != w.Commands {
+			t.Errorf("span %d = %+v, want %+v", i, span, w)
+		}
+	}
+	if got := tl.PhaseAt(step(6)); got != PhaseImplementation {
+		t.Errorf("PhaseAt(minute 6) = %q, want implementation", got)
+	}
+	if !tl.IdleAt(step(15)) || tl.IdleAt(step(5)) {
+		t.Error("IdleAt should hold only inside the gap")
+	}
+}
+
+func TestSessionTimelineEmpty(t *testing.T) {
+	tl := SessionTimeline(&Session{})
+	if tl.Duration() != 0 || len(tl.Phases) != 0 {
+		t.Errorf("empty session timeline = %+v, want zero", tl)
+	}
+	tl = SessionTimeline(&Session{Commands: []CommandEntry{{ToolName: "Bash", RawCommand: "ls"}}})
+	if len(tl.Phases) != 0 {
+		t.Errorf("phases = %+v, want none without phase commands", tl.Phases)
+	}
+}
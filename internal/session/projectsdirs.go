@@ -0,0 +1,57 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cc_session_mon/internal/config"
+)
+
+// ExpandProjectsDirs expands configured projects directories: a leading ~
+// and environment variables ($WORKSPACE, ${WORKSPACE}) are expanded, and
+// entries with glob characters (~/machines/*/home/*/.claude/projects) are
+// replaced by the directories they match. Plain entries are kept even if
+// they don't exist yet, as the watcher picks them up once created; entries
+// naming unset variables are dropped. The result is sorted and without
+// duplicates.
+func ExpandProjectsDirs(entries []string) []string {
+	var dirs []string
+	for _, entry := range entries {
+		pattern, ok := expandPath(entry)
+		if !ok {
+			continue
+		}
+		if !hasGlob(pattern) {
+			dirs = append(dirs, filepath.Clean(pattern))
+			continue
+		}
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				dirs = append(dirs, match)
+			}
+		}
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
+
+// expandPath expands a leading ~ and environment variables in path,
+// reporting false if a variable is unset
+func expandPath(path string) (string, bool) {
+	path = config.ExpandHome(path)
+	set := true
+	path = os.Expand(path, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		set = set && ok
+		return value
+	})
+	return path, set
+}
+
+// hasGlob reports whether path has glob metacharacters
+func hasGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
@@ -0,0 +1,34 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExpandProjectsDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"laptop/home/alice/.claude/projects", "laptop/home/bob/.claude", "desktop/home/alice/.claude/projects"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", root)
+	t.Setenv("WORKSPACE", filepath.Join(root, "ws"))
+
+	got := ExpandProjectsDirs([]string{
+		"~/*/home/*/.claude/projects",            // bob has no projects directory
+		"$WORKSPACE/.claude/projects",            // Kept though it doesn't exist yet
+		"${WORKSPACE}/.claude/projects/",         // The same directory
+		"$CC_SESSION_MON_UNSET/.claude/projects", // Dropped
+	})
+	want := []string{
+		filepath.Join(root, "desktop/home/alice/.claude/projects"),
+		filepath.Join(root, "laptop/home/alice/.claude/projects"),
+		filepath.Join(root, "ws/.claude/projects"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ExpandProjectsDirs() = %v, want %v", got, want)
+	}
+}
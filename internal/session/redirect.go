@@ -0,0 +1,61 @@
+package session
+
+import "strings"
+
+// WriteTargets returns the files a Bash command line writes through output
+// redirects (> file, >> file, 2>file, &>file) and tee. Heredoc bodies are
+// ignored, and file descriptor duplications like 2>&1 are not targets.
+// Paths are returned as written (possibly relative).
+func WriteTargets(command string) []string {
+	script, _ := SplitHeredocs(command)
+	words := shellWords(script)
+
+	var targets []string
+	for i := 0; i < len(words); i++ {
+		target, ok := redirectTarget(words[i])
+		if !ok {
+			continue
+		}
+		if target == "" && i+1 < len(words) {
+			i++
+			target = words[i]
+		}
+		if target != "" {
+			targets = append(targets, target)
+		}
+	}
+
+	for _, cmd := range SimpleCommands(script) {
+		if cmd[0] != "tee" {
+			continue
+		}
+		for i := 1; i < len(cmd); i++ {
+			if target, ok := redirectTarget(cmd[i]); ok {
+				if target == "" {
+					i++ // Already collected above
+				}
+				continue
+			}
+			if !strings.HasPrefix(cmd[i], "-") && !strings.HasPrefix(cmd[i], "<") {
+				targets = append(targets, cmd[i])
+			}
+		}
+	}
+	return targets
+}
+
+// redirectTarget reports whether word is an output redirect and returns the
+// target attached to it ("" when the target is the next word)
+func redirectTarget(word string) (string, bool) {
+	rest := strings.TrimLeft(word, "0123456789")
+	rest = strings.TrimPrefix(rest, "&")
+	if !strings.HasPrefix(rest, ">") {
+		return "", false
+	}
+	rest = strings.TrimLeft(rest, ">")
+	rest = strings.TrimPrefix(rest, "|") // >| forces overwrite
+	if strings.HasPrefix(rest, "&") {
+		return "", false // fd duplication (2>&1)
+	}
+	return rest, true
+}
@@ -0,0 +1,29 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWriteTargets(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"ls -la", nil},
+		{"echo hi > out.txt", []string{"out.txt"}},
+		{"echo hi >>/etc/hosts", []string{"/etc/hosts"}},
+		{"make 2>&1 > build.log", []string{"build.log"}},
+		{"go test ./... 2>/dev/null", []string{"/dev/null"}},
+		{"cmd &> all.log", []string{"all.log"}},
+		{`echo "a > b"`, nil},
+		{"echo x | sudo tee -a /etc/profile > /dev/null", []string{"/dev/null", "/etc/profile"}},
+		{"cat <<EOF > ~/.bashrc\nexport X=1 > nope\nEOF", []string{"~/.bashrc"}},
+	}
+
+	for _, tt := range tests {
+		if got := WriteTargets(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WriteTargets(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
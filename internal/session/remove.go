@@ -0,0 +1,108 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cc_session_mon/internal/config"
+)
+
+// DefaultArchiveDir is where archived sessions go, relative to the parent of
+// the projects directory they were found in (e.g. ~/.claude/session-archive)
+const DefaultArchiveDir = "session-archive"
+
+// SessionFiles returns what a session occupies on disk: its JSONL file and,
+// if it exists, the directory of the same name holding its subagent
+// transcripts and tool results
+func SessionFiles(filePath string) []string {
+	files := []string{filePath}
+	dir := strings.TrimSuffix(filePath, ".jsonl")
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		files = append(files, dir)
+	}
+	return files
+}
+
+// RemoveSession deletes a session's files from disk or, with archive set,
+// moves them into a per-project subdirectory of archiveDir (DefaultArchiveDir
+// next to the session's projects directory when empty; a leading ~/ is the
+// home directory), returning that subdirectory. Active sessions are refused, since Claude Code is still
+// writing them. The watcher stops tracking a removed session.
+func (w *Watcher) RemoveSession(filePath string, archive bool, archiveDir string) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.replica {
+		return "", errors.New("sessions can only be removed by the instance that owns them")
+	}
+	sess, ok := w.sessions[filePath]
+	if !ok {
+		return "", fmt.Errorf("%s is not a tracked session", filePath)
+	}
+	if sess.IsActive {
+		return "", errors.New("the session is active; wait until it is idle")
+	}
+
+	projectDir := filepath.Dir(filePath)
+	projectsDir := filepath.Dir(projectDir)
+	if !slices.ContainsFunc(w.projectsDirs, func(dir string) bool { return filepath.Clean(dir) == projectsDir }) {
+		return "", fmt.Errorf("%s is not in a watched projects directory", filePath)
+	}
+
+	files := SessionFiles(filePath)
+	dest := ""
+	if archive {
+		if archiveDir == "" {
+			archiveDir = filepath.Join(filepath.Dir(projectsDir), DefaultArchiveDir)
+		} else {
+			archiveDir = config.ExpandHome(archiveDir)
+		}
+		dest = filepath.Join(archiveDir, filepath.Base(projectDir))
+		if err := os.MkdirAll(dest, 0o700); err != nil {
+			return "", err
+		}
+		for _, f := range files {
+			target := filepath.Join(dest, filepath.Base(f))
+			if _, err := os.Lstat(target); err == nil {
+				return "", fmt.Errorf("%s is already archived", target)
+			}
+		}
+	}
+
+	for _, f := range files {
+		var err error
+		if archive {
+			err = os.Rename(f, filepath.Join(dest, filepath.Base(f)))
+		} else {
+			err = os.RemoveAll(f)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	w.forget(filePath)
+	return dest, nil
+}
+
+// forget stops tracking a session and its subagent files. Must be called
+// with w.mu held for writing.
+func (w *Watcher) forget(filePath string) {
+	for sub, main := range w.subagentMap {
+		if main == filePath {
+			delete(w.subagentMap, sub)
+			delete(w.offsets, sub)
+			delete(w.lineNumbers, sub)
+			delete(w.thinking, sub)
+		}
+	}
+	delete(w.sessions, filePath)
+	delete(w.offsets, filePath)
+	delete(w.lineNumbers, filePath)
+	delete(w.thinking, filePath)
+	w.invalidateSortedCache()
+}
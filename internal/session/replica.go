@@ -0,0 +1,95 @@
+package session
+
+import "sort"
+
+// NewReplicaWatcher creates a watcher that tracks no directories. Its state is
+// driven entirely by Inject, for consumers that receive events from elsewhere
+// (e.g. a shared monitor instance) and must not re-parse session files.
+func NewReplicaWatcher() (*Watcher, error) {
+	w, err := NewWatcher(nil)
+	if err != nil {
+		return nil, err
+	}
+	w.replica = true
+	return w, nil
+}
+
+// IsReplica returns true if the watcher is fed by Inject rather than the filesystem
+func (w *Watcher) IsReplica() bool {
+	return w.replica
+}
+
+// Inject applies an event produced by another watcher and re-emits it.
+// "discovered" replaces the session's commands, "new_commands" appends
+// event.Commands, "updated" refreshes only session metadata, and "removed"
+// stops tracking the session.
+func (w *Watcher) Inject(event WatchEvent) {
+	if event.Session == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if event.Type == "removed" {
+		if sess, exists := w.sessions[event.Session.FilePath]; exists {
+			w.forget(event.Session.FilePath)
+			w.emit(WatchEvent{Type: event.Type, Session: sess})
+		}
+		return
+	}
+
+	sess, exists := w.sessions[event.Session.FilePath]
+	if !exists {
+		sess = &Session{}
+		w.sessions[event.Session.FilePath] = sess
+	}
+	applySessionHeader(sess, event.Session)
+
+	switch event.Type {
+	case "discovered":
+		sess.Commands = append([]CommandEntry(nil), event.Session.Commands...)
+	case "new_commands":
+		sess.Commands = append(sess.Commands, event.Commands...)
+	}
+	w.invalidateSortedCache()
+
+	w.emit(WatchEvent{
+		Type:     event.Type,
+		Session:  sess,
+		Commands: event.Commands,
+	})
+}
+
+// applySessionHeader copies metadata (everything but Commands) from src to dst
+func applySessionHeader(dst, src *Session) {
+	dst.ID = src.ID
+	dst.ProjectPath = src.ProjectPath
+	dst.FilePath = src.FilePath
+	dst.GitBranch = src.GitBranch
+	dst.LastActivity = src.LastActivity
+	dst.IsActive = src.IsActive
+	dst.Origin = src.Origin
+	dst.CWD = src.CWD
+	dst.Flags = src.Flags
+	dst.Duplicates = src.Duplicates
+	dst.Thinking = src.Thinking
+	dst.Markers = src.Markers
+	dst.RootUUID = src.RootUUID
+	dst.LastUUID = src.LastUUID
+	dst.StartedAt = src.StartedAt
+	dst.ResumedFrom = src.ResumedFrom
+}
+
+// trackedSessions returns all tracked sessions sorted by last activity.
+// Must be called with w.mu held.
+func (w *Watcher) trackedSessions() []*Session {
+	sessions := make([]*Session, 0, len(w.sessions))
+	for _, s := range w.sessions {
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastActivity.After(sessions[j].LastActivity)
+	})
+	return sessions
+}
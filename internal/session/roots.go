@@ -0,0 +1,153 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RootError reports a projects directory that exists but can't be read
+// (permissions, a stale mount); its sessions are missing until RetryRoot
+// succeeds
+type RootError struct {
+	Root string
+	Err  error
+}
+
+func (e *RootError) Error() string {
+	return fmt.Sprintf("projects directory %s can't be read: %v", e.Root, e.Err)
+}
+
+func (e *RootError) Unwrap() error {
+	return e.Err
+}
+
+// reportRootError passes a projects directory's read failure to Errors
+// without blocking. A missing directory isn't one: watchRoot waits for it.
+func (w *Watcher) reportRootError(projectsDir string, err error) {
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	select {
+	case w.Errors <- &RootError{Root: projectsDir, Err: err}:
+	default:
+	}
+}
+
+// RetryRoot reads a projects directory that failed again, discovering its
+// sessions (sent as "discovered" events) if it can be read now
+func (w *Watcher) RetryRoot(projectsDir string) error {
+	if _, err := os.ReadDir(projectsDir); err != nil {
+		return &RootError{Root: projectsDir, Err: err}
+	}
+	if w.watchRoot(projectsDir) {
+		w.discoverRoot(projectsDir)
+	}
+	return nil
+}
+
+// watchRoot watches a projects directory or, while it doesn't exist (a
+// devagent container that hasn't started, a home without .claude yet), its
+// nearest existing ancestor, so its creation is seen. It reports whether
+// the projects directory itself is watched.
+func (w *Watcher) watchRoot(projectsDir string) bool {
+	for dir := projectsDir; ; dir = filepath.Dir(dir) {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			w.watch(dir, levelRoot)
+			return dir == projectsDir
+		}
+		if dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+// handleNewDir handles a directory created under a watched one. When it is
+// a missing projects directory, or on the way to one, the projects directory
+// is discovered in full, since it may already hold sessions (a container's
+// volume appearing, or mkdir -p racing the watch). Directories inside a
+// projects directory are watched, and a new project's session files or a new
+// session directory's subagent transcripts written before the watch was added
+// are picked up.
+func (w *Watcher) handleNewDir(dir string) {
+	for _, projectsDir := range w.ProjectsDirs() {
+		switch {
+		case dir == projectsDir || within(projectsDir, dir):
+			if w.watchRoot(projectsDir) {
+				w.discoverRoot(projectsDir)
+			}
+		case filepath.Dir(dir) == projectsDir:
+			w.watch(dir, levelProject)
+			w.discoverProject(dir)
+		case within(dir, projectsDir):
+			w.watch(dir, levelSession)
+			w.discoverSubagents(dir)
+		}
+	}
+}
+
+// discoverRoot watches each project directory in a projects directory that
+// appeared, and discovers their sessions
+func (w *Watcher) discoverRoot(projectsDir string) {
+	entries, err := os.ReadDir(projectsDir)
+	if err != nil {
+		w.reportRootError(projectsDir, err)
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			projectDir := filepath.Join(projectsDir, entry.Name())
+			w.watch(projectDir, levelProject)
+			w.discoverProject(projectDir)
+		}
+	}
+}
+
+// discoverProject picks up the session files in a project directory that
+// aren't tracked yet, each sent as a "discovered" event
+func (w *Watcher) discoverProject(projectDir string) {
+	files, _ := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
+	for _, path := range files {
+		w.handleNewFile(path)
+	}
+}
+
+// handleRemovedDir stops tracking the sessions under a removed or renamed
+// projects directory, one of its ancestors, or a project directory, each
+// sent as a "removed" event. A projects directory that went away is watched
+// for again, so its sessions come back if it does.
+func (w *Watcher) handleRemovedDir(path string) {
+	var gone []string
+	for _, projectsDir := range w.ProjectsDirs() {
+		switch {
+		case path == projectsDir || within(projectsDir, path):
+			gone = append(gone, projectsDir)
+			w.watchRoot(projectsDir)
+		case filepath.Dir(path) == projectsDir && !strings.HasSuffix(path, ".jsonl"):
+			gone = append(gone, path)
+		}
+	}
+	if len(gone) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for filePath, sess := range w.sessions {
+		for _, dir := range gone {
+			if within(filePath, dir) {
+				w.forget(filePath)
+				w.emit(WatchEvent{Type: "removed", Session: sess})
+				break
+			}
+		}
+	}
+}
+
+// within reports whether path is inside dir
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,112 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Line scanner buffer sizes: buffers start at scanBufferSize and grow for
+// lines up to maxLineSize
+const (
+	scanBufferSize = 64 * 1024
+	maxLineSize    = 2 * 1024 * 1024
+)
+
+// scanBuffers pools line scanner buffers. The watcher scans a file on every
+// write, and under a busy agent a fresh buffer per event dominated its
+// allocations.
+var scanBuffers = sync.Pool{New: func() any {
+	buf := make([]byte, 0, scanBufferSize)
+	return &buf
+}}
+
+// newLineScanner returns a scanner of r's lines (see scanLines) using a
+// pooled buffer, and a function that returns the buffer to the pool. Lines
+// from the scanner must not be kept after calling it.
+func newLineScanner(r io.Reader) (*bufio.Scanner, func()) {
+	buf := scanBuffers.Get().(*[]byte)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(*buf, maxLineSize)
+	scanner.Split(scanLines)
+	return scanner, func() { scanBuffers.Put(buf) }
+}
+
+// utf8BOM is the byte order mark some Windows editors and tools write at
+// the start of a file
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// scanLines is bufio.ScanLines, which already drops the CR of a CRLF line
+// ending, also dropping a byte order mark before the line
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	return advance, bytes.TrimPrefix(token, utf8BOM), err
+}
+
+// scanRecords returns a split function like scanLines that adds the bytes
+// each line takes up, line ending and byte order mark included, to
+// *consumed. At EOF a final line without a newline is returned only if it
+// is a complete JSON record; a partial one is left unconsumed for the
+// writer to finish.
+func scanRecords(consumed *int64) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) > 0 && bytes.IndexByte(data, '\n') < 0 && !json.Valid(bytes.TrimPrefix(data, utf8BOM)) {
+			return 0, nil, nil
+		}
+		advance, token, err := scanLines(data, atEOF)
+		*consumed += int64(advance)
+		return advance, token, err
+	}
+}
+
+// parseStates pools parse states for their lookup maps, which are cleared
+// rather than reallocated between parses
+var parseStates = sync.Pool{New: func() any {
+	return &parseState{
+		seen:     make(map[seenKey]bool),
+		toolUses: make(map[string]int),
+		jobs:     make(map[string]int),
+	}
+}}
+
+// release returns the parse state to the pool. The commands and metadata
+// it produced belong to the caller and are not reused.
+func (ps *parseState) release() {
+	clear(ps.seen)
+	clear(ps.toolUses)
+	clear(ps.jobs)
+	*ps = parseState{seen: ps.seen, toolUses: ps.toolUses, jobs: ps.jobs}
+	parseStates.Put(ps)
+}
+
+// skipped is a JSON value that is decoded without being stored
+type skipped struct{}
+
+// UnmarshalJSON discards the value
+func (*skipped) UnmarshalJSON([]byte) error { return nil }
+
+// recordHeader decodes a JSONL record without its message, for lines whose
+// message holds nothing the scanner reads. Tool results are most of a
+// session file's bytes, and this leaves their content undecoded.
+type recordHeader struct {
+	*JSONLRecord
+	Message skipped `json:"message"`
+}
+
+// messageHints are substrings of every line whose message the scanner
+// reads: tool calls, thinking blocks, failed results, and background
+// shells starting
+var messageHints = [][]byte{[]byte(`"tool_use"`), []byte(`thinking"`), []byte(`"is_error":true`), []byte("in background with ID")}
+
+// decodeRecord decodes a JSONL line, leaving out the message unless the
+// line can hold something in it the scanner reads
+func decodeRecord(line []byte, record *JSONLRecord) error {
+	for _, hint := range messageHints {
+		if bytes.Contains(line, hint) {
+			return json.Unmarshal(line, record)
+		}
+	}
+	return json.Unmarshal(line, &recordHeader{JSONLRecord: record})
+}
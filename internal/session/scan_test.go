@@ -0,0 +1,137 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPooledParseStateStartsClean(t *testing.T) {
+	path := writeBackgroundSession(t)
+
+	// The second parse reuses the first one's maps; nothing may carry over
+	for range 2 {
+		commands, _, err := ParseSessionFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(commands) != 1 || commands[0].Polls != 2 {
+			t.Fatalf("expected one job polled twice, got %+v", commands)
+		}
+	}
+}
+
+func TestDecodeRecordSkipsUnreadMessages(t *testing.T) {
+	var record JSONLRecord
+	line := `{"type":"user","uuid":"u2","cwd":"/p","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`
+	if err := decodeRecord([]byte(line), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Message != nil || record.UUID != "u2" || record.CWD != "/p" {
+		t.Errorf("expected the header without the message, got %+v", record)
+	}
+
+	line = `{"type":"user","uuid":"u4","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","is_error":true,"content":"boom"}]}}`
+	if err := decodeRecord([]byte(line), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Message == nil || len(record.Message.Content) != 1 || !record.Message.Content[0].IsError {
+		t.Errorf("expected a failed result's message to be decoded, got %+v", record.Message)
+	}
+}
+
+func TestParseSessionFileFromHoldsBackPartialLine(t *testing.T) {
+	toolUse := func(n int) string {
+		return fmt.Sprintf(`{"type":"assistant","uuid":"u%d","message":{"role":"assistant","content":[{"type":"tool_use","id":"t%d","name":"Bash","input":{"command":"echo %d"}}]}}`, n, n, n)
+	}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var offset int64
+	var line int
+	read := func() []CommandEntry {
+		t.Helper()
+		commands, _, newOffset, newLine, err := ParseSessionFileFrom(path, offset, line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		offset, line = newOffset, newLine
+		return commands
+	}
+
+	// The watcher wakes while the second record is half written
+	second := toolUse(2)
+	appendFile(t, path, toolUse(1)+"\n"+second[:40])
+	if commands := read(); len(commands) != 1 || offset != int64(len(toolUse(1))+1) {
+		t.Fatalf("expected only the complete record read, got %d commands, offset %d", len(commands), offset)
+	}
+	appendFile(t, path, second[40:]+"\n")
+	if commands := read(); len(commands) != 1 || commands[0].RawCommand != "echo 2" || commands[0].LineNumber != 2 {
+		t.Fatalf("expected the finished record on line 2, got %+v", commands)
+	}
+
+	// A whole record waiting for its newline is read, and the newline
+	// arriving later doesn't count as a line of its own
+	appendFile(t, path, toolUse(3))
+	if commands := read(); len(commands) != 1 || commands[0].LineNumber != 3 {
+		t.Fatalf("expected the unterminated record on line 3, got %+v", commands)
+	}
+	appendFile(t, path, "\n"+toolUse(4)+"\n")
+	if commands := read(); len(commands) != 1 || commands[0].RawCommand != "echo 4" || commands[0].LineNumber != 4 {
+		t.Fatalf("expected the next record on line 4, got %+v", commands)
+	}
+}
+
+func TestWindowsLineEndingsAndBOM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	data := "\ufeff" + strings.Join(backgroundSession[:2], "\r\n") + "\r\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, _, err := ParseSessionFile(path)
+	if err != nil || len(commands) != 1 || commands[0].BackgroundID != "bash_1" {
+		t.Fatalf("expected the first record parsed despite the BOM, got %+v, %v", commands, err)
+	}
+	_, _, offset, line, err := ParseSessionFileFrom(path, 0, 0)
+	if err != nil || offset != int64(len(data)) || line != 2 {
+		t.Fatalf("expected offset %d line 2, got %d line %d, %v", len(data), offset, line, err)
+	}
+
+	// A record waiting for its LF after the CR: the LF isn't an empty line
+	more := backgroundSession[2] + "\r"
+	appendFile(t, path, more)
+	commands, _, offset, line, err = ParseSessionFileFrom(path, offset, line)
+	if err != nil || len(commands) != 0 || line != 3 {
+		t.Fatalf("expected the BashOutput call folded on line 3, got %+v line %d, %v", commands, line, err)
+	}
+	appendFile(t, path, "\n"+backgroundSession[6]+"\r\n")
+	commands, meta, offset, line, err := ParseSessionFileFrom(path, offset, line)
+	if err != nil || line != 4 || len(meta.JobCalls) != 1 {
+		t.Fatalf("expected the KillShell call on line 4, got %+v line %d, %v", commands, line, err)
+	}
+	if info, _ := os.Stat(path); offset != info.Size() {
+		t.Errorf("offset %d, want the file size %d", offset, info.Size())
+	}
+
+	input, err := FetchToolInput(path, 1, "Bash", "u1")
+	if err != nil || input.Result != "Command running in background with ID: bash_1" {
+		t.Errorf("expected the tool input and result, got %+v, %v", input, err)
+	}
+}
+
+// appendFile appends s to the file at path
+func appendFile(t *testing.T, path, s string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(s); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,215 @@
+package session
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+)
+
+// SharedProjects is a user's projects directory in a shared directory that
+// a team's session files are synced to
+type SharedProjects struct {
+	Dir  string // The user's projects directory
+	User string // Name of the user's subfolder
+}
+
+// Origin returns the origin label for the user's sessions, e.g. "shared:alice"
+func (s SharedProjects) Origin() string {
+	return "shared:" + s.User
+}
+
+// FindSharedProjects returns the users' projects directories in shared
+// directories (shared_dirs), sorted and without duplicates. Each subfolder
+// of a shared directory is a user, holding .claude/projects, projects, or
+// the project directories themselves; hidden subfolders (a sync tool's
+// state) are skipped. Entries may start with ~ or $VARS.
+func FindSharedProjects(dirs []string) []SharedProjects {
+	var found []SharedProjects
+	for _, entry := range dirs {
+		dir, ok := expandPath(entry)
+		if !ok {
+			continue
+		}
+		users, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, user := range users {
+			if !user.IsDir() || strings.HasPrefix(user.Name(), ".") {
+				continue
+			}
+			projects := userProjectsDir(filepath.Join(dir, user.Name()))
+			if !slices.ContainsFunc(found, func(s SharedProjects) bool { return s.Dir == projects }) {
+				found = append(found, SharedProjects{Dir: projects, User: user.Name()})
+			}
+		}
+	}
+	slices.SortFunc(found, func(a, b SharedProjects) int { return cmp.Compare(a.Dir, b.Dir) })
+	return found
+}
+
+// userProjectsDir returns the projects directory in a user's subfolder of a
+// shared directory
+func userProjectsDir(userDir string) string {
+	for _, dir := range []string{filepath.Join(userDir, ".claude", "projects"), filepath.Join(userDir, "projects")} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return userDir
+}
+
+// isShared reports whether origin is that of a shared directory
+func isShared(origin string) bool {
+	return strings.HasPrefix(origin, "shared:")
+}
+
+// isSynced reports whether origin's files are written by a sync, a shared
+// directory's or a remote source's mirror, rather than appended to by
+// Claude Code
+func isSynced(origin string) bool {
+	return isShared(origin) || strings.HasPrefix(origin, "remote:")
+}
+
+// watchesShared reports whether any projects directory watched is shared
+func (w *Watcher) watchesShared() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, origin := range w.originMap {
+		if isShared(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncedFile reports whether path is written by a sync
+func (w *Watcher) syncedFile(path string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return isSynced(w.originOf(path))
+}
+
+// mergeSynced reads a synced session or subagent file again in full and
+// merges it into its session. A sync replaces files whole, and may deliver
+// an older copy after a newer one, so offsets into the file can't be
+// trusted: commands are matched by tool_use ID instead, new ones are added
+// in time order, known ones only gain results (an error, a hook, a shell's
+// polls), and none are dropped because a copy lacks them.
+// Must be called with w.mu held for writing.
+func (w *Watcher) mergeSynced(path string, sess *Session, isSubagent bool) {
+	commands, meta, offset, line, err := ParseSessionFileFrom(path, 0, 0)
+	if err != nil {
+		return
+	}
+	normalizeTimes(sess.Origin, commands, &meta)
+	newer := offset >= w.offsets[path]
+	w.offsets[path] = offset
+	w.lineNumbers[path] = line
+
+	known := make(map[string]int, len(sess.Commands))
+	for i := range sess.Commands {
+		known[syncKey(&sess.Commands[i])] = i
+	}
+	var added []CommandEntry
+	changed := false
+	for i := range commands {
+		if j, ok := known[syncKey(&commands[i])]; ok {
+			changed = sess.Commands[j].mergeResults(&commands[i]) || changed
+		} else {
+			added = append(added, commands[i])
+		}
+	}
+
+	// Metadata comes from the newest copy only
+	if newer && !isSubagent {
+		if meta.CWD != "" && sess.CWD == "" {
+			sess.ProjectPath = meta.CWD
+		}
+		if meta.LastCWD != "" {
+			sess.CWD = meta.LastCWD
+		}
+		if meta.LastUUID != "" {
+			sess.LastUUID = meta.LastUUID
+		}
+		if len(meta.Markers) > len(sess.Markers) {
+			sess.Markers = meta.Markers
+			changed = true
+		}
+	}
+	if meta.GitBranch != "" && sess.GitBranch == "" {
+		sess.GitBranch = meta.GitBranch
+	}
+
+	if len(added) == 0 {
+		if changed {
+			w.emit(WatchEvent{Type: "updated", Session: sess})
+		}
+		return
+	}
+
+	// Synced commands may predate ones already shown; activity is when they
+	// ran, not when the sync delivered them
+	sess.Commands = append(sess.Commands, added...)
+	sort.SliceStable(sess.Commands, func(i, j int) bool {
+		return sess.Commands[i].Timestamp.Before(sess.Commands[j].Timestamp)
+	})
+	for i := range added {
+		sess.Thinking.Add(added[i].Thinking)
+		if added[i].Timestamp.After(sess.LastActivity) {
+			sess.LastActivity = added[i].Timestamp
+		}
+	}
+	sess.IsActive = time.Since(sess.LastActivity) < config.Global().ActivityWindow
+	w.invalidateSortedCache()
+
+	w.emit(WatchEvent{
+		Type:     "new_commands",
+		Session:  sess,
+		Commands: added,
+	})
+}
+
+// syncKey identifies a command across copies of a synced file: its tool_use
+// ID, or its file and line for calls recorded without one
+func syncKey(c *CommandEntry) string {
+	if c.ToolUseID != "" {
+		return c.ToolUseID
+	}
+	return c.FilePath + ":" + strconv.Itoa(c.LineNumber)
+}
+
+// mergeResults adds the results another copy of c's file recorded for it,
+// reporting whether c changed. Results only accumulate, so copies merge the
+// same whatever order they arrive in.
+func (c *CommandEntry) mergeResults(o *CommandEntry) bool {
+	changed := false
+	if o.IsError && !c.IsError {
+		c.IsError = true
+		changed = true
+	}
+	if o.BackgroundID != "" && c.BackgroundID == "" {
+		c.BackgroundID = o.BackgroundID
+		changed = true
+	}
+	if o.Polls > c.Polls {
+		c.Polls = o.Polls
+		changed = true
+	}
+	if o.Killed && !c.Killed {
+		c.Killed = true
+		changed = true
+	}
+	hooks := len(c.Hooks)
+	for _, run := range o.Hooks {
+		c.addHook(run)
+	}
+	return changed || len(c.Hooks) != hooks
+}
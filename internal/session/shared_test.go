@@ -0,0 +1,103 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestFindSharedProjects(t *testing.T) {
+	team := t.TempDir()
+	for _, dir := range []string{"alice/.claude/projects", "bob/projects", "carol/-work-app", ".sync/state"} {
+		if err := os.MkdirAll(filepath.Join(team, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(team, "README"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	found := FindSharedProjects([]string{team, team, filepath.Join(team, "missing")})
+	want := []SharedProjects{
+		{Dir: filepath.Join(team, "alice", ".claude", "projects"), User: "alice"},
+		{Dir: filepath.Join(team, "bob", "projects"), User: "bob"},
+		{Dir: filepath.Join(team, "carol"), User: "carol"},
+	}
+	if len(found) != len(want) {
+		t.Fatalf("got %+v, want %+v", found, want)
+	}
+	for i := range want {
+		if found[i] != want[i] {
+			t.Errorf("found[%d] = %+v, want %+v", i, found[i], want[i])
+		}
+	}
+	if origin := found[0].Origin(); origin != "shared:alice" {
+		t.Errorf("Origin() = %q", origin)
+	}
+}
+
+func TestSharedFilesMergedOutOfOrder(t *testing.T) {
+	// A sync delivers the session a few lines behind
+	lines := append(append([]string{}, backgroundSession...),
+		`{"type":"assistant","uuid":"u9","message":{"role":"assistant","content":[{"type":"tool_use","id":"t9","name":"Bash","input":{"command":"ls"}}]}}`)
+	root, path := writeSessionCopy(t, lines[:4])
+	w, err := NewWatcher([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	w.SetOrigin(root, "shared:alice")
+	if _, err := w.DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+	if !w.Polling() {
+		t.Error("expected shared directories to be polled")
+	}
+
+	// replace renames a copy over the file, as sync tools do
+	replace := func(lines []string) {
+		t.Helper()
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+		w.handleFSEvent(fsnotify.Event{Name: path, Op: fsnotify.Create})
+	}
+	session := func() *Session {
+		t.Helper()
+		sessions := w.GetSessions()
+		if len(sessions) != 1 {
+			t.Fatalf("expected 1 session, got %d", len(sessions))
+		}
+		return sessions[0]
+	}
+
+	// The newer copy adds a command and results for the known one
+	replace(lines)
+	ev := <-w.Events
+	if ev.Type != "new_commands" || len(ev.Commands) != 1 || ev.Commands[0].RawCommand != "ls" {
+		t.Fatalf("expected only the new command, got %s %+v", ev.Type, ev.Commands)
+	}
+	if cmds := session().Commands; len(cmds) != 2 || cmds[0].Polls != 2 || !cmds[0].Killed {
+		t.Fatalf("expected the background command's polls and kill merged, got %+v", cmds)
+	}
+
+	// An older copy arriving late, and the newer one again, change nothing
+	replace(lines[:4])
+	w.CatchUp()
+	replace(lines)
+	select {
+	case ev := <-w.Events:
+		t.Errorf("expected no events for copies already merged, got %s %+v", ev.Type, ev.Commands)
+	default:
+	}
+	if cmds := session().Commands; len(cmds) != 2 || cmds[0].Polls != 2 || !cmds[0].Killed {
+		t.Errorf("expected the merged commands kept, got %+v", cmds)
+	}
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// subagentScanDelay is how long after a Task call the session is checked for
+// subagent transcripts, giving Claude Code time to create the first one
+var subagentScanDelay = 2 * time.Second
+
+// startsSubagent reports whether any of commands is a Task call, which runs
+// a subagent writing its own transcript
+func startsSubagent(commands []CommandEntry) bool {
+	for i := range commands {
+		if commands[i].ToolName == "Task" {
+			return true
+		}
+	}
+	return false
+}
+
+// watchSubagents watches a session's subagents directory ahead of its first
+// transcript, or while it doesn't exist, the session directory that will hold
+// it. The project directory is watched already, and handleNewDir takes over
+// as the missing directories are created.
+func (w *Watcher) watchSubagents(sessionPath string) {
+	sessionDir := strings.TrimSuffix(sessionPath, ".jsonl")
+	for _, dir := range []string{filepath.Join(sessionDir, "subagents"), sessionDir} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			w.watch(dir, levelSession)
+			return
+		}
+	}
+}
+
+// discoverSubagents picks up the transcripts written to a session directory
+// or subagents directory that was just created, before its watch was added:
+// Claude Code creates the directories and the first transcript in quick
+// succession
+func (w *Watcher) discoverSubagents(dir string) {
+	subagentDir := dir
+	if filepath.Base(dir) != "subagents" {
+		subagentDir = filepath.Join(dir, "subagents")
+		if info, err := os.Stat(subagentDir); err != nil || !info.IsDir() {
+			return
+		}
+		w.watch(subagentDir, levelSession)
+	}
+	files, _ := filepath.Glob(filepath.Join(subagentDir, "*.jsonl"))
+	for _, path := range files {
+		w.handleNewFile(path)
+	}
+}
+
+// scheduleSubagentScan runs ScanForNewSubagents shortly, unless a scan is
+// already scheduled, to pick up the transcript of a subagent just started
+// even if the file watcher misses its creation
+func (w *Watcher) scheduleSubagentScan() {
+	if w.scanScheduled.Swap(true) {
+		return
+	}
+	time.AfterFunc(subagentScanDelay, func() {
+		w.scanScheduled.Store(false)
+		select {
+		case <-w.done:
+		default:
+			w.ScanForNewSubagents()
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestTaskCallSchedulesSubagentScan(t *testing.T) {
+	subagentScanDelay = 10 * time.Millisecond
+	t.Cleanup(func() { subagentScanDelay = 2 * time.Second })
+
+	_, path := writeSessionCopy(t, backgroundSession[:1])
+	w, err := NewWatcher([]string{filepath.Dir(filepath.Dir(path))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	if _, err := w.DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The subagent's transcript is created without an event reaching the watcher
+	appendFile(t, path, `{"type":"assistant","uuid":"u9","message":{"role":"assistant","content":[{"type":"tool_use","id":"t9","name":"Task","input":{"description":"Explore the parser","prompt":"..."}}]}}`+"\n")
+	subagents := filepath.Join(strings.TrimSuffix(path, ".jsonl"), "subagents")
+	if err := os.MkdirAll(subagents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(subagents, "agent-1.jsonl"), []byte(backgroundSession[2]+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w.handleFileUpdate(path)
+	if ev := <-w.Events; ev.Type != "new_commands" || ev.Commands[0].ToolName != "Task" {
+		t.Fatalf("expected the Task call, got %s %+v", ev.Type, ev.Commands)
+	}
+	select {
+	case ev := <-w.Events:
+		if ev.Type != "new_commands" || ev.Commands[0].ToolName != "BashOutput" {
+			t.Errorf("expected the subagent's command, got %s %+v", ev.Type, ev.Commands)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a scan after the Task call to find the subagent's transcript")
+	}
+}
+
+func TestTaskCallWatchesSubagents(t *testing.T) {
+	subagentScanDelay = time.Hour // Only the watches may find the transcript
+	t.Cleanup(func() { subagentScanDelay = 2 * time.Second })
+
+	_, path := writeSessionCopy(t, backgroundSession[:1])
+	w, err := NewWatcher([]string{filepath.Dir(filepath.Dir(path))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	if _, err := w.DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The session directory exists by the time of the Task call
+	sessionDir := strings.TrimSuffix(path, ".jsonl")
+	if err := os.Mkdir(sessionDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, `{"type":"assistant","uuid":"u9","message":{"role":"assistant","content":[{"type":"tool_use","id":"t9","name":"Task","input":{"description":"Explore the parser","prompt":"..."}}]}}`+"\n")
+	w.handleFileUpdate(path)
+	<-w.Events
+	if !slices.Contains(w.fsWatcher.WatchList(), sessionDir) {
+		t.Fatalf("expected the session directory watched, got %v", w.fsWatcher.WatchList())
+	}
+
+	// subagents/ and the first transcript are written before its watch is added
+	subagents := filepath.Join(sessionDir, "subagents")
+	if err := os.Mkdir(subagents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	transcript := filepath.Join(subagents, "agent-1.jsonl")
+	if err := os.WriteFile(transcript, []byte(backgroundSession[2]+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w.handleFSEvent(fsnotify.Event{Name: subagents, Op: fsnotify.Create})
+	if ev := <-w.Events; ev.Type != "new_commands" || ev.Commands[0].ToolName != "BashOutput" {
+		t.Fatalf("expected the subagent's command, got %s %+v", ev.Type, ev.Commands)
+	}
+	if !slices.Contains(w.fsWatcher.WatchList(), subagents) {
+		t.Errorf("expected the subagents directory watched, got %v", w.fsWatcher.WatchList())
+	}
+
+	// The transcript's own create event, arriving late, doesn't add it twice
+	w.handleFSEvent(fsnotify.Event{Name: transcript, Op: fsnotify.Create})
+	select {
+	case ev := <-w.Events:
+		t.Errorf("expected no event for a tracked transcript, got %s %+v", ev.Type, ev.Commands)
+	default:
+	}
+}
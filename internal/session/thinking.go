@@ -0,0 +1,92 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ThinkingStats counts extended-reasoning (thinking) blocks and their size
+type ThinkingStats struct {
+	Blocks int
+	Chars  int // Visible reasoning text; redacted blocks count as blocks only
+}
+
+// Add accumulates other into t
+func (t *ThinkingStats) Add(other ThinkingStats) {
+	t.Blocks += other.Blocks
+	t.Chars += other.Chars
+}
+
+// String renders the stats, e.g. "3 blocks, 4.2k chars"
+func (t ThinkingStats) String() string {
+	blocks := fmt.Sprintf("%d blocks", t.Blocks)
+	if t.Blocks == 1 {
+		blocks = "1 block"
+	}
+	if t.Chars < 1000 {
+		return fmt.Sprintf("%s, %d chars", blocks, t.Chars)
+	}
+	return fmt.Sprintf("%s, %.1fk chars", blocks, float64(t.Chars)/1000)
+}
+
+// redactedThinking stands in for a redacted_thinking block's encrypted text
+const redactedThinking = "[redacted]"
+
+// thinkingText returns a content item's reasoning text and whether it is a
+// thinking block at all
+func thinkingText(content *ContentItem) (string, bool) {
+	switch content.Type {
+	case "thinking":
+		return content.Thinking, true
+	case "redacted_thinking":
+		return redactedThinking, true
+	}
+	return "", false
+}
+
+// countThinking adds an assistant record's thinking blocks to the session's
+// totals and to the reasoning credited to the next tool call
+func (ps *parseState) countThinking(content *ContentItem) {
+	text, ok := thinkingText(content)
+	if !ok {
+		return
+	}
+	stats := ThinkingStats{Blocks: 1}
+	if content.Type == "thinking" {
+		stats.Chars = len([]rune(text))
+	}
+	ps.meta.Thinking.Add(stats)
+	ps.thinking.Add(stats)
+}
+
+// precedingThinking returns the thinking blocks in lines after the last
+// tool_use, oldest first: the reasoning leading up to the line that follows
+func precedingThinking(lines [][]byte) []string {
+	var blocks []string
+	for i := len(lines) - 1; i >= 0; i-- {
+		if !bytes.Contains(lines[i], []byte(`"tool_use"`)) && !bytes.Contains(lines[i], []byte(`thinking"`)) {
+			continue
+		}
+		var record JSONLRecord
+		if err := json.Unmarshal(lines[i], &record); err != nil || record.Message == nil || record.Type != "assistant" {
+			continue
+		}
+		var found []string
+		toolUse := false
+		for j := range record.Message.Content {
+			content := &record.Message.Content[j]
+			if content.Type == "tool_use" {
+				toolUse = true
+				found = nil // Only reasoning after the record's last tool call counts
+			} else if text, ok := thinkingText(content); ok {
+				found = append(found, text)
+			}
+		}
+		blocks = append(found, blocks...)
+		if toolUse {
+			break
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,51 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestThinkingStats(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","uuid":"u1","message":{"role":"assistant","content":[{"type":"thinking","thinking":"Check the tests first."}]}}`,
+		`{"type":"assistant","uuid":"u2","message":{"role":"assistant","content":[{"type":"redacted_thinking","data":"abc"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]}}`,
+		`{"type":"assistant","uuid":"u3","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"git status"}}]}}`,
+		`{"type":"assistant","uuid":"u4","message":{"role":"assistant","content":[{"type":"thinking","thinking":"Now delete it."},{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"rm -rf build"}}]}}`,
+		`{"type":"assistant","uuid":"u5","message":{"role":"assistant","content":[{"type":"thinking","thinking":"Done."}]}}`,
+	}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, meta, err := ParseSessionFile(path)
+	if err != nil || len(commands) != 3 {
+		t.Fatalf("expected 3 commands, got %d, %v", len(commands), err)
+	}
+	if meta.Thinking != (ThinkingStats{Blocks: 4, Chars: 41}) {
+		t.Errorf("session thinking = %+v", meta.Thinking)
+	}
+	if meta.TrailingThinking != (ThinkingStats{Blocks: 1, Chars: 5}) {
+		t.Errorf("trailing thinking = %+v", meta.TrailingThinking)
+	}
+	want := []ThinkingStats{{Blocks: 2, Chars: 22}, {}, {Blocks: 1, Chars: 14}}
+	for i, c := range commands {
+		if c.Thinking != want[i] {
+			t.Errorf("command %d thinking = %+v, want %+v", i, c.Thinking, want[i])
+		}
+	}
+
+	input, err := FetchToolInput(path, 2, "Bash", "u2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(input.Thinking, "|"); got != "Check the tests first.|[redacted]" {
+		t.Errorf("Thinking = %q", got)
+	}
+	input, err = FetchToolInput(path, 3, "Bash", "u3")
+	if err != nil || len(input.Thinking) != 0 {
+		t.Errorf("expected no reasoning after the previous call, got %q, %v", input.Thinking, err)
+	}
+}
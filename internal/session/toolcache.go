@@ -0,0 +1,86 @@
+package session
+
+import (
+	"container/list"
+	"sync"
+)
+
+// toolInputCacheSize caps the tool calls FetchToolInput remembers
+const toolInputCacheSize = 256
+
+// toolInputKey identifies a tool call within a session file
+type toolInputKey struct {
+	file, uuid, toolName string
+}
+
+// toolInputs caches FetchToolInput results so reopening a command doesn't
+// rescan its file. A completed result never changes, so it stays until
+// evicted; one still waiting for its tool_result (or, for a background
+// command, for its shell to end) is forgotten as soon as the watcher reads
+// new content from the file (ForgetPendingToolInputs).
+var toolInputs = struct {
+	sync.Mutex
+	entries     map[toolInputKey]*list.Element
+	order       *list.List     // Most recently used at the front
+	generations map[string]int // Per file, bumped each time pending entries are forgotten
+}{entries: make(map[toolInputKey]*list.Element), order: list.New(), generations: make(map[string]int)}
+
+// toolInputEntry is a cached tool call
+type toolInputEntry struct {
+	key   toolInputKey
+	input *ToolInput
+}
+
+// cachedToolInput returns the cached input for key, marking it recently
+// used. On a miss it returns the file's generation to pass to cacheToolInput.
+func cachedToolInput(key toolInputKey) (input *ToolInput, generation int, ok bool) {
+	toolInputs.Lock()
+	defer toolInputs.Unlock()
+
+	el, ok := toolInputs.entries[key]
+	if !ok {
+		return nil, toolInputs.generations[key.file], false
+	}
+	toolInputs.order.MoveToFront(el)
+	return el.Value.(*toolInputEntry).input, 0, true
+}
+
+// cacheToolInput remembers input for key, evicting the least recently used
+// entries beyond the cap. A pending input is dropped if the file's content
+// moved on (its generation changed) while it was being read.
+func cacheToolInput(key toolInputKey, generation int, input *ToolInput) {
+	toolInputs.Lock()
+	defer toolInputs.Unlock()
+
+	if !input.Settled() && toolInputs.generations[key.file] != generation {
+		return
+	}
+
+	if el, ok := toolInputs.entries[key]; ok {
+		el.Value.(*toolInputEntry).input = input
+		toolInputs.order.MoveToFront(el)
+		return
+	}
+	toolInputs.entries[key] = toolInputs.order.PushFront(&toolInputEntry{key: key, input: input})
+	for toolInputs.order.Len() > toolInputCacheSize {
+		oldest := toolInputs.order.Back()
+		toolInputs.order.Remove(oldest)
+		delete(toolInputs.entries, oldest.Value.(*toolInputEntry).key)
+	}
+}
+
+// ForgetPendingToolInputs drops the cached tool calls from file whose output
+// wasn't settled when they were fetched. The watcher calls it whenever
+// it reads new content, which may hold those results.
+func ForgetPendingToolInputs(file string) {
+	toolInputs.Lock()
+	defer toolInputs.Unlock()
+
+	toolInputs.generations[file]++
+	for key, el := range toolInputs.entries {
+		if key.file == file && !el.Value.(*toolInputEntry).input.Settled() {
+			toolInputs.order.Remove(el)
+			delete(toolInputs.entries, key)
+		}
+	}
+}
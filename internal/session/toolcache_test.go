@@ -0,0 +1,43 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFetchToolInputCachesUntilNewContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	call := `{"type":"assistant","uuid":"u1","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"sleep 5"}}]}}` + "\n"
+	if err := os.WriteFile(path, []byte(call), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	input, err := FetchToolInput(path, 1, "Bash", "u1")
+	if err != nil || input.HasResult {
+		t.Fatalf("expected a pending result, got %+v, %v", input, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"type":"user","uuid":"u2","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"done"}]}}` + "\n")
+	_ = f.Close()
+
+	if cached, _ := FetchToolInput(path, 1, "Bash", "u1"); cached != input {
+		t.Error("expected the pending result to be served from the cache until the watcher reads more")
+	}
+
+	ForgetPendingToolInputs(path)
+	input, err = FetchToolInput(path, 1, "Bash", "u1")
+	if err != nil || !input.HasResult || input.Result != "done" {
+		t.Fatalf("expected the result after new content, got %+v, %v", input, err)
+	}
+
+	// A completed result survives later content
+	ForgetPendingToolInputs(path)
+	if cached, _ := FetchToolInput(path, 1, "Bash", "u1"); cached != input {
+		t.Error("expected the completed result to stay cached")
+	}
+}
@@ -0,0 +1,81 @@
+package session
+
+import "sort"
+
+// Tool kinds a ToolMix splits commands into, in the order bars stack them
+const (
+	KindRead  = "Read"
+	KindEdit  = "Edit"
+	KindWrite = "Write"
+	KindBash  = "Bash"
+	KindOther = "Other"
+)
+
+// ToolMixKinds lists the tool kinds in bar order
+var ToolMixKinds = []string{KindRead, KindEdit, KindWrite, KindBash, KindOther}
+
+// ToolKind returns the kind a tool's calls count as in a ToolMix: reading
+// (Read, Glob, Grep, ...), editing existing files, writing new ones, shell
+// commands, or anything else
+func ToolKind(toolName string) string {
+	switch toolName {
+	case "Read", "Glob", "Grep", "LS", "NotebookRead":
+		return KindRead
+	case "Edit", "MultiEdit", "NotebookEdit":
+		return KindEdit
+	case "Write":
+		return KindWrite
+	case "Bash":
+		return KindBash
+	}
+	return KindOther
+}
+
+// ToolMix is how the commands of one project's sessions split between tool kinds
+type ToolMix struct {
+	ProjectPath string
+	Sessions    int
+	Total       int
+	Counts      map[string]int // Commands per tool kind
+}
+
+// Share returns the fraction (0-1) of the project's commands of one kind
+func (m *ToolMix) Share(kind string) float64 {
+	if m.Total == 0 {
+		return 0
+	}
+	return float64(m.Counts[kind]) / float64(m.Total)
+}
+
+// ToolMixByProject counts the commands of all sessions by project and tool
+// kind, most commands first (then by path). Projects without commands are left out.
+func ToolMixByProject(sessions []*Session) []*ToolMix {
+	mixes := make(map[string]*ToolMix)
+	for _, sess := range sessions {
+		if len(sess.Commands) == 0 {
+			continue
+		}
+		mix, ok := mixes[sess.ProjectPath]
+		if !ok {
+			mix = &ToolMix{ProjectPath: sess.ProjectPath, Counts: make(map[string]int)}
+			mixes[sess.ProjectPath] = mix
+		}
+		mix.Sessions++
+		for i := range sess.Commands {
+			mix.Counts[ToolKind(sess.Commands[i].ToolName)]++
+			mix.Total++
+		}
+	}
+
+	sorted := make([]*ToolMix, 0, len(mixes))
+	for _, mix := range mixes {
+		sorted = append(sorted, mix)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Total != sorted[j].Total {
+			return sorted[i].Total > sorted[j].Total
+		}
+		return sorted[i].ProjectPath < sorted[j].ProjectPath
+	})
+	return sorted
+}
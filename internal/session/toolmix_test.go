@@ -0,0 +1,37 @@
+package session
+
+import "testing"
+
+func TestToolMixByProject(t *testing.T) {
+	commands := func(tools ...string) []CommandEntry {
+		entries := make([]CommandEntry, len(tools))
+		for i, tool := range tools {
+			entries[i] = CommandEntry{ToolName: tool}
+		}
+		return entries
+	}
+	sessions := []*Session{
+		{ProjectPath: "/code/reader", Commands: commands("Read", "Grep", "Glob", "Edit")},
+		{ProjectPath: "/code/writer", Commands: commands("Write", "Write", "Bash", "MultiEdit", "WebFetch")},
+		{ProjectPath: "/code/reader", Commands: commands("Read", "Bash")},
+		{ProjectPath: "/code/empty"},
+	}
+
+	mixes := ToolMixByProject(sessions)
+	if len(mixes) != 2 {
+		t.Fatalf("got %d projects, want 2 (projects without commands left out)", len(mixes))
+	}
+	reader := mixes[0]
+	if reader.ProjectPath != "/code/reader" || reader.Total != 6 || reader.Sessions != 2 {
+		t.Fatalf("first mix = %+v, want /code/reader with 6 commands in 2 sessions", reader)
+	}
+	if reader.Counts[KindRead] != 4 || reader.Share(KindRead) != 4.0/6 {
+		t.Errorf("reader read %d times (share %v), want 4", reader.Counts[KindRead], reader.Share(KindRead))
+	}
+	writer := mixes[1]
+	for kind, want := range map[string]int{KindWrite: 2, KindEdit: 1, KindBash: 1, KindOther: 1, KindRead: 0} {
+		if writer.Counts[kind] != want {
+			t.Errorf("writer %s count = %d, want %d", kind, writer.Counts[kind], want)
+		}
+	}
+}
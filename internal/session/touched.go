@@ -0,0 +1,126 @@
+package session
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TouchedNode is a file or directory in the tree of paths a session touched.
+// Directories hold the totals of everything below them.
+type TouchedNode struct {
+	Name     string         // Path relative to the parent node (several directories when compacted)
+	Path     string         // Absolute path
+	Reads    int            // Read calls
+	Writes   int            // Edit, Write, and NotebookEdit calls and Bash redirects
+	Children []*TouchedNode // Directories first, then files, each by name; nil for files
+}
+
+// IsDir reports whether the node is a directory
+func (n *TouchedNode) IsDir() bool {
+	return n.Children != nil
+}
+
+// TouchedPaths builds the trees of the files a session read and wrote: one
+// rooted at the project, and one rooted at / for paths outside it (nil when
+// it stayed inside). Directories with a single subdirectory and no files are
+// merged into it, e.g. "internal/tui".
+func TouchedPaths(sess *Session) (project, outside *TouchedNode) {
+	root := filepath.Clean(sess.ProjectPath)
+	project = &TouchedNode{Name: root, Path: root, Children: []*TouchedNode{}}
+	outside = &TouchedNode{Name: string(filepath.Separator), Path: string(filepath.Separator), Children: []*TouchedNode{}}
+
+	for i := range sess.Commands {
+		cmd := &sess.Commands[i]
+		for _, path := range touchedFiles(cmd) {
+			if !filepath.IsAbs(path) && cmd.CWD != "" {
+				path = filepath.Join(cmd.CWD, path)
+			}
+			path = filepath.Clean(path)
+			if strings.HasPrefix(path, "/dev/") {
+				continue
+			}
+			write := cmd.ToolName != "Read"
+
+			sep := string(filepath.Separator)
+			rel, err := filepath.Rel(root, path)
+			inside := sess.ProjectPath != "" && err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+sep)
+			switch {
+			case inside && rel == ".":
+				continue
+			case inside:
+				project.add(strings.Split(rel, sep), write)
+			default:
+				outside.add(strings.Split(strings.TrimPrefix(path, sep), sep), write)
+			}
+		}
+	}
+
+	project.finish()
+	if len(outside.Children) == 0 {
+		return project, nil
+	}
+	outside.finish()
+	return project, outside
+}
+
+// touchedFiles returns the files a command read or wrote, as written
+func touchedFiles(cmd *CommandEntry) []string {
+	switch cmd.ToolName {
+	case "Read", "Edit", "Write", "NotebookEdit":
+		if cmd.RawCommand != "" {
+			return []string{cmd.RawCommand}
+		}
+	case "Bash":
+		return WriteTargets(cmd.RawCommand)
+	}
+	return nil
+}
+
+// add counts a read or write of the file at parts below n
+func (n *TouchedNode) add(parts []string, write bool) {
+	if write {
+		n.Writes++
+	} else {
+		n.Reads++
+	}
+	if len(parts) == 0 {
+		return
+	}
+
+	var child *TouchedNode
+	for _, c := range n.Children {
+		if c.Name == parts[0] {
+			child = c
+			break
+		}
+	}
+	if child == nil {
+		child = &TouchedNode{Name: parts[0], Path: filepath.Join(n.Path, parts[0])}
+		n.Children = append(n.Children, child)
+	}
+	if len(parts) > 1 && child.Children == nil {
+		child.Children = []*TouchedNode{}
+	}
+	child.add(parts[1:], write)
+}
+
+// finish sorts the tree below n and merges single-directory chains
+func (n *TouchedNode) finish() {
+	for _, c := range n.Children {
+		for len(c.Children) == 1 && c.Children[0].IsDir() {
+			only := c.Children[0]
+			c.Name = filepath.Join(c.Name, only.Name)
+			c.Path = only.Path
+			c.Children = only.Children
+		}
+		c.finish()
+	}
+	sort.Slice(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.IsDir() != b.IsDir() {
+			return a.IsDir()
+		}
+		return a.Name < b.Name
+	})
+}
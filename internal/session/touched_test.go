@@ -0,0 +1,37 @@
+package session
+
+import "testing"
+
+func TestTouchedPaths(t *testing.T) {
+	sess := &Session{ProjectPath: "/code/app", Commands: []CommandEntry{
+		{ToolName: "Read", RawCommand: "/code/app/internal/tui/model.go"},
+		{ToolName: "Edit", RawCommand: "/code/app/internal/tui/model.go"},
+		{ToolName: "Read", RawCommand: "/code/app/internal/tui/view.go"},
+		{ToolName: "Write", RawCommand: "README.md", CWD: "/code/app"},
+		{ToolName: "Bash", RawCommand: "go test ./... > /tmp/out.txt 2>/dev/null"},
+		{ToolName: "Grep", RawCommand: "TODO in /code/app"},
+	}}
+
+	project, outside := TouchedPaths(sess)
+	if project.Reads != 2 || project.Writes != 2 || len(project.Children) != 2 {
+		t.Fatalf("project = %d reads, %d writes, %d children; want 2, 2, 2", project.Reads, project.Writes, len(project.Children))
+	}
+	dir := project.Children[0]
+	if dir.Name != "internal/tui" || !dir.IsDir() || dir.Path != "/code/app/internal/tui" || len(dir.Children) != 2 {
+		t.Fatalf("first child = %+v, want the compacted internal/tui directory with 2 files", dir)
+	}
+	if model := dir.Children[0]; model.Name != "model.go" || model.Reads != 1 || model.Writes != 1 || model.IsDir() {
+		t.Errorf("model.go = %+v, want read and written once", model)
+	}
+	if readme := project.Children[1]; readme.Name != "README.md" || readme.Writes != 1 {
+		t.Errorf("second child = %+v, want README.md written once", readme)
+	}
+
+	if outside == nil || len(outside.Children) != 1 || outside.Children[0].Name != "tmp" || outside.Children[0].Children[0].Name != "out.txt" {
+		t.Fatalf("outside = %+v, want /tmp/out.txt (and not /dev/null)", outside)
+	}
+
+	if _, outside := TouchedPaths(&Session{ProjectPath: "/code/app"}); outside != nil {
+		t.Error("expected no outside tree for a session that stayed in its project")
+	}
+}
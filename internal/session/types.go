@@ -10,8 +10,29 @@ type Session struct {
 	GitBranch    string         // Current git branch
 	LastActivity time.Time      // Timestamp of last command
 	Commands     []CommandEntry // All write operation commands
-	IsActive     bool           // True if file modified recently (within 5 minutes)
+	IsActive     bool           // True if file modified within the activity window (5 minutes by default)
 	Origin       string         // "local" or "devagent:container-name"
+	CWD          string         // Most recent working directory (may drift from ProjectPath)
+	Flags        []string       // Why alert actions flagged the session (action names)
+	Duplicates   []string       // Files of the same session under other watched roots, hidden in its favor
+	Thinking     ThinkingStats  // Thinking blocks across the session and its subagents
+	Markers      []Marker       // Context compactions and restarts in the main session file
+
+	// Lineage, for linking resumed sessions (see Predecessor)
+	RootUUID    string    // First message of the conversation
+	LastUUID    string    // Latest message
+	StartedAt   time.Time // When the first message was written
+	ResumedFrom []string  // Last message UUIDs of the conversations this one resumes
+}
+
+// Header returns a copy of the session without its command history
+func (s *Session) Header() *Session {
+	if s == nil {
+		return nil
+	}
+	hdr := *s
+	hdr.Commands = nil
+	return &hdr
 }
 
 // CommandEntry represents a single tool invocation
@@ -24,6 +45,20 @@ type CommandEntry struct {
 	UUID       string    // Message UUID for deduplication
 	LineNumber int       // Line number in JSONL file (1-indexed) for lazy loading
 	FilePath   string    // Path to session JSONL file
+	CWD        string    // Working directory when the command was issued
+	ToolUseID  string    // tool_use ID, for matching results that arrive later
+	IsError    bool      // Tool result reported an error
+
+	// Background Bash commands (run_in_background) absorb the BashOutput
+	// and KillShell calls made on their shell instead of listing them
+	Background   bool   // Started with run_in_background
+	BackgroundID string // Shell ID from the command's result, once written
+	Polls        int    // BashOutput calls on the shell
+	Killed       bool   // A KillShell call stopped the shell
+
+	Thinking ThinkingStats // Reasoning written since the previous tool call
+
+	Hooks []HookRun // Hooks that fired for the call, in file order
 }
 
 // CommandPattern represents a unique command pattern for aggregation
@@ -7,14 +7,18 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"cc_session_mon/internal/applog"
+	"cc_session_mon/internal/config"
+
 	"github.com/fsnotify/fsnotify"
 )
 
 // WatchEvent represents a session change event
 type WatchEvent struct {
-	Type     string         // "discovered", "updated", "new_commands"
+	Type     string         // "discovered", "updated", "new_commands", "removed"
 	Session  *Session       // The affected session
 	Commands []CommandEntry // New commands (for "new_commands" type)
 }
@@ -22,18 +26,32 @@ type WatchEvent struct {
 // Watcher monitors the Claude projects directory for session changes
 type Watcher struct {
 	fsWatcher    *fsnotify.Watcher
-	projectsDirs []string           // multiple directories to monitor
-	sessions     map[string]*Session // keyed by main session file path
-	offsets      map[string]int64    // file read offsets for incremental parsing
-	lineNumbers  map[string]int      // line numbers for incremental parsing (1-indexed, next line to read)
-	subagentMap  map[string]string   // maps subagent file path -> main session file path
-	originMap    map[string]string   // maps projectsDir path to origin label (e.g. "local" or "devagent:container-name")
+	projectsDirs []string                 // multiple directories to monitor
+	sessions     map[string]*Session      // keyed by main session file path
+	offsets      map[string]int64         // file read offsets for incremental parsing
+	lineNumbers  map[string]int           // line numbers for incremental parsing (1-indexed, next line to read)
+	thinking     map[string]ThinkingStats // reasoning after the last tool call read from each file, for the next one
+	subagentMap  map[string]string        // maps subagent file path -> main session file path
+	originMap    map[string]string        // maps projectsDir path to origin label (e.g. "local" or "devagent:container-name")
 	mu           sync.RWMutex
 
 	// Cached sorted sessions to avoid re-sorting on every GetSessions call
 	sortedCache      []*Session
 	sortedCacheValid bool
 
+	// Additional event consumers registered via Subscribe
+	subscribers []chan WatchEvent
+	dropped     int       // Events slow subscribers missed since the last report
+	dropLogged  time.Time // When dropped events were last reported
+
+	// replica watchers are fed by Inject and never touch the filesystem
+	replica bool
+
+	strategy string      // watch_strategy: what gets an fsnotify watch
+	limitHit atomic.Bool // Whether the OS refused a watch; the watcher polls from then on
+
+	scanScheduled atomic.Bool // Whether a subagent scan after a Task call is pending
+
 	Events chan WatchEvent
 	Errors chan error
 	done   chan struct{}
@@ -52,11 +70,13 @@ func NewWatcher(projectsDirs []string) (*Watcher, error) {
 		sessions:     make(map[string]*Session),
 		offsets:      make(map[string]int64),
 		lineNumbers:  make(map[string]int),
+		thinking:     make(map[string]ThinkingStats),
 		subagentMap:  make(map[string]string),
 		originMap:    make(map[string]string),
 		Events:       make(chan WatchEvent, 100),
 		Errors:       make(chan error, 10),
 		done:         make(chan struct{}),
+		strategy:     config.Global().WatchStrategy,
 	}
 
 	return w, nil
@@ -67,15 +87,17 @@ func (w *Watcher) DiscoverSessions() ([]*Session, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if w.replica {
+		return w.trackedSessions(), nil
+	}
+
 	sessions := make([]*Session, 0, len(w.projectsDirs)*4) //nolint:mnd // rough estimate
 
 	for _, projectsDir := range w.projectsDirs {
 		// Watch the projects directory so we detect new project subdirectories.
 		// If it doesn't exist yet (e.g., devagent container with no sessions),
-		// watch the parent directory so we detect when it gets created.
-		if err := w.fsWatcher.Add(projectsDir); err != nil {
-			_ = w.fsWatcher.Add(filepath.Dir(projectsDir))
-		}
+		// watch its nearest ancestor so we detect when it gets created.
+		w.watchRoot(projectsDir)
 
 		found := w.discoverInDir(projectsDir)
 		sessions = append(sessions, found...)
@@ -86,7 +108,7 @@ func (w *Watcher) DiscoverSessions() ([]*Session, error) {
 		return sessions[i].LastActivity.After(sessions[j].LastActivity)
 	})
 
-	return sessions, nil
+	return dedupeSessions(sessions), nil
 }
 
 // discoverInDir scans a single projects directory for sessions.
@@ -96,6 +118,7 @@ func (w *Watcher) discoverInDir(projectsDir string) []*Session {
 
 	entries, err := os.ReadDir(projectsDir)
 	if err != nil {
+		w.reportRootError(projectsDir, err)
 		return nil
 	}
 
@@ -118,33 +141,26 @@ func (w *Watcher) discoverInDir(projectsDir string) []*Session {
 				w.sessions[jsonlPath] = s
 				w.invalidateSortedCache()
 
-				if info, err := os.Stat(jsonlPath); err == nil {
-					w.offsets[jsonlPath] = info.Size()
-				}
-
 				// Watch session-ID subdirectory so we detect subagents/ creation
 				sessionID := strings.TrimSuffix(filepath.Base(jsonlPath), ".jsonl")
 				sessionSubdir := filepath.Join(projectDir, sessionID)
-				_ = w.fsWatcher.Add(sessionSubdir)
+				w.watch(sessionSubdir, levelSession)
 
 				// Watch and track subagent files
 				subagentDir := filepath.Join(sessionSubdir, "subagents")
 				if subagentFiles, err := filepath.Glob(filepath.Join(subagentDir, "*.jsonl")); err == nil {
 					for _, subPath := range subagentFiles {
 						w.subagentMap[subPath] = jsonlPath
-						if info, err := os.Stat(subPath); err == nil {
-							w.offsets[subPath] = info.Size()
-						}
 					}
 					if len(subagentFiles) > 0 {
-						_ = w.fsWatcher.Add(subagentDir)
+						w.watch(subagentDir, levelSession)
 					}
 				}
 			}
 		}
 
 		// Watch the project directory for new sessions
-		_ = w.fsWatcher.Add(projectDir)
+		w.watch(projectDir, levelProject)
 	}
 
 	return sessions
@@ -161,7 +177,7 @@ func (w *Watcher) parseSessionFile(path, encodedProject string) *Session {
 	sessionID := strings.TrimSuffix(filepath.Base(path), ".jsonl")
 
 	// Parse the main session file
-	commands, meta, err := ParseSessionFile(path)
+	commands, meta, err := w.parseTracked(path)
 	if err != nil {
 		return nil
 	}
@@ -172,12 +188,20 @@ func (w *Watcher) parseSessionFile(path, encodedProject string) *Session {
 		projectPath = encodedProject
 	}
 
+	origin := w.originOf(path)
+	normalizeTimes(origin, commands, &meta)
+
+	thinking := meta.Thinking
+	w.thinking[path] = meta.TrailingThinking
+
 	// Also parse subagent files if they exist
 	subagentDir := filepath.Join(filepath.Dir(path), sessionID, "subagents")
 	if subagentFiles, err := filepath.Glob(filepath.Join(subagentDir, "*.jsonl")); err == nil {
 		for _, subagentPath := range subagentFiles {
-			subCommands, _, _ := ParseSessionFile(subagentPath)
+			subCommands, subMeta, _ := w.parseTracked(subagentPath)
+			normalizeTimes(origin, subCommands, nil)
 			commands = append(commands, subCommands...)
+			thinking.Add(subMeta.Thinking)
 		}
 	}
 
@@ -202,17 +226,8 @@ func (w *Watcher) parseSessionFile(path, encodedProject string) *Session {
 		}
 	}
 
-	// Consider active if modified in last 5 minutes
-	isActive := time.Since(lastActivity) < 5*time.Minute
-
-	// Determine origin by finding which projectsDir this path belongs to
-	origin := ""
-	for _, projectsDir := range w.projectsDirs {
-		if strings.HasPrefix(path, projectsDir+string(filepath.Separator)) || path == projectsDir {
-			origin = w.originMap[projectsDir]
-			break
-		}
-	}
+	// Consider active if modified within the activity window
+	isActive := time.Since(lastActivity) < config.Global().ActivityWindow
 
 	return &Session{
 		ID:           sessionID,
@@ -223,7 +238,28 @@ func (w *Watcher) parseSessionFile(path, encodedProject string) *Session {
 		Commands:     commands,
 		IsActive:     isActive,
 		Origin:       origin,
+		CWD:          meta.LastCWD,
+		Thinking:     thinking,
+		Markers:      meta.Markers,
+		RootUUID:     meta.RootUUID,
+		LastUUID:     meta.LastUUID,
+		StartedAt:    meta.StartedAt,
+		ResumedFrom:  meta.ResumedFrom,
+	}
+}
+
+// parseTracked parses a whole file the watcher starts following, recording
+// where the parse ended so updates continue from there. A partly written
+// final line is left for the next update, not skipped.
+// Must be called with w.mu held for writing.
+func (w *Watcher) parseTracked(path string) ([]CommandEntry, SessionMetadata, error) {
+	commands, meta, offset, line, err := ParseSessionFileFrom(path, 0, 0)
+	if err != nil {
+		return nil, SessionMetadata{}, err
 	}
+	w.offsets[path] = offset
+	w.lineNumbers[path] = line
+	return commands, meta, nil
 }
 
 // AddProjectsDir adds a new directory to the list of directories to monitor.
@@ -240,6 +276,13 @@ func (w *Watcher) AddProjectsDir(dir string) bool {
 	return true
 }
 
+// ProjectsDirs returns the directories being monitored
+func (w *Watcher) ProjectsDirs() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return slices.Clone(w.projectsDirs)
+}
+
 // SetOrigin sets the origin label for a projects directory.
 func (w *Watcher) SetOrigin(dir, label string) {
 	w.mu.Lock()
@@ -258,6 +301,11 @@ func (w *Watcher) Stop() error {
 	return w.fsWatcher.Close()
 }
 
+// Done is closed when the watcher is stopped, for work feeding it to end
+func (w *Watcher) Done() <-chan struct{} {
+	return w.done
+}
+
 // watchLoop handles fsnotify events
 func (w *Watcher) watchLoop() {
 	for {
@@ -278,7 +326,7 @@ func (w *Watcher) watchLoop() {
 			select {
 			case w.Errors <- err:
 			default:
-				// Error channel full, drop
+				applog.Warnf("watcher: error channel full, dropped: %v", err)
 			}
 		}
 	}
@@ -286,10 +334,13 @@ func (w *Watcher) watchLoop() {
 
 // handleFSEvent processes a filesystem event
 func (w *Watcher) handleFSEvent(event fsnotify.Event) {
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		w.handleRemovedDir(event.Name)
+	}
 	if event.Op&fsnotify.Create == fsnotify.Create {
-		// New directory inside a watched projects dir — start watching it for session files
+		// New directory: a projects dir appearing, or a project inside one
 		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
-			_ = w.fsWatcher.Add(event.Name)
+			w.handleNewDir(event.Name)
 			return
 		}
 	}
@@ -304,6 +355,10 @@ func (w *Watcher) handleFSEvent(event fsnotify.Event) {
 		w.handleFileUpdate(event.Name)
 
 	case event.Op&fsnotify.Create == fsnotify.Create:
+		// Syncs replace a file by renaming a new copy over it
+		if w.syncedFile(event.Name) {
+			w.handleFileUpdate(event.Name)
+		}
 		w.handleNewFile(event.Name)
 	}
 }
@@ -327,6 +382,10 @@ func (w *Watcher) handleFileUpdate(path string) {
 	if !exists {
 		return
 	}
+	if isSynced(session.Origin) {
+		w.mergeSynced(path, session, isSubagent)
+		return
+	}
 
 	// Get current offset and line number
 	offset := w.offsets[path]
@@ -337,21 +396,54 @@ func (w *Watcher) handleFileUpdate(path string) {
 	if err != nil {
 		return
 	}
+	normalizeTimes(session.Origin, newCommands, &meta)
 
 	// Update offset and line number
 	w.offsets[path] = newOffset
 	w.lineNumbers[path] = newLine
+	if newOffset != offset {
+		ForgetPendingToolInputs(path)
+	}
 
-	// Update session metadata if we now have better info
-	// This handles the case where the session was created before CWD was available
-	if meta.CWD != "" && session.ProjectPath != meta.CWD {
+	// Update session metadata if we now have better info. The project path
+	// comes from the first CWD only (the session may have been created before
+	// one was available); later CWDs are tracked as drift, not a new project.
+	if meta.CWD != "" && session.CWD == "" && !isSubagent {
 		session.ProjectPath = meta.CWD
 	}
+	if meta.LastCWD != "" && !isSubagent {
+		session.CWD = meta.LastCWD
+	}
 	if meta.GitBranch != "" && session.GitBranch == "" {
 		session.GitBranch = meta.GitBranch
 	}
+	if meta.LastUUID != "" && !isSubagent {
+		session.LastUUID = meta.LastUUID
+	}
+
+	// Results for commands parsed in an earlier update
+	changed := markFailed(session.Commands, meta.FailedToolUses)
+	changed = ApplyJobEvents(session.Commands, meta) || changed
+	changed = ApplyHooks(session.Commands, meta.Hooks) || changed
+	if !isSubagent && len(meta.Markers) > 0 {
+		session.Markers = append(session.Markers, meta.Markers...)
+		changed = true
+	}
+
+	// Reasoning read at the end of the last update preceded this update's first command
+	session.Thinking.Add(meta.Thinking)
+	carried := w.thinking[path]
+	if len(newCommands) > 0 {
+		newCommands[0].Thinking.Add(carried)
+		carried = ThinkingStats{}
+	}
+	carried.Add(meta.TrailingThinking)
+	w.thinking[path] = carried
 
 	if len(newCommands) == 0 {
+		if changed {
+			w.emit(WatchEvent{Type: "updated", Session: session})
+		}
 		return
 	}
 
@@ -360,17 +452,33 @@ func (w *Watcher) handleFileUpdate(path string) {
 	session.LastActivity = time.Now()
 	session.IsActive = true
 	w.invalidateSortedCache()
+	if startsSubagent(newCommands) {
+		w.watchSubagents(path)
+		w.scheduleSubagentScan()
+	}
 
 	// Send event
-	select {
-	case w.Events <- WatchEvent{
+	w.emit(WatchEvent{
 		Type:     "new_commands",
 		Session:  session,
 		Commands: newCommands,
-	}:
-	default:
-		// Event channel full
+	})
+}
+
+// markFailed sets IsError on the commands with the given tool_use IDs,
+// searching from the newest, and reports whether any were found
+func markFailed(commands []CommandEntry, toolUseIDs []string) bool {
+	var found bool
+	for _, id := range toolUseIDs {
+		for i := len(commands) - 1; i >= 0; i-- {
+			if commands[i].ToolUseID == id {
+				commands[i].IsError = true
+				found = true
+				break
+			}
+		}
 	}
+	return found
 }
 
 // handleNewFile processes a newly created session file
@@ -386,17 +494,21 @@ func (w *Watcher) handleNewFile(path string) {
 		sessionID := filepath.Base(sessionDir)
 		projectDir := filepath.Dir(sessionDir)
 
+		// Already tracking it (found by a scan or a new directory)?
+		if _, tracked := w.subagentMap[path]; tracked {
+			return
+		}
+
 		// Look for the main session file
 		mainSessionPath := filepath.Join(projectDir, sessionID+".jsonl")
 		if session, exists := w.sessions[mainSessionPath]; exists {
 			// Track this subagent file
 			w.subagentMap[path] = mainSessionPath
-			if info, err := os.Stat(path); err == nil {
-				w.offsets[path] = info.Size()
-			}
 
 			// Parse and add its commands to the session
-			commands, _, _ := ParseSessionFile(path)
+			commands, meta, _ := w.parseTracked(path)
+			normalizeTimes(session.Origin, commands, nil)
+			session.Thinking.Add(meta.Thinking)
 			if len(commands) > 0 {
 				session.Commands = append(session.Commands, commands...)
 				session.LastActivity = time.Now()
@@ -404,14 +516,11 @@ func (w *Watcher) handleNewFile(path string) {
 				w.invalidateSortedCache()
 
 				// Send event
-				select {
-				case w.Events <- WatchEvent{
+				w.emit(WatchEvent{
 					Type:     "new_commands",
 					Session:  session,
 					Commands: commands,
-				}:
-				default:
-				}
+				})
 			}
 		}
 		return
@@ -434,18 +543,47 @@ func (w *Watcher) handleNewFile(path string) {
 	w.sessions[path] = session
 	w.invalidateSortedCache()
 
-	// Track file size
-	if info, err := os.Stat(path); err == nil {
-		w.offsets[path] = info.Size()
-	}
-
 	// Send event
-	select {
-	case w.Events <- WatchEvent{
+	w.emit(WatchEvent{
 		Type:    "discovered",
 		Session: session,
-	}:
+	})
+}
+
+// Subscribe returns a channel that receives a copy of every event sent on
+// Events. Slow subscribers miss events rather than blocking the watcher.
+func (w *Watcher) Subscribe() <-chan WatchEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ch := make(chan WatchEvent, 100)
+	w.subscribers = append(w.subscribers, ch)
+	return ch
+}
+
+// emit delivers an event to Events and all subscribers without blocking.
+// Events for a session hidden in favor of a twin under another watched root
+// are dropped. Must be called with w.mu held for writing.
+func (w *Watcher) emit(event WatchEvent) {
+	if event.Session != nil && w.isDuplicate(event.Session) {
+		return
+	}
+	select {
+	case w.Events <- event:
 	default:
+		// Event channel full
+	}
+	for _, ch := range w.subscribers {
+		select {
+		case ch <- event:
+		default:
+			w.dropped++
+		}
+	}
+	// Reported at most once a minute, as a stalled subscriber misses every event
+	if w.dropped > 0 && time.Since(w.dropLogged) >= time.Minute {
+		applog.Warnf("watcher: %d event(s) dropped for slow subscribers (web, share, alerts, or recording)", w.dropped)
+		w.dropped, w.dropLogged = 0, time.Now()
 	}
 }
 
@@ -479,6 +617,55 @@ func (w *Watcher) GetSessions() []*Session {
 	return result
 }
 
+// Snapshot returns copies of the tracked sessions, sorted by last activity,
+// taken under the watcher's lock. Readers on other goroutines (share, web)
+// use it because the watcher keeps updating the sessions and their commands
+// in place.
+func (w *Watcher) Snapshot() []*Session {
+	sessions := w.GetSessions()
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for i, s := range sessions {
+		copied := *s
+		copied.Commands = slices.Clone(s.Commands)
+		sessions[i] = &copied
+	}
+	return sessions
+}
+
+// SnapshotEvent returns a copy of event taken under the watcher's lock, as
+// Snapshot. Its session is a full copy for "discovered" events, whose command
+// history is the point, and a Header otherwise.
+func (w *Watcher) SnapshotEvent(event WatchEvent) WatchEvent {
+	return w.snapshotEvent(event, event.Type == "discovered")
+}
+
+// SnapshotEventWithHistory is SnapshotEvent with the session's command history
+// copied whatever the event type, for readers that evaluate it (alert
+// thresholds, findings that look at earlier commands, command counts)
+func (w *Watcher) SnapshotEventWithHistory(event WatchEvent) WatchEvent {
+	return w.snapshotEvent(event, true)
+}
+
+// snapshotEvent copies event under the lock, with the session's commands
+// only if history is set
+func (w *Watcher) snapshotEvent(event WatchEvent, history bool) WatchEvent {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if event.Session != nil {
+		copied := *event.Session
+		copied.Commands = nil
+		if history {
+			copied.Commands = slices.Clone(event.Session.Commands)
+		}
+		event.Session = &copied
+	}
+	event.Commands = slices.Clone(event.Commands)
+	return event
+}
+
 // rebuildSortedCache rebuilds the sorted session cache.
 // Must be called with w.mu held for writing.
 func (w *Watcher) rebuildSortedCache() {
@@ -490,6 +677,7 @@ func (w *Watcher) rebuildSortedCache() {
 	sort.Slice(w.sortedCache, func(i, j int) bool {
 		return w.sortedCache[i].LastActivity.After(w.sortedCache[j].LastActivity)
 	})
+	w.sortedCache = dedupeSessions(w.sortedCache)
 
 	w.sortedCacheValid = true
 }
@@ -500,18 +688,80 @@ func (w *Watcher) invalidateSortedCache() {
 	w.sortedCacheValid = false
 }
 
+// FlagSession marks a session (by main file path) as flagged for reason and
+// emits an "updated" event. Repeating a reason is a no-op.
+func (w *Watcher) FlagSession(filePath, reason string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sess, ok := w.sessions[filePath]
+	if !ok || slices.Contains(sess.Flags, reason) {
+		return
+	}
+	sess.Flags = append(sess.Flags, reason)
+	w.emit(WatchEvent{Type: "updated", Session: sess})
+}
+
 // RefreshActivityStatus updates IsActive flag for all sessions
 func (w *Watcher) RefreshActivityStatus() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if w.replica {
+		return // activity comes from injected "updated" events
+	}
+
 	for path, session := range w.sessions {
 		if info, err := os.Stat(path); err == nil {
-			session.IsActive = time.Since(info.ModTime()) < 5*time.Minute
+			session.IsActive = time.Since(info.ModTime()) < config.Global().ActivityWindow
 		}
 	}
 }
 
+// CatchUp reads what was appended to tracked files and picks up session
+// files created while fsnotify events may have been lost, e.g. when the
+// process was suspended long enough for the kernel's event queue to overflow.
+// Changes are emitted like any other.
+func (w *Watcher) CatchUp() {
+	if w.replica {
+		return
+	}
+
+	w.mu.RLock()
+	var grown []string
+	for path, offset := range w.offsets {
+		// A synced file replaced by an older, shorter copy is merged too
+		if info, err := os.Stat(path); err == nil && (info.Size() > offset || info.Size() != offset && isSynced(w.originOf(path))) {
+			grown = append(grown, path)
+		}
+	}
+	projectsDirs := slices.Clone(w.projectsDirs)
+	w.mu.RUnlock()
+
+	for _, path := range grown {
+		w.handleFileUpdate(path)
+	}
+
+	for _, projectsDir := range projectsDirs {
+		projectDirs, _ := filepath.Glob(filepath.Join(projectsDir, "*"))
+		for _, projectDir := range projectDirs {
+			jsonlFiles, _ := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
+			if len(jsonlFiles) > 0 {
+				w.watch(projectDir, levelProject)
+			}
+			for _, path := range jsonlFiles {
+				w.mu.RLock()
+				_, tracked := w.sessions[path]
+				w.mu.RUnlock()
+				if !tracked {
+					w.handleNewFile(path)
+				}
+			}
+		}
+	}
+	w.ScanForNewSubagents()
+}
+
 // ScanForNewSubagents polls for subagent JSONL files that may have been missed
 // by fsnotify due to a race condition on macOS (kqueue). For each tracked session,
 // it globs for subagent files and picks up any not already in w.subagentMap.
@@ -519,6 +769,10 @@ func (w *Watcher) ScanForNewSubagents() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if w.replica {
+		return
+	}
+
 	for mainPath, sess := range w.sessions {
 		sessionID := strings.TrimSuffix(filepath.Base(mainPath), ".jsonl")
 		projectDir := filepath.Dir(mainPath)
@@ -537,13 +791,12 @@ func (w *Watcher) ScanForNewSubagents() {
 			// New subagent file discovered by polling
 			w.subagentMap[subPath] = mainPath
 
-			commands, _, _ := ParseSessionFile(subPath)
-			if info, err := os.Stat(subPath); err == nil {
-				w.offsets[subPath] = info.Size()
-			}
+			commands, meta, _ := w.parseTracked(subPath)
+			normalizeTimes(sess.Origin, commands, nil)
+			sess.Thinking.Add(meta.Thinking)
 
 			// Ensure we're watching the subagents directory
-			_ = w.fsWatcher.Add(subagentDir)
+			w.watch(subagentDir, levelSession)
 
 			if len(commands) > 0 {
 				sess.Commands = append(sess.Commands, commands...)
@@ -551,14 +804,11 @@ func (w *Watcher) ScanForNewSubagents() {
 				sess.IsActive = true
 				w.invalidateSortedCache()
 
-				select {
-				case w.Events <- WatchEvent{
+				w.emit(WatchEvent{
 					Type:     "new_commands",
 					Session:  sess,
 					Commands: commands,
-				}:
-				default:
-				}
+				})
 			}
 		}
 	}
@@ -0,0 +1,233 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestCatchUpReadsMissedChanges(t *testing.T) {
+	root, path := writeSessionCopy(t, backgroundSession[:1])
+	w, err := NewWatcher([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	if _, err := w.DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Written while no events were delivered (the watcher was never started)
+	appendFile(t, path, `{"type":"assistant","uuid":"u9","message":{"role":"assistant","content":[{"type":"tool_use","id":"t9","name":"Bash","input":{"command":"go test ./..."}}]}}`+"\n")
+	betaDir := filepath.Join(root, "-projects-beta")
+	if err := os.MkdirAll(betaDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	betaPath := filepath.Join(betaDir, "sess-2.jsonl")
+	if err := os.WriteFile(betaPath, []byte(strings.Join(backgroundSession, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w.CatchUp()
+
+	events := make(map[string]string) // type -> session file
+	for len(w.Events) > 0 {
+		ev := <-w.Events
+		events[ev.Type] = ev.Session.FilePath
+	}
+	if events["new_commands"] != path {
+		t.Errorf("expected the appended command read from %s, got events %v", path, events)
+	}
+	if events["discovered"] != betaPath {
+		t.Errorf("expected the new session %s discovered, got events %v", betaPath, events)
+	}
+	if n := len(w.GetSessions()); n != 2 {
+		t.Errorf("expected 2 sessions after catching up, got %d", n)
+	}
+}
+
+func TestRemoveSession(t *testing.T) {
+	root, path := writeSessionCopy(t, backgroundSession)
+	subagents := filepath.Join(strings.TrimSuffix(path, ".jsonl"), "subagents")
+	if err := os.MkdirAll(subagents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	if _, err := w.DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.RemoveSession(path, false, ""); err == nil {
+		t.Fatal("expected a just-written (active) session to be refused")
+	}
+
+	w.sessions[path].IsActive = false
+	dest, err := w.RemoveSession(path, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(filepath.Dir(root), DefaultArchiveDir, "-projects-alpha"); dest != want {
+		t.Errorf("archived to %s, want %s", dest, want)
+	}
+	for _, moved := range []string{filepath.Join(dest, "sess-1.jsonl"), filepath.Join(dest, "sess-1", "subagents")} {
+		if _, err := os.Stat(moved); err != nil {
+			t.Errorf("expected %s archived: %v", moved, err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the session file moved out of the projects directory")
+	}
+	if len(w.GetSessions()) != 0 {
+		t.Error("expected the watcher to stop tracking the removed session")
+	}
+}
+
+func TestRemoveSessionDeletes(t *testing.T) {
+	root, path := writeSessionCopy(t, backgroundSession)
+	w, err := NewWatcher([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	if _, err := w.DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	w.sessions[path].IsActive = false
+	if _, err := w.RemoveSession(path, false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the session file deleted")
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	_, path := writeSessionCopy(t, backgroundSession)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := DiskUsage(path); got != info.Size() {
+		t.Errorf("DiskUsage() = %d without subagents, want the file size %d", got, info.Size())
+	}
+
+	subagents := filepath.Join(strings.TrimSuffix(path, ".jsonl"), "subagents")
+	if err := os.MkdirAll(subagents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(subagents, "agent-1.jsonl"), make([]byte, 1000), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := DiskUsage(path); got != info.Size()+1000 {
+		t.Errorf("DiskUsage() = %d, want %d including subagents", got, info.Size()+1000)
+	}
+	if got := DiskUsage(filepath.Join(t.TempDir(), "missing.jsonl")); got != 0 {
+		t.Errorf("DiskUsage() = %d for a missing session, want 0", got)
+	}
+}
+
+func TestProjectsDirAppearsAndDisappears(t *testing.T) {
+	// A devagent container's projects directory, before the container starts
+	root := t.TempDir()
+	container := filepath.Join(root, "c1")
+	projects := filepath.Join(container, ".claude", "projects")
+	w, err := NewWatcher([]string{projects})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	if sessions, _ := w.DiscoverSessions(); len(sessions) != 0 {
+		t.Fatalf("expected no sessions, got %d", len(sessions))
+	}
+	if !slices.Contains(w.fsWatcher.WatchList(), root) {
+		t.Fatalf("expected the nearest existing ancestor watched, got %v", w.fsWatcher.WatchList())
+	}
+
+	// The whole tree appears at once, sessions included
+	projectDir := filepath.Join(projects, "-projects-alpha")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(projectDir, "sess-1.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(backgroundSession, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w.handleFSEvent(fsnotify.Event{Name: container, Op: fsnotify.Create})
+	if ev := <-w.Events; ev.Type != "discovered" || ev.Session.FilePath != path {
+		t.Fatalf("expected %s discovered, got %s %+v", path, ev.Type, ev.Session)
+	}
+	if watched := w.fsWatcher.WatchList(); !slices.Contains(watched, projects) || !slices.Contains(watched, projectDir) {
+		t.Errorf("expected the projects and project directories watched, got %v", watched)
+	}
+
+	// The container goes away
+	if err := os.RemoveAll(container); err != nil {
+		t.Fatal(err)
+	}
+	w.handleFSEvent(fsnotify.Event{Name: projects, Op: fsnotify.Remove})
+	if ev := <-w.Events; ev.Type != "removed" || ev.Session.FilePath != path {
+		t.Fatalf("expected %s removed, got %s", path, ev.Type)
+	}
+	if n := len(w.GetSessions()); n != 0 {
+		t.Errorf("expected no sessions after the directory went away, got %d", n)
+	}
+}
+
+func TestUnreadableRootIsReportedAndRetried(t *testing.T) {
+	// A projects directory that can't be read: a file stands in for one
+	// without permissions, which root ignores
+	projects := filepath.Join(t.TempDir(), "projects")
+	if err := os.WriteFile(projects, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher([]string{projects})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	if _, err := w.DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+	var rootErr *RootError
+	if err := <-w.Errors; !errors.As(err, &rootErr) || rootErr.Root != projects {
+		t.Fatalf("expected a RootError for %s, got %v", projects, err)
+	}
+	if err := w.RetryRoot(projects); !errors.As(err, &rootErr) {
+		t.Errorf("expected the retry to fail the same way, got %v", err)
+	}
+
+	// Fixed: the retry discovers its sessions
+	if err := os.Remove(projects); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(projects, "-projects-alpha", "sess-1.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(backgroundSession, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.RetryRoot(projects); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if ev := <-w.Events; ev.Type != "discovered" || ev.Session.FilePath != path {
+		t.Errorf("expected %s discovered, got %s", path, ev.Type)
+	}
+
+	// A missing projects directory is waited for, not reported
+	missing := filepath.Join(t.TempDir(), "absent")
+	w.reportRootError(missing, &os.PathError{Op: "open", Path: missing, Err: os.ErrNotExist})
+	if len(w.Errors) != 0 {
+		t.Errorf("expected no error for a missing directory, got %v", <-w.Errors)
+	}
+}
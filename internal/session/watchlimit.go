@@ -0,0 +1,73 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+)
+
+// Watch strategies (watch_strategy), from most to fewest fsnotify watches
+const (
+	WatchDirectories = "directories" // Projects, project, session, and subagents directories (the default)
+	WatchProjects    = "projects"    // Projects and project directories; subagent transcripts are found by polling
+	WatchPoll        = "poll"        // Projects directories only; session files are found and reread by polling
+)
+
+// Directory levels, for deciding what a strategy watches
+const (
+	levelRoot    = iota // A projects directory, or the ancestor watched until it exists
+	levelProject        // A project directory holding session files
+	levelSession        // A session's directory or its subagents directory
+)
+
+// WatchLimitError reports that the OS refused another watch: inotify's
+// per-user watch limit on Linux, or the open file limit kqueue runs into
+// on macOS. The watcher polls from then on.
+type WatchLimitError struct {
+	Path string
+	Err  error
+}
+
+func (e *WatchLimitError) Error() string {
+	fix := "raise the open file limit (ulimit -n)"
+	if runtime.GOOS == "linux" {
+		fix = "raise it with `sudo sysctl fs.inotify.max_user_watches=524288` (add it to /etc/sysctl.d/ to keep it)"
+	}
+	return fmt.Sprintf("watch limit reached at %s (%v): polling for changes instead; %s, or set watch_strategy: projects", e.Path, e.Err, fix)
+}
+
+func (e *WatchLimitError) Unwrap() error {
+	return e.Err
+}
+
+// watch adds an fsnotify watch on dir if the watch strategy covers its
+// level. Hitting the watch limit is reported once on Errors and switches
+// the watcher to polling; other failures (a directory gone again) are
+// ignored, as the next discovery or poll catches up.
+func (w *Watcher) watch(dir string, level int) {
+	switch {
+	case w.strategy == WatchPoll && level > levelRoot,
+		w.strategy == WatchProjects && level > levelProject:
+		return
+	}
+	err := w.fsWatcher.Add(dir)
+	if err == nil || !(errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)) {
+		return
+	}
+	if w.limitHit.Swap(true) {
+		return
+	}
+	select {
+	case w.Errors <- &WatchLimitError{Path: dir, Err: err}:
+	default:
+	}
+}
+
+// Polling reports whether the watcher relies on polling (CatchUp every
+// refresh) rather than events to see changes: with watch_strategy: poll,
+// once the watch limit was hit, or while watching a shared directory, as
+// network mounts and sync tools don't reliably raise events
+func (w *Watcher) Polling() bool {
+	return w.strategy == WatchPoll || w.limitHit.Load() || w.watchesShared()
+}
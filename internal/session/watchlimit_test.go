@@ -0,0 +1,70 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+
+	"cc_session_mon/internal/config"
+)
+
+func TestWatchStrategies(t *testing.T) {
+	root, path := writeSessionCopy(t, backgroundSession)
+	projectDir := filepath.Dir(path)
+	subagents := filepath.Join(strings.TrimSuffix(path, ".jsonl"), "subagents")
+	if err := os.MkdirAll(subagents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(subagents, "agent-1.jsonl"), []byte(backgroundSession[0]+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		strategy string
+		watched  []string
+		polling  bool
+	}{
+		{WatchDirectories, []string{root, projectDir, filepath.Dir(subagents), subagents}, false},
+		{WatchProjects, []string{root, projectDir}, false},
+		{WatchPoll, []string{root}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			config.SetGlobal(&config.Config{WatchStrategy: tt.strategy})
+			t.Cleanup(func() { config.SetGlobal(nil) })
+			w, err := NewWatcher([]string{root})
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = w.Stop() })
+			if sessions, _ := w.DiscoverSessions(); len(sessions) != 1 {
+				t.Fatalf("expected the session discovered, got %d", len(sessions))
+			}
+
+			watched := w.fsWatcher.WatchList()
+			slices.Sort(watched)
+			want := slices.Sorted(slices.Values(tt.watched))
+			if !slices.Equal(watched, want) {
+				t.Errorf("watched %v, want %v", watched, want)
+			}
+			if w.Polling() != tt.polling {
+				t.Errorf("Polling() = %v, want %v", w.Polling(), tt.polling)
+			}
+		})
+	}
+}
+
+func TestWatchLimitError(t *testing.T) {
+	err := error(&WatchLimitError{Path: "/home/dev/.claude/projects/-app", Err: syscall.ENOSPC})
+	if !errors.Is(err, syscall.ENOSPC) {
+		t.Error("expected the error to wrap ENOSPC")
+	}
+	if runtime.GOOS == "linux" && !strings.Contains(err.Error(), "fs.inotify.max_user_watches") {
+		t.Errorf("expected the sysctl fix in %q", err)
+	}
+}
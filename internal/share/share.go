@@ -0,0 +1,261 @@
+// Package share lets one monitor instance own the Watcher and stream its
+// state to read-only viewers over a Unix socket or TCP. Viewers apply the
+// stream to a replica watcher, so session files are parsed only once.
+package share
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+// maxFrameSize bounds a single frame; "discovered" frames carry full command history
+const maxFrameSize = 64 * 1024 * 1024
+
+// frame is one newline-delimited JSON message on the wire
+type frame struct {
+	Type     string                 `json:"type"` // "discovered", "updated", "new_commands"
+	Session  *session.Session       `json:"session"`
+	Commands []session.CommandEntry `json:"commands,omitempty"`
+}
+
+// parseAddr splits "unix:/path" into ("unix", "/path"); anything else is TCP
+func parseAddr(addr string) (network, address string) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", addr
+}
+
+// viewerQueue is how many frames a viewer may fall behind by before it is
+// disconnected
+const viewerQueue = 256
+
+// writeTimeout bounds each write to a viewer, so a stalled one is dropped
+// instead of holding up its queue forever
+const writeTimeout = 10 * time.Second
+
+// viewer is a connected read-only monitor. broadcast queues frames on send
+// without blocking; serve writes them out on the viewer's own goroutine.
+type viewer struct {
+	conn net.Conn
+	send chan []byte
+}
+
+// Server streams a watcher's sessions and events to connected viewers
+type Server struct {
+	watcher *session.Watcher
+	events  <-chan session.WatchEvent
+
+	mu      sync.Mutex
+	viewers map[*viewer]struct{}
+}
+
+// NewServer creates a share server for the given watcher
+func NewServer(w *session.Watcher) *Server {
+	return &Server{
+		watcher: w,
+		events:  w.Subscribe(),
+		viewers: make(map[*viewer]struct{}),
+	}
+}
+
+// Listen opens a listener for addr ("unix:/path/to.sock" or "host:port").
+// Viewers aren't authenticated, so a Unix socket is made private to the user
+// (a stale one from a previous run is removed first) and TCP listens on
+// loopback only; a host-less ":port" means 127.0.0.1. Reach it from other
+// machines through an SSH tunnel.
+func Listen(addr string) (net.Listener, error) {
+	network, address := parseAddr(addr)
+	if network == "unix" {
+		if err := os.Remove(address); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		ln, err := net.Listen(network, address)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(address, 0o600); err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+		return ln, nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	switch ip := net.ParseIP(host); {
+	case host == "":
+		address = "127.0.0.1" + address
+	case host != "localhost" && (ip == nil || !ip.IsLoopback()):
+		return nil, fmt.Errorf("share listener %s is not loopback: viewers are unauthenticated, so listen on 127.0.0.1 and tunnel over SSH", addr)
+	}
+	return net.Listen(network, address)
+}
+
+// Serve accepts viewers on ln until it is closed
+func (s *Server) Serve(ln net.Listener) error {
+	go s.broadcastLoop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go s.addViewer(conn)
+	}
+}
+
+// addViewer sends the current state to a new viewer, then its queued events
+func (s *Server) addViewer(conn net.Conn) {
+	defer conn.Close()
+	v := &viewer{conn: conn, send: make(chan []byte, viewerQueue)}
+
+	// Take the snapshot and register under the lock so no event is
+	// interleaved or missed; the writes happen after it is released
+	s.mu.Lock()
+	var snapshot []byte
+	for _, sess := range s.watcher.Snapshot() {
+		data, err := encodeFrame(frame{Type: "discovered", Session: sess})
+		if err != nil {
+			s.mu.Unlock()
+			return
+		}
+		snapshot = append(snapshot, data...)
+	}
+	s.viewers[v] = struct{}{}
+	s.mu.Unlock()
+
+	if err := v.write(snapshot); err == nil {
+		v.serve()
+	}
+
+	s.mu.Lock()
+	if _, ok := s.viewers[v]; ok {
+		delete(s.viewers, v)
+		close(v.send)
+	}
+	s.mu.Unlock()
+}
+
+// serve writes queued frames until a write fails or times out, or broadcast
+// closes send because the viewer fell too far behind
+func (v *viewer) serve() {
+	for data := range v.send {
+		if err := v.write(data); err != nil {
+			return
+		}
+	}
+}
+
+// write sends data, giving up after writeTimeout
+func (v *viewer) write(data []byte) error {
+	if err := v.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	_, err := v.conn.Write(data)
+	return err
+}
+
+// broadcastLoop forwards watcher events and periodic metadata to viewers
+func (s *Server) broadcastLoop() {
+	// Push session metadata (activity status, branch) every refresh so
+	// viewers stay current without touching the filesystem
+	ticker := time.NewTicker(config.Global().RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-s.events:
+			event = s.watcher.SnapshotEvent(event)
+			s.broadcast(frame{Type: event.Type, Session: event.Session, Commands: event.Commands})
+
+		case <-ticker.C:
+			for _, sess := range s.watcher.GetSessions() {
+				event := s.watcher.SnapshotEvent(session.WatchEvent{Type: "updated", Session: sess})
+				s.broadcast(frame{Type: event.Type, Session: event.Session})
+			}
+		}
+	}
+}
+
+// broadcast queues a frame for every viewer without blocking, dropping
+// viewers whose queue is full
+func (s *Server) broadcast(f frame) {
+	data, err := encodeFrame(f)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for v := range s.viewers {
+		select {
+		case v.send <- data:
+		default:
+			// Closing send ends the viewer's writer, which disconnects it
+			delete(s.viewers, v)
+			close(v.send)
+		}
+	}
+}
+
+// encodeFrame encodes f as one newline-terminated line
+func encodeFrame(f frame) ([]byte, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Connect dials a share server and applies its stream to a replica watcher.
+// It returns once the connection is established; the stream is consumed in
+// the background and the error that ends it is delivered on w.Errors.
+func Connect(addr string, w *session.Watcher) error {
+	network, address := parseAddr(addr)
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer conn.Close()
+		err := consume(conn, w)
+		select {
+		case w.Errors <- err:
+		default:
+		}
+	}()
+	return nil
+}
+
+// consume decodes frames from conn and injects them into w until EOF
+func consume(conn net.Conn, w *session.Watcher) error {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFrameSize)
+
+	for scanner.Scan() {
+		var f frame
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			continue
+		}
+		w.Inject(session.WatchEvent{Type: f.Type, Session: f.Session, Commands: f.Commands})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("share connection closed by owner")
+}
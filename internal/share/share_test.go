@@ -0,0 +1,143 @@
+package share
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+const testRecord = `{"type":"assistant","timestamp":"2026-01-02T10:00:00Z","uuid":"u1","sessionId":"sess-1","cwd":"/projects/alpha",` +
+	`"message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"git status"}}]}}` + "\n"
+
+func TestParseAddr(t *testing.T) {
+	tests := []struct {
+		addr        string
+		wantNetwork string
+		wantAddress string
+	}{
+		{"unix:/tmp/ccmon.sock", "unix", "/tmp/ccmon.sock"},
+		{"127.0.0.1:7000", "tcp", "127.0.0.1:7000"},
+		{":7000", "tcp", ":7000"},
+	}
+	for _, tt := range tests {
+		network, address := parseAddr(tt.addr)
+		if network != tt.wantNetwork || address != tt.wantAddress {
+			t.Errorf("parseAddr(%q) = (%q, %q), want (%q, %q)",
+				tt.addr, network, address, tt.wantNetwork, tt.wantAddress)
+		}
+	}
+}
+
+func TestListenLoopbackOnly(t *testing.T) {
+	ln, err := Listen(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if addr := ln.Addr().(*net.TCPAddr); !addr.IP.IsLoopback() {
+		t.Errorf("expected a host-less address to listen on loopback, got %s", addr)
+	}
+
+	if ln, err := Listen("0.0.0.0:0"); err == nil {
+		_ = ln.Close()
+		t.Error("expected a non-loopback TCP address to be refused")
+	}
+
+	sock := filepath.Join(t.TempDir(), "share.sock")
+	ln, err = Listen("unix:" + sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if info, err := os.Stat(sock); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected a private socket, got %v (%v)", info.Mode().Perm(), err)
+	}
+}
+
+func TestBroadcastDropsSlowViewer(t *testing.T) {
+	srv := &Server{viewers: make(map[*viewer]struct{})}
+	slow := &viewer{send: make(chan []byte)} // never drained
+	srv.viewers[slow] = struct{}{}
+
+	srv.broadcast(frame{Type: "updated"})
+
+	if _, ok := srv.viewers[slow]; ok {
+		t.Error("expected the full viewer to be dropped")
+	}
+	if _, open := <-slow.send; open {
+		t.Error("expected the dropped viewer's queue to be closed")
+	}
+}
+
+func TestViewerReceivesSnapshotAndEvents(t *testing.T) {
+	projectsDir := t.TempDir()
+	projectDir := filepath.Join(projectsDir, "-projects-alpha")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "sess-1.jsonl"), []byte(testRecord), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	owner, err := session.NewWatcher([]string{projectsDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = owner.Stop() })
+	srv := NewServer(owner)
+	if _, err := owner.DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := "unix:" + filepath.Join(t.TempDir(), "share.sock")
+	ln, err := Listen(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() { _ = srv.Serve(ln) }()
+
+	viewer, err := session.NewReplicaWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Connect(addr, viewer); err != nil {
+		t.Fatal(err)
+	}
+
+	// Snapshot arrives as a "discovered" event
+	select {
+	case ev := <-viewer.Events:
+		if ev.Type != "discovered" || ev.Session.ID != "sess-1" || len(ev.Session.Commands) != 1 {
+			t.Fatalf("unexpected snapshot event: %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for snapshot")
+	}
+
+	// A new command on the owner is appended on the viewer
+	ownerSess := owner.GetSessions()[0]
+	owner.Inject(session.WatchEvent{
+		Type:     "new_commands",
+		Session:  ownerSess,
+		Commands: []session.CommandEntry{{ToolName: "Bash", RawCommand: "go test", Pattern: "Bash(go:test:*)"}},
+	})
+
+	select {
+	case ev := <-viewer.Events:
+		if ev.Type != "new_commands" || len(ev.Session.Commands) != 2 {
+			t.Fatalf("unexpected event: type=%s commands=%d", ev.Type, len(ev.Session.Commands))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for new_commands")
+	}
+
+	sessions, _ := viewer.DiscoverSessions()
+	if len(sessions) != 1 {
+		t.Errorf("expected viewer to track 1 session, got %d", len(sessions))
+	}
+}
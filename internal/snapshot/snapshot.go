@@ -0,0 +1,305 @@
+// Package snapshot bundles the monitor's parsed state into a sanitized
+// tar.gz archive suitable for attaching to bug reports.
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/digest"
+	"cc_session_mon/internal/session"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRecentLimit is the number of most recent commands included by default
+const DefaultRecentLimit = 200
+
+// Options configures snapshot contents
+type Options struct {
+	Version     string         // Application version recorded in the manifest
+	Config      *config.Config // Active configuration
+	RecentLimit int            // Number of recent commands to include
+	Redact      bool           // Drop raw commands and the config, and anonymize project paths
+}
+
+// Manifest describes the snapshot and the environment that produced it
+type Manifest struct {
+	Version      string    `json:"version"`
+	GoVersion    string    `json:"go_version"`
+	OS           string    `json:"os"`
+	Arch         string    `json:"arch"`
+	CreatedAt    time.Time `json:"created_at"`
+	Redacted     bool      `json:"redacted"`
+	SessionCount int       `json:"session_count"`
+}
+
+// SessionSummary is the per-session record written to sessions.json
+type SessionSummary struct {
+	ID           string           `json:"id"`
+	ProjectPath  string           `json:"project_path"`
+	Origin       string           `json:"origin"`
+	Owner        string           `json:"owner,omitempty"`
+	GitBranch    string           `json:"git_branch"`
+	LastActivity time.Time        `json:"last_activity"`
+	IsActive     bool             `json:"is_active"`
+	CommandCount int              `json:"command_count"`
+	Summary      string           `json:"summary"`
+	Patterns     []PatternSummary `json:"patterns"`
+}
+
+// PatternSummary is an aggregated pattern within a session
+type PatternSummary struct {
+	Pattern  string    `json:"pattern"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+	Examples []string  `json:"examples,omitempty"`
+}
+
+// RecentCommand is one entry in recent_commands.json
+type RecentCommand struct {
+	Timestamp  time.Time `json:"timestamp"`
+	SessionID  string    `json:"session_id"`
+	ToolName   string    `json:"tool_name"`
+	Pattern    string    `json:"pattern"`
+	RawCommand string    `json:"raw_command,omitempty"`
+}
+
+// Write builds a snapshot of sessions and writes it as a tar.gz archive to path
+func Write(path string, sessions []*session.Session, opts Options) error {
+	if opts.RecentLimit <= 0 {
+		opts.RecentLimit = DefaultRecentLimit
+	}
+	san := newSanitizer(opts.Redact)
+
+	files := map[string]any{
+		"manifest.json": Manifest{
+			Version:      opts.Version,
+			GoVersion:    runtime.Version(),
+			OS:           runtime.GOOS,
+			Arch:         runtime.GOARCH,
+			CreatedAt:    time.Now(),
+			Redacted:     opts.Redact,
+			SessionCount: len(sessions),
+		},
+		"sessions.json":        summarizeSessions(sessions, san),
+		"recent_commands.json": recentCommands(sessions, opts.RecentLimit, san),
+	}
+
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	// Write in a stable order so archives are easy to diff
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := json.MarshalIndent(files[name], "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := addFile(tw, name, data); err != nil {
+			return err
+		}
+	}
+
+	// The config is full of project paths, so redacting leaves it out
+	if opts.Config != nil && !opts.Redact {
+		data, err := yaml.Marshal(san.config(opts.Config))
+		if err != nil {
+			return err
+		}
+		if err := addFile(tw, "config.yaml", []byte(san.command(string(data)))); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// addFile writes a single regular file entry to the archive
+func addFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// summarizeSessions converts sessions into sanitized summaries with patterns
+func summarizeSessions(sessions []*session.Session, san *sanitizer) []SessionSummary {
+	summaries := make([]SessionSummary, 0, len(sessions))
+	for _, sess := range sessions {
+		patterns := session.AggregatePatterns(sess.Commands)
+		ps := make([]PatternSummary, len(patterns))
+		for i, p := range patterns {
+			ps[i] = PatternSummary{
+				Pattern:  p.Pattern,
+				Count:    p.Count,
+				LastSeen: p.LastSeen,
+				Examples: san.commands(p.Examples),
+			}
+		}
+		summaries = append(summaries, SessionSummary{
+			ID:           sess.ID,
+			ProjectPath:  san.project(sess.ProjectPath),
+			Origin:       sess.Origin,
+			Owner:        config.Global().OwnerOf(sess.Origin, sess.ProjectPath),
+			GitBranch:    sess.GitBranch,
+			LastActivity: sess.LastActivity,
+			IsActive:     sess.IsActive,
+			CommandCount: len(sess.Commands),
+			Summary:      san.summary(digest.Summarize(sess, nil)),
+			Patterns:     ps,
+		})
+	}
+	return summaries
+}
+
+// recentCommands returns the newest commands across all sessions
+func recentCommands(sessions []*session.Session, limit int, san *sanitizer) []RecentCommand {
+	var all []session.CommandEntry
+	for _, sess := range sessions {
+		all = append(all, sess.Commands...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Timestamp.After(all[j].Timestamp)
+	})
+	if len(all) > limit {
+		all = all[:limit]
+	}
+
+	recent := make([]RecentCommand, len(all))
+	for i := range all {
+		cmd := &all[i]
+		recent[i] = RecentCommand{
+			Timestamp:  cmd.Timestamp,
+			SessionID:  cmd.SessionID,
+			ToolName:   cmd.ToolName,
+			Pattern:    cmd.Pattern,
+			RawCommand: san.command(cmd.RawCommand),
+		}
+	}
+	return recent
+}
+
+// secretAssignment matches NAME=value pairs whose name suggests a credential
+var secretAssignment = regexp.MustCompile(`(?i)\b([A-Z0-9_]*(?:TOKEN|SECRET|PASSWORD|PASSWD|API_?KEY|ACCESS_?KEY)[A-Z0-9_]*)=(\S+)`)
+
+// sanitizer scrubs personal data from snapshot contents. It always replaces
+// the home directory with "~" and masks credential-looking assignments; with
+// redact set it also drops raw commands and anonymizes project paths.
+type sanitizer struct {
+	home     string
+	redact   bool
+	projects map[string]string
+}
+
+func newSanitizer(redact bool) *sanitizer {
+	home, _ := os.UserHomeDir()
+	return &sanitizer{home: home, redact: redact, projects: make(map[string]string)}
+}
+
+// command sanitizes a raw command, returning "" when redacting
+func (s *sanitizer) command(raw string) string {
+	if s.redact {
+		return ""
+	}
+	raw = secretAssignment.ReplaceAllString(raw, "$1=***")
+	if s.home != "" {
+		raw = strings.ReplaceAll(raw, s.home, "~")
+	}
+	return raw
+}
+
+// commands sanitizes a list of raw commands, dropping it entirely when redacting
+func (s *sanitizer) commands(raws []string) []string {
+	if s.redact {
+		return nil
+	}
+	out := make([]string, len(raws))
+	for i, r := range raws {
+		out[i] = s.command(r)
+	}
+	return out
+}
+
+// summary renders a session summary, without its command example and
+// directory when redacting
+func (s *sanitizer) summary(sum digest.Summary) string {
+	if s.redact {
+		return sum.Redacted().String()
+	}
+	sum.Example = s.command(sum.Example)
+	return sum.String()
+}
+
+// config returns a copy of cfg with the Slack, Discord, and sink webhook
+// URLs masked, since they are credentials. Write passes the YAML through
+// command too, for home paths and token assignments in exec sink commands.
+func (s *sanitizer) config(cfg *config.Config) *config.Config {
+	masked := *cfg
+	alerts := &masked.Alerts
+	alerts.Slack = maskWebhook(alerts.Slack)
+	alerts.Discord = maskWebhook(alerts.Discord)
+	alerts.Sinks = slices.Clone(alerts.Sinks)
+	for i := range alerts.Sinks {
+		alerts.Sinks[i].ChatSink = maskWebhook(alerts.Sinks[i].ChatSink)
+	}
+	return &masked
+}
+
+// maskWebhook hides a webhook URL; the name of the variable holding one is kept
+func maskWebhook(c config.ChatSink) config.ChatSink {
+	if c.WebhookURL != "" {
+		c.WebhookURL = "***"
+	}
+	return c
+}
+
+// project sanitizes a project path; when redacting, each distinct path maps to "project-N"
+func (s *sanitizer) project(path string) string {
+	if s.redact {
+		if alias, ok := s.projects[path]; ok {
+			return alias
+		}
+		alias := fmt.Sprintf("project-%d", len(s.projects)+1)
+		s.projects[path] = alias
+		return alias
+	}
+	if s.home != "" && strings.HasPrefix(path, s.home) {
+		return "~" + strings.TrimPrefix(path, s.home)
+	}
+	return path
+}
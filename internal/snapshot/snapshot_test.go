@@ -0,0 +1,168 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/digest"
+	"cc_session_mon/internal/session"
+)
+
+func TestSanitizerCommand(t *testing.T) {
+	s := &sanitizer{home: "/home/alice", projects: map[string]string{}}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"git status", "git status"},
+		{"cat /home/alice/notes.txt", "cat ~/notes.txt"},
+		{"GITHUB_TOKEN=ghp_abc123 gh pr list", "GITHUB_TOKEN=*** gh pr list"},
+		{"AWS_SECRET_ACCESS_KEY=xyz aws s3 ls", "AWS_SECRET_ACCESS_KEY=*** aws s3 ls"},
+		{"FOO=bar make", "FOO=bar make"},
+	}
+	for _, tt := range tests {
+		if got := s.command(tt.input); got != tt.expected {
+			t.Errorf("command(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSanitizerRedact(t *testing.T) {
+	s := &sanitizer{home: "/home/alice", redact: true, projects: map[string]string{}}
+
+	if got := s.command("git status"); got != "" {
+		t.Errorf("expected redacted command to be empty, got %q", got)
+	}
+	a := s.project("/home/alice/code/alpha")
+	b := s.project("/home/alice/code/beta")
+	if a != "project-1" || b != "project-2" {
+		t.Errorf("expected project-1/project-2, got %q/%q", a, b)
+	}
+	if again := s.project("/home/alice/code/alpha"); again != a {
+		t.Errorf("expected stable alias %q, got %q", a, again)
+	}
+	sum := digest.Summary{Commands: 3, EditedFiles: 1, EditedDir: "secret-plans", Dangerous: 1, Example: "rm -rf /home/alice"}
+	if got := s.summary(sum); got != "3 cmds: edited 1 file; 1 dangerous" {
+		t.Errorf("expected summary without example or directory, got %q", got)
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	now := time.Now()
+	sessions := []*session.Session{
+		{
+			ID:          "sess-1",
+			ProjectPath: "/projects/alpha",
+			Commands: []session.CommandEntry{
+				{ToolName: "Bash", RawCommand: "git status", Pattern: "Bash(git:status:*)", Timestamp: now},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "snap.tar.gz")
+	err := Write(path, sessions, Options{Version: "test", Config: config.DefaultConfig()})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, hdr.Name)
+	}
+
+	expected := []string{"manifest.json", "recent_commands.json", "sessions.json", "config.yaml"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected entries %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("entry %d: expected %q, got %q", i, expected[i], names[i])
+		}
+	}
+}
+
+func TestWriteMasksConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfg := config.DefaultConfig()
+	cfg.Alerts.Slack.WebhookURL = "https://hooks.slack.com/services/T0/B0/s3cr3t"
+	cfg.Alerts.Sinks = []config.SinkConfig{
+		{Name: "ops", Type: "webhook", ChatSink: config.ChatSink{WebhookURL: "https://example.com/hook?key=s3cr3t"}},
+		{Name: "page", Type: "exec", Command: []string{home + "/bin/page", "PAGER_TOKEN=s3cr3t"}},
+	}
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.tar.gz")
+	if err := Write(plain, nil, Options{Config: cfg}); err != nil {
+		t.Fatal(err)
+	}
+	data, ok := readArchive(t, plain)["config.yaml"]
+	if !ok {
+		t.Fatal("expected config.yaml in the archive")
+	}
+	if strings.Contains(data, "s3cr3t") || strings.Contains(data, home) {
+		t.Errorf("config.yaml leaks a secret or the home directory:\n%s", data)
+	}
+	if !strings.Contains(data, "~/bin/page") {
+		t.Errorf("expected the exec sink's program kept with ~ for home:\n%s", data)
+	}
+	if cfg.Alerts.Sinks[0].WebhookURL == "***" {
+		t.Error("masking changed the caller's config")
+	}
+
+	redacted := filepath.Join(dir, "redacted.tar.gz")
+	if err := Write(redacted, nil, Options{Config: cfg, Redact: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readArchive(t, redacted)["config.yaml"]; ok {
+		t.Error("expected a redacted archive to leave config.yaml out")
+	}
+}
+
+// readArchive returns the contents of a snapshot archive's entries by name
+func readArchive(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		data, _ := io.ReadAll(tr)
+		entries[hdr.Name] = string(data)
+	}
+	return entries
+}
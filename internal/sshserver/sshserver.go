@@ -0,0 +1,92 @@
+// Package sshserver exposes the TUI over SSH using charmbracelet/wish, so
+// sessions on one machine can be monitored from another terminal.
+package sshserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"cc_session_mon/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	wishtea "github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+)
+
+// shutdownTimeout bounds how long open connections get to close on exit
+const shutdownTimeout = 10 * time.Second
+
+// Options configures the SSH server
+type Options struct {
+	Addr               string // Listen address (e.g. ":2222")
+	HostKeyPath        string // Host key location; generated on first run if missing
+	AuthorizedKeysPath string // Public keys allowed to connect
+	Model              tui.ModelOptions
+}
+
+// Serve runs the SSH server until SIGINT/SIGTERM. Each connection gets its
+// own Model (and therefore its own Watcher).
+func Serve(opts Options) error {
+	if _, err := os.Stat(opts.AuthorizedKeysPath); err != nil {
+		return fmt.Errorf("authorized keys file required: %w", err)
+	}
+
+	srv, err := wish.NewServer(
+		wish.WithAddress(opts.Addr),
+		wish.WithHostKeyPath(opts.HostKeyPath),
+		wish.WithAuthorizedKeys(opts.AuthorizedKeysPath),
+		wish.WithMiddleware(
+			wishtea.Middleware(teaHandler(opts.Model)),
+			activeterm.Middleware(), // Bubble Tea apps need a PTY
+			logging.Middleware(),
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	fmt.Printf("Serving TUI over SSH on %s\n", opts.Addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-done:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// teaHandler builds a fresh Model for each SSH session
+func teaHandler(modelOpts tui.ModelOptions) wishtea.Handler {
+	return func(sess ssh.Session) (tea.Model, []tea.ProgramOption) {
+		// Build styles for the connecting client's terminal (its color profile
+		// and background), not the server's: the default renderer is shared
+		// by every client
+		opts := modelOpts
+		opts.Renderer = wishtea.MakeRenderer(sess)
+		return tui.NewModel(opts), []tea.ProgramOption{tea.WithAltScreen()}
+	}
+}
@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cc_session_mon/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// addDirSavedMsg reports saving an added directory to the config file
+type addDirSavedMsg struct {
+	dir  string
+	path string
+	err  error
+}
+
+// newAddDirInput creates the add-directory dialog's text input
+func newAddDirInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "~/.claude/projects of another machine or user"
+	input.Prompt = "> "
+	input.CharLimit = 4096
+	return input
+}
+
+// openAddDir opens the add-directory dialog ('A')
+func (m Model) openAddDir() (Model, tea.Cmd) {
+	if m.watcher == nil || m.watcher.IsReplica() {
+		m.notice = "Directories can only be added where the sessions are read"
+		return m, nil
+	}
+	m.showAddDir = true
+	m.addDirErr = ""
+	m.addDirInput.Reset()
+	return m, m.addDirInput.Focus()
+}
+
+// handleAddDirKey handles keys while the add-directory dialog is open: enter
+// adds the directory, tab toggles saving it to the config, esc cancels, and
+// everything else edits the path
+func (m Model) handleAddDirKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.showAddDir = false
+		m.addDirInput.Blur()
+		return m, nil
+	case "tab":
+		m.addDirSave = !m.addDirSave
+		return m, nil
+	case "enter":
+		return m.addDir()
+	}
+	var cmd tea.Cmd
+	m.addDirInput, cmd = m.addDirInput.Update(msg)
+	m.addDirErr = ""
+	return m, cmd
+}
+
+// addDir validates the typed directory and starts watching it, then saves
+// it to the config if asked to
+func (m Model) addDir() (tea.Model, tea.Cmd) {
+	dir, err := resolveProjectsDir(m.addDirInput.Value())
+	if err == nil && slices.Contains(m.watcher.ProjectsDirs(), dir) {
+		err = fmt.Errorf("%s is already watched", dir)
+	}
+	if err != nil {
+		m.addDirErr = err.Error()
+		return m, nil
+	}
+
+	m.watcher.AddProjectsDir(dir)
+	m.showAddDir = false
+	m.addDirInput.Blur()
+	m.notice = "Watching " + dir
+	cmds := []tea.Cmd{m.discoverSessionsCmd()}
+	if m.addDirSave {
+		cmds = append(cmds, func() tea.Msg {
+			path := config.SavePath()
+			return addDirSavedMsg{dir: dir, path: path, err: config.AddProjectsDir(path, dir)}
+		})
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// handleAddDirSaved reports where an added directory was saved
+func (m Model) handleAddDirSaved(msg addDirSavedMsg) Model {
+	if msg.err != nil {
+		m.notice = m.theme.ErrorStyle().UnsetPadding().Render(fmt.Sprintf("Watching %s, but it wasn't saved: %v", msg.dir, msg.err))
+		return m
+	}
+	m.notice = fmt.Sprintf("Watching %s (saved to %s)", msg.dir, msg.path)
+	return m
+}
+
+// resolveProjectsDir turns a typed or pasted path into the Claude projects
+// directory to watch: ~ and environment variables are expanded, and a home
+// directory or .claude directory is taken to mean the projects directory
+// inside it
+func resolveProjectsDir(input string) (string, error) {
+	input = strings.Trim(strings.TrimSpace(input), `"'`)
+	if input == "" {
+		return "", errors.New("type a directory to watch")
+	}
+	dir, err := filepath.Abs(os.ExpandEnv(config.ExpandHome(input)))
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err):
+		return "", fmt.Errorf("%s does not exist", dir)
+	case err != nil:
+		return "", err
+	case !info.IsDir():
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+	inner := filepath.Join(dir, ".claude", "projects")
+	if filepath.Base(dir) == ".claude" {
+		inner = filepath.Join(dir, "projects")
+	}
+	if info, err := os.Stat(inner); err == nil && info.IsDir() {
+		dir = inner
+	}
+	if _, err := os.ReadDir(dir); err != nil {
+		return "", fmt.Errorf("%s can't be read: %w", dir, err)
+	}
+	return dir, nil
+}
+
+// overlayAddDir renders the add-directory dialog centered over the existing view
+func (m Model) overlayAddDir(background string) string {
+	t := m.theme
+	save := "[ ]"
+	if m.addDirSave {
+		save = "[x]"
+	}
+	lines := []string{
+		m.theme.LabelStyle().Render("Watch another projects directory:"),
+		"",
+		m.addDirInput.View(),
+		"",
+		m.theme.MutedStyle().Render(save + " Save to " + config.SavePath()),
+	}
+	if m.addDirErr != "" {
+		lines = append(lines, "", m.theme.DangerStyle().Render(indicator("✗ ", "[ERROR] ")+m.addDirErr))
+	}
+	lines = append(lines, "", m.theme.NewStyle().Foreground(t.Muted).Italic(true).Render("enter:add  tab:toggle save  esc:cancel"))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return m.overlayDialog(background, content, min(m.width-8, 80))
+}
@@ -0,0 +1,586 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// analyticsPage is one of the pages of the Analytics view, cycled with a
+type analyticsPage int
+
+const (
+	pageCommands   analyticsPage = iota // All commands leaderboard
+	pageToolMix                         // Tool mix per project
+	pageActivity                        // Heatmap of commands per hour of each day
+	pageHotspots                        // Files most read, edited, and written
+	pageCategories                      // Commands per category of the classification
+	analyticsPageCount
+)
+
+// analyticsPageNames are the page titles shown in the column header
+var analyticsPageNames = [...]string{
+	pageCommands: "All commands", pageToolMix: "Tool mix", pageActivity: "Activity",
+	pageHotspots: "Hotspots", pageCategories: "Categories",
+}
+
+// ============================================================================
+// Command Rank Item
+// ============================================================================
+
+// commandRankItem wraps a CommandRank for the list component
+type commandRankItem struct {
+	rank      *session.CommandRank
+	byPattern bool
+}
+
+func (i commandRankItem) FilterValue() string { return i.rank.Key }
+func (i commandRankItem) Title() string       { return i.rank.Key }
+func (i commandRankItem) Description() string {
+	return fmt.Sprintf("%d runs in %d sessions", i.rank.Count, i.rank.Sessions)
+}
+
+// commandRankDelegate renders the rows of the All commands leaderboard
+type commandRankDelegate struct {
+	theme *Theme
+	width int
+}
+
+// Column widths for the All commands leaderboard (exported for header rendering)
+const (
+	RankCountWidth    = 7
+	RankSessionsWidth = 8
+	RankLastUsedWidth = 10
+)
+
+func newCommandRankDelegate(theme *Theme) *commandRankDelegate {
+	return &commandRankDelegate{width: 80, theme: theme}
+}
+
+func (d *commandRankDelegate) SetWidth(w int) {
+	d.width = w
+}
+
+func (d *commandRankDelegate) Height() int                             { return 1 }
+func (d *commandRankDelegate) Spacing() int                            { return 0 }
+func (d *commandRankDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d *commandRankDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(commandRankItem)
+	if !ok {
+		return
+	}
+
+	// Format: "count  sessions  last used  pattern  command..." (or
+	// "... pattern  group" when ranking patterns)
+	group := toolGroupFor(nil, i.rank.Pattern)
+	last := i.rank.Key
+	if i.byPattern {
+		last = ""
+		if group != nil {
+			last = group.Name
+		}
+	}
+	fixedWidth := RankCountWidth + 2 + RankSessionsWidth + 2 + RankLastUsedWidth + 2 + CommandPatternWidth + 2
+	lastWidth := max(10, d.width-fixedWidth)
+	if len(last) > lastWidth {
+		last = truncateWithEllipsis(last, lastWidth)
+	}
+
+	pattern := i.rank.Pattern
+	if len(pattern) > CommandPatternWidth {
+		pattern = truncateWithEllipsis(pattern, CommandPatternWidth)
+	}
+	row := fmt.Sprintf("%s  %s  %s  %s  %s",
+		padLeft(fmt.Sprintf("%d", i.rank.Count), RankCountWidth),
+		padLeft(fmt.Sprintf("%d", i.rank.Sessions), RankSessionsWidth),
+		padRight(formatTimeAgo(i.rank.LastUsed), RankLastUsedWidth),
+		padRight(pattern, CommandPatternWidth),
+		last,
+	)
+	if len(row) < d.width {
+		row += strings.Repeat(" ", d.width-len(row))
+	}
+
+	style := d.theme.styleForGroup(group).Width(d.width)
+	if index == m.Index() {
+		style = style.Background(d.theme.Surface).Bold(true)
+	}
+	fmt.Fprint(w, style.Render(row))
+}
+
+// ============================================================================
+// Tool Mix Item
+// ============================================================================
+
+// toolMixItem wraps a project's ToolMix for the list component
+type toolMixItem struct {
+	mix *session.ToolMix
+}
+
+func (i toolMixItem) FilterValue() string { return i.mix.ProjectPath }
+func (i toolMixItem) Title() string       { return i.mix.ProjectPath }
+func (i toolMixItem) Description() string {
+	return fmt.Sprintf("%d commands in %d sessions", i.mix.Total, i.mix.Sessions)
+}
+
+// toolMixDelegate renders a project's stacked bar of tool kinds
+type toolMixDelegate struct {
+	theme *Theme
+	width int
+}
+
+// Column widths for the tool mix (exported for header rendering)
+const (
+	MixProjectWidth = 20
+	MixBarWidth     = 30
+	MixTotalWidth   = 8
+)
+
+// toolKindColors are the bar colors of the tool kinds, matching the
+// default tool groups
+var toolKindColors = map[string]string{
+	session.KindRead:  "green",
+	session.KindEdit:  "yellow",
+	session.KindWrite: "peach",
+	session.KindBash:  "mauve",
+	session.KindOther: "overlay1",
+}
+
+func newToolMixDelegate(theme *Theme) *toolMixDelegate {
+	return &toolMixDelegate{width: 80, theme: theme}
+}
+
+func (d *toolMixDelegate) SetWidth(w int) {
+	d.width = w
+}
+
+func (d *toolMixDelegate) Height() int                             { return 1 }
+func (d *toolMixDelegate) Spacing() int                            { return 0 }
+func (d *toolMixDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d *toolMixDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(toolMixItem)
+	if !ok {
+		return
+	}
+
+	base := d.theme.NormalItemStyle()
+	if index == m.Index() {
+		base = base.Background(d.theme.Surface).Bold(true)
+	}
+
+	// Format: "project  ████▒▒▒▒  commands  R 40% E 20% W 5% B 30% O 5%"
+	project := filepath.Base(i.mix.ProjectPath)
+	if len(project) > MixProjectWidth {
+		project = truncateWithEllipsis(project, MixProjectWidth)
+	}
+	shares := make([]string, len(session.ToolMixKinds))
+	for k, kind := range session.ToolMixKinds {
+		shares[k] = fmt.Sprintf("%s %d%%", kind[:1], int(math.Round(i.mix.Share(kind)*100)))
+	}
+
+	row := base.Render(padRight(project, MixProjectWidth)+"  ") +
+		renderMixBar(d.theme, i.mix, MixBarWidth, base) +
+		base.Render("  "+padLeft(fmt.Sprintf("%d", i.mix.Total), MixTotalWidth)+"  "+strings.Join(shares, " "))
+	if pad := d.width - lipgloss.Width(row); pad > 0 {
+		row += base.Render(strings.Repeat(" ", pad))
+	}
+	fmt.Fprint(w, row)
+}
+
+// renderMixBar renders a mix as a bar of width columns, one colored segment
+// per tool kind (its initial when text_indicators is set)
+func renderMixBar(theme *Theme, mix *session.ToolMix, width int, base lipgloss.Style) string {
+	var b strings.Builder
+	for k, n := range mixSegments(mix, width) {
+		kind := session.ToolMixKinds[k]
+		style := base.Foreground(theme.ColorByName(toolKindColors[kind]))
+		b.WriteString(style.Render(strings.Repeat(indicator("█", kind[:1]), n)))
+	}
+	return b.String()
+}
+
+// mixSegments splits width columns between the tool kinds in proportion to
+// their counts (largest remainder), so the segments always fill the bar
+func mixSegments(mix *session.ToolMix, width int) []int {
+	segments := make([]int, len(session.ToolMixKinds))
+	if mix.Total == 0 {
+		return segments
+	}
+	remainders := make([]float64, len(segments))
+	used := 0
+	for k, kind := range session.ToolMixKinds {
+		exact := mix.Share(kind) * float64(width)
+		segments[k] = int(exact)
+		remainders[k] = exact - float64(segments[k])
+		used += segments[k]
+	}
+	for ; used < width; used++ {
+		largest := 0
+		for k := range remainders {
+			if remainders[k] > remainders[largest] {
+				largest = k
+			}
+		}
+		segments[largest]++
+		remainders[largest] = -1
+	}
+	return segments
+}
+
+// ============================================================================
+// Hotspot Item
+// ============================================================================
+
+// hotspotItem wraps a FileHotspot for the list component
+type hotspotItem struct {
+	spot *session.FileHotspot
+}
+
+func (i hotspotItem) FilterValue() string { return i.spot.Path }
+func (i hotspotItem) Title() string       { return hotspotPath(i.spot) }
+func (i hotspotItem) Description() string {
+	return fmt.Sprintf("edited %d times across %d sessions", i.spot.Changes(), i.spot.Sessions)
+}
+
+// hotspotDelegate renders the rows of the file hotspots
+type hotspotDelegate struct {
+	theme *Theme
+	width int
+}
+
+// HotspotCountWidth is the width of the edit, write, and read count columns
+const HotspotCountWidth = 6
+
+func newHotspotDelegate(theme *Theme) *hotspotDelegate {
+	return &hotspotDelegate{width: 80, theme: theme}
+}
+
+func (d *hotspotDelegate) SetWidth(w int) {
+	d.width = w
+}
+
+func (d *hotspotDelegate) Height() int                             { return 1 }
+func (d *hotspotDelegate) Spacing() int                            { return 0 }
+func (d *hotspotDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d *hotspotDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(hotspotItem)
+	if !ok {
+		return
+	}
+
+	// Format: "edits  writes  reads  sessions  last touched  project/path..."
+	fixedWidth := 3*(HotspotCountWidth+2) + RankSessionsWidth + 2 + RankLastUsedWidth + 2
+	path := hotspotPath(i.spot)
+	if pathWidth := max(10, d.width-fixedWidth); len(path) > pathWidth {
+		// Keep the file name end of long paths
+		path = ellipsis() + path[len(path)-pathWidth+lipgloss.Width(ellipsis()):]
+	}
+	row := fmt.Sprintf("%s  %s  %s  %s  %s  %s",
+		padLeft(fmt.Sprintf("%d", i.spot.Edits), HotspotCountWidth),
+		padLeft(fmt.Sprintf("%d", i.spot.Writes), HotspotCountWidth),
+		padLeft(fmt.Sprintf("%d", i.spot.Reads), HotspotCountWidth),
+		padLeft(fmt.Sprintf("%d", i.spot.Sessions), RankSessionsWidth),
+		padRight(formatTimeAgo(i.spot.LastTouched), RankLastUsedWidth),
+		path,
+	)
+	if len(row) < d.width {
+		row += strings.Repeat(" ", d.width-len(row))
+	}
+
+	// Colored like the tool that changed (or only read) the file
+	pattern := "Read"
+	if i.spot.Changes() > 0 {
+		pattern = "Edit"
+	}
+	style := d.theme.styleForGroup(toolGroupFor(nil, pattern)).Width(d.width)
+	if index == m.Index() {
+		style = style.Background(d.theme.Surface).Bold(true)
+	}
+	fmt.Fprint(w, style.Render(row))
+}
+
+// hotspotPath shows a file relative to its project, under the project's
+// name (e.g. "app/internal/model.go"), or in full when outside it
+func hotspotPath(spot *session.FileHotspot) string {
+	rel, err := filepath.Rel(spot.ProjectPath, spot.Path)
+	if spot.ProjectPath == "" || err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return spot.Path
+	}
+	return filepath.Join(filepath.Base(spot.ProjectPath), rel)
+}
+
+// ============================================================================
+// Category Item
+// ============================================================================
+
+// categoryItem wraps a CategoryStat for the list component
+type categoryItem struct {
+	stat  *session.CategoryStat
+	total int // Commands in all categories
+}
+
+func (i categoryItem) FilterValue() string { return i.stat.Name }
+func (i categoryItem) Title() string       { return i.stat.Name }
+func (i categoryItem) Description() string {
+	return fmt.Sprintf("%d commands in %d sessions", i.stat.Count, i.stat.Sessions)
+}
+
+// share returns the category's fraction (0-1) of all commands
+func (i categoryItem) share() float64 {
+	if i.total == 0 {
+		return 0
+	}
+	return float64(i.stat.Count) / float64(i.total)
+}
+
+// categoryDelegate renders a category's count and share of all commands
+type categoryDelegate struct {
+	theme *Theme
+	width int
+}
+
+// Column widths for the categories (exported for header rendering)
+const (
+	CategoryNameWidth  = 12
+	CategoryBarWidth   = 20
+	CategoryShareWidth = 4
+)
+
+func newCategoryDelegate(theme *Theme) *categoryDelegate {
+	return &categoryDelegate{width: 80, theme: theme}
+}
+
+func (d *categoryDelegate) SetWidth(w int) {
+	d.width = w
+}
+
+func (d *categoryDelegate) Height() int                             { return 1 }
+func (d *categoryDelegate) Spacing() int                            { return 0 }
+func (d *categoryDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d *categoryDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(categoryItem)
+	if !ok {
+		return
+	}
+
+	base := d.theme.NormalItemStyle()
+	if i.stat.Name == config.Uncategorized {
+		base = d.theme.MutedStyle()
+	}
+	if index == m.Index() {
+		base = base.Background(d.theme.Surface).Bold(true)
+	}
+
+	// Format: "category  count  sessions  ████░░░░  share  top patterns..."
+	filled := int(math.Round(i.share() * CategoryBarWidth))
+	bar := d.theme.NewStyle().Inherit(base).Foreground(d.theme.Secondary).Render(strings.Repeat(indicator("█", "#"), filled)) +
+		base.Render(strings.Repeat(indicator("░", "."), CategoryBarWidth-filled))
+	name := i.stat.Name
+	if len(name) > CategoryNameWidth {
+		name = truncateWithEllipsis(name, CategoryNameWidth)
+	}
+	row := base.Render(fmt.Sprintf("%s  %s  %s  ",
+		padRight(name, CategoryNameWidth),
+		padLeft(fmt.Sprintf("%d", i.stat.Count), RankCountWidth),
+		padLeft(fmt.Sprintf("%d", i.stat.Sessions), RankSessionsWidth),
+	)) + bar + base.Render(fmt.Sprintf("  %s  %s",
+		padLeft(fmt.Sprintf("%d%%", int(math.Round(i.share()*100))), CategoryShareWidth),
+		strings.Join(i.stat.TopPatterns, ", "),
+	))
+	row = d.theme.NewStyle().Inline(true).MaxWidth(d.width).Render(row)
+	if pad := d.width - lipgloss.Width(row); pad > 0 {
+		row += base.Render(strings.Repeat(" ", pad))
+	}
+	fmt.Fprint(w, row)
+}
+
+// ============================================================================
+// Model helpers
+// ============================================================================
+
+// aggregateAnalytics rebuilds the analytics page being shown from all sessions
+func (m Model) aggregateAnalytics() Model {
+	switch m.analyticsPage {
+	case pageCommands:
+		m.commandRanks = session.RankCommands(m.sessions, m.ranksByPattern)
+		items := make([]list.Item, len(m.commandRanks))
+		for i, r := range m.commandRanks {
+			items[i] = commandRankItem{rank: r, byPattern: m.ranksByPattern}
+		}
+		m.rankList.SetItems(items)
+	case pageToolMix:
+		mixes := session.ToolMixByProject(m.sessions)
+		items := make([]list.Item, len(mixes))
+		for i, mix := range mixes {
+			items[i] = toolMixItem{mix: mix}
+		}
+		m.mixList.SetItems(items)
+	case pageActivity:
+		now := time.Now().In(config.Global().Clock.Location())
+		m.heatmap = session.ActivityHeatmap(m.sessions, now.AddDate(0, 0, -(heatmapDays-1)), now)
+	case pageHotspots:
+		spots := session.FileHotspots(m.sessions)
+		items := make([]list.Item, len(spots))
+		for i, spot := range spots {
+			items[i] = hotspotItem{spot: spot}
+		}
+		m.hotspotList.SetItems(items)
+	case pageCategories:
+		stats := session.CategoryStats(m.sessions)
+		total := 0
+		for _, st := range stats {
+			total += st.Count
+		}
+		items := make([]list.Item, len(stats))
+		for i, st := range stats {
+			items[i] = categoryItem{stat: st, total: total}
+		}
+		m.categoryList.SetItems(items)
+	}
+	return m
+}
+
+// cycleAnalyticsPage switches to the next analytics page
+func (m Model) cycleAnalyticsPage() Model {
+	m.analyticsPage = (m.analyticsPage + 1) % analyticsPageCount
+	return m.aggregateAnalytics()
+}
+
+// updateAnalyticsList forwards a message to the list of the analytics page being shown
+func (m Model) updateAnalyticsList(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch m.analyticsPage {
+	case pageCommands:
+		m.rankList, cmd = m.rankList.Update(msg)
+	case pageToolMix:
+		m.mixList, cmd = m.mixList.Update(msg)
+	case pageHotspots:
+		m.hotspotList, cmd = m.hotspotList.Update(msg)
+	case pageCategories:
+		m.categoryList, cmd = m.categoryList.Update(msg)
+	}
+	return m, cmd
+}
+
+// toggleRanksByPattern switches the leaderboard between raw commands and patterns
+func (m Model) toggleRanksByPattern() Model {
+	m.ranksByPattern = !m.ranksByPattern
+	m = m.aggregateAnalytics()
+	m.rankList.Select(0)
+	return m
+}
+
+// renderAnalyticsView renders the analytics page being shown
+func (m Model) renderAnalyticsView() string {
+	switch m.analyticsPage {
+	case pageActivity:
+		return m.renderActivityHeatmap()
+	case pageHotspots:
+		header := fmt.Sprintf("%s  %s  %s  %s  %s  %s",
+			padLeft("Edits", HotspotCountWidth),
+			padLeft("Writes", HotspotCountWidth),
+			padLeft("Reads", HotspotCountWidth),
+			padLeft("Sessions", RankSessionsWidth),
+			padRight("Last", RankLastUsedWidth),
+			"File - "+analyticsPageNames[pageHotspots],
+		)
+		return m.theme.ColumnHeaderStyle(m.width-4).Render(header) + "\n" + m.hotspotList.View()
+	case pageCategories:
+		header := fmt.Sprintf("%s  %s  %s  %s  %s  %s",
+			padRight("Category", CategoryNameWidth),
+			padLeft("Count", RankCountWidth),
+			padLeft("Sessions", RankSessionsWidth),
+			padRight("Share", CategoryBarWidth),
+			padLeft("", CategoryShareWidth),
+			"Top patterns - "+analyticsPageNames[pageCategories],
+		)
+		return m.theme.ColumnHeaderStyle(m.width-4).Render(header) + "\n" + m.categoryList.View()
+	}
+	if m.analyticsPage == pageToolMix {
+		header := fmt.Sprintf("%s  %s  %s  %s",
+			padRight("Project", MixProjectWidth),
+			padRight("Read/Edit/Write/Bash/Other", MixBarWidth),
+			padLeft("Commands", MixTotalWidth),
+			"Shares - "+analyticsPageNames[pageToolMix],
+		)
+		return m.theme.ColumnHeaderStyle(m.width-4).Render(header) + "\n" + m.mixList.View()
+	}
+
+	last := "Command - " + analyticsPageNames[pageCommands]
+	if m.ranksByPattern {
+		last = "Group - All patterns"
+	}
+	header := fmt.Sprintf("%s  %s  %s  %s  %s",
+		padLeft("Count", RankCountWidth),
+		padLeft("Sessions", RankSessionsWidth),
+		padRight("Last used", RankLastUsedWidth),
+		padRight("Pattern", CommandPatternWidth),
+		last,
+	)
+	return m.theme.ColumnHeaderStyle(m.width-4).Render(header) + "\n" + m.rankList.View()
+}
+
+// ============================================================================
+// Activity heatmap
+// ============================================================================
+
+// heatmapDays is the number of days the Activity page counts
+const heatmapDays = 30
+
+// heatmapShades are the cells of the Activity heatmap from no commands to
+// the busiest hour, with text equivalents for text_indicators
+var heatmapShades = [...]struct{ symbol, text string }{
+	{"··", " ."}, {"░░", " 1"}, {"▒▒", " 2"}, {"▓▓", " 3"}, {"██", " 4"},
+}
+
+// renderActivityHeatmap renders the commands per hour of the most recent
+// days that fit, newest first, like a contributions calendar turned sideways
+func (m Model) renderActivityHeatmap() string {
+	hours := make([]string, 24)
+	for h := range hours {
+		hours[h] = "  "
+		if h%3 == 0 {
+			hours[h] = fmt.Sprintf("%02d", h)
+		}
+	}
+	header := padRight("Day", 10) + "  " + strings.Join(hours, "") + "  Total - " + analyticsPageNames[pageActivity]
+	lines := []string{m.theme.ColumnHeaderStyle(m.width - 4).Render(header)}
+
+	h := m.heatmap
+	if h == nil {
+		return lines[0]
+	}
+	// The list height, less the legend and the blank line above it
+	rows := max(1, m.height-11-m.logPaneHeight())
+	shade := m.theme.NewStyle().Foreground(m.theme.Secondary)
+	for i := len(h.Days) - 1; i >= 0 && len(lines) <= rows; i-- {
+		var b strings.Builder
+		b.WriteString(padRight(h.Days[i].Format("Mon Jan 02"), 10) + "  ")
+		total := 0
+		for _, count := range h.Counts[i] {
+			cell := heatmapShades[h.Level(count, len(heatmapShades))]
+			b.WriteString(shade.Render(indicator(cell.symbol, cell.text)))
+			total += count
+		}
+		fmt.Fprintf(&b, "  %5d", total)
+		lines = append(lines, b.String())
+	}
+
+	legend := make([]string, len(heatmapShades))
+	for i, cell := range heatmapShades {
+		legend[i] = shade.Render(indicator(cell.symbol, cell.text))
+	}
+	lines = append(lines, "", m.theme.MutedStyle().Render("less ")+strings.Join(legend, " ")+
+		m.theme.MutedStyle().Render(fmt.Sprintf(" more (busiest hour: %d commands)", h.Max)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"mime"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"cc_session_mon/internal/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// attachmentsSavedMsg reports the temp files a command's result attachments
+// were written to
+type attachmentsSavedMsg struct {
+	key   string // detailKey of the command
+	paths []string
+}
+
+// openAttachmentsCmd writes a result's attachments to temp files and opens
+// each with the system viewer, when there is one
+func openAttachmentsCmd(key string, attachments []session.Attachment) tea.Cmd {
+	return func() tea.Msg {
+		opener := systemOpener()
+		var paths []string
+		for _, att := range attachments {
+			path, err := writeAttachment(att)
+			if err != nil {
+				return errMsg{err}
+			}
+			paths = append(paths, path)
+			if opener != "" {
+				_ = exec.Command(opener, path).Run() //nolint:gosec // fixed opener binary, our own temp file
+			}
+		}
+		return attachmentsSavedMsg{key: key, paths: paths}
+	}
+}
+
+// writeAttachment writes an attachment to a temp file named for its media type
+func writeAttachment(att session.Attachment) (string, error) {
+	ext := ".bin"
+	if exts, _ := mime.ExtensionsByType(att.MediaType); len(exts) > 0 {
+		ext = exts[0]
+	}
+	f, err := os.CreateTemp("", "cc_session_mon-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(att.Data); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// systemOpener returns the command that opens a file in its default viewer
+// (or a directory in the file manager),
+// or "" when none is installed
+func systemOpener() string {
+	command := "xdg-open"
+	switch runtime.GOOS {
+	case "darwin":
+		command = "open"
+	case "windows":
+		command = "explorer"
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return ""
+	}
+	return path
+}
@@ -21,7 +21,14 @@ import (
 
 // sessionItem wraps a Session for the list component
 type sessionItem struct {
-	session *session.Session
+	session  *session.Session
+	summary  string            // One-line summary, shown in the expanded view
+	timeline *session.Timeline // Duration and phases, shown in the expanded view
+	chainPos int               // Position in its resume chain (1 is the original), when chainLen > 1
+	chainLen int
+	size     int    // Bytes on disk, main file and subagents; 0 until measured
+	owner    string // Owner label from config owners, if any
+	blast    int    // Blast radius score while the list is sorted by it, else -1
 }
 
 func (i sessionItem) FilterValue() string { return i.session.ProjectPath }
@@ -38,20 +45,27 @@ func (i sessionItem) Description() string {
 	)
 }
 
-// sessionDelegate renders session items
+// sessionDelegate renders session items, with a summary row when expanded
 type sessionDelegate struct {
-	width int
+	theme    *Theme
+	width    int
+	expanded bool
 }
 
-func newSessionDelegate() *sessionDelegate {
-	return &sessionDelegate{width: 80}
+func newSessionDelegate(theme *Theme) *sessionDelegate {
+	return &sessionDelegate{width: 80, theme: theme}
 }
 
 func (d *sessionDelegate) SetWidth(w int) {
 	d.width = w
 }
 
-func (d *sessionDelegate) Height() int                             { return 1 }
+func (d *sessionDelegate) Height() int {
+	if d.expanded {
+		return 2
+	}
+	return 1
+}
 func (d *sessionDelegate) Spacing() int                            { return 0 }
 func (d *sessionDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d *sessionDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
@@ -61,27 +75,46 @@ func (d *sessionDelegate) Render(w io.Writer, m list.Model, index int, item list
 	}
 
 	// Build the row content
-	var indicator string
-	if i.session.IsActive {
-		indicator = "● "
-	} else {
-		indicator = "  "
-	}
+	indicator := activityIndicator(i.session.IsActive)
 
 	// Add origin tag for devagent sessions
 	var originTag string
-	if i.session.Origin != "" && i.session.Origin != "local" {
+	if strings.HasPrefix(i.session.Origin, "devagent:") {
 		originTag = "[da] "
 	}
 
+	// Flagged by alert actions: marker before, reasons after the project
+	var flagTag string
 	name := i.session.ProjectPath
+	if len(i.session.Flags) > 0 {
+		flagTag = flagMarker()
+		name += " [" + strings.Join(i.session.Flags, ", ") + "]"
+	}
 	info := fmt.Sprintf(" %d cmds | %s",
 		len(i.session.Commands),
 		formatTimeAgo(i.session.LastActivity),
 	)
+	// Disk usage, once measured
+	if i.size > 0 {
+		info = " " + formatSize(i.size) + " |" + info
+	}
+	if i.owner != "" {
+		info = " " + i.owner + " |" + info
+	}
+	if i.blast >= 0 {
+		info = fmt.Sprintf(" blast %d |", i.blast) + info
+	}
+	// Resumed sessions show their place in the chain, e.g. "↻ 2/3"
+	if i.chainLen > 1 {
+		info = fmt.Sprintf(" ↻ %d/%d |", i.chainPos, i.chainLen) + info
+	}
+	// Sessions also found under other watched roots show the copy count
+	if n := len(i.session.Duplicates); n > 0 {
+		info = fmt.Sprintf(" ×%d |", n+1) + info
+	}
 
 	// Calculate available space for name (use lipgloss.Width for Unicode-safe measurement)
-	availableWidth := d.width - lipgloss.Width(originTag) - lipgloss.Width(indicator) - lipgloss.Width(info) - 2
+	availableWidth := d.width - lipgloss.Width(originTag) - lipgloss.Width(flagTag) - lipgloss.Width(indicator) - lipgloss.Width(info) - 2
 	if availableWidth < 10 {
 		availableWidth = 10
 	}
@@ -91,37 +124,73 @@ func (d *sessionDelegate) Render(w io.Writer, m list.Model, index int, item list
 		name = name[:availableWidth-3] + "..."
 	}
 
-	row := originTag + indicator + name + strings.Repeat(" ", max(0, availableWidth-len(name))) + info
+	row := originTag + flagTag + indicator + name + strings.Repeat(" ", max(0, availableWidth-len(name))) + info
 
 	// Apply styling
 	var style lipgloss.Style
 	switch {
 	case index == m.Index():
-		style = lipgloss.NewStyle().
-			Background(GetTheme().Surface).
-			Foreground(GetTheme().Text).
+		style = d.theme.NewStyle().
+			Background(d.theme.Surface).
+			Foreground(d.theme.Text).
 			Bold(true).
 			Width(d.width)
+	case len(i.session.Flags) > 0:
+		style = d.theme.DangerStyle().Bold(true).Width(d.width)
 	case i.session.IsActive:
-		style = lipgloss.NewStyle().
-			Foreground(GetTheme().Secondary).
+		style = d.theme.NewStyle().
+			Foreground(d.theme.Secondary).
 			Width(d.width)
 	default:
-		style = lipgloss.NewStyle().
-			Foreground(GetTheme().Muted).
+		style = d.theme.NewStyle().
+			Foreground(d.theme.Muted).
 			Width(d.width)
 	}
 
 	fmt.Fprint(w, style.Render(row))
+	if d.expanded {
+		// Phase bar and duration first, once the session has commands
+		summary := d.theme.MutedStyle().Render(i.summary)
+		if i.timeline != nil && len(i.session.Commands) > 0 {
+			summary = renderPhaseBar(d.theme, i.timeline, phaseBarWidth) + d.theme.MutedStyle().Render(" "+formatDuration(i.timeline.Duration())+" · "+i.summary)
+		}
+		fmt.Fprint(w, "\n"+d.theme.NewStyle().Inline(true).MaxWidth(d.width).Render("    "+summary))
+	}
 }
 
 // ============================================================================
 // Command Item
 // ============================================================================
 
+// singleLine prepares a command for one-row display: heredoc bodies are
+// dropped (the opener line remains) and other newlines become a visible marker
+func singleLine(raw string) string {
+	script, _ := session.SplitHeredocs(raw)
+	return strings.ReplaceAll(script, "\n", "↵")
+}
+
+// toolGroupFor returns the matching tool group from cfg, or from the global config if cfg is nil
+func toolGroupFor(cfg *config.Config, pattern string) *config.ToolGroup {
+	if cfg == nil {
+		cfg = config.Global()
+	}
+	return cfg.GetToolGroup(pattern)
+}
+
+// categoryFor returns the category cfg classifies a pattern in, or the
+// global config's if cfg is nil
+func categoryFor(cfg *config.Config, pattern string) string {
+	if cfg == nil {
+		cfg = config.Global()
+	}
+	return cfg.Classify(pattern)
+}
+
 // commandItem wraps a CommandEntry for the list component
 type commandItem struct {
 	command session.CommandEntry
+	cfg     *config.Config // Project-aware config (nil means global)
+	outside []string       // Paths written outside the session's project
 }
 
 func (i commandItem) FilterValue() string { return i.command.RawCommand }
@@ -130,18 +199,21 @@ func (i commandItem) Description() string { return i.command.RawCommand }
 
 // commandDelegate renders command items
 type commandDelegate struct {
-	width int
+	theme     *Theme
+	width     int
+	highlight string // Active search query, highlighted within commands
 }
 
 // Column widths for command list (exported for header rendering)
 const (
 	CommandTimestampWidth = 12
 	CommandGroupWidth     = 12
+	CommandCategoryWidth  = 8
 	CommandPatternWidth   = 20
 )
 
-func newCommandDelegate() *commandDelegate {
-	return &commandDelegate{width: 80}
+func newCommandDelegate(theme *Theme) *commandDelegate {
+	return &commandDelegate{width: 80, theme: theme}
 }
 
 func (d *commandDelegate) SetWidth(w int) {
@@ -152,51 +224,64 @@ func (d *commandDelegate) Height() int                             { return 1 }
 func (d *commandDelegate) Spacing() int                            { return 0 }
 func (d *commandDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d *commandDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if mk, ok := item.(markerItem); ok {
+		d.renderMarker(w, mk, index == m.Index())
+		return
+	}
 	i, ok := item.(commandItem)
 	if !ok {
 		return
 	}
 
-	// Format: "Jan 02 15:04  group  Pattern  command..."
+	// Format: "Jan 02 15:04  group  category  Pattern  command..."
 	timestamp := i.command.Timestamp.Format("Jan 02 15:04")
 	pattern := i.command.Pattern
 
-	// Get group name from config
-	group := config.Global().GetToolGroup(pattern)
+	// Get group name and category from config
+	group := toolGroupFor(i.cfg, pattern)
 	groupName := ""
 	if group != nil {
 		groupName = group.Name
 	}
+	category := padRight(categoryFor(i.cfg, pattern), CommandCategoryWidth)
 
 	// Pad/truncate group to fixed width
 	if len(groupName) > CommandGroupWidth {
-		groupName = groupName[:CommandGroupWidth-1] + "…"
+		groupName = truncateWithEllipsis(groupName, CommandGroupWidth)
 	} else {
 		groupName += strings.Repeat(" ", CommandGroupWidth-len(groupName))
 	}
 
 	// Pad/truncate pattern to fixed width
 	if len(pattern) > CommandPatternWidth {
-		pattern = pattern[:CommandPatternWidth-1] + "…"
+		pattern = truncateWithEllipsis(pattern, CommandPatternWidth)
 	} else {
 		pattern += strings.Repeat(" ", CommandPatternWidth-len(pattern))
 	}
 
 	// Calculate space for raw command
-	// Format: "timestamp  group  pattern  command"
-	fixedWidth := CommandTimestampWidth + 2 + CommandGroupWidth + 2 + CommandPatternWidth + 2
+	// Format: "timestamp  group  category  pattern  command"
+	fixedWidth := CommandTimestampWidth + 2 + CommandGroupWidth + 2 + CommandCategoryWidth + 2 + CommandPatternWidth + 2
 	commandWidth := d.width - fixedWidth
 	if commandWidth < 10 {
 		commandWidth = 10
 	}
 
-	// Replace newlines with visible marker to keep single-line display
-	rawCmd := strings.ReplaceAll(i.command.RawCommand, "\n", "↵")
+	rawCmd := jobBadge(&i.command) + hookBadge(&i.command) + singleLine(i.command.RawCommand)
 	if len(rawCmd) > commandWidth {
-		rawCmd = rawCmd[:commandWidth-1] + "…"
+		rawCmd = truncateWithEllipsis(rawCmd, commandWidth)
+	}
+
+	// Writes outside the project are marked and shown in the danger color
+	marker := "  "
+	baseStyle := d.theme.styleForGroup(group)
+	if len(i.outside) > 0 {
+		marker = " !"
+		baseStyle = d.theme.DangerStyle().Bold(true)
 	}
 
-	row := fmt.Sprintf("%s  %s  %s  %s", timestamp, groupName, pattern, rawCmd)
+	head := fmt.Sprintf("%s%s%s  %s  %s  ", timestamp, marker, groupName, category, pattern)
+	row := head + rawCmd
 
 	// Pad to full width
 	if len(row) < d.width {
@@ -205,20 +290,80 @@ func (d *commandDelegate) Render(w io.Writer, m list.Model, index int, item list
 
 	// Apply styling based on selection and tool type
 	var style lipgloss.Style
-	baseStyle := StyleForPattern(i.command.Pattern)
 
 	if index == m.Index() {
 		style = baseStyle.
-			Background(GetTheme().Surface).
+			Background(d.theme.Surface).
 			Bold(true).
 			Width(d.width)
 	} else {
 		style = baseStyle.Width(d.width)
 	}
 
+	if d.highlight != "" {
+		style = style.UnsetWidth()
+		fmt.Fprint(w, style.Render(head)+highlightMatches(d.theme, row[len(head):], d.highlight, style))
+		return
+	}
 	fmt.Fprint(w, style.Render(row))
 }
 
+// markerItem is a divider in the command list where the session's context
+// was compacted or its conversation restarted
+type markerItem struct {
+	marker session.Marker
+}
+
+func (i markerItem) FilterValue() string { return "" }
+func (i markerItem) Title() string       { return i.marker.Label() }
+func (i markerItem) Description() string { return "" }
+
+// renderMarker renders a marker as a full-width rule: "── context compacted (auto, 156k tokens) · Jan 02 15:04 ──────"
+func (d *commandDelegate) renderMarker(w io.Writer, i markerItem, selected bool) {
+	label := "── " + i.marker.Label()
+	if !i.marker.Timestamp.IsZero() {
+		label += " · " + i.marker.Timestamp.Format("Jan 02 15:04")
+	}
+	label += " "
+	if fill := d.width - lipgloss.Width(label); fill > 0 {
+		label += strings.Repeat("─", fill)
+	}
+
+	style := d.theme.MarkerStyle()
+	if selected {
+		style = style.Background(d.theme.Surface)
+	}
+	fmt.Fprint(w, style.Width(d.width).MaxWidth(d.width).Render(label))
+}
+
+// jobBadge marks a background command with the BashOutput polls and
+// KillShell folded into it, e.g. "[bg 3 polls] "
+func jobBadge(c *session.CommandEntry) string {
+	if !c.Background {
+		return ""
+	}
+	badge := "bg"
+	if c.Polls > 0 {
+		badge += fmt.Sprintf(" %d poll", c.Polls)
+		if c.Polls > 1 {
+			badge += "s"
+		}
+	}
+	if c.Killed {
+		badge += ", killed"
+	}
+	return "[" + badge + "] "
+}
+
+// hookBadge marks a command that a hook blocked, modified, or failed on,
+// e.g. "[hook blocked] "; hooks that only ran are left to the detail panel
+func hookBadge(c *session.CommandEntry) string {
+	if outcome := c.HookOutcome(); outcome != "" {
+		return "[hook " + outcome + "] "
+	}
+	return ""
+}
+
 // ============================================================================
 // Pattern Item
 // ============================================================================
@@ -226,6 +371,7 @@ func (d *commandDelegate) Render(w io.Writer, m list.Model, index int, item list
 // patternItem wraps a CommandPattern for the list component
 type patternItem struct {
 	pattern *session.CommandPattern
+	cfg     *config.Config // Project-aware config (nil means global)
 }
 
 func (i patternItem) FilterValue() string { return i.pattern.Pattern }
@@ -236,18 +382,20 @@ func (i patternItem) Description() string {
 
 // patternDelegate renders pattern items
 type patternDelegate struct {
+	theme *Theme
 	width int
 }
 
 // Column widths for pattern list (exported for header rendering)
 const (
-	PatternPatternWidth = 25
-	PatternGroupWidth   = 12
-	PatternCountWidth   = 8
+	PatternPatternWidth  = 25
+	PatternGroupWidth    = 12
+	PatternCategoryWidth = 8
+	PatternCountWidth    = 8
 )
 
-func newPatternDelegate() *patternDelegate {
-	return &patternDelegate{width: 80}
+func newPatternDelegate(theme *Theme) *patternDelegate {
+	return &patternDelegate{width: 80, theme: theme}
 }
 
 func (d *patternDelegate) SetWidth(w int) {
@@ -263,27 +411,28 @@ func (d *patternDelegate) Render(w io.Writer, m list.Model, index int, item list
 		return
 	}
 
-	// Format: "Pattern  Group  [count]  example..."
+	// Format: "Pattern  Group  Category  [count]  example..."
 	pattern := i.pattern.Pattern
 	countStr := fmt.Sprintf("[%d]", i.pattern.Count)
 
-	// Get group name from config
-	group := config.Global().GetToolGroup(pattern)
+	// Get group name and category from config
+	group := toolGroupFor(i.cfg, pattern)
 	groupName := ""
 	if group != nil {
 		groupName = group.Name
 	}
+	category := padRight(categoryFor(i.cfg, pattern), PatternCategoryWidth)
 
 	// Pad/truncate pattern
 	if len(pattern) > PatternPatternWidth {
-		pattern = pattern[:PatternPatternWidth-1] + "…"
+		pattern = truncateWithEllipsis(pattern, PatternPatternWidth)
 	} else {
 		pattern += strings.Repeat(" ", PatternPatternWidth-len(pattern))
 	}
 
 	// Pad/truncate group to fixed width
 	if len(groupName) > PatternGroupWidth {
-		groupName = groupName[:PatternGroupWidth-1] + "…"
+		groupName = truncateWithEllipsis(groupName, PatternGroupWidth)
 	} else {
 		groupName += strings.Repeat(" ", PatternGroupWidth-len(groupName))
 	}
@@ -292,7 +441,7 @@ func (d *patternDelegate) Render(w io.Writer, m list.Model, index int, item list
 	countStr = strings.Repeat(" ", PatternCountWidth-len(countStr)) + countStr
 
 	// Calculate space for example
-	fixedWidth := PatternPatternWidth + 2 + PatternGroupWidth + 2 + PatternCountWidth + 2
+	fixedWidth := PatternPatternWidth + 2 + PatternGroupWidth + 2 + PatternCategoryWidth + 2 + PatternCountWidth + 2
 	exampleWidth := d.width - fixedWidth
 	if exampleWidth < 10 {
 		exampleWidth = 10
@@ -300,14 +449,13 @@ func (d *patternDelegate) Render(w io.Writer, m list.Model, index int, item list
 
 	example := ""
 	if len(i.pattern.Examples) > 0 {
-		// Replace newlines with visible marker to keep single-line display
-		example = strings.ReplaceAll(i.pattern.Examples[0], "\n", "↵")
+		example = singleLine(i.pattern.Examples[0])
 		if len(example) > exampleWidth {
-			example = example[:exampleWidth-1] + "…"
+			example = truncateWithEllipsis(example, exampleWidth)
 		}
 	}
 
-	row := fmt.Sprintf("%s  %s  %s  %s", pattern, groupName, countStr, example)
+	row := fmt.Sprintf("%s  %s  %s  %s  %s", pattern, groupName, category, countStr, example)
 
 	// Pad to full width
 	if len(row) < d.width {
@@ -316,11 +464,11 @@ func (d *patternDelegate) Render(w io.Writer, m list.Model, index int, item list
 
 	// Apply styling
 	var style lipgloss.Style
-	baseStyle := StyleForPattern(i.pattern.Pattern)
+	baseStyle := d.theme.styleForGroup(group)
 
 	if index == m.Index() {
 		style = baseStyle.
-			Background(GetTheme().Surface).
+			Background(d.theme.Surface).
 			Bold(true).
 			Width(d.width)
 	} else {
@@ -358,6 +506,6 @@ func formatTimeAgo(t time.Time) string {
 }
 
 // MutedStyle returns a style for description text
-func MutedStyle() lipgloss.Style {
-	return lipgloss.NewStyle().Foreground(GetTheme().Muted)
+func (t *Theme) MutedStyle() lipgloss.Style {
+	return t.NewStyle().Foreground(t.Muted)
 }
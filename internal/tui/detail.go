@@ -2,70 +2,193 @@ package tui
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"strings"
 
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
 	"cc_session_mon/internal/session"
-
-	"github.com/charmbracelet/lipgloss"
 )
 
-// renderDetailPanel renders the command detail side panel
+// renderDetailPanel renders the command detail side panel, scrolled to
+// detailScroll. The header is highlighted while the panel has focus.
 func (m Model) renderDetailPanel(width, height int) string {
-	var b strings.Builder
+	title := "Command Details"
+	headerStyle := m.theme.DetailHeaderStyle(width)
+	if m.detailFocused {
+		headerStyle = m.theme.DetailHeaderFocusedStyle(width)
+	}
 
-	// Panel header
-	header := DetailHeaderStyle(width).Render("Command Details")
-	b.WriteString(header)
-	b.WriteString("\n")
+	lines := strings.Split(m.renderDetailBody(width), "\n")
+	visible := height - 1 // Below the header
+	if visible < 1 {
+		visible = 1
+	}
+	offset := min(m.detailScroll, max(len(lines)-visible, 0))
+	if len(lines) > visible {
+		title += fmt.Sprintf(" (%d-%d/%d)", offset+1, min(offset+visible, len(lines)), len(lines))
+	}
+	lines = lines[offset:min(offset+visible, len(lines))]
+
+	body := headerStyle.Render(title) + "\n" + strings.Join(lines, "\n")
+	return m.theme.NewStyle().Width(width).Height(height).Render(body)
+}
 
+// maxDetailScroll returns the furthest the detail panel can scroll at the
+// current terminal size
+func (m Model) maxDetailScroll() int {
+	_, detailWidth, contentHeight := m.splitLayout()
+	lines := strings.Count(m.renderDetailBody(detailWidth), "\n") + 1
+	return max(lines-contentHeight, 0) // contentHeight+1 rows, less the header
+}
+
+// renderDetailBody renders the detail panel's content below its header
+func (m Model) renderDetailBody(width int) string {
 	if m.loadingDetail {
-		b.WriteString(MutedStyle().Render("Loading..."))
-		return lipgloss.NewStyle().Width(width).Height(height).Render(b.String())
+		return m.theme.MutedStyle().Render("Loading...")
 	}
 
 	if m.detailError != nil {
-		b.WriteString(ErrorStyle().Render(fmt.Sprintf("Error: %v", m.detailError)))
-		return lipgloss.NewStyle().Width(width).Height(height).Render(b.String())
+		return m.theme.ErrorStyle().Render(fmt.Sprintf("Error: %v", m.detailError))
 	}
 
 	if m.loadedInput == nil || m.selectedCommand == nil {
-		b.WriteString(MutedStyle().Render("Select a command and press Enter"))
-		return lipgloss.NewStyle().Width(width).Height(height).Render(b.String())
+		return m.theme.MutedStyle().Render("Select a command and press Enter")
+	}
+
+	var b strings.Builder
+
+	// Tool-specific formatting, with the active project's security rules
+	dc := detailContext{cfg: config.Global(), expandHeredocs: m.heredocsExpanded, scriptRuns: m.scriptRuns}
+	if m.searchActive {
+		dc.highlight = m.searchInput.Value()
+	}
+	if sess := m.ActiveSession(); sess != nil {
+		dc.cfg = config.ForProject(sess.ProjectPath)
+		dc.projectPath = sess.ProjectPath
+	}
+
+	// Flag commands issued after the agent wandered away from the project root
+	if drift := security.CWDDrift(dc.projectPath, m.loadedInput.CWD); drift != "" {
+		b.WriteString(m.theme.WarningHeaderStyle().Render("* " + drift))
+		b.WriteString("\n\n")
+	}
+	b.WriteString(formatThinking(m.theme, m.loadedInput.Thinking, width-2, m.thinkingShown))
+	b.WriteString(formatHooks(m.theme, m.selectedCommand.Hooks, width-2))
+	b.WriteString(formatToolInput(m.theme, m.selectedCommand.ToolName, m.loadedInput, width-2, dc))
+	if m.resultPending() {
+		// Reloaded until the result lands; see detailPollCmd
+		label := "Output:"
+		if m.loadedInput.HasResult {
+			label = "Background job:" // Started, its shell still running
+		}
+		b.WriteString("\n" + m.theme.LabelStyle().Render(label) + " " + m.detailSpinner.View() + m.theme.MutedStyle().Render(" running..."))
+	}
+	for _, path := range m.attachmentPaths {
+		b.WriteString("\n" + m.theme.MutedStyle().Render("Saved to "+path))
+	}
+	return b.String()
+}
+
+// formatHooks renders the hooks that fired for a tool call, colored by
+// whether they blocked it, changed it, or failed
+func formatHooks(theme *Theme, hooks []session.HookRun, width int) string {
+	if len(hooks) == 0 {
+		return ""
+	}
+	truncate := theme.NewStyle().Inline(true).MaxWidth(width)
+	var b strings.Builder
+	b.WriteString(theme.LabelStyle().Render("Hooks:"))
+	b.WriteString("\n")
+	for _, h := range hooks {
+		style := theme.MutedStyle()
+		switch h.Outcome {
+		case session.HookBlocked:
+			style = theme.DangerStyle().Bold(true)
+		case session.HookModified, session.HookFailed, session.HookCancelled:
+			style = theme.WarningStyle()
+		}
+		b.WriteString(truncate.Render(style.Render(h.Label())))
+		b.WriteString("\n")
+	}
+	return b.String() + "\n"
+}
+
+// formatThinking renders the thinking blocks written before a tool call:
+// their count and size, or with shown, the reasoning itself
+func formatThinking(theme *Theme, blocks []string, width int, shown bool) string {
+	if len(blocks) == 0 {
+		return ""
+	}
+	stats := session.ThinkingStats{Blocks: len(blocks)}
+	for _, block := range blocks {
+		stats.Chars += len([]rune(block))
 	}
 
-	// Tool-specific formatting
-	content := formatToolInput(m.selectedCommand.ToolName, m.loadedInput, width-2)
-	b.WriteString(content)
+	var b strings.Builder
+	b.WriteString(theme.LabelStyle().Render("Thinking: "))
+	b.WriteString(theme.MutedStyle().Render(stats.String()))
+	if !shown {
+		b.WriteString(theme.MutedStyle().Render(" (t:show)"))
+		return b.String() + "\n\n"
+	}
+	b.WriteString("\n")
+	for _, block := range blocks {
+		b.WriteString(renderLines(theme.SyntaxCommentStyle(), wrapText(strings.TrimSpace(block), width-2)))
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+// detailContext carries settings that affect how tool details are rendered
+type detailContext struct {
+	cfg            *config.Config       // Project-aware config for security rules
+	expandHeredocs bool                 // Show heredoc bodies instead of a collapsed summary
+	scriptRuns     []security.ScriptRun // Session-written scripts the Bash command runs
+	projectPath    string               // Active session's project, for outside-project writes
+	highlight      string               // Active search query, highlighted in the command or path
+}
 
-	return lipgloss.NewStyle().Width(width).Height(height).Render(b.String())
+// outsideWrites returns the paths a tool call writes outside the session's project
+func (dc detailContext) outsideWrites(toolName, raw, cwd string) []string {
+	return security.OutsideProjectWrites(toolName, raw, cwd, dc.projectPath, dc.cfg.Security.AllowedWritePaths)
 }
 
 // formatToolInput dispatches to tool-specific formatters
-func formatToolInput(toolName string, input *session.ToolInput, width int) string {
+func formatToolInput(theme *Theme, toolName string, input *session.ToolInput, width int, dc detailContext) string {
 	switch toolName {
 	case "Bash":
-		return formatBashDetail(input, width)
+		return formatBashDetail(theme, input, width, dc)
 	case "Edit":
-		return formatEditDetail(input, width)
+		return formatEditDetail(theme, input, width, dc)
 	case "Write":
-		return formatWriteDetail(input, width)
+		return formatWriteDetail(theme, input, width, dc)
+	case "NotebookEdit":
+		return formatNotebookEditDetail(theme, input, width, dc)
 	case "Read":
-		return formatReadDetail(input, width)
+		return formatReadDetail(theme, input, width, dc)
 	case "Glob":
-		return formatGlobDetail(input, width)
+		return formatGlobDetail(theme, input, width)
 	case "Grep":
-		return formatGrepDetail(input, width)
+		return formatGrepDetail(theme, input, width)
 	case "Task":
-		return formatTaskDetail(input, width)
+		return formatTaskDetail(theme, input, width)
+	case "Skill":
+		return formatSkillDetail(theme, input, width)
+	case "AskUserQuestion":
+		return formatAskUserQuestionDetail(theme, input, width)
+	case "ExitPlanMode":
+		return formatExitPlanModeDetail(theme, input, width)
 	case "WebFetch", "WebSearch":
-		return formatWebDetail(input, width)
+		return formatWebDetail(theme, input, width)
 	default:
-		return formatGenericDetail(input, width)
+		return formatGenericDetail(theme, input, width)
 	}
 }
 
 // formatBashDetail renders Bash command details with security warnings
-func formatBashDetail(input *session.ToolInput, width int) string {
+func formatBashDetail(theme *Theme, input *session.ToolInput, width int, dc detailContext) string {
 	var b strings.Builder
 
 	command := getString(input.Parsed, "command")
@@ -73,154 +196,187 @@ func formatBashDetail(input *session.ToolInput, width int) string {
 	timeout := getFloat(input.Parsed, "timeout")
 	runInBg := getBool(input.Parsed, "run_in_background")
 
-	// Security analysis
-	warnings := analyzeBashSecurity(command)
+	script, heredocs := session.SplitHeredocs(command)
+	interp, isInterp := session.ParseInterpreter(command)
+	code := security.InlineCode(command)
+
+	// Security analysis: built-in checks, configured warn patterns, and inline code
+	warnings := security.AnalyzeBash(command)
+	warnings = append(warnings, dc.cfg.SecurityWarnings(session.ExtractPattern("Bash", command))...)
+	if code != "" {
+		warnings = append(warnings, security.AnalyzeCode(code)...)
+	}
+	for _, path := range dc.outsideWrites("Bash", command, input.CWD) {
+		warnings = append(warnings, "Writes outside the project: "+path)
+	}
+	for _, e := range security.SecretExposures("Bash", command) {
+		warnings = append(warnings, fmt.Sprintf("Secret %s: %s", e.Action, e.Secret))
+	}
+	for _, w := range security.ConfigTampering("Bash", command) {
+		warnings = append(warnings, fmt.Sprintf("Changes the agent's %s: %s", w.Kind, w.Path))
+	}
+	for _, run := range dc.scriptRuns {
+		if len(run.Warnings) > 0 {
+			warnings = append(warnings, fmt.Sprintf("Runs %s, written earlier by this session, which contains: %s",
+				filepath.Base(run.Path), strings.ToLower(strings.Join(run.Warnings, ", "))))
+		}
+	}
 	if len(warnings) > 0 {
-		b.WriteString(DangerHeaderStyle().Render("! Security Warnings"))
+		b.WriteString(theme.DangerHeaderStyle().Render("! Security Warnings"))
 		b.WriteString("\n")
 		for _, w := range warnings {
-			b.WriteString(DangerStyle().Render("  - " + w))
+			b.WriteString(theme.DangerStyle().Render("  - " + w))
 			b.WriteString("\n")
 		}
 		b.WriteString("\n")
 	}
 
-	// Command field
-	b.WriteString(LabelStyle().Render("Command:"))
+	// Network egress gets its own group so contacted endpoints are easy to audit
+	b.WriteString(formatNetworkAccess(theme, security.AnalyzeNetwork(command)))
+
+	// Inline interpreter code is shown first, it's what actually runs
+	if isInterp && interp.Code != "" {
+		b.WriteString(theme.LabelStyle().Render(fmt.Sprintf("Inline %s code:", interp.Name)))
+		b.WriteString("\n")
+		b.WriteString(theme.CodeBlockStyle(width).Render(truncateMultiline(interp.Code, width-4, maxInlineCodeLines)))
+		b.WriteString("\n\n")
+	}
+
+	// Scripts this session wrote and this command runs
+	for _, run := range dc.scriptRuns {
+		b.WriteString(theme.WarningStyle().Render(fmt.Sprintf("* Runs script written by this session at %s",
+			run.WrittenAt.Local().Format("15:04:05"))))
+		b.WriteString("\n")
+		b.WriteString(theme.PathStyle().Render("  " + run.Path))
+		b.WriteString("\n\n")
+	}
+
+	// Command field, with heredoc bodies split out into their own blocks
+	b.WriteString(theme.LabelStyle().Render("Command:"))
 	b.WriteString("\n")
-	b.WriteString(CodeBlockStyle(width).Render(wrapText(command, width-4)))
+	b.WriteString(theme.CodeBlockStyle(width).Render(highlightMatches(theme, wrapText(script, width-4), dc.highlight, theme.CodeTextStyle())))
 	b.WriteString("\n\n")
+	b.WriteString(formatHeredocs(theme, heredocs, width, dc.expandHeredocs))
 
 	// Description if present
 	if description != "" {
-		b.WriteString(LabelStyle().Render("Description:"))
+		b.WriteString(theme.LabelStyle().Render("Description:"))
 		b.WriteString("\n")
-		b.WriteString(MutedStyle().Render(wrapText(description, width-2)))
+		b.WriteString(theme.MutedStyle().Render(wrapText(description, width-2)))
 		b.WriteString("\n\n")
 	}
 
 	// Metadata
 	if timeout > 0 {
-		b.WriteString(LabelStyle().Render("Timeout: "))
+		b.WriteString(theme.LabelStyle().Render("Timeout: "))
 		fmt.Fprintf(&b, "%.0fms", timeout)
 		b.WriteString("\n")
 	}
 
 	if runInBg {
-		b.WriteString(WarningStyle().Render("* Runs in background"))
+		b.WriteString(theme.WarningStyle().Render("* Runs in background"))
 		b.WriteString("\n")
 	}
 
 	// Context info
 	if input.CWD != "" {
 		b.WriteString("\n")
-		b.WriteString(MutedStyle().Render("CWD: " + input.CWD))
+		b.WriteString(theme.MutedStyle().Render("CWD: " + input.CWD))
 		b.WriteString("\n")
 	}
 
-	// Tool result/output
-	b.WriteString(formatResultSection(input, width))
+	// Tool result/output, then what the background shell reported since
+	b.WriteString(formatResultSection(theme, input, width))
+	b.WriteString(formatJobSection(theme, input.Job, width))
 
 	return b.String()
 }
 
-// securityCheck defines a check function and its warning message
-type securityCheck struct {
-	check   func(cmd string) bool
-	warning string
-}
-
-// securityChecks contains all bash security checks
-var securityChecks = []securityCheck{
-	{checkRecursiveRm, "Recursive file deletion"},
-	{checkSimpleRm, "File deletion"},
-	{checkSudo, "Runs with elevated privileges"},
-	{checkChmod, "Changes file permissions"},
-	{checkChown, "Changes file ownership"},
-	{checkCurlPipeShell, "Downloads and pipes to shell"},
-	{checkDd, "Direct disk/device operation"},
-	{checkMkfs, "Filesystem creation"},
-	{checkKill, "Process termination"},
-	{checkGitForcePush, "Force push to remote"},
-	{checkGitHardReset, "Hard reset (discards changes)"},
-}
-
-// analyzeBashSecurity returns security warnings for a bash command
-func analyzeBashSecurity(command string) []string {
-	var warnings []string
-	cmd := strings.ToLower(command)
-
-	for _, sc := range securityChecks {
-		if sc.check(cmd) {
-			warnings = append(warnings, sc.warning)
-		}
+// formatJobSection renders a background command's status and the output
+// accumulated across the BashOutput calls on its shell
+func formatJobSection(theme *Theme, job *session.BackgroundJob, width int) string {
+	if job == nil {
+		return ""
 	}
-	return warnings
-}
-
-// hasCommand checks if cmd contains "name " or starts with "name\t"
-func hasCommand(cmd, name string) bool {
-	return strings.Contains(cmd, name+" ") || strings.HasPrefix(cmd, name+"\t")
-}
 
-func checkRecursiveRm(cmd string) bool {
-	if !hasCommand(cmd, "rm") && !strings.HasPrefix(cmd, "rm\n") {
-		return false
+	var b strings.Builder
+	b.WriteString("\n")
+	status := job.Status
+	switch job.Status {
+	case session.JobCompleted, session.JobFailed:
+		status += fmt.Sprintf(" (exit %d)", job.ExitCode)
 	}
-	return strings.Contains(cmd, "-rf") || strings.Contains(cmd, "-r ") || strings.Contains(cmd, " -fr")
-}
-
-func checkSimpleRm(cmd string) bool {
-	if !hasCommand(cmd, "rm") && !strings.HasPrefix(cmd, "rm\n") {
-		return false
+	label := theme.LabelStyle().Render(fmt.Sprintf("Background job %s:", job.ShellID))
+	switch job.Status {
+	case session.JobFailed:
+		status = theme.DangerStyle().Render(status)
+	case session.JobKilled:
+		status = theme.WarningStyle().Render(status)
 	}
-	// Only flag if not already caught by recursive check
-	return !checkRecursiveRm(cmd)
-}
-
-func checkSudo(cmd string) bool {
-	return strings.Contains(cmd, "sudo ") || strings.HasPrefix(cmd, "sudo\t")
-}
-
-func checkChmod(cmd string) bool {
-	return strings.Contains(cmd, "chmod ")
-}
-
-func checkChown(cmd string) bool {
-	return strings.Contains(cmd, "chown ")
-}
-
-func checkCurlPipeShell(cmd string) bool {
-	if !strings.Contains(cmd, "|") {
-		return false
+	polls := fmt.Sprintf("%d polls", job.Polls)
+	if job.Polls == 1 {
+		polls = "1 poll"
 	}
-	hasCurl := strings.Contains(cmd, "curl") || strings.Contains(cmd, "wget")
-	hasShell := strings.Contains(cmd, "bash") || strings.Contains(cmd, "sh")
-	return hasCurl && hasShell
-}
+	fmt.Fprintf(&b, "%s %s, %s\n", label, status, polls)
 
-func checkDd(cmd string) bool {
-	return hasCommand(cmd, "dd")
+	if job.Output != "" {
+		b.WriteString(theme.CodeBlockStyle(width).Render(tailLines(theme, job.Output, width-4, resultMaxLines)))
+		b.WriteString("\n")
+	}
+	return b.String()
 }
 
-func checkMkfs(cmd string) bool {
-	return strings.Contains(cmd, "mkfs")
+// formatNetworkAccess renders the network-reaching commands and their destination hosts
+func formatNetworkAccess(theme *Theme, accesses []security.NetworkAccess) string {
+	if len(accesses) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(theme.WarningHeaderStyle().Render("~ Network Access"))
+	b.WriteString("\n")
+	for _, a := range accesses {
+		b.WriteString(theme.WarningStyle().Render("  - " + a.Command + " → "))
+		if a.Host != "" {
+			b.WriteString(theme.PathStyle().Render(a.Host))
+		} else {
+			b.WriteString(theme.MutedStyle().Render("unknown host"))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
 }
 
-func checkKill(cmd string) bool {
-	return strings.Contains(cmd, "kill ") || strings.Contains(cmd, "pkill ") || strings.Contains(cmd, "killall ")
+// formatHeredocs renders heredoc bodies as blocks, or one summary line each when collapsed
+func formatHeredocs(theme *Theme, heredocs []session.Heredoc, width int, expanded bool) string {
+	var b strings.Builder
+	for _, doc := range heredocs {
+		if !expanded {
+			b.WriteString(theme.LabelStyle().Render(fmt.Sprintf("▸ Heredoc %s", doc.Delimiter)))
+			b.WriteString(theme.MutedStyle().Render(fmt.Sprintf(" (%d lines, x:expand)", doc.Lines)))
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(theme.LabelStyle().Render(fmt.Sprintf("▾ Heredoc %s", doc.Delimiter)))
+		b.WriteString(theme.MutedStyle().Render(" (x:collapse)"))
+		b.WriteString("\n")
+		b.WriteString(theme.CodeBlockStyle(width).Render(truncateMultiline(doc.Body, width-4, maxHeredocLines)))
+		b.WriteString("\n")
+	}
+	if len(heredocs) > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
 }
 
-func checkGitForcePush(cmd string) bool {
-	return strings.Contains(cmd, "git push") && strings.Contains(cmd, "--force")
-}
+// maxHeredocLines caps an expanded heredoc block
+const maxHeredocLines = 40
 
-func checkGitHardReset(cmd string) bool {
-	return strings.Contains(cmd, "git reset --hard")
-}
+// maxInlineCodeLines caps the inline interpreter code block
+const maxInlineCodeLines = 20
 
 // formatEditDetail renders Edit tool details
-func formatEditDetail(input *session.ToolInput, width int) string {
+func formatEditDetail(theme *Theme, input *session.ToolInput, width int, dc detailContext) string {
 	var b strings.Builder
 
 	filePath := getString(input.Parsed, "file_path")
@@ -228,76 +384,192 @@ func formatEditDetail(input *session.ToolInput, width int) string {
 	newString := getString(input.Parsed, "new_string")
 	replaceAll := getBool(input.Parsed, "replace_all")
 
+	if len(dc.outsideWrites("Edit", filePath, input.CWD)) > 0 {
+		b.WriteString(theme.DangerHeaderStyle().Render("! Editing outside the project"))
+		b.WriteString("\n\n")
+	}
+	if len(security.SecretExposures("Edit", filePath)) > 0 {
+		b.WriteString(theme.DangerHeaderStyle().Render("! Editing a secrets (.env) file"))
+		b.WriteString("\n\n")
+	}
+	for _, w := range security.ConfigTampering("Edit", filePath) {
+		b.WriteString(theme.DangerHeaderStyle().Render("! Editing the agent's own " + w.Kind))
+		b.WriteString("\n\n")
+	}
+
 	// File path with security check
-	b.WriteString(LabelStyle().Render("File:"))
+	b.WriteString(theme.LabelStyle().Render("File:"))
 	b.WriteString("\n")
-	if isSensitivePath(filePath) {
-		b.WriteString(DangerStyle().Render("! " + filePath))
+	if security.IsSensitivePath(filePath, dc.cfg.Security.SensitivePaths) {
+		b.WriteString(theme.DangerStyle().Render("! ") + highlightMatches(theme, filePath, dc.highlight, theme.DangerStyle()))
 	} else {
-		b.WriteString(PathStyle().Render(filePath))
+		b.WriteString(highlightMatches(theme, filePath, dc.highlight, theme.PathStyle()))
 	}
 	b.WriteString("\n\n")
 
 	// Show diff-like view
-	b.WriteString(LabelStyle().Render("Change:"))
+	b.WriteString(theme.LabelStyle().Render("Change:"))
 	b.WriteString("\n")
 
 	// Old string (red/deletion style)
 	if oldString != "" {
-		b.WriteString(DeletionStyle().Render("- " + truncateMultiline(oldString, width-4, 5)))
+		b.WriteString(theme.DeletionStyle().Render("- " + truncateMultiline(oldString, width-4, 5)))
 		b.WriteString("\n")
 	}
 
 	// New string (green/addition style)
 	if newString != "" {
-		b.WriteString(AdditionStyle().Render("+ " + truncateMultiline(newString, width-4, 5)))
+		b.WriteString(theme.AdditionStyle().Render("+ " + truncateMultiline(newString, width-4, 5)))
 		b.WriteString("\n")
 	}
 
 	if replaceAll {
 		b.WriteString("\n")
-		b.WriteString(WarningStyle().Render("* Replaces ALL occurrences"))
+		b.WriteString(theme.WarningStyle().Render("* Replaces ALL occurrences"))
 		b.WriteString("\n")
 	}
 
 	// Tool result/output
-	b.WriteString(formatResultSection(input, width))
+	b.WriteString(formatResultSection(theme, input, width))
 
 	return b.String()
 }
 
 // formatWriteDetail renders Write tool details
-func formatWriteDetail(input *session.ToolInput, width int) string {
+func formatWriteDetail(theme *Theme, input *session.ToolInput, width int, dc detailContext) string {
 	var b strings.Builder
 
 	filePath := getString(input.Parsed, "file_path")
 	content := getString(input.Parsed, "content")
 
 	// Security warnings
-	if isSensitivePath(filePath) {
-		b.WriteString(DangerHeaderStyle().Render("! Writing to sensitive path"))
+	if security.IsSensitivePath(filePath, dc.cfg.Security.SensitivePaths) {
+		b.WriteString(theme.DangerHeaderStyle().Render("! Writing to sensitive path"))
+		b.WriteString("\n\n")
+	}
+	if len(dc.outsideWrites("Write", filePath, input.CWD)) > 0 {
+		b.WriteString(theme.DangerHeaderStyle().Render("! Writing outside the project"))
+		b.WriteString("\n\n")
+	}
+	if len(security.SecretExposures("Write", filePath)) > 0 {
+		b.WriteString(theme.DangerHeaderStyle().Render("! Writing a secrets (.env) file"))
+		b.WriteString("\n\n")
+	}
+	for _, w := range security.ConfigTampering("Write", filePath) {
+		b.WriteString(theme.DangerHeaderStyle().Render("! Writing the agent's own " + w.Kind))
 		b.WriteString("\n\n")
 	}
+	if security.IsScript(filePath, content) {
+		if warnings := security.AnalyzeScript(filePath, content); len(warnings) > 0 {
+			b.WriteString(theme.DangerHeaderStyle().Render("! Script contains"))
+			b.WriteString("\n")
+			for _, w := range warnings {
+				b.WriteString(theme.DangerStyle().Render("  - " + w))
+				b.WriteString("\n")
+			}
+			b.WriteString("\n")
+		}
+	}
 
-	b.WriteString(LabelStyle().Render("File:"))
+	b.WriteString(theme.LabelStyle().Render("File:"))
 	b.WriteString("\n")
-	b.WriteString(PathStyle().Render(filePath))
+	b.WriteString(highlightMatches(theme, filePath, dc.highlight, theme.PathStyle()))
 	b.WriteString("\n\n")
 
-	b.WriteString(LabelStyle().Render("Content:"))
+	b.WriteString(theme.LabelStyle().Render("Content:"))
 	fmt.Fprintf(&b, " (%d bytes)", len(content))
 	b.WriteString("\n")
-	b.WriteString(CodeBlockStyle(width).Render(truncateMultiline(content, width-4, 10)))
+	b.WriteString(theme.CodeBlockStyle(width).Render(truncateMultiline(content, width-4, 10)))
+	b.WriteString("\n")
+
+	// Tool result/output
+	b.WriteString(formatResultSection(theme, input, width))
+
+	return b.String()
+}
+
+// formatNotebookEditDetail renders NotebookEdit details: the notebook, the
+// cell and how it changes, and the new source, highlighted for code cells
+func formatNotebookEditDetail(theme *Theme, input *session.ToolInput, width int, dc detailContext) string {
+	var b strings.Builder
+
+	notebookPath := getString(input.Parsed, "notebook_path")
+	cellID := getString(input.Parsed, "cell_id")
+	cellNumber, hasNumber := input.Parsed["cell_number"].(float64)
+	cellType := getString(input.Parsed, "cell_type")
+	editMode := getString(input.Parsed, "edit_mode")
+	source := getString(input.Parsed, "new_source")
+	if editMode == "" {
+		editMode = "replace"
+	}
+
+	if len(dc.outsideWrites("NotebookEdit", notebookPath, input.CWD)) > 0 {
+		b.WriteString(theme.DangerHeaderStyle().Render("! Editing outside the project"))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(theme.LabelStyle().Render("Notebook:"))
+	b.WriteString("\n")
+	if security.IsSensitivePath(notebookPath, dc.cfg.Security.SensitivePaths) {
+		b.WriteString(theme.DangerStyle().Render("! ") + highlightMatches(theme, notebookPath, dc.highlight, theme.DangerStyle()))
+	} else {
+		b.WriteString(highlightMatches(theme, notebookPath, dc.highlight, theme.PathStyle()))
+	}
+	b.WriteString("\n\n")
+
+	// Which cell, and what happens to it
+	var cell string
+	switch {
+	case cellID != "":
+		cell = "cell " + cellID
+	case hasNumber:
+		cell = fmt.Sprintf("cell #%d", int(cellNumber))
+	}
+	if cell != "" {
+		b.WriteString(theme.LabelStyle().Render("Cell:"))
+		b.WriteString(" " + cell)
+		if cellType != "" && editMode != "insert" {
+			b.WriteString(" (" + cellType + ")")
+		}
+		b.WriteString("\n")
+	}
+	switch editMode {
+	case "delete":
+		b.WriteString(theme.WarningStyle().Render("* Deletes the cell"))
+	case "insert":
+		inserted := strings.TrimSpace("Inserts a new " + cellType + " cell")
+		if cell != "" {
+			b.WriteString(theme.MutedStyle().Render(inserted + " after " + cell))
+		} else {
+			b.WriteString(theme.MutedStyle().Render(inserted + " at the start"))
+		}
+	default:
+		b.WriteString(theme.MutedStyle().Render("Replaces the cell's source"))
+	}
 	b.WriteString("\n")
 
+	if editMode != "delete" && source != "" {
+		b.WriteString("\n")
+		b.WriteString(theme.LabelStyle().Render("Source:"))
+		fmt.Fprintf(&b, " (%d lines)", strings.Count(source, "\n")+1)
+		b.WriteString("\n")
+		source = truncateMultiline(source, width-4, 15)
+		if cellType != "markdown" {
+			source = highlightPython(theme, source)
+		}
+		for _, line := range strings.Split(source, "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
 	// Tool result/output
-	b.WriteString(formatResultSection(input, width))
+	b.WriteString(formatResultSection(theme, input, width))
 
 	return b.String()
 }
 
 // formatReadDetail renders Read tool details
-func formatReadDetail(input *session.ToolInput, width int) string {
+func formatReadDetail(theme *Theme, input *session.ToolInput, width int, dc detailContext) string {
 	var b strings.Builder
 
 	filePath := getString(input.Parsed, "file_path")
@@ -305,18 +577,18 @@ func formatReadDetail(input *session.ToolInput, width int) string {
 	limit := getFloat(input.Parsed, "limit")
 
 	// Security check
-	if isSensitivePath(filePath) {
-		b.WriteString(DangerHeaderStyle().Render("! Reading sensitive path"))
+	if security.IsSensitivePath(filePath, dc.cfg.Security.SensitivePaths) {
+		b.WriteString(theme.DangerHeaderStyle().Render("! Reading sensitive path"))
 		b.WriteString("\n\n")
 	}
 
-	b.WriteString(LabelStyle().Render("File:"))
+	b.WriteString(theme.LabelStyle().Render("File:"))
 	b.WriteString("\n")
-	b.WriteString(PathStyle().Render(filePath))
+	b.WriteString(highlightMatches(theme, filePath, dc.highlight, theme.PathStyle()))
 	b.WriteString("\n\n")
 
 	if offset > 0 || limit > 0 {
-		b.WriteString(LabelStyle().Render("Range:"))
+		b.WriteString(theme.LabelStyle().Render("Range:"))
 		b.WriteString("\n")
 		if offset > 0 {
 			fmt.Fprintf(&b, "  Offset: %.0f\n", offset)
@@ -327,38 +599,38 @@ func formatReadDetail(input *session.ToolInput, width int) string {
 	}
 
 	// Tool result/output
-	b.WriteString(formatResultSection(input, width))
+	b.WriteString(formatResultSection(theme, input, width))
 
 	return b.String()
 }
 
 // formatGlobDetail renders Glob tool details
-func formatGlobDetail(input *session.ToolInput, width int) string {
+func formatGlobDetail(theme *Theme, input *session.ToolInput, width int) string {
 	var b strings.Builder
 
 	pattern := getString(input.Parsed, "pattern")
 	path := getString(input.Parsed, "path")
 
-	b.WriteString(LabelStyle().Render("Pattern:"))
+	b.WriteString(theme.LabelStyle().Render("Pattern:"))
 	b.WriteString("\n")
-	b.WriteString(CodeBlockStyle(width).Render(pattern))
+	b.WriteString(theme.CodeBlockStyle(width).Render(pattern))
 	b.WriteString("\n\n")
 
 	if path != "" {
-		b.WriteString(LabelStyle().Render("Path:"))
+		b.WriteString(theme.LabelStyle().Render("Path:"))
 		b.WriteString("\n")
-		b.WriteString(PathStyle().Render(path))
+		b.WriteString(theme.PathStyle().Render(path))
 		b.WriteString("\n")
 	}
 
 	// Tool result/output
-	b.WriteString(formatResultSection(input, width))
+	b.WriteString(formatResultSection(theme, input, width))
 
 	return b.String()
 }
 
 // formatGrepDetail renders Grep tool details
-func formatGrepDetail(input *session.ToolInput, width int) string {
+func formatGrepDetail(theme *Theme, input *session.ToolInput, width int) string {
 	var b strings.Builder
 
 	pattern := getString(input.Parsed, "pattern")
@@ -367,15 +639,15 @@ func formatGrepDetail(input *session.ToolInput, width int) string {
 	fileType := getString(input.Parsed, "type")
 	outputMode := getString(input.Parsed, "output_mode")
 
-	b.WriteString(LabelStyle().Render("Pattern:"))
+	b.WriteString(theme.LabelStyle().Render("Pattern:"))
 	b.WriteString("\n")
-	b.WriteString(CodeBlockStyle(width).Render(pattern))
+	b.WriteString(theme.CodeBlockStyle(width).Render(pattern))
 	b.WriteString("\n\n")
 
 	if path != "" {
-		b.WriteString(LabelStyle().Render("Path:"))
+		b.WriteString(theme.LabelStyle().Render("Path:"))
 		b.WriteString("\n")
-		b.WriteString(PathStyle().Render(path))
+		b.WriteString(theme.PathStyle().Render(path))
 		b.WriteString("\n\n")
 	}
 
@@ -392,20 +664,20 @@ func formatGrepDetail(input *session.ToolInput, width int) string {
 	}
 
 	if len(opts) > 0 {
-		b.WriteString(LabelStyle().Render("Options:"))
+		b.WriteString(theme.LabelStyle().Render("Options:"))
 		b.WriteString("\n")
-		b.WriteString(MutedStyle().Render(strings.Join(opts, ", ")))
+		b.WriteString(theme.MutedStyle().Render(strings.Join(opts, ", ")))
 		b.WriteString("\n")
 	}
 
 	// Tool result/output
-	b.WriteString(formatResultSection(input, width))
+	b.WriteString(formatResultSection(theme, input, width))
 
 	return b.String()
 }
 
 // formatTaskDetail renders Task tool details (subagent spawning)
-func formatTaskDetail(input *session.ToolInput, width int) string {
+func formatTaskDetail(theme *Theme, input *session.ToolInput, width int) string {
 	var b strings.Builder
 
 	description := getString(input.Parsed, "description")
@@ -415,38 +687,194 @@ func formatTaskDetail(input *session.ToolInput, width int) string {
 
 	// Security note for subagents
 	if subagentType != "" {
-		b.WriteString(WarningStyle().Render("* Spawns subagent: " + subagentType))
+		b.WriteString(theme.WarningStyle().Render("* Spawns subagent: " + subagentType))
 		b.WriteString("\n\n")
 	}
 
 	if description != "" {
-		b.WriteString(LabelStyle().Render("Task:"))
+		b.WriteString(theme.LabelStyle().Render("Task:"))
 		b.WriteString("\n")
 		b.WriteString(wrapText(description, width-2))
 		b.WriteString("\n\n")
 	}
 
 	if prompt != "" {
-		b.WriteString(LabelStyle().Render("Prompt:"))
+		b.WriteString(theme.LabelStyle().Render("Prompt:"))
 		b.WriteString("\n")
-		b.WriteString(MutedStyle().Render(truncateMultiline(prompt, width-2, 8)))
+		b.WriteString(theme.MutedStyle().Render(truncateMultiline(prompt, width-2, 8)))
 		b.WriteString("\n\n")
 	}
 
 	if model != "" {
-		b.WriteString(LabelStyle().Render("Model: "))
+		b.WriteString(theme.LabelStyle().Render("Model: "))
 		b.WriteString(model)
 		b.WriteString("\n")
 	}
 
 	// Tool result/output
-	b.WriteString(formatResultSection(input, width))
+	b.WriteString(formatResultSection(theme, input, width))
+
+	return b.String()
+}
+
+// questionAnswer matches one answer in an AskUserQuestion result:
+// "question"="answer"
+var questionAnswer = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"="((?:[^"\\]|\\.)*)"`)
+
+// formatSkillDetail renders the skill invoked and the arguments passed to it
+func formatSkillDetail(theme *Theme, input *session.ToolInput, width int) string {
+	var b strings.Builder
+
+	name := getString(input.Parsed, "skill")
+	if name == "" {
+		name = getString(input.Parsed, "command") // Older sessions
+	}
+	b.WriteString(theme.LabelStyle().Render("Skill: "))
+	b.WriteString(strings.TrimPrefix(name, "/"))
+	b.WriteString("\n\n")
+
+	if args := getString(input.Parsed, "args"); args != "" {
+		b.WriteString(theme.LabelStyle().Render("Arguments:"))
+		b.WriteString("\n")
+		b.WriteString(theme.CodeBlockStyle(width).Render(truncateMultiline(args, width-4, 8)))
+		b.WriteString("\n\n")
+	}
+
+	// Tool result/output
+	b.WriteString(formatResultSection(theme, input, width))
 
 	return b.String()
 }
 
+// formatAskUserQuestionDetail renders the questions asked, their options,
+// and the user's answers, marking the chosen options
+func formatAskUserQuestionDetail(theme *Theme, input *session.ToolInput, width int) string {
+	var b strings.Builder
+
+	answers := make(map[string]string)
+	for _, m := range questionAnswer.FindAllStringSubmatch(input.Result, -1) {
+		answers[m[1]] = m[2]
+	}
+
+	questions, _ := input.Parsed["questions"].([]interface{})
+	for _, q := range questions {
+		question, _ := q.(map[string]interface{})
+		text := getString(question, "question")
+		label := "Question:"
+		if header := getString(question, "header"); header != "" {
+			label = header + ":"
+		}
+		b.WriteString(theme.LabelStyle().Render(label))
+		if getBool(question, "multiSelect") {
+			b.WriteString(theme.MutedStyle().Render(" (multiple choice)"))
+		}
+		b.WriteString("\n")
+		b.WriteString(wrapText(text, width-2))
+		b.WriteString("\n")
+
+		answer, answered := answers[text]
+		chosen := make(map[string]bool)
+		for _, a := range strings.Split(answer, ", ") {
+			chosen[a] = true
+		}
+		options, _ := question["options"].([]interface{})
+		for _, o := range options {
+			option, _ := o.(map[string]interface{})
+			optLabel := getString(option, "label")
+			line := "  ○ " + optLabel
+			if chosen[optLabel] {
+				line = theme.AdditionStyle().Render("  ● " + optLabel)
+			}
+			b.WriteString(line)
+			if desc := getString(option, "description"); desc != "" {
+				b.WriteString(theme.MutedStyle().Render(" - " + truncateLine(desc, max(width-len(optLabel)-7, 10))))
+			}
+			b.WriteString("\n")
+		}
+		if answered && !optionLabels(options)[answer] {
+			// Free-text answer, or several options picked
+			b.WriteString(theme.AdditionStyle().Render("  → " + answer))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(answers) == 0 {
+		// Not answered yet, declined, or a result format we don't parse
+		b.WriteString(formatResultSection(theme, input, width))
+	}
+
+	return b.String()
+}
+
+// optionLabels returns the set of an AskUserQuestion question's option labels
+func optionLabels(options []interface{}) map[string]bool {
+	labels := make(map[string]bool)
+	for _, o := range options {
+		option, _ := o.(map[string]interface{})
+		labels[getString(option, "label")] = true
+	}
+	return labels
+}
+
+// formatExitPlanModeDetail renders the plan proposed for approval as
+// markdown, then whether it was approved
+func formatExitPlanModeDetail(theme *Theme, input *session.ToolInput, width int) string {
+	var b strings.Builder
+
+	b.WriteString(theme.LabelStyle().Render("Proposed plan:"))
+	b.WriteString("\n")
+	b.WriteString(renderMarkdown(theme, getString(input.Parsed, "plan"), width-2))
+	b.WriteString("\n")
+
+	// Tool result/output (approval or the user's feedback)
+	b.WriteString(formatResultSection(theme, input, width))
+
+	return b.String()
+}
+
+// renderMarkdown renders the parts of markdown a plan uses: headings,
+// bullet and numbered lists, fenced code, and wrapped paragraphs
+func renderMarkdown(theme *Theme, src string, width int) string {
+	var out []string
+	inCode := false
+	for _, line := range strings.Split(strings.TrimSpace(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, theme.CodeTextStyle().Render("  "+truncateLine(line, width-2)))
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			out = append(out, theme.LabelStyle().Render(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))))
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			out = append(out, hangingWrap(indent+"• ", stripEmphasis(trimmed[2:]), width))
+		default:
+			out = append(out, hangingWrap(indent, stripEmphasis(trimmed), width))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// hangingWrap wraps text after prefix, indenting continuation lines to match
+func hangingWrap(prefix, text string, width int) string {
+	pad := strings.Repeat(" ", len([]rune(prefix)))
+	return prefix + strings.ReplaceAll(wrapText(text, width-len(pad)), "\n", "\n"+pad)
+}
+
+// stripEmphasis removes markdown bold and inline-code markers
+func stripEmphasis(s string) string {
+	return strings.NewReplacer("**", "", "__", "", "`", "").Replace(s)
+}
+
 // formatWebDetail renders WebFetch/WebSearch tool details
-func formatWebDetail(input *session.ToolInput, width int) string {
+func formatWebDetail(theme *Theme, input *session.ToolInput, width int) string {
 	var b strings.Builder
 
 	url := getString(input.Parsed, "url")
@@ -454,43 +882,43 @@ func formatWebDetail(input *session.ToolInput, width int) string {
 	prompt := getString(input.Parsed, "prompt")
 
 	if url != "" {
-		b.WriteString(LabelStyle().Render("URL:"))
+		b.WriteString(theme.LabelStyle().Render("URL:"))
 		b.WriteString("\n")
-		b.WriteString(PathStyle().Render(url))
+		b.WriteString(theme.PathStyle().Render(url))
 		b.WriteString("\n\n")
 	}
 
 	if query != "" {
-		b.WriteString(LabelStyle().Render("Query:"))
+		b.WriteString(theme.LabelStyle().Render("Query:"))
 		b.WriteString("\n")
 		b.WriteString(wrapText(query, width-2))
 		b.WriteString("\n\n")
 	}
 
 	if prompt != "" {
-		b.WriteString(LabelStyle().Render("Prompt:"))
+		b.WriteString(theme.LabelStyle().Render("Prompt:"))
 		b.WriteString("\n")
-		b.WriteString(MutedStyle().Render(truncateMultiline(prompt, width-2, 5)))
+		b.WriteString(theme.MutedStyle().Render(truncateMultiline(prompt, width-2, 5)))
 		b.WriteString("\n")
 	}
 
 	// Tool result/output
-	b.WriteString(formatResultSection(input, width))
+	b.WriteString(formatResultSection(theme, input, width))
 
 	return b.String()
 }
 
 // formatGenericDetail renders a generic tool detail view
-func formatGenericDetail(input *session.ToolInput, width int) string {
+func formatGenericDetail(theme *Theme, input *session.ToolInput, width int) string {
 	var b strings.Builder
 
-	b.WriteString(LabelStyle().Render("Tool: "))
+	b.WriteString(theme.LabelStyle().Render("Tool: "))
 	b.WriteString(input.ToolName)
 	b.WriteString("\n\n")
 
 	// Show all parsed fields
 	if len(input.Parsed) > 0 {
-		b.WriteString(LabelStyle().Render("Parameters:"))
+		b.WriteString(theme.LabelStyle().Render("Parameters:"))
 		b.WriteString("\n")
 		for key, value := range input.Parsed {
 			valueStr := fmt.Sprintf("%v", value)
@@ -502,31 +930,11 @@ func formatGenericDetail(input *session.ToolInput, width int) string {
 	}
 
 	// Tool result/output
-	b.WriteString(formatResultSection(input, width))
+	b.WriteString(formatResultSection(theme, input, width))
 
 	return b.String()
 }
 
-// sensitivePatterns contains path patterns that indicate security-sensitive files.
-// Defined at package level to avoid allocation on each isSensitivePath call.
-var sensitivePatterns = []string{
-	"/etc/", "/usr/", "/bin/", "/sbin/",
-	".ssh/", ".gnupg/", ".aws/",
-	".env", "credentials", "secrets",
-	"/root/", "sudoers", "passwd", "shadow",
-}
-
-// isSensitivePath checks if a path is security-sensitive
-func isSensitivePath(path string) bool {
-	pathLower := strings.ToLower(path)
-	for _, s := range sensitivePatterns {
-		if strings.Contains(pathLower, s) {
-			return true
-		}
-	}
-	return false
-}
-
 // Helper functions for parsing input
 
 func getString(m map[string]interface{}, key string) string {
@@ -610,9 +1018,22 @@ func truncateMultiline(text string, width, maxLines int) string {
 	return strings.Join(lines, "\n")
 }
 
+// formatSize renders a byte count as B, KB, MB, or GB
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
 // formatResultSection renders the tool result/output section if available
-func formatResultSection(input *session.ToolInput, width int) string {
-	if input.Result == "" {
+func formatResultSection(theme *Theme, input *session.ToolInput, width int) string {
+	if input.Result == "" && len(input.Attachments) == 0 {
 		return ""
 	}
 
@@ -620,18 +1041,25 @@ func formatResultSection(input *session.ToolInput, width int) string {
 	b.WriteString("\n")
 
 	if input.IsError {
-		b.WriteString(DangerHeaderStyle().Render("Output (Error):"))
+		b.WriteString(theme.DangerHeaderStyle().Render("Output (Error):"))
 	} else {
-		b.WriteString(LabelStyle().Render("Output:"))
+		b.WriteString(theme.LabelStyle().Render("Output:"))
 	}
 	b.WriteString("\n")
 
-	// Truncate long results
-	result := truncateMultiline(input.Result, width-4, 8)
+	// Placeholders for images and binary content instead of their bytes
+	for _, att := range input.Attachments {
+		b.WriteString(theme.MutedStyle().Render(fmt.Sprintf("[%s, %s] v:open", att.MediaType, formatSize(len(att.Data)))))
+		b.WriteString("\n")
+	}
+	if input.Result == "" {
+		return b.String()
+	}
+
 	if input.IsError {
-		b.WriteString(DangerStyle().Render(result))
+		b.WriteString(theme.DangerStyle().Render(truncateMultiline(input.Result, width-4, 8)))
 	} else {
-		b.WriteString(CodeBlockStyle(width).Render(result))
+		b.WriteString(formatResultBody(theme, input, width))
 	}
 	b.WriteString("\n")
 
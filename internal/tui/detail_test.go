@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestFormatResultSectionAttachments(t *testing.T) {
+	input := &session.ToolInput{
+		HasResult:   true,
+		Attachments: []session.Attachment{{MediaType: "image/png", Data: make([]byte, 3*1024*1024/2)}},
+	}
+	got := formatResultSection(testTheme(), input, 60)
+	if !strings.Contains(got, "[image/png, 1.5 MB] v:open") {
+		t.Errorf("expected an attachment placeholder, got %q", got)
+	}
+}
+
+func TestFormatNotebookEditDetail(t *testing.T) {
+	input := &session.ToolInput{ToolName: "NotebookEdit", Parsed: map[string]interface{}{
+		"notebook_path": "/projects/alpha/analysis.ipynb",
+		"cell_id":       "a1b2",
+		"cell_type":     "code",
+		"edit_mode":     "insert",
+		"new_source":    "import pandas as pd\n# load\ndf = pd.read_csv(\"data.csv\")",
+	}}
+	got := ansi.Strip(formatNotebookEditDetail(testTheme(), input, 60, detailContext{cfg: config.DefaultConfig()}))
+	for _, want := range []string{"/projects/alpha/analysis.ipynb", "Cell: cell a1b2", "Inserts a new code cell after cell a1b2", "(3 lines)", `df = pd.read_csv("data.csv")`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+
+	input.Parsed["edit_mode"] = "delete"
+	if got := ansi.Strip(formatNotebookEditDetail(testTheme(), input, 60, detailContext{cfg: config.DefaultConfig()})); !strings.Contains(got, "Deletes the cell") || strings.Contains(got, "Source:") {
+		t.Errorf("expected a delete without source, got:\n%s", got)
+	}
+}
+
+func TestHighlightPythonKeepsText(t *testing.T) {
+	src := "def f(x):\n    '''doc\n    string'''\n    return x + 1  # inc"
+	if got := ansi.Strip(highlightPython(testTheme(), src)); got != src {
+		t.Errorf("highlighting changed the source:\n%s", got)
+	}
+}
+
+func TestFormatAskUserQuestionDetail(t *testing.T) {
+	input := &session.ToolInput{ToolName: "AskUserQuestion", HasResult: true, Parsed: map[string]interface{}{
+		"questions": []interface{}{map[string]interface{}{
+			"question": "Which database?",
+			"header":   "Database",
+			"options": []interface{}{
+				map[string]interface{}{"label": "Postgres", "description": "Relational"},
+				map[string]interface{}{"label": "SQLite"},
+			},
+		}},
+	}, Result: `User has answered your questions: "Which database?"="Postgres". You can now continue.`}
+
+	got := ansi.Strip(formatAskUserQuestionDetail(testTheme(), input, 60))
+	for _, want := range []string{"Database:", "Which database?", "● Postgres - Relational", "○ SQLite"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Output:") {
+		t.Error("expected the parsed answer instead of the raw result")
+	}
+}
+
+func TestFormatSkillDetail(t *testing.T) {
+	input := &session.ToolInput{ToolName: "Skill", HasResult: true, Parsed: map[string]interface{}{
+		"skill": "pdf",
+		"args":  "extract tables from report.pdf",
+	}, Result: "Launching skill: pdf"}
+
+	got := ansi.Strip(formatSkillDetail(testTheme(), input, 60))
+	for _, want := range []string{"Skill: pdf", "Arguments:", "extract tables from report.pdf", "Launching skill: pdf"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	plan := "# Plan\n\n1. Add **cache**\n  - keyed by `uuid`\n\n```\ngo test ./...\n```"
+	got := ansi.Strip(renderMarkdown(testTheme(), plan, 40))
+	want := "Plan\n\n1. Add cache\n  • keyed by uuid\n\n  go test ./..."
+	if got != want {
+		t.Errorf("renderMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConfigTamperingWarnings(t *testing.T) {
+	dc := detailContext{cfg: config.DefaultConfig(), projectPath: "/projects/alpha"}
+	edit := &session.ToolInput{ToolName: "Edit", Parsed: map[string]interface{}{
+		"file_path":  "/projects/alpha/.claude/settings.json",
+		"old_string": `"deny": ["Bash(rm:*)"]`,
+		"new_string": `"deny": []`,
+	}}
+	if got := ansi.Strip(formatEditDetail(testTheme(), edit, 60, dc)); !strings.Contains(got, "! Editing the agent's own settings") {
+		t.Errorf("expected a tampering header, got:\n%s", got)
+	}
+
+	bash := &session.ToolInput{ToolName: "Bash", Parsed: map[string]interface{}{
+		"command": "cp hook.sh .claude/hooks/ && echo ok >> CLAUDE.md",
+	}}
+	got := ansi.Strip(formatBashDetail(testTheme(), bash, 80, dc))
+	for _, want := range []string{"Changes the agent's hooks: .claude/hooks/hook.sh", "Changes the agent's instructions: CLAUDE.md"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatHooks(t *testing.T) {
+	cmd := session.CommandEntry{ToolName: "Bash", RawCommand: "git push --force", Hooks: []session.HookRun{
+		{Event: "PreToolUse", Command: "~/.claude/hooks/guard.sh", Outcome: session.HookBlocked, Detail: "No force pushes"},
+		{Event: "PostToolUse", Command: "notify.sh", Outcome: session.HookRan},
+	}}
+	got := ansi.Strip(formatHooks(testTheme(), cmd.Hooks, 80))
+	for _, want := range []string{"Hooks:", "PreToolUse [~/.claude/hooks/guard.sh] blocked: No force pushes", "PostToolUse [notify.sh] ran"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if badge := hookBadge(&cmd); badge != "[hook blocked] " {
+		t.Errorf("hookBadge = %q, want the blocking outcome", badge)
+	}
+}
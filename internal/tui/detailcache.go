@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"container/list"
+	"fmt"
+
+	"cc_session_mon/internal/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// detailCacheSize caps the loaded command details kept for quick navigation
+const detailCacheSize = 64
+
+// detailCache is a least-recently-used cache of loaded command details.
+// Only settled details (the tool result has landed and any background shell
+// has ended) are cached, so pending output is always reloaded.
+type detailCache struct {
+	entries map[string]*list.Element
+	order   *list.List // Most recently used at the front
+}
+
+func newDetailCache() *detailCache {
+	return &detailCache{entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// detailKey identifies a command's details: the arguments to session.FetchToolInput
+func detailKey(cmd session.CommandEntry) string {
+	return fmt.Sprintf("%s:%d:%s:%s", cmd.FilePath, cmd.LineNumber, cmd.ToolName, cmd.UUID)
+}
+
+// get returns the cached details for key, marking them recently used
+func (c *detailCache) get(key string) (detailLoadedMsg, bool) {
+	el, ok := c.entries[key]
+	if !ok {
+		return detailLoadedMsg{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(detailLoadedMsg), true
+}
+
+// put caches loaded details, evicting the least recently used beyond the cap
+func (c *detailCache) put(msg detailLoadedMsg) {
+	if msg.input == nil || !msg.input.Settled() {
+		return
+	}
+	if el, ok := c.entries[msg.key]; ok {
+		el.Value = msg
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[msg.key] = c.order.PushFront(msg)
+	for c.order.Len() > detailCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(detailLoadedMsg).key)
+	}
+}
+
+// loadDetail shows cmd's details in the detail panel, from the cache when
+// possible, and prefetches the rows either side of the selection
+func (m Model) loadDetail(cmd session.CommandEntry) (Model, tea.Cmd) {
+	prefetch := m.prefetchDetailCmd()
+	if cached, ok := m.detailCache.get(detailKey(cmd)); ok {
+		m.loadingDetail = false
+		m.loadedInput = cached.input
+		m.scriptRuns = cached.scriptRuns
+		return m, prefetch
+	}
+	m.loadingDetail = true
+	return m, tea.Batch(m.loadDetailCmd(cmd), prefetch)
+}
+
+// prefetchDetailCmd loads the details of the commands above and below the
+// selection that aren't cached yet
+func (m Model) prefetchDetailCmd() tea.Cmd {
+	var cmds []tea.Cmd
+	items := m.commandList.Items()
+	for _, i := range []int{m.commandList.Index() - 1, m.commandList.Index() + 1} {
+		if i < 0 || i >= len(items) {
+			continue
+		}
+		item, ok := items[i].(commandItem)
+		if !ok {
+			continue
+		}
+		if _, cached := m.detailCache.entries[detailKey(item.command)]; cached {
+			continue
+		}
+		load := m.loadDetailCmd(item.command)
+		cmds = append(cmds, func() tea.Msg {
+			if msg, ok := load().(detailLoadedMsg); ok {
+				return detailPrefetchedMsg(msg)
+			}
+			return nil // Errors surface if the row is actually opened
+		})
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diskUsageMsg carries the bytes each session occupies on disk, by file path
+type diskUsageMsg map[string]int
+
+// diskUsageCmd measures the sessions' files (main and subagents) off the UI
+// goroutine; it runs at discovery and on every tick
+func (m Model) diskUsageCmd() tea.Cmd {
+	paths := make([]string, len(m.sessions))
+	for i, sess := range m.sessions {
+		paths[i] = sess.FilePath
+	}
+	return func() tea.Msg {
+		usage := make(diskUsageMsg, len(paths))
+		for _, path := range paths {
+			usage[path] = int(session.DiskUsage(path))
+		}
+		return usage
+	}
+}
+
+// handleDiskUsage stores new measurements, reordering the Sessions list when
+// it is sorted by size
+func (m Model) handleDiskUsage(msg diskUsageMsg) Model {
+	m.diskUsage = msg
+	if m.sessionOrder == orderSize {
+		return m.resortSessions(false)
+	}
+	return m.updateSessionList()
+}
+
+// sessionUsage returns the bytes a session occupies, or 0 if not measured yet
+func (m Model) sessionUsage(sess *session.Session) int {
+	return m.diskUsage[sess.FilePath]
+}
+
+// projectUsage returns the bytes used by all sessions in the same project
+// directory as sess, and how many sessions that is
+func (m Model) projectUsage(sess *session.Session) (total, sessions int) {
+	dir := filepath.Dir(sess.FilePath)
+	for _, s := range m.sessions {
+		if filepath.Dir(s.FilePath) == dir {
+			total += m.sessionUsage(s)
+			sessions++
+		}
+	}
+	return total, sessions
+}
+
+// totalUsage returns the bytes used by every listed session
+func (m Model) totalUsage() int {
+	total := 0
+	for _, s := range m.sessions {
+		total += m.sessionUsage(s)
+	}
+	return total
+}
+
+// sessionOrder is how the Sessions list is sorted
+type sessionOrder int
+
+const (
+	orderActivity sessionOrder = iota // Most recently active first, the watcher's order
+	orderSize                         // Largest on disk first
+	orderOwner                        // Grouped by owner, unowned sessions last
+	orderBlast                        // Highest blast radius first, for review triage
+	sessionOrderCount
+)
+
+// sortSessions applies the owner filter and orders the sessions largest
+// first, by owner, or by blast radius; otherwise they keep the watcher's
+// activity order
+func (m Model) sortSessions() Model {
+	m.sessions = m.filterByOwner(m.sessions)
+	m.blastScores = nil
+	switch m.sessionOrder {
+	case orderSize:
+		sort.SliceStable(m.sessions, func(i, j int) bool {
+			return m.sessionUsage(m.sessions[i]) > m.sessionUsage(m.sessions[j])
+		})
+	case orderOwner:
+		slices.SortStableFunc(m.sessions, func(a, b *session.Session) int {
+			return compareOwners(sessionOwner(a), sessionOwner(b))
+		})
+	case orderBlast:
+		m.blastScores = make(map[string]int, len(m.sessions))
+		for _, sess := range m.sessions {
+			m.blastScores[sess.FilePath] = security.SessionBlastRadius(sess).Score
+		}
+		sort.SliceStable(m.sessions, func(i, j int) bool {
+			return m.blastScores[m.sessions[i].FilePath] > m.blastScores[m.sessions[j].FilePath]
+		})
+	}
+	return m
+}
+
+// resortSessions reorders the Sessions list after the sort order, the
+// owner filter, or the sizes change, keeping the active and highlighted
+// sessions selected. refetch starts over from the watcher's list, which is
+// in activity order and includes sessions the owner filter hid.
+func (m Model) resortSessions(refetch bool) Model {
+	var active, highlighted string
+	if m.activeIdx >= 0 && m.activeIdx < len(m.sessions) {
+		active = m.sessions[m.activeIdx].FilePath
+	}
+	if sess := m.highlightedSession(); sess != nil {
+		highlighted = sess.FilePath
+	}
+
+	if refetch && m.watcher != nil {
+		m.sessions = m.watcher.GetSessions()
+	}
+	m = m.sortSessions()
+	m = m.updateSessionList()
+
+	m.activeIdx = min(m.activeIdx, len(m.sessions)-1)
+	for i, sess := range m.sessions {
+		if sess.FilePath == active {
+			m.activeIdx = i
+		}
+		if sess.FilePath == highlighted {
+			m.sessionList.Select(i)
+		}
+	}
+	return m
+}
+
+// cycleSessionOrder switches the Sessions list from activity to size to
+// owner to blast radius order, and back
+func (m Model) cycleSessionOrder() Model {
+	m.sessionOrder = (m.sessionOrder + 1) % sessionOrderCount
+	return m.resortSessions(m.sessionOrder == orderActivity)
+}
+
+// sessionSortLabel describes the Sessions list order and owner filter for
+// its column header
+func (m Model) sessionSortLabel() string {
+	var label string
+	switch m.sessionOrder {
+	case orderSize:
+		label = fmt.Sprintf(" (by size, %s total)", formatSize(m.totalUsage()))
+	case orderOwner:
+		label = " (by owner)"
+	case orderBlast:
+		label = " (by blast radius)"
+	}
+	if m.ownerFilter != "" {
+		label += " · owner " + m.ownerFilter
+	}
+	return label
+}
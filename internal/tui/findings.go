@@ -0,0 +1,285 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// Finding Item
+// ============================================================================
+
+// findingItem wraps a FindingSummary for the list component
+type findingItem struct {
+	finding *security.FindingSummary
+}
+
+func (i findingItem) FilterValue() string { return i.finding.Rule }
+func (i findingItem) Title() string       { return i.finding.Rule }
+func (i findingItem) Description() string {
+	return fmt.Sprintf("%d commands in %d sessions", i.finding.Count, i.finding.Sessions)
+}
+
+// findingDelegate renders finding summary rows
+type findingDelegate struct {
+	theme *Theme
+	width int
+}
+
+// Column widths for the findings list (exported for header rendering)
+const (
+	FindingSeverityWidth = 8
+	FindingRuleWidth     = 32
+	FindingCountWidth    = 7
+	FindingSessionsWidth = 8
+)
+
+func newFindingDelegate(theme *Theme) *findingDelegate {
+	return &findingDelegate{width: 80, theme: theme}
+}
+
+func (d *findingDelegate) SetWidth(w int) {
+	d.width = w
+}
+
+func (d *findingDelegate) Height() int                             { return 1 }
+func (d *findingDelegate) Spacing() int                            { return 0 }
+func (d *findingDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d *findingDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(findingItem)
+	if !ok {
+		return
+	}
+
+	// Format: "severity  rule  count  sessions  last seen"
+	row := fmt.Sprintf("%s  %s  %s  %s  %s",
+		padRight(i.finding.Severity.String(), FindingSeverityWidth),
+		padRight(i.finding.Rule, FindingRuleWidth),
+		padLeft(fmt.Sprintf("%d", i.finding.Count), FindingCountWidth),
+		padLeft(fmt.Sprintf("%d", i.finding.Sessions), FindingSessionsWidth),
+		formatTimeAgo(i.finding.LastSeen),
+	)
+	if len(row) < d.width {
+		row += strings.Repeat(" ", d.width-len(row))
+	}
+
+	style := d.theme.severityStyle(i.finding.Severity).Width(d.width)
+	if index == m.Index() {
+		style = style.Background(d.theme.Surface).Bold(true)
+	}
+	fmt.Fprint(w, style.Render(row))
+}
+
+// severityStyle returns the row style for a finding severity
+func (t *Theme) severityStyle(sev security.Severity) lipgloss.Style {
+	switch sev {
+	case security.SeverityHigh:
+		return t.DangerStyle().Bold(true)
+	case security.SeverityMedium:
+		return t.WarningStyle()
+	default:
+		return t.MutedStyle()
+	}
+}
+
+// ============================================================================
+// Finding Command Item
+// ============================================================================
+
+// findingCommandItem wraps a command that triggered the drilled-down finding
+type findingCommandItem struct {
+	ref security.FindingRef
+}
+
+func (i findingCommandItem) FilterValue() string { return i.ref.Command.RawCommand }
+func (i findingCommandItem) Title() string       { return i.ref.Command.Pattern }
+func (i findingCommandItem) Description() string { return i.ref.Command.RawCommand }
+
+// findingCommandDelegate renders the offending commands of a finding
+type findingCommandDelegate struct {
+	theme *Theme
+	width int
+}
+
+// FindingProjectWidth is the project column width in the finding drill-down
+const FindingProjectWidth = 16
+
+func newFindingCommandDelegate(theme *Theme) *findingCommandDelegate {
+	return &findingCommandDelegate{width: 80, theme: theme}
+}
+
+func (d *findingCommandDelegate) SetWidth(w int) {
+	d.width = w
+}
+
+func (d *findingCommandDelegate) Height() int                             { return 1 }
+func (d *findingCommandDelegate) Spacing() int                            { return 0 }
+func (d *findingCommandDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d *findingCommandDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(findingCommandItem)
+	if !ok {
+		return
+	}
+
+	// Format: "Jan 02 15:04  project  pattern  command..."
+	fixedWidth := CommandTimestampWidth + 2 + FindingProjectWidth + 2 + CommandPatternWidth + 2
+	commandWidth := max(10, d.width-fixedWidth)
+	rawCmd := singleLine(i.ref.Command.RawCommand)
+	if len(rawCmd) > commandWidth {
+		rawCmd = truncateWithEllipsis(rawCmd, commandWidth)
+	}
+
+	row := fmt.Sprintf("%s  %s  %s  %s",
+		i.ref.Command.Timestamp.Format("Jan 02 15:04"),
+		padRight(filepath.Base(i.ref.Session.ProjectPath), FindingProjectWidth),
+		padRight(i.ref.Command.Pattern, CommandPatternWidth),
+		rawCmd,
+	)
+	if len(row) < d.width {
+		row += strings.Repeat(" ", d.width-len(row))
+	}
+
+	style := d.theme.styleForGroup(toolGroupFor(nil, i.ref.Command.Pattern)).Width(d.width)
+	if index == m.Index() {
+		style = style.Background(d.theme.Surface).Bold(true)
+	}
+	fmt.Fprint(w, style.Render(row))
+}
+
+// ============================================================================
+// Model helpers
+// ============================================================================
+
+// aggregateFindings rebuilds the findings across all sessions, keeping the
+// drilled-down finding (by rule) when it still exists
+func (m Model) aggregateFindings() Model {
+	m.findings = security.AggregateFindings(m.sessions)
+
+	items := make([]list.Item, len(m.findings))
+	for i, f := range m.findings {
+		items[i] = findingItem{finding: f}
+	}
+	m.findingList.SetItems(items)
+
+	if m.findingDrill != nil {
+		rule := m.findingDrill.Rule
+		m.findingDrill = nil
+		for _, f := range m.findings {
+			if f.Rule == rule {
+				m = m.drillIntoFinding(f)
+				break
+			}
+		}
+	}
+	return m
+}
+
+// drillIntoFinding lists the commands that triggered a finding
+func (m Model) drillIntoFinding(f *security.FindingSummary) Model {
+	sameFinding := m.findingDrill != nil && m.findingDrill.Rule == f.Rule
+	m.findingDrill = f
+	items := make([]list.Item, len(f.Commands))
+	for i, ref := range f.Commands {
+		items[i] = findingCommandItem{ref: ref}
+	}
+	m.findingCmdList.SetItems(items)
+	if !sameFinding {
+		m.findingCmdList.Select(0)
+	}
+	return m
+}
+
+// handleFindingsEnter drills into the selected finding, or from the
+// drill-down jumps to the selected command in its session's Commands view
+func (m Model) handleFindingsEnter() (Model, tea.Cmd, bool) {
+	if m.findingDrill == nil {
+		if item, ok := m.findingList.SelectedItem().(findingItem); ok {
+			m = m.drillIntoFinding(item.finding)
+		}
+		return m, nil, true
+	}
+
+	item, ok := m.findingCmdList.SelectedItem().(findingCommandItem)
+	if !ok {
+		return m, nil, true
+	}
+	return m.jumpToCommand(item.ref)
+}
+
+// jumpToCommand switches to a finding's session, selects its command in the
+// Commands view (clearing filters that hide it), and opens the detail panel
+func (m Model) jumpToCommand(ref security.FindingRef) (Model, tea.Cmd, bool) {
+	for i, s := range m.sessions {
+		if s.FilePath == ref.Session.FilePath {
+			m.activeIdx = i
+			break
+		}
+	}
+	m.viewMode = ViewCommands
+	m = m.updateCommandList()
+	m = m.aggregatePatterns()
+
+	idx := m.commandIndex(ref.Command)
+	if idx < 0 && (m.searchActive || m.outsideOnly) {
+		m.searchActive = false
+		m.searchFocused = false
+		m.searchInput.SetValue("")
+		m.searchInput.Blur()
+		m.outsideOnly = false
+		m = m.applySearchFilter()
+		idx = m.commandIndex(ref.Command)
+	}
+	if idx < 0 {
+		return m, nil, true
+	}
+
+	m.commandList.Select(idx)
+	cmd := ref.Command
+	m = m.openDetailPanel(&cmd)
+	m, load := m.loadDetail(cmd)
+	return m, load, true
+}
+
+// commandIndex returns the position of a command in the visible command list,
+// or -1. Parallel calls of one tool share a message UUID, so the tool_use
+// ID tells them apart.
+func (m Model) commandIndex(c session.CommandEntry) int {
+	for i, item := range m.commandList.Items() {
+		ci, ok := item.(commandItem)
+		if ok && ci.command.UUID == c.UUID && ci.command.ToolName == c.ToolName && ci.command.ToolUseID == c.ToolUseID {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderFindingsView renders the findings summary, or a finding's commands
+func (m Model) renderFindingsView() string {
+	if m.findingDrill == nil {
+		header := fmt.Sprintf("%s  %s  %s  %s  %s",
+			padRight("Severity", FindingSeverityWidth),
+			padRight("Rule", FindingRuleWidth),
+			padLeft("Count", FindingCountWidth),
+			padLeft("Sessions", FindingSessionsWidth),
+			"Last seen",
+		)
+		return m.theme.ColumnHeaderStyle(m.width-4).Render(header) + "\n" + m.findingList.View()
+	}
+
+	header := fmt.Sprintf("%s  %s  %s  %s",
+		padRight("Date", CommandTimestampWidth),
+		padRight("Project", FindingProjectWidth),
+		padRight("Pattern", CommandPatternWidth),
+		"Command - "+m.findingDrill.Rule,
+	)
+	return m.theme.ColumnHeaderStyle(m.width-4).Render(header) + "\n" + m.findingCmdList.View()
+}
@@ -0,0 +1,211 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/gitstate"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gitTimeout bounds each git invocation, so a huge or wedged repository
+// can't pile up goroutines
+const gitTimeout = 30 * time.Second
+
+// gitSnapshotMsg carries the git snapshot taken for a session, by file path
+type gitSnapshotMsg struct {
+	path string
+	snap *gitstate.Snapshot
+	err  error
+}
+
+// gitDiffMsg carries the change since a session's snapshot
+type gitDiffMsg struct {
+	path string
+	diff *gitstate.Diff
+	err  error
+}
+
+// gitSnapshotCmd snapshots the project of each session seen active for the
+// first time, when git_snapshots is set. Sessions get an entry as soon as
+// their snapshot is requested, so each is snapshotted once.
+func (m Model) gitSnapshotCmd() tea.Cmd {
+	if !config.Global().GitSnapshots {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, sess := range m.sessions {
+		if !sess.IsActive || sess.ProjectPath == "" {
+			continue
+		}
+		if _, seen := m.gitSnapshots[sess.FilePath]; seen {
+			continue
+		}
+		m.gitSnapshots[sess.FilePath] = gitSnapshotMsg{path: sess.FilePath}
+		path, dir := sess.FilePath, sess.ProjectPath
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+			defer cancel()
+			snap, err := gitstate.Take(ctx, dir)
+			return gitSnapshotMsg{path: path, snap: snap, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleGitDiffKey handles the 'C' key to show the active session's net
+// change to its project since the snapshot
+func (m Model) handleGitDiffKey(key string) (Model, tea.Cmd, bool) {
+	if key != "C" || (m.viewMode != ViewSessions && m.viewMode != ViewCommands) {
+		return m, nil, false
+	}
+	sess := m.ActiveSession()
+	if sess == nil {
+		return m, nil, false
+	}
+	entry, ok := m.gitSnapshots[sess.FilePath]
+	switch {
+	case !config.Global().GitSnapshots:
+		m.notice = "No git snapshots (set git_snapshots: true in the config)"
+		return m, nil, true
+	case !ok:
+		m.notice = "No git snapshot: the session wasn't active while the monitor ran"
+		return m, nil, true
+	case entry.err != nil:
+		m.notice = m.theme.ErrorStyle().UnsetPadding().Render("No git snapshot: " + entry.err.Error())
+		return m, nil, true
+	case entry.snap == nil:
+		m.notice = "The git snapshot is still being taken"
+		return m, nil, true
+	}
+
+	m.showGitDiff = true
+	m.gitDiff, m.gitDiffErr = nil, nil
+	m.gitDiffScroll = 0
+	path, snap := sess.FilePath, entry.snap
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+		defer cancel()
+		diff, err := snap.Diff(ctx)
+		return gitDiffMsg{path: path, diff: diff, err: err}
+	}, true
+}
+
+// handleGitDiffMsg stores a finished diff if it is still the one on screen
+func (m Model) handleGitDiffMsg(msg gitDiffMsg) Model {
+	if sess := m.ActiveSession(); !m.showGitDiff || sess == nil || sess.FilePath != msg.path {
+		return m
+	}
+	m.gitDiff, m.gitDiffErr = msg.diff, msg.err
+	return m
+}
+
+// handleGitDiffScrollKey handles keys while the diff dialog is open
+func (m Model) handleGitDiffScrollKey(key string) (tea.Model, tea.Cmd) {
+	last := max(0, len(m.gitDiffLines())-m.gitDiffHeight())
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q", "C":
+		m.showGitDiff = false
+	case "j", "down":
+		m.gitDiffScroll = min(m.gitDiffScroll+1, last)
+	case "k", "up":
+		m.gitDiffScroll = max(m.gitDiffScroll-1, 0)
+	case "ctrl+d", "pgdown", " ":
+		m.gitDiffScroll = min(m.gitDiffScroll+m.gitDiffHeight()/2, last)
+	case "ctrl+u", "pgup":
+		m.gitDiffScroll = max(m.gitDiffScroll-m.gitDiffHeight()/2, 0)
+	case "g", "home":
+		m.gitDiffScroll = 0
+	case "G", "end":
+		m.gitDiffScroll = last
+	}
+	return m, nil
+}
+
+// gitDiffHeight is how many diff lines the dialog shows at once
+func (m Model) gitDiffHeight() int {
+	return max(5, m.height-14)
+}
+
+// gitDiffLines renders the loaded diff: new untracked files, then the patch
+// with additions and deletions colored
+func (m Model) gitDiffLines() []string {
+	d := m.gitDiff
+	if d == nil {
+		return nil
+	}
+	var lines []string
+	for _, f := range d.NewFiles {
+		lines = append(lines, m.theme.AdditionStyle().Render("new file (untracked): "+f))
+	}
+	if d.Patch == "" {
+		return lines
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	for _, line := range strings.Split(strings.TrimRight(d.Patch, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+			strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+			lines = append(lines, m.theme.LabelStyle().Render(line))
+		case strings.HasPrefix(line, "@@"):
+			lines = append(lines, m.theme.MutedStyle().Render(line))
+		case strings.HasPrefix(line, "+"):
+			lines = append(lines, m.theme.AdditionStyle().Render(line))
+		case strings.HasPrefix(line, "-"):
+			lines = append(lines, m.theme.DeletionStyle().Render(line))
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// overlayGitDiff renders the active session's change since its snapshot in
+// a scrollable dialog centered over the existing view
+func (m Model) overlayGitDiff(background string) string {
+	sess := m.ActiveSession()
+	if sess == nil {
+		return background
+	}
+	entry := m.gitSnapshots[sess.FilePath]
+	if entry.snap == nil {
+		return background
+	}
+
+	title := fmt.Sprintf("Changes since %s - %s", entry.snap.Taken.Format("15:04"), filepath.Base(sess.ProjectPath))
+	lines := []string{m.theme.LabelStyle().Render(title), ""}
+	all := m.gitDiffLines()
+	switch {
+	case m.gitDiffErr != nil:
+		lines = append(lines, m.theme.ErrorStyle().UnsetPadding().Render("Could not diff: "+m.gitDiffErr.Error()))
+	case m.gitDiff == nil:
+		lines = append(lines, m.theme.MutedStyle().Render("Running git diff..."))
+	case m.gitDiff.Empty():
+		lines = append(lines, m.theme.MutedStyle().Render("No changes since the snapshot"))
+	default:
+		// Long lines are cut rather than wrapped, to keep one row per line
+		truncate := m.theme.NewStyle().Inline(true).MaxWidth(max(36, m.width-12))
+		end := min(len(all), m.gitDiffScroll+m.gitDiffHeight())
+		for _, line := range all[m.gitDiffScroll:end] {
+			lines = append(lines, truncate.Render(line))
+		}
+		if len(all) > m.gitDiffHeight() {
+			lines = append(lines, m.theme.MutedStyle().Render(fmt.Sprintf("lines %d-%d of %d", m.gitDiffScroll+1, end, len(all))))
+		}
+	}
+
+	lines = append(lines, "", m.theme.NewStyle().Foreground(m.theme.Muted).Italic(true).Render(
+		"j/k:scroll  ctrl+d/u:page  g/G:top/bottom  esc:close"))
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return m.overlayDialog(background, content, m.width-8)
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"cc_session_mon/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Symbols with text equivalents, used when text_indicators is set for
+// screen readers and terminal fonts without them
+
+// textIndicators reports whether the config asks for text instead of symbols
+func textIndicators() bool {
+	return config.Global().TextIndicators
+}
+
+// indicator returns symbol, or text when text_indicators is set
+func indicator(symbol, text string) string {
+	if textIndicators() {
+		return text
+	}
+	return symbol
+}
+
+// activityIndicator prefixes a session row: a dot when active, or
+// [ACTIVE]/[IDLE] markers of equal width
+func activityIndicator(active bool) string {
+	switch {
+	case textIndicators() && active:
+		return "[ACTIVE] "
+	case textIndicators():
+		return "[IDLE]   "
+	case active:
+		return "● "
+	}
+	return "  "
+}
+
+// activityLabel describes a session's activity: "● active"/"○ idle", or
+// [ACTIVE]/[IDLE]
+func activityLabel(active bool) string {
+	switch {
+	case textIndicators() && active:
+		return "[ACTIVE]"
+	case textIndicators():
+		return "[IDLE]"
+	case active:
+		return "● active"
+	}
+	return "○ idle"
+}
+
+// flagMarker precedes the reasons a session was flagged
+func flagMarker() string {
+	return indicator("⚑ ", "[FLAGGED] ")
+}
+
+// ellipsis marks truncated text
+func ellipsis() string {
+	return indicator("…", "...")
+}
+
+// truncateWithEllipsis cuts s, which is longer than width, to width
+// columns ending in an ellipsis
+func truncateWithEllipsis(s string, width int) string {
+	e := ellipsis()
+	return s[:max(0, width-lipgloss.Width(e))] + e
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pythonKeywords are highlighted in notebook code cells
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true, "def": true,
+	"del": true, "elif": true, "else": true, "except": true, "finally": true, "for": true,
+	"from": true, "global": true, "if": true, "import": true, "in": true, "is": true,
+	"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// highlightPython colors Python source: keywords, strings (including
+// triple-quoted ones spanning lines), comments, and numbers. It is a
+// tokenizer, not a parser, which is enough for reading notebook cells.
+func highlightPython(theme *Theme, src string) string {
+	var b strings.Builder
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '#':
+			end := i
+			for end < len(runes) && runes[end] != '\n' {
+				end++
+			}
+			b.WriteString(renderLines(theme.SyntaxCommentStyle(), string(runes[i:end])))
+			i = end
+		case r == '\'' || r == '"':
+			end := stringEnd(runes, i)
+			b.WriteString(renderLines(theme.SyntaxStringStyle(), string(runes[i:end])))
+			i = end
+		case unicode.IsDigit(r):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.' || runes[end] == '_') {
+				end++
+			}
+			b.WriteString(theme.SyntaxNumberStyle().Render(string(runes[i:end])))
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			word := string(runes[i:end])
+			if pythonKeywords[word] {
+				word = theme.SyntaxKeywordStyle().Render(word)
+			}
+			b.WriteString(word)
+			i = end
+		default:
+			b.WriteRune(r)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// stringEnd returns the index just past the string literal starting at i,
+// or the end of the line (or source, for triple quotes) when unterminated
+func stringEnd(runes []rune, i int) int {
+	quote := runes[i]
+	triple := i+2 < len(runes) && runes[i+1] == quote && runes[i+2] == quote
+	if triple {
+		for j := i + 3; j+2 < len(runes); j++ {
+			if runes[j] == quote && runes[j+1] == quote && runes[j+2] == quote {
+				return j + 3
+			}
+		}
+		return len(runes)
+	}
+	for j := i + 1; j < len(runes); j++ {
+		switch runes[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		case '\n':
+			return j
+		}
+	}
+	return len(runes)
+}
+
+// renderLines styles each line of s separately so a styled span crossing a
+// newline doesn't leave padding or escape codes at the line ends
+func renderLines(style lipgloss.Style, s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = style.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cc_session_mon/internal/config"
+)
+
+// maxSearchHistory caps the remembered search queries
+const maxSearchHistory = 50
+
+// searchHistory holds recent search queries, newest first, and the position
+// while recalling them with Up/Down in the search input
+type searchHistory struct {
+	queries []string
+	pos     int    // Index of the recalled query, -1 while editing
+	draft   string // What was typed before recall started
+	path    string // File the history persists to, "" to keep it for this run only
+}
+
+// searchHistoryPath is where search history persists, in the state directory
+func searchHistoryPath() string {
+	return config.StatePath("search-history")
+}
+
+// loadSearchHistory returns the history persisted at path, or an empty
+// in-memory history when path is ""
+func loadSearchHistory(path string) searchHistory {
+	h := searchHistory{pos: -1, path: path}
+	if path == "" {
+		return h
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return h
+	}
+	for _, q := range strings.Split(string(data), "\n") {
+		if q != "" && len(h.queries) < maxSearchHistory {
+			h.queries = append(h.queries, q)
+		}
+	}
+	return h
+}
+
+// add records a query as the most recent, moving it up if already present,
+// and persists the history. Saving is best-effort: the TUI has nowhere to
+// report a failure and the in-memory history still works.
+func (h *searchHistory) add(query string) {
+	h.pos = -1
+	if query == "" {
+		return
+	}
+	queries := []string{query}
+	for _, q := range h.queries {
+		if q != query && len(queries) < maxSearchHistory {
+			queries = append(queries, q)
+		}
+	}
+	h.queries = queries
+
+	if h.path != "" {
+		if err := os.MkdirAll(filepath.Dir(h.path), 0o750); err == nil {
+			_ = os.WriteFile(filepath.Clean(h.path), []byte(strings.Join(h.queries, "\n")+"\n"), 0o600)
+		}
+	}
+}
+
+// older returns the next older query, remembering current as the draft when
+// recall starts; ok is false at the oldest query
+func (h *searchHistory) older(current string) (query string, ok bool) {
+	if h.pos+1 >= len(h.queries) {
+		return "", false
+	}
+	if h.pos == -1 {
+		h.draft = current
+	}
+	h.pos++
+	return h.queries[h.pos], true
+}
+
+// newer returns the next newer query, or the draft after the newest; ok is
+// false when not recalling
+func (h *searchHistory) newer() (query string, ok bool) {
+	switch h.pos {
+	case -1:
+		return "", false
+	case 0:
+		h.pos = -1
+		return h.draft, true
+	}
+	h.pos--
+	return h.queries[h.pos], true
+}
+
+// reset ends recall, keeping whatever query is in the input
+func (h *searchHistory) reset() {
+	h.pos = -1
+}
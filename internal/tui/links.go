@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+
+	"cc_session_mon/internal/deeplink"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// linkCopiedMsg reports copying a ccmon:// link to the clipboard
+type linkCopiedMsg struct {
+	link string
+	err  error
+}
+
+// copyLinkCmd copies the link to the selected command in the Commands
+// view, or to the highlighted session in the Sessions view
+func (m Model) copyLinkCmd() tea.Cmd {
+	link, ok := m.currentLink()
+	if !ok {
+		return nil
+	}
+	text := link.String()
+	return func() tea.Msg {
+		return linkCopiedMsg{link: text, err: copyToClipboard(text)}
+	}
+}
+
+// currentLink returns the link to what the view has selected
+func (m Model) currentLink() (deeplink.Link, bool) {
+	switch m.viewMode {
+	case ViewSessions:
+		if sess := m.highlightedSession(); sess != nil {
+			return deeplink.ForSession(sess), true
+		}
+	case ViewCommands:
+		if m.activeIdx < 0 || m.activeIdx >= len(m.sessions) {
+			return deeplink.Link{}, false
+		}
+		sess := m.sessions[m.activeIdx]
+		cmd := m.currentCommand()
+		if cmd == nil {
+			return deeplink.ForSession(sess), true
+		}
+		// With chained history the command may be from an earlier session
+		for _, s := range m.sessions {
+			if s.ID == cmd.SessionID {
+				sess = s
+				break
+			}
+		}
+		return deeplink.ForCommand(sess, cmd), true
+	}
+	return deeplink.Link{}, false
+}
+
+// currentCommand returns the command the Commands view shows details of,
+// else the one selected in its list
+func (m Model) currentCommand() *session.CommandEntry {
+	if m.detailPanelOpen && m.selectedCommand != nil {
+		return m.selectedCommand
+	}
+	if item, ok := m.commandList.SelectedItem().(commandItem); ok {
+		return &item.command
+	}
+	return nil
+}
+
+// handleLinkCopied shows the copied link, or the link itself to copy by
+// hand when there's no clipboard (as over SSH)
+func (m Model) handleLinkCopied(msg linkCopiedMsg) Model {
+	if msg.err != nil {
+		m.notice = fmt.Sprintf("No clipboard (%v); link: %s", msg.err, msg.link)
+		return m
+	}
+	m.notice = "Copied " + msg.link
+	return m
+}
+
+// focusLink opens the session, or the command's details, that the link
+// given at startup names. It waits for the session to be discovered,
+// trying again on each session event.
+func (m Model) focusLink() (Model, tea.Cmd) {
+	link := m.pendingLink
+	if link == nil {
+		return m, nil
+	}
+	sess := link.Find(m.sessions)
+	if sess == nil {
+		return m, nil
+	}
+	m.pendingLink = nil
+
+	for i := range sess.Commands {
+		if link.Matches(&sess.Commands[i]) {
+			m, load, _ := m.jumpToCommand(security.FindingRef{Session: sess, Command: sess.Commands[i]})
+			return m, load
+		}
+	}
+	for i, s := range m.sessions {
+		if s == sess {
+			m.activeIdx = i
+			break
+		}
+	}
+	m.viewMode = ViewCommands
+	m = m.updateCommandList()
+	m = m.aggregatePatterns()
+	if link.Command != "" {
+		m.notice = fmt.Sprintf("Command %s not found in session %s", link.Command, sess.ID)
+	}
+	return m, nil
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"cc_session_mon/internal/applog"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// logPaneLines is how many entries the log pane (L) shows
+const logPaneLines = 6
+
+// logPaneHeight returns the rows the log pane takes from the content area,
+// its title rule and entries, or none while it's closed
+func (m Model) logPaneHeight() int {
+	if !m.showLog {
+		return 0
+	}
+	return logPaneLines + 1
+}
+
+// toggleLogPane opens or closes the log pane, marking the warnings logged
+// so far as seen
+func (m Model) toggleLogPane() Model {
+	m.showLog = !m.showLog
+	m.logSeen = applog.Last()
+	return m.updateListSizes()
+}
+
+// renderLogPane renders the latest internal warnings and events, padded to
+// its full height so the layout doesn't shift as entries arrive
+func (m Model) renderLogPane() string {
+	width := max(20, m.width-4)
+	title := m.theme.MutedStyle().Render(" Log (L:close) ")
+	rule := m.theme.TabGapStyle().Render("──") + title + m.theme.TabGapStyle().Render(strings.Repeat("─", max(0, width-2-lipgloss.Width(title))))
+
+	lines := make([]string, logPaneLines)
+	entries := applog.Recent(logPaneLines)
+	if len(entries) == 0 {
+		lines[0] = m.theme.MutedStyle().Render("Nothing logged yet: watcher errors, dropped events, skipped lines, and alert deliveries show here")
+	}
+	for i, e := range entries {
+		style := m.theme.MutedStyle()
+		if e.Level == applog.Warn {
+			style = m.theme.WarningStyle()
+		}
+		text := strings.ReplaceAll(e.Text, "\n", " ")
+		lines[i] = style.Render(truncateAnsi(m.theme, fmt.Sprintf("%s %-4s %s", e.Time.Format("15:04:05"), e.Level, text), width))
+	}
+	return rule + "\n" + strings.Join(lines, "\n")
+}
+
+// renderLogBadge counts warnings logged since the log pane was last open,
+// for the header; empty when there are none or the pane is open
+func (m Model) renderLogBadge() string {
+	if m.showLog {
+		return ""
+	}
+	n := applog.WarningsSince(m.logSeen)
+	if n == 0 {
+		return ""
+	}
+	return m.theme.WarningStyle().Render(fmt.Sprintf("%d %s (L)", n, pluralize(n, "warning")))
+}
@@ -1,37 +1,72 @@
 package tui
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/deeplink"
 	"cc_session_mon/internal/devagent"
+	"cc_session_mon/internal/digest"
+	"cc_session_mon/internal/gitstate"
+	"cc_session_mon/internal/remote"
+	"cc_session_mon/internal/security"
 	"cc_session_mon/internal/session"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // ViewMode represents the current view
 type ViewMode int
 
 const (
-	ViewSessions ViewMode = iota // Session list
-	ViewCommands                 // Command log for selected session
-	ViewPatterns                 // Unique patterns aggregation
+	ViewSessions  ViewMode = iota // Session list
+	ViewCommands                  // Command log for selected session
+	ViewPatterns                  // Unique patterns aggregation
+	ViewFindings                  // Security findings across all sessions
+	ViewAnalytics                 // Command leaderboard across all sessions
 )
 
 // ModelOptions configures Model creation
 type ModelOptions struct {
 	FollowDevagent bool
+
+	// Watcher, if set, is used instead of creating one (e.g. a replica fed by a share server)
+	Watcher *session.Watcher
+
+	// Label is appended to the header title (e.g. "[viewer]")
+	Label string
+
+	// Suspendable lets Ctrl+Z suspend the process to the shell. It stays off
+	// for the SSH server, where it would stop the server for every client.
+	Suspendable bool
+
+	// PendingAlerts, if set, reports alerts queued for delivery (e.g.
+	// alert.Engine.Pending) for the header's status bar
+	PendingAlerts func() int
+
+	// Focus, if set, is a ccmon:// link to open once its session is found
+	Focus *deeplink.Link
+
+	// Renderer, if set, is what styles are built with instead of lipgloss's
+	// default renderer for stdout: the SSH server's clients each have their
+	// own terminal, color profile, and background
+	Renderer *lipgloss.Renderer
 }
 
 // Model represents the application state
 type Model struct {
 	// Core state
+	theme     *Theme // Colors and styles, from the client's renderer
 	watcher   *session.Watcher
 	sessions  []*session.Session
 	activeIdx int // Currently selected session index
@@ -47,84 +82,151 @@ type Model struct {
 	commandDelegate *commandDelegate
 	patternDelegate *patternDelegate
 
+	// Findings view state
+	findingList        list.Model
+	findingCmdList     list.Model
+	findingDelegate    *findingDelegate
+	findingCmdDelegate *findingCommandDelegate
+	findings           []*security.FindingSummary
+	findingDrill       *security.FindingSummary // Finding whose commands are listed (nil shows the summary)
+
+	// Analytics view state
+	analyticsPage    analyticsPage // Page shown (a)
+	rankList         list.Model
+	rankDelegate     *commandRankDelegate
+	commandRanks     []*session.CommandRank
+	ranksByPattern   bool // Whether the leaderboard ranks patterns instead of raw commands
+	mixList          list.Model
+	mixDelegate      *toolMixDelegate
+	heatmap          *session.Heatmap // Commands per hour of the last heatmapDays days
+	hotspotList      list.Model
+	hotspotDelegate  *hotspotDelegate
+	categoryList     list.Model
+	categoryDelegate *categoryDelegate
+
 	// Aggregated patterns for active session
 	patterns           []*session.CommandPattern
 	patternListSession string // Session ID for which patterns are displayed
 
 	// Detail panel state
-	detailPanelOpen bool                  // Whether the detail panel is visible
-	selectedCommand *session.CommandEntry // Currently selected command for details
-	loadedInput     *session.ToolInput    // Lazily loaded input data
-	loadingDetail   bool                  // Loading state indicator
-	detailError     error                 // Error from loading details
-
-	// Path dialog state
-	showPathDialog bool // Whether the session path dialog is visible
+	detailPanelOpen  bool                  // Whether the detail panel is visible
+	selectedCommand  *session.CommandEntry // Currently selected command for details
+	loadedInput      *session.ToolInput    // Lazily loaded input data
+	loadingDetail    bool                  // Loading state indicator
+	detailError      error                 // Error from loading details
+	heredocsExpanded bool                  // Whether heredoc bodies are shown in full
+	thinkingShown    bool                  // Whether the reasoning before a command is shown in full
+	scriptRuns       []security.ScriptRun  // Session-written scripts run by the selected command
+	detailFocused    bool                  // Whether scrolling keys drive the detail panel instead of the list
+	detailScroll     int                   // Lines the detail panel is scrolled down
+	detailMaximized  bool                  // Whether the detail panel fills the content area
+	detailSpinner    spinner.Model         // Shown while the selected command's result is pending
+	detailCache      *detailCache          // Recently loaded and prefetched details, shared across model copies
+	attachmentPaths  []string              // Temp files the selected result's attachments were saved to
+
+	// Dialog state
+	showPathDialog   bool             // Whether the session path menu is visible
+	pathMenuIdx      int              // Selected path menu action
+	pathMenuStatus   string           // Outcome of the last path menu action
+	pathMenuErr      error            // Failure of the last path menu action
+	showSecretsPanel bool             // Whether the active session's secrets-touched panel is visible
+	confirmRemove    *session.Session // Session awaiting confirmation to delete or archive
+	showTouched      bool             // Whether the active session's touched paths tree is visible
+	touchedIdx       int              // Selected row of the touched paths tree
+	touchedCollapsed map[string]bool  // Collapsed directories of the touched paths tree, by path
+	showGitDiff      bool             // Whether the active session's change since its git snapshot is visible
+	gitDiff          *gitstate.Diff   // The change shown, nil while git runs
+	gitDiffErr       error            // Failure of the diff shown
+	gitDiffScroll    int              // Lines the diff dialog is scrolled down
+	notice           string           // Outcome of the last action, shown in place of the help until the next key
+	showAddDir       bool             // Whether the add-directory dialog (A) is open
+	addDirInput      textinput.Model  // Path typed into the add-directory dialog
+	addDirSave       bool             // Whether an added directory is saved to the config's projects_dirs
+	addDirErr        string           // Why the typed directory can't be watched
+
+	// Sessions view state
+	sessionsExpanded bool            // Whether each session shows its summary row
+	lineage          session.Lineage // Resumed sessions and the sessions they resume
+	chainHistory     bool            // Whether the Commands view combines the active session's resume chain
+	diskUsage        map[string]int  // Bytes on disk per session file path, from the last diskUsageCmd
+	sessionOrder     sessionOrder    // How the Sessions list is sorted (S)
+	blastScores      map[string]int  // Blast radius per session file path, while sorted by it
+	ownerFilter      string          // Owner whose sessions are shown (O); "" shows everyone's
+	scannedDirs      []scannedDir    // Projects directories checked when discovery found no sessions
+
+	// Git snapshots per session file path (git_snapshots), shared across
+	// model copies; an entry without a snapshot or error is being taken
+	gitSnapshots map[string]gitSnapshotMsg
 
 	// Search state
 	searchActive    bool            // Whether search bar is visible
 	searchFocused   bool            // Whether search input has keyboard focus
 	searchInput     textinput.Model // Text input component
+	searchHistory   searchHistory   // Recent queries, recalled with Up/Down
 	allCommandItems []list.Item     // Unfiltered command items for active session
+	outsideOnly     bool            // Show only writes outside the session's project
 
 	// UI dimensions
 	width  int
 	height int
 
-	// Error state
-	err error
+	// Error state: err is a startup failure shown in place of the UI; other
+	// errors are toasts
+	err      error
+	toasts   []toast
+	toastSeq int // Last toast id
+
+	// Problems panel (E): failures kept until retried, fixed, or dismissed
+	problems     []problem
+	problemIdx   int  // Selected problem
+	showProblems bool // Whether the panel is open
 
 	// Devagent support
 	followDevagent bool
+
+	// Extra header label (e.g. "[viewer]")
+	label string
+
+	// Whether Ctrl+Z suspends the process (off when serving over SSH)
+	suspendable bool
+
+	// Link given at startup, until its session is discovered
+	pendingLink *deeplink.Link
+
+	// Header status bar state
+	pendingAlerts    func() int // Alerts queued for delivery; nil when alerts aren't configured
+	watching         bool       // Whether the watcher has been started
+	watcherErrors    int        // Errors the watcher has reported
+	lastWatcherError time.Time
+
+	// Log pane state
+	showLog bool   // Whether the log pane (L) is open
+	logSeen uint64 // applog.Last() when the log pane was last open, for the header's warning count
 }
 
 // NewModel creates a new Model with initialized state
 func NewModel(opts ModelOptions) Model {
-	var projectsDirs []string
-	var watcher *session.Watcher
-	var err error
+	theme := loadTheme(config.Global().Theme, opts.Renderer)
 
 	// Create delegates
-	sessionDel := newSessionDelegate()
-	commandDel := newCommandDelegate()
-	patternDel := newPatternDelegate()
-
-	// Initialize based on devagent flag
-	if opts.FollowDevagent {
-		// Discover devagent environments and build projects dirs
-		envs, discoverErr := devagent.Discover()
-		if discoverErr != nil {
-			// Fall back to local if discovery fails
-			projectsDir := filepath.Join(os.Getenv("HOME"), ".claude", "projects")
-			projectsDirs = []string{projectsDir}
-			watcher, err = session.NewWatcher(projectsDirs)
-			if err == nil {
-				watcher.SetOrigin(projectsDir, "local")
-			}
-		} else {
-			// Build projects dirs from environments
-			for _, env := range envs {
-				projectsDirs = append(projectsDirs, env.ProjectsDir)
-			}
-			watcher, err = session.NewWatcher(projectsDirs)
-			if err == nil {
-				// Set origin labels for each environment
-				for _, env := range envs {
-					watcher.SetOrigin(env.ProjectsDir, "devagent:"+env.ContainerName)
-				}
-			}
-		}
-	} else {
-		// Local mode: use ~/.claude/projects
-		projectsDir := filepath.Join(os.Getenv("HOME"), ".claude", "projects")
-		projectsDirs = []string{projectsDir}
-		watcher, err = session.NewWatcher(projectsDirs)
-		if err == nil {
-			watcher.SetOrigin(projectsDir, "local")
-		}
+	sessionDel := newSessionDelegate(theme)
+	commandDel := newCommandDelegate(theme)
+	patternDel := newPatternDelegate(theme)
+	findingDel := newFindingDelegate(theme)
+	findingCmdDel := newFindingCommandDelegate(theme)
+	rankDel := newCommandRankDelegate(theme)
+	mixDel := newToolMixDelegate(theme)
+	hotspotDel := newHotspotDelegate(theme)
+	categoryDel := newCategoryDelegate(theme)
+
+	watcher := opts.Watcher
+	var err error
+	if watcher == nil {
+		watcher, err = NewWatcher(opts.FollowDevagent)
 	}
 
 	m := Model{
+		theme:           theme,
 		watcher:         watcher,
 		viewMode:        ViewSessions,
 		activeIdx:       0,
@@ -133,13 +235,34 @@ func NewModel(opts ModelOptions) Model {
 		commandDelegate: commandDel,
 		patternDelegate: patternDel,
 		followDevagent:  opts.FollowDevagent,
+		label:           opts.Label,
+		pendingAlerts:   opts.PendingAlerts,
+		suspendable:     opts.Suspendable,
+		pendingLink:     opts.Focus,
+
+		findingDelegate:    findingDel,
+		findingCmdDelegate: findingCmdDel,
+		rankDelegate:       rankDel,
+		mixDelegate:        mixDel,
+		hotspotDelegate:    hotspotDel,
+		categoryDelegate:   categoryDel,
 	}
 
+	m.detailCache = newDetailCache()
+	m.gitSnapshots = make(map[string]gitSnapshotMsg)
+	m.detailSpinner = spinner.New(spinner.WithSpinner(spinner.MiniDot), spinner.WithStyle(theme.MutedStyle()))
+
 	// Initialize search input
 	m.searchInput = textinput.New()
 	m.searchInput.Placeholder = "search commands..."
 	m.searchInput.Prompt = "/ "
 	m.searchInput.CharLimit = 200
+	historyPath := ""
+	if config.Global().PersistSearchHistory {
+		historyPath = searchHistoryPath()
+	}
+	m.searchHistory = loadSearchHistory(historyPath)
+	m.addDirInput = newAddDirInput()
 
 	// Initialize list components with delegates
 	m.sessionList = list.New([]list.Item{}, sessionDel, 0, 0)
@@ -163,15 +286,133 @@ func NewModel(opts ModelOptions) Model {
 	m.patternList.SetFilteringEnabled(false)
 	m.patternList.DisableQuitKeybindings()
 
-	return m
+	m.findingList = list.New([]list.Item{}, findingDel, 0, 0)
+	m.findingList.SetShowTitle(false)
+	m.findingList.SetShowHelp(false)
+	m.findingList.SetShowStatusBar(false)
+	m.findingList.SetFilteringEnabled(false)
+	m.findingList.DisableQuitKeybindings()
+
+	m.findingCmdList = list.New([]list.Item{}, findingCmdDel, 0, 0)
+	m.findingCmdList.SetShowTitle(false)
+	m.findingCmdList.SetShowHelp(false)
+	m.findingCmdList.SetShowStatusBar(false)
+	m.findingCmdList.SetFilteringEnabled(false)
+	m.findingCmdList.DisableQuitKeybindings()
+
+	m.rankList = list.New([]list.Item{}, rankDel, 0, 0)
+	m.rankList.SetShowTitle(false)
+	m.rankList.SetShowHelp(false)
+	m.rankList.SetShowStatusBar(false)
+	m.rankList.SetFilteringEnabled(false)
+	m.rankList.DisableQuitKeybindings()
+
+	m.mixList = list.New([]list.Item{}, mixDel, 0, 0)
+	m.mixList.SetShowTitle(false)
+	m.mixList.SetShowHelp(false)
+	m.mixList.SetShowStatusBar(false)
+	m.mixList.SetFilteringEnabled(false)
+	m.mixList.DisableQuitKeybindings()
+
+	m.hotspotList = list.New([]list.Item{}, hotspotDel, 0, 0)
+	m.hotspotList.SetShowTitle(false)
+	m.hotspotList.SetShowHelp(false)
+	m.hotspotList.SetShowStatusBar(false)
+	m.hotspotList.SetFilteringEnabled(false)
+	m.hotspotList.DisableQuitKeybindings()
+
+	m.categoryList = list.New([]list.Item{}, categoryDel, 0, 0)
+	m.categoryList.SetShowTitle(false)
+	m.categoryList.SetShowHelp(false)
+	m.categoryList.SetShowStatusBar(false)
+	m.categoryList.SetFilteringEnabled(false)
+	m.categoryList.DisableQuitKeybindings()
+
+	return m.applyLayout(true)
+}
+
+// NewWatcher creates a session watcher for the local projects directory, or for
+// all discovered devagent environments when followDevagent is set. If devagent
+// discovery fails, it falls back to local-only monitoring. The config's
+// projects_dirs, the projects directories of homes matching config homes
+// globs, and those of the users in shared_dirs are watched too, the latter
+// two labeled with their owner. Each remote_sources entry is mirrored into a
+// local cache that is watched like them, fetched in the background until
+// the watcher stops.
+func NewWatcher(followDevagent bool) (*session.Watcher, error) {
+	watcher, err := newBaseWatcher(followDevagent)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range session.ExpandProjectsDirs(config.Global().ProjectsDirs) {
+		watcher.AddProjectsDir(dir)
+	}
+	for _, h := range session.FindHomeProjects(config.Global().Homes) {
+		if watcher.AddProjectsDir(h.Dir) {
+			watcher.SetOrigin(h.Dir, h.Origin())
+		}
+	}
+	for _, s := range session.FindSharedProjects(config.Global().SharedDirs) {
+		if watcher.AddProjectsDir(s.Dir) {
+			watcher.SetOrigin(s.Dir, s.Origin())
+		}
+	}
+	for _, src := range config.Global().RemoteSources {
+		mirror, err := remote.New(src)
+		if err != nil {
+			return nil, err
+		}
+		if watcher.AddProjectsDir(mirror.Dir()) {
+			watcher.SetOrigin(mirror.Dir(), mirror.Origin())
+			go mirror.Follow(watcher)
+		}
+	}
+	return watcher, nil
+}
+
+// newBaseWatcher creates the local or devagent watcher NewWatcher extends
+func newBaseWatcher(followDevagent bool) (*session.Watcher, error) {
+	// UserHomeDir rather than $HOME, which Windows doesn't set
+	home, _ := os.UserHomeDir()
+	localDir := filepath.Join(home, ".claude", "projects")
+
+	if followDevagent {
+		// Discover devagent environments and build projects dirs
+		if envs, err := devagent.Discover(); err == nil {
+			projectsDirs := make([]string, 0, len(envs))
+			for _, env := range envs {
+				projectsDirs = append(projectsDirs, env.ProjectsDir)
+			}
+			watcher, err := session.NewWatcher(projectsDirs)
+			if err != nil {
+				return nil, err
+			}
+			// Set origin labels for each environment
+			for _, env := range envs {
+				watcher.SetOrigin(env.ProjectsDir, "devagent:"+env.ContainerName)
+			}
+			return watcher, nil
+		}
+		// Fall back to local if discovery fails
+	}
+
+	// Local mode: use ~/.claude/projects
+	watcher, err := session.NewWatcher([]string{localDir})
+	if err != nil {
+		return nil, err
+	}
+	watcher.SetOrigin(localDir, "local")
+	return watcher, nil
 }
 
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
-		m.discoverSessionsCmd(),
-		m.tickCmd(),
-	)
+	cmds := []tea.Cmd{m.discoverSessionsCmd(), m.tickCmd(), m.subagentScanTickCmd()}
+	if m.followDevagent {
+		// Listed now as well, so a missing devagent is a problem from the start
+		cmds = append(cmds, m.devagentTickCmd(), m.devagentRefreshCmd())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Message types
@@ -179,19 +420,37 @@ type (
 	sessionsDiscoveredMsg []*session.Session
 	sessionEventMsg       session.WatchEvent
 	tickMsg               time.Time
-	errMsg                struct{ error }    // General error
-	detailLoadedMsg       *session.ToolInput // Tool input loaded successfully
-	detailErrorMsg        struct{ error }    // Error loading tool input
+	devagentTickMsg       time.Time
+	subagentScanTickMsg   time.Time
+	errMsg                struct{ error }      // General error
+	watcherErrorMsg       struct{ error }      // Error reported by the running watcher
+	detailErrorMsg        struct{ error }      // Error loading tool input
+	detailPollMsg         struct{ key string } // Time to reload a command whose result is pending
 	devagentRefreshMsg    struct {
 		envs []devagent.Environment
 	}
+	// projectsDirsMsg carries the configured projects_dirs, expanded, and
+	// the users' projects directories in shared_dirs
+	projectsDirsMsg struct {
+		dirs   []string
+		shared []session.SharedProjects
+	}
+	// detailLoadedMsg carries tool input loaded successfully, plus scripts
+	// the command runs that were written earlier in the session
+	detailLoadedMsg struct {
+		key        string // detailKey of the command
+		input      *session.ToolInput
+		scriptRuns []security.ScriptRun
+	}
+	// detailPrefetchedMsg carries details loaded ahead of the selection
+	detailPrefetchedMsg detailLoadedMsg
 )
 
 // discoverSessionsCmd discovers existing sessions
 func (m Model) discoverSessionsCmd() tea.Cmd {
 	return func() tea.Msg {
 		if m.watcher == nil {
-			return errMsg{m.err}
+			return nil // m.err already says why
 		}
 		sessions, err := m.watcher.DiscoverSessions()
 		if err != nil {
@@ -211,45 +470,155 @@ func (m Model) watchSessionsCmd() tea.Cmd {
 		case event := <-m.watcher.Events:
 			return sessionEventMsg(event)
 		case err := <-m.watcher.Errors:
-			return errMsg{err}
+			return watcherErrorMsg{err}
 		}
 	}
 }
 
-// tickCmd returns a command that ticks every 30 seconds to refresh timestamps
+// tickCmd returns a command that ticks every refresh interval to refresh
+// activity status and timestamps
 func (m Model) tickCmd() tea.Cmd {
-	return tea.Tick(30*time.Second, func(t time.Time) tea.Msg {
+	return tea.Tick(config.Global().RefreshInterval, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
+// devagentTickCmd returns a command that ticks every devagent poll interval
+// to look for new containers
+func (m Model) devagentTickCmd() tea.Cmd {
+	return tea.Tick(config.Global().DevagentPollInterval, func(t time.Time) tea.Msg {
+		return devagentTickMsg(t)
+	})
+}
+
+// subagentScanTickCmd returns a command that ticks every subagent scan
+// interval to look for subagent transcripts the file watcher missed
+func (m Model) subagentScanTickCmd() tea.Cmd {
+	return tea.Tick(config.Global().SubagentScanInterval, func(t time.Time) tea.Msg {
+		return subagentScanTickMsg(t)
+	})
+}
+
+// subagentScanCmd scans tracked sessions for new subagent transcripts in the
+// background; what it finds arrives as ordinary watcher events
+func (m Model) subagentScanCmd() tea.Cmd {
+	return func() tea.Msg {
+		if m.watcher != nil {
+			m.watcher.ScanForNewSubagents()
+		}
+		return nil
+	}
+}
+
+// suspendCmd suspends the program to the shell, if allowed; the terminal is
+// restored and a tea.ResumeMsg sent when it is resumed with fg
+func (m Model) suspendCmd() tea.Cmd {
+	if !m.suspendable {
+		return nil
+	}
+	return tea.Suspend
+}
+
+// catchUpCmd reads session changes whose file events may have been lost
+// while the program was suspended; they arrive as ordinary watcher events
+func (m Model) catchUpCmd() tea.Cmd {
+	return func() tea.Msg {
+		if m.watcher != nil {
+			m.watcher.CatchUp()
+		}
+		return nil
+	}
+}
+
 // devagentRefreshCmd discovers devagent environments and returns a refresh message
 func (m Model) devagentRefreshCmd() tea.Cmd {
 	return func() tea.Msg {
 		envs, err := devagent.Discover()
 		if err != nil {
-			return errMsg{err}
+			return problemMsg{problem{key: "devagent", text: "devagent: " + err.Error(), retry: retryDevagent}}
 		}
 		return devagentRefreshMsg{envs: envs}
 	}
 }
 
-// loadDetailCmd asynchronously loads tool input for a command
+// projectsDirsCmd expands the configured projects_dirs again, so globs pick
+// up directories created since the last tick, and lists shared_dirs again
+// for users who started syncing
+func (m Model) projectsDirsCmd() tea.Cmd {
+	entries, shared := config.Global().ProjectsDirs, config.Global().SharedDirs
+	if m.watcher == nil || len(entries)+len(shared) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		return projectsDirsMsg{
+			dirs:   session.ExpandProjectsDirs(entries),
+			shared: session.FindSharedProjects(shared),
+		}
+	}
+}
+
+// loadDetailCmd asynchronously loads tool input for a command. For Bash
+// commands it also checks whether they run scripts the session wrote earlier.
 func (m Model) loadDetailCmd(cmd session.CommandEntry) tea.Cmd {
+	var commands []session.CommandEntry
+	if sess := m.ActiveSession(); sess != nil {
+		commands = sess.Commands
+	}
+
 	return func() tea.Msg {
 		input, err := session.FetchToolInput(cmd.FilePath, cmd.LineNumber, cmd.ToolName, cmd.UUID)
 		if err != nil {
 			return detailErrorMsg{err}
 		}
-		return detailLoadedMsg(input)
+		msg := detailLoadedMsg{key: detailKey(cmd), input: input}
+		if cmd.ToolName == "Bash" {
+			command, _ := input.Parsed["command"].(string)
+			msg.scriptRuns = security.WrittenScriptRuns(command, input.CWD, cmd.Timestamp, commands)
+		}
+		return msg
+	}
+}
+
+// detailPollInterval is how often a command whose result is pending is reloaded
+const detailPollInterval = time.Second
+
+// detailPollCmd schedules reloading the selected command's details
+func (m Model) detailPollCmd() tea.Cmd {
+	key := detailKey(*m.selectedCommand)
+	return tea.Tick(detailPollInterval, func(time.Time) tea.Msg {
+		return detailPollMsg{key: key}
+	})
+}
+
+// resultPending reports whether the detail panel shows a command whose
+// tool_result hasn't been written yet (or whose background shell is still
+// running), in a session that may still write it
+func (m Model) resultPending() bool {
+	if !m.detailPanelOpen || m.loadedInput == nil || m.loadedInput.Settled() {
+		return false
 	}
+	sess := m.ActiveSession()
+	return sess != nil && sess.IsActive
 }
 
 // updateSessionList rebuilds the session list items
 func (m Model) updateSessionList() Model {
+	m.lineage = session.NewLineage(m.sessions)
 	items := make([]list.Item, len(m.sessions))
 	for i, s := range m.sessions {
-		items[i] = sessionItem{session: s}
+		item := sessionItem{session: s, size: m.sessionUsage(s), owner: sessionOwner(s), blast: -1}
+		if score, ok := m.blastScores[s.FilePath]; ok {
+			item.blast = score
+		}
+		if chain := m.lineage.Chain(s); len(chain) > 1 {
+			item.chainPos = slices.Index(chain, s) + 1
+			item.chainLen = len(chain)
+		}
+		if m.sessionsExpanded {
+			item.summary = digest.Summarize(s, nil).String()
+			item.timeline = session.SessionTimeline(s)
+		}
+		items[i] = item
 	}
 	m.sessionList.SetItems(items)
 	return m
@@ -264,31 +633,64 @@ func (m Model) updateCommandList() Model {
 	}
 
 	sess := m.sessions[m.activeIdx]
+	commands := sess.Commands
+	markers := slices.Clone(sess.Markers)
+	title := "Commands - " + filepath.Base(sess.ProjectPath)
+
+	// Optionally the whole resume chain's history, without the copies
+	// resumed sessions hold of earlier commands
+	if chain := m.lineage.Chain(sess); m.chainHistory && len(chain) > 1 {
+		commands = session.ChainCommands(chain)
+		markers = nil
+		for _, s := range chain {
+			markers = append(markers, s.Markers...)
+		}
+		title += fmt.Sprintf(" (%d resumed sessions)", len(chain))
+	}
 
 	// Remember if user was at the top (following tail)
 	wasAtTop := m.commandList.Index() == 0
 	previousCount := len(m.commandList.Items())
 
 	// Create sorted indices instead of copying the full slice
-	indices := make([]int, len(sess.Commands))
+	indices := make([]int, len(commands))
 	for i := range indices {
 		indices[i] = i
 	}
 	sort.Slice(indices, func(i, j int) bool {
-		return sess.Commands[indices[i]].Timestamp.After(sess.Commands[indices[j]].Timestamp)
+		return commands[indices[i]].Timestamp.After(commands[indices[j]].Timestamp)
+	})
+
+	// Compaction and restart markers become dividers above the commands
+	// that came after them (newest first, like the commands)
+	sort.SliceStable(markers, func(i, j int) bool {
+		return markers[i].Timestamp.After(markers[j].Timestamp)
 	})
 
 	// Build items using sorted indices, avoiding struct copy in range
-	items := make([]list.Item, len(indices))
-	for i, idx := range indices {
-		items[i] = commandItem{command: sess.Commands[idx]}
+	cfg := config.ForProject(sess.ProjectPath)
+	items := make([]list.Item, 0, len(indices)+len(markers))
+	for _, idx := range indices {
+		c := commands[idx]
+		for len(markers) > 0 && markers[0].Timestamp.After(c.Timestamp) {
+			items = append(items, markerItem{marker: markers[0]})
+			markers = markers[1:]
+		}
+		items = append(items, commandItem{
+			command: c,
+			cfg:     cfg,
+			outside: security.OutsideProjectWrites(c.ToolName, c.RawCommand, c.CWD, sess.ProjectPath, cfg.Security.AllowedWritePaths),
+		})
+	}
+	for _, mk := range markers {
+		items = append(items, markerItem{marker: mk})
 	}
 
 	// Store unfiltered items and apply search filter
 	m.allCommandItems = items
 	m = m.applySearchFilter()
 
-	m.commandList.Title = "Commands - " + filepath.Base(sess.ProjectPath)
+	m.commandList.Title = title
 
 	// Only auto-scroll to top if user was already at top, or this is initial load
 	if wasAtTop || previousCount == 0 {
@@ -298,9 +700,15 @@ func (m Model) updateCommandList() Model {
 	return m
 }
 
-// applySearchFilter filters allCommandItems by search text and sets commandList items.
+// applySearchFilter filters allCommandItems by search text and the
+// outside-project toggle, and sets commandList items.
 func (m Model) applySearchFilter() Model {
-	if !m.searchActive || m.searchInput.Value() == "" {
+	searching := m.searchActive && m.searchInput.Value() != ""
+	m.commandDelegate.highlight = ""
+	if searching {
+		m.commandDelegate.highlight = m.searchInput.Value()
+	}
+	if !searching && !m.outsideOnly {
 		m.commandList.SetItems(m.allCommandItems)
 		return m
 	}
@@ -308,10 +716,12 @@ func (m Model) applySearchFilter() Model {
 	text := strings.ToLower(m.searchInput.Value())
 	filtered := make([]list.Item, 0, len(m.allCommandItems))
 	for _, item := range m.allCommandItems {
-		if ci, ok := item.(commandItem); ok {
-			if strings.Contains(strings.ToLower(ci.command.RawCommand), text) {
-				filtered = append(filtered, item)
-			}
+		ci, ok := item.(commandItem)
+		if !ok || (m.outsideOnly && len(ci.outside) == 0) {
+			continue
+		}
+		if !searching || strings.Contains(strings.ToLower(ci.command.RawCommand), text) {
+			filtered = append(filtered, item)
 		}
 	}
 	m.commandList.SetItems(filtered)
@@ -320,8 +730,6 @@ func (m Model) applySearchFilter() Model {
 
 // aggregatePatterns builds the unique patterns for the active session
 func (m Model) aggregatePatterns() Model {
-	patternMap := make(map[string]*session.CommandPattern)
-
 	sess := m.ActiveSession()
 	if sess == nil {
 		m.patterns = nil
@@ -338,51 +746,13 @@ func (m Model) aggregatePatterns() Model {
 	wasAtTop := m.patternList.Index() == 0
 	previousCount := len(m.patternList.Items())
 
-	// Use a map per pattern to track unique examples (O(1) lookup instead of O(n))
-	exampleSets := make(map[string]map[string]struct{})
-
-	for i := range sess.Commands {
-		cmd := &sess.Commands[i] // Use pointer to avoid copying 128-byte struct
-
-		if p, exists := patternMap[cmd.Pattern]; exists {
-			p.Count++
-			if cmd.Timestamp.After(p.LastSeen) {
-				p.LastSeen = cmd.Timestamp
-			}
-			// Use set for O(1) duplicate check
-			if len(p.Examples) < 5 {
-				exSet := exampleSets[cmd.Pattern]
-				if _, seen := exSet[cmd.RawCommand]; !seen {
-					exSet[cmd.RawCommand] = struct{}{}
-					p.Examples = append(p.Examples, cmd.RawCommand)
-				}
-			}
-		} else {
-			patternMap[cmd.Pattern] = &session.CommandPattern{
-				Pattern:  cmd.Pattern,
-				ToolName: cmd.ToolName,
-				Count:    1,
-				LastSeen: cmd.Timestamp,
-				Examples: []string{cmd.RawCommand},
-			}
-			// Initialize example set for this pattern
-			exampleSets[cmd.Pattern] = map[string]struct{}{cmd.RawCommand: {}}
-		}
-	}
-
-	// Convert to slice and sort by count
-	m.patterns = make([]*session.CommandPattern, 0, len(patternMap))
-	for _, p := range patternMap {
-		m.patterns = append(m.patterns, p)
-	}
-	sort.Slice(m.patterns, func(i, j int) bool {
-		return m.patterns[i].Count > m.patterns[j].Count
-	})
+	m.patterns = session.AggregatePatterns(sess.Commands)
 
 	// Update pattern list
+	cfg := config.ForProject(sess.ProjectPath)
 	items := make([]list.Item, len(m.patterns))
 	for i, p := range m.patterns {
-		items[i] = patternItem{pattern: p}
+		items[i] = patternItem{pattern: p, cfg: cfg}
 	}
 	m.patternList.SetItems(items)
 	m.patternList.Title = "Patterns - " + filepath.Base(sess.ProjectPath)
@@ -398,7 +768,7 @@ func (m Model) aggregatePatterns() Model {
 // updateListSizes updates list dimensions based on terminal size
 func (m Model) updateListSizes() Model {
 	// Reserve space for header (2), tabs (2), column headers (1), help (2), margins (2)
-	listHeight := m.height - 9
+	listHeight := m.height - 9 - m.logPaneHeight()
 	if listHeight < 5 {
 		listHeight = 5
 	}
@@ -422,14 +792,32 @@ func (m Model) updateListSizes() Model {
 		commandListWidth = int(float64(listWidth) * 0.58)
 	}
 
+	// Session list width is reduced when the preview pane is shown
+	sessionListWidth := listWidth
+	if m.showPreview() {
+		sessionListWidth, _ = m.previewLayout()
+	}
+
 	// Update delegate widths
-	m.sessionDelegate.SetWidth(listWidth)
+	m.sessionDelegate.SetWidth(sessionListWidth)
 	m.commandDelegate.SetWidth(commandListWidth)
 	m.patternDelegate.SetWidth(listWidth)
-
-	m.sessionList.SetSize(listWidth, listHeight)
+	m.findingDelegate.SetWidth(listWidth)
+	m.findingCmdDelegate.SetWidth(listWidth)
+	m.rankDelegate.SetWidth(listWidth)
+	m.mixDelegate.SetWidth(listWidth)
+	m.hotspotDelegate.SetWidth(listWidth)
+	m.categoryDelegate.SetWidth(listWidth)
+
+	m.sessionList.SetSize(sessionListWidth, listHeight)
 	m.commandList.SetSize(commandListWidth, commandListHeight)
 	m.patternList.SetSize(listWidth, listHeight)
+	m.findingList.SetSize(listWidth, listHeight)
+	m.findingCmdList.SetSize(listWidth, listHeight)
+	m.rankList.SetSize(listWidth, listHeight)
+	m.mixList.SetSize(listWidth, listHeight)
+	m.hotspotList.SetSize(listWidth, listHeight)
+	m.categoryList.SetSize(listWidth, listHeight)
 
 	return m
 }
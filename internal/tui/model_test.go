@@ -1,15 +1,36 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"cc_session_mon/internal/applog"
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/deeplink"
 	"cc_session_mon/internal/session"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
 )
 
+// testTheme returns the configured theme rendering for stdout, for testing
+// formatters outside a Model
+func testTheme() *Theme {
+	return loadTheme(config.Global().Theme, nil)
+}
+
 // newTestModelWithSessions creates a Model with pre-populated sessions for testing.
 // Each session has commands with known RawCommand values for predictable filtering.
 func newTestModelWithSessions() Model {
@@ -82,6 +103,20 @@ func TestViewModeCycleRight(t *testing.T) {
 		t.Errorf("expected view mode to be ViewPatterns after 'l', got %d", model.viewMode)
 	}
 
+	// Press 'l' again to go to Findings
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	model = updated.(Model)
+	if model.viewMode != ViewFindings {
+		t.Errorf("expected view mode to be ViewFindings after 'l', got %d", model.viewMode)
+	}
+
+	// Press 'l' again to go to Analytics
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	model = updated.(Model)
+	if model.viewMode != ViewAnalytics {
+		t.Errorf("expected view mode to be ViewAnalytics after 'l', got %d", model.viewMode)
+	}
+
 	// Press 'l' again to wrap back to Sessions
 	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
 	model = updated.(Model)
@@ -100,9 +135,23 @@ func TestViewModeCycleLeft(t *testing.T) {
 		t.Fatalf("expected initial view mode to be ViewSessions")
 	}
 
-	// Press 'h' to go to Patterns (wrapping backwards)
+	// Press 'h' to go to Analytics (wrapping backwards)
 	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
 	model := updated.(Model)
+	if model.viewMode != ViewAnalytics {
+		t.Errorf("expected view mode to be ViewAnalytics after 'h', got %d", model.viewMode)
+	}
+
+	// Press 'h' again to go to Findings
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	model = updated.(Model)
+	if model.viewMode != ViewFindings {
+		t.Errorf("expected view mode to be ViewFindings after 'h', got %d", model.viewMode)
+	}
+
+	// Press 'h' again to go to Patterns
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	model = updated.(Model)
 	if model.viewMode != ViewPatterns {
 		t.Errorf("expected view mode to be ViewPatterns after 'h', got %d", model.viewMode)
 	}
@@ -510,6 +559,251 @@ func TestUnfocusedSearchAllowsListNavigation(t *testing.T) {
 	}
 }
 
+func TestSearchHistoryRecall(t *testing.T) {
+	m := NewModel(ModelOptions{})
+	m.width = 80
+	m.height = 24
+	m.viewMode = ViewCommands
+	m.allCommandItems = testCommandItems()
+	m.searchActive = true
+
+	key := func(m Model, k tea.KeyType) Model {
+		updated, _ := m.Update(tea.KeyMsg{Type: k})
+		return updated.(Model)
+	}
+	// Searching "git", then "ls", then "git" again leaves git newest, once
+	for _, q := range []string{"git", "ls", "git"} {
+		m.searchFocused = true
+		m.searchInput.SetValue(q)
+		m = key(m, tea.KeyEnter)
+	}
+	if len(m.searchHistory.queries) != 2 || m.searchHistory.queries[0] != "git" {
+		t.Fatalf("expected history [git ls], got %v", m.searchHistory.queries)
+	}
+
+	m.searchFocused = true
+	m.searchInput.SetValue("draft")
+	m = key(m, tea.KeyUp)
+	m = key(m, tea.KeyUp)
+	if m.searchInput.Value() != "ls" {
+		t.Errorf("expected Up twice to recall ls, got %q", m.searchInput.Value())
+	}
+	if got := len(m.commandList.Items()); got != 1 {
+		t.Errorf("expected the recalled query to filter to 1 item, got %d", got)
+	}
+	m = key(m, tea.KeyUp) // Already at the oldest
+	m = key(m, tea.KeyDown)
+	m = key(m, tea.KeyDown)
+	if m.searchInput.Value() != "draft" {
+		t.Errorf("expected Down past the newest to restore the draft, got %q", m.searchInput.Value())
+	}
+}
+
+func TestSearchMatchNavigation(t *testing.T) {
+	m := NewModel(ModelOptions{})
+	m.width = 80
+	m.height = 24
+	m.viewMode = ViewCommands
+	m.allCommandItems = testCommandItems()
+	m.searchActive = true
+	m.searchInput.SetValue("go")
+	m = m.applySearchFilter()
+
+	if got := m.searchMatchStatus(); got != "2 matches (1/2)" {
+		t.Errorf("searchMatchStatus() = %q", got)
+	}
+	key := func(m Model, k string) Model {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		return updated.(Model)
+	}
+	m = key(m, "n")
+	if got := m.searchMatchStatus(); got != "2 matches (2/2)" {
+		t.Errorf("after n, searchMatchStatus() = %q", got)
+	}
+	m = key(m, "n") // Wraps to the first match
+	m = key(m, "N") // And back to the last
+	if got := m.commandList.Index(); got != 1 {
+		t.Errorf("expected n then N to wrap back to match 2, got index %d", got)
+	}
+
+	m.searchInput.SetValue("nonexistent")
+	m = m.applySearchFilter()
+	if got := m.searchMatchStatus(); got != "no matches" {
+		t.Errorf("searchMatchStatus() = %q", got)
+	}
+}
+
+func TestDetailPanelFocusAndScroll(t *testing.T) {
+	m := newTestModelWithSessions()
+	m = m.updateCommandList()
+	key := func(m Model, k tea.KeyMsg) Model {
+		updated, _ := m.Update(k)
+		return updated.(Model)
+	}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+
+	input := &session.ToolInput{ToolName: "Bash", Parsed: map[string]interface{}{
+		"command": "echo start\n" + strings.Repeat("echo line\n", 100),
+	}}
+	m = key(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m.loadingDetail = false
+	m.loadedInput = input
+	if m.maxDetailScroll() == 0 {
+		t.Fatal("expected the long command to overflow the detail panel")
+	}
+
+	// Unfocused, down moves the list selection
+	m = key(m, down)
+	if m.commandList.Index() != 1 || m.detailScroll != 0 {
+		t.Fatalf("expected down to move the list, got index %d scroll %d", m.commandList.Index(), m.detailScroll)
+	}
+	m = key(m, tea.KeyMsg{Type: tea.KeyUp})
+	m.loadingDetail = false
+	m.loadedInput = input
+
+	m = key(m, tea.KeyMsg{Type: tea.KeyRight})
+	if !m.detailFocused {
+		t.Fatal("expected right to focus the detail panel")
+	}
+	m = key(m, down)
+	m = key(m, down)
+	if m.commandList.Index() != 0 || m.detailScroll != 2 {
+		t.Errorf("expected down to scroll the panel, got index %d scroll %d", m.commandList.Index(), m.detailScroll)
+	}
+	if !strings.Contains(m.View(), "(3-") {
+		t.Error("expected the panel header to show the scrolled position")
+	}
+	m = key(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if m.detailScroll != m.maxDetailScroll() {
+		t.Errorf("expected G to scroll to the bottom, got %d of %d", m.detailScroll, m.maxDetailScroll())
+	}
+	m = key(m, down)
+	if m.detailScroll != m.maxDetailScroll() {
+		t.Error("expected scrolling to stop at the bottom")
+	}
+
+	m = key(m, tea.KeyMsg{Type: tea.KeyLeft})
+	if m.detailFocused || m.viewMode != ViewCommands {
+		t.Error("expected left to return focus to the list without switching view")
+	}
+	m = key(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.detailPanelOpen || m.detailScroll != 0 {
+		t.Error("expected esc to close the panel and reset its scroll")
+	}
+}
+
+func TestDetailPanelMaximize(t *testing.T) {
+	m := newTestModelWithSessions()
+	m = m.updateCommandList()
+	key := func(m Model, k tea.KeyMsg) Model {
+		updated, _ := m.Update(k)
+		return updated.(Model)
+	}
+	z := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")}
+
+	m = key(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m.loadingDetail = false
+	m.loadedInput = &session.ToolInput{ToolName: "Bash", Parsed: map[string]interface{}{"command": "git status"}}
+	split := m.maxDetailScroll()
+
+	m = key(m, z)
+	if !m.detailMaximized || !m.detailFocused {
+		t.Fatal("expected z to maximize and focus the detail panel")
+	}
+	if listWidth, detailWidth, _ := m.splitLayout(); listWidth != 0 || detailWidth != m.width-4 {
+		t.Errorf("expected the panel to take the full width, got list %d detail %d", listWidth, detailWidth)
+	}
+	if view := m.View(); strings.Contains(view, "Group") || !strings.Contains(view, "Command Details") {
+		t.Errorf("expected the maximized view to show the panel without the list:\n%s", view)
+	}
+	if m.maxDetailScroll() > split {
+		t.Error("expected the wider panel to need no more scrolling than the split one")
+	}
+
+	m = key(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.detailMaximized || !m.detailPanelOpen {
+		t.Error("expected esc to return to the split view with the panel still open")
+	}
+}
+
+func TestDetailPanelWaitsForPendingResult(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.sessions[0].IsActive = true
+	m.sessions[1].IsActive = false
+	m.sessions[0].Commands[0].UUID = "uuid-1"
+	m = m.updateCommandList()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+
+	key := detailKey(*m.selectedCommand)
+	input := &session.ToolInput{ToolName: "Bash", Parsed: map[string]interface{}{"command": "sleep 60"}}
+	updated, cmd := m.Update(detailLoadedMsg{key: key, input: input})
+	m = updated.(Model)
+	if cmd == nil || !strings.Contains(m.View(), "running...") {
+		t.Fatal("expected a pending result to show a spinner and schedule a reload")
+	}
+	if _, cmd := m.Update(detailPollMsg{key: key}); cmd == nil {
+		t.Error("expected a poll to reload the pending command")
+	}
+
+	// A late load for another command is ignored
+	updated, _ = m.Update(detailLoadedMsg{key: "other", input: &session.ToolInput{HasResult: true}})
+	m = updated.(Model)
+	if m.loadedInput != input {
+		t.Error("expected a load for another command to be dropped")
+	}
+
+	done := &session.ToolInput{ToolName: "Bash", Parsed: input.Parsed, Result: "done", HasResult: true}
+	updated, _ = m.Update(detailLoadedMsg{key: key, input: done})
+	m = updated.(Model)
+	if view := m.View(); strings.Contains(view, "running...") || !strings.Contains(view, "done") {
+		t.Error("expected the output to replace the spinner once the result lands")
+	}
+	if _, cmd := m.Update(detailPollMsg{key: key}); cmd != nil {
+		t.Error("expected no reload once the result has landed")
+	}
+}
+
+func TestDetailCache(t *testing.T) {
+	c := newDetailCache()
+	for i := 0; i <= detailCacheSize; i++ {
+		if i == detailCacheSize {
+			c.get("0") // Recently used, so "1" is evicted instead
+		}
+		c.put(detailLoadedMsg{key: fmt.Sprint(i), input: &session.ToolInput{HasResult: true}})
+	}
+	c.put(detailLoadedMsg{key: "pending", input: &session.ToolInput{}})
+	if _, ok := c.get("pending"); ok {
+		t.Error("expected a pending result not to be cached")
+	}
+	if _, ok := c.get("1"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if _, ok := c.get("0"); !ok {
+		t.Error("expected a recently used entry to stay cached")
+	}
+}
+
+func TestDetailPanelUsesCachedDetails(t *testing.T) {
+	m := newTestModelWithSessions()
+	m = m.updateCommandList()
+	items := m.commandList.Items()
+	second := items[1].(commandItem).command
+	cached := &session.ToolInput{ToolName: second.ToolName, Result: "ok", HasResult: true}
+	m.detailCache.put(detailLoadedMsg{key: detailKey(second), input: cached})
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if !m.loadingDetail {
+		t.Fatal("expected an uncached command to load")
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(Model)
+	if m.loadingDetail || m.loadedInput != cached {
+		t.Error("expected moving to a cached command to show it without loading")
+	}
+}
+
 func TestUpdateCommandListAppliesFilter(t *testing.T) {
 	// AC3.4: After calling updateCommandList (simulating new commands arriving),
 	// the filter is still applied
@@ -735,3 +1029,1157 @@ func TestFilterReappliedOnNewCommands(t *testing.T) {
 	}
 }
 
+func TestOutsideOnlyFilter(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.activeIdx = 1 // session-2 writes /path/to/new.go, outside /projects/beta
+	m = m.updateCommandList()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	model := updated.(Model)
+	if !model.outsideOnly {
+		t.Fatal("expected 'o' to enable the outside-project filter")
+	}
+	items := model.commandList.Items()
+	if len(items) != 1 || items[0].(commandItem).command.RawCommand != "/path/to/new.go" {
+		t.Errorf("expected only the outside-project Write, got %d items", len(items))
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	model = updated.(Model)
+	if got := len(model.commandList.Items()); got != 3 {
+		t.Errorf("expected all 3 commands after toggling off, got %d", got)
+	}
+}
+
+func TestSearchHighlightsMatches(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI256)
+
+	got := highlightMatches(testTheme(), "git status && GIT STATUS", "status", testTheme().MutedStyle())
+	if plain := ansi.Strip(got); plain != "git status && GIT STATUS" {
+		t.Errorf("highlighting changed the text: %q", plain)
+	}
+	match := testTheme().SearchMatchStyle().Render("status")
+	if !strings.Contains(got, match) || !strings.Contains(got, testTheme().SearchMatchStyle().Render("STATUS")) {
+		t.Errorf("expected both matches highlighted, got %q", got)
+	}
+
+	m := newTestModelWithSessions()
+	m.searchActive = true
+	m.searchInput.SetValue("status")
+	m = m.applySearchFilter()
+	if m.commandDelegate.highlight != "status" || !strings.Contains(m.commandList.View(), match) {
+		t.Error("expected the matching command row to highlight the query")
+	}
+	m.searchActive = false
+	if m = m.applySearchFilter(); m.commandDelegate.highlight != "" {
+		t.Error("expected closing the search to clear the highlight")
+	}
+}
+
+func TestSessionSummariesToggle(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	m = m.updateSessionList()
+	m = m.updateListSizes()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	model := updated.(Model)
+	if !model.sessionsExpanded || model.sessionDelegate.Height() != 2 {
+		t.Fatal("expected 'e' to expand the sessions view")
+	}
+	if view := model.View(); !strings.Contains(view, "2m · 3 cmds: mostly git") {
+		t.Errorf("expected a summary row for session-2, got:\n%s", view)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	if model = updated.(Model); model.sessionDelegate.Height() != 1 || strings.Contains(model.View(), "3 cmds:") {
+		t.Error("expected 'e' again to collapse the summaries")
+	}
+}
+
+func TestSecretsPanelOpensAndDismisses(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.sessions[0].Commands = append(m.sessions[0].Commands,
+		session.CommandEntry{ToolName: "Bash", RawCommand: "echo $GITHUB_TOKEN", Pattern: "Bash(echo:*)", Timestamp: time.Now()})
+	m = m.updateListSizes() // Full-height background for the overlay
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	model := updated.(Model)
+	if !model.showSecretsPanel {
+		t.Fatal("expected 's' to open the secrets panel")
+	}
+	if view := model.View(); !strings.Contains(view, "GITHUB_TOKEN") {
+		t.Error("expected the secrets panel to list GITHUB_TOKEN")
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if updated.(Model).showSecretsPanel {
+		t.Error("expected any key to dismiss the secrets panel")
+	}
+}
+
+func TestFindingsDrillDownJumpsToCommand(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.sessions[1].Commands = append(m.sessions[1].Commands,
+		session.CommandEntry{ToolName: "Bash", RawCommand: "rm -rf dist", Pattern: "Bash(rm:*)", UUID: "rm-1", Timestamp: time.Now()})
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'4'}})
+	model := updated.(Model)
+	if model.viewMode != ViewFindings || len(model.findings) == 0 {
+		t.Fatalf("expected findings view with findings, got view %d and %d findings", model.viewMode, len(model.findings))
+	}
+	if model.findings[0].Rule != "Recursive file deletion" {
+		t.Fatalf("expected recursive deletion as the top finding, got %q", model.findings[0].Rule)
+	}
+
+	// Enter drills into the finding, esc backs out, enter twice opens the command
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.findingDrill == nil || len(model.findingCmdList.Items()) != 1 {
+		t.Fatal("expected enter to list the finding's commands")
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	model = updated.(Model)
+	if model.findingDrill != nil || model.viewMode != ViewFindings {
+		t.Fatal("expected esc to return to the findings summary")
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updated, _ = updated.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.viewMode != ViewCommands || model.activeIdx != 1 {
+		t.Fatalf("expected to jump to session-2's commands, got view %d session %d", model.viewMode, model.activeIdx)
+	}
+	if !model.detailPanelOpen || model.selectedCommand == nil || model.selectedCommand.UUID != "rm-1" {
+		t.Error("expected the detail panel to open on the offending command")
+	}
+}
+
+func TestAnalyticsLeaderboard(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.sessions[1].Commands = append(m.sessions[1].Commands,
+		session.CommandEntry{ToolName: "Bash", RawCommand: "git status", Pattern: "Bash(git:*)", Timestamp: time.Now()})
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'5'}})
+	model := updated.(Model)
+	if model.viewMode != ViewAnalytics || len(model.commandRanks) != 6 {
+		t.Fatalf("expected the leaderboard of 6 raw commands, got view %d and %d ranks", model.viewMode, len(model.commandRanks))
+	}
+	if top := model.commandRanks[0]; top.Key != "git status" || top.Sessions != 2 {
+		t.Errorf("expected git status (2 sessions) on top, got %q (%d sessions)", top.Key, top.Sessions)
+	}
+	if view := model.View(); !strings.Contains(view, "All commands") {
+		t.Error("expected the All commands leaderboard to be rendered")
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	model = updated.(Model)
+	if !model.ranksByPattern || model.commandRanks[0].Key != "Bash(git:*)" || model.commandRanks[0].Count != 4 {
+		t.Errorf("expected 'b' to rank patterns with Bash(git:*) x4 on top, got %+v", model.commandRanks[0])
+	}
+}
+
+func TestAnalyticsToolMix(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewAnalytics
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	model := updated.(Model)
+	if model.analyticsPage != pageToolMix || len(model.mixList.Items()) != 2 {
+		t.Fatalf("expected 'a' to show the tool mix of 2 projects, got page %d with %d rows", model.analyticsPage, len(model.mixList.Items()))
+	}
+	if view := model.View(); !strings.Contains(view, "Tool mix") || !strings.Contains(view, "alpha") {
+		t.Error("expected the tool mix page to list project alpha")
+	}
+
+	// The bar is always filled, however the shares round
+	mix := model.mixList.Items()[0].(toolMixItem).mix
+	for _, width := range []int{7, 30, 31} {
+		total := 0
+		for _, n := range mixSegments(mix, width) {
+			total += n
+		}
+		if total != width {
+			t.Errorf("segments of a %d-column bar add up to %d", width, total)
+		}
+	}
+}
+
+func TestAnalyticsHotspots(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.sessions[1].Commands = append(m.sessions[1].Commands,
+		session.CommandEntry{ToolName: "Edit", RawCommand: "/projects/beta/new.go", Timestamp: time.Now()},
+		session.CommandEntry{ToolName: "Edit", RawCommand: "/projects/beta/new.go", Timestamp: time.Now()})
+	m.viewMode = ViewAnalytics
+	m.analyticsPage = pageHotspots
+	m = m.aggregateAnalytics()
+
+	items := m.hotspotList.Items()
+	if len(items) != 3 {
+		t.Fatalf("expected 3 files touched, got %d", len(items))
+	}
+	top := items[0].(hotspotItem)
+	if top.Title() != "beta/new.go" || top.Description() != "edited 2 times across 1 sessions" {
+		t.Errorf("expected beta/new.go edited twice on top, got %q: %q", top.Title(), top.Description())
+	}
+	if items[1].(hotspotItem).Title() != "/path/to/new.go" {
+		t.Errorf("expected a file outside the project shown in full, got %q", items[1].(hotspotItem).Title())
+	}
+	if view := m.View(); !strings.Contains(view, "beta/new.go") {
+		t.Error("expected the hotspots page to list beta/new.go")
+	}
+}
+
+func TestAnalyticsCategories(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewAnalytics
+	m.analyticsPage = pageCategories
+	m = m.updateListSizes()
+	m = m.aggregateAnalytics()
+
+	// git status, git diff, git commit; Read, Write; go test
+	items := m.categoryList.Items()
+	if len(items) != 3 {
+		t.Fatalf("expected 3 categories, got %d", len(items))
+	}
+	top := items[0].(categoryItem)
+	if top.stat.Name != "vcs" || top.stat.Count != 3 || top.share() != 0.5 {
+		t.Errorf("expected vcs with half of the commands on top, got %+v", top.stat)
+	}
+	if view := m.View(); !strings.Contains(view, "file-io") || !strings.Contains(view, "50%") {
+		t.Errorf("expected the categories page to list file-io and vcs's share, got:\n%s", view)
+	}
+
+	m.viewMode = ViewCommands
+	if view := m.View(); !strings.Contains(view, "Category") || !strings.Contains(view, "vcs") {
+		t.Errorf("expected a Category column in the Commands view, got:\n%s", view)
+	}
+}
+
+func TestTouchedPathsTree(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.sessions[0].Commands = append(m.sessions[0].Commands,
+		session.CommandEntry{ToolName: "Edit", RawCommand: "/projects/alpha/internal/tui/model.go", Timestamp: time.Now()},
+		session.CommandEntry{ToolName: "Read", RawCommand: "/projects/alpha/internal/tui/view.go", Timestamp: time.Now()})
+	m = m.updateListSizes() // Full-height background for the overlay
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	model := updated.(Model)
+	if !model.showTouched {
+		t.Fatal("expected 'T' to open the touched paths tree")
+	}
+	// alpha, internal/tui, model.go, view.go, then /, path/to, and file.go outside
+	rows := model.touchedRows()
+	if len(rows) != 7 || rows[1].node.Name != "internal/tui" || !rows[4].outside {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	view := model.View()
+	for _, want := range []string{"Touched paths - alpha", "internal/tui/", "Outside the project", "file.go"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected the tree to show %q", want)
+		}
+	}
+
+	// Collapsing internal/tui hides its files
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	updated, _ = updated.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if rows := model.touchedRows(); len(rows) != 5 {
+		t.Errorf("expected 5 rows with internal/tui collapsed, got %d", len(rows))
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if updated.(Model).showTouched {
+		t.Error("expected esc to close the tree")
+	}
+}
+
+func TestAnalyticsActivityHeatmap(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewAnalytics
+	m.analyticsPage = pageActivity
+	m = m.aggregateAnalytics()
+
+	if m.heatmap == nil || len(m.heatmap.Days) != heatmapDays {
+		t.Fatalf("expected a heatmap of the last %d days", heatmapDays)
+	}
+	total := 0
+	for _, day := range m.heatmap.Counts {
+		for _, n := range day {
+			total += n
+		}
+	}
+	if total != 6 {
+		t.Errorf("expected the 6 commands in the heatmap, got %d", total)
+	}
+
+	view := m.View()
+	if !strings.Contains(view, time.Now().Format("Mon Jan 02")) || !strings.Contains(view, "busiest hour") {
+		t.Errorf("expected today's row and the legend, got:\n%s", view)
+	}
+	if lines := strings.Count(view, "\n") + 1; lines > m.height {
+		t.Errorf("expected the heatmap to fit %d lines, got %d", m.height, lines)
+	}
+}
+
+func TestCompactionMarkersDivideCommands(t *testing.T) {
+	m := newTestModelWithSessions()
+	sess := m.sessions[0]
+	sess.Markers = []session.Marker{{
+		Kind:      session.MarkerCompaction,
+		Detail:    "auto, 156k tokens",
+		Timestamp: sess.Commands[1].Timestamp.Add(30 * time.Second),
+	}}
+	m = m.updateCommandList()
+
+	items := m.commandList.Items()
+	if len(items) != 4 {
+		t.Fatalf("expected 3 commands and a divider, got %d items", len(items))
+	}
+	mk, ok := items[1].(markerItem)
+	if !ok {
+		t.Fatalf("expected the divider between the newest two commands, got %T", items[1])
+	}
+	if got := mk.marker.Label(); got != "context compacted (auto, 156k tokens)" {
+		t.Errorf("Label() = %q", got)
+	}
+
+	// Dividers aren't search matches
+	m.searchActive = true
+	m.searchInput.SetValue("go")
+	m = m.applySearchFilter()
+	for _, item := range m.commandList.Items() {
+		if _, ok := item.(markerItem); ok {
+			t.Error("expected dividers to be filtered out while searching")
+		}
+	}
+}
+
+func TestSessionPreviewFollowsHighlight(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	m = m.updateSessionList()
+	m = m.updateListSizes()
+
+	if view := m.View(); !strings.Contains(view, "Preview") || !strings.Contains(view, "git status") {
+		t.Fatalf("expected a preview of the first session, got:\n%s", view)
+	}
+	if view := m.View(); !strings.Contains(view, "exploration 1m") || !strings.Contains(view, "verification 1m") {
+		t.Errorf("expected the preview to show the session's phases, got:\n%s", view)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	model := updated.(Model)
+	if view := model.View(); !strings.Contains(view, "git commit -m fix") || strings.Contains(view, "git status") {
+		t.Errorf("expected the preview to follow the highlight, got:\n%s", view)
+	}
+	if model.activeIdx != 0 {
+		t.Errorf("expected the highlight to leave the active session alone, got %d", model.activeIdx)
+	}
+
+	model.width = previewMinWidth - 1
+	model = model.updateListSizes()
+	if strings.Contains(model.View(), "Preview") {
+		t.Error("expected no preview pane in a narrow terminal")
+	}
+}
+
+func TestHeaderStatusBar(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.width = 200
+	now := time.Now()
+	m.sessions[1].Commands = m.sessions[1].Commands[:1] // Drop the write outside the project
+	m.sessions[1].Commands = append(m.sessions[1].Commands,
+		session.CommandEntry{ToolName: "Bash", RawCommand: "git push --force origin main", Pattern: "Bash(git:*)", Timestamp: now.Add(-3 * time.Minute)},
+		session.CommandEntry{ToolName: "Bash", RawCommand: "git push --force origin dev", Pattern: "Bash(git:*)", Timestamp: now.Add(-20 * time.Minute)})
+	m.pendingAlerts = func() int { return 2 }
+
+	c := m.countStatus(now)
+	if c.dangerous != 1 {
+		t.Errorf("expected only the recent force push counted, got %d", c.dangerous)
+	}
+	if c.perMinute != 5.0/5 {
+		t.Errorf("expected 5 commands over 5 minutes, got %.2f/min", c.perMinute)
+	}
+
+	header := m.renderHeader()
+	for _, want := range []string{"2 sessions", "1 dangerous/10m", "2 alerts pending", "1.0 cmd/min", "starting"} {
+		if !strings.Contains(header, want) {
+			t.Errorf("expected %q in the header, got:\n%s", want, header)
+		}
+	}
+
+	m.watching = true
+	updated, _ := m.Update(watcherErrorMsg{errors.New("queue overflow")})
+	model := updated.(Model)
+	if model.err != nil {
+		t.Fatal("expected a watcher error to leave the UI running")
+	}
+	if header := model.renderHeader(); !strings.Contains(header, "watcher: 1 error") {
+		t.Errorf("expected the watcher shown as degraded, got:\n%s", header)
+	}
+
+	model.width = 60
+	if header := model.renderHeader(); strings.Contains(header, "cmd/min") || !strings.Contains(header, "2 sessions") {
+		t.Errorf("expected a narrow header to drop the trailing counters, got:\n%s", header)
+	}
+}
+
+func TestCtrlZSuspends(t *testing.T) {
+	m := newTestModelWithSessions()
+	ctrlZ := tea.KeyMsg{Type: tea.KeyCtrlZ}
+
+	if _, cmd := m.Update(ctrlZ); cmd != nil {
+		t.Error("expected Ctrl+Z ignored when suspending isn't allowed (SSH)")
+	}
+
+	m.suspendable = true
+	_, cmd := m.Update(ctrlZ)
+	if cmd == nil {
+		t.Fatal("expected Ctrl+Z to suspend")
+	}
+	if _, ok := cmd().(tea.SuspendMsg); !ok {
+		t.Error("expected Ctrl+Z to send a SuspendMsg")
+	}
+
+	if _, cmd := m.Update(tea.ResumeMsg{}); cmd == nil {
+		t.Error("expected resuming to redraw and catch up")
+	}
+}
+
+func TestPathMenuRunsActions(t *testing.T) {
+	var copied []string
+	copyToClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	t.Cleanup(func() { copyToClipboard = clipboard.WriteAll })
+
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	press := func(m Model, key string) (Model, tea.Cmd) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		updated, cmd := m.Update(msg)
+		return updated.(Model), cmd
+	}
+
+	m, _ = press(m, "p")
+	if !m.showPathDialog || !strings.Contains(m.View(), "Copy grep command") {
+		t.Fatal("expected 'p' to open the path menu")
+	}
+
+	// A shortcut runs its action; the menu stays open to show the outcome
+	m, cmd := press(m, "c")
+	if cmd == nil {
+		t.Fatal("expected 'c' to copy the path")
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(Model)
+	if len(copied) != 1 || copied[0] != "/tmp/test" {
+		t.Errorf("expected the session dir copied, got %v", copied)
+	}
+	if !m.showPathDialog || !strings.Contains(m.View(), "Copied the path") {
+		t.Error("expected the menu to report the copy")
+	}
+
+	// Enter runs the selected action
+	m, _ = press(m, "j")
+	m, cmd = press(m, "enter")
+	m.Update(cmd())
+	if len(copied) != 2 || copied[1] != "grep -ri 'search_term' /tmp/test" {
+		t.Errorf("expected the grep command copied, got %v", copied)
+	}
+
+	// Subagents that don't exist are reported rather than opened
+	_, cmd = press(m, "s")
+	if done, ok := cmd().(pathActionDoneMsg); !ok || done.err == nil {
+		t.Errorf("expected an error for a session without subagents, got %+v", done)
+	}
+
+	if m, _ = press(m, "q"); m.showPathDialog {
+		t.Error("expected 'q' to close the menu, not quit")
+	}
+}
+
+func TestRemoveSessionConfirmation(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	sessions := len(m.sessions)
+	press := func(m Model, key string) (Model, tea.Cmd) {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return updated.(Model), cmd
+	}
+
+	m, _ = press(m, "D")
+	if m.confirmRemove == nil || !strings.Contains(m.View(), "delete permanently") {
+		t.Fatal("expected 'D' to ask before removing the highlighted session")
+	}
+
+	// Any key other than d or a cancels without removing anything
+	m, cmd := press(m, "j")
+	if m.confirmRemove != nil || cmd != nil {
+		t.Fatal("expected 'j' to cancel the removal")
+	}
+	if len(m.sessions) != sessions {
+		t.Errorf("expected all %d sessions kept, got %d", sessions, len(m.sessions))
+	}
+
+	// A failed removal is reported in the footer until the next key
+	m, _ = press(m, "D")
+	m, cmd = press(m, "d")
+	if m.confirmRemove != nil || cmd == nil {
+		t.Fatal("expected 'd' to remove the session")
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(Model)
+	if !strings.Contains(m.View(), "Could not remove") {
+		t.Error("expected the failure shown without a watcher")
+	}
+	if m, _ = press(m, "j"); m.notice != "" {
+		t.Error("expected the next key to clear the notice")
+	}
+}
+
+func TestSessionsSortBySize(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	m = m.handleDiskUsage(diskUsageMsg{
+		"/tmp/test/session1.jsonl": 2 << 10,
+		"/tmp/test/session2.jsonl": 3 << 20,
+	})
+	if m.sessions[0].ID != "session-1" {
+		t.Fatal("expected the activity order kept until sorting by size")
+	}
+	if view := m.View(); !strings.Contains(view, "2.0 KB") || !strings.Contains(view, "project 3.0 MB in 2 sessions") {
+		t.Errorf("expected session and project sizes shown, got:\n%s", view)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	m = updated.(Model)
+	if m.sessions[0].ID != "session-2" || m.sessions[m.activeIdx].ID != "session-1" {
+		t.Errorf("expected the largest session first with the selection kept, got %s first, %s active",
+			m.sessions[0].ID, m.sessions[m.activeIdx].ID)
+	}
+	if h := m.highlightedSession(); h == nil || h.ID != "session-1" {
+		t.Error("expected the cursor to follow the highlighted session")
+	}
+	if !strings.Contains(m.View(), "by size, 3.0 MB total") {
+		t.Error("expected the header to show the size order and total")
+	}
+}
+
+func TestSessionsSortByBlastRadius(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	m.sessions[1].Commands = append(m.sessions[1].Commands,
+		session.CommandEntry{ToolName: "Bash", RawCommand: "sudo rm -rf /opt/cache", Timestamp: time.Now()},
+		session.CommandEntry{ToolName: "Write", RawCommand: "/etc/hosts", Timestamp: time.Now()})
+	m = m.updateSessionList()
+	m = m.updateListSizes()
+
+	for range 3 {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+		m = updated.(Model)
+	}
+	if m.sessionOrder != orderBlast || m.sessions[0].ID != "session-2" {
+		t.Fatalf("expected the third S to sort session-2 first by blast radius, got order %d with %s first", m.sessionOrder, m.sessions[0].ID)
+	}
+	m.sessionList.Select(0)
+	view := m.View()
+	for _, want := range []string{"by blast radius", "blast 26 |", "Blast radius 26/100", "writes outside the project: 2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the Sessions view, got:\n%s", want, view)
+		}
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	if m = updated.(Model); m.sessionOrder != orderActivity || m.blastScores != nil {
+		t.Error("expected S to cycle back to activity order")
+	}
+}
+
+func TestCycleProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	config.SetGlobal(nil)
+	t.Cleanup(func() {
+		config.SetGlobal(nil)
+	})
+
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	press := func(m Model) Model {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+		return updated.(Model)
+	}
+	if m = press(m); !strings.Contains(m.notice, "No profiles") {
+		t.Errorf("expected no profiles reported, got %q", m.notice)
+	}
+
+	profile := []byte("theme: latte\nlayout:\n  summaries: true\n  hide_preview: true\n  start_view: findings\n")
+	if err := config.ImportProfile("casual", profile, false); err != nil {
+		t.Fatal(err)
+	}
+	m = press(m)
+	if config.Global().Profile != "casual" || testTheme().flavor.Name() != "latte" {
+		t.Errorf("expected the casual profile and its theme applied, got %q, %s", config.Global().Profile, testTheme().flavor.Name())
+	}
+	if !m.sessionsExpanded || m.showPreview() || m.viewMode != ViewSessions {
+		t.Error("expected the profile's layout applied without leaving the view")
+	}
+	if !strings.Contains(m.renderHeader(), "[casual]") {
+		t.Error("expected the header to name the profile")
+	}
+
+	if m = press(m); config.Global().Profile != "" || m.sessionsExpanded || !m.showPreview() {
+		t.Error("expected P to cycle back to the config file alone")
+	}
+}
+
+func TestReviewerLayout(t *testing.T) {
+	cfg := config.DefaultConfig()
+	layout, err := config.LayoutPreset("reviewer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Layout = layout
+	config.SetGlobal(cfg)
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	m := newTestModelWithSessions()
+	if m.viewMode != ViewCommands || !m.sessionsExpanded || !m.thinkingShown || m.showPreview() {
+		t.Fatal("expected the reviewer preset to start in the Commands view with summaries and reasoning, without the preview")
+	}
+
+	// Selecting a session opens its transcript with the detail panel
+	m.viewMode = ViewSessions
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.viewMode != ViewCommands || !m.detailPanelOpen || cmd == nil {
+		t.Fatal("expected enter to open the Commands view with the detail panel loading")
+	}
+	if !m.heredocsExpanded {
+		t.Error("expected heredocs expanded by the preset")
+	}
+}
+
+func TestTextIndicators(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.TextIndicators = true
+	config.SetGlobal(cfg)
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	m.watching = true
+	m.sessions[0].IsActive = true
+	m.sessions[1].IsActive = false
+	m = m.updateSessionList()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	view := updated.(Model).View()
+	for _, want := range []string{"[ACTIVE] ", "[IDLE]", "[WATCHING]"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the Sessions view", want)
+		}
+	}
+	if strings.ContainsAny(view, "●○…") {
+		t.Errorf("expected no activity symbols or ellipses, got:\n%s", view)
+	}
+
+	if got := truncateWithEllipsis("Bash(terraform:apply)", 10); got != "Bash(te..." {
+		t.Errorf("truncateWithEllipsis() = %q, want a three-dot ellipsis within the width", got)
+	}
+}
+
+func TestOwnerFilterAndGrouping(t *testing.T) {
+	config.SetGlobal(&config.Config{Owners: []config.Owner{
+		{Name: "ci-bot", Origins: []string{"devagent:ci-*"}},
+	}})
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	watcher, err := session.NewReplicaWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, s := range []*session.Session{
+		{ID: "a", FilePath: "/tmp/test/a.jsonl", ProjectPath: "/projects/a", Origin: "local", LastActivity: now},
+		{ID: "b", FilePath: "/tmp/test/b.jsonl", ProjectPath: "/projects/b", Origin: "user:carol", LastActivity: now.Add(-time.Minute)},
+		{ID: "c", FilePath: "/tmp/test/c.jsonl", ProjectPath: "/projects/c", Origin: "devagent:ci-1", LastActivity: now.Add(-2 * time.Minute)},
+	} {
+		s.Commands = []session.CommandEntry{{ToolName: "Bash", RawCommand: fmt.Sprint("echo ", i), Pattern: "Bash(echo:*)", Timestamp: s.LastActivity}}
+		watcher.Inject(session.WatchEvent{Type: "discovered", Session: s})
+	}
+
+	m := NewModel(ModelOptions{Watcher: watcher})
+	updated, _ := m.Update(sessionsDiscoveredMsg(watcher.GetSessions()))
+	m = updated.(Model)
+	updated, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	press := func(m Model, key string) Model {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return updated.(Model)
+	}
+	ids := func(m Model) string {
+		var ids []string
+		for _, s := range m.sessions {
+			ids = append(ids, s.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	// S cycles activity, size, then owner order: owners alphabetically, unowned last
+	m = press(m, "S")
+	if m = press(m, "S"); ids(m) != "b,c,a" {
+		t.Errorf("expected carol, ci-bot, then unowned, got %s", ids(m))
+	}
+	if view := m.View(); !strings.Contains(view, "(by owner)") || !strings.Contains(view, "ci-bot |") {
+		t.Errorf("expected the owner order and labels shown, got:\n%s", view)
+	}
+
+	// O shows each owner's sessions in turn, then everyone's
+	if m = press(m, "O"); ids(m) != "b" || m.notice != "Owner: carol" {
+		t.Errorf("expected only carol's session, got %s (%q)", ids(m), m.notice)
+	}
+	if m = press(m, "O"); ids(m) != "c" || m.sessions[m.activeIdx].ID != "c" {
+		t.Errorf("expected only ci-bot's session selected, got %s", ids(m))
+	}
+	if !strings.Contains(m.View(), "owner ci-bot") {
+		t.Error("expected the filter shown in the column header")
+	}
+	if m = press(m, "O"); ids(m) != "b,c,a" || m.notice != "Owner: everyone" {
+		t.Errorf("expected every session back in owner order, got %s (%q)", ids(m), m.notice)
+	}
+}
+
+func TestGitDiffSinceSnapshot(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	writeFile := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	writeFile("package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	m := newTestModelWithSessions()
+	m = m.updateListSizes()
+	m.sessions[0].ProjectPath = dir
+	m.sessions[0].IsActive = true
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	if model := updated.(Model); model.showGitDiff || !strings.Contains(model.notice, "git_snapshots: true") {
+		t.Fatalf("expected a notice without git_snapshots, got %q", model.notice)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.GitSnapshots = true
+	config.SetGlobal(cfg)
+	t.Cleanup(func() { config.SetGlobal(nil) })
+
+	// Only the active session is snapshotted, once
+	snapshot := m.gitSnapshotCmd()
+	if m.gitSnapshotCmd() != nil {
+		t.Error("expected a session's snapshot to be requested once")
+	}
+	updated, _ = m.Update(snapshot())
+	m = updated.(Model)
+	if len(m.gitSnapshots) != 1 || m.gitSnapshots[m.sessions[0].FilePath].snap == nil {
+		t.Fatalf("expected a snapshot of the active session, got %+v", m.gitSnapshots)
+	}
+
+	writeFile("package main\n\n// during the session\n")
+	updated, diff := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	if !updated.(Model).showGitDiff || diff == nil {
+		t.Fatal("expected 'C' to open the diff and run git")
+	}
+	updated, _ = updated.(Model).Update(diff())
+	model := updated.(Model)
+	view := model.View()
+	for _, want := range []string{"Changes since", "+// during the session", "esc:close"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected the diff dialog to show %q, got:\n%s", want, view)
+		}
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if updated.(Model).showGitDiff {
+		t.Error("expected esc to close the diff")
+	}
+}
+
+func TestOnboardingWithoutSessions(t *testing.T) {
+	empty, missing := t.TempDir(), filepath.Join(t.TempDir(), "absent")
+	if err := os.Mkdir(filepath.Join(empty, "-home-dev-app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := session.NewWatcher([]string{empty, missing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = watcher.Stop() })
+
+	m := NewModel(ModelOptions{Watcher: watcher})
+	m.width, m.height = 120, 40
+	updated, _ := m.Update(sessionsDiscoveredMsg(nil))
+	m = updated.(Model)
+	view := m.View()
+	for _, want := range []string{"No Claude Code sessions found", empty, "1 project directory", missing, "does not exist", "d: follow devagent containers"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected the onboarding screen to show %q, got:\n%s", want, view)
+		}
+	}
+
+	// 'd' looks for devagent containers; a failure is a notice, not a fatal error
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if cmd == nil || updated.(Model).confirmRemove != nil {
+		t.Fatal("expected 'd' to look for devagent containers")
+	}
+	updated, _ = updated.(Model).Update(devagentEnabledMsg{err: errors.New("docker not running")})
+	if model := updated.(Model); model.err != nil || model.followDevagent || !strings.Contains(model.notice, "docker not running") {
+		t.Errorf("expected a notice for a failed lookup, got err %v, notice %q", model.err, model.notice)
+	}
+
+	// The screen gives way to the list once a session exists
+	m.sessions = newTestModelWithSessions().sessions
+	if m.showOnboarding() {
+		t.Error("expected no onboarding screen with sessions")
+	}
+}
+
+func TestAddDirDialog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir()) // No config.yaml in the working directory
+	local := t.TempDir()
+	watcher, err := session.NewWatcher([]string{local})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel(ModelOptions{Watcher: watcher})
+	m.width, m.height = 120, 40
+
+	key := func(m Model, keys ...string) (Model, tea.Cmd) {
+		var cmd tea.Cmd
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "tab":
+				msg = tea.KeyMsg{Type: tea.KeyTab}
+			}
+			updated, c := m.Update(msg)
+			m, cmd = updated.(Model), c
+		}
+		return m, cmd
+	}
+
+	// Missing and already watched directories are refused in the dialog
+	m, _ = key(m, "A", filepath.Join(local, "absent"), "enter")
+	if !m.showAddDir || !strings.Contains(m.addDirErr, "does not exist") {
+		t.Fatalf("expected a missing directory to be refused, got %q", m.addDirErr)
+	}
+	m, _ = key(m, "esc")
+	m, _ = key(m, "A", local, "enter")
+	if !strings.Contains(m.addDirErr, "already watched") {
+		t.Fatalf("expected a watched directory to be refused, got %q", m.addDirErr)
+	}
+	m, _ = key(m, "esc")
+
+	// A home directory means its .claude/projects; tab saves it to the config
+	home := t.TempDir()
+	projects := filepath.Join(home, ".claude", "projects")
+	if err := os.MkdirAll(projects, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = key(m, "A", home, "tab")
+	if view := m.View(); !strings.Contains(view, "Watch another projects directory") || !strings.Contains(view, "[x] Save to") {
+		t.Errorf("expected the dialog with saving on, got:\n%s", view)
+	}
+	m, cmd := key(m, "enter")
+	if m.showAddDir || !slices.Contains(watcher.ProjectsDirs(), projects) {
+		t.Fatalf("expected %s to be watched, got %v", projects, watcher.ProjectsDirs())
+	}
+	for _, msg := range cmd().(tea.BatchMsg) {
+		if saved, ok := msg().(addDirSavedMsg); ok {
+			m = m.handleAddDirSaved(saved)
+		}
+	}
+	if !strings.Contains(m.notice, "saved to "+config.UserConfigPath()) {
+		t.Errorf("expected the directory to be saved, got notice %q", m.notice)
+	}
+	cfg, err := config.Load(config.UserConfigPath())
+	if err != nil || !slices.Equal(cfg.ProjectsDirs, []string{projects}) {
+		t.Errorf("expected projects_dirs [%s] in the config, got %v, %v", projects, cfg, err)
+	}
+}
+
+func TestLogPane(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	m.width = 200
+	m = m.updateListSizes()
+	m.logSeen = applog.Last() // Ignore what other tests logged
+	listHeight := m.sessionList.Height()
+
+	updated, _ := m.Update(watcherErrorMsg{errors.New("projects dir gone")})
+	m = updated.(Model)
+	if header := m.renderHeader(); !strings.Contains(header, "1 warning (L)") {
+		t.Errorf("expected the unseen warning counted in the header, got:\n%s", header)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	m = updated.(Model)
+	view := m.View()
+	if !m.showLog || !strings.Contains(view, "Log (L:close)") || !strings.Contains(view, "watcher: projects dir gone") {
+		t.Fatalf("expected the log pane with the watcher error, got:\n%s", view)
+	}
+	if lines := strings.Count(view, "\n") + 1; lines > m.height {
+		t.Errorf("expected the view to fit %d lines with the log pane, got %d", m.height, lines)
+	}
+	if m.sessionList.Height() != listHeight-m.logPaneHeight() {
+		t.Errorf("expected the list to give the pane its rows, got height %d from %d", m.sessionList.Height(), listHeight)
+	}
+	if header := m.renderHeader(); strings.Contains(header, "warning") {
+		t.Errorf("expected no warning count while the pane is open, got:\n%s", header)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	m = updated.(Model)
+	if m.showLog || strings.Contains(m.View(), "Log (L:close)") || strings.Contains(m.renderHeader(), "warning") {
+		t.Error("expected L to close the pane with its warnings seen")
+	}
+}
+
+func TestErrorToasts(t *testing.T) {
+	m := newTestModelWithSessions()
+	m.viewMode = ViewSessions
+	m = m.updateListSizes()
+
+	update := func(m Model, msg tea.Msg) (Model, tea.Cmd) {
+		updated, cmd := m.Update(msg)
+		return updated.(Model), cmd
+	}
+	m, _ = update(m, errMsg{errors.New("devagent list: docker not running")})
+	m, cmd := update(m, errMsg{errors.New("devagent list: docker not running")})
+	if m.err != nil {
+		t.Fatal("expected a non-fatal error to leave the UI running")
+	}
+	view := m.View()
+	if !strings.Contains(view, "1 Sessions") || !strings.Contains(view, "devagent list: docker not running (x2)") {
+		t.Errorf("expected the UI with one toast counting the repeat, got:\n%s", view)
+	}
+	if lines := strings.Count(view, "\n") + 1; lines > m.height {
+		t.Errorf("expected the toast not to add lines, got %d", lines)
+	}
+
+	// The first error's expiry doesn't remove the renewed toast
+	m = m.expireToast(toastExpiredMsg{id: 1})
+	if len(m.toasts) != 1 {
+		t.Fatalf("expected the renewed toast kept, got %+v", m.toasts)
+	}
+	if cmd == nil {
+		t.Fatal("expected a command expiring the toast")
+	}
+	if m, _ = update(m, toastExpiredMsg{id: m.toasts[0].id}); len(m.toasts) != 0 || strings.Contains(m.View(), "docker not running") {
+		t.Errorf("expected the toast gone after its expiry, got %+v", m.toasts)
+	}
+
+	for i := range maxToasts + 2 {
+		m, _ = m.addToast(fmt.Errorf("error %d", i))
+	}
+	if len(m.toasts) != maxToasts || m.toasts[0].text != "error 2" {
+		t.Errorf("expected the newest %d toasts, got %+v", maxToasts, m.toasts)
+	}
+}
+
+func TestProblemsPanel(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken") // A file, so it can't be read as a projects directory
+	other := filepath.Join(dir, "other")
+	for _, f := range []string{broken, other} {
+		if err := os.WriteFile(f, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watcher, err := session.NewWatcher([]string{broken, other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = watcher.Stop() })
+	m := NewModel(ModelOptions{Watcher: watcher})
+	m.width, m.height = 160, 40
+
+	update := func(m Model, msg tea.Msg) (Model, tea.Cmd) {
+		updated, cmd := m.Update(msg)
+		return updated.(Model), cmd
+	}
+	key := func(m Model, k string) (Model, tea.Cmd) {
+		return update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+
+	// Every failure is kept, a repeat counted rather than added
+	m, _ = update(m, watcherErrorMsg{watcher.RetryRoot(broken)})
+	m, _ = update(m, watcherErrorMsg{watcher.RetryRoot(other)})
+	m, _ = update(m, problemMsg{problem{key: "devagent", text: "devagent: not found", retry: retryDevagent}})
+	m, _ = update(m, watcherErrorMsg{watcher.RetryRoot(broken)})
+	if len(m.problems) != 3 || m.problems[0].count != 2 {
+		t.Fatalf("expected 3 problems with the first counted twice, got %+v", m.problems)
+	}
+	if header := m.renderHeader(); !strings.Contains(header, "3 problems (E)") {
+		t.Errorf("expected the problems counted in the header, got:\n%s", header)
+	}
+
+	m, _ = key(m, "E")
+	view := m.View()
+	for _, want := range []string{"Problems (3)", "projects directory " + broken, "projects directory " + other, "devagent: not found", "2x, last"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the panel, got:\n%s", want, view)
+		}
+	}
+
+	// Retrying a directory still broken keeps it; fixed, the retry resolves it
+	m, cmd := key(m, "r")
+	if m, _ = update(m, cmd()); len(m.problems) != 3 || m.problems[0].count != 3 {
+		t.Errorf("expected the failed retry counted, got %+v", m.problems)
+	}
+	if err := os.Remove(broken); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(broken, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	m, cmd = key(m, "r")
+	if m, _ = update(m, cmd()); len(m.problems) != 2 || m.problems[0].key != other || !strings.Contains(m.notice, "Resolved: "+broken) {
+		t.Errorf("expected %s resolved, got %+v, notice %q", broken, m.problems, m.notice)
+	}
+
+	// d dismisses; the panel closes with the last problem
+	m, _ = key(m, "d")
+	m, _ = key(m, "d")
+	if len(m.problems) != 0 || m.showProblems || strings.Contains(m.renderHeader(), "problem") {
+		t.Errorf("expected every problem dismissed and the panel closed, got %+v", m.problems)
+	}
+}
+
+func TestDeepLinks(t *testing.T) {
+	var copied []string
+	copyToClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	t.Cleanup(func() { copyToClipboard = clipboard.WriteAll })
+
+	base := newTestModelWithSessions()
+	sessions := base.sessions
+	sessions[1].Commands[2].UUID = "msg-3"
+	sessions[1].Commands[2].SessionID = "session-2"
+	link, err := deeplink.Parse("ccmon://session/session-2?cmd=msg-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel(ModelOptions{Focus: &link})
+	m.watcher = nil
+	m.width, m.height = 120, 40
+	update := func(m Model, msg tea.Msg) (Model, tea.Cmd) {
+		updated, cmd := m.Update(msg)
+		return updated.(Model), cmd
+	}
+
+	// The link waits for its session
+	m, _ = update(m, sessionsDiscoveredMsg(sessions[:1]))
+	if m.pendingLink == nil || !strings.Contains(m.notice, "session-2 not found yet") {
+		t.Fatalf("expected the link to wait for its session, got notice %q", m.notice)
+	}
+	m, _ = update(m, sessionsDiscoveredMsg(sessions))
+	if m.pendingLink != nil || m.viewMode != ViewCommands || m.activeIdx != 1 {
+		t.Fatalf("expected the link to open session-2's commands, got view %d session %d", m.viewMode, m.activeIdx)
+	}
+	if !m.detailPanelOpen || m.selectedCommand == nil || m.selectedCommand.RawCommand != "git commit -m fix" {
+		t.Fatal("expected the detail panel open on the linked command")
+	}
+
+	// y copies the link back
+	m, cmd := update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if cmd == nil {
+		t.Fatal("expected y to copy a link")
+	}
+	m, _ = update(m, cmd())
+	if len(copied) != 1 || copied[0] != link.String() || m.notice != "Copied "+link.String() {
+		t.Errorf("expected the command's link copied, got %v and notice %q", copied, m.notice)
+	}
+
+	m.viewMode = ViewSessions
+	m = m.updateSessionList()
+	m.sessionList.Select(0)
+	_, cmd = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if msg := cmd().(linkCopiedMsg); msg.link != "ccmon://session/session-1" {
+		t.Errorf("expected the highlighted session's link, got %s", msg.link)
+	}
+}
+
+func TestSourceCommand(t *testing.T) {
+	c := &session.CommandEntry{FilePath: "/p/s.jsonl", LineNumber: 42}
+	tests := []struct {
+		visual, editor, pager string
+		want                  string
+	}{
+		{"", "vim", "", "vim +42 /p/s.jsonl"},
+		{"code --wait", "vim", "", "code --wait --goto /p/s.jsonl:42"},
+		{"", "/usr/bin/hx", "", "/usr/bin/hx /p/s.jsonl:42"},
+		{"", "", "", "less -N +42g /p/s.jsonl"},
+		{"", "", "less -R", "less -R -N +42g /p/s.jsonl"},
+		{"", "", "more", "more +42 /p/s.jsonl"},
+		{"", "", "bat", "bat /p/s.jsonl"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(sourceCommand(tt.visual, tt.editor, tt.pager, c), " "); got != tt.want {
+			t.Errorf("sourceCommand(%q, %q, %q) = %s, want %s", tt.visual, tt.editor, tt.pager, got, tt.want)
+		}
+	}
+
+	// A file that isn't on this machine is reported, not opened
+	m := newTestModelWithSessions()
+	cmd := m.openSourceCmd()
+	if cmd == nil {
+		t.Fatal("expected J to act on the selected command")
+	}
+	updated, _ := m.Update(cmd())
+	if model := updated.(Model); len(model.toasts) != 1 || !strings.Contains(model.toasts[0].text, "session file") {
+		t.Errorf("expected a toast for the missing session file, got %+v", model.toasts)
+	}
+}
+
+func TestModelRenderer(t *testing.T) {
+	// Each SSH client gets a renderer for its own terminal
+	plain := lipgloss.NewRenderer(io.Discard)
+	plain.SetColorProfile(termenv.Ascii)
+	colored := lipgloss.NewRenderer(io.Discard)
+	colored.SetColorProfile(termenv.TrueColor)
+
+	m := NewModel(ModelOptions{Renderer: plain})
+	if got := m.theme.TitleStyle().Render("title"); got != "title" {
+		t.Errorf("expected no colors for an ASCII client, got %q", got)
+	}
+	m = NewModel(ModelOptions{Renderer: colored})
+	if got := m.theme.TitleStyle().Render("title"); !strings.Contains(got, "\x1b[") {
+		t.Errorf("expected colors for a true color client, got %q", got)
+	}
+}
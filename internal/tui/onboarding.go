@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cc_session_mon/internal/devagent"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scannedDir is a projects directory the watcher scanned, and what was
+// found there, for the onboarding screen
+type scannedDir struct {
+	path   string
+	ok     bool   // The directory exists and can be read
+	status string // e.g. "does not exist" or "exists, 3 project directories"
+}
+
+// devagentEnabledMsg carries the devagent environments found when devagent
+// mode is switched on from the onboarding screen
+type devagentEnabledMsg struct {
+	envs []devagent.Environment
+	err  error
+}
+
+// showOnboarding reports whether the Sessions view shows the guided screen
+// instead of the list: discovery finished and found no sessions at all
+// (rather than none for the owner filter)
+func (m Model) showOnboarding() bool {
+	return m.watching && len(m.sessions) == 0 && m.ownerFilter == ""
+}
+
+// scanProjectsDirs checks the watcher's projects directories, for the
+// onboarding screen. It runs when discovery finds nothing.
+func (m Model) scanProjectsDirs() []scannedDir {
+	if m.watcher == nil {
+		return nil
+	}
+	var dirs []scannedDir
+	for _, dir := range m.watcher.ProjectsDirs() {
+		dirs = append(dirs, inspectProjectsDir(dir))
+	}
+	return dirs
+}
+
+// inspectProjectsDir describes what is at a projects directory
+func inspectProjectsDir(dir string) scannedDir {
+	sd := scannedDir{path: dir}
+	info, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err):
+		sd.status = "does not exist"
+		return sd
+	case err != nil:
+		sd.status = "can't be read: " + err.Error()
+		return sd
+	case !info.IsDir():
+		sd.status = "is not a directory"
+		return sd
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		sd.status = "can't be read: " + err.Error()
+		return sd
+	}
+	projects := 0
+	for _, e := range entries {
+		if e.IsDir() {
+			projects++
+		}
+	}
+	sd.ok = true
+	noun := "project directories"
+	if projects == 1 {
+		noun = "project directory"
+	}
+	sd.status = fmt.Sprintf("exists, %d %s, no session files", projects, noun)
+	return sd
+}
+
+// onboardingCauses lists the likely reasons no sessions were found, from
+// the environment
+func onboardingCauses() []string {
+	home, _ := os.UserHomeDir()
+	causes := []string{
+		"Claude Code hasn't run as this user yet: start a session and it appears here",
+		fmt.Sprintf("Sessions are read from $HOME/.claude/projects, and HOME is %s; a different HOME (another user, a service account) has its own sessions", home),
+	}
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		causes = append(causes, fmt.Sprintf("CLAUDE_CONFIG_DIR is set, so Claude Code writes sessions to %s", filepath.Join(dir, "projects")))
+	}
+	if user := os.Getenv("SUDO_USER"); user != "" {
+		causes = append(causes, fmt.Sprintf("Running under sudo: %s's sessions are in their own home (list it under homes in the config)", user))
+	}
+	if inContainer() {
+		causes = append(causes, "This looks like a container: sessions of Claude Code on the host stay in the host's home unless it's mounted here")
+	}
+	causes = append(causes, "Claude Code runs in devagent containers: their sessions are only watched in devagent mode")
+	return causes
+}
+
+// inContainer reports whether the monitor seems to run in a container
+func inContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// handleOnboardingKey handles the onboarding screen's shortcuts: d follows
+// devagent containers (A, adding a directory, and r, scanning again, are
+// global keys)
+func (m Model) handleOnboardingKey(key string) (Model, tea.Cmd, bool) {
+	if m.viewMode != ViewSessions || !m.showOnboarding() || key != "d" {
+		return m, nil, false
+	}
+	if m.followDevagent {
+		m.notice = "Already following devagent containers"
+		return m, nil, true
+	}
+	m.notice = "Looking for devagent containers..."
+	return m, func() tea.Msg {
+		envs, err := devagent.Discover()
+		return devagentEnabledMsg{envs: envs, err: err}
+	}, true
+}
+
+// handleDevagentEnabled switches devagent mode on once discovery succeeds:
+// the containers' directories are added to the watcher and polled for from
+// then on
+func (m Model) handleDevagentEnabled(msg devagentEnabledMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.notice = m.theme.ErrorStyle().UnsetPadding().Render("Could not follow devagent containers: " + msg.err.Error())
+		return m, nil
+	}
+	m.followDevagent = true
+	m.notice = fmt.Sprintf("Following devagent containers (%d found)", len(msg.envs))
+	return m, tea.Batch(m.devagentTickCmd(), m.handleDevagentRefresh(devagentRefreshMsg{envs: msg.envs}))
+}
+
+// renderOnboarding renders the guided screen shown in place of an empty
+// Sessions list: where sessions were looked for, likely causes, and shortcuts
+func (m Model) renderOnboarding() string {
+	height := max(5, m.height-9-m.logPaneHeight()) + 1 // The list and its column headers
+	width := max(20, m.width-4)
+	wrap := m.theme.NewStyle().Width(width)
+
+	lines := []string{m.theme.LabelStyle().Render("No Claude Code sessions found"), ""}
+	if len(m.scannedDirs) > 0 {
+		lines = append(lines, "Scanned:")
+		for _, d := range m.scannedDirs {
+			mark, style := indicator("✓ ", "[OK] "), m.theme.MutedStyle()
+			if !d.ok {
+				mark, style = indicator("✗ ", "[MISSING] "), m.theme.WarningStyle()
+			}
+			lines = append(lines, wrap.Render("  "+style.Render(mark)+m.theme.PathStyle().Render(d.path)+style.Render(" "+d.status)))
+		}
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "Common causes:")
+	for _, cause := range onboardingCauses() {
+		lines = append(lines, wrap.Render(m.theme.MutedStyle().Render("  - "+cause)))
+	}
+
+	keys := []string{"A: add a directory", "d: follow devagent containers", "r: scan again"}
+	if m.followDevagent {
+		keys = slices.Delete(keys, 1, 2)
+	}
+	lines = append(lines, "", m.theme.LabelStyle().Render(strings.Join(keys, "   ")))
+
+	return m.theme.NewStyle().Height(height).MaxHeight(height).Render(strings.Join(lines, "\n"))
+}
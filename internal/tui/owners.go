@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"slices"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+)
+
+// sessionOwner returns the owner label of a session, or "" when no owner
+// matches (see config owners)
+func sessionOwner(sess *session.Session) string {
+	return config.Global().OwnerOf(sess.Origin, sess.ProjectPath)
+}
+
+// ownerNames returns the owners of all watched sessions, sorted, whatever
+// the owner filter hides
+func (m Model) ownerNames() []string {
+	sessions := m.sessions
+	if m.watcher != nil {
+		sessions = m.watcher.GetSessions()
+	}
+	var names []string
+	for _, sess := range sessions {
+		if owner := sessionOwner(sess); owner != "" && !slices.Contains(names, owner) {
+			names = append(names, owner)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// cycleOwnerFilter shows only the next owner's sessions, after the last one
+// back to everyone's, and reports it in the footer
+func (m Model) cycleOwnerFilter() Model {
+	names := m.ownerNames()
+	if len(names) == 0 && m.ownerFilter == "" {
+		m.notice = "No session has an owner (set owners in the config)"
+		return m
+	}
+
+	order := append([]string{""}, names...)
+	m.ownerFilter = order[(slices.Index(order, m.ownerFilter)+1)%len(order)]
+	m = m.resortSessions(true)
+	m = m.updateCommandList()
+	m = m.aggregatePatterns()
+	if m.viewMode == ViewFindings {
+		m = m.aggregateFindings()
+	}
+	if m.viewMode == ViewAnalytics {
+		m = m.aggregateAnalytics()
+	}
+	if m.ownerFilter == "" {
+		m.notice = "Owner: everyone"
+	} else {
+		m.notice = "Owner: " + m.ownerFilter
+	}
+	return m
+}
+
+// filterByOwner drops sessions not owned by the owner filter, if one is set
+func (m Model) filterByOwner(sessions []*session.Session) []*session.Session {
+	if m.ownerFilter == "" {
+		return sessions
+	}
+	return slices.DeleteFunc(sessions, func(s *session.Session) bool { return sessionOwner(s) != m.ownerFilter })
+}
+
+// compareOwners orders owner labels alphabetically with unowned sessions last
+func compareOwners(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	case a < b:
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"cc_session_mon/internal/session"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// copyToClipboard writes text to the system clipboard (replaced in tests)
+var copyToClipboard = clipboard.WriteAll
+
+// pathActionDoneMsg reports the outcome of a path menu action
+type pathActionDoneMsg struct {
+	status string
+	err    error
+}
+
+// pathAction is an entry in the session path menu (p)
+type pathAction struct {
+	key   string
+	label string
+	run   func(sess *session.Session) tea.Cmd
+}
+
+// pathActions are the path menu's entries, in display order
+var pathActions = []pathAction{
+	{"c", "Copy session dir path", func(sess *session.Session) tea.Cmd {
+		return copyCmd(sessionDir(sess), "path")
+	}},
+	{"g", "Copy grep command", func(sess *session.Session) tea.Cmd {
+		return copyCmd(grepCommand(sess), "grep command")
+	}},
+	{"f", "Open dir in file manager", func(sess *session.Session) tea.Cmd {
+		return revealCmd(sessionDir(sess))
+	}},
+	{"e", "Open dir in $EDITOR", func(sess *session.Session) tea.Cmd {
+		return editCmd(sessionDir(sess))
+	}},
+	{"s", "Reveal subagents dir", func(sess *session.Session) tea.Cmd {
+		dir := subagentsDir(sess)
+		if _, err := os.Stat(dir); err != nil {
+			return func() tea.Msg { return pathActionDoneMsg{err: errors.New("this session has no subagents")} }
+		}
+		return revealCmd(dir)
+	}},
+}
+
+// sessionDir returns the directory holding a session's JSONL file
+func sessionDir(sess *session.Session) string {
+	return filepath.Dir(sess.FilePath)
+}
+
+// subagentsDir returns where a session's subagent transcripts are written
+func subagentsDir(sess *session.Session) string {
+	return filepath.Join(sessionDir(sess), sess.ID, "subagents")
+}
+
+// grepCommand returns an example command searching a session's directory
+func grepCommand(sess *session.Session) string {
+	return fmt.Sprintf("grep -ri 'search_term' %s", sessionDir(sess))
+}
+
+// copyCmd copies text to the clipboard, naming it what in the status line
+func copyCmd(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		if err := copyToClipboard(text); err != nil {
+			return pathActionDoneMsg{err: fmt.Errorf("copying the %s: %w", what, err)}
+		}
+		return pathActionDoneMsg{status: "Copied the " + what + " to the clipboard"}
+	}
+}
+
+// revealCmd opens a directory in the system file manager without waiting
+// for it to close
+func revealCmd(dir string) tea.Cmd {
+	return func() tea.Msg {
+		opener := systemOpener()
+		if opener == "" {
+			return pathActionDoneMsg{err: errors.New("no file manager opener found")}
+		}
+		cmd := exec.Command(opener, dir) //nolint:gosec // fixed opener binary, a session directory
+		if err := cmd.Start(); err != nil {
+			return pathActionDoneMsg{err: err}
+		}
+		go func() { _ = cmd.Wait() }()
+		return pathActionDoneMsg{status: "Opened " + dir}
+	}
+}
+
+// editCmd hands the terminal to $VISUAL or $EDITOR on path until it exits
+func editCmd(path string) tea.Cmd {
+	editor := strings.Fields(firstNonEmpty(os.Getenv("VISUAL"), os.Getenv("EDITOR")))
+	if len(editor) == 0 {
+		return func() tea.Msg { return pathActionDoneMsg{err: errors.New("set $EDITOR to open files in an editor")} }
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], path)...) //nolint:gosec // the user's own editor
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			return pathActionDoneMsg{err: fmt.Errorf("%s: %w", editor[0], err)}
+		}
+		return pathActionDoneMsg{status: "Closed " + editor[0]}
+	})
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// handlePathMenuKey handles keys while the path menu is open: moving the
+// selection, running the selected action or one by its key, and closing
+func (m Model) handlePathMenuKey(key string) (tea.Model, tea.Cmd) {
+	sess := m.ActiveSession()
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q", "p":
+		m.showPathDialog = false
+		return m, nil
+	case "j", "down":
+		m.pathMenuIdx = (m.pathMenuIdx + 1) % len(pathActions)
+		return m, nil
+	case "k", "up":
+		m.pathMenuIdx = (m.pathMenuIdx + len(pathActions) - 1) % len(pathActions)
+		return m, nil
+	case "enter":
+		if sess != nil {
+			m.pathMenuStatus, m.pathMenuErr = "", nil
+			return m, pathActions[m.pathMenuIdx].run(sess)
+		}
+	}
+	for i, action := range pathActions {
+		if key == action.key && sess != nil {
+			m.pathMenuIdx = i
+			m.pathMenuStatus, m.pathMenuErr = "", nil
+			return m, action.run(sess)
+		}
+	}
+	return m, nil
+}
+
+// overlayPathDialog renders the path menu centered over the existing view
+func (m Model) overlayPathDialog(background string) string {
+	sess := m.ActiveSession()
+	if sess == nil {
+		return background
+	}
+	t := m.theme
+
+	lines := []string{
+		m.theme.LabelStyle().Render("Session data path:"),
+		m.theme.NewStyle().Foreground(t.Secondary).Render(sessionDir(sess)),
+		"",
+	}
+	if len(sess.Duplicates) > 0 {
+		lines = append(lines, m.theme.LabelStyle().Render("Also found at (hidden):"))
+		for _, dup := range sess.Duplicates {
+			lines = append(lines, m.theme.MutedStyle().Render(filepath.Dir(dup)))
+		}
+		lines = append(lines, "")
+	}
+
+	width := 0
+	for i, action := range pathActions {
+		row := fmt.Sprintf("  %s  %s", action.key, action.label)
+		style := m.theme.NormalItemStyle()
+		if i == m.pathMenuIdx {
+			row = "▸" + row[1:]
+			style = m.theme.SelectedItemStyle()
+		}
+		width = max(width, lipgloss.Width(row))
+		lines = append(lines, style.Render(row))
+	}
+	lines = append(lines, "", m.theme.MutedStyle().Render(grepCommand(sess)))
+
+	switch {
+	case m.pathMenuErr != nil:
+		lines = append(lines, "", m.theme.DangerStyle().Render("✗ "+m.pathMenuErr.Error()))
+	case m.pathMenuStatus != "":
+		lines = append(lines, "", m.theme.ActiveIndicatorStyle().Render("✓ "+m.pathMenuStatus))
+	}
+	lines = append(lines, "", m.theme.NewStyle().Foreground(t.Muted).Italic(true).Render("j/k:select  enter or key:run  esc:close"))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return m.overlayDialog(background, content, max(width, lipgloss.Width(content))+6)
+}
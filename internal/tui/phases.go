@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/session"
+)
+
+// phaseColors are the phase bar colors, matching the tool groups the
+// phases mostly use
+var phaseColors = map[session.Phase]string{
+	session.PhaseExploration:    "green",
+	session.PhaseImplementation: "yellow",
+	session.PhaseVerification:   "mauve",
+}
+
+// phaseBarWidth is the width of the phase bar in expanded session rows
+const phaseBarWidth = 16
+
+// renderPhaseBar renders a session's timeline as width columns, each
+// colored by the phase at its time; idle gaps show as dots. Text
+// indicators use the phases' initials.
+func renderPhaseBar(theme *Theme, t *session.Timeline, width int) string {
+	if len(t.Phases) == 0 || width <= 0 {
+		return theme.MutedStyle().Render(strings.Repeat("·", width))
+	}
+	var b strings.Builder
+	step := t.Duration() / time.Duration(width)
+	for col := range width {
+		// Sample the middle of the column; a session without a duration
+		// is all its first phase
+		at := t.Start.Add(step*time.Duration(col) + step/2)
+		if t.IdleAt(at) {
+			b.WriteString(theme.MutedStyle().Render("·"))
+			continue
+		}
+		phase := t.PhaseAt(at)
+		if phase == "" {
+			phase = t.Phases[0].Phase
+		}
+		style := theme.NewStyle().Foreground(theme.ColorByName(phaseColors[phase]))
+		b.WriteString(style.Render(indicator("█", strings.ToUpper(string(phase[:1])))))
+	}
+	return b.String()
+}
+
+// renderPhaseLegend lists the phases with their colors and time spent,
+// e.g. "■ exploration 12m  ■ implementation 40m"
+func renderPhaseLegend(theme *Theme, t *session.Timeline) string {
+	spent := make(map[session.Phase]time.Duration)
+	for _, span := range t.Phases {
+		spent[span.Phase] += span.End.Sub(span.Start)
+	}
+	for _, gap := range t.Idle {
+		spent[t.PhaseAt(gap.Start)] -= gap.End.Sub(gap.Start)
+	}
+	var parts []string
+	for _, phase := range session.Phases {
+		if _, ok := spent[phase]; !ok {
+			continue
+		}
+		style := theme.NewStyle().Foreground(theme.ColorByName(phaseColors[phase]))
+		mark := indicator("■", strings.ToUpper(string(phase[:1])))
+		parts = append(parts, style.Render(mark)+theme.MutedStyle().Render(" "+string(phase)+" "+formatDuration(max(0, spent[phase]))))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// timelineSummary describes a timeline's length, e.g. "1h05m, 48m active, 2 idle gaps"
+func timelineSummary(t *session.Timeline) string {
+	s := formatDuration(t.Duration())
+	if len(t.Idle) > 0 {
+		s += fmt.Sprintf(", %s active, %d idle %s", formatDuration(t.Active), len(t.Idle), pluralize(len(t.Idle), "gap"))
+	}
+	return s
+}
+
+// formatDuration formats a duration to the minute, e.g. "45s", "12m", or "1h05m"
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// previewMinWidth is the narrowest terminal that shows the preview pane
+// beside the Sessions list; narrower terminals show the list alone
+const previewMinWidth = 100
+
+// previewCommands is how many of the newest commands the preview lists
+const previewCommands = 5
+
+// showPreview reports whether the Sessions view has room for the preview
+// pane and the layout doesn't hide it
+func (m Model) showPreview() bool {
+	return m.width >= previewMinWidth && !config.Global().Layout.HidePreview
+}
+
+// previewLayout returns the widths of the Sessions list and preview pane
+func (m Model) previewLayout() (listWidth, previewWidth int) {
+	totalWidth := m.width - 4
+	listWidth = int(float64(totalWidth) * 0.58)
+	return listWidth, totalWidth - listWidth - 1 // -1 for separator
+}
+
+// highlightedSession returns the session under the cursor in the Sessions
+// list, which becomes the active session only once selected
+func (m Model) highlightedSession() *session.Session {
+	if i := m.sessionList.Index(); i >= 0 && i < len(m.sessions) {
+		return m.sessions[i]
+	}
+	return nil
+}
+
+// renderSessionsWithPreview renders the Sessions list beside a preview of
+// the highlighted session
+func (m Model) renderSessionsWithPreview() string {
+	listWidth, previewWidth := m.previewLayout()
+	_, _, contentHeight := m.splitLayout()
+
+	leftSide := m.theme.NewStyle().
+		Width(listWidth).
+		Height(contentHeight + 1). // +1 for header
+		Render(m.theme.ColumnHeaderStyle(listWidth).Render("  Session Path"+m.sessionSortLabel()) + "\n" + m.sessionList.View())
+
+	separator := m.theme.NewStyle().
+		Foreground(m.theme.Muted).
+		Render(strings.Repeat("│\n", contentHeight+1))
+
+	rightSide := m.renderPreview(previewWidth, contentHeight+1)
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, leftSide, separator, rightSide)
+}
+
+// renderPreview renders the highlighted session's status and newest commands
+func (m Model) renderPreview(width, height int) string {
+	body := m.theme.DetailHeaderStyle(width).Render("Preview") + "\n" + m.renderPreviewBody(width)
+	return m.theme.NewStyle().Width(width).Height(height).MaxHeight(height).Render(body)
+}
+
+// blastStyle colors a blast radius score: red from 50, yellow from 20
+func (t *Theme) blastStyle(score int) lipgloss.Style {
+	switch {
+	case score >= 50:
+		return t.DangerStyle().Bold(true)
+	case score >= 20:
+		return t.WarningStyle()
+	}
+	return t.MutedStyle()
+}
+
+// renderPreviewBody renders the preview pane's content below its header
+func (m Model) renderPreviewBody(width int) string {
+	sess := m.highlightedSession()
+	if sess == nil {
+		return m.theme.MutedStyle().Render("No session highlighted")
+	}
+	truncate := m.theme.NewStyle().Inline(true).MaxWidth(width)
+
+	var b strings.Builder
+	b.WriteString(truncate.Render(m.theme.PathStyle().Render(sess.ProjectPath)))
+	b.WriteString("\n")
+
+	status := m.theme.InactiveIndicatorStyle().Render(activityLabel(false))
+	if sess.IsActive {
+		status = m.theme.ActiveIndicatorStyle().Render(activityLabel(true))
+	}
+	status += m.theme.MutedStyle().Render(fmt.Sprintf(" · %d cmds · %s", len(sess.Commands), formatTimeAgo(sess.LastActivity)))
+	b.WriteString(truncate.Render(status))
+	b.WriteString("\n")
+
+	var details []string
+	if sess.GitBranch != "" {
+		details = append(details, "branch "+sess.GitBranch)
+	}
+	if sess.Origin != "" && sess.Origin != "local" {
+		details = append(details, sess.Origin)
+	}
+	if owner := sessionOwner(sess); owner != "" {
+		details = append(details, "owner "+owner)
+	}
+	if snap := m.gitSnapshots[sess.FilePath].snap; snap != nil {
+		details = append(details, "git snapshot "+snap.Taken.Format("15:04"))
+	}
+	if len(details) > 0 {
+		b.WriteString(truncate.Render(m.theme.MutedStyle().Render(strings.Join(details, " · "))))
+		b.WriteString("\n")
+	}
+	if size := m.sessionUsage(sess); size > 0 {
+		total, sessions := m.projectUsage(sess)
+		usage := fmt.Sprintf("%s on disk · project %s in %d %s", formatSize(size), formatSize(total), sessions, pluralize(sessions, "session"))
+		b.WriteString(truncate.Render(m.theme.MutedStyle().Render(usage)))
+		b.WriteString("\n")
+	}
+	if len(sess.Commands) > 0 {
+		timeline := session.SessionTimeline(sess)
+		b.WriteString(truncate.Render(m.theme.MutedStyle().Render(timelineSummary(timeline))))
+		b.WriteString("\n" + renderPhaseBar(m.theme, timeline, width) + "\n")
+		if legend := renderPhaseLegend(m.theme, timeline); legend != "" {
+			b.WriteString(truncate.Render(legend) + "\n")
+		}
+	}
+	if radius := security.SessionBlastRadius(sess); radius.Score > 0 {
+		b.WriteString(truncate.Render(m.theme.blastStyle(radius.Score).Render(
+			fmt.Sprintf("Blast radius %d/%d", radius.Score, security.MaxBlastRadius))))
+		b.WriteString("\n")
+		for _, f := range radius.Factors {
+			b.WriteString(truncate.Render(m.theme.MutedStyle().Render("  " + f.String())))
+			b.WriteString("\n")
+		}
+	}
+	if len(sess.Flags) > 0 {
+		b.WriteString(truncate.Render(m.theme.DangerStyle().Bold(true).Render(flagMarker() + strings.Join(sess.Flags, ", "))))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.theme.LabelStyle().Render("Recent commands:"))
+	if len(sess.Commands) == 0 {
+		b.WriteString("\n" + m.theme.MutedStyle().Render("None yet"))
+		return b.String()
+	}
+
+	// Newest first, like the Commands view; subagent commands are appended
+	// out of order, so the slice is sorted by time rather than trusted
+	recent := make([]*session.CommandEntry, len(sess.Commands))
+	for i := range sess.Commands {
+		recent[i] = &sess.Commands[i]
+	}
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].Timestamp.After(recent[j].Timestamp)
+	})
+	cfg := config.ForProject(sess.ProjectPath)
+	for _, cmd := range recent[:min(len(recent), previewCommands)] {
+		timestamp := m.theme.TimestampStyle().Render(cmd.Timestamp.Format("15:04"))
+		pattern := m.theme.styleForGroup(toolGroupFor(cfg, cmd.Pattern)).Render(cmd.Pattern)
+		row := timestamp + " " + pattern + " " + jobBadge(cmd) + singleLine(cmd.RawCommand)
+		b.WriteString("\n" + truncate.Render(row))
+	}
+	return b.String()
+}
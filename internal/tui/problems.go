@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// retryKind is what retrying a problem does
+type retryKind int
+
+const (
+	retryNone     retryKind = iota // Nothing to retry (the watch limit, a one-off watcher error)
+	retryRoot                      // Read the projects directory again (session.Watcher.RetryRoot)
+	retryDevagent                  // List devagent environments again
+)
+
+// problem is a failure that lasts until it's retried, fixed, or dismissed,
+// listed in the problems panel (E). Each failing thing keeps one entry, so
+// a failure doesn't hide earlier ones.
+type problem struct {
+	key   string // What failed (a projects directory, "devagent"); a new failure of it replaces the entry
+	text  string
+	count int // Failures of key since it was recorded
+	last  time.Time
+	retry retryKind
+}
+
+type (
+	// problemMsg records a failure as a problem
+	problemMsg struct{ problem }
+	// problemResolvedMsg removes a problem whose retry succeeded
+	problemResolvedMsg struct{ key string }
+)
+
+// watcherProblem classifies a watcher error: an unreadable projects
+// directory can be retried; other errors are listed by their text
+func watcherProblem(err error) problem {
+	var root *session.RootError
+	var limit *session.WatchLimitError
+	switch {
+	case errors.As(err, &root):
+		return problem{key: root.Root, text: err.Error(), retry: retryRoot}
+	case errors.As(err, &limit):
+		return problem{key: "watch limit", text: err.Error()}
+	default:
+		return problem{key: err.Error(), text: err.Error()}
+	}
+}
+
+// recordProblem adds p, or updates and counts the entry for its key. A new
+// problem also shows as a toast.
+func (m Model) recordProblem(p problem) (Model, tea.Cmd) {
+	p.count, p.last = 1, time.Now()
+	problems := slices.Clone(m.problems)
+	if i := slices.IndexFunc(problems, func(o problem) bool { return o.key == p.key }); i >= 0 {
+		p.count += problems[i].count
+		problems[i] = p
+		m.problems = problems
+		return m, nil
+	}
+	m.problems = append(problems, p)
+	return m.addToast(errors.New(p.text))
+}
+
+// resolveProblem removes the problem for key, saying so if there was one
+func (m Model) resolveProblem(key string) Model {
+	i := slices.IndexFunc(m.problems, func(p problem) bool { return p.key == key })
+	if i < 0 {
+		return m
+	}
+	m.problems = slices.Delete(slices.Clone(m.problems), i, i+1)
+	m.problemIdx = min(m.problemIdx, max(0, len(m.problems)-1))
+	m.notice = m.theme.ActiveIndicatorStyle().Render("Resolved: " + key)
+	if len(m.problems) == 0 {
+		m.showProblems = false
+	}
+	return m
+}
+
+// retryProblemCmd retries a problem in the background; success arrives as
+// a problemResolvedMsg (or, for devagent, a devagentRefreshMsg) and another
+// failure as a problemMsg
+func (m Model) retryProblemCmd(p problem) tea.Cmd {
+	switch p.retry {
+	case retryRoot:
+		watcher := m.watcher
+		return func() tea.Msg {
+			if watcher == nil {
+				return nil
+			}
+			if err := watcher.RetryRoot(p.key); err != nil {
+				return problemMsg{watcherProblem(err)}
+			}
+			return problemResolvedMsg{key: p.key}
+		}
+	case retryDevagent:
+		return m.devagentRefreshCmd()
+	}
+	return nil
+}
+
+// openProblems opens the problems panel, or says there are none
+func (m Model) openProblems() Model {
+	if len(m.problems) == 0 {
+		m.notice = m.theme.MutedStyle().Render("No problems")
+		return m
+	}
+	m.showProblems = true
+	m.problemIdx = min(m.problemIdx, len(m.problems)-1)
+	return m
+}
+
+// handleProblemsKey handles keys while the problems panel is open: j/k
+// select, r or enter retries, d dismisses, esc closes
+func (m Model) handleProblemsKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		m.problemIdx = min(m.problemIdx+1, len(m.problems)-1)
+	case "k", "up":
+		m.problemIdx = max(m.problemIdx-1, 0)
+	case "r", "enter":
+		if len(m.problems) == 0 {
+			break
+		}
+		p := m.problems[m.problemIdx]
+		if p.retry == retryNone {
+			m.notice = m.theme.MutedStyle().Render("Nothing to retry for " + p.key + "; d dismisses it")
+			return m, nil
+		}
+		m.notice = m.theme.MutedStyle().Render("Retrying " + p.key + "...")
+		return m, m.retryProblemCmd(p)
+	case "d":
+		m.problems = slices.Delete(slices.Clone(m.problems), m.problemIdx, m.problemIdx+1)
+		m.problemIdx = min(m.problemIdx, max(0, len(m.problems)-1))
+		m.showProblems = len(m.problems) > 0
+	case "esc", "q", "E":
+		m.showProblems = false
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// overlayProblems renders the problems panel centered over the view
+func (m Model) overlayProblems(background string) string {
+	width := max(40, min(100, m.width-14))
+	lines := []string{m.theme.DangerHeaderStyle().Render(fmt.Sprintf("Problems (%d)", len(m.problems))), ""}
+	for i, p := range m.problems {
+		cursor, style := "  ", m.theme.NormalItemStyle()
+		if i == m.problemIdx {
+			cursor, style = "> ", m.theme.SelectedItemStyle()
+		}
+		text := p.text
+		if lipgloss.Width(text) > width-2 {
+			text = truncateAnsi(m.theme, text, width-2-lipgloss.Width(ellipsis())) + ellipsis()
+		}
+		lines = append(lines, style.Render(cursor+text))
+
+		detail := fmt.Sprintf("%dx, last %s", p.count, formatTimeAgo(p.last))
+		if p.retry != retryNone {
+			detail += " · r to retry"
+		}
+		lines = append(lines, m.theme.MutedStyle().Render("  "+detail))
+	}
+	lines = append(lines, "", m.theme.NewStyle().Foreground(m.theme.Muted).Italic(true).Render(
+		strings.Join([]string{"j/k:select", "r:retry", "d:dismiss", "esc:close"}, " | ")))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return m.overlayDialog(background, content, lipgloss.Width(content)+6)
+}
+
+// renderProblemsBadge counts the open problems for the header
+func (m Model) renderProblemsBadge() string {
+	if len(m.problems) == 0 {
+		return ""
+	}
+	return m.theme.DangerStyle().Render(fmt.Sprintf("%d %s (E)", len(m.problems), pluralize(len(m.problems), "problem")))
+}
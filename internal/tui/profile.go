@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"slices"
+
+	"cc_session_mon/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startViews maps layout.start_view names to views
+var startViews = map[string]ViewMode{
+	"sessions":  ViewSessions,
+	"commands":  ViewCommands,
+	"patterns":  ViewPatterns,
+	"findings":  ViewFindings,
+	"analytics": ViewAnalytics,
+}
+
+// applyLayout sets up the views from the config's layout; the start view
+// only applies at startup, so switching profiles doesn't move the user
+func (m Model) applyLayout(startup bool) Model {
+	layout := config.Global().Layout
+	if view, ok := startViews[layout.StartView]; ok && startup {
+		m.viewMode = view
+	}
+	m.sessionsExpanded = layout.Summaries
+	m.sessionDelegate.expanded = layout.Summaries
+	m.heredocsExpanded = layout.ExpandHeredocs
+	m.thinkingShown = layout.ShowThinking
+	return m
+}
+
+// openLayoutDetail opens the detail panel on the selected command when the
+// layout asks for it on entering the Commands view
+func (m Model) openLayoutDetail() (Model, tea.Cmd) {
+	if !config.Global().Layout.DetailPanel || m.viewMode != ViewCommands || m.detailPanelOpen {
+		return m, nil
+	}
+	item, ok := m.commandList.SelectedItem().(commandItem)
+	if !ok {
+		return m, nil
+	}
+	cmd := item.command
+	m = m.openDetailPanel(&cmd)
+	return m.loadDetail(cmd)
+}
+
+// cycleProfile switches to the next saved profile, after the last one back
+// to the config file alone, and reports it in the footer
+func (m Model) cycleProfile() Model {
+	names, err := config.Profiles()
+	switch {
+	case err != nil:
+		m.notice = m.theme.ErrorStyle().UnsetPadding().Render("Could not list profiles: " + err.Error())
+		return m
+	case len(names) == 0:
+		m.notice = "No profiles in " + config.ProfilesDir() + " (add one with `cc_session_mon config profile import`)"
+		return m
+	}
+
+	order := append([]string{""}, names...)
+	next := order[(slices.Index(order, config.Global().Profile)+1)%len(order)]
+	cfg, err := config.LoadProfile(next)
+	if err != nil {
+		m.notice = m.theme.ErrorStyle().UnsetPadding().Render("Could not load the profile: " + err.Error())
+		return m
+	}
+
+	m = m.applyConfig(cfg)
+	if next == "" {
+		m.notice = "Profile: none (config file only)"
+	} else {
+		m.notice = "Profile: " + next
+	}
+	return m
+}
+
+// applyConfig makes cfg the global config and redraws everything it styles
+// or filters. Alert rules stay as they were at startup.
+func (m Model) applyConfig(cfg *config.Config) Model {
+	config.SetGlobal(cfg)
+	// Delegates share the theme, so it changes in place
+	*m.theme = *loadTheme(cfg.Theme, m.theme.renderer)
+	m = m.applyLayout(false)
+	m = m.updateListSizes()
+	m = m.updateSessionList()
+	m = m.updateCommandList()
+	m = m.aggregatePatterns()
+	m = m.aggregateFindings()
+	return m.aggregateAnalytics()
+}
+
+// profileLabel names the active profile for the header, or "" if none
+func profileLabel() string {
+	if name := config.Global().Profile; name != "" {
+		return "[" + name + "]"
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sessionRemovedMsg reports the outcome of deleting or archiving a session
+type sessionRemovedMsg struct {
+	project string
+	archive string // Where the session was archived, or "" when deleted
+	err     error
+}
+
+// handleRemoveKey handles keys while a session awaits removal: d deletes
+// it, a archives it, and any other key cancels
+func (m Model) handleRemoveKey(key string) (tea.Model, tea.Cmd) {
+	sess := m.confirmRemove
+	m.confirmRemove = nil
+	switch key {
+	case "d":
+		return m, m.removeSessionCmd(sess, false)
+	case "a":
+		return m, m.removeSessionCmd(sess, true)
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// removeSessionCmd deletes or archives a session's files
+func (m Model) removeSessionCmd(sess *session.Session, archive bool) tea.Cmd {
+	watcher := m.watcher
+	return func() tea.Msg {
+		msg := sessionRemovedMsg{project: filepath.Base(sess.ProjectPath)}
+		if watcher == nil {
+			msg.err = errors.New("sessions can't be removed without a watcher")
+			return msg
+		}
+		msg.archive, msg.err = watcher.RemoveSession(sess.FilePath, archive, config.Global().ArchiveDir)
+		return msg
+	}
+}
+
+// handleSessionRemoved drops a removed session from the lists and reports
+// the outcome in the footer
+func (m Model) handleSessionRemoved(msg sessionRemovedMsg) Model {
+	switch {
+	case msg.err != nil:
+		m.notice = m.theme.ErrorStyle().UnsetPadding().Render(fmt.Sprintf("Could not remove the %s session: %v", msg.project, msg.err))
+		return m
+	case msg.archive != "":
+		m.notice = fmt.Sprintf("Archived the %s session to %s", msg.project, msg.archive)
+	default:
+		m.notice = fmt.Sprintf("Deleted the %s session", msg.project)
+	}
+	m = m.handleSessionEvent(sessionEventMsg{Type: "removed"})
+	return m.updateCommandList()
+}
+
+// overlayRemoveDialog asks to confirm deleting or archiving a session,
+// listing the files it would affect
+func (m Model) overlayRemoveDialog(background string) string {
+	sess := m.confirmRemove
+	archiveDir := config.Global().ArchiveDir
+	if archiveDir == "" {
+		archiveDir = filepath.Join(ellipsis(), session.DefaultArchiveDir)
+	}
+
+	lines := []string{
+		m.theme.WarningHeaderStyle().Render("Remove session - " + filepath.Base(sess.ProjectPath)),
+		m.theme.MutedStyle().Render(fmt.Sprintf("%d cmds, last active %s", len(sess.Commands), formatTimeAgo(sess.LastActivity))),
+		"",
+		m.theme.LabelStyle().Render("Files:"),
+	}
+	for _, f := range session.SessionFiles(sess.FilePath) {
+		lines = append(lines, m.theme.PathStyle().Render(f))
+	}
+	if sess.IsActive {
+		lines = append(lines, "", m.theme.WarningStyle().Render("The session is active; it can't be removed until it is idle"))
+	}
+	lines = append(lines, "",
+		m.theme.DangerStyle().Bold(true).Render("d")+" delete permanently",
+		m.theme.WarningStyle().Bold(true).Render("a")+" archive to "+strings.TrimSuffix(archiveDir, "/")+"/",
+		m.theme.NewStyle().Foreground(m.theme.Muted).Italic(true).Render("Any other key cancels"),
+	)
+
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return m.overlayDialog(background, content, lipgloss.Width(content)+6)
+}
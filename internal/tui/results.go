@@ -0,0 +1,223 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cc_session_mon/internal/session"
+)
+
+// resultMaxLines caps the lines of a structured result in the detail panel
+const resultMaxLines = 20
+
+var (
+	// grepMatchLine is a Grep content-mode line with line numbers: path:line:text
+	grepMatchLine = regexp.MustCompile(`^(.+?):(\d+):(.*)$`)
+	// testFailLine and testPassLine mark test runner output (go test, pytest, jest, TAP)
+	testFailLine = regexp.MustCompile(`^\s*--- FAIL|^FAIL\b|\bFAILED\b|✕|✗|^\s*not ok\b`)
+	testPassLine = regexp.MustCompile(`^\s*--- PASS|^ok\s|^PASS\b|\bPASSED\b|✓|^\s*ok \d+\b`)
+)
+
+// formatResultBody renders a successful result by its tool and content:
+// pretty-printed JSON, Grep matches grouped by file, Glob paths as a tree,
+// and test output colored by pass/fail. Anything else is plain text.
+func formatResultBody(theme *Theme, input *session.ToolInput, width int) string {
+	switch {
+	case input.ToolName == "Grep":
+		if s, ok := formatGrepResult(theme, input.Result, width); ok {
+			return s
+		}
+	case input.ToolName == "Glob":
+		if s, ok := formatGlobResult(theme, input.Result, width); ok {
+			return s
+		}
+	case input.ToolName == "Bash" && isTestOutput(input.Result):
+		return formatTestResult(theme, input.Result, width)
+	}
+	if pretty, ok := prettyJSON(input.Result); ok {
+		return theme.CodeBlockStyle(width).Render(truncateMultiline(pretty, width-4, resultMaxLines))
+	}
+	return theme.CodeBlockStyle(width).Render(truncateMultiline(input.Result, width-4, 8))
+}
+
+// prettyJSON indents result when it is a JSON object or array
+func prettyJSON(result string) (string, bool) {
+	trimmed := strings.TrimSpace(result)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(trimmed), "", "  "); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// formatGrepResult renders Grep output as matches grouped under their file,
+// or as a file list; ok is false for other output modes (e.g. counts)
+func formatGrepResult(theme *Theme, result string, width int) (string, bool) {
+	var header, lines []string
+	for _, line := range strings.Split(strings.TrimSpace(result), "\n") {
+		if strings.HasPrefix(line, "Found ") {
+			header = append(header, line)
+		} else if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return "", false
+	}
+
+	var out []string
+	for _, h := range header {
+		out = append(out, theme.MutedStyle().Render(h))
+	}
+	if allMatch(lines, grepMatchLine) {
+		file := ""
+		for _, line := range lines {
+			m := grepMatchLine.FindStringSubmatch(line)
+			if m[1] != file {
+				file = m[1]
+				out = append(out, theme.PathStyle().Render(truncateLine(file, width)))
+			}
+			num := theme.MutedStyle().Render(fmt.Sprintf("%6s ", m[2]))
+			out = append(out, num+truncateLine(strings.TrimSpace(m[3]), width-7))
+		}
+	} else {
+		for _, line := range lines {
+			if strings.Contains(line, ": ") || strings.Contains(line, "\t") {
+				return "", false // Not a file list
+			}
+			out = append(out, theme.PathStyle().Render(truncateLine(line, width)))
+		}
+	}
+	return capLines(theme, out), true
+}
+
+// formatGlobResult renders Glob output as a tree under the paths' common
+// directory; ok is false when the result isn't a path list
+func formatGlobResult(theme *Theme, result string, width int) (string, bool) {
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(result), "\n") {
+		if line == "" {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			return "", false // e.g. "No files found"
+		}
+		paths = append(paths, filepath.Clean(line))
+	}
+	if len(paths) == 0 {
+		return "", false
+	}
+	sort.Strings(paths)
+
+	root := filepath.Dir(paths[0])
+	for _, p := range paths[1:] {
+		// Stop at the filesystem root ("/", or a drive root like C:\)
+		for filepath.Dir(root) != root && !strings.HasPrefix(p, root+string(filepath.Separator)) {
+			root = filepath.Dir(root)
+		}
+	}
+
+	out := []string{theme.PathStyle().Render(truncateLine(root+"/", width))}
+	var prev []string
+	for _, p := range paths {
+		rel, _ := filepath.Rel(root, p)
+		parts := strings.Split(rel, string(filepath.Separator))
+		shared := 0
+		for shared < len(prev)-1 && shared < len(parts)-1 && prev[shared] == parts[shared] {
+			shared++
+		}
+		for depth := shared; depth < len(parts); depth++ {
+			name := parts[depth]
+			if depth < len(parts)-1 {
+				name += "/"
+			}
+			out = append(out, truncateLine(strings.Repeat("  ", depth+1)+name, width))
+		}
+		prev = parts
+	}
+	return capLines(theme, out), true
+}
+
+// isTestOutput reports whether result looks like test runner output
+func isTestOutput(result string) bool {
+	for _, line := range strings.Split(result, "\n") {
+		if testFailLine.MatchString(line) || testPassLine.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTestResult renders test output with passes in green and failures
+// in red, keeping the end of long output where runners print their summary
+func formatTestResult(theme *Theme, result string, width int) string {
+	lines := strings.Split(strings.TrimRight(result, "\n"), "\n")
+	var out []string
+	if len(lines) > resultMaxLines {
+		out = append(out, theme.MutedStyle().Render(fmt.Sprintf("... %d earlier lines", len(lines)-resultMaxLines)))
+		lines = lines[len(lines)-resultMaxLines:]
+	}
+	for _, line := range lines {
+		line = truncateLine(strings.ReplaceAll(line, "\t", "  "), width)
+		switch {
+		case testFailLine.MatchString(line):
+			out = append(out, theme.DeletionStyle().Render(line))
+		case testPassLine.MatchString(line):
+			out = append(out, theme.AdditionStyle().Render(line))
+		default:
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// tailLines keeps the last maxLines lines of text, cut to width, noting how
+// many came before
+func tailLines(theme *Theme, text string, width, maxLines int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	var out []string
+	if len(lines) > maxLines {
+		out = append(out, theme.MutedStyle().Render(fmt.Sprintf("... %d earlier lines", len(lines)-maxLines)))
+		lines = lines[len(lines)-maxLines:]
+	}
+	for _, line := range lines {
+		out = append(out, truncateLine(strings.ReplaceAll(line, "\t", "  "), width))
+	}
+	return strings.Join(out, "\n")
+}
+
+// allMatch reports whether every line matches re
+func allMatch(lines []string, re *regexp.Regexp) bool {
+	for _, line := range lines {
+		if !re.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// capLines joins rendered lines, cutting them at resultMaxLines
+func capLines(theme *Theme, lines []string) string {
+	if len(lines) > resultMaxLines {
+		more := len(lines) - resultMaxLines
+		lines = append(lines[:resultMaxLines], theme.MutedStyle().Render(fmt.Sprintf("... %d more", more)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateLine cuts a line to width runes, ending it with "..."
+func truncateLine(line string, width int) string {
+	runes := []rune(line)
+	if width < 4 || len(runes) <= width {
+		return line
+	}
+	return string(runes[:width-3]) + "..."
+}
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"cc_session_mon/internal/session"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// sessionInput is a completed tool call with the given result
+func sessionInput(tool, result string) *session.ToolInput {
+	return &session.ToolInput{ToolName: tool, Result: result, HasResult: true}
+}
+
+func TestFormatResultBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   string
+		result string
+		want   []string // Lines expected in order, ANSI stripped and trimmed
+	}{
+		{"JSON", "Bash", `{"a":1,"b":[true]}`, []string{"{", `"a": 1,`, `"b": [`, "true", "]", "}"}},
+		{"grep matches", "Grep", "/src/a.go:3:func A() {}\n/src/a.go:9:  return\n/src/b.go:1:package b",
+			[]string{"/src/a.go", "3 func A() {}", "9 return", "/src/b.go", "1 package b"}},
+		{"grep files", "Grep", "Found 2 files\n/src/a.go\n/src/b.go", []string{"Found 2 files", "/src/a.go", "/src/b.go"}},
+		{"glob tree", "Glob", "/p/internal/tui/view.go\n/p/internal/tui/model.go\n/p/main.go",
+			[]string{"/p/", "internal/", "tui/", "model.go", "view.go", "main.go"}},
+		{"plain text", "Bash", "hello", []string{"hello"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := sessionInput(tt.tool, tt.result)
+			var got []string
+			for _, line := range strings.Split(ansi.Strip(formatResultBody(testTheme(), input, 60)), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					got = append(got, line)
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestOutputDetection(t *testing.T) {
+	out := "=== RUN   TestA\n--- PASS: TestA (0.00s)\n--- FAIL: TestB (0.01s)\nFAIL\nFAIL\tpkg\t0.1s"
+	if !isTestOutput(out) {
+		t.Fatal("expected go test output to be detected")
+	}
+	if isTestOutput("total 8\ndrwxr-xr-x  2 josh staff  64 Jan 1 main.go") {
+		t.Error("expected ls output not to be treated as test output")
+	}
+	if got := ansi.Strip(formatTestResult(testTheme(), out, 60)); !strings.Contains(got, "--- FAIL: TestB") {
+		t.Errorf("expected failures kept, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cc_session_mon/internal/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sourceClosedMsg reports the editor or pager showing a command's JSONL
+// record exiting
+type sourceClosedMsg struct {
+	program string
+	err     error
+}
+
+// openSourceCmd hands the terminal to $VISUAL or $EDITOR, or without one to
+// $PAGER (less by default), at the selected command's line in its session
+// file, for the raw record in context
+func (m Model) openSourceCmd() tea.Cmd {
+	c := m.currentCommand()
+	if c == nil {
+		return nil
+	}
+	fail := func(err error) tea.Cmd {
+		return func() tea.Msg { return sourceClosedMsg{err: err} }
+	}
+	if c.FilePath == "" {
+		return fail(errors.New("this command has no session file"))
+	}
+	if _, err := os.Stat(c.FilePath); err != nil {
+		return fail(fmt.Errorf("session file: %w", err)) // e.g. a viewer of another host's sessions
+	}
+
+	argv := sourceCommand(os.Getenv("VISUAL"), os.Getenv("EDITOR"), os.Getenv("PAGER"), c)
+	cmd := exec.Command(argv[0], argv[1:]...) //nolint:gosec // the user's own editor or pager
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			err = fmt.Errorf("%s: %w", argv[0], err)
+		}
+		return sourceClosedMsg{program: argv[0], err: err}
+	})
+}
+
+// sourceCommand returns the command line opening c's session file at its
+// line: the editor's own syntax for the line (+N for vi, emacs, nano and
+// most others), else the pager's (+Ng for less, +N for more)
+func sourceCommand(visual, editor, pager string, c *session.CommandEntry) []string {
+	line := strconv.Itoa(max(c.LineNumber, 1))
+	if argv := strings.Fields(firstNonEmpty(visual, editor)); len(argv) > 0 {
+		switch filepath.Base(argv[0]) {
+		case "code", "code-insiders", "codium", "cursor":
+			return append(argv, "--goto", c.FilePath+":"+line)
+		case "subl", "hx", "helix", "zed":
+			return append(argv, c.FilePath+":"+line)
+		default:
+			return append(argv, "+"+line, c.FilePath)
+		}
+	}
+
+	argv := strings.Fields(firstNonEmpty(pager, "less"))
+	switch filepath.Base(argv[0]) {
+	case "less":
+		return append(argv, "-N", "+"+line+"g", c.FilePath)
+	case "more", "most":
+		return append(argv, "+"+line, c.FilePath)
+	default:
+		return append(argv, c.FilePath)
+	}
+}
+
+// handleSourceClosed reports a failure to open the session file as a toast
+func (m Model) handleSourceClosed(msg sourceClosedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		return m.addToast(msg.err)
+	}
+	m.notice = "Closed " + msg.program
+	return m, nil
+}
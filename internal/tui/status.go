@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
+)
+
+// Header status bar windows
+const (
+	rateWindow         = 5 * time.Minute  // Commands per minute are averaged over this window
+	dangerWindow       = 10 * time.Minute // Dangerous commands are counted within this window
+	watcherErrorWindow = 5 * time.Minute  // A watcher error marks the watcher degraded this long
+)
+
+// statusCounters are the global counters shown in the header
+type statusCounters struct {
+	sessions  int
+	active    int
+	perMinute float64 // Commands per minute across all sessions, over rateWindow
+	dangerous int     // High-severity commands across all sessions within dangerWindow
+}
+
+// countStatus computes the header counters at now
+func (m Model) countStatus(now time.Time) statusCounters {
+	var c statusCounters
+	recent := 0
+	for _, sess := range m.sessions {
+		c.sessions++
+		if sess.IsActive {
+			c.active++
+		}
+		var cfg *config.Config
+		for i := range sess.Commands {
+			cmd := &sess.Commands[i]
+			age := now.Sub(cmd.Timestamp)
+			if age < 0 || age >= max(rateWindow, dangerWindow) {
+				continue
+			}
+			if age < rateWindow {
+				recent++
+			}
+			if age >= dangerWindow {
+				continue
+			}
+			if cfg == nil {
+				cfg = config.ForProject(sess.ProjectPath)
+			}
+			for _, f := range security.CommandFindings(cmd, sess.ProjectPath, cfg, sess.Commands) {
+				if f.Severity == security.SeverityHigh {
+					c.dangerous++
+					break
+				}
+			}
+		}
+	}
+	c.perMinute = float64(recent) / rateWindow.Minutes()
+	return c
+}
+
+// renderStatusBar renders the header's counters, most important first, so a
+// narrow header can drop them from the end
+func (m Model) renderStatusBar(now time.Time) []string {
+	if len(m.sessions) == 0 {
+		return withBadges([]string{m.theme.StatusStyle().Render("No sessions found"), m.renderWatcherHealth(now)}, m.renderProblemsBadge(), m.renderLogBadge())
+	}
+
+	c := m.countStatus(now)
+	parts := []string{m.theme.StatusStyle().Render(fmt.Sprintf("%d sessions (%d active)", c.sessions, c.active))}
+
+	dangerous := m.theme.MutedStyle().Render("0 dangerous/10m")
+	if c.dangerous > 0 {
+		dangerous = m.theme.DangerStyle().Bold(true).Render(fmt.Sprintf("%d dangerous/10m", c.dangerous))
+	}
+	parts = withBadges(append(parts, dangerous, m.renderWatcherHealth(now)), m.renderProblemsBadge(), m.renderLogBadge())
+
+	if m.pendingAlerts != nil {
+		pending := m.theme.MutedStyle().Render("0 alerts pending")
+		if n := m.pendingAlerts(); n > 0 {
+			pending = m.theme.WarningStyle().Render(fmt.Sprintf("%d %s pending", n, pluralize(n, "alert")))
+		}
+		parts = append(parts, pending)
+	}
+	return append(parts, m.theme.MutedStyle().Render(fmt.Sprintf("%.1f cmd/min", c.perMinute)))
+}
+
+// renderWatcherHealth shows whether the file watcher is running and, for a
+// while after one, how many errors it reported
+func (m Model) renderWatcherHealth(now time.Time) string {
+	switch {
+	case !m.watching:
+		return m.theme.MutedStyle().Render(indicator("○ starting", "[STARTING]"))
+	case m.watcher != nil && m.watcher.Polling() && !m.watcher.IsReplica():
+		return m.theme.WarningStyle().Render(indicator("◐ polling", "[POLLING]"))
+	case !m.lastWatcherError.IsZero() && now.Sub(m.lastWatcherError) < watcherErrorWindow:
+		return m.theme.WarningStyle().Bold(true).Render(fmt.Sprintf("%swatcher: %d %s", indicator("⚠ ", "[WARNING] "), m.watcherErrors, pluralize(m.watcherErrors, "error")))
+	default:
+		return m.theme.ActiveIndicatorStyle().Render(indicator("● watching", "[WATCHING]"))
+	}
+}
+
+// withBadges adds the problem and log warning counts after the watcher
+// health, leaving out empty ones
+func withBadges(parts []string, badges ...string) []string {
+	for _, b := range badges {
+		if b != "" {
+			parts = append(parts, b)
+		}
+	}
+	return parts
+}
+
+// pluralize appends an "s" to word unless n is 1
+func pluralize(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// joinStatus joins status bar parts with a separator
+func joinStatus(theme *Theme, parts []string) string {
+	return strings.Join(parts, theme.MutedStyle().Render(" · "))
+}
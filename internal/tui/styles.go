@@ -7,8 +7,10 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Theme holds the current color palette
+// Theme holds a model's color palette and builds its styles with the
+// renderer for the model's terminal
 type Theme struct {
+	renderer  *lipgloss.Renderer
 	flavor    catppuccin.Flavor
 	Primary   lipgloss.Color
 	Secondary lipgloss.Color
@@ -21,19 +23,12 @@ type Theme struct {
 	Text      lipgloss.Color
 }
 
-// currentTheme is the active theme
-var currentTheme *Theme
-
-// GetTheme returns the current theme, initializing if needed
-func GetTheme() *Theme {
-	if currentTheme == nil {
-		currentTheme = loadTheme(config.Global().Theme)
+// loadTheme creates a Theme from a catppuccin flavor name, rendering with r
+// (lipgloss's default renderer if nil)
+func loadTheme(name string, r *lipgloss.Renderer) *Theme {
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
 	}
-	return currentTheme
-}
-
-// loadTheme creates a Theme from a catppuccin flavor name
-func loadTheme(name string) *Theme {
 	var flavor catppuccin.Flavor
 
 	switch name {
@@ -50,6 +45,7 @@ func loadTheme(name string) *Theme {
 	}
 
 	return &Theme{
+		renderer:  r,
 		flavor:    flavor,
 		Primary:   lipgloss.Color(flavor.Mauve().Hex),
 		Secondary: lipgloss.Color(flavor.Green().Hex),
@@ -63,6 +59,11 @@ func loadTheme(name string) *Theme {
 	}
 }
 
+// NewStyle returns an empty style rendered for the theme's terminal
+func (t *Theme) NewStyle() lipgloss.Style {
+	return t.renderer.NewStyle()
+}
+
 // ColorByName returns a lipgloss.Color for a catppuccin color name
 func (t *Theme) ColorByName(name string) lipgloss.Color {
 	if getter, ok := t.colorGetters()[name]; ok {
@@ -106,143 +107,127 @@ func (t *Theme) colorGetters() map[string]func() catppuccin.Color {
 
 // Style accessors - these create styles dynamically based on current theme
 
-func TitleStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) TitleStyle() lipgloss.Style {
+	return t.NewStyle().
 		Bold(true).
 		Foreground(t.Primary)
 }
 
-func StatusStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) StatusStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Muted)
 }
 
-func ActiveIndicatorStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) ActiveIndicatorStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Secondary).
 		Bold(true)
 }
 
-func InactiveIndicatorStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) InactiveIndicatorStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Muted)
 }
 
-func ErrorStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) ErrorStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Danger).
 		Bold(true).
 		Padding(1)
 }
 
-func ActiveTabStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) ActiveTabStyle() lipgloss.Style {
+	return t.NewStyle().
 		Bold(true).
 		Background(t.Primary).
 		Foreground(t.Base).
 		Padding(0, 2)
 }
 
-func InactiveTabStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) InactiveTabStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Muted).
 		Padding(0, 2)
 }
 
-func TabGapStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) TabGapStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Muted)
 }
 
-func SelectedItemStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) SelectedItemStyle() lipgloss.Style {
+	return t.NewStyle().
 		Background(t.Surface).
 		Foreground(t.Text).
 		Bold(true)
 }
 
-func NormalItemStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) NormalItemStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Text)
 }
 
-func ActiveSessionStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) ActiveSessionStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Secondary).
 		Bold(true)
 }
 
-func InactiveSessionStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) InactiveSessionStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Muted)
 }
 
-func CountBadgeStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) CountBadgeStyle() lipgloss.Style {
+	return t.NewStyle().
 		Background(t.Primary).
 		Foreground(t.Base).
 		Padding(0, 1)
 }
 
-func ExampleStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) ExampleStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Muted).
 		Italic(true)
 }
 
-func HelpStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) HelpStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Muted)
 }
 
 // SearchBarStyle returns style for the search bar container
-func SearchBarStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) SearchBarStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Muted)
 }
 
-func ColumnHeaderStyle(width int) lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) ColumnHeaderStyle(width int) lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Text).
 		Background(t.Surface1).
 		Bold(true).
 		Width(width)
 }
 
-func TimestampStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) TimestampStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Muted).
 		Width(8)
 }
 
 // StyleForPattern returns appropriate style based on pattern
-func StyleForPattern(pattern string) lipgloss.Style {
-	t := GetTheme()
+func (t *Theme) StyleForPattern(pattern string) lipgloss.Style {
+	return t.styleForGroup(config.Global().GetToolGroup(pattern))
+}
 
-	group := config.Global().GetToolGroup(pattern)
+// styleForGroup returns the style for a tool group (normal style if nil)
+func (t *Theme) styleForGroup(group *config.ToolGroup) lipgloss.Style {
 	if group == nil {
-		return NormalItemStyle()
+		return t.NormalItemStyle()
 	}
 
-	style := lipgloss.NewStyle().Foreground(t.ColorByName(group.Color))
+	style := t.NewStyle().Foreground(t.ColorByName(group.Color))
 	if group.Bold {
 		style = style.Bold(true)
 	}
@@ -252,9 +237,8 @@ func StyleForPattern(pattern string) lipgloss.Style {
 // Detail panel styles
 
 // DetailHeaderStyle returns style for detail panel header
-func DetailHeaderStyle(width int) lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) DetailHeaderStyle(width int) lipgloss.Style {
+	return t.NewStyle().
 		Bold(true).
 		Foreground(t.Text).
 		Background(t.Surface1).
@@ -262,25 +246,46 @@ func DetailHeaderStyle(width int) lipgloss.Style {
 		Padding(0, 1)
 }
 
+// DetailHeaderFocusedStyle returns the detail panel header style while the
+// panel has keyboard focus
+func (t *Theme) DetailHeaderFocusedStyle(width int) lipgloss.Style {
+	return t.DetailHeaderStyle(width).
+		Foreground(t.Base).
+		Background(t.Primary)
+}
+
 // LabelStyle returns style for field labels in detail panel
-func LabelStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) LabelStyle() lipgloss.Style {
+	return t.NewStyle().
 		Bold(true).
 		Foreground(t.Primary)
 }
 
 // PathStyle returns style for file paths
-func PathStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) PathStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Secondary)
 }
 
+// CodeTextStyle returns the text colors of CodeBlockStyle, for styling
+// spans within a code block
+func (t *Theme) CodeTextStyle() lipgloss.Style {
+	return t.NewStyle().
+		Background(t.Surface).
+		Foreground(t.Text)
+}
+
+// SearchMatchStyle returns style for search matches within command text
+func (t *Theme) SearchMatchStyle() lipgloss.Style {
+	return t.NewStyle().
+		Background(t.Warning).
+		Foreground(t.Base).
+		Bold(true)
+}
+
 // CodeBlockStyle returns style for code blocks
-func CodeBlockStyle(width int) lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) CodeBlockStyle(width int) lipgloss.Style {
+	return t.NewStyle().
 		Background(t.Surface).
 		Foreground(t.Text).
 		Width(width).
@@ -288,37 +293,82 @@ func CodeBlockStyle(width int) lipgloss.Style {
 }
 
 // DangerHeaderStyle returns style for security warning headers
-func DangerHeaderStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) DangerHeaderStyle() lipgloss.Style {
+	return t.NewStyle().
 		Bold(true).
 		Foreground(t.Danger)
 }
 
 // DangerStyle returns style for security warning text
-func DangerStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) DangerStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Danger)
 }
 
+// ToastStyle returns style for transient error banners
+func (t *Theme) ToastStyle() lipgloss.Style {
+	return t.NewStyle().
+		Foreground(t.Base).
+		Background(t.Danger).
+		Bold(true).
+		Padding(0, 1)
+}
+
 // WarningStyle returns style for warning/caution text
-func WarningStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) WarningStyle() lipgloss.Style {
+	return t.NewStyle().
+		Foreground(t.Warning)
+}
+
+// WarningHeaderStyle returns style for caution section headers
+func (t *Theme) WarningHeaderStyle() lipgloss.Style {
+	return t.NewStyle().
+		Bold(true).
 		Foreground(t.Warning)
 }
 
 // DeletionStyle returns style for deleted/old content in diffs
-func DeletionStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) DeletionStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Danger)
 }
 
 // AdditionStyle returns style for added/new content in diffs
-func AdditionStyle() lipgloss.Style {
-	t := GetTheme()
-	return lipgloss.NewStyle().
+func (t *Theme) AdditionStyle() lipgloss.Style {
+	return t.NewStyle().
+		Foreground(t.Secondary)
+}
+
+// Syntax highlighting styles for code in the detail panel
+
+// SyntaxKeywordStyle returns style for language keywords
+func (t *Theme) SyntaxKeywordStyle() lipgloss.Style {
+	return t.NewStyle().
+		Bold(true).
+		Foreground(t.Primary)
+}
+
+// SyntaxStringStyle returns style for string literals
+func (t *Theme) SyntaxStringStyle() lipgloss.Style {
+	return t.NewStyle().
 		Foreground(t.Secondary)
 }
+
+// SyntaxCommentStyle returns style for comments
+func (t *Theme) SyntaxCommentStyle() lipgloss.Style {
+	return t.NewStyle().
+		Italic(true).
+		Foreground(t.Muted)
+}
+
+// SyntaxNumberStyle returns style for numeric literals
+func (t *Theme) SyntaxNumberStyle() lipgloss.Style {
+	return t.NewStyle().
+		Foreground(t.ColorByName("peach"))
+}
+
+// MarkerStyle returns style for compaction and restart dividers in the command list
+func (t *Theme) MarkerStyle() lipgloss.Style {
+	return t.NewStyle().
+		Foreground(t.ColorByName("sky"))
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Error toasts: non-fatal errors (errMsg) show as banners in the top right
+// corner for toastDuration while the UI keeps working. Only a watcher that
+// couldn't be created at startup (Model.err) takes over the screen.
+const (
+	toastDuration = 6 * time.Second
+	maxToasts     = 3 // Older toasts give way to newer ones
+)
+
+// toast is an error banner
+type toast struct {
+	id    int // Expiry key; a repeat of the error gets a new one
+	text  string
+	count int // Times the error arrived while shown
+}
+
+// toastExpiredMsg removes the toast with id, unless a repeat renewed it
+type toastExpiredMsg struct{ id int }
+
+// addToast shows err as a toast, or renews and counts the toast already
+// showing it, and returns the command that expires it
+func (m Model) addToast(err error) (Model, tea.Cmd) {
+	m.toastSeq++
+	t := toast{id: m.toastSeq, text: strings.ReplaceAll(err.Error(), "\n", " "), count: 1}
+	toasts := slices.DeleteFunc(slices.Clone(m.toasts), func(o toast) bool {
+		if o.text == t.text {
+			t.count += o.count
+			return true
+		}
+		return false
+	})
+	toasts = append(toasts, t)
+	m.toasts = toasts[max(0, len(toasts)-maxToasts):]
+	return m, tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpiredMsg{t.id} })
+}
+
+// expireToast removes a toast whose time is up
+func (m Model) expireToast(msg toastExpiredMsg) Model {
+	m.toasts = slices.DeleteFunc(slices.Clone(m.toasts), func(t toast) bool { return t.id == msg.id })
+	return m
+}
+
+// overlayToasts draws the toasts right-aligned over the top of the content,
+// below the header and tabs, newest last
+func (m Model) overlayToasts(background string) string {
+	if len(m.toasts) == 0 {
+		return background
+	}
+	width := max(20, min(80, m.width-4))
+	bgLines := strings.Split(background, "\n")
+	for i, t := range m.toasts {
+		row := 2 + i
+		if row >= len(bgLines) {
+			break
+		}
+		text := indicator("✗ ", "[ERROR] ") + t.text
+		if t.count > 1 {
+			text += fmt.Sprintf(" (x%d)", t.count)
+		}
+		if lipgloss.Width(text) > width-2 {
+			text = truncateAnsi(m.theme, text, width-2-lipgloss.Width(ellipsis())) + ellipsis()
+		}
+		banner := m.theme.ToastStyle().Render(text)
+		col := max(0, m.width-lipgloss.Width(banner)-1)
+		bgLine := bgLines[row]
+		if w := lipgloss.Width(bgLine); w < col {
+			bgLine += strings.Repeat(" ", col-w)
+		}
+		bgLines[row] = placeover(m.theme, bgLine, banner, col)
+	}
+	return strings.Join(bgLines, "\n")
+}
@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"cc_session_mon/internal/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// touchedRow is a visible row of the touched paths tree
+type touchedRow struct {
+	node    *session.TouchedNode
+	depth   int
+	outside bool // Under the tree of paths outside the project
+}
+
+// touchedRows flattens the active session's touched paths trees into the
+// rows not hidden by a collapsed directory
+func (m Model) touchedRows() []touchedRow {
+	sess := m.ActiveSession()
+	if sess == nil {
+		return nil
+	}
+	project, outside := session.TouchedPaths(sess)
+
+	var rows []touchedRow
+	var walk func(n *session.TouchedNode, depth int, out bool)
+	walk = func(n *session.TouchedNode, depth int, out bool) {
+		rows = append(rows, touchedRow{node: n, depth: depth, outside: out})
+		if m.touchedCollapsed[n.Path] {
+			return
+		}
+		for _, c := range n.Children {
+			walk(c, depth+1, out)
+		}
+	}
+	walk(project, 0, false)
+	if outside != nil {
+		walk(outside, 0, true)
+	}
+	return rows
+}
+
+// handleTouchedKey handles keys while the touched paths tree is open: moving
+// the selection, collapsing and expanding directories, and closing
+func (m Model) handleTouchedKey(key string) (tea.Model, tea.Cmd) {
+	rows := m.touchedRows()
+	if len(rows) == 0 {
+		m.showTouched = false
+		return m, nil
+	}
+	m.touchedIdx = min(m.touchedIdx, len(rows)-1)
+	selected := rows[m.touchedIdx].node
+
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q", "T":
+		m.showTouched = false
+	case "j", "down":
+		m.touchedIdx = min(m.touchedIdx+1, len(rows)-1)
+	case "k", "up":
+		m.touchedIdx = max(m.touchedIdx-1, 0)
+	case "g", "home":
+		m.touchedIdx = 0
+	case "G", "end":
+		m.touchedIdx = len(rows) - 1
+	case "enter", " ":
+		if selected.IsDir() {
+			m.touchedCollapsed[selected.Path] = !m.touchedCollapsed[selected.Path]
+		}
+	case "l", "right":
+		delete(m.touchedCollapsed, selected.Path)
+	case "h", "left":
+		if selected.IsDir() {
+			m.touchedCollapsed[selected.Path] = true
+		}
+	}
+	return m, nil
+}
+
+// handleTouchedTree handles the 'T' key to show the paths the active session touched
+func (m Model) handleTouchedTree(key string) (Model, bool) {
+	if key == "T" && (m.viewMode == ViewSessions || m.viewMode == ViewCommands) {
+		if m.ActiveSession() != nil {
+			m.showTouched = true
+			m.touchedIdx = 0
+			m.touchedCollapsed = make(map[string]bool)
+			return m, true
+		}
+	}
+	return m, false
+}
+
+// overlayTouchedTree renders the active session's touched paths as a
+// collapsible tree centered over the existing view: written files in the
+// Write group's color, files only read in the Read group's
+func (m Model) overlayTouchedTree(background string) string {
+	sess := m.ActiveSession()
+	if sess == nil {
+		return background
+	}
+
+	rows := m.touchedRows()
+	lines := []string{m.theme.LabelStyle().Render("Touched paths - " + filepath.Base(sess.ProjectPath)), ""}
+	if len(rows) == 1 && len(rows[0].node.Children) == 0 {
+		lines = append(lines, m.theme.MutedStyle().Render("No files read or written"))
+	}
+
+	// Only the rows around the selection that fit the screen
+	height := max(5, m.height-14)
+	start := max(0, min(m.touchedIdx-height/2, len(rows)-height))
+	end := min(len(rows), start+height)
+	width := 0
+	for i := start; i < end; i++ {
+		row := rows[i]
+		if row.depth == 0 && row.outside {
+			lines = append(lines, m.theme.DangerStyle().Render("Outside the project"))
+		}
+		line := m.renderTouchedRow(row, i == m.touchedIdx)
+		width = max(width, lipgloss.Width(line))
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", m.theme.NewStyle().Foreground(m.theme.Muted).Italic(true).Render(
+		"j/k:select  enter:collapse/expand  h/l:collapse/expand  esc:close"))
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return m.overlayDialog(background, content, max(width, lipgloss.Width(content))+6)
+}
+
+// renderTouchedRow renders one tree row, e.g. "  ▾ internal/tui/  3 reads, 1 write"
+func (m Model) renderTouchedRow(row touchedRow, selected bool) string {
+	n := row.node
+	marker := "  "
+	name := n.Name
+	if n.IsDir() {
+		marker = indicator("▾ ", "- ")
+		if m.touchedCollapsed[n.Path] {
+			marker = indicator("▸ ", "+ ")
+		}
+		name = strings.TrimSuffix(name, string(filepath.Separator)) + string(filepath.Separator)
+	}
+
+	pattern := "Read"
+	if n.Writes > 0 {
+		pattern = "Write"
+	}
+	style := m.theme.styleForGroup(toolGroupFor(nil, pattern))
+	if row.outside && n.Writes > 0 {
+		style = m.theme.DangerStyle()
+	}
+	if selected {
+		style = style.Background(m.theme.Surface).Bold(true)
+	}
+	counts := m.theme.MutedStyle().Render(fmt.Sprintf("  %d %s, %d %s",
+		n.Reads, pluralize(n.Reads, "read"), n.Writes, pluralize(n.Writes, "write")))
+	return strings.Repeat("  ", row.depth) + style.Render(marker+name) + counts
+}
@@ -1,8 +1,14 @@
 package tui
 
 import (
+	"errors"
+	"time"
+
+	"cc_session_mon/internal/applog"
+	"cc_session_mon/internal/config"
 	"cc_session_mon/internal/session"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -28,42 +34,165 @@ func (m Model) handleNonKeyMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case sessionsDiscoveredMsg:
 		m.sessions = msg
+		m = m.sortSessions()
 		m = m.updateSessionList()
 		m = m.updateCommandList()
 		m = m.aggregatePatterns()
+		cmds = append(cmds, m.diskUsageCmd(), m.gitSnapshotCmd())
+		var load tea.Cmd
+		m, load = m.openLayoutDetail()
+		cmds = append(cmds, load)
 
 		// Start watching for updates
 		if m.watcher != nil {
 			m.watcher.Start()
+			m.watching = true
 			cmds = append(cmds, m.watchSessionsCmd())
 		}
+		if m.showOnboarding() {
+			m.scannedDirs = m.scanProjectsDirs()
+		}
+		if m.pendingLink != nil {
+			var focus tea.Cmd
+			m, focus = m.focusLink()
+			cmds = append(cmds, focus)
+			if m.pendingLink != nil {
+				m.notice = "Session " + m.pendingLink.SessionID + " not found yet; waiting for it"
+			}
+		}
 
 	case sessionEventMsg:
 		m = m.handleSessionEvent(msg)
-		cmds = append(cmds, m.watchSessionsCmd())
+		var focus tea.Cmd
+		m, focus = m.focusLink()
+		cmds = append(cmds, focus, m.watchSessionsCmd(), m.gitSnapshotCmd())
 
 	case tickMsg:
 		m = m.handleTick()
-		cmds = append(cmds, m.tickCmd())
-		if m.followDevagent {
-			cmds = append(cmds, m.devagentRefreshCmd())
+		cmds = append(cmds, m.tickCmd(), m.diskUsageCmd(), m.gitSnapshotCmd(), m.projectsDirsCmd())
+		if m.watcher != nil && m.watcher.Polling() {
+			cmds = append(cmds, m.catchUpCmd())
 		}
 
+	case diskUsageMsg:
+		m = m.handleDiskUsage(msg)
+
+	case gitSnapshotMsg:
+		m.gitSnapshots[msg.path] = msg
+
+	case gitDiffMsg:
+		m = m.handleGitDiffMsg(msg)
+
+	case addDirSavedMsg:
+		m = m.handleAddDirSaved(msg)
+
+	case devagentEnabledMsg:
+		var cmd tea.Cmd
+		m, cmd = m.handleDevagentEnabled(msg)
+		cmds = append(cmds, cmd)
+
+	case tea.ResumeMsg:
+		// Back from Ctrl+Z: repaint, and read what changed while suspended
+		m = m.handleTick()
+		cmds = append(cmds, tea.ClearScreen, m.catchUpCmd())
+
+	case devagentTickMsg:
+		cmds = append(cmds, m.devagentTickCmd(), m.devagentRefreshCmd())
+
+	case subagentScanTickMsg:
+		cmds = append(cmds, m.subagentScanTickCmd(), m.subagentScanCmd())
+
 	case errMsg:
-		m.err = msg.error
+		applog.Warnf("error: %v", msg.error)
+		var cmd tea.Cmd
+		m, cmd = m.addToast(msg.error)
+		cmds = append(cmds, cmd)
+
+	case toastExpiredMsg:
+		m = m.expireToast(msg)
+
+	case watcherErrorMsg:
+		applog.Warnf("watcher: %v", msg.error)
+		// The watcher keeps running; the header shows it as degraded
+		m.watcherErrors++
+		m.lastWatcherError = time.Now()
+		// Hitting the watch limit needs the user to act, so say how
+		var limit *session.WatchLimitError
+		if errors.As(msg.error, &limit) {
+			m.notice = m.theme.WarningStyle().Render(limit.Error())
+		}
+		var cmd tea.Cmd
+		m, cmd = m.recordProblem(watcherProblem(msg.error))
+		cmds = append(cmds, m.watchSessionsCmd(), cmd)
+
+	case problemMsg:
+		var cmd tea.Cmd
+		m, cmd = m.recordProblem(msg.problem)
+		cmds = append(cmds, cmd)
+
+	case problemResolvedMsg:
+		m = m.resolveProblem(msg.key)
 
 	case detailLoadedMsg:
+		m.detailCache.put(msg)
+		// Drop loads for a command that is no longer selected
+		if m.selectedCommand == nil || detailKey(*m.selectedCommand) != msg.key {
+			break
+		}
 		m.loadingDetail = false
-		m.loadedInput = msg
+		m.loadedInput = msg.input
+		m.scriptRuns = msg.scriptRuns
+		// Keep reloading until the command's output lands
+		if m.resultPending() {
+			cmds = append(cmds, m.detailPollCmd(), m.detailSpinner.Tick)
+		}
+
+	case sessionRemovedMsg:
+		m = m.handleSessionRemoved(msg)
+
+	case linkCopiedMsg:
+		m = m.handleLinkCopied(msg)
+
+	case sourceClosedMsg:
+		var cmd tea.Cmd
+		m, cmd = m.handleSourceClosed(msg)
+		cmds = append(cmds, cmd)
+
+	case pathActionDoneMsg:
+		m.pathMenuStatus, m.pathMenuErr = msg.status, msg.err
+
+	case attachmentsSavedMsg:
+		if m.selectedCommand != nil && detailKey(*m.selectedCommand) == msg.key {
+			m.attachmentPaths = msg.paths
+		}
+
+	case detailPrefetchedMsg:
+		m.detailCache.put(detailLoadedMsg(msg))
+
+	case detailPollMsg:
+		if m.resultPending() && detailKey(*m.selectedCommand) == msg.key {
+			cmds = append(cmds, m.loadDetailCmd(*m.selectedCommand))
+		}
+
+	case spinner.TickMsg:
+		if m.resultPending() {
+			var cmd tea.Cmd
+			m.detailSpinner, cmd = m.detailSpinner.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 
 	case detailErrorMsg:
 		m.loadingDetail = false
 		m.detailError = msg.error
 
 	case devagentRefreshMsg:
+		m = m.resolveProblem("devagent")
 		if newCmd := m.handleDevagentRefresh(msg); newCmd != nil {
 			cmds = append(cmds, newCmd)
 		}
+
+	case projectsDirsMsg:
+		cmds = append(cmds, m.handleProjectsDirs(msg))
 	}
 
 	// Update the active list component
@@ -85,15 +214,25 @@ func (m Model) updateActiveList(msg tea.Msg) (Model, tea.Cmd) {
 		m.commandList, cmd = m.commandList.Update(msg)
 	case ViewPatterns:
 		m.patternList, cmd = m.patternList.Update(msg)
+	case ViewFindings:
+		if m.findingDrill != nil {
+			m.findingCmdList, cmd = m.findingCmdList.Update(msg)
+		} else {
+			m.findingList, cmd = m.findingList.Update(msg)
+		}
+	case ViewAnalytics:
+		m, cmd = m.updateAnalyticsList(msg)
 	}
 	return m, cmd
 }
 
 // handleTick refreshes activity status on timer tick
 func (m Model) handleTick() Model {
+	if m.showLog {
+		m.logSeen = applog.Last()
+	}
 	if m.watcher != nil {
 		m.watcher.RefreshActivityStatus()
-		m.watcher.ScanForNewSubagents()
 		m = m.updateSessionList()
 	}
 	return m
@@ -103,11 +242,34 @@ func (m Model) handleTick() Model {
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
-	// Dismiss path dialog on any key
+	m.notice = ""
+
+	// A pending removal takes the next key, the path menu, the touched
+	// paths tree, the git diff, the add-directory dialog, and the problems
+	// panel take keys until closed, and the secrets panel is dismissed by
+	// any key
+	if m.confirmRemove != nil {
+		return m.handleRemoveKey(key)
+	}
 	if m.showPathDialog {
-		m.showPathDialog = false
+		return m.handlePathMenuKey(key)
+	}
+	if m.showSecretsPanel {
+		m.showSecretsPanel = false
 		return m, nil
 	}
+	if m.showTouched {
+		return m.handleTouchedKey(key)
+	}
+	if m.showGitDiff {
+		return m.handleGitDiffScrollKey(key)
+	}
+	if m.showAddDir {
+		return m.handleAddDirKey(msg)
+	}
+	if m.showProblems {
+		return m.handleProblemsKey(key)
+	}
 
 	// When search is focused, route most keys to the text input
 	if m.searchActive && m.searchFocused {
@@ -118,8 +280,30 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch key {
 	case "ctrl+c", "q":
 		return m, tea.Quit
+	case "ctrl+z":
+		return m, m.suspendCmd()
 	case "r":
 		return m, m.discoverSessionsCmd()
+	case "P":
+		return m.cycleProfile(), nil
+	case "O":
+		return m.cycleOwnerFilter(), nil
+	case "A":
+		return m.openAddDir()
+	case "L":
+		return m.toggleLogPane(), nil
+	case "E":
+		return m.openProblems(), nil
+	case "y":
+		if cmd := m.copyLinkCmd(); cmd != nil {
+			return m, cmd
+		}
+	case "J":
+		if m.viewMode == ViewCommands {
+			if cmd := m.openSourceCmd(); cmd != nil {
+				return m, cmd
+			}
+		}
 	case "ctrl+f":
 		// Toggle search (only on Commands tab)
 		if m.viewMode == ViewCommands {
@@ -127,6 +311,11 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Focus switching and scrolling in the split command view
+	if newModel, handled := m.handleDetailFocus(key); handled {
+		return newModel, nil
+	}
+
 	// Session navigation keys
 	if newModel, handled := m.handleSessionNavigation(key); handled {
 		return newModel, nil
@@ -149,6 +338,24 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if newModel, handled := m.handlePathDialog(key); handled {
 		return newModel, nil
 	}
+	if newModel, handled := m.handleSecretsPanel(key); handled {
+		return newModel, nil
+	}
+	if newModel, handled := m.handleTouchedTree(key); handled {
+		return newModel, nil
+	}
+	if newModel, cmd, handled := m.handleGitDiffKey(key); handled {
+		return newModel, cmd
+	}
+	if newModel, cmd, handled := m.handleOnboardingKey(key); handled {
+		return newModel, cmd
+	}
+	if key == "D" && m.viewMode == ViewSessions {
+		if sess := m.highlightedSession(); sess != nil {
+			m.confirmRemove = sess
+			return m, nil
+		}
+	}
 
 	// Pass through to active list and handle detail panel updates
 	return m.handleListNavigation(msg)
@@ -175,6 +382,52 @@ func (m Model) handleSessionNavigation(key string) (Model, bool) {
 	return m, false
 }
 
+// handleDetailFocus moves focus between the command list and the detail
+// panel with right/left, maximizes the panel with z, and scrolls the panel
+// while it has focus
+func (m Model) handleDetailFocus(key string) (Model, bool) {
+	if m.viewMode != ViewCommands || !m.detailPanelOpen {
+		return m, false
+	}
+	switch key {
+	case "right":
+		m.detailFocused = true
+		return m, true
+	case "z":
+		// A maximized panel always has focus; the list isn't visible
+		m.detailMaximized = !m.detailMaximized
+		m.detailFocused = m.detailFocused || m.detailMaximized
+		m.detailScroll = min(m.detailScroll, m.maxDetailScroll())
+		return m, true
+	}
+	if !m.detailFocused {
+		return m, false
+	}
+
+	_, _, page := m.splitLayout()
+	switch key {
+	case "left":
+		m.detailFocused = m.detailMaximized
+		return m, true
+	case "j", "down":
+		m.detailScroll++
+	case "k", "up":
+		m.detailScroll--
+	case "ctrl+d", "pgdown":
+		m.detailScroll += page / 2
+	case "ctrl+u", "pgup":
+		m.detailScroll -= page / 2
+	case "g", "home":
+		m.detailScroll = 0
+	case "G", "end":
+		m.detailScroll = m.maxDetailScroll()
+	default:
+		return m, false
+	}
+	m.detailScroll = max(0, min(m.detailScroll, m.maxDetailScroll()))
+	return m, true
+}
+
 // handleViewSwitch handles h/l and arrow keys for view cycling
 func (m Model) handleViewSwitch(key string) (Model, bool) {
 	switch key {
@@ -202,6 +455,12 @@ func (m Model) cycleViewForward() Model {
 		m.viewMode = ViewPatterns
 		m = m.aggregatePatterns()
 	case ViewPatterns:
+		m.viewMode = ViewFindings
+		m = m.aggregateFindings()
+	case ViewFindings:
+		m.viewMode = ViewAnalytics
+		m = m.aggregateAnalytics()
+	case ViewAnalytics:
 		m.viewMode = ViewSessions
 	}
 	return m
@@ -211,6 +470,12 @@ func (m Model) cycleViewForward() Model {
 func (m Model) cycleViewBackward() Model {
 	switch m.viewMode {
 	case ViewSessions:
+		m.viewMode = ViewAnalytics
+		m = m.aggregateAnalytics()
+	case ViewAnalytics:
+		m.viewMode = ViewFindings
+		m = m.aggregateFindings()
+	case ViewFindings:
 		m.viewMode = ViewPatterns
 		m = m.aggregatePatterns()
 	case ViewPatterns:
@@ -221,7 +486,7 @@ func (m Model) cycleViewBackward() Model {
 	return m
 }
 
-// handleActionKeys handles enter, esc, backspace
+// handleActionKeys handles enter, esc, backspace, x (heredoc toggle), t (thinking toggle), c (resume chain history), v (open result attachments), o (outside-project filter), b (leaderboard by pattern), a (analytics page), e (session summaries), S (sort sessions by size), and n/N (search matches)
 func (m Model) handleActionKeys(key string) (Model, tea.Cmd, bool) {
 	switch key {
 	case "enter":
@@ -233,6 +498,76 @@ func (m Model) handleActionKeys(key string) (Model, tea.Cmd, bool) {
 			m.viewMode = ViewSessions
 		}
 		return m, nil, true
+	case "x":
+		// Expand/collapse heredoc bodies in the detail panel
+		if m.viewMode == ViewCommands && m.detailPanelOpen {
+			m.heredocsExpanded = !m.heredocsExpanded
+			return m, nil, true
+		}
+	case "t":
+		// Show/hide the reasoning that preceded the command in the detail panel
+		if m.viewMode == ViewCommands && m.detailPanelOpen {
+			m.thinkingShown = !m.thinkingShown
+			return m, nil, true
+		}
+	case "c":
+		// Show the commands of every session in the active session's resume chain
+		if m.viewMode == ViewCommands {
+			m.chainHistory = !m.chainHistory
+			m = m.updateCommandList()
+			m.commandList.Select(0)
+			return m, nil, true
+		}
+	case "S":
+		// Sort the Sessions list by activity, disk usage, owner, or blast radius
+		if m.viewMode == ViewSessions {
+			return m.cycleSessionOrder(), nil, true
+		}
+	case "e":
+		// Expand/collapse the summary row under each session
+		if m.viewMode == ViewSessions {
+			m.sessionsExpanded = !m.sessionsExpanded
+			m.sessionDelegate.expanded = m.sessionsExpanded
+			return m.updateSessionList(), nil, true
+		}
+	case "v":
+		// Save the result's images or binary content to temp files and open them
+		if m.viewMode == ViewCommands && m.detailPanelOpen && m.loadedInput != nil && len(m.loadedInput.Attachments) > 0 {
+			return m, openAttachmentsCmd(detailKey(*m.selectedCommand), m.loadedInput.Attachments), true
+		}
+	case "b":
+		// Rank patterns instead of raw commands in the leaderboard, or back
+		if m.viewMode == ViewAnalytics && m.analyticsPage == pageCommands {
+			return m.toggleRanksByPattern(), nil, true
+		}
+	case "a":
+		// Switch to the next analytics page
+		if m.viewMode == ViewAnalytics {
+			return m.cycleAnalyticsPage(), nil, true
+		}
+	case "o":
+		// Show only writes outside the session's project
+		if m.viewMode == ViewCommands {
+			m.outsideOnly = !m.outsideOnly
+			m = m.applySearchFilter()
+			m.commandList.Select(0)
+			return m, nil, true
+		}
+	case "n", "N":
+		// Step to the next/previous search match, wrapping like less
+		if m.viewMode == ViewCommands && m.searchActive && m.searchInput.Value() != "" {
+			count := len(m.commandList.Items())
+			if count == 0 {
+				return m, nil, true
+			}
+			step := 1
+			if key == "N" {
+				step = count - 1
+			}
+			m.commandList.Select((m.commandList.Index() + step) % count)
+			m, cmd := m.reloadDetail()
+			return m, cmd, true
+		}
 	}
 	return m, nil, false
 }
@@ -247,7 +582,8 @@ func (m Model) handleEnter() (Model, tea.Cmd, bool) {
 			m = m.aggregatePatterns()
 		}
 		m.viewMode = ViewCommands
-		return m, nil, true
+		m, load := m.openLayoutDetail()
+		return m, load, true
 
 	case ViewCommands:
 		return m.toggleDetailPanel()
@@ -255,6 +591,9 @@ func (m Model) handleEnter() (Model, tea.Cmd, bool) {
 	case ViewPatterns:
 		// No action on enter in patterns view
 		return m, nil, false
+
+	case ViewFindings:
+		return m.handleFindingsEnter()
 	}
 	return m, nil, false
 }
@@ -278,12 +617,16 @@ func (m Model) toggleDetailPanel() (Model, tea.Cmd, bool) {
 
 	// Open panel and start loading
 	m = m.openDetailPanel(&cmd)
-	return m, m.loadDetailCmd(cmd), true
+	m, load := m.loadDetail(cmd)
+	return m, load, true
 }
 
 // closeDetailPanel closes the detail panel and clears related state
 func (m Model) closeDetailPanel() Model {
 	m.detailPanelOpen = false
+	m.detailFocused = false
+	m.detailMaximized = false
+	m.detailScroll = 0
 	m.selectedCommand = nil
 	m.loadedInput = nil
 	m.detailError = nil
@@ -295,6 +638,10 @@ func (m Model) closeDetailPanel() Model {
 func (m Model) openDetailPanel(cmd *session.CommandEntry) Model {
 	m.detailPanelOpen = true
 	m.selectedCommand = cmd
+	m.heredocsExpanded = config.Global().Layout.ExpandHeredocs
+	m.scriptRuns = nil
+	m.attachmentPaths = nil
+	m.detailScroll = 0
 	m.loadedInput = nil
 	m.loadingDetail = true
 	m.detailError = nil
@@ -304,11 +651,22 @@ func (m Model) openDetailPanel(cmd *session.CommandEntry) Model {
 
 // handleEsc processes escape key
 func (m Model) handleEsc() (Model, tea.Cmd, bool) {
+	// Return a maximized detail panel to the split view
+	if m.viewMode == ViewCommands && m.detailMaximized {
+		m.detailMaximized = false
+		m.detailScroll = min(m.detailScroll, m.maxDetailScroll())
+		return m, nil, true
+	}
 	// If detail panel is open, close it first
 	if m.viewMode == ViewCommands && m.detailPanelOpen {
 		m = m.closeDetailPanel()
 		return m, nil, true
 	}
+	// Leave a finding's drill-down for the findings summary
+	if m.viewMode == ViewFindings && m.findingDrill != nil {
+		m.findingDrill = nil
+		return m, nil, true
+	}
 	// Go back to sessions view
 	if m.viewMode != ViewSessions {
 		m.viewMode = ViewSessions
@@ -316,7 +674,7 @@ func (m Model) handleEsc() (Model, tea.Cmd, bool) {
 	return m, nil, true
 }
 
-// handleNumberKeys handles 1/2/3 for direct view switching
+// handleNumberKeys handles 1/2/3/4/5 for direct view switching
 func (m Model) handleNumberKeys(key string) (Model, bool) {
 	switch key {
 	case "1":
@@ -328,6 +686,14 @@ func (m Model) handleNumberKeys(key string) (Model, bool) {
 	case "3":
 		m.viewMode = ViewPatterns
 		return m, true
+	case "4":
+		m.viewMode = ViewFindings
+		m = m.aggregateFindings()
+		return m, true
+	case "5":
+		m.viewMode = ViewAnalytics
+		m = m.aggregateAnalytics()
+		return m, true
 	}
 	return m, false
 }
@@ -341,33 +707,64 @@ func (m Model) handleListNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.sessionList, cmd = m.sessionList.Update(msg)
 	case ViewCommands:
 		m.commandList, cmd = m.commandList.Update(msg)
-		// If detail panel is open and selection changed, reload details
-		if m.detailPanelOpen {
-			if item, ok := m.commandList.SelectedItem().(commandItem); ok {
-				newCmd := item.command
-				if m.selectedCommand == nil ||
-					m.selectedCommand.UUID != newCmd.UUID ||
-					m.selectedCommand.ToolName != newCmd.ToolName {
-					m.selectedCommand = &newCmd
-					m.loadedInput = nil
-					m.loadingDetail = true
-					m.detailError = nil
-					return m, m.loadDetailCmd(newCmd)
-				}
-			}
+		if m, loadCmd := m.reloadDetail(); loadCmd != nil {
+			return m, loadCmd
 		}
 	case ViewPatterns:
 		m.patternList, cmd = m.patternList.Update(msg)
+	case ViewFindings:
+		if m.findingDrill != nil {
+			m.findingCmdList, cmd = m.findingCmdList.Update(msg)
+		} else {
+			m.findingList, cmd = m.findingList.Update(msg)
+		}
+	case ViewAnalytics:
+		m, cmd = m.updateAnalyticsList(msg)
 	}
 
 	return m, cmd
 }
 
-// handlePathDialog handles the 'p' key to show session path dialog
+// reloadDetail starts loading the selected command's details when the
+// detail panel is open and the selection changed
+func (m Model) reloadDetail() (Model, tea.Cmd) {
+	if !m.detailPanelOpen {
+		return m, nil
+	}
+	if item, ok := m.commandList.SelectedItem().(commandItem); ok {
+		newCmd := item.command
+		if m.selectedCommand == nil ||
+			m.selectedCommand.UUID != newCmd.UUID ||
+			m.selectedCommand.ToolName != newCmd.ToolName {
+			m.selectedCommand = &newCmd
+			m.loadedInput = nil
+			m.detailError = nil
+			m.detailScroll = 0
+			m.attachmentPaths = nil
+			return m.loadDetail(newCmd)
+		}
+	}
+	return m, nil
+}
+
+// handlePathDialog handles the 'p' key to open the session path menu
 func (m Model) handlePathDialog(key string) (Model, bool) {
 	if key == "p" && (m.viewMode == ViewSessions || m.viewMode == ViewCommands) {
 		if m.ActiveSession() != nil {
 			m.showPathDialog = true
+			m.pathMenuIdx = 0
+			m.pathMenuStatus, m.pathMenuErr = "", nil
+			return m, true
+		}
+	}
+	return m, false
+}
+
+// handleSecretsPanel handles the 's' key to show the secrets the active session touched
+func (m Model) handleSecretsPanel(key string) (Model, bool) {
+	if key == "s" && (m.viewMode == ViewSessions || m.viewMode == ViewCommands) {
+		if m.ActiveSession() != nil {
+			m.showSecretsPanel = true
 			return m, true
 		}
 	}
@@ -388,6 +785,7 @@ func (m Model) handleCtrlF() (tea.Model, tea.Cmd) {
 
 	case m.searchFocused:
 		// Focused → close and clear
+		m.searchHistory.add(m.searchInput.Value())
 		m.searchActive = false
 		m.searchFocused = false
 		m.searchInput.SetValue("")
@@ -412,18 +810,39 @@ func (m Model) handleSearchFocusedKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "ctrl+c":
 		return m, tea.Quit
 
+	case "ctrl+z":
+		return m, m.suspendCmd()
+
 	case "ctrl+f":
 		// Close search
 		return m.handleCtrlF()
 
-	case "esc":
+	case "esc", "enter":
 		// Unfocus but keep filter active
+		m.searchHistory.add(m.searchInput.Value())
 		m.searchFocused = false
 		m.searchInput.Blur()
 		return m, nil
 
+	case "up", "down":
+		// Recall an older or newer query from the search history
+		var query string
+		var ok bool
+		if key == "up" {
+			query, ok = m.searchHistory.older(m.searchInput.Value())
+		} else {
+			query, ok = m.searchHistory.newer()
+		}
+		if ok {
+			m.searchInput.SetValue(query)
+			m.searchInput.CursorEnd()
+			m = m.applySearchFilter()
+		}
+		return m, nil
+
 	case "tab":
 		// Cycle session forward + unfocus
+		m.searchHistory.add(m.searchInput.Value())
 		m.searchFocused = false
 		m.searchInput.Blur()
 		if len(m.sessions) > 0 {
@@ -435,6 +854,7 @@ func (m Model) handleSearchFocusedKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "shift+tab":
 		// Cycle session backward + unfocus
+		m.searchHistory.add(m.searchInput.Value())
 		m.searchFocused = false
 		m.searchInput.Blur()
 		if len(m.sessions) > 0 {
@@ -445,7 +865,8 @@ func (m Model) handleSearchFocusedKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// All other keys go to the text input
+	// All other keys go to the text input, and editing ends recall
+	m.searchHistory.reset()
 	var cmd tea.Cmd
 	m.searchInput, cmd = m.searchInput.Update(msg)
 	// Re-apply filter after each keystroke
@@ -459,19 +880,21 @@ func (m Model) handleSessionEvent(event sessionEventMsg) Model {
 		return m
 	}
 
-	// Remember currently selected session by file path
-	var selectedFilePath string
+	// Remember currently selected session by ID, which survives the shown
+	// copy of a duplicated session changing
+	var selectedID string
 	if m.activeIdx >= 0 && m.activeIdx < len(m.sessions) {
-		selectedFilePath = m.sessions[m.activeIdx].FilePath
+		selectedID = m.sessions[m.activeIdx].ID
 	}
 
-	// Get fresh sorted list from watcher (already sorted, no re-sort needed)
+	// Get fresh sorted list from watcher, reordered only when sorting by size
 	m.sessions = m.watcher.GetSessions()
+	m = m.sortSessions()
 
-	// Restore selection by finding the session with the same file path
-	if selectedFilePath != "" {
+	// Restore selection by finding the session with the same ID
+	if selectedID != "" {
 		for i, s := range m.sessions {
-			if s.FilePath == selectedFilePath {
+			if s.ID == selectedID {
 				m.activeIdx = i
 				break
 			}
@@ -491,10 +914,37 @@ func (m Model) handleSessionEvent(event sessionEventMsg) Model {
 		m = m.updateCommandList()
 	}
 	m = m.aggregatePatterns()
+	if m.viewMode == ViewFindings {
+		m = m.aggregateFindings()
+	}
+	if m.viewMode == ViewAnalytics {
+		m = m.aggregateAnalytics()
+	}
 
 	return m
 }
 
+// handleProjectsDirs watches directories newly matched by projects_dirs or
+// of users newly found in shared_dirs, and discovers their sessions
+func (m Model) handleProjectsDirs(msg projectsDirsMsg) tea.Cmd {
+	added := false
+	for _, dir := range msg.dirs {
+		if m.watcher.AddProjectsDir(dir) {
+			added = true
+		}
+	}
+	for _, s := range msg.shared {
+		if m.watcher.AddProjectsDir(s.Dir) {
+			m.watcher.SetOrigin(s.Dir, s.Origin())
+			added = true
+		}
+	}
+	if added {
+		return m.discoverSessionsCmd()
+	}
+	return nil
+}
+
 // handleDevagentRefresh processes devagent environment refresh
 func (m Model) handleDevagentRefresh(msg devagentRefreshMsg) tea.Cmd {
 	if m.watcher == nil {
@@ -3,19 +3,29 @@ package tui
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/security"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
-// View renders the UI based on the model state
+// View renders the UI based on the model state, with any error toasts over it
 func (m Model) View() string {
+	return m.overlayToasts(m.renderView())
+}
+
+// renderView renders the UI without toasts
+func (m Model) renderView() string {
 	if m.width == 0 {
 		return "Loading..."
 	}
 
 	if m.err != nil {
-		return ErrorStyle().Render(fmt.Sprintf("Error: %v", m.err))
+		return m.theme.ErrorStyle().Render(fmt.Sprintf("Error: %v", m.err))
 	}
 
 	var b strings.Builder
@@ -31,11 +41,20 @@ func (m Model) View() string {
 	// Main content area based on view mode
 	switch m.viewMode {
 	case ViewSessions:
-		b.WriteString(m.renderSessionHeaders())
-		b.WriteString("\n")
-		b.WriteString(m.sessionList.View())
+		if m.showOnboarding() {
+			b.WriteString(m.renderOnboarding())
+		} else if m.showPreview() {
+			b.WriteString(m.renderSessionsWithPreview())
+		} else {
+			b.WriteString(m.renderSessionHeaders())
+			b.WriteString("\n")
+			b.WriteString(m.sessionList.View())
+		}
 	case ViewCommands:
-		if m.detailPanelOpen {
+		if m.detailPanelOpen && m.detailMaximized {
+			_, width, height := m.splitLayout()
+			b.WriteString(m.renderDetailPanel(width, height+1))
+		} else if m.detailPanelOpen {
 			b.WriteString(m.renderSplitCommandView())
 		} else {
 			b.WriteString(m.renderCommandHeaders())
@@ -50,16 +69,43 @@ func (m Model) View() string {
 		b.WriteString(m.renderPatternHeaders())
 		b.WriteString("\n")
 		b.WriteString(m.patternList.View())
+	case ViewFindings:
+		b.WriteString(m.renderFindingsView())
+	case ViewAnalytics:
+		b.WriteString(m.renderAnalyticsView())
+	}
+
+	if m.showLog {
+		b.WriteString("\n")
+		b.WriteString(m.renderLogPane())
 	}
 
 	// Help footer
 	b.WriteString("\n")
 	b.WriteString(m.renderHelp())
 
-	// Overlay path dialog if active
+	// Overlay path menu, secrets panel, or another dialog if active
 	if m.showPathDialog {
 		return m.overlayPathDialog(b.String())
 	}
+	if m.showSecretsPanel {
+		return m.overlaySecretsPanel(b.String())
+	}
+	if m.confirmRemove != nil {
+		return m.overlayRemoveDialog(b.String())
+	}
+	if m.showTouched {
+		return m.overlayTouchedTree(b.String())
+	}
+	if m.showGitDiff {
+		return m.overlayGitDiff(b.String())
+	}
+	if m.showAddDir {
+		return m.overlayAddDir(b.String())
+	}
+	if m.showProblems {
+		return m.overlayProblems(b.String())
+	}
 
 	return b.String()
 }
@@ -70,40 +116,44 @@ func (m Model) renderHeader() string {
 	if m.followDevagent {
 		titleText += " [devagent]"
 	}
-	title := TitleStyle().Render(titleText)
-
-	// Session status
-	activeCount := 0
-	for _, s := range m.sessions {
-		if s.IsActive {
-			activeCount++
-		}
+	if m.label != "" {
+		titleText += " " + m.label
 	}
-
-	var status string
-	if len(m.sessions) == 0 {
-		status = StatusStyle().Render("No sessions found")
-	} else {
-		status = StatusStyle().Render(fmt.Sprintf(
-			"%d sessions (%d active)",
-			len(m.sessions),
-			activeCount,
-		))
+	if profile := profileLabel(); profile != "" {
+		titleText += " " + profile
 	}
+	title := m.theme.TitleStyle().Render(titleText)
+
+	// Global counters, dropped from the end when the header is too narrow
+	parts := m.renderStatusBar(time.Now())
 
-	// Add active session indicator
+	// Add active session indicator, with its working directory when it has
+	// moved away from the project root
 	activeSession := ""
 	if sess := m.ActiveSession(); sess != nil {
 		name := filepath.Base(sess.ProjectPath)
 		if sess.IsActive {
-			activeSession = ActiveIndicatorStyle().Render(" [" + name + "]")
+			activeSession = m.theme.ActiveIndicatorStyle().Render(" [" + name + "]")
 		} else {
-			activeSession = InactiveIndicatorStyle().Render(" [" + name + "]")
+			activeSession = m.theme.InactiveIndicatorStyle().Render(" [" + name + "]")
+		}
+		if sess.CWD != "" && filepath.Clean(sess.CWD) != filepath.Clean(sess.ProjectPath) {
+			cwd := " cwd: " + sess.CWD
+			if security.CWDDrift(sess.ProjectPath, sess.CWD) != "" {
+				activeSession += m.theme.WarningStyle().Bold(true).Render(cwd)
+			} else {
+				activeSession += m.theme.MutedStyle().Render(cwd)
+			}
 		}
 	}
 
 	// Calculate spacing
 	leftPart := lipgloss.Width(title)
+	status := joinStatus(m.theme, parts)
+	for len(parts) > 1 && leftPart+lipgloss.Width(status)+lipgloss.Width(activeSession)+5 > m.width {
+		parts = parts[:len(parts)-1]
+		status = joinStatus(m.theme, parts)
+	}
 	rightPart := lipgloss.Width(status) + lipgloss.Width(activeSession)
 	spacing := m.width - leftPart - rightPart - 4
 	if spacing < 1 {
@@ -129,57 +179,116 @@ func (m Model) renderViewTabs() string {
 		{"Sessions", ViewSessions, "1"},
 		{"Commands", ViewCommands, "2"},
 		{"Patterns", ViewPatterns, "3"},
+		{"Findings", ViewFindings, "4"},
+		{"Analytics", ViewAnalytics, "5"},
 	}
 
 	rendered := make([]string, len(tabs))
 	for i, t := range tabs {
 		label := fmt.Sprintf("%s %s", t.key, t.name)
 		if t.mode == m.viewMode {
-			rendered[i] = ActiveTabStyle().Render(label)
+			rendered[i] = m.theme.ActiveTabStyle().Render(label)
 		} else {
-			rendered[i] = InactiveTabStyle().Render(label)
+			rendered[i] = m.theme.InactiveTabStyle().Render(label)
 		}
 	}
 
 	row := lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
 	gap := strings.Repeat("─", max(0, m.width-lipgloss.Width(row)-2))
 
-	return row + TabGapStyle().Render(gap)
+	return row + m.theme.TabGapStyle().Render(gap)
 }
 
 // renderHelp renders the help footer
 func (m Model) renderHelp() string {
+	if m.notice != "" {
+		return m.theme.HelpStyle().Render(m.notice)
+	}
 	var help []string
+	changesHelp := "" // Only offered with git_snapshots, dropped below otherwise
+	if config.Global().GitSnapshots {
+		changesHelp = "C:changes"
+	}
+	problemsHelp := "" // Only offered while there are problems
+	if len(m.problems) > 0 {
+		problemsHelp = "E:problems"
+	}
 
 	switch m.viewMode {
 	case ViewSessions:
+		expandHelp := "summaries"
+		if m.sessionsExpanded {
+			expandHelp = "collapse"
+		}
+		sortHelp := [...]string{orderActivity: "size", orderSize: "owner", orderOwner: "blast radius", orderBlast: "activity"}[m.sessionOrder]
 		help = []string{
 			"j/k:navigate",
 			"enter:select",
 			"tab:next session",
 			"h/l:switch view",
+			"e:" + expandHelp,
 			"p:path",
+			"y:copy link",
+			"s:secrets",
+			"T:touched paths",
+			changesHelp,
+			"S:sort by " + sortHelp,
+			"O:owner",
+			"D:remove",
+			"A:add dir",
+			"P:profile",
+			problemsHelp,
+			"L:log",
 			"r:refresh",
 			"q:quit",
 		}
 	case ViewCommands:
+		outsideHelp := "o:outside writes"
+		if m.outsideOnly {
+			outsideHelp = "o:all commands"
+		}
 		switch {
 		case m.searchActive && m.searchFocused:
 			help = []string{
 				"type to filter",
+				"↑/↓:history",
 				"esc:unfocus",
 				"tab:next session",
 				"ctrl+f:close",
 				"ctrl+c:quit",
 			}
+		case m.detailPanelOpen && m.detailMaximized:
+			help = []string{
+				"j/k:scroll",
+				"ctrl+d/u:page",
+				"g/G:top/bottom",
+				"z/esc:split view",
+				"q:quit",
+			}
+		case m.detailPanelOpen && m.detailFocused:
+			help = []string{
+				"j/k:scroll",
+				"ctrl+d/u:page",
+				"g/G:top/bottom",
+				"←:focus list",
+				"z:maximize",
+				"esc:close panel",
+				"q:quit",
+			}
 		case m.detailPanelOpen:
 			help = []string{
 				"j/k:navigate",
+				"→:focus details",
+				"z:maximize",
 				"enter:close panel",
 				"esc:close panel",
+				"x:heredocs",
+				"t:thinking",
 				"tab:next session",
 				"ctrl+f:search",
+				outsideHelp,
 				"p:path",
+				"J:open JSONL line",
 				"q:quit",
 			}
 		default:
@@ -189,11 +298,23 @@ func (m Model) renderHelp() string {
 				"tab:next session",
 				"h/l:switch view",
 				"ctrl+f:search",
+				outsideHelp,
 				"p:path",
+				"y:copy link",
+				"J:open JSONL line",
+				"s:secrets",
+				"T:touched paths",
+				changesHelp,
+				"c:resume chain",
+				problemsHelp,
+				"L:log",
 				"esc:back",
 				"q:quit",
 			}
 		}
+		if m.searchActive && !m.searchFocused && m.searchInput.Value() != "" {
+			help = append(help[:1], append([]string{"n/N:next/prev match"}, help[1:]...)...)
+		}
 	case ViewPatterns:
 		help = []string{
 			"j/k:navigate",
@@ -201,21 +322,64 @@ func (m Model) renderHelp() string {
 			"esc:back",
 			"q:quit",
 		}
+	case ViewFindings:
+		if m.findingDrill != nil {
+			help = []string{
+				"j/k:navigate",
+				"enter:open command",
+				"esc:back to findings",
+				"q:quit",
+			}
+		} else {
+			help = []string{
+				"j/k:navigate",
+				"enter:show commands",
+				"h/l:switch view",
+				"esc:back",
+				"q:quit",
+			}
+		}
+	case ViewAnalytics:
+		help = []string{"j/k:navigate", "a:next page"}
+		if m.analyticsPage == pageCommands && m.ranksByPattern {
+			help = append(help, "b:by command")
+		} else if m.analyticsPage == pageCommands {
+			help = append(help, "b:by pattern")
+		}
+		help = append(help, "h/l:switch view", "esc:back", "q:quit")
 	}
 
-	return HelpStyle().Render(strings.Join(help, " | "))
+	help = slices.DeleteFunc(help, func(h string) bool { return h == "" })
+	return m.theme.HelpStyle().Render(strings.Join(help, " | "))
 }
 
 // renderSearchBar renders the search input at the bottom of the Commands tab
 func (m Model) renderSearchBar() string {
-	return SearchBarStyle().Render(m.searchInput.View())
+	bar := m.searchInput.View()
+	if m.searchInput.Value() != "" {
+		bar += "  " + m.theme.MutedStyle().Render(m.searchMatchStatus())
+	}
+	return m.theme.SearchBarStyle().Render(bar)
+}
+
+// searchMatchStatus describes the matches and the selected one's position,
+// e.g. "12 matches (3/12)"
+func (m Model) searchMatchStatus() string {
+	count := len(m.commandList.Items())
+	switch count {
+	case 0:
+		return "no matches"
+	case 1:
+		return "1 match (1/1)"
+	}
+	return fmt.Sprintf("%d matches (%d/%d)", count, m.commandList.Index()+1, count)
 }
 
 // renderSessionHeaders renders column headers for the session list
 func (m Model) renderSessionHeaders() string {
 	// Session list doesn't have fixed columns, just a simple indicator
-	header := "  Session Path"
-	return ColumnHeaderStyle(m.width - 4).Render(header)
+	header := "  Session Path" + m.sessionSortLabel()
+	return m.theme.ColumnHeaderStyle(m.width - 4).Render(header)
 }
 
 // renderCommandHeaders renders column headers for the command list
@@ -223,11 +387,12 @@ func (m Model) renderCommandHeaders() string {
 	// Build header with same widths as delegate
 	date := padRight("Date", CommandTimestampWidth)
 	group := padRight("Group", CommandGroupWidth)
+	category := padRight("Category", CommandCategoryWidth)
 	pattern := padRight("Pattern", CommandPatternWidth)
 	command := "Command"
 
-	header := fmt.Sprintf("%s  %s  %s  %s", date, group, pattern, command)
-	return ColumnHeaderStyle(m.width - 4).Render(header)
+	header := fmt.Sprintf("%s  %s  %s  %s  %s", date, group, category, pattern, command)
+	return m.theme.ColumnHeaderStyle(m.width - 4).Render(header)
 }
 
 // renderPatternHeaders renders column headers for the pattern list
@@ -235,11 +400,12 @@ func (m Model) renderPatternHeaders() string {
 	// Build header with same widths as delegate
 	pattern := padRight("Pattern", PatternPatternWidth)
 	group := padRight("Group", PatternGroupWidth)
+	category := padRight("Category", PatternCategoryWidth)
 	count := padLeft("Count", PatternCountWidth)
 	example := "Example"
 
-	header := fmt.Sprintf("%s  %s  %s  %s", pattern, group, count, example)
-	return ColumnHeaderStyle(m.width - 4).Render(header)
+	header := fmt.Sprintf("%s  %s  %s  %s  %s", pattern, group, category, count, example)
+	return m.theme.ColumnHeaderStyle(m.width - 4).Render(header)
 }
 
 // padRight pads a string with spaces on the right to reach target width
@@ -258,46 +424,46 @@ func padLeft(s string, width int) string {
 	return strings.Repeat(" ", width-len(s)) + s
 }
 
-// overlayPathDialog renders the path dialog centered over the existing view
-func (m Model) overlayPathDialog(background string) string {
+// overlaySecretsPanel renders the active session's secrets-touched summary
+// centered over the existing view
+func (m Model) overlaySecretsPanel(background string) string {
 	sess := m.ActiveSession()
 	if sess == nil {
 		return background
 	}
 
-	sessionDir := filepath.Dir(sess.FilePath)
-
-	// Build dialog content
-	t := GetTheme()
+	lines := []string{m.theme.LabelStyle().Render("Secrets touched - " + filepath.Base(sess.ProjectPath)), ""}
+	touched := security.SecretsTouched(sess.Commands)
+	if len(touched) == 0 {
+		lines = append(lines, m.theme.MutedStyle().Render("No secrets printed, exported, or written"))
+	}
+	for _, t := range touched {
+		lines = append(lines, m.theme.DangerStyle().Render(t.Secret)+m.theme.MutedStyle().Render(fmt.Sprintf(
+			"  %s, %dx, last %s", strings.Join(t.Actions, "/"), t.Count, formatTimeAgo(t.LastSeen))))
+	}
+	lines = append(lines, "", dismissHint(m.theme))
 
-	pathLabel := LabelStyle().Render("Session data path:")
-	pathValue := lipgloss.NewStyle().Foreground(t.Secondary).Render(sessionDir)
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return m.overlayDialog(background, content, lipgloss.Width(content)+6)
+}
 
-	grepLabel := LabelStyle().Render("Search example:")
-	grepCmd := lipgloss.NewStyle().Foreground(t.Text).
-		Background(t.Surface).
-		Padding(0, 1).
-		Render(fmt.Sprintf("grep -ri 'search_term' %s", sessionDir))
+// dismissHint renders the footer line of a dismissable dialog
+func dismissHint(theme *Theme) string {
+	return theme.NewStyle().Foreground(theme.Muted).Italic(true).Render("Press any key to dismiss")
+}
 
-	dismiss := lipgloss.NewStyle().Foreground(t.Muted).Italic(true).Render("Press any key to dismiss")
-
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		pathLabel,
-		pathValue,
-		"",
-		grepLabel,
-		grepCmd,
-		"",
-		dismiss,
-	)
+// overlayDialog renders content in a bordered dialog (at least 40 columns,
+// up to contentWidth) centered over the existing view
+func (m Model) overlayDialog(background, content string, contentWidth int) string {
+	t := m.theme
 
 	// Build bordered dialog box
-	dialogWidth := min(m.width-8, lipgloss.Width(grepCmd)+6)
+	dialogWidth := min(m.width-8, contentWidth)
 	if dialogWidth < 40 {
 		dialogWidth = 40
 	}
 
-	dialog := lipgloss.NewStyle().
+	dialog := m.theme.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(t.Primary).
 		Padding(1, 2).
@@ -332,14 +498,14 @@ func (m Model) overlayPathDialog(background string) string {
 			bgLine += strings.Repeat(" ", startCol+lipgloss.Width(dLine)-bgW)
 		}
 		// Replace the portion of the background line with dialog line
-		bgLines[row] = placeover(bgLine, dLine, startCol)
+		bgLines[row] = placeover(m.theme, bgLine, dLine, startCol)
 	}
 
 	return strings.Join(bgLines, "\n")
 }
 
 // placeover places overlay text at a given column position in a line
-func placeover(bg, overlay string, col int) string {
+func placeover(theme *Theme, bg, overlay string, col int) string {
 	// Use lipgloss.PlaceHorizontal for ANSI-aware placement
 	bgWidth := lipgloss.Width(bg)
 	overlayWidth := lipgloss.Width(overlay)
@@ -352,29 +518,59 @@ func placeover(bg, overlay string, col int) string {
 	left := ""
 	if col > 0 {
 		// Take first col characters from background
-		left = truncateAnsi(bg, col)
+		left = truncateAnsi(theme, bg, col)
 	}
 
 	return left + overlay + strings.Repeat(" ", max(0, totalWidth-col-overlayWidth))
 }
 
 // truncateAnsi truncates a string to a display width, preserving ANSI sequences
-func truncateAnsi(s string, width int) string {
+func truncateAnsi(theme *Theme, s string, width int) string {
 	// Use lipgloss.PlaceHorizontal to get a fixed-width string, then take what we need
-	return lipgloss.NewStyle().Width(width).MaxWidth(width).Render(
-		lipgloss.NewStyle().Inline(true).MaxWidth(width).Render(s),
+	return theme.NewStyle().Width(width).MaxWidth(width).Render(
+		theme.NewStyle().Inline(true).MaxWidth(width).Render(s),
 	)
 }
 
-// renderSplitCommandView renders the commands list with detail panel side-by-side
-func (m Model) renderSplitCommandView() string {
+// highlightMatches renders s in style with each case-insensitive occurrence
+// of query in SearchMatchStyle, so search results show why they matched
+func highlightMatches(theme *Theme, s, query string, style lipgloss.Style) string {
+	lower, q := strings.ToLower(s), strings.ToLower(query)
+	if q == "" || len(lower) != len(s) { // Offsets only line up when lowering keeps byte lengths
+		return style.Render(s)
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 {
+			break
+		}
+		if i > 0 {
+			b.WriteString(style.Render(s[:i]))
+		}
+		b.WriteString(theme.SearchMatchStyle().Render(s[i : i+len(q)]))
+		s, lower = s[i+len(q):], lower[i+len(q):]
+	}
+	if s != "" {
+		b.WriteString(style.Render(s))
+	}
+	return b.String()
+}
+
+// splitLayout returns the list and detail panel widths and the content
+// height of the split command view, or of the maximized detail panel
+func (m Model) splitLayout() (listWidth, detailWidth, contentHeight int) {
 	// Calculate widths: 60% for list, 40% for detail (minus separator)
 	totalWidth := m.width - 4
-	listWidth := int(float64(totalWidth) * 0.58)
-	detailWidth := totalWidth - listWidth - 1 // -1 for separator
+	listWidth = int(float64(totalWidth) * 0.58)
+	detailWidth = totalWidth - listWidth - 1 // -1 for separator
+	if m.detailMaximized {
+		listWidth, detailWidth = 0, totalWidth
+	}
 
 	// Calculate available height for content (same as list height calculation)
-	contentHeight := m.height - 9
+	contentHeight = m.height - 9 - m.logPaneHeight()
 	if contentHeight < 5 {
 		contentHeight = 5
 	}
@@ -385,6 +581,12 @@ func (m Model) renderSplitCommandView() string {
 			contentHeight = 3
 		}
 	}
+	return listWidth, detailWidth, contentHeight
+}
+
+// renderSplitCommandView renders the commands list with detail panel side-by-side
+func (m Model) renderSplitCommandView() string {
+	listWidth, detailWidth, contentHeight := m.splitLayout()
 
 	// Build the list side with headers
 	listHeader := m.renderCommandHeadersWithWidth(listWidth)
@@ -394,14 +596,14 @@ func (m Model) renderSplitCommandView() string {
 	listView := m.commandList.View()
 
 	// Build left side (header + list)
-	leftSide := lipgloss.NewStyle().
+	leftSide := m.theme.NewStyle().
 		Width(listWidth).
 		Height(contentHeight + 1). // +1 for header
 		Render(listHeader + "\n" + listView)
 
 	// Build the separator - a vertical line
-	separator := lipgloss.NewStyle().
-		Foreground(GetTheme().Muted).
+	separator := m.theme.NewStyle().
+		Foreground(m.theme.Muted).
 		Render(strings.Repeat("│\n", contentHeight+1))
 
 	// Build the detail panel
@@ -415,9 +617,10 @@ func (m Model) renderSplitCommandView() string {
 func (m Model) renderCommandHeadersWithWidth(width int) string {
 	date := padRight("Date", CommandTimestampWidth)
 	group := padRight("Group", CommandGroupWidth)
+	category := padRight("Category", CommandCategoryWidth)
 	pattern := padRight("Pattern", CommandPatternWidth)
 	command := "Command"
 
-	header := fmt.Sprintf("%s  %s  %s  %s", date, group, pattern, command)
-	return ColumnHeaderStyle(width).Render(header)
+	header := fmt.Sprintf("%s  %s  %s  %s  %s", date, group, category, pattern, command)
+	return m.theme.ColumnHeaderStyle(width).Render(header)
 }
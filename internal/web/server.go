@@ -0,0 +1,355 @@
+// Package web serves a small embedded dashboard that mirrors the TUI's
+// sessions, live command feed, and pattern table over HTTP and WebSocket.
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+
+	"github.com/coder/websocket"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// recentCommandLimit caps how many commands are sent in the initial snapshot
+const recentCommandLimit = 200
+
+// SessionView is the JSON representation of a session
+type SessionView struct {
+	ID           string    `json:"id"`
+	ProjectPath  string    `json:"project_path"`
+	CWD          string    `json:"cwd"`
+	Flags        []string  `json:"flags,omitempty"`
+	GitBranch    string    `json:"git_branch"`
+	Origin       string    `json:"origin"`
+	Owner        string    `json:"owner,omitempty"`
+	LastActivity time.Time `json:"last_activity"`
+	IsActive     bool      `json:"is_active"`
+	CommandCount int       `json:"command_count"`
+}
+
+// CommandView is the JSON representation of a command entry
+type CommandView struct {
+	Timestamp  time.Time `json:"timestamp"`
+	SessionID  string    `json:"session_id"`
+	ToolName   string    `json:"tool_name"`
+	Pattern    string    `json:"pattern"`
+	RawCommand string    `json:"raw_command"`
+}
+
+// PatternView is the JSON representation of an aggregated pattern
+type PatternView struct {
+	Pattern  string    `json:"pattern"`
+	ToolName string    `json:"tool_name"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+	Examples []string  `json:"examples"`
+}
+
+// message is a frame pushed to dashboard clients
+type message struct {
+	Type     string        `json:"type"` // "snapshot", "sessions", "discovered", "new_commands"
+	Sessions []SessionView `json:"sessions,omitempty"`
+	Session  *SessionView  `json:"session,omitempty"`
+	Commands []CommandView `json:"commands,omitempty"`
+}
+
+// Server serves the dashboard and fans watcher events out to WebSocket clients
+type Server struct {
+	watcher *session.Watcher
+	events  <-chan session.WatchEvent
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+}
+
+// NewServer creates a dashboard server backed by the given watcher
+func NewServer(w *session.Watcher) *Server {
+	return &Server{
+		watcher: w,
+		events:  w.Subscribe(),
+		clients: make(map[*client]struct{}),
+	}
+}
+
+// Handler returns the HTTP handler for the dashboard and its API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	static, _ := fs.Sub(staticFiles, "static")
+	mux.Handle("GET /", http.FileServerFS(static))
+	mux.HandleFunc("GET /api/sessions", s.handleSessions)
+	mux.HandleFunc("GET /api/sessions/{id}/patterns", s.handlePatterns)
+	mux.HandleFunc("GET /ws", s.handleWebSocket)
+
+	return mux
+}
+
+// ListenAddr returns the address addr is served on. One without a host
+// (":8080") listens on loopback only; serving other machines takes an
+// explicit host such as 0.0.0.0:8080.
+func ListenAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "127.0.0.1" + addr
+	}
+	return addr
+}
+
+// ListenAndServe starts the broadcast loop and serves HTTP on addr until it fails
+func (s *Server) ListenAndServe(addr string) error {
+	addr = ListenAddr(addr)
+	handler := s.Handler()
+	if host, _, err := net.SplitHostPort(addr); err == nil && isLoopbackHost(host) {
+		handler = loopbackHostsOnly(handler)
+	}
+
+	go s.broadcastLoop()
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+// loopbackHostsOnly refuses requests whose Host header isn't a loopback name,
+// so a page on another site can't reach a loopback-bound dashboard by
+// pointing its own domain at 127.0.0.1 (DNS rebinding)
+func loopbackHostsOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !isLoopbackHost(host) {
+			http.Error(w, "forbidden host", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback IP
+func isLoopbackHost(host string) bool {
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// handleSessions returns all sessions as JSON, or with ?owner= only those
+// with that owner label
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	views := s.sessionViews()
+	if owner := r.URL.Query().Get("owner"); owner != "" {
+		views = slices.DeleteFunc(views, func(v SessionView) bool { return v.Owner != owner })
+	}
+	writeJSON(w, views)
+}
+
+// handlePatterns returns aggregated patterns for one session
+func (s *Server) handlePatterns(w http.ResponseWriter, r *http.Request) {
+	sess := s.findSession(r.PathValue("id"))
+	if sess == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	patterns := session.AggregatePatterns(sess.Commands)
+	views := make([]PatternView, len(patterns))
+	for i, p := range patterns {
+		views[i] = PatternView{
+			Pattern:  p.Pattern,
+			ToolName: p.ToolName,
+			Count:    p.Count,
+			LastSeen: p.LastSeen,
+			Examples: p.Examples,
+		}
+	}
+	writeJSON(w, views)
+}
+
+// handleWebSocket upgrades the connection, sends a snapshot, and registers the client
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// Accept refuses pages from other origins (cross-site WebSocket
+	// hijacking) and writes the error response itself
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.CloseNow() }()
+
+	// Clients never send anything; reading only answers pings and notices
+	// the close, which cancels ctx
+	ctx := conn.CloseRead(r.Context())
+
+	data, err := json.Marshal(message{
+		Type:     "snapshot",
+		Sessions: s.sessionViews(),
+		Commands: s.recentCommands(),
+	})
+	if err != nil {
+		return
+	}
+	c := newClient(conn)
+	if err := c.write(ctx, data); err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+
+	// Block until the client disconnects or is dropped, then unregister it
+	c.serve(ctx)
+
+	s.mu.Lock()
+	delete(s.clients, c)
+	s.mu.Unlock()
+}
+
+// broadcastLoop forwards watcher events and periodic session refreshes to clients
+func (s *Server) broadcastLoop() {
+	// Same interval as the TUI tick, for activity status updates
+	ticker := time.NewTicker(config.Global().RefreshInterval)
+	defer ticker.Stop()
+	subagentScan := time.NewTicker(config.Global().SubagentScanInterval)
+	defer subagentScan.Stop()
+
+	for {
+		select {
+		case event := <-s.events:
+			// A copy with the history, which the view's command count needs
+			event = s.watcher.SnapshotEventWithHistory(event)
+			view := toSessionView(event.Session)
+			s.broadcast(message{
+				Type:     event.Type,
+				Session:  &view,
+				Commands: toCommandViews(event.Commands),
+			})
+
+		case <-ticker.C:
+			s.watcher.RefreshActivityStatus()
+			if s.watcher.Polling() {
+				s.watcher.CatchUp()
+			}
+			s.broadcast(message{Type: "sessions", Sessions: s.sessionViews()})
+
+		case <-subagentScan.C:
+			s.watcher.ScanForNewSubagents()
+		}
+	}
+}
+
+// broadcast queues a message for every connected client without blocking,
+// dropping clients whose queue is full
+func (s *Server) broadcast(msg message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.clients {
+		select {
+		case c.send <- data:
+		default:
+			// Closing send tells the client's writer to disconnect it
+			delete(s.clients, c)
+			close(c.send)
+		}
+	}
+}
+
+// sessionViews converts the watcher's sessions to JSON views
+func (s *Server) sessionViews() []SessionView {
+	sessions := s.watcher.Snapshot()
+	views := make([]SessionView, len(sessions))
+	for i, sess := range sessions {
+		views[i] = toSessionView(sess)
+	}
+	return views
+}
+
+// recentCommands returns the most recent commands across all sessions, newest first
+func (s *Server) recentCommands() []CommandView {
+	var all []session.CommandEntry
+	for _, sess := range s.watcher.Snapshot() {
+		all = append(all, sess.Commands...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Timestamp.After(all[j].Timestamp)
+	})
+	if len(all) > recentCommandLimit {
+		all = all[:recentCommandLimit]
+	}
+	return toCommandViews(all)
+}
+
+// findSession returns a copy of the session with the given ID, or nil
+func (s *Server) findSession(id string) *session.Session {
+	for _, sess := range s.watcher.Snapshot() {
+		if sess.ID == id {
+			return sess
+		}
+	}
+	return nil
+}
+
+func toSessionView(sess *session.Session) SessionView {
+	if sess == nil {
+		return SessionView{}
+	}
+	return SessionView{
+		ID:           sess.ID,
+		ProjectPath:  sess.ProjectPath,
+		CWD:          sess.CWD,
+		Flags:        sess.Flags,
+		GitBranch:    sess.GitBranch,
+		Origin:       sess.Origin,
+		Owner:        config.Global().OwnerOf(sess.Origin, sess.ProjectPath),
+		LastActivity: sess.LastActivity,
+		IsActive:     sess.IsActive,
+		CommandCount: len(sess.Commands),
+	}
+}
+
+func toCommandViews(commands []session.CommandEntry) []CommandView {
+	views := make([]CommandView, len(commands))
+	for i := range commands {
+		cmd := &commands[i]
+		views[i] = CommandView{
+			Timestamp:  cmd.Timestamp,
+			SessionID:  cmd.SessionID,
+			ToolName:   cmd.ToolName,
+			Pattern:    cmd.Pattern,
+			RawCommand: cmd.RawCommand,
+		}
+	}
+	return views
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
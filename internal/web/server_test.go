@@ -0,0 +1,190 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/session"
+
+	"github.com/coder/websocket"
+)
+
+const testRecord = `{"type":"assistant","timestamp":"2026-01-02T10:00:00Z","uuid":"u1","sessionId":"sess-1","cwd":"/projects/alpha",` +
+	`"message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"git status"}}]}}` + "\n"
+
+// newTestServer creates a server over a temp projects dir with one session
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	projectsDir := t.TempDir()
+	projectDir := filepath.Join(projectsDir, "-projects-alpha")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "sess-1.jsonl"), []byte(testRecord), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := session.NewWatcher([]string{projectsDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	if _, err := w.DiscoverSessions(); err != nil {
+		t.Fatal(err)
+	}
+	return NewServer(w)
+}
+
+func TestHandleSessions(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions", http.NoBody))
+
+	var sessions []SessionView
+	if err := json.NewDecoder(rec.Body).Decode(&sessions); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(sessions))
+	}
+	if sessions[0].ID != "sess-1" || sessions[0].CommandCount != 1 {
+		t.Errorf("unexpected session view: %+v", sessions[0])
+	}
+}
+
+func TestHandleSessionsByOwner(t *testing.T) {
+	config.SetGlobal(&config.Config{Owners: []config.Owner{{Name: "alice", Projects: []string{"alpha"}}}})
+	t.Cleanup(func() { config.SetGlobal(nil) })
+	srv := newTestServer(t)
+
+	for owner, want := range map[string]int{"alice": 1, "bob": 0} {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions?owner="+owner, http.NoBody))
+		var sessions []SessionView
+		if err := json.NewDecoder(rec.Body).Decode(&sessions); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(sessions) != want || (want == 1 && sessions[0].Owner != "alice") {
+			t.Errorf("expected %d sessions owned by %s, got %+v", want, owner, sessions)
+		}
+	}
+}
+
+func TestHandlePatterns(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions/sess-1/patterns", http.NoBody))
+
+	var patterns []PatternView
+	if err := json.NewDecoder(rec.Body).Decode(&patterns); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(patterns) != 1 || patterns[0].Pattern != "Bash(git:status:*)" {
+		t.Errorf("unexpected patterns: %+v", patterns)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions/missing/patterns", http.NoBody))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown session, got %d", rec.Code)
+	}
+}
+
+func TestWebSocketRequiresUpgrade(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws", http.NoBody))
+	if rec.Code != http.StatusUpgradeRequired {
+		t.Errorf("expected 426 for plain GET on /ws, got %d", rec.Code)
+	}
+}
+
+func TestWebSocketSnapshotAndOrigin(t *testing.T) {
+	srv := newTestServer(t)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.CloseNow() }()
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "snapshot" || len(msg.Sessions) != 1 {
+		t.Errorf("unexpected first message: %s (%v)", data, err)
+	}
+
+	// A page on another site must not be able to read the feed
+	_, resp, err := websocket.Dial(ctx, url, &websocket.DialOptions{
+		HTTPHeader: http.Header{"Origin": []string{"https://evil.example"}},
+	})
+	if err == nil {
+		t.Fatal("expected a cross-origin upgrade to be refused")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for a foreign origin, got %v", resp)
+	}
+}
+
+func TestBroadcastDropsSlowClient(t *testing.T) {
+	srv := newTestServer(t)
+	slow := &client{send: make(chan []byte)} // never drained
+	srv.clients[slow] = struct{}{}
+
+	srv.broadcast(message{Type: "sessions"})
+
+	if _, ok := srv.clients[slow]; ok {
+		t.Error("expected the full client to be dropped")
+	}
+	if _, open := <-slow.send; open {
+		t.Error("expected the dropped client's queue to be closed")
+	}
+}
+
+func TestListenAddr(t *testing.T) {
+	for addr, want := range map[string]string{
+		":8080":          "127.0.0.1:8080",
+		"0.0.0.0:8080":   "0.0.0.0:8080",
+		"localhost:9000": "localhost:9000",
+	} {
+		if got := ListenAddr(addr); got != want {
+			t.Errorf("ListenAddr(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestLoopbackHostsOnly(t *testing.T) {
+	handler := loopbackHostsOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for host, want := range map[string]int{
+		"localhost:8080":      http.StatusOK,
+		"127.0.0.1:8080":      http.StatusOK,
+		"[::1]:8080":          http.StatusOK,
+		"rebind.example:8080": http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("Host %s: expected %d, got %d", host, want, rec.Code)
+		}
+	}
+}
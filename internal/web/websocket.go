@@ -0,0 +1,53 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// clientQueue is how many messages a dashboard client may fall behind by
+// before it is disconnected
+const clientQueue = 64
+
+// writeTimeout bounds each write to a client, so a stalled browser is
+// dropped instead of holding up its queue forever
+const writeTimeout = 10 * time.Second
+
+// client is a connected dashboard. broadcast queues messages on send without
+// blocking; serve writes them out on the client's own goroutine.
+type client struct {
+	conn *websocket.Conn
+	send chan []byte
+}
+
+func newClient(conn *websocket.Conn) *client {
+	return &client{conn: conn, send: make(chan []byte, clientQueue)}
+}
+
+// serve writes queued messages until the client disconnects, a write fails or
+// times out, or broadcast closes send because the client fell too far behind
+func (c *client) serve(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data, ok := <-c.send:
+			if !ok {
+				_ = c.conn.Close(websocket.StatusPolicyViolation, "too far behind")
+				return
+			}
+			if err := c.write(ctx, data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write sends one text frame, giving up after writeTimeout
+func (c *client) write(ctx context.Context, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+	return c.conn.Write(ctx, websocket.MessageText, data)
+}
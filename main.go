@@ -1,24 +1,1208 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"maps"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
+	"cc_session_mon/internal/alert"
+	"cc_session_mon/internal/annotate"
+	"cc_session_mon/internal/applog"
+	"cc_session_mon/internal/bench"
+	"cc_session_mon/internal/chat"
+	"cc_session_mon/internal/check"
+	"cc_session_mon/internal/config"
+	"cc_session_mon/internal/deeplink"
+	"cc_session_mon/internal/demo"
+	"cc_session_mon/internal/digest"
+	"cc_session_mon/internal/doctor"
+	"cc_session_mon/internal/export"
+	"cc_session_mon/internal/plain"
+	"cc_session_mon/internal/replay"
+	"cc_session_mon/internal/scrub"
+	"cc_session_mon/internal/security"
+	"cc_session_mon/internal/session"
+	"cc_session_mon/internal/share"
+	"cc_session_mon/internal/snapshot"
+	"cc_session_mon/internal/sshserver"
 	"cc_session_mon/internal/tui"
+	"cc_session_mon/internal/web"
+
+	"filippo.io/age"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
+// version is set at build time via -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
-	followDevagent := flag.Bool("follow-devagent", false, "Monitor sessions in devagent containers")
-	flag.Parse()
+	// Refuse to run without the rules an organization policy requires
+	if _, err := config.LoadPolicy(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in organization policy: %v\n", err)
+		os.Exit(1)
+	}
+
+	name, err := dispatch(os.Args[1:])
+	if errors.Is(err, errFailed) {
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+// runTUI monitors sessions in the terminal UI, or with its flags the web
+// dashboard, a demo, a replay, a share, or plain text lines
+func runTUI(args []string) error {
+	fs := newFlagSet("tui")
+	followDevagent := fs.Bool("follow-devagent", false, "Monitor sessions in devagent containers")
+	webAddr := fs.String("web", "", "Serve the web dashboard on this address (e.g. :8080, loopback unless a host is given) instead of the TUI")
+	shareListen := fs.String("share-listen", "", "Share this instance's sessions with read-only viewers (unix:/path or host:port)")
+	shareConnect := fs.String("share-connect", "", "Connect as a read-only viewer to a sharing instance (unix:/path or host:port)")
+	demoMode := fs.Bool("demo", false, "Monitor a generated directory of synthetic sessions instead of real data")
+	recordEvents := fs.String("record-events", "", "Record the live event stream to this file")
+	replayEvents := fs.String("replay-events", "", "Replay a recorded event stream instead of watching sessions")
+	replaySpeed := fs.Float64("replay-speed", 1, "Replay speed multiplier (0 replays without delays)")
+	layout := fs.String("layout", "", "Start with a layout preset: "+config.LayoutPresetNames())
+	plainMode := fs.Bool("plain", false, "Write events as plain text lines instead of the TUI (for screen readers and tee)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var focus *deeplink.Link
+	if fs.NArg() > 0 {
+		link, err := deeplink.Parse(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		focus = &link
+		// Flags may follow the link
+		if err := parseFlags(fs, fs.Args()[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("usage: cc_session_mon [tui] [flags] [ccmon://session/<id>[?cmd=<uuid>]]")
+		}
+	}
+
+	if *layout != "" {
+		preset, err := config.LayoutPreset(*layout)
+		if err != nil {
+			return err
+		}
+		cfg := *config.Global()
+		cfg.Layout = preset
+		config.SetGlobal(&cfg)
+	}
+
+	if *webAddr != "" {
+		return runWeb(*webAddr, *followDevagent)
+	}
 
 	opts := tui.ModelOptions{
 		FollowDevagent: *followDevagent,
 	}
+
+	// cleanup runs after the TUI exits (e.g. removing the demo directory)
+	cleanup := func() {}
+
+	switch {
+	case *demoMode:
+		watcher, stop, err := startDemo()
+		if err != nil {
+			return fmt.Errorf("starting demo: %w", err)
+		}
+		cleanup = stop
+		opts = tui.ModelOptions{Watcher: watcher, Label: "[demo]"}
+
+	case *replayEvents != "":
+		watcher, err := startReplay(*replayEvents, *replaySpeed)
+		if err != nil {
+			return fmt.Errorf("replaying events: %w", err)
+		}
+		opts = tui.ModelOptions{Watcher: watcher, Label: "[replay]"}
+
+	case *shareConnect != "":
+		watcher, err := session.NewReplicaWatcher()
+		if err == nil {
+			err = share.Connect(*shareConnect, watcher)
+		}
+		if err != nil {
+			return fmt.Errorf("connecting to shared monitor: %w", err)
+		}
+		opts = tui.ModelOptions{Watcher: watcher, Label: "[viewer]"}
+
+	case *shareListen != "":
+		watcher, err := startShareServer(*shareListen, *followDevagent)
+		if err != nil {
+			return fmt.Errorf("starting share server: %w", err)
+		}
+		opts.Watcher = watcher
+		opts.Label = "[sharing]"
+	}
+
+	if *recordEvents != "" {
+		stop, err := startRecording(*recordEvents, &opts)
+		if err != nil {
+			return fmt.Errorf("recording events: %w", err)
+		}
+		prev := cleanup
+		cleanup = func() { stop(); prev() }
+	}
+
+	// Alerts come from the instance that owns the sessions, not viewers,
+	// replays, or the demo
+	if !*demoMode && *shareConnect == "" && *replayEvents == "" {
+		stop, err := startAlerts(&opts, "")
+		if err != nil {
+			return fmt.Errorf("starting alerts: %w", err)
+		}
+		prev := cleanup
+		cleanup = func() { stop(); prev() }
+	}
+
+	if *plainMode {
+		err := runPlain(opts)
+		cleanup()
+		return err
+	}
+
+	opts.Suspendable = true
+	opts.Focus = focus
 	p := tea.NewProgram(tui.NewModel(opts), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running program: %v\n", err)
-		os.Exit(1)
+	_, err := p.Run()
+	cleanup()
+	return err
+}
+
+// runPlain writes the sessions found and then each event to stdout as
+// lines of text until interrupted
+func runPlain(opts tui.ModelOptions) error {
+	watcher := opts.Watcher
+	if watcher == nil {
+		var err error
+		if watcher, err = tui.NewWatcher(opts.FollowDevagent); err != nil {
+			return err
+		}
+	}
+	sessions, err := watcher.DiscoverSessions()
+	if err != nil {
+		return err
+	}
+
+	out := plain.New(os.Stdout)
+	out.Initial(sessions)
+	watcher.Start()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	poll := time.NewTicker(config.Global().RefreshInterval)
+	defer poll.Stop()
+	subagentScan := time.NewTicker(config.Global().SubagentScanInterval)
+	defer subagentScan.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-watcher.Events:
+			// A copy, since the watcher keeps updating the session; findings
+			// on a command look at the session's earlier ones
+			out.Event(watcher.SnapshotEventWithHistory(event))
+		case err := <-watcher.Errors:
+			applog.Warnf("watcher: %v", err)
+			fmt.Fprintf(os.Stderr, "Watcher error: %v\n", err)
+		case <-poll.C:
+			if watcher.Polling() {
+				go watcher.CatchUp()
+			}
+		case <-subagentScan.C:
+			go watcher.ScanForNewSubagents()
+		}
+	}
+}
+
+// startDemo creates a synthetic projects directory that keeps growing, and a
+// watcher over it. The returned stop function removes the directory.
+func startDemo() (*session.Watcher, func(), error) {
+	gen, err := demo.NewGenerator()
+	if err != nil {
+		return nil, nil, err
+	}
+	watcher, err := session.NewWatcher([]string{gen.Dir})
+	if err != nil {
+		_ = gen.Stop()
+		return nil, nil, err
+	}
+	watcher.SetOrigin(gen.Dir, "local")
+	gen.Start()
+	return watcher, func() { _ = gen.Stop() }, nil
+}
+
+// startReplay loads a recording and plays it into a replica watcher. Frames at
+// offset zero are applied before returning so they form the initial state.
+func startReplay(path string, speed float64) (*session.Watcher, error) {
+	frames, err := replay.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := session.NewReplicaWatcher()
+	if err != nil {
+		return nil, err
+	}
+	replay.Play(frames, watcher, speed)
+	return watcher, nil
+}
+
+// startRecording records the event stream of opts.Watcher (creating the
+// default watcher if none is set) to path. The initial sessions are recorded
+// as discovered at offset zero. The returned stop function closes the file.
+func startRecording(path string, opts *tui.ModelOptions) (func(), error) {
+	if opts.Watcher == nil {
+		watcher, err := tui.NewWatcher(opts.FollowDevagent)
+		if err != nil {
+			return nil, err
+		}
+		opts.Watcher = watcher
+	}
+
+	sessions, err := opts.Watcher.DiscoverSessions()
+	if err != nil {
+		return nil, err
+	}
+	rec, err := replay.NewRecorder(path, sessions)
+	if err != nil {
+		return nil, err
+	}
+	go rec.Record(opts.Watcher, opts.Watcher.Subscribe())
+	return func() { _ = rec.Close() }, nil
+}
+
+// startAlerts sends alerts for new commands in opts.Watcher (creating the
+// default watcher if none is set) when a sink is configured. Chat alerts link
+// to dashboardURL unless the config names a dashboard. The returned stop
+// function delivers any batched alerts.
+func startAlerts(opts *tui.ModelOptions, dashboardURL string) (func(), error) {
+	engine, err := alert.New(config.Global(), alert.Options{
+		DashboardURL: dashboardURL,
+		FlagSession:  func(path, reason string) { opts.Watcher.FlagSession(path, reason) },
+		SnapshotEvent: func(ev session.WatchEvent) session.WatchEvent {
+			return opts.Watcher.SnapshotEventWithHistory(ev)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !engine.Enabled() {
+		_ = engine.Close()
+		return func() {}, nil
+	}
+
+	if opts.Watcher == nil {
+		watcher, err := tui.NewWatcher(opts.FollowDevagent)
+		if err != nil {
+			_ = engine.Close()
+			return nil, err
+		}
+		opts.Watcher = watcher
+	}
+	opts.PendingAlerts = engine.Pending
+	go engine.Run(opts.Watcher.Subscribe())
+	return func() { _ = engine.Close() }, nil
+}
+
+// startShareServer creates the owning watcher and serves it to viewers in the background
+func startShareServer(addr string, followDevagent bool) (*session.Watcher, error) {
+	watcher, err := tui.NewWatcher(followDevagent)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := share.Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+	go func() { _ = share.NewServer(watcher).Serve(ln) }()
+	return watcher, nil
+}
+
+// runServe parses serve flags and serves the web dashboard (also tui --web)
+func runServe(args []string) error {
+	fs := newFlagSet("serve")
+	addr := fs.String("addr", "127.0.0.1:8080", "Address to listen on (0.0.0.0:8080 serves other machines)")
+	followDevagent := fs.Bool("follow-devagent", false, "Monitor sessions in devagent containers")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return runWeb(*addr, *followDevagent)
+}
+
+// runWeb discovers sessions and serves the web dashboard until the server fails
+func runWeb(addr string, followDevagent bool) error {
+	addr = web.ListenAddr(addr)
+	watcher, err := tui.NewWatcher(followDevagent)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = watcher.Stop() }()
+
+	server := web.NewServer(watcher)
+	stopAlerts, err := startAlerts(&tui.ModelOptions{Watcher: watcher}, dashboardURL(addr))
+	if err != nil {
+		return err
+	}
+	defer stopAlerts()
+	if _, err := watcher.DiscoverSessions(); err != nil {
+		return err
+	}
+	watcher.Start()
+
+	fmt.Printf("Serving dashboard on http://%s\n", addr)
+	return server.ListenAndServe(addr)
+}
+
+// dashboardURL returns the URL of a dashboard served on addr (":8080" -> http://localhost:8080)
+func dashboardURL(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "http://localhost" + addr
+	}
+	return "http://" + addr
+}
+
+// runServeSSH parses serve-ssh flags and exposes the TUI over SSH
+func runServeSSH(args []string) error {
+	home, _ := os.UserHomeDir()
+
+	fs := newFlagSet("serve-ssh")
+	addr := fs.String("addr", ":2222", "Address to listen on")
+	hostKey := fs.String("host-key", "", "Host key path, generated if missing (default ssh_host_ed25519 in the state directory)")
+	authorizedKeys := fs.String("authorized-keys", filepath.Join(home, ".ssh", "authorized_keys"),
+		"Public keys allowed to connect")
+	followDevagent := fs.Bool("follow-devagent", false, "Monitor sessions in devagent containers")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	// Resolved after parsing: the state directory depends on --config and
+	// --profile, and StatePath may move a key an earlier version left behind
+	if *hostKey == "" {
+		*hostKey = config.StatePath("ssh_host_ed25519")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*hostKey), 0o700); err != nil {
+		return err
+	}
+	return sshserver.Serve(sshserver.Options{
+		Addr:               *addr,
+		HostKeyPath:        *hostKey,
+		AuthorizedKeysPath: *authorizedKeys,
+		Model:              tui.ModelOptions{FollowDevagent: *followDevagent},
+	})
+}
+
+// runSnapshot discovers sessions and writes a sanitized state archive for bug reports
+func runSnapshot(args []string) error {
+	fs := newFlagSet("snapshot")
+	output := fs.String("o", "cc_session_mon-snapshot-"+time.Now().Format("20060102-150405")+".tar.gz",
+		"Output archive path")
+	redact := fs.Bool("redact", false, "Drop raw commands and the config, and anonymize project paths")
+	recent := fs.Int("recent", snapshot.DefaultRecentLimit, "Number of recent commands to include")
+	followDevagent := fs.Bool("follow-devagent", false, "Include sessions in devagent containers")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	watcher, err := tui.NewWatcher(*followDevagent)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = watcher.Stop() }()
+
+	sessions, err := watcher.DiscoverSessions()
+	if err != nil {
+		return err
+	}
+
+	err = snapshot.Write(*output, sessions, snapshot.Options{
+		Version:     version,
+		Config:      config.Global(),
+		RecentLimit: *recent,
+		Redact:      *redact,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote snapshot of %d sessions to %s\n", len(sessions), *output)
+	return nil
+}
+
+// runExport bundles selected sessions into an encrypted archive for
+// long-term retention; `open` reads it back
+func runExport(args []string) error {
+	fs := newFlagSet("export")
+	output := fs.String("o", "cc_session_mon-export-"+time.Now().Format("20060102-150405")+".tar.gz.age",
+		"Output archive path")
+	recipients := fs.String("r", "", "Comma-separated age public keys (age1...) to encrypt to")
+	recipientsFile := fs.String("R", "", "Encrypt to the age public keys in this file, one per line")
+	passphrase := fs.Bool("passphrase", false, "Encrypt with the passphrase in $CCMON_PASSPHRASE (or --passphrase-file)")
+	passphraseFile := fs.String("passphrase-file", "", "Read the passphrase from this file")
+	keygen := fs.String("keygen", "", "Write a new identity to this file, print its public key, and exit")
+	ids := fs.String("session", "", "Comma-separated session IDs (or ID prefixes) to export")
+	project := fs.String("project", "", "Only export sessions whose project path contains this")
+	owner := fs.String("owner", "", "Only export sessions with this owner label (see owners in the config)")
+	since := fs.Duration("since", 0, "Only export sessions active this recently (e.g. 720h)")
+	scrubbed := fs.Bool("scrub", false, "Apply the config's scrub rules to the archived files")
+	followDevagent := fs.Bool("follow-devagent", false, "Include sessions in devagent containers")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *keygen != "" {
+		return writeIdentity(*keygen)
+	}
+
+	keys := splitList(*recipients)
+	if *recipientsFile != "" {
+		data, err := os.ReadFile(*recipientsFile)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				keys = append(keys, line)
+			}
+		}
+	}
+	usePassphrase := *passphrase || *passphraseFile != ""
+	if usePassphrase && len(keys) > 0 {
+		// The age format allows a passphrase only as the sole recipient
+		return fmt.Errorf("--passphrase can't be combined with -r or -R")
+	}
+
+	var to []age.Recipient
+	if usePassphrase {
+		secret, err := readPassphrase(*passphraseFile)
+		if err != nil {
+			return err
+		}
+		r, err := age.NewScryptRecipient(secret)
+		if err != nil {
+			return err
+		}
+		to = append(to, r)
+	}
+	for _, key := range keys {
+		r, err := age.ParseX25519Recipient(key)
+		if err != nil {
+			return err
+		}
+		to = append(to, r)
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients: pass -r, -R, or --passphrase")
+	}
+
+	watcher, err := tui.NewWatcher(*followDevagent)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = watcher.Stop() }()
+
+	sessions, err := watcher.DiscoverSessions()
+	if err != nil {
+		return err
+	}
+	filter := export.Filter{IDs: splitList(*ids), Project: *project, Owner: *owner, Since: *since}
+	sessions = filter.Select(sessions)
+	if len(sessions) == 0 {
+		return fmt.Errorf("no sessions match")
+	}
+
+	opts := export.Options{Version: version, Recipients: to}
+	if *scrubbed {
+		if opts.Scrub, err = scrub.New(config.Global().Scrub); err != nil {
+			return err
+		}
+	}
+	if err := export.Write(*output, sessions, opts); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d sessions to %s\n", len(sessions), *output)
+	return nil
+}
+
+// runScrub rewrites session files with the scrub rules applied, in place or
+// as copies under -o, for retention policies
+func runScrub(args []string) error {
+	fs := newFlagSet("scrub")
+	output := fs.String("o", "", "Write scrubbed copies under this directory instead of rewriting in place")
+	dryRun := fs.Bool("dry-run", false, "Report what would be scrubbed without writing anything")
+	force := fs.Bool("force", false, "Also rewrite sessions written to within the activity window")
+	stripToolResults := fs.Bool("strip-tool-results", false, "Replace tool results' content with a placeholder (default scrub.strip_tool_results)")
+	maskSecrets := fs.Bool("mask-secrets", false, "Mask credentials in all text (default scrub.mask_secrets)")
+	dropThinking := fs.Bool("drop-thinking", false, "Remove thinking blocks (default scrub.drop_thinking)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	// Read after parsing, so --config or --profile given after the command
+	// applies; the rule flags override only when given
+	rules := config.Global().Scrub
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "strip-tool-results":
+			rules.StripToolResults = *stripToolResults
+		case "mask-secrets":
+			rules.MaskSecrets = *maskSecrets
+		case "drop-thinking":
+			rules.DropThinking = *dropThinking
+		}
+	})
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: cc_session_mon scrub [flags] <session.jsonl|dir>...")
+	}
+
+	scrubber, err := scrub.New(rules)
+	if err != nil {
+		return err
+	}
+	var total scrub.Stats
+	files, skipped := 0, 0
+	for _, path := range fs.Args() {
+		base, paths, err := scrub.Files(path)
+		if err != nil {
+			return err
+		}
+		for _, src := range paths {
+			dst := src
+			if *output != "" {
+				rel, _ := filepath.Rel(base, src)
+				dst = filepath.Join(*output, rel)
+			} else if info, err := os.Stat(src); err == nil && !*force &&
+				time.Since(info.ModTime()) < config.Global().ActivityWindow {
+				fmt.Printf("Skipping %s: written to recently (use --force while it isn't in use)\n", src)
+				skipped++
+				continue
+			}
+			stats, err := scrubber.File(src, dst, *dryRun)
+			if err != nil {
+				return fmt.Errorf("%s: %w", src, err)
+			}
+			total.Add(stats)
+			files++
+		}
+	}
+
+	verb := "Scrubbed"
+	if *dryRun {
+		verb = "Would scrub"
+	}
+	fmt.Printf("%s %d of %d lines in %d file(s): %d tool results stripped, %d thinking blocks dropped, %d secrets masked\n",
+		verb, total.Changed, total.Lines, files, total.ToolResults, total.Thinking, total.Secrets)
+	if skipped > 0 {
+		return fmt.Errorf("%d active file(s) skipped", skipped)
+	}
+	return nil
+}
+
+// runOpen decrypts an exported archive and monitors its sessions in the
+// TUI, or extracts them with --extract
+func runOpen(args []string) error {
+	fs := newFlagSet("open")
+	identityFile := fs.String("i", "", "Decrypt with the age identities in this file")
+	passphrase := fs.Bool("passphrase", false, "Decrypt with the passphrase in $CCMON_PASSPHRASE (or --passphrase-file)")
+	passphraseFile := fs.String("passphrase-file", "", "Read the passphrase from this file")
+	extract := fs.String("extract", "", "Extract the sessions to this directory instead of opening them")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: cc_session_mon open [flags] <archive.tar.gz.age>")
+	}
+
+	var identities []age.Identity
+	if *passphrase || *passphraseFile != "" {
+		secret, err := readPassphrase(*passphraseFile)
+		if err != nil {
+			return err
+		}
+		id, err := age.NewScryptIdentity(secret)
+		if err != nil {
+			return err
+		}
+		identities = append(identities, id)
+	}
+	if *identityFile != "" {
+		f, err := os.Open(*identityFile)
+		if err != nil {
+			return err
+		}
+		ids, err := age.ParseIdentities(f)
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", *identityFile, err)
+		}
+		identities = append(identities, ids...)
+	}
+	if len(identities) == 0 {
+		return fmt.Errorf("no identities: pass -i or --passphrase")
+	}
+
+	dir := *extract
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "cc_session_mon-archive-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	}
+	manifest, err := export.Open(fs.Arg(0), dir, identities...)
+	if err != nil {
+		return err
+	}
+	if *extract != "" {
+		fmt.Printf("Extracted %d sessions (exported %s) to %s\n",
+			len(manifest.Sessions), manifest.ExportedAt.Format(time.DateTime), dir)
+		return nil
+	}
+
+	projectsDirs := manifest.ProjectsDirs(dir)
+	watcher, err := session.NewWatcher(slices.Sorted(maps.Keys(projectsDirs)))
+	if err != nil {
+		return err
+	}
+	for projectsDir, origin := range projectsDirs {
+		watcher.SetOrigin(projectsDir, origin)
+	}
+	defer func() { _ = watcher.Stop() }()
+
+	p := tea.NewProgram(tui.NewModel(tui.ModelOptions{Watcher: watcher, Label: "[archive]"}), tea.WithAltScreen())
+	_, err = p.Run()
+	return err
+}
+
+// writeIdentity writes a new age identity to path, as age-keygen does, and
+// prints its public key
+func writeIdentity(path string) error {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("# created: %s\n# public key: %s\n%s\n",
+		time.Now().Format(time.RFC3339), id.Recipient(), id)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Public key: %s\n", id.Recipient())
+	return nil
+}
+
+// readPassphrase reads an archive passphrase from file, or else from
+// $CCMON_PASSPHRASE, so it never appears in the process list
+func readPassphrase(file string) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	secret := os.Getenv("CCMON_PASSPHRASE")
+	if secret == "" {
+		return "", fmt.Errorf("set CCMON_PASSPHRASE or pass --passphrase-file")
+	}
+	return secret, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// runDigest summarizes activity since the last digest (or --since) and
+// delivers it to stdout, a file, and/or a webhook. Meant to run from cron.
+func runDigest(args []string) error {
+	fs := newFlagSet("digest")
+	since := fs.Duration("since", 0, "Summarize this far back instead of since the last digest (e.g. 24h)")
+	output := fs.String("o", "-", "Write the digest to this file (- for stdout)")
+	format := fs.String("format", "text", "Output format: text, json, or html")
+	webhook := fs.String("webhook", "", "Also POST the digest as JSON to this URL")
+	slack := fs.String("slack", "", "Also post the digest to this Slack incoming webhook")
+	discord := fs.String("discord", "", "Also post the digest to this Discord webhook")
+	statePath := fs.String("state", "", "File recording when the last digest ran (default digest-last-run in the state directory)")
+	noSave := fs.Bool("no-save", false, "Don't record this run (the next digest covers the same period)")
+	followDevagent := fs.Bool("follow-devagent", false, "Include sessions in devagent containers")
+	owner := fs.String("owner", "", "Only summarize sessions with this owner label (see owners in the config)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	// Resolved after parsing: the state directory depends on --config and
+	// --profile
+	if *statePath == "" {
+		*statePath = digest.DefaultStatePath()
+	}
+
+	until := time.Now()
+	start := until.Add(-*since)
+	if *since == 0 {
+		last, err := digest.LoadLastRun(*statePath)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", *statePath, err)
+		}
+		start = last
+		if start.IsZero() {
+			start = until.Add(-24 * time.Hour)
+		}
+	}
+
+	watcher, err := tui.NewWatcher(*followDevagent)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = watcher.Stop() }()
+
+	sessions, err := watcher.DiscoverSessions()
+	if err != nil {
+		return err
+	}
+	report := digest.Build(sessions, digest.Options{Since: start, Until: until, Config: config.Global(), Owner: *owner})
+
+	if *output == "-" {
+		err = report.Write(os.Stdout, *format)
+	} else {
+		err = writeDigestFile(*output, report, *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	dashboard := config.Global().Alerts.DashboardURL
+	posts := []struct {
+		name, url string
+		payload   func() any
+	}{
+		{"webhook", *webhook, func() any { return report.WebhookPayload() }},
+		{"Slack", *slack, func() any { return chat.SlackPayload(report.ChatMessages(dashboard)...) }},
+		{"Discord", *discord, func() any { return chat.DiscordPayload(report.ChatMessages(dashboard)...) }},
+	}
+	for _, p := range posts {
+		if p.url == "" {
+			continue
+		}
+		if err := chat.Post(ctx, p.url, p.payload()); err != nil {
+			return fmt.Errorf("posting to %s: %w", p.name, err)
+		}
+	}
+
+	if *noSave {
+		return nil
+	}
+	return digest.SaveLastRun(*statePath, until)
+}
+
+// writeDigestFile writes a digest report to path
+func writeDigestFile(path string, report *digest.Report, format string) error {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	if err := report.Write(f, format); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runCheck checks session files against the config's check rules for CI,
+// printing each violation and failing if there are any
+func runCheck(args []string) error {
+	fs := newFlagSet("check")
+	failOn := fs.String("fail-on", "", "Lowest finding severity that fails: low, medium, or high (default check.fail_on, else high)")
+	project := fs.String("project", "", "Directory the agent may write in, e.g. the repo checkout (default each session's first working directory)")
+	format := fs.String("format", "text", "Output format: text, json, junit, checkstyle, or sarif")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: cc_session_mon check [flags] <session.jsonl>...")
+	}
+
+	cfg := config.Global()
+	severity, err := check.FailOn(cfg)
+	if *failOn != "" {
+		severity, err = security.ParseSeverity(*failOn)
+	}
+	if err != nil {
+		return err
+	}
+	projectPath := *project
+	if projectPath != "" {
+		if projectPath, err = filepath.Abs(projectPath); err != nil {
+			return err
+		}
+	}
+
+	var violations []check.Violation
+	for _, path := range fs.Args() {
+		found, err := check.File(path, check.Options{Config: cfg, FailOn: severity, ProjectPath: projectPath})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		violations = append(violations, found...)
+	}
+	if err := check.Write(os.Stdout, violations, *format); err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		// The report is on stdout for CI to parse, so the count goes to stderr
+		fmt.Fprintf(os.Stderr, "%d violation(s) found\n", len(violations))
+		return errFailed
+	}
+	if *format == "text" {
+		fmt.Printf("No violations at %s severity or above in %d file(s)\n", severity, fs.NArg())
+	}
+	return nil
+}
+
+// runAnnotate turns a session into a GitHub pull request review: it writes
+// the review JSON for a bot, or posts it to a PR with `gh api`
+func runAnnotate(args []string) error {
+	fs := newFlagSet("annotate")
+	pr := fs.Int("pr", 0, "Post the review to this pull request of the current repository with `gh api`")
+	output := fs.String("o", "-", "Write the review JSON to this file (- for stdout) instead of posting it")
+	project := fs.String("project", "", "Directory the agent worked in (default the session's first working directory)")
+	checkout := fs.String("checkout", ".", "The PR's checkout, where changed lines are looked up")
+	commit := fs.String("commit", "", "Commit the comments refer to (default the PR head)")
+	minSeverity := fs.String("min-severity", "medium", "Lowest finding severity listed: low, medium, or high")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: cc_session_mon annotate [flags] <session.jsonl>")
+	}
+	severity, err := security.ParseSeverity(*minSeverity)
+	if err != nil {
+		return err
+	}
+
+	// GitHub rejects a review with any comment outside the PR's diff
+	var diff annotate.Diff
+	if *pr > 0 {
+		cmd := exec.Command("gh", "api", "--paginate", fmt.Sprintf("repos/{owner}/{repo}/pulls/%d/files", *pr))
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("gh api: %w", err)
+		}
+		if diff, err = annotate.ParseFiles(bytes.NewReader(out)); err != nil {
+			return err
+		}
+	}
+
+	review, err := annotate.Build(fs.Arg(0), annotate.Options{
+		Config:      config.Global(),
+		MinSeverity: severity,
+		ProjectPath: *project,
+		Checkout:    *checkout,
+		CommitID:    *commit,
+		Diff:        diff,
+	})
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(review, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *pr > 0:
+		// gh fills in {owner}/{repo} from the current repository
+		cmd := exec.Command("gh", "api", "--method", "POST", fmt.Sprintf("repos/{owner}/{repo}/pulls/%d/reviews", *pr), "--input", "-")
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("gh api: %w", err)
+		}
+		fmt.Printf("Posted a review with %d comments to pull request #%d\n", len(review.Comments), *pr)
+		return nil
+	case *output == "-":
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	default:
+		return os.WriteFile(filepath.Clean(*output), append(data, '\n'), 0o600)
+	}
+}
+
+// runConfig dispatches the config subcommands (init, validate, profile, policy)
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: cc_session_mon config <init|validate|profile|policy> [flags]")
+	}
+
+	switch args[0] {
+	case "init":
+		fs := newFlagSet("config init")
+		path := fs.String("path", config.UserConfigPath(), "Where to write the config")
+		force := fs.Bool("force", false, "Overwrite an existing config")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if err := config.WriteDefault(*path, *force); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote default config to %s\n", *path)
+		return nil
+
+	case "validate":
+		fs := newFlagSet("config validate")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return validateConfig(fs.Arg(0))
+
+	case "profile":
+		return runProfile(args[1:])
+
+	case "policy":
+		return showPolicy()
+
+	default:
+		return fmt.Errorf("unknown config subcommand %q (want init, validate, profile, or policy)", args[0])
+	}
+}
+
+// showPolicy prints the organization policy's rules, which main has already
+// validated
+func showPolicy() error {
+	policy, err := config.LoadPolicy()
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		fmt.Printf("No organization policy at %s\n", config.PolicyPath())
+		return nil
+	}
+
+	fmt.Printf("Organization policy %s:\n", config.PolicyPath())
+	for _, p := range policy.Security.WarnPatterns {
+		fmt.Printf("  warn pattern:   %s\n", p)
+	}
+	for _, p := range policy.Security.SensitivePaths {
+		fmt.Printf("  sensitive path: %s\n", p)
+	}
+	for _, p := range policy.NeverExclude {
+		fmt.Printf("  never excluded: %s\n", p)
+	}
+	return nil
+}
+
+// runProfile dispatches the config profile subcommands (list, export, import)
+func runProfile(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: cc_session_mon config profile <list|export|import> [flags]")
+	}
+
+	switch args[0] {
+	case "list":
+		names, err := config.Profiles()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Printf("No profiles in %s\n", config.ProfilesDir())
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+
+	case "export":
+		fs := newFlagSet("config profile export")
+		output := fs.String("o", "", "Write the profile to this file instead of stdout")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: cc_session_mon config profile export [-o file] <name>")
+		}
+		data, err := config.ExportProfile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if *output == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(filepath.Clean(*output), data, 0o600)
+
+	case "import":
+		fs := newFlagSet("config profile import")
+		name := fs.String("name", "", "Profile name (default: the file name without .yaml)")
+		force := fs.Bool("force", false, "Replace an existing profile")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: cc_session_mon config profile import [--name name] [--force] <file>")
+		}
+		path := filepath.Clean(fs.Arg(0))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if *name == "" {
+			*name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if err := config.ImportProfile(*name, data, *force); err != nil {
+			return err
+		}
+		fmt.Printf("Imported profile %s (use it with --profile %s)\n", *name, *name)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown config profile subcommand %q (want list, export, or import)", args[0])
+	}
+}
+
+// validateConfig checks path (or the config file in use if empty) and prints each problem
+func validateConfig(path string) error {
+	if path == "" {
+		path = config.FindPath()
+		if path == "" {
+			return fmt.Errorf("no config file found (create one with `cc_session_mon config init`)")
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	problems := config.Validate(data)
+	for _, p := range problems {
+		fmt.Printf("%s:%s\n", path, p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	fmt.Printf("%s: OK\n", path)
+	return nil
+}
+
+// runDoctor checks the environment and prints what to fix, failing if
+// any check failed
+func runDoctor(args []string) error {
+	fs := newFlagSet("doctor")
+	followDevagent := fs.Bool("follow-devagent", false, "Also check devagent containers, as the TUI's --follow-devagent needs")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("usage: cc_session_mon doctor [flags]")
+	}
+
+	results := doctor.Run(doctor.Options{
+		Config:         config.Global(),
+		ConfigPath:     config.FindPath(),
+		FollowDevagent: *followDevagent,
+	})
+	doctor.Write(os.Stdout, results)
+	if doctor.Failed(results) {
+		return fmt.Errorf("some checks failed")
+	}
+	return nil
+}
+
+// runBench parses a corpus of session files (or generated fixtures when no
+// paths are given) repeatedly and reports parser throughput and memory use
+func runBench(args []string) error {
+	fs := newFlagSet("bench")
+	iterations := fs.Int("n", 10, "Parse the corpus this many times")
+	calls := fs.Int("calls", 20000, "Tool calls per generated fixture file")
+	files := fs.Int("files", 4, "Number of fixture files to generate")
+	fixtures := fs.String("fixtures", "", "Generate fixtures into this directory and keep them (default: a temp dir)")
+	cpuProfile := fs.String("cpuprofile", "", "Write a CPU profile to this file")
+	memProfile := fs.String("memprofile", "", "Write an allocation profile to this file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: cc_session_mon bench [flags] [file or dir ...]")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		dir, err := generateBenchFixtures(*fixtures, *files, *calls)
+		if err != nil {
+			return err
+		}
+		if *fixtures == "" {
+			defer func() { _ = os.RemoveAll(dir) }()
+		}
+		paths = []string{dir}
+	}
+	corpus, err := bench.Corpus(paths)
+	if err != nil {
+		return err
+	}
+
+	stop, err := bench.StartProfiles(*cpuProfile, *memProfile)
+	if err != nil {
+		return err
+	}
+	result, err := bench.Run(corpus, *iterations)
+	if stopErr := stop(); err == nil {
+		err = stopErr
+	}
+	if err != nil {
+		return err
+	}
+	result.Write(os.Stdout)
+	return nil
+}
+
+// generateBenchFixtures writes files generated sessions of calls tool calls
+// each into dir, or a new temp dir if empty, and returns the directory
+func generateBenchFixtures(dir string, files, calls int) (string, error) {
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "cc_session_mon-bench-")
+		if err != nil {
+			return "", err
+		}
+		dir = tmp
+	}
+	for i := range files {
+		path := filepath.Join(dir, "-home-bench-app", fmt.Sprintf("bench-%d.jsonl", i))
+		if err := bench.GenerateFixture(path, calls, uint64(i)); err != nil { //nolint:gosec // i is small and non-negative
+			return "", err
+		}
 	}
+	return dir, nil
 }